@@ -101,7 +101,8 @@ func benchmarkProposeN(b *testing.B, sz int) {
 	}
 	total := uint64(0)
 	q := newEntryQueue(2048, 0)
-	pp := newPendingProposal(p, q, 1, 1, "localhost:9090", 200)
+	pp := newPendingProposal(p, q, 1, 1,
+		"localhost:9090", 200, pb.NoCompression)
 	session := client.NewNoOPSession(1, random.LockGuardedRand)
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -115,7 +116,7 @@ func benchmarkProposeN(b *testing.B, sz int) {
 				atomic.StoreUint64(&total, 0)
 				q.get(false)
 			}
-			pp.applied(rs.key, rs.clientID, rs.seriesID, 1, false)
+			pp.applied(rs.key, rs.clientID, rs.seriesID, sm.Result{Value: 1}, false)
 			rs.Release()
 		}
 	})
@@ -143,7 +144,8 @@ func BenchmarkPendingProposalNextKey(b *testing.B) {
 		return obj
 	}
 	q := newEntryQueue(2048, 0)
-	pp := newPendingProposal(p, q, 1, 1, "localhost:9090", 200)
+	pp := newPendingProposal(p, q, 1, 1,
+		"localhost:9090", 200, pb.NoCompression)
 	b.RunParallel(func(pb *testing.PB) {
 		clientID := rand.Uint64()
 		for pb.Next() {
@@ -442,12 +444,12 @@ func BenchmarkTransport1024(b *testing.B) {
 type noopNodeProxy struct {
 }
 
-func (n *noopNodeProxy) RestoreRemotes(pb.Snapshot)                     {}
-func (n *noopNodeProxy) ApplyUpdate(pb.Entry, uint64, bool, bool, bool) {}
-func (n *noopNodeProxy) ApplyConfigChange(pb.ConfigChange)              {}
-func (n *noopNodeProxy) ConfigChangeProcessed(uint64, bool)             {}
-func (n *noopNodeProxy) NodeID() uint64                                 { return 1 }
-func (n *noopNodeProxy) ClusterID() uint64                              { return 1 }
+func (n *noopNodeProxy) RestoreRemotes(pb.Snapshot)                        {}
+func (n *noopNodeProxy) ApplyUpdate(pb.Entry, sm.Result, bool, bool, bool) {}
+func (n *noopNodeProxy) ApplyConfigChange(pb.ConfigChange)                 {}
+func (n *noopNodeProxy) ConfigChangeProcessed(uint64, bool)                {}
+func (n *noopNodeProxy) NodeID() uint64                                    { return 1 }
+func (n *noopNodeProxy) ClusterID() uint64                                 { return 1 }
 
 func benchmarkStateMachineStep(b *testing.B, sz int, noopSession bool) {
 	b.ReportAllocs()
@@ -455,7 +457,7 @@ func benchmarkStateMachineStep(b *testing.B, sz int, noopSession bool) {
 	ds := &tests.NoOP{}
 	done := make(chan struct{})
 	nds := rsm.NewNativeStateMachine(rsm.NewRegularStateMachine(ds), done)
-	smo := rsm.NewStateMachine(nds, nil, false, &noopNodeProxy{})
+	smo := rsm.NewStateMachine(nds, nil, false, false, &noopNodeProxy{}, nil)
 	idx := uint64(0)
 	var s *client.Session
 	if noopSession {
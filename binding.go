@@ -42,7 +42,7 @@ func (rr *RequestResult) GetCode() RequestResultCode {
 func (nh *NodeHost) ProposeCH(s *client.Session,
 	data []byte, handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
-	return nh.propose(s, data, handler, timeout)
+	return nh.propose(s, data, handler, timeout, nil)
 }
 
 // ReadIndexCH is similar to the ReadIndex method with an extra
@@ -56,7 +56,7 @@ func (nh *NodeHost) ProposeCH(s *client.Session,
 func (nh *NodeHost) ReadIndexCH(clusterID uint64,
 	handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
-	rs, _, err := nh.readIndex(clusterID, handler, timeout)
+	rs, _, err := nh.readIndex(clusterID, handler, timeout, nil)
 	return rs, err
 }
 
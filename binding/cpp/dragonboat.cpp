@@ -110,6 +110,8 @@ const int Status::ErrCanceled = ::ErrCanceled;
 const int Status::ErrResultBufferTooSmall = ::ErrResultBufferTooSmall;
 const int Status::ErrRejected = ::ErrRejected;
 const int Status::ErrInvalidClusterSettings = ::ErrInvalidClusterSettings;
+const int Status::ErrClusterNotReady = ::ErrClusterNotReady;
+const int Status::ErrNodeHostDraining = ::ErrNodeHostDraining;
 
 Buffer::Buffer(size_t n) noexcept
   : data_(), len_(n)
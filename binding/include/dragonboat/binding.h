@@ -86,6 +86,8 @@ enum ErrorCode
   ErrResultBufferTooSmall = -15,
   ErrRejected = -16,
   ErrInvalidClusterSettings = -17,
+  ErrClusterNotReady = -18,
+  ErrNodeHostDraining = -19,
 };
 
 // CompleteHandlerType is the type of complete handler. CompleteHandlerCPP is
@@ -189,6 +189,8 @@ class Status
   static const int ErrResultBufferTooSmall;
   static const int ErrRejected;
   static const int ErrInvalidClusterSettings;
+  static const int ErrClusterNotReady;
+  static const int ErrNodeHostDraining;
  private:
   int code_;
 };
@@ -304,6 +306,7 @@ enum class ResultCode : int
   RequestCompleted = 1,
   RequestTerminated = 2,
   RequestRejected = 3,
+  RequestDropped = 4,
 };
 
 // RWResult is the result returned to client to indicate the complete state
@@ -87,6 +87,10 @@ func getErrorCode(err error) int {
 		return int(C.ErrCanceled)
 	} else if err == dragonboat.ErrRejected {
 		return int(C.ErrRejected)
+	} else if dragonboat.IsClusterNotReady(err) {
+		return int(C.ErrClusterNotReady)
+	} else if err == dragonboat.ErrNodeHostDraining {
+		return int(C.ErrNodeHostDraining)
 	}
 	panic(fmt.Sprintf("unknown error %v", err))
 }
@@ -27,6 +27,8 @@ import (
 	"github.com/lni/dragonboat/internal/utils/stringutil"
 	"github.com/lni/dragonboat/logger"
 	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+	"github.com/lni/dragonboat/tracing"
 )
 
 var (
@@ -43,6 +45,17 @@ type RaftRPCFactoryFunc func(NodeHostConfig,
 type LogDBFactoryFunc func(dirs []string,
 	lowLatencyDirs []string) (raftio.ILogDB, error)
 
+// IConfigChangeValidator is the interface used for validating requested Raft
+// membership changes. ValidateConfigChange is invoked on every replica when
+// the membership change is applied, it must be deterministic so all replicas
+// reach the same decision given the same inputs.
+type IConfigChangeValidator interface {
+	// ValidateConfigChange returns an error when the specified membership change
+	// should be rejected. members is the current membership of the Raft cluster
+	// before the membership change is applied.
+	ValidateConfigChange(cc pb.ConfigChange, members pb.Membership) error
+}
+
 // Config is used to configure Raft nodes.
 type Config struct {
 	// NodeID is a non-zero value used to identify a node within a Raft cluster.
@@ -52,13 +65,65 @@ type Config struct {
 	// IsObserver indicates whether this is an observer Raft node without voting
 	// power.
 	IsObserver bool
+	// IsWitness indicates whether this is a witness Raft node. A witness votes
+	// in elections and acknowledges replicated entries, but it only receives
+	// the index and term of each entry. Witness nodes never apply any entry to
+	// the user state machine.
+	IsWitness bool
 	// CheckQuorum specifies whether the leader node should periodically check
 	// non-leader node status and step down to become a follower node when it no
 	// longer has the quorum.
 	CheckQuorum bool
+	// PreVote specifies whether to enable the PreVote extension described in
+	// section 9.6 of the Raft thesis. When enabled, a node first asks other
+	// nodes whether it could win an election before incrementing its term and
+	// starting the actual election. This prevents nodes rejoining the cluster
+	// after being partitioned from disrupting the current leader.
+	PreVote bool
+	// LeaseRead specifies whether the leader node is allowed to serve ReadIndex
+	// requests using the leader lease described in section 6.4.1 of the Raft
+	// thesis. When enabled, a leader whose messages sent within the lease
+	// period have been acknowledged by a quorum of voting members confirms its
+	// leadership locally without having to exchange heartbeat messages with
+	// other nodes. The lease starts when the acknowledged messages are sent
+	// rather than when the acknowledgements are received. ReadIndex requests
+	// fall back to the regular ReadIndex protocol when the lease has expired.
+	//
+	// The lease period is ElectionRTT minus ClockDriftRTT, two extra RTTs are
+	// always deducted to cover message delays and the granularity of the
+	// logical clock. LeaseRead relies on bounded clock drift between NodeHost
+	// instances, it requires CheckQuorum to be enabled.
+	LeaseRead bool
+	// ClockDriftRTT is the maximum expected clock drift between NodeHost
+	// instances during the lease period, it is defined in terms of the number
+	// of message RTT. ClockDriftRTT is only used when LeaseRead is enabled.
+	ClockDriftRTT uint64
 	// Quiesce specifies whether to let the Raft cluster enter quiesce mode when
 	// there is no cluster activity.
 	Quiesce bool
+	// PreferredLeader is the NodeID of the node preferred to be the leader of
+	// the Raft cluster. When set, the leader automatically transfers the
+	// leadership to the preferred node once the preferred node has been active
+	// and caught up with the leader for two election timeouts. The default value
+	// 0 means there is no preferred leader. Observers and witnesses can not be
+	// the preferred leader. PreferredLeader can be changed at runtime using the
+	// SetPreferredLeader method of NodeHost on any node of the Raft cluster.
+	// Changes are forwarded to the leader and the leader broadcasts its own
+	// PreferredLeader and PreferredRegion values to all other nodes, the value
+	// set here is replaced once the node hears from a leader with a different
+	// value. Drummer doesn't move leaders of Raft clusters with a preferred
+	// leader or a preferred region.
+	PreferredLeader uint64
+	// PreferredRegion is the list of NodeIDs of the voting members located in
+	// the region preferred to host the leader of the Raft cluster. When set, a
+	// leader outside of the preferred region automatically transfers the
+	// leadership to the most caught up active node in the preferred region once
+	// it has been ready for two election timeouts. PreferredLeader takes
+	// precedence over PreferredRegion when the preferred leader is available.
+	// PreferredRegion can be changed at runtime using the SetPreferredRegion
+	// method of NodeHost, it converges on the leader's value in the same way as
+	// PreferredLeader.
+	PreferredRegion []uint64
 	// ElectionRTT is the minimum number of message RTT between elections. Message
 	// RTT is defined by NodeHostConfig.RTTMillisecond. The Raft paper suggests it
 	// to be a magnitude greater than HeartbeatRTT, which is the interval between
@@ -106,6 +171,25 @@ type Config struct {
 	// MaxInMemLogSize should be left as 0 or be set to be greater than
 	// 3 * MaxProposalPayloadSize, MaxProposalPayloadSize is 32Mbytes by default.
 	MaxInMemLogSize uint64
+	// EntryCompressionType is the compression type to use for compressing the
+	// payload of user proposals before they are replicated and saved into the
+	// Log DB. Compressed entries are tagged in the Raft log, entries proposed
+	// with different compression types can thus coexist and the compression
+	// type can be changed across restarts. Entries are always decompressed
+	// before they are passed to the state machine. The default value
+	// NoCompression means entries are not compressed.
+	EntryCompressionType pb.CompressionType
+	// SnapshotCompressionType is the compression type to use for compressing
+	// the state machine data in generated snapshots. Compressed snapshots are
+	// also streamed to remote nodes in the compressed form. The default value
+	// NoCompression means snapshots are not compressed.
+	SnapshotCompressionType pb.CompressionType
+	// ConfigChangeValidator is the optional validator used for authorizing
+	// requested membership changes before they are applied. It is invoked on
+	// every replica of the Raft cluster, a rejected membership change is
+	// reported to the requester as RequestRejected. The default nil value means
+	// all membership changes are accepted.
+	ConfigChangeValidator IConfigChangeValidator
 }
 
 // Validate validates the Config instance and return an error when any member
@@ -129,9 +213,39 @@ func (c *Config) Validate() error {
 	if c.MaxInMemLogSize > 0 && c.MaxInMemLogSize < settings.Soft.ExpectedMaxInMemLogSize {
 		return errors.New("MaxInMemLogSize is too small")
 	}
+	if c.IsObserver && c.IsWitness {
+		return errors.New("witness node can not be an observer")
+	}
+	if c.IsObserver || c.IsWitness {
+		if c.PreferredLeader == c.NodeID {
+			return errors.New("observer or witness can not be the preferred leader")
+		}
+		for _, nid := range c.PreferredRegion {
+			if nid == c.NodeID {
+				return errors.New("observer or witness can not be in the preferred region")
+			}
+		}
+	}
+	if c.LeaseRead && !c.CheckQuorum {
+		return errors.New("LeaseRead requires CheckQuorum to be enabled")
+	}
+	if c.LeaseRead && c.ElectionRTT <= c.ClockDriftRTT+2 {
+		return errors.New("ClockDriftRTT is too large for the ElectionRTT")
+	}
+	if !isValidCompressionType(c.EntryCompressionType) {
+		return errors.New("unknown EntryCompressionType")
+	}
+	if !isValidCompressionType(c.SnapshotCompressionType) {
+		return errors.New("unknown SnapshotCompressionType")
+	}
 	return nil
 }
 
+func isValidCompressionType(ct pb.CompressionType) bool {
+	_, ok := pb.CompressionType_name[int32(ct)]
+	return ok
+}
+
 // NodeHostConfig is the configuration used to configure NodeHost instances.
 type NodeHostConfig struct {
 	// DeploymentID is used to determine whether two NodeHost instances belong to
@@ -204,12 +318,69 @@ type NodeHostConfig struct {
 	KeyFile string
 	// LogDBFactory is the factory function used for creating the Log DB instance
 	// used by NodeHost. The default zero value causes the default built-in RocksDB
-	// based Log DB implementation to be used.
+	// based Log DB implementation to be used. The pure Go WAL based Log DB can be
+	// used by setting this field to the NewLogDB function in the plugin/wal
+	// package.
 	LogDBFactory LogDBFactoryFunc
+	// LogDBKVStoreType is the type of the key-value store used by the default
+	// built-in Log DB implementation, supported values are "rocksdb", "leveldb"
+	// and "pebble". RocksDB is not available when the dragonboat_no_rocksdb
+	// build tag is set. The default empty value causes the RocksDB based
+	// key-value store to be used when it is included in the build, or the
+	// LevelDB based one to be used otherwise.
+	// The key-value store type is recorded in the NodeHost dir, NodeHost fails
+	// to start when the recorded type is different from the configured one.
+	// This field is ignored when LogDBFactory is set, the name of the Log DB
+	// created by LogDBFactory is recorded and checked instead.
+	LogDBKVStoreType string
+	// LogDBShards is the number of shards used by the default built-in Log DB
+	// implementation, each shard is backed by its own key-value store instance.
+	// Hosts with fast storage devices such as NVMe arrays can benefit from more
+	// shards. The value can not be larger than 16, which is the number of step
+	// engine workers, a divisor of 16 such as 4, 8 or 16 evenly spreads Raft
+	// clusters across shards. The shard count is recorded in the NodeHost dir
+	// when the Log DB is created, the default zero value causes the recorded
+	// shard count to be used, or 8 shards to be used when creating a new Log DB.
+	// Log DBs created by older versions always have 16 shards. NodeHost fails
+	// to start when the configured shard count is different from the recorded
+	// one, use the repartition op of the logdbtool program in the
+	// tools/logdbtool directory to change the shard count of existing data. This
+	// field is ignored when LogDBFactory is set.
+	LogDBShards uint64
 	// RaftRPCFactory is the factory function used for creating the Raft RPC
 	// instance for exchanging Raft message between NodeHost instances. The default
 	// zero value causes the built-in TCP based RPC module to be used.
 	RaftRPCFactory RaftRPCFactoryFunc
+	// MetricsAddress is the hostname:port or IP:port address of the optional
+	// metrics server. When set, NodeHost collects metrics such as proposal and
+	// read counts, commit and apply latencies, election counts, snapshot
+	// durations, transport and Log DB statistics, they are served in the
+	// Prometheus text format at the /metrics path of the specified address. It
+	// is recommended to use a local address, e.g. localhost:9090. Empty value
+	// means metrics are not collected.
+	MetricsAddress string
+	// RaftEventListener is the optional listener to be notified on Raft and
+	// system events such as leadership changes, membership changes, snapshot
+	// events and failed connections. Events are delivered on a dedicated
+	// goroutine so a slow listener doesn't block Raft nodes.
+	RaftEventListener raftio.IRaftEventListener
+	// Tracer is the optional tracer used for tracing individual proposals and
+	// reads made using the SyncPropose and SyncRead methods. Spans covering
+	// queueing, log append, Log DB saves, replication, commit and state machine
+	// update are reported to the Tracer. The default nil value means tracing is
+	// disabled.
+	Tracer tracing.ITracer
+	// MaxSnapshotSendBytesPerSecond defines how much snapshot data can be sent
+	// every second by the NodeHost instance. The bandwidth is fairly shared by
+	// all concurrent outgoing snapshot streams. It can be used to prevent
+	// snapshot streams from saturating the network and delaying Raft messages
+	// such as heartbeats. The default value 0 means no limit.
+	MaxSnapshotSendBytesPerSecond uint64
+	// MaxSnapshotRecvBytesPerSecond defines how much snapshot data can be
+	// received every second by the NodeHost instance. The bandwidth is fairly
+	// shared by all concurrent incoming snapshot streams. The default value 0
+	// means no limit.
+	MaxSnapshotRecvBytesPerSecond uint64
 }
 
 // Validate validates the NodeHostConfig instance and return an error when
@@ -224,6 +395,9 @@ func (c *NodeHostConfig) Validate() error {
 	if len(c.APIAddress) > 0 && !stringutil.IsValidAddress(c.APIAddress) {
 		return errors.New("invalid NodeHost API address")
 	}
+	if len(c.MetricsAddress) > 0 && !stringutil.IsValidAddress(c.MetricsAddress) {
+		return errors.New("invalid MetricsAddress")
+	}
 	for _, da := range c.MasterServers {
 		if !stringutil.IsValidAddress(da) {
 			return errors.New("invalid drummer address")
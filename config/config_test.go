@@ -16,6 +16,8 @@ package config
 
 import (
 	"testing"
+
+	pb "github.com/lni/dragonboat/raftpb"
 )
 
 func ExampleNodeHostConfig() {
@@ -105,3 +107,70 @@ func TestIsValidAddress(t *testing.T) {
 		checkInvalidAddress(t, v)
 	}
 }
+
+func TestMetricsAddressIsValidated(t *testing.T) {
+	nhc := NodeHostConfig{
+		RaftAddress:    "raft.address:23456",
+		MetricsAddress: "localhost:9090",
+	}
+	if err := nhc.Validate(); err != nil {
+		t.Errorf("valid config rejected, %v", err)
+	}
+	nhc.MetricsAddress = "localhost"
+	if err := nhc.Validate(); err == nil {
+		t.Errorf("invalid MetricsAddress not rejected")
+	}
+}
+
+func TestCompressionTypeIsValidated(t *testing.T) {
+	c := Config{
+		NodeID:                  1,
+		HeartbeatRTT:            1,
+		ElectionRTT:             10,
+		EntryCompressionType:    pb.Snappy,
+		SnapshotCompressionType: pb.Snappy,
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("valid config rejected, %v", err)
+	}
+	c.EntryCompressionType = pb.CompressionType(100)
+	if err := c.Validate(); err == nil {
+		t.Errorf("unknown EntryCompressionType not rejected")
+	}
+	c.EntryCompressionType = pb.NoCompression
+	c.SnapshotCompressionType = pb.CompressionType(100)
+	if err := c.Validate(); err == nil {
+		t.Errorf("unknown SnapshotCompressionType not rejected")
+	}
+}
+
+func TestObserverAndWitnessCanNotBePreferred(t *testing.T) {
+	tests := []struct {
+		observer bool
+		witness  bool
+		leader   uint64
+		region   []uint64
+		ok       bool
+	}{
+		{false, false, 1, []uint64{1, 2}, true},
+		{true, false, 2, []uint64{2, 3}, true},
+		{true, false, 1, nil, false},
+		{false, true, 1, nil, false},
+		{true, false, 0, []uint64{2, 1}, false},
+		{false, true, 0, []uint64{1}, false},
+	}
+	for idx, tt := range tests {
+		c := Config{
+			NodeID:          1,
+			HeartbeatRTT:    1,
+			ElectionRTT:     10,
+			IsObserver:      tt.observer,
+			IsWitness:       tt.witness,
+			PreferredLeader: tt.leader,
+			PreferredRegion: tt.region,
+		}
+		if err := c.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%d, unexpected result %v", idx, err)
+		}
+	}
+}
@@ -153,6 +153,8 @@ func toDrummerPBClusterInfo(cil []dragonboat.ClusterInfo) []pb.ClusterInfo {
 			ConfigChangeIndex: v.ConfigChangeIndex,
 			Incomplete:        v.Incomplete,
 			Pending:           v.Pending,
+			PreferredLeader:   v.PreferredLeader,
+			PreferredRegion:   v.PreferredRegion,
 		}
 		result = append(result, pbv)
 	}
@@ -255,6 +257,8 @@ func (dc *drummerClient) handleRequest(ctx context.Context,
 		dc.handleAddDeleteRequest(reqCtx, req)
 	} else if req.Change.Type == pb.Request_KILL {
 		dc.handleKillRequest(req)
+	} else if req.Change.Type == pb.Request_LEADER_TRANSFER {
+		dc.handleLeaderTransferRequest(req)
 	} else {
 		panic("unknown request type")
 	}
@@ -271,6 +275,17 @@ func (dc *drummerClient) handleKillRequest(req pb.NodeHostRequest) {
 	}
 }
 
+func (dc *drummerClient) handleLeaderTransferRequest(req pb.NodeHostRequest) {
+	nodeID := req.Change.Members[0]
+	clusterID := req.Change.ClusterId
+	plog.Debugf("leader transfer request handled on %s for %s",
+		dc.nh.RaftAddress(), logutil.DescribeNode(clusterID, nodeID))
+	if err := dc.nh.RequestLeaderTransfer(clusterID, nodeID); err != nil {
+		plog.Warningf("leader transfer to %s failed, %v",
+			logutil.DescribeNode(clusterID, nodeID), err)
+	}
+}
+
 func (dc *drummerClient) handleAddDeleteRequest(ctx context.Context,
 	req pb.NodeHostRequest) {
 	nodeID := req.Change.Members[0]
@@ -308,7 +323,8 @@ func (dc *drummerClient) handleAddDeleteRequest(ctx context.Context,
 		if !v.Completed() &&
 			!v.Timeout() &&
 			!v.Terminated() &&
-			!v.Rejected() {
+			!v.Rejected() &&
+			!v.Dropped() {
 			plog.Panicf("unknown result code: %v", v)
 		}
 		return
@@ -29,12 +29,14 @@ var (
 )
 
 type node struct {
-	ClusterID     uint64
-	NodeID        uint64
-	Address       string
-	IsLeader      bool
-	Tick          uint64
-	FirstObserved uint64
+	ClusterID       uint64
+	NodeID          uint64
+	Address         string
+	IsLeader        bool
+	PreferredLeader uint64
+	PreferredRegion []uint64
+	Tick            uint64
+	FirstObserved   uint64
 }
 
 type cluster struct {
@@ -217,12 +219,14 @@ func (c *cluster) deepCopy() *cluster {
 	nc.Nodes = make(map[uint64]*node)
 	for k, v := range c.Nodes {
 		nc.Nodes[k] = &node{
-			ClusterID:     v.ClusterID,
-			NodeID:        v.NodeID,
-			Address:       v.Address,
-			IsLeader:      v.IsLeader,
-			Tick:          v.Tick,
-			FirstObserved: v.FirstObserved,
+			ClusterID:       v.ClusterID,
+			NodeID:          v.NodeID,
+			Address:         v.Address,
+			IsLeader:        v.IsLeader,
+			PreferredLeader: v.PreferredLeader,
+			PreferredRegion: v.PreferredRegion,
+			Tick:            v.Tick,
+			FirstObserved:   v.FirstObserved,
 		}
 	}
 	return nc
@@ -329,6 +333,8 @@ func (mc *multiCluster) syncLeaderInfo(nhi pb.NodeHostInfo) {
 		if !ok {
 			continue
 		}
+		n.PreferredLeader = ci.PreferredLeader
+		n.PreferredRegion = ci.PreferredRegion
 		if !ci.IsLeader && n.IsLeader {
 			n.IsLeader = false
 		} else if ci.IsLeader && !n.IsLeader {
@@ -448,6 +454,8 @@ func (mc *multiCluster) getCluster(ci pb.ClusterInfo, tick uint64) *cluster {
 	n, ok := c.Nodes[ci.NodeId]
 	if ok {
 		n.IsLeader = ci.IsLeader
+		n.PreferredLeader = ci.PreferredLeader
+		n.PreferredRegion = ci.PreferredRegion
 	}
 	return c
 }
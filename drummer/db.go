@@ -212,22 +212,22 @@ func (d *DB) GetHash() uint64 {
 }
 
 // Update updates the DB instance.
-func (d *DB) Update(data []byte) uint64 {
+func (d *DB) Update(data []byte) statemachine.Result {
 	d.assertNotFailed()
 	var c pb.Update
 	if err := c.Unmarshal(data); err != nil {
 		panic(err)
 	}
 	if c.Type == pb.Update_CLUSTER {
-		return d.applyClusterUpdate(c.Change)
+		return statemachine.Result{Value: d.applyClusterUpdate(c.Change)}
 	} else if c.Type == pb.Update_KV {
-		return d.applyKVUpdate(c.KvUpdate)
+		return statemachine.Result{Value: d.applyKVUpdate(c.KvUpdate)}
 	} else if c.Type == pb.Update_NODEHOST_INFO {
-		return d.applyNodeHostInfoUpdate(c.NodehostInfo)
+		return statemachine.Result{Value: d.applyNodeHostInfoUpdate(c.NodehostInfo)}
 	} else if c.Type == pb.Update_REQUESTS {
-		return d.applyRequestsUpdate(c.Requests)
+		return statemachine.Result{Value: d.applyRequestsUpdate(c.Requests)}
 	} else if c.Type == pb.Update_TICK {
-		return d.applyTickUpdate()
+		return statemachine.Result{Value: d.applyTickUpdate()}
 	}
 	panic("Unknown update type")
 }
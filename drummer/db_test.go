@@ -91,9 +91,9 @@ func testTickCanBeIncreased(t *testing.T, db statemachine.IStateMachine) {
 	if err != nil {
 		panic(err)
 	}
-	v1 := db.Update(data)
-	v2 := db.Update(data)
-	v3 := db.Update(data)
+	v1 := db.Update(data).Value
+	v2 := db.Update(data).Value
+	v3 := db.Update(data).Value
 	if v2 != v1+tickIntervalSecond ||
 		v3 != v2+tickIntervalSecond ||
 		v3 != 3*tickIntervalSecond {
@@ -102,7 +102,7 @@ func testTickCanBeIncreased(t *testing.T, db statemachine.IStateMachine) {
 	if db.(*DB).Tick != 3*tickIntervalSecond {
 		t.Errorf("unexpected tick value")
 	}
-	v4 := db.Update(data)
+	v4 := db.Update(data).Value
 	if v4 != 4*tickIntervalSecond || db.(*DB).Tick != 4*tickIntervalSecond {
 		t.Errorf("unexpected tick value")
 	}
@@ -309,7 +309,7 @@ func testRequestsCanBeUpdated(t *testing.T, db statemachine.IStateMachine) {
 	if err != nil {
 		panic(err)
 	}
-	v := db.Update(data)
+	v := db.Update(data).Value
 	if v != 3 {
 		t.Errorf("unexpected returned value")
 	}
@@ -739,7 +739,7 @@ func TestLaunchRequestSetsTheLaunchFlag(t *testing.T) {
 	if db.(*DB).launched() {
 		t.Fatalf("launched flag already unexpectedly set")
 	}
-	v := db.Update(data)
+	v := db.Update(data).Value
 	if v != 1 {
 		t.Errorf("unexpected returned value")
 	}
@@ -488,6 +488,9 @@ func (d *Drummer) maintainClusters() ([]pb.NodeHostRequest, error) {
 	// kill zombies
 	reqs = d.scheduler.killZombieNodes()
 	requests = append(requests, reqs...)
+	// move leaders around when they are not evenly distributed
+	reqs = d.scheduler.balanceLeaders()
+	requests = append(requests, reqs...)
 	for _, req := range requests {
 		validateNodeHostRequest(req)
 	}
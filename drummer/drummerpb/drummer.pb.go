@@ -247,10 +247,11 @@ func (LookupResponse_Code) EnumDescriptor() ([]byte, []int) {
 type Request_Type int32
 
 const (
-	Request_CREATE Request_Type = 0
-	Request_DELETE Request_Type = 1
-	Request_ADD    Request_Type = 2
-	Request_KILL   Request_Type = 3
+	Request_CREATE          Request_Type = 0
+	Request_DELETE          Request_Type = 1
+	Request_ADD             Request_Type = 2
+	Request_KILL            Request_Type = 3
+	Request_LEADER_TRANSFER Request_Type = 4
 )
 
 var Request_Type_name = map[int32]string{
@@ -258,12 +259,14 @@ var Request_Type_name = map[int32]string{
 	1: "DELETE",
 	2: "ADD",
 	3: "KILL",
+	4: "LEADER_TRANSFER",
 }
 var Request_Type_value = map[string]int32{
-	"CREATE": 0,
-	"DELETE": 1,
-	"ADD":    2,
-	"KILL":   3,
+	"CREATE":          0,
+	"DELETE":          1,
+	"ADD":             2,
+	"KILL":            3,
+	"LEADER_TRANSFER": 4,
 }
 
 func (x Request_Type) Enum() *Request_Type {
@@ -1129,6 +1132,8 @@ type ClusterInfo struct {
 	ConfigChangeIndex uint64            `protobuf:"varint,5,opt,name=config_change_index,json=configChangeIndex" json:"config_change_index"`
 	Incomplete        bool              `protobuf:"varint,6,opt,name=incomplete" json:"incomplete"`
 	Pending           bool              `protobuf:"varint,7,opt,name=pending" json:"pending"`
+	PreferredLeader   uint64            `protobuf:"varint,8,opt,name=preferred_leader,json=preferredLeader" json:"preferred_leader"`
+	PreferredRegion   []uint64          `protobuf:"varint,9,rep,name=preferred_region,json=preferredRegion" json:"preferred_region,omitempty"`
 }
 
 func (m *ClusterInfo) Reset()         { *m = ClusterInfo{} }
@@ -1213,6 +1218,20 @@ func (m *ClusterInfo) GetPending() bool {
 	return false
 }
 
+func (m *ClusterInfo) GetPreferredLeader() uint64 {
+	if m != nil {
+		return m.PreferredLeader
+	}
+	return 0
+}
+
+func (m *ClusterInfo) GetPreferredRegion() []uint64 {
+	if m != nil {
+		return m.PreferredRegion
+	}
+	return nil
+}
+
 // LogInfo is the message used by nodehost to notify Drummer that it has
 // raft log that belongs to the specified raft cluster.
 type LogInfo struct {
@@ -2972,6 +2991,16 @@ func (m *ClusterInfo) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0
 	}
 	i++
+	dAtA[i] = 0x40
+	i++
+	i = encodeVarintDrummer(dAtA, i, uint64(m.PreferredLeader))
+	if len(m.PreferredRegion) > 0 {
+		for _, num := range m.PreferredRegion {
+			dAtA[i] = 0x48
+			i++
+			i = encodeVarintDrummer(dAtA, i, uint64(num))
+		}
+	}
 	return i, nil
 }
 
@@ -3676,6 +3705,12 @@ func (m *ClusterInfo) Size() (n int) {
 	n += 1 + sovDrummer(uint64(m.ConfigChangeIndex))
 	n += 2
 	n += 2
+	n += 1 + sovDrummer(uint64(m.PreferredLeader))
+	if len(m.PreferredRegion) > 0 {
+		for _, e := range m.PreferredRegion {
+			n += 1 + sovDrummer(uint64(e))
+		}
+	}
 	return n
 }
 
@@ -6315,6 +6350,98 @@ func (m *ClusterInfo) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Pending = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PreferredLeader", wireType)
+			}
+			m.PreferredLeader = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDrummer
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PreferredLeader |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDrummer
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.PreferredRegion = append(m.PreferredRegion, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDrummer
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDrummer
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.PreferredRegion) == 0 {
+					m.PreferredRegion = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDrummer
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.PreferredRegion = append(m.PreferredRegion, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field PreferredRegion", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDrummer(dAtA[iNdEx:])
@@ -8234,138 +8361,140 @@ var (
 func init() { proto.RegisterFile("drummer.proto", fileDescriptor_drummer_dbd5fe4da91fe005) }
 
 var fileDescriptor_drummer_dbd5fe4da91fe005 = []byte{
-	// 2121 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x4d, 0x73, 0xdb, 0xc6,
-	0x19, 0x26, 0xf8, 0xcd, 0x97, 0xa4, 0x04, 0xad, 0xbf, 0x10, 0x4d, 0x2c, 0xd3, 0xa8, 0x93, 0x2a,
-	0x9e, 0x44, 0x76, 0x34, 0x6e, 0xed, 0xa6, 0x75, 0x13, 0x8a, 0x84, 0x64, 0x56, 0x34, 0xe9, 0x80,
-	0x90, 0x9d, 0xf4, 0xc2, 0x81, 0x88, 0x95, 0x84, 0x8a, 0x04, 0x50, 0x60, 0xa9, 0x89, 0x72, 0xec,
-	0x0f, 0xe8, 0xf4, 0xde, 0x3f, 0xd0, 0xe9, 0xa1, 0xb7, 0xfe, 0x87, 0xcc, 0xf4, 0xd0, 0x1c, 0x7b,
-	0xea, 0x74, 0xec, 0x1f, 0xd0, 0x69, 0x7b, 0xcc, 0xa5, 0x83, 0xdd, 0x05, 0xb8, 0x20, 0x20, 0xcb,
-	0xd3, 0x3a, 0x17, 0x0e, 0xf1, 0x7e, 0xed, 0xee, 0xfb, 0xbc, 0x5f, 0xbb, 0xd0, 0xb4, 0xfc, 0xf9,
-	0x6c, 0x86, 0xfd, 0x2d, 0xcf, 0x77, 0x89, 0x8b, 0x6a, 0xfc, 0xd3, 0x3b, 0x5c, 0xff, 0xe8, 0xd8,
-	0x26, 0x27, 0xf3, 0xc3, 0xad, 0x89, 0x3b, 0xbb, 0x77, 0xec, 0x1e, 0xbb, 0xf7, 0xa8, 0xc4, 0xe1,
-	0xfc, 0x88, 0x7e, 0xd1, 0x0f, 0xfa, 0x8f, 0x69, 0xaa, 0x0f, 0xa1, 0xa2, 0xe3, 0x63, 0xdb, 0x75,
-	0x02, 0x74, 0x1d, 0xca, 0x3e, 0xfd, 0xab, 0x48, 0xad, 0xc2, 0x66, 0x4d, 0xe7, 0x5f, 0xe8, 0x2a,
-	0x94, 0x26, 0xee, 0xdc, 0x21, 0x4a, 0xbe, 0x55, 0xd8, 0x2c, 0xea, 0xec, 0x43, 0xb5, 0xa1, 0xd2,
-	0x99, 0xce, 0x03, 0x82, 0x7d, 0xa4, 0x40, 0x65, 0x86, 0x67, 0x87, 0xd8, 0x0f, 0xa8, 0x66, 0x51,
-	0x8f, 0x3e, 0xd1, 0x0f, 0x00, 0x26, 0x4c, 0x68, 0x6c, 0x5b, 0x4a, 0xbe, 0x25, 0x6d, 0x16, 0x77,
-	0x8a, 0xdf, 0xfc, 0xfd, 0x56, 0x4e, 0xaf, 0x71, 0x7a, 0xcf, 0x42, 0xb7, 0xa0, 0x6a, 0x7a, 0xde,
-	0xd8, 0x31, 0x67, 0x58, 0x29, 0xb4, 0xa4, 0xcd, 0x1a, 0x17, 0xa9, 0x98, 0x9e, 0x37, 0x30, 0x67,
-	0x58, 0xed, 0xc0, 0x1a, 0x5f, 0xaa, 0xe3, 0x4e, 0xa7, 0x78, 0x42, 0xc2, 0x5d, 0x6d, 0x41, 0x95,
-	0x9b, 0x60, 0xab, 0xd6, 0xb7, 0xd1, 0x56, 0xec, 0x85, 0x2d, 0x2e, 0xaf, 0xc7, 0x32, 0xea, 0x5f,
-	0x24, 0xc8, 0xef, 0x3f, 0x47, 0xd7, 0xa1, 0x70, 0x8a, 0xcf, 0x15, 0xa9, 0x95, 0x8f, 0xd7, 0x09,
-	0x09, 0x68, 0x1d, 0x4a, 0x67, 0xe6, 0x74, 0x8e, 0x95, 0xbc, 0xc0, 0x61, 0x24, 0xf4, 0x1e, 0xd4,
-	0x6d, 0x27, 0x20, 0xa6, 0x33, 0xc1, 0xe1, 0x31, 0x0a, 0xc2, 0x31, 0x20, 0x62, 0xf4, 0x2c, 0xa4,
-	0x40, 0x91, 0xd8, 0x93, 0x53, 0xa5, 0x28, 0xf0, 0x29, 0x05, 0x7d, 0x08, 0xab, 0xee, 0xd4, 0x1a,
-	0x8b, 0x46, 0x4a, 0x82, 0x50, 0xd3, 0x9d, 0x5a, 0xbd, 0x85, 0x1d, 0x15, 0x6a, 0x47, 0xb6, 0x63,
-	0x4e, 0xed, 0xaf, 0xb1, 0xa5, 0x94, 0x5b, 0xd2, 0x66, 0x35, 0xf2, 0x59, 0x4c, 0x56, 0xff, 0x24,
-	0x41, 0xb9, 0x73, 0x62, 0x3a, 0xc7, 0x18, 0xdd, 0x87, 0x22, 0x39, 0xf7, 0x30, 0x3d, 0xd2, 0xca,
-	0xf6, 0x75, 0xd1, 0x09, 0x54, 0x60, 0xcb, 0x38, 0xf7, 0x70, 0xbc, 0x9d, 0x73, 0x0f, 0xa7, 0x50,
-	0xc9, 0x67, 0xa1, 0x22, 0x80, 0x5a, 0x48, 0x82, 0x2a, 0xe2, 0x55, 0xcc, 0xc2, 0x0b, 0x41, 0x31,
-	0x5c, 0x13, 0x01, 0x94, 0x3b, 0xba, 0xd6, 0x36, 0x34, 0x39, 0xa7, 0xfe, 0x55, 0x82, 0x15, 0xb6,
-	0x1f, 0x1d, 0x07, 0x9e, 0xeb, 0x04, 0x18, 0x3d, 0x82, 0xe2, 0xc4, 0xb5, 0xa2, 0x8d, 0x6f, 0xa4,
-	0x36, 0x1e, 0x09, 0x6e, 0x75, 0x5c, 0x2b, 0x3e, 0x40, 0xa8, 0xa1, 0xfe, 0x46, 0x82, 0x62, 0x48,
-	0x44, 0x65, 0xc8, 0x0f, 0xf7, 0xe5, 0x1c, 0xba, 0x06, 0x6b, 0x9d, 0xfe, 0xc1, 0xc8, 0xd0, 0xf4,
-	0xf1, 0x60, 0x68, 0x8c, 0x77, 0x87, 0x07, 0x83, 0xae, 0x2c, 0x21, 0x04, 0x2b, 0x9d, 0xe1, 0x60,
-	0xb7, 0xdf, 0xeb, 0x44, 0xb4, 0x3c, 0x5a, 0x83, 0xe6, 0xc1, 0x60, 0x7f, 0x30, 0x7c, 0x31, 0x18,
-	0xeb, 0x9a, 0xa1, 0x7f, 0x29, 0x17, 0x42, 0x52, 0xa4, 0xad, 0x7d, 0xd1, 0x1b, 0x19, 0x72, 0x11,
-	0xc9, 0xd0, 0xd8, 0x19, 0x0e, 0x8d, 0x91, 0xa1, 0xb7, 0x9f, 0x3d, 0xd3, 0xba, 0x72, 0x09, 0xad,
-	0x42, 0x5d, 0xd7, 0xf6, 0x7a, 0xc3, 0xc1, 0x68, 0x3c, 0xd2, 0x0c, 0xb9, 0xac, 0xfe, 0x2b, 0x0f,
-	0xe5, 0x03, 0xcf, 0x32, 0x09, 0x46, 0xf7, 0xa0, 0x3c, 0xa1, 0x5b, 0x56, 0xa4, 0x96, 0xb4, 0x59,
-	0xdf, 0x5e, 0x4b, 0x9d, 0x85, 0x6f, 0x9f, 0x8b, 0xc5, 0x98, 0xe5, 0x53, 0x98, 0x31, 0x8b, 0x69,
-	0xcc, 0xee, 0x43, 0xed, 0xf4, 0x6c, 0x3c, 0xa7, 0x5c, 0x1a, 0x81, 0xf5, 0xed, 0xa6, 0xa0, 0xb6,
-	0xff, 0x9c, 0x4b, 0x57, 0x4f, 0xcf, 0xf8, 0xa6, 0x76, 0xa0, 0xe9, 0xb8, 0x16, 0x3e, 0x71, 0x03,
-	0x32, 0xb6, 0x9d, 0x23, 0x97, 0x62, 0x55, 0xdf, 0xbe, 0x21, 0x68, 0x0d, 0x5c, 0x0b, 0x3f, 0x71,
-	0x03, 0xd2, 0x73, 0x8e, 0x5c, 0xae, 0xdf, 0x88, 0x74, 0x42, 0x1a, 0xda, 0x85, 0xaa, 0x8f, 0x7f,
-	0x3d, 0xc7, 0x01, 0x09, 0x68, 0xc4, 0xd6, 0xb7, 0xef, 0x64, 0xa8, 0xeb, 0x4c, 0x64, 0x91, 0x9c,
-	0xd1, 0x5e, 0x22, 0x5d, 0x75, 0x97, 0x47, 0x44, 0x1d, 0x2a, 0xdc, 0xd3, 0x72, 0x2e, 0x04, 0x6f,
-	0xff, 0xb9, 0x2c, 0xa1, 0x2a, 0x14, 0x8d, 0x5e, 0x67, 0x9f, 0x61, 0x33, 0x18, 0x76, 0xb5, 0x27,
-	0xc3, 0x91, 0x31, 0xee, 0x0d, 0x76, 0x87, 0x72, 0x01, 0x35, 0xa0, 0xaa, 0x6b, 0x9f, 0x1f, 0x68,
-	0x23, 0x63, 0x24, 0x17, 0xd5, 0xef, 0xf2, 0xd0, 0xec, 0xbb, 0xee, 0xe9, 0xdc, 0xe3, 0x6b, 0xa2,
-	0x87, 0x89, 0xe8, 0xbf, 0x29, 0xec, 0x2e, 0x21, 0x97, 0x76, 0xe8, 0xfb, 0xb0, 0xba, 0x48, 0x82,
-	0xf1, 0xd4, 0x0e, 0xa2, 0xfa, 0xd6, 0x8c, 0x73, 0xa0, 0x6f, 0x07, 0x84, 0x3b, 0x7e, 0x4a, 0x8d,
-	0x5d, 0xe2, 0x78, 0xb6, 0x62, 0x58, 0x2e, 0xa2, 0x83, 0x87, 0xf9, 0x25, 0x96, 0x03, 0x88, 0x18,
-	0x3d, 0x0b, 0x6d, 0x40, 0xc5, 0xb4, 0x2c, 0x1f, 0x07, 0xcc, 0xb5, 0x8b, 0x2c, 0x62, 0x44, 0xf4,
-	0x09, 0x94, 0x02, 0x62, 0x92, 0x80, 0x96, 0x80, 0x7a, 0x32, 0x3f, 0xd8, 0x0e, 0x47, 0xc4, 0x24,
-	0x98, 0x1f, 0x30, 0xaa, 0x58, 0x54, 0x45, 0x35, 0x5e, 0xe7, 0xef, 0x6b, 0xb0, 0x36, 0xea, 0x3c,
-	0xd1, 0xba, 0x07, 0x7d, 0x4d, 0x1f, 0x77, 0x86, 0x03, 0x43, 0xfb, 0xc2, 0x58, 0xf6, 0x34, 0x4d,
-	0x1d, 0x9e, 0x13, 0x23, 0xa3, 0x6d, 0x68, 0x23, 0xb9, 0xa4, 0xfe, 0x3e, 0x0f, 0x2b, 0x91, 0x57,
-	0x53, 0x39, 0x2c, 0x2d, 0xe5, 0x70, 0x52, 0x30, 0x95, 0xc3, 0x89, 0xfa, 0x9d, 0xbf, 0xbc, 0x7e,
-	0x73, 0x1c, 0x7c, 0x1c, 0xcc, 0xa7, 0xe4, 0x12, 0x1c, 0x74, 0x2a, 0x94, 0x08, 0xde, 0xe2, 0xff,
-	0x11, 0xbc, 0xef, 0xbd, 0x51, 0xb1, 0x51, 0xbf, 0x93, 0xc2, 0x56, 0xca, 0xa2, 0xf2, 0xe3, 0x44,
-	0x54, 0x8a, 0x29, 0x77, 0x61, 0x3c, 0x7e, 0xcf, 0x45, 0x19, 0xdd, 0x85, 0x95, 0x89, 0xeb, 0x1c,
-	0x8d, 0x59, 0x05, 0x5a, 0x6e, 0x41, 0x8d, 0x90, 0xc7, 0x4a, 0x55, 0xcf, 0x52, 0x3f, 0x4e, 0x17,
-	0xf0, 0xf0, 0x7f, 0x57, 0xeb, 0x6b, 0x86, 0x26, 0x4b, 0xa8, 0x02, 0x85, 0x76, 0x37, 0x2c, 0xa6,
-	0x55, 0x28, 0xee, 0xf7, 0xfa, 0x7d, 0xb9, 0xa0, 0x3e, 0x86, 0x2b, 0x19, 0x51, 0x99, 0x95, 0x65,
-	0x52, 0x46, 0x96, 0xa9, 0xbf, 0x2d, 0x42, 0x43, 0xd4, 0x5f, 0x72, 0x87, 0x94, 0xed, 0x8e, 0xbb,
-	0xb0, 0x32, 0xc5, 0xa6, 0x85, 0xfd, 0x71, 0x58, 0xb5, 0x96, 0x47, 0x8c, 0x06, 0xe3, 0x85, 0x40,
-	0xf7, 0x2c, 0xf4, 0x08, 0x4a, 0xa1, 0x10, 0x73, 0x5c, 0x7d, 0x5b, 0xbd, 0x20, 0x9d, 0x68, 0x5c,
-	0x04, 0x9a, 0x43, 0xfc, 0x73, 0x9d, 0x29, 0xa0, 0xa7, 0xd0, 0xd0, 0x9f, 0x75, 0xda, 0x2c, 0x2d,
-	0x71, 0x18, 0x4b, 0xa1, 0x81, 0x0f, 0x2e, 0x32, 0x20, 0xca, 0x32, 0x3b, 0x09, 0x75, 0xf4, 0x13,
-	0x96, 0xd7, 0x98, 0xfa, 0x3f, 0x59, 0xb2, 0x12, 0x76, 0xe8, 0xaf, 0x98, 0xd6, 0x18, 0x3d, 0x80,
-	0x2b, 0x21, 0x4e, 0xf6, 0x71, 0x8c, 0xa2, 0x63, 0xe1, 0xaf, 0x68, 0x81, 0x88, 0x0e, 0xbd, 0xc6,
-	0x04, 0x38, 0x94, 0x21, 0x7b, 0xbd, 0x0b, 0xb0, 0x38, 0xd4, 0x62, 0x00, 0x5a, 0xe8, 0x2c, 0x0f,
-	0x40, 0xd2, 0xd2, 0x00, 0xf4, 0x49, 0xfe, 0x91, 0xb4, 0xbe, 0x0f, 0x6b, 0xa9, 0x93, 0xfd, 0xaf,
-	0xc6, 0xd4, 0x16, 0x94, 0x18, 0xcc, 0x51, 0x4e, 0xad, 0x42, 0xfd, 0x60, 0xd0, 0x7e, 0xde, 0xee,
-	0xf5, 0xdb, 0x3b, 0x7d, 0x4d, 0x96, 0xd4, 0x7f, 0xe6, 0xa1, 0xce, 0xdd, 0x41, 0x3b, 0xd1, 0x1b,
-	0xc5, 0xc3, 0x4d, 0xa8, 0x2c, 0x02, 0x61, 0x21, 0x51, 0x76, 0x58, 0x08, 0xdc, 0x86, 0x9a, 0x1d,
-	0x8c, 0x59, 0x54, 0xd0, 0x12, 0x12, 0x0d, 0x56, 0x55, 0x3b, 0xe8, 0x53, 0x2a, 0x7a, 0x18, 0x45,
-	0x09, 0x03, 0xf9, 0x76, 0x1a, 0x9c, 0x70, 0x37, 0x19, 0x41, 0x72, 0x01, 0x34, 0xa5, 0xd7, 0x42,
-	0x83, 0xee, 0x00, 0xd8, 0xce, 0xc4, 0x9d, 0x79, 0x53, 0x4c, 0x70, 0x62, 0xd6, 0x13, 0xe8, 0x61,
-	0xa7, 0xf0, 0xb0, 0x63, 0xd9, 0xce, 0xb1, 0x52, 0x11, 0x44, 0x22, 0xe2, 0xdb, 0x01, 0x58, 0x7d,
-	0x0a, 0x95, 0xbe, 0x7b, 0xfc, 0xb6, 0x9c, 0xad, 0x3e, 0x81, 0xa6, 0x18, 0xce, 0x01, 0x7a, 0x08,
-	0x30, 0x89, 0x8b, 0x2c, 0x1f, 0xd9, 0x6f, 0x5c, 0xd4, 0xd4, 0x04, 0x51, 0xf5, 0xdf, 0x79, 0x68,
-	0x88, 0x93, 0x0a, 0xfa, 0x21, 0x34, 0x7c, 0xf3, 0x88, 0x8c, 0xa3, 0xf6, 0x29, 0x0e, 0xf3, 0xf5,
-	0x90, 0xc3, 0xa3, 0x14, 0x7d, 0x0a, 0x8d, 0xf8, 0x1c, 0xe1, 0x04, 0xc4, 0xfa, 0xcc, 0xf5, 0x6c,
-	0x50, 0x23, 0x03, 0x13, 0x21, 0xea, 0x32, 0xca, 0x57, 0x21, 0x6b, 0x48, 0xb8, 0x0d, 0xb5, 0xa9,
-	0x19, 0x90, 0x71, 0x6a, 0xfe, 0xaf, 0x86, 0x64, 0x23, 0xbc, 0x03, 0x6c, 0x03, 0xf2, 0xa6, 0xee,
-	0x31, 0xdd, 0xc8, 0xd8, 0x76, 0x26, 0xd3, 0xb9, 0x85, 0x59, 0x0d, 0x8e, 0xf0, 0x94, 0x43, 0x7e,
-	0xb8, 0x6c, 0x8f, 0x73, 0xd1, 0x8f, 0xa0, 0x16, 0xeb, 0x28, 0xe5, 0x54, 0x93, 0xe4, 0x70, 0x45,
-	0x4b, 0x45, 0xea, 0xe8, 0xdd, 0xf8, 0x22, 0x57, 0x11, 0xa0, 0x8e, 0xae, 0x73, 0x77, 0x00, 0x16,
-	0x89, 0xac, 0x54, 0x05, 0x09, 0x81, 0xae, 0xce, 0x00, 0x45, 0x3e, 0x17, 0x2e, 0x5d, 0x8f, 0x2f,
-	0xc1, 0x30, 0x63, 0xa0, 0x14, 0x14, 0xe2, 0x1b, 0x52, 0x7e, 0xf9, 0x86, 0xa4, 0xfe, 0x41, 0x82,
-	0x6b, 0x9d, 0xe5, 0xf4, 0xa0, 0xae, 0xdd, 0x83, 0x0a, 0x4d, 0x25, 0x1c, 0x5d, 0xf3, 0x3e, 0x12,
-	0xe1, 0xcb, 0x52, 0xd9, 0xea, 0x31, 0x79, 0x96, 0x9f, 0x91, 0xf6, 0xfa, 0x2e, 0x34, 0x44, 0xc6,
-	0x9b, 0xe5, 0x49, 0x31, 0x9d, 0x27, 0x3f, 0x85, 0x95, 0x2e, 0xf6, 0xa6, 0xee, 0xf9, 0x0c, 0x3b,
-	0x2c, 0x1e, 0x3f, 0x80, 0xa6, 0x15, 0x53, 0x96, 0x33, 0xa6, 0xb1, 0x60, 0xf5, 0x2c, 0xb5, 0x02,
-	0x25, 0x6d, 0xe6, 0x91, 0x73, 0xf5, 0x3f, 0x79, 0x58, 0x5d, 0x1a, 0x41, 0xd0, 0xfd, 0xa5, 0x6b,
-	0x04, 0x4a, 0xcf, 0x0d, 0x4b, 0xf7, 0x88, 0x16, 0x34, 0x78, 0x0e, 0x8a, 0x13, 0x2c, 0xb0, 0x14,
-	0xe4, 0x91, 0xd9, 0xe0, 0x69, 0xb2, 0x08, 0xdf, 0x9a, 0x5e, 0xe7, 0x34, 0x2a, 0xf2, 0x00, 0xae,
-	0xb0, 0x9b, 0x29, 0xb1, 0x4d, 0x82, 0xe3, 0x56, 0x2a, 0x86, 0xf1, 0x9a, 0x20, 0xc0, 0xfb, 0xe9,
-	0x72, 0x12, 0x8a, 0x33, 0x6c, 0x22, 0x09, 0x15, 0x28, 0xfe, 0xca, 0xb5, 0x9d, 0x44, 0x75, 0xa3,
-	0x94, 0xb0, 0xae, 0xf9, 0x38, 0x20, 0xae, 0x8f, 0x93, 0x75, 0x8d, 0x13, 0x13, 0x33, 0x4d, 0x35,
-	0x6b, 0xa6, 0x09, 0xef, 0x5d, 0x34, 0x02, 0x94, 0x5a, 0xfa, 0xde, 0x45, 0x19, 0xb1, 0xbf, 0xe8,
-	0x97, 0xfa, 0x25, 0xbc, 0x73, 0xe1, 0xdc, 0x87, 0x7e, 0x26, 0xcc, 0x8b, 0x2c, 0xd4, 0xd6, 0x2f,
-	0x9e, 0x17, 0x53, 0x53, 0xe2, 0x01, 0x5c, 0xed, 0x32, 0x61, 0xb6, 0x72, 0x04, 0xaa, 0x50, 0x26,
-	0xa5, 0x8c, 0x9e, 0x24, 0xdc, 0x02, 0xf2, 0x19, 0xb7, 0x00, 0xf5, 0x8f, 0x25, 0x28, 0x33, 0x83,
-	0xe8, 0x7d, 0xa8, 0x6b, 0x7c, 0xaf, 0xba, 0x61, 0x24, 0x02, 0x57, 0x64, 0xa0, 0x4d, 0x68, 0x3c,
-	0xc1, 0xa6, 0x4f, 0x0e, 0xb1, 0x49, 0x42, 0xc1, 0xc4, 0x4c, 0x24, 0x72, 0x42, 0x8b, 0x9d, 0x13,
-	0x3c, 0x39, 0xfd, 0x7c, 0xee, 0xfa, 0xf3, 0x59, 0xa2, 0x18, 0x89, 0x0c, 0xf4, 0x00, 0x50, 0xc7,
-	0x9d, 0x79, 0x26, 0x5d, 0x62, 0x78, 0x86, 0xfd, 0x13, 0x6c, 0x5a, 0x89, 0xb1, 0x23, 0x83, 0x8f,
-	0xb6, 0x60, 0x75, 0xe4, 0x98, 0x5e, 0x70, 0xe2, 0x92, 0x30, 0xe3, 0x6c, 0x1c, 0x50, 0x98, 0x23,
-	0x95, 0x65, 0x26, 0x7a, 0x04, 0x57, 0x75, 0xf3, 0x88, 0xf0, 0x92, 0xbc, 0x98, 0xb7, 0x44, 0xe8,
-	0x33, 0x25, 0xd0, 0x87, 0xb0, 0xc2, 0x7d, 0x1f, 0x95, 0xb5, 0x9a, 0xa0, 0xb3, 0xc4, 0x43, 0x77,
-	0xa1, 0xc9, 0x29, 0x34, 0x94, 0xbb, 0x0a, 0x88, 0x6f, 0x31, 0x09, 0x16, 0xfa, 0x0c, 0x14, 0x81,
-	0x10, 0xe2, 0xdf, 0xb5, 0x7d, 0x3c, 0x21, 0xae, 0x7f, 0xae, 0xd4, 0x85, 0x35, 0x2e, 0x94, 0x42,
-	0x3f, 0x86, 0x2b, 0x9c, 0xf7, 0xa2, 0xdd, 0x5f, 0x28, 0x37, 0x04, 0xe5, 0x2c, 0x01, 0xa4, 0x42,
-	0xed, 0xe9, 0x9c, 0xcc, 0xcd, 0xa9, 0xd1, 0x1f, 0x29, 0x4d, 0xf1, 0x15, 0x28, 0x26, 0x87, 0x85,
-	0xbe, 0xd3, 0xde, 0xb5, 0xa7, 0x58, 0x59, 0x11, 0x0b, 0x3d, 0xa3, 0xa1, 0x16, 0x54, 0x3b, 0xd8,
-	0x27, 0x94, 0xbf, 0x2a, 0xf0, 0x63, 0x6a, 0x18, 0x7c, 0xfb, 0xf8, 0x9c, 0x0a, 0xc8, 0x62, 0xf0,
-	0x71, 0x62, 0x88, 0xe0, 0x53, 0xf3, 0xab, 0x9e, 0xf3, 0x14, 0xcf, 0xfa, 0xee, 0xf1, 0xc8, 0xfe,
-	0x1a, 0x2b, 0x6b, 0x22, 0x82, 0x4b, 0xcc, 0xed, 0x3f, 0x97, 0xa1, 0xc2, 0xcf, 0x82, 0xf6, 0x40,
-	0x6e, 0x5b, 0x16, 0xff, 0x1a, 0x61, 0xff, 0x0c, 0xfb, 0xe8, 0x96, 0x90, 0x4f, 0x59, 0xc9, 0xb2,
-	0x2e, 0x0b, 0x02, 0xac, 0x4e, 0xe6, 0xd0, 0x2f, 0xe0, 0x8a, 0x8e, 0x67, 0xee, 0x19, 0x7e, 0x0b,
-	0xb6, 0x76, 0x60, 0x6d, 0x0f, 0x93, 0xa5, 0xf2, 0x9d, 0x12, 0x5c, 0x7f, 0x47, 0xb4, 0x9d, 0x10,
-	0x56, 0x73, 0xe8, 0x05, 0xdc, 0xda, 0xc3, 0x24, 0x7e, 0x90, 0xcc, 0xea, 0x59, 0x69, 0x8b, 0xad,
-	0xcb, 0x9a, 0x96, 0x9a, 0x43, 0xbf, 0x84, 0x1b, 0x3a, 0xf6, 0x5c, 0x9f, 0xb4, 0xcf, 0x4c, 0x7b,
-	0x6a, 0x1e, 0x4e, 0x71, 0x14, 0x4d, 0xe8, 0xa2, 0x1e, 0xbb, 0xfe, 0x46, 0x37, 0x5a, 0xea, 0xc4,
-	0x6b, 0x7b, 0x98, 0x64, 0x74, 0xf4, 0xf4, 0x56, 0x6f, 0x66, 0x98, 0x4c, 0xd8, 0xfa, 0x14, 0xea,
-	0x0b, 0x07, 0x04, 0x19, 0x16, 0xde, 0x4d, 0x0f, 0x58, 0x09, 0x03, 0x3f, 0x87, 0xc6, 0x68, 0x7e,
-	0x38, 0xb3, 0x09, 0x7f, 0xc1, 0x4c, 0x3f, 0x97, 0x25, 0x10, 0x48, 0xbe, 0x06, 0xaa, 0x39, 0xf4,
-	0x19, 0xac, 0x8e, 0x30, 0xd9, 0x71, 0x5d, 0x12, 0x10, 0xdf, 0xf4, 0x3c, 0x6c, 0x5d, 0x82, 0x61,
-	0xca, 0xc2, 0x63, 0x80, 0x11, 0x26, 0xd1, 0xc3, 0x77, 0xb2, 0xcf, 0x52, 0xda, 0xeb, 0xd5, 0x07,
-	0x20, 0x2f, 0x3c, 0xc0, 0xc7, 0xdb, 0x4b, 0xde, 0x67, 0xd6, 0x95, 0x0b, 0xf8, 0x81, 0x9a, 0xdb,
-	0x51, 0xbe, 0x79, 0xb9, 0x21, 0x7d, 0xfb, 0x72, 0x43, 0xfa, 0xc7, 0xcb, 0x0d, 0xe9, 0x77, 0xaf,
-	0x36, 0x72, 0xdf, 0xbe, 0xda, 0xc8, 0xfd, 0xed, 0xd5, 0x46, 0xee, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0xb0, 0x64, 0x33, 0x01, 0xe8, 0x17, 0x00, 0x00,
+	// 2154 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x4f, 0x73, 0xdb, 0xc6,
+	0x15, 0x27, 0xf8, 0x9f, 0x8f, 0xa4, 0x04, 0xad, 0x62, 0x1b, 0xe6, 0xc4, 0x32, 0x8d, 0x26, 0xa9,
+	0xe2, 0x49, 0x64, 0x57, 0xe3, 0xd6, 0x6e, 0x5a, 0x37, 0xa1, 0x48, 0x48, 0x66, 0x45, 0x93, 0x0e,
+	0x08, 0xd9, 0x49, 0x2f, 0x1c, 0x88, 0x58, 0x49, 0xa8, 0x48, 0x00, 0x05, 0x96, 0x9a, 0x28, 0xc7,
+	0x7e, 0x80, 0x4e, 0xee, 0xbd, 0xf4, 0xd8, 0xe9, 0xa1, 0xb7, 0x7e, 0x87, 0xcc, 0xf4, 0xd0, 0x1c,
+	0x7b, 0xea, 0x74, 0xec, 0x4f, 0xd0, 0xf6, 0xd8, 0x4b, 0x67, 0xb1, 0x0b, 0x72, 0x41, 0x80, 0x96,
+	0xa7, 0x75, 0x2f, 0x1c, 0xe2, 0xfd, 0xdb, 0xdd, 0xf7, 0x7e, 0xef, 0xed, 0x7b, 0x0b, 0x75, 0xcb,
+	0x9f, 0x4d, 0xa7, 0xd8, 0xdf, 0xf1, 0x7c, 0x97, 0xb8, 0xa8, 0xc2, 0x3f, 0xbd, 0xe3, 0xc6, 0xc7,
+	0xa7, 0x36, 0x39, 0x9b, 0x1d, 0xef, 0x8c, 0xdd, 0xe9, 0xbd, 0x53, 0xf7, 0xd4, 0xbd, 0x17, 0x4a,
+	0x1c, 0xcf, 0x4e, 0xc2, 0xaf, 0xf0, 0x23, 0xfc, 0xc7, 0x34, 0xd5, 0x87, 0x50, 0xd2, 0xf1, 0xa9,
+	0xed, 0x3a, 0x01, 0xba, 0x0e, 0x45, 0x3f, 0xfc, 0xab, 0x48, 0xcd, 0xdc, 0x76, 0x45, 0xe7, 0x5f,
+	0xe8, 0x1d, 0x28, 0x8c, 0xdd, 0x99, 0x43, 0x94, 0x6c, 0x33, 0xb7, 0x9d, 0xd7, 0xd9, 0x87, 0x6a,
+	0x43, 0xa9, 0x3d, 0x99, 0x05, 0x04, 0xfb, 0x48, 0x81, 0xd2, 0x14, 0x4f, 0x8f, 0xb1, 0x1f, 0x84,
+	0x9a, 0x79, 0x3d, 0xfa, 0x44, 0xdf, 0x03, 0x18, 0x33, 0xa1, 0x91, 0x6d, 0x29, 0xd9, 0xa6, 0xb4,
+	0x9d, 0xdf, 0xcb, 0x7f, 0xfb, 0xb7, 0xdb, 0x19, 0xbd, 0xc2, 0xe9, 0x5d, 0x0b, 0xdd, 0x86, 0xb2,
+	0xe9, 0x79, 0x23, 0xc7, 0x9c, 0x62, 0x25, 0xd7, 0x94, 0xb6, 0x2b, 0x5c, 0xa4, 0x64, 0x7a, 0x5e,
+	0xdf, 0x9c, 0x62, 0xb5, 0x0d, 0x1b, 0x7c, 0xa9, 0xb6, 0x3b, 0x99, 0xe0, 0x31, 0xa1, 0xbb, 0xda,
+	0x81, 0x32, 0x37, 0xc1, 0x56, 0xad, 0xee, 0xa2, 0x9d, 0xb9, 0x17, 0x76, 0xb8, 0xbc, 0x3e, 0x97,
+	0x51, 0xff, 0x2c, 0x41, 0xf6, 0xf0, 0x39, 0xba, 0x0e, 0xb9, 0x73, 0x7c, 0xa9, 0x48, 0xcd, 0xec,
+	0x7c, 0x1d, 0x4a, 0x40, 0x0d, 0x28, 0x5c, 0x98, 0x93, 0x19, 0x56, 0xb2, 0x02, 0x87, 0x91, 0xd0,
+	0xfb, 0x50, 0xb5, 0x9d, 0x80, 0x98, 0xce, 0x18, 0xd3, 0x63, 0xe4, 0x84, 0x63, 0x40, 0xc4, 0xe8,
+	0x5a, 0x48, 0x81, 0x3c, 0xb1, 0xc7, 0xe7, 0x4a, 0x5e, 0xe0, 0x87, 0x14, 0xf4, 0x11, 0xac, 0xbb,
+	0x13, 0x6b, 0x24, 0x1a, 0x29, 0x08, 0x42, 0x75, 0x77, 0x62, 0x75, 0x17, 0x76, 0x54, 0xa8, 0x9c,
+	0xd8, 0x8e, 0x39, 0xb1, 0xbf, 0xc6, 0x96, 0x52, 0x6c, 0x4a, 0xdb, 0xe5, 0xc8, 0x67, 0x73, 0xb2,
+	0xfa, 0x47, 0x09, 0x8a, 0xed, 0x33, 0xd3, 0x39, 0xc5, 0xe8, 0x3e, 0xe4, 0xc9, 0xa5, 0x87, 0xc3,
+	0x23, 0xad, 0xed, 0x5e, 0x17, 0x9d, 0x10, 0x0a, 0xec, 0x18, 0x97, 0x1e, 0x9e, 0x6f, 0xe7, 0xd2,
+	0xc3, 0x89, 0xa8, 0x64, 0xd3, 0xa2, 0x22, 0x04, 0x35, 0x17, 0x0f, 0xaa, 0x18, 0xaf, 0x7c, 0x5a,
+	0xbc, 0x10, 0xe4, 0xe9, 0x9a, 0x08, 0xa0, 0xd8, 0xd6, 0xb5, 0x96, 0xa1, 0xc9, 0x19, 0xf5, 0x2f,
+	0x12, 0xac, 0xb1, 0xfd, 0xe8, 0x38, 0xf0, 0x5c, 0x27, 0xc0, 0xe8, 0x11, 0xe4, 0xc7, 0xae, 0x15,
+	0x6d, 0x7c, 0x2b, 0xb1, 0xf1, 0x48, 0x70, 0xa7, 0xed, 0x5a, 0xf3, 0x03, 0x50, 0x0d, 0xf5, 0xd7,
+	0x12, 0xe4, 0x29, 0x11, 0x15, 0x21, 0x3b, 0x38, 0x94, 0x33, 0xe8, 0x1a, 0x6c, 0xb4, 0x7b, 0x47,
+	0x43, 0x43, 0xd3, 0x47, 0xfd, 0x81, 0x31, 0xda, 0x1f, 0x1c, 0xf5, 0x3b, 0xb2, 0x84, 0x10, 0xac,
+	0xb5, 0x07, 0xfd, 0xfd, 0x5e, 0xb7, 0x1d, 0xd1, 0xb2, 0x68, 0x03, 0xea, 0x47, 0xfd, 0xc3, 0xfe,
+	0xe0, 0x45, 0x7f, 0xa4, 0x6b, 0x86, 0xfe, 0xa5, 0x9c, 0xa3, 0xa4, 0x48, 0x5b, 0xfb, 0xa2, 0x3b,
+	0x34, 0xe4, 0x3c, 0x92, 0xa1, 0xb6, 0x37, 0x18, 0x18, 0x43, 0x43, 0x6f, 0x3d, 0x7b, 0xa6, 0x75,
+	0xe4, 0x02, 0x5a, 0x87, 0xaa, 0xae, 0x1d, 0x74, 0x07, 0xfd, 0xe1, 0x68, 0xa8, 0x19, 0x72, 0x51,
+	0xfd, 0x47, 0x16, 0x8a, 0x47, 0x9e, 0x65, 0x12, 0x8c, 0xee, 0x41, 0x71, 0x1c, 0x6e, 0x59, 0x91,
+	0x9a, 0xd2, 0x76, 0x75, 0x77, 0x23, 0x71, 0x16, 0xbe, 0xfd, 0xe2, 0x38, 0x1e, 0xb3, 0x6c, 0x22,
+	0x66, 0xcc, 0x62, 0x32, 0x66, 0xf7, 0xa1, 0x72, 0x7e, 0x31, 0x9a, 0x85, 0xdc, 0x10, 0x81, 0xd5,
+	0xdd, 0xba, 0xa0, 0x76, 0xf8, 0x9c, 0x4b, 0x97, 0xcf, 0x2f, 0xf8, 0xa6, 0xf6, 0xa0, 0xee, 0xb8,
+	0x16, 0x3e, 0x73, 0x03, 0x32, 0xb2, 0x9d, 0x13, 0x37, 0x8c, 0x55, 0x75, 0xf7, 0x86, 0xa0, 0xd5,
+	0x77, 0x2d, 0xfc, 0xc4, 0x0d, 0x48, 0xd7, 0x39, 0x71, 0xb9, 0x7e, 0x2d, 0xd2, 0xa1, 0x34, 0xb4,
+	0x0f, 0x65, 0x1f, 0xff, 0x6a, 0x86, 0x03, 0x12, 0x84, 0x88, 0xad, 0xee, 0xbe, 0x97, 0xa2, 0xae,
+	0x33, 0x91, 0x45, 0x72, 0x46, 0x7b, 0x89, 0x74, 0xd5, 0x7d, 0x8e, 0x88, 0x2a, 0x94, 0xb8, 0xa7,
+	0xe5, 0x0c, 0x0d, 0xde, 0xe1, 0x73, 0x59, 0x42, 0x65, 0xc8, 0x1b, 0xdd, 0xf6, 0x21, 0x8b, 0x4d,
+	0x7f, 0xd0, 0xd1, 0x9e, 0x0c, 0x86, 0xc6, 0xa8, 0xdb, 0xdf, 0x1f, 0xc8, 0x39, 0x54, 0x83, 0xb2,
+	0xae, 0x7d, 0x7e, 0xa4, 0x0d, 0x8d, 0xa1, 0x9c, 0x57, 0xff, 0x9d, 0x85, 0x7a, 0xcf, 0x75, 0xcf,
+	0x67, 0x1e, 0x5f, 0x13, 0x3d, 0x8c, 0xa1, 0xff, 0x96, 0xb0, 0xbb, 0x98, 0x5c, 0xd2, 0xa1, 0x1f,
+	0xc0, 0xfa, 0x22, 0x09, 0x46, 0x13, 0x3b, 0x88, 0xea, 0x5b, 0x7d, 0x9e, 0x03, 0x3d, 0x3b, 0x20,
+	0xdc, 0xf1, 0x93, 0xd0, 0xd8, 0x15, 0x8e, 0x67, 0x2b, 0xd2, 0x72, 0x11, 0x1d, 0x9c, 0xe6, 0x97,
+	0x58, 0x0e, 0x20, 0x62, 0x74, 0x2d, 0xb4, 0x05, 0x25, 0xd3, 0xb2, 0x7c, 0x1c, 0x30, 0xd7, 0x2e,
+	0xb2, 0x88, 0x11, 0xd1, 0x27, 0x50, 0x08, 0x88, 0x49, 0x82, 0xb0, 0x04, 0x54, 0xe3, 0xf9, 0xc1,
+	0x76, 0x38, 0x24, 0x26, 0xc1, 0xfc, 0x80, 0x51, 0xc5, 0x0a, 0x55, 0x54, 0xe3, 0x75, 0xfe, 0xbe,
+	0x06, 0x1b, 0xc3, 0xf6, 0x13, 0xad, 0x73, 0xd4, 0xd3, 0xf4, 0x51, 0x7b, 0xd0, 0x37, 0xb4, 0x2f,
+	0x8c, 0x65, 0x4f, 0x87, 0xa9, 0xc3, 0x73, 0x62, 0x68, 0xb4, 0x0c, 0x6d, 0x28, 0x17, 0xd4, 0xdf,
+	0x66, 0x61, 0x2d, 0xf2, 0x6a, 0x22, 0x87, 0xa5, 0xa5, 0x1c, 0x8e, 0x0b, 0x26, 0x72, 0x38, 0x56,
+	0xbf, 0xb3, 0x57, 0xd7, 0x6f, 0x1e, 0x07, 0x1f, 0x07, 0xb3, 0x09, 0xb9, 0x22, 0x0e, 0x7a, 0x28,
+	0x14, 0x03, 0x6f, 0xfe, 0x7f, 0x00, 0xef, 0xfb, 0x6f, 0x54, 0x6c, 0xd4, 0x6f, 0xb2, 0xf4, 0x2a,
+	0x65, 0xa8, 0xfc, 0x41, 0x0c, 0x95, 0x62, 0xca, 0xad, 0xc4, 0xe3, 0xff, 0xb9, 0x28, 0xa3, 0xbb,
+	0xb0, 0x36, 0x76, 0x9d, 0x93, 0x11, 0xab, 0x40, 0xcb, 0x57, 0x50, 0x8d, 0xf2, 0x58, 0xa9, 0xea,
+	0x5a, 0xea, 0x7e, 0xb2, 0x80, 0xd3, 0xff, 0x1d, 0xad, 0xa7, 0x19, 0x9a, 0x2c, 0xa1, 0x12, 0xe4,
+	0x5a, 0x1d, 0x5a, 0x4c, 0xcb, 0x90, 0x3f, 0xec, 0xf6, 0x7a, 0x72, 0x0e, 0x6d, 0xc2, 0x7a, 0x4f,
+	0x6b, 0x75, 0x34, 0x7d, 0x64, 0xe8, 0xad, 0xfe, 0x70, 0x5f, 0xd3, 0xe5, 0xbc, 0xfa, 0x18, 0x36,
+	0x53, 0xa0, 0x9a, 0x96, 0x7a, 0x52, 0x4a, 0xea, 0xa9, 0xbf, 0xc9, 0x43, 0x4d, 0xd4, 0x5f, 0xf2,
+	0x91, 0x94, 0xee, 0xa3, 0xbb, 0xb0, 0x36, 0xc1, 0xa6, 0x85, 0xfd, 0x11, 0x2d, 0x65, 0xcb, 0x7d,
+	0x47, 0x8d, 0xf1, 0x68, 0xf4, 0xbb, 0x16, 0x7a, 0x04, 0x05, 0x2a, 0xc4, 0xbc, 0x59, 0xdd, 0x55,
+	0x57, 0xe4, 0x58, 0x08, 0x96, 0x40, 0x73, 0x88, 0x7f, 0xa9, 0x33, 0x05, 0xf4, 0x14, 0x6a, 0xfa,
+	0xb3, 0x76, 0x8b, 0xe5, 0x2a, 0xa6, 0x00, 0xa3, 0x06, 0x3e, 0x5c, 0x65, 0x40, 0x94, 0x65, 0x76,
+	0x62, 0xea, 0xe8, 0xc7, 0x2c, 0xd9, 0x71, 0x18, 0x94, 0x78, 0x1d, 0x8b, 0xd9, 0x09, 0x7f, 0xc5,
+	0x5c, 0xc7, 0xe8, 0x01, 0x6c, 0xd2, 0xe0, 0xd9, 0xa7, 0xf3, 0xd0, 0x3a, 0x16, 0xfe, 0x4a, 0x29,
+	0x0a, 0x87, 0xde, 0x60, 0x02, 0x3c, 0xbe, 0x94, 0xdd, 0xe8, 0x00, 0x2c, 0x0e, 0xb5, 0xe8, 0x8a,
+	0x16, 0x3a, 0xcb, 0x5d, 0x91, 0xb4, 0xd4, 0x15, 0x7d, 0x92, 0x7d, 0x24, 0x35, 0x0e, 0x61, 0x23,
+	0x71, 0xb2, 0xff, 0xd6, 0x98, 0xda, 0x84, 0x02, 0x0b, 0x73, 0x94, 0x68, 0xeb, 0x50, 0x3d, 0xea,
+	0xb7, 0x9e, 0xb7, 0xba, 0xbd, 0xd6, 0x5e, 0x4f, 0x93, 0x25, 0xf5, 0x77, 0x39, 0xa8, 0x72, 0x77,
+	0x84, 0xd7, 0xd3, 0x1b, 0xe1, 0xe1, 0x16, 0x94, 0x16, 0x40, 0x58, 0x48, 0x14, 0x1d, 0x06, 0x81,
+	0x3b, 0x50, 0xb1, 0x83, 0x11, 0x43, 0x85, 0x92, 0x13, 0xba, 0xad, 0xb2, 0x1d, 0xf4, 0x42, 0x2a,
+	0x7a, 0x18, 0xa1, 0x84, 0x05, 0xf9, 0x4e, 0x32, 0x38, 0x74, 0x37, 0x29, 0x20, 0x59, 0x11, 0x9a,
+	0xc2, 0x6b, 0x43, 0x83, 0xde, 0x03, 0xb0, 0x9d, 0xb1, 0x3b, 0xf5, 0x26, 0x98, 0xe0, 0x58, 0x03,
+	0x28, 0xd0, 0xe9, 0xf5, 0xe1, 0x61, 0xc7, 0xb2, 0x9d, 0x53, 0xa5, 0x24, 0x88, 0x44, 0x44, 0x74,
+	0x0f, 0x64, 0xcf, 0xc7, 0x27, 0xd8, 0xf7, 0xb1, 0x15, 0x1d, 0xaf, 0x2c, 0x2c, 0xbc, 0x3e, 0xe7,
+	0xb2, 0x53, 0xbe, 0x1d, 0x44, 0xa8, 0x4f, 0xa1, 0xd4, 0x73, 0x4f, 0xdf, 0x56, 0x74, 0xd4, 0x27,
+	0x50, 0x17, 0xf1, 0x1f, 0xa0, 0x87, 0x00, 0xe3, 0x79, 0xa9, 0xe6, 0x8d, 0xff, 0x8d, 0x55, 0x57,
+	0xa3, 0x20, 0xaa, 0xfe, 0x33, 0x0b, 0x35, 0xb1, 0xdf, 0x41, 0xdf, 0x87, 0x9a, 0x6f, 0x9e, 0x90,
+	0x51, 0x74, 0x09, 0x8b, 0x23, 0x41, 0x95, 0x72, 0x38, 0xac, 0xd1, 0xa7, 0x50, 0x9b, 0x9f, 0x83,
+	0xf6, 0x51, 0xec, 0xb6, 0xba, 0x9e, 0x8e, 0x82, 0xc8, 0xc0, 0x78, 0x41, 0x4a, 0xab, 0x77, 0xb9,
+	0xb4, 0x56, 0xe3, 0x0e, 0x54, 0x26, 0x66, 0x40, 0x46, 0x89, 0x29, 0xa2, 0x4c, 0xc9, 0x06, 0x9d,
+	0x24, 0x76, 0x01, 0x79, 0x13, 0xf7, 0x34, 0xdc, 0xc8, 0xc8, 0x76, 0xc6, 0x93, 0x99, 0x85, 0x59,
+	0x25, 0x8f, 0x00, 0x20, 0x53, 0x3e, 0x5d, 0xb6, 0xcb, 0xb9, 0xe8, 0x87, 0x50, 0x99, 0xeb, 0x28,
+	0xc5, 0xc4, 0x55, 0xcb, 0xc3, 0x15, 0x2d, 0x15, 0xa9, 0xa3, 0x77, 0xe7, 0xe3, 0x60, 0x49, 0x08,
+	0x35, 0xa7, 0x51, 0x90, 0x2e, 0x32, 0x5f, 0x29, 0x0b, 0x12, 0x02, 0x5d, 0x9d, 0x02, 0x8a, 0x7c,
+	0x2e, 0x8c, 0x6e, 0x8f, 0xaf, 0x88, 0x61, 0x4a, 0x5b, 0x2a, 0x28, 0xcc, 0xe7, 0xac, 0xec, 0xf2,
+	0x9c, 0xa5, 0xfe, 0x5e, 0x82, 0x6b, 0xed, 0xe5, 0x7c, 0x0a, 0x5d, 0x7b, 0x00, 0xa5, 0x30, 0xf7,
+	0x70, 0x34, 0x2c, 0x7e, 0x2c, 0x86, 0x2f, 0x4d, 0x65, 0xa7, 0xcb, 0xe4, 0x59, 0x42, 0x47, 0xda,
+	0x8d, 0x7d, 0xa8, 0x89, 0x8c, 0x37, 0xcb, 0x93, 0x7c, 0x32, 0x4f, 0x7e, 0x02, 0x6b, 0x1d, 0xec,
+	0x4d, 0xdc, 0xcb, 0x29, 0x76, 0x18, 0x1e, 0x3f, 0x84, 0xba, 0x35, 0xa7, 0x2c, 0x67, 0x4c, 0x6d,
+	0xc1, 0xea, 0x5a, 0x6a, 0x09, 0x0a, 0xda, 0xd4, 0x23, 0x97, 0xea, 0xbf, 0xb2, 0xb0, 0xbe, 0xd4,
+	0xc8, 0xa0, 0xfb, 0x4b, 0xc3, 0x08, 0x4a, 0x76, 0x1f, 0x4b, 0xd3, 0x48, 0x13, 0x6a, 0x3c, 0x07,
+	0xc5, 0x3e, 0x18, 0x58, 0x0a, 0x72, 0x64, 0xd6, 0x78, 0x9a, 0x2c, 0xe0, 0x5b, 0xd1, 0xab, 0x9c,
+	0x16, 0x8a, 0x3c, 0x80, 0x4d, 0x36, 0xdf, 0x12, 0xdb, 0x24, 0x78, 0x7e, 0xf7, 0x8a, 0x30, 0xde,
+	0x10, 0x04, 0xf8, 0x05, 0xbc, 0x9c, 0x84, 0x62, 0x27, 0x1c, 0x4b, 0x42, 0x05, 0xf2, 0xbf, 0x74,
+	0x6d, 0x27, 0x56, 0x0e, 0x43, 0x0a, 0x2d, 0x84, 0x3e, 0x0e, 0x88, 0xeb, 0xe3, 0x78, 0x21, 0xe4,
+	0xc4, 0x58, 0x67, 0x54, 0x4e, 0xeb, 0x8c, 0xe8, 0xf4, 0x16, 0x22, 0x40, 0xa9, 0x24, 0xa7, 0xb7,
+	0x90, 0x31, 0xf7, 0x57, 0xf8, 0xa5, 0x7e, 0x09, 0x37, 0x57, 0x76, 0x8f, 0xe8, 0xa7, 0x42, 0xd7,
+	0xc9, 0xa0, 0xd6, 0x58, 0xdd, 0x75, 0x26, 0x7a, 0xcd, 0x23, 0x78, 0xa7, 0xc3, 0x84, 0xd9, 0xca,
+	0x51, 0x50, 0x85, 0x32, 0x29, 0xa5, 0x5c, 0x62, 0xc2, 0x2c, 0x91, 0x4d, 0x99, 0x25, 0xd4, 0x3f,
+	0x14, 0xa0, 0xc8, 0x0c, 0xa2, 0x0f, 0xa0, 0xaa, 0xf1, 0xbd, 0xea, 0x86, 0x11, 0x03, 0xae, 0xc8,
+	0x40, 0xdb, 0x50, 0x7b, 0x82, 0x4d, 0x9f, 0x1c, 0x63, 0x93, 0x50, 0xc1, 0x58, 0x13, 0x25, 0x72,
+	0xa8, 0xc5, 0xf6, 0x19, 0x1e, 0x9f, 0x7f, 0x3e, 0x73, 0xfd, 0xd9, 0x34, 0x56, 0x8c, 0x44, 0x06,
+	0x7a, 0x00, 0xa8, 0xed, 0x4e, 0x3d, 0x33, 0x5c, 0x62, 0x70, 0x81, 0xfd, 0x33, 0x6c, 0x5a, 0xb1,
+	0x3e, 0x25, 0x85, 0x8f, 0x76, 0x60, 0x7d, 0xe8, 0x98, 0x5e, 0x70, 0xe6, 0x12, 0x9a, 0x71, 0x36,
+	0x0e, 0x94, 0x92, 0xa0, 0xb2, 0xcc, 0x44, 0x8f, 0xe0, 0x1d, 0xdd, 0x3c, 0x21, 0xbc, 0x24, 0x2f,
+	0x1a, 0x34, 0x31, 0xf4, 0xa9, 0x12, 0xe8, 0x23, 0x58, 0xe3, 0xbe, 0xe7, 0x34, 0xa5, 0x22, 0xe8,
+	0x2c, 0xf1, 0xd0, 0x5d, 0xa8, 0x73, 0x4a, 0x08, 0xe5, 0x8e, 0x02, 0xe2, 0x8b, 0x4e, 0x8c, 0x85,
+	0x3e, 0x03, 0x45, 0x20, 0xd0, 0xf8, 0x77, 0x6c, 0x1f, 0x8f, 0x89, 0xeb, 0x5f, 0x2a, 0x55, 0x61,
+	0x8d, 0x95, 0x52, 0xe8, 0x47, 0xb0, 0xc9, 0x79, 0x2f, 0x5a, 0xbd, 0x85, 0x72, 0x4d, 0x50, 0x4e,
+	0x13, 0xa0, 0x6f, 0x49, 0x4f, 0x67, 0x64, 0x66, 0x4e, 0x8c, 0xde, 0x50, 0xa9, 0x8b, 0x6f, 0x49,
+	0x73, 0x32, 0x2d, 0xf4, 0xed, 0xd6, 0xbe, 0x3d, 0xc1, 0xca, 0x9a, 0x58, 0xe8, 0x19, 0x0d, 0x35,
+	0xa1, 0xdc, 0xc6, 0x3e, 0x09, 0xf9, 0xeb, 0x02, 0x7f, 0x4e, 0xa5, 0xe0, 0x3b, 0xc4, 0x97, 0xa1,
+	0x80, 0x2c, 0x82, 0x8f, 0x13, 0x69, 0x04, 0x9f, 0x9a, 0x5f, 0x75, 0x9d, 0xa7, 0x78, 0xda, 0x73,
+	0x4f, 0x87, 0xf6, 0xd7, 0x58, 0xd9, 0x10, 0x23, 0xb8, 0xc4, 0xdc, 0xfd, 0x53, 0x11, 0x4a, 0xfc,
+	0x2c, 0xe8, 0x00, 0xe4, 0x96, 0x65, 0xf1, 0xaf, 0x21, 0xf6, 0x2f, 0xb0, 0x8f, 0x6e, 0x0b, 0xf9,
+	0x94, 0x96, 0x2c, 0x0d, 0x59, 0x10, 0x60, 0x75, 0x32, 0x83, 0x7e, 0x0e, 0x9b, 0x3a, 0x9e, 0xba,
+	0x17, 0xf8, 0x2d, 0xd8, 0xda, 0x83, 0x8d, 0x03, 0x4c, 0x96, 0xca, 0x77, 0x42, 0xb0, 0x71, 0x53,
+	0xb4, 0x1d, 0x13, 0x56, 0x33, 0xe8, 0x05, 0xdc, 0x3e, 0xc0, 0x64, 0xfe, 0xac, 0x99, 0x76, 0x67,
+	0x25, 0x2d, 0x36, 0xaf, 0xba, 0xb4, 0xd4, 0x0c, 0xfa, 0x05, 0xdc, 0xd0, 0xb1, 0xe7, 0xfa, 0xa4,
+	0x75, 0x61, 0xda, 0x13, 0xf3, 0x78, 0x82, 0x23, 0x34, 0xa1, 0x55, 0x77, 0x6c, 0xe3, 0x8d, 0xe6,
+	0xe2, 0xd0, 0x89, 0xd7, 0x0e, 0x30, 0x49, 0xb9, 0xd1, 0x93, 0x5b, 0xbd, 0x95, 0x62, 0x32, 0x66,
+	0xeb, 0x53, 0xa8, 0x2e, 0x1c, 0x10, 0xa4, 0x58, 0x78, 0x37, 0xd9, 0x60, 0xc5, 0x0c, 0xfc, 0x0c,
+	0x6a, 0xc3, 0xd9, 0xf1, 0xd4, 0x26, 0xfc, 0x1d, 0x34, 0xf9, 0xe8, 0xd6, 0xb8, 0x99, 0x20, 0x45,
+	0xef, 0x11, 0x6a, 0x06, 0x7d, 0x06, 0xeb, 0x43, 0x4c, 0xf6, 0x5c, 0x97, 0x04, 0xc4, 0x37, 0x3d,
+	0x0f, 0x5b, 0x57, 0xc4, 0x30, 0x61, 0xe1, 0x31, 0xc0, 0x10, 0x93, 0xe8, 0xf9, 0x3c, 0x7e, 0xcf,
+	0x86, 0xb4, 0xd7, 0xab, 0xf7, 0x41, 0x5e, 0x78, 0x80, 0xb7, 0xb7, 0x57, 0xbc, 0xf2, 0x34, 0x94,
+	0x15, 0xfc, 0x40, 0xcd, 0xec, 0x29, 0xdf, 0xbe, 0xdc, 0x92, 0xbe, 0x7b, 0xb9, 0x25, 0xfd, 0xfd,
+	0xe5, 0x96, 0xf4, 0xcd, 0xab, 0xad, 0xcc, 0x77, 0xaf, 0xb6, 0x32, 0x7f, 0x7d, 0xb5, 0x95, 0xf9,
+	0xcf, 0x00, 0x1f, 0xfe, 0x72, 0x0f, 0x2e, 0x18, 0x00, 0x00,
 }
@@ -16,6 +16,7 @@ package drummer
 
 import (
 	"errors"
+	"sort"
 
 	pb "github.com/lni/dragonboat/drummer/drummerpb"
 	"github.com/lni/dragonboat/internal/settings"
@@ -24,6 +25,10 @@ import (
 )
 
 var (
+	// leaderImbalanceThreshold is the minimum difference between the number of
+	// leaders on two node hosts required before moving a leader between them.
+	// it prevents leaders from flapping between node hosts with similar load.
+	leaderImbalanceThreshold = 2
 	// errNotEnoughNodeHost indicates that there are not enough node host
 	// instances currently available.
 	errNotEnoughNodeHost = errors.New("not enough node host")
@@ -326,6 +331,102 @@ func (s *scheduler) killZombieNodes() []pb.NodeHostRequest {
 	return results
 }
 
+//
+// Leader balancing related
+//
+
+// balanceLeaders generates leader transfer requests to move leaders away from
+// node hosts hosting noticeably more leaders than others. Clusters with
+// failed or to be started nodes are skipped as they are being repaired.
+// Clusters with a preferred leader or a preferred region known to any of their
+// nodes are left to their preferred leaders, moving them would be reverted by
+// raft, their leaders are still counted.
+func (s *scheduler) balanceLeaders() []pb.NodeHostRequest {
+	results := make([]pb.NodeHostRequest, 0)
+	repairing := make(map[uint64]struct{})
+	for _, cr := range s.clustersToRepair {
+		repairing[cr.clusterID] = struct{}{}
+	}
+	clusterIDList := make([]uint64, 0)
+	leaders := make(map[string]int)
+	for clusterID, c := range s.multiCluster.Clusters {
+		if _, ok := repairing[clusterID]; ok {
+			continue
+		}
+		if leader, ok := getLeaderNode(c); ok {
+			leaders[leader.Address]++
+		}
+		if hasPreferredLeader(c) {
+			continue
+		}
+		clusterIDList = append(clusterIDList, clusterID)
+	}
+	sort.Slice(clusterIDList, func(i, j int) bool {
+		return clusterIDList[i] < clusterIDList[j]
+	})
+	for _, clusterID := range clusterIDList {
+		c := s.multiCluster.Clusters[clusterID]
+		leader, ok := getLeaderNode(c)
+		if !ok {
+			continue
+		}
+		var target *node
+		for _, n := range c.Nodes {
+			diff := leaders[leader.Address] - leaders[n.Address]
+			if n.NodeID == leader.NodeID || diff < leaderImbalanceThreshold {
+				continue
+			}
+			if target == nil ||
+				leaders[n.Address] < leaders[target.Address] ||
+				(leaders[n.Address] == leaders[target.Address] &&
+					n.NodeID < target.NodeID) {
+				target = n
+			}
+		}
+		if target == nil {
+			continue
+		}
+		leaders[leader.Address]--
+		leaders[target.Address]++
+		req := s.getLeaderTransferRequest(clusterID, target.NodeID, leader.Address)
+		results = append(results, req)
+		plog.Infof("scheduler generated a leader transfer request for %s, %s to %s",
+			logutil.ClusterID(clusterID), leader.Address, target.Address)
+	}
+	return results
+}
+
+func hasPreferredLeader(c *cluster) bool {
+	for _, n := range c.Nodes {
+		if n.PreferredLeader != 0 || len(n.PreferredRegion) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func getLeaderNode(c *cluster) (*node, bool) {
+	for _, n := range c.Nodes {
+		if n.IsLeader {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scheduler) getLeaderTransferRequest(clusterID uint64,
+	targetNodeID uint64, leaderAddr string) pb.NodeHostRequest {
+	change := pb.Request{
+		Type:      pb.Request_LEADER_TRANSFER,
+		ClusterId: clusterID,
+		Members:   []uint64{targetNodeID},
+	}
+	return pb.NodeHostRequest{
+		Change:      change,
+		RaftAddress: leaderAddr,
+	}
+}
+
 func (s *scheduler) getKillRequest(clusterID uint64,
 	nodeID uint64, addr string) pb.NodeHostRequest {
 	kc := pb.Request{
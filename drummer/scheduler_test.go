@@ -18,6 +18,7 @@
 package drummer
 
 import (
+	"fmt"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -608,3 +609,94 @@ func TestLaunchRequestsForLargeNumberOfClustersCanBeDelivered(t *testing.T) {
 		plog.Infof("encoded sz: %d", len(encoded))
 	}
 }
+
+func getLeaderBalanceTestMultiCluster(tick uint64) *multiCluster {
+	mc := newMultiCluster()
+	for clusterID := uint64(1); clusterID <= 3; clusterID++ {
+		c := &cluster{
+			ClusterID: clusterID,
+			Nodes:     make(map[uint64]*node),
+		}
+		for nodeID := uint64(1); nodeID <= 3; nodeID++ {
+			c.Nodes[nodeID] = &node{
+				ClusterID: clusterID,
+				NodeID:    nodeID,
+				Address:   fmt.Sprintf("a%d", nodeID),
+				IsLeader:  nodeID == 1,
+				Tick:      tick,
+			}
+		}
+		mc.Clusters[clusterID] = c
+	}
+	return mc
+}
+
+func TestLeadersCanBeBalanced(t *testing.T) {
+	tick := uint64(100)
+	mc := getLeaderBalanceTestMultiCluster(tick)
+	s := newSchedulerWithContext(nil,
+		pb.Config{}, tick, nil, mc, newMultiNodeHost())
+	reqs := s.balanceLeaders()
+	if len(reqs) != 2 {
+		t.Fatalf("got %d requests, want 2", len(reqs))
+	}
+	targets := make(map[uint64]struct{})
+	for _, req := range reqs {
+		validateNodeHostRequest(req)
+		if req.Change.Type != pb.Request_LEADER_TRANSFER {
+			t.Errorf("unexpected type %s", req.Change.Type)
+		}
+		if req.RaftAddress != "a1" {
+			t.Errorf("address %s, want a1", req.RaftAddress)
+		}
+		targets[req.Change.Members[0]] = struct{}{}
+	}
+	if _, ok := targets[2]; !ok {
+		t.Errorf("no leader moved to node 2")
+	}
+	if _, ok := targets[3]; !ok {
+		t.Errorf("no leader moved to node 3")
+	}
+}
+
+func TestBalancedLeadersAreNotMoved(t *testing.T) {
+	tick := uint64(100)
+	mc := getLeaderBalanceTestMultiCluster(tick)
+	delete(mc.Clusters, 3)
+	mc.Clusters[2].Nodes[1].IsLeader = false
+	mc.Clusters[2].Nodes[2].IsLeader = true
+	s := newSchedulerWithContext(nil,
+		pb.Config{}, tick, nil, mc, newMultiNodeHost())
+	if reqs := s.balanceLeaders(); len(reqs) != 0 {
+		t.Errorf("unexpected leader transfer requests %v", reqs)
+	}
+}
+
+func TestLeadersInClustersToRepairAreNotMoved(t *testing.T) {
+	tick := uint64(100)
+	mc := getLeaderBalanceTestMultiCluster(tick)
+	for _, c := range mc.Clusters {
+		c.Nodes[3].Tick = 1
+	}
+	s := newSchedulerWithContext(nil,
+		pb.Config{}, tick+nodeHostTTL, nil, mc, newMultiNodeHost())
+	if reqs := s.balanceLeaders(); len(reqs) != 0 {
+		t.Errorf("unexpected leader transfer requests %v", reqs)
+	}
+}
+
+func TestLeadersWithPreferredLeaderOrRegionAreNotMoved(t *testing.T) {
+	tick := uint64(100)
+	mc := getLeaderBalanceTestMultiCluster(tick)
+	mc.Clusters[1].Nodes[1].PreferredLeader = 1
+	mc.Clusters[2].Nodes[3].PreferredRegion = []uint64{1, 2}
+	s := newSchedulerWithContext(nil,
+		pb.Config{}, tick, nil, mc, newMultiNodeHost())
+	reqs := s.balanceLeaders()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	if reqs[0].Change.ClusterId != 3 {
+		t.Errorf("leader of cluster %d moved", reqs[0].Change.ClusterId)
+	}
+}
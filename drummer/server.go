@@ -231,6 +231,8 @@ func waitDrummerRequestResult(ctx context.Context,
 			return nil, dragonboat.ErrTimeout
 		} else if r.Terminated() {
 			return nil, dragonboat.ErrClusterClosed
+		} else if r.Dropped() {
+			return nil, dragonboat.ErrClusterNotReady
 		}
 		plog.Panicf("unknown v code")
 	case <-ctx.Done():
@@ -500,7 +502,11 @@ func (s *server) proposeDrummerUpdate(ctx context.Context,
 	if err != nil {
 		panic(err)
 	}
-	return s.nh.SyncPropose(ctx, session, data)
+	result, err := s.nh.SyncPropose(ctx, session, data)
+	if err != nil {
+		return 0, err
+	}
+	return result.Value, nil
 }
 
 func (s *server) proposeFinalizedKV(ctx context.Context,
@@ -44,7 +44,8 @@ func validateNodeHostRequest(req pb.NodeHostRequest) {
 	}
 	if req.Change.Type == pb.Request_ADD ||
 		req.Change.Type == pb.Request_DELETE ||
-		req.Change.Type == pb.Request_KILL {
+		req.Change.Type == pb.Request_KILL ||
+		req.Change.Type == pb.Request_LEADER_TRANSFER {
 		if len(req.Change.Members) == 0 {
 			plog.Panicf("len(req.Change.Members) == 0")
 		}
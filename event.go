@@ -0,0 +1,148 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dragonboat
+
+import (
+	"sync"
+
+	"github.com/lni/dragonboat/internal/utils/syncutil"
+	"github.com/lni/dragonboat/raftio"
+)
+
+// raftEventListener queues raised events and delivers them to the user
+// specified raftio.IRaftEventListener on a dedicated worker goroutine. The
+// queue is unbounded so raising an event never blocks the caller. All
+// methods can be invoked on a nil raftEventListener, nil is used when there
+// is no user specified listener.
+type raftEventListener struct {
+	listener raftio.IRaftEventListener
+	stopper  *syncutil.Stopper
+	notifyC  chan struct{}
+	mu       struct {
+		sync.Mutex
+		events []func()
+	}
+}
+
+var _ raftio.IRaftEventListener = (*raftEventListener)(nil)
+
+func newRaftEventListener(l raftio.IRaftEventListener) *raftEventListener {
+	if l == nil {
+		return nil
+	}
+	el := &raftEventListener{
+		listener: l,
+		stopper:  syncutil.NewStopper(),
+		notifyC:  make(chan struct{}, 1),
+	}
+	el.stopper.RunWorker(func() {
+		el.worker()
+	})
+	return el
+}
+
+// stop stops the worker goroutine after all queued events are delivered.
+func (el *raftEventListener) stop() {
+	if el != nil {
+		el.stopper.Stop()
+	}
+}
+
+func (el *raftEventListener) worker() {
+	for {
+		select {
+		case <-el.stopper.ShouldStop():
+			el.deliver()
+			return
+		case <-el.notifyC:
+			el.deliver()
+		}
+	}
+}
+
+func (el *raftEventListener) deliver() {
+	for {
+		el.mu.Lock()
+		events := el.mu.events
+		el.mu.events = nil
+		el.mu.Unlock()
+		if len(events) == 0 {
+			return
+		}
+		for _, f := range events {
+			f()
+		}
+	}
+}
+
+func (el *raftEventListener) raise(f func()) {
+	if el == nil {
+		return
+	}
+	el.mu.Lock()
+	el.mu.events = append(el.mu.events, f)
+	el.mu.Unlock()
+	select {
+	case el.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+func (el *raftEventListener) LeaderUpdated(clusterID uint64,
+	nodeID uint64, term uint64, leaderID uint64) {
+	el.raise(func() {
+		el.listener.LeaderUpdated(clusterID, nodeID, term, leaderID)
+	})
+}
+
+func (el *raftEventListener) MembershipChanged(clusterID uint64,
+	nodeID uint64) {
+	el.raise(func() {
+		el.listener.MembershipChanged(clusterID, nodeID)
+	})
+}
+
+func (el *raftEventListener) SnapshotCreated(clusterID uint64,
+	nodeID uint64, index uint64) {
+	el.raise(func() {
+		el.listener.SnapshotCreated(clusterID, nodeID, index)
+	})
+}
+
+func (el *raftEventListener) SnapshotRecovered(clusterID uint64,
+	nodeID uint64, index uint64) {
+	el.raise(func() {
+		el.listener.SnapshotRecovered(clusterID, nodeID, index)
+	})
+}
+
+func (el *raftEventListener) SnapshotReceived(clusterID uint64,
+	nodeID uint64, from uint64, index uint64) {
+	el.raise(func() {
+		el.listener.SnapshotReceived(clusterID, nodeID, from, index)
+	})
+}
+
+func (el *raftEventListener) NodeUnloaded(clusterID uint64, nodeID uint64) {
+	el.raise(func() {
+		el.listener.NodeUnloaded(clusterID, nodeID)
+	})
+}
+
+func (el *raftEventListener) ConnectionFailed(address string, snapshot bool) {
+	el.raise(func() {
+		el.listener.ConnectionFailed(address, snapshot)
+	})
+}
@@ -0,0 +1,148 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !dragonboat_slowtest
+// +build !dragonboat_errorinjectiontest
+
+package dragonboat
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testRaftEventListener struct {
+	mu      sync.Mutex
+	events  []string
+	blocked chan struct{}
+}
+
+func (l *testRaftEventListener) add(event string) {
+	if l.blocked != nil {
+		<-l.blocked
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *testRaftEventListener) getEvents() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.events...)
+}
+
+func (l *testRaftEventListener) LeaderUpdated(clusterID uint64,
+	nodeID uint64, term uint64, leaderID uint64) {
+	l.add(fmt.Sprintf("leader %d %d %d %d", clusterID, nodeID, term, leaderID))
+}
+
+func (l *testRaftEventListener) MembershipChanged(clusterID uint64,
+	nodeID uint64) {
+	l.add(fmt.Sprintf("membership %d %d", clusterID, nodeID))
+}
+
+func (l *testRaftEventListener) SnapshotCreated(clusterID uint64,
+	nodeID uint64, index uint64) {
+	l.add(fmt.Sprintf("created %d %d %d", clusterID, nodeID, index))
+}
+
+func (l *testRaftEventListener) SnapshotRecovered(clusterID uint64,
+	nodeID uint64, index uint64) {
+	l.add(fmt.Sprintf("recovered %d %d %d", clusterID, nodeID, index))
+}
+
+func (l *testRaftEventListener) SnapshotReceived(clusterID uint64,
+	nodeID uint64, from uint64, index uint64) {
+	l.add(fmt.Sprintf("received %d %d %d %d", clusterID, nodeID, from, index))
+}
+
+func (l *testRaftEventListener) NodeUnloaded(clusterID uint64, nodeID uint64) {
+	l.add(fmt.Sprintf("unloaded %d %d", clusterID, nodeID))
+}
+
+func (l *testRaftEventListener) ConnectionFailed(address string,
+	snapshot bool) {
+	l.add(fmt.Sprintf("connection %s %t", address, snapshot))
+}
+
+func TestRaftEventListenerCanBeUsedWhenNotSet(t *testing.T) {
+	el := newRaftEventListener(nil)
+	if el != nil {
+		t.Fatalf("listener unexpectedly created")
+	}
+	el.LeaderUpdated(1, 2, 3, 4)
+	el.MembershipChanged(1, 2)
+	el.SnapshotCreated(1, 2, 3)
+	el.SnapshotRecovered(1, 2, 3)
+	el.SnapshotReceived(1, 2, 3, 4)
+	el.NodeUnloaded(1, 2)
+	el.ConnectionFailed("localhost:9090", true)
+	el.stop()
+}
+
+func TestRaftEventsAreDeliveredInOrder(t *testing.T) {
+	l := &testRaftEventListener{}
+	el := newRaftEventListener(l)
+	el.LeaderUpdated(1, 2, 3, 4)
+	el.MembershipChanged(1, 2)
+	el.SnapshotCreated(1, 2, 3)
+	el.SnapshotRecovered(1, 2, 3)
+	el.SnapshotReceived(1, 2, 3, 4)
+	el.NodeUnloaded(1, 2)
+	el.ConnectionFailed("localhost:9090", true)
+	el.stop()
+	expected := []string{
+		"leader 1 2 3 4",
+		"membership 1 2",
+		"created 1 2 3",
+		"recovered 1 2 3",
+		"received 1 2 3 4",
+		"unloaded 1 2",
+		"connection localhost:9090 true",
+	}
+	events := l.getEvents()
+	if len(events) != len(expected) {
+		t.Fatalf("got %d events, want %d", len(events), len(expected))
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d, got %s, want %s", i, events[i], expected[i])
+		}
+	}
+}
+
+func TestSlowRaftEventListenerDoesNotBlockCaller(t *testing.T) {
+	l := &testRaftEventListener{blocked: make(chan struct{})}
+	el := newRaftEventListener(l)
+	done := make(chan struct{})
+	go func() {
+		for i := uint64(0); i < 1000; i++ {
+			el.LeaderUpdated(1, 2, i, 3)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("raising events blocked by the listener")
+	}
+	close(l.blocked)
+	el.stop()
+	if len(l.getEvents()) != 1000 {
+		t.Errorf("got %d events, want 1000", len(l.getEvents()))
+	}
+}
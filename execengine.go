@@ -17,6 +17,7 @@ package dragonboat
 import (
 	"time"
 
+	"github.com/lni/dragonboat/internal/metrics"
 	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/server"
 	"github.com/lni/dragonboat/internal/settings"
@@ -99,11 +100,14 @@ type execEngine struct {
 	commitWorkReady            *workReady
 	snapshotWorkReady          *workReady
 	requestedSnapshotWorkReady *workReady
+	streamSnapshotWorkReady    *workReady
 	sendLocalMsg               sendLocalMessageFunc
+	metrics                    *engineMetrics
 }
 
 func newExecEngine(nh nodeLoader, ctx *server.Context,
-	logdb raftio.ILogDB, sendLocalMsg sendLocalMessageFunc) *execEngine {
+	logdb raftio.ILogDB, sendLocalMsg sendLocalMessageFunc,
+	registry *metrics.Registry) *execEngine {
 	s := &execEngine{
 		nh:                         nh,
 		ctx:                        ctx,
@@ -115,9 +119,11 @@ func newExecEngine(nh nodeLoader, ctx *server.Context,
 		commitWorkReady:            newWorkReady(commitWorkerCount),
 		snapshotWorkReady:          newWorkReady(snapshotWorkerCount),
 		requestedSnapshotWorkReady: newWorkReady(snapshotWorkerCount),
+		streamSnapshotWorkReady:    newWorkReady(snapshotWorkerCount),
 		ctxs:                       make([]raftio.IContext, workerCount),
 		profilers:                  make([]*profiler, workerCount),
 		sendLocalMsg:               sendLocalMsg,
+		metrics:                    newEngineMetrics(registry),
 	}
 	sampleRatio := int64(delaySampleRatio / 10)
 	for i := uint64(1); i <= workerCount; i++ {
@@ -218,6 +224,15 @@ func (s *execEngine) snapshotWorkerMain(workerID uint64) {
 					return
 				}
 			}
+		case <-s.streamSnapshotWorkReady.waitCh(workerID):
+			clusterIDMap := s.streamSnapshotWorkReady.getReadyMap(workerID)
+			for clusterID := range clusterIDMap {
+				nodes, cci = s.loadSnapshotNodes(workerID, cci, nodes)
+				s.streamSnapshot(clusterID, nodes)
+				if s.snapshotWorkerClosed(nodes) {
+					return
+				}
+			}
 		}
 	}
 }
@@ -234,14 +249,25 @@ func (s *execEngine) saveSnapshot(clusterID uint64,
 	if !ok {
 		return
 	}
-	if _, ok := node.ss.getSaveSnapshotReq(); !ok {
+	rec, ok := node.ss.getSaveSnapshotReq()
+	if !ok {
 		return
 	}
 	plog.Infof("%s called saveSnapshot", node.describe())
-	node.saveSnapshot()
+	node.saveSnapshot(rec)
 	node.saveSnapshotDone()
 }
 
+func (s *execEngine) streamSnapshot(clusterID uint64,
+	nodes map[uint64]*node) {
+	node, ok := nodes[clusterID]
+	if !ok {
+		return
+	}
+	plog.Infof("%s called streamSnapshot", node.describe())
+	node.streamSnapshot()
+}
+
 func (s *execEngine) recoverFromSnapshot(clusterID uint64,
 	nodes map[uint64]*node) {
 	node, ok := nodes[clusterID]
@@ -364,6 +390,7 @@ func (s *execEngine) execSMs(workerID uint64,
 			idmap[k] = struct{}{}
 		}
 	}
+	start := time.Now()
 	var p *profiler
 	if workerCount == commitWorkerCount {
 		p = s.profilers[workerID-1]
@@ -405,6 +432,7 @@ func (s *execEngine) execSMs(workerID uint64,
 					node.ss.setTakingSnapshot()
 				} else {
 					plog.Infof("commit.SnapshotRequested ignored on %s", node.describe())
+					node.snapshotRequestDone(commit.SnapshotRequest, 0)
 					continue
 				}
 				s.reportRequestedSnapshot(node, commit)
@@ -416,6 +444,7 @@ func (s *execEngine) execSMs(workerID uint64,
 	if p != nil {
 		p.exec.end()
 	}
+	s.metrics.apply.ObserveSince(start)
 }
 
 func (s *execEngine) reportRequestedSnapshot(node *node,
@@ -500,6 +529,7 @@ func (s *execEngine) execNodes(workerID uint64,
 	p := s.profilers[workerID-1]
 	p.newIteration()
 	p.step.start()
+	stepStart := time.Now()
 	if len(clusterIDMap) == 0 {
 		for cid := range nodes {
 			clusterIDMap[cid] = struct{}{}
@@ -532,16 +562,20 @@ func (s *execEngine) execNodes(workerID uint64,
 		node := nodes[ud.ClusterID]
 		node.sendAppendMessages(ud)
 		node.processReadyToRead(ud)
+		node.processDroppedRequests(ud)
 	}
 	p.step.end()
+	s.metrics.step.ObserveSince(stepStart)
 	p.recordEntryCount(nodeUpdates)
 	if readyToReturnTestKnob(stopC, "saving raft state") {
 		return
 	}
 	p.save.start()
+	saveStart := time.Now()
 	if err := s.logdb.SaveRaftState(nodeUpdates, nodeCtx); err != nil {
 		panic(err)
 	}
+	s.metrics.raftStateSaved(saveStart, nodeUpdates)
 	p.save.end()
 	if readyToReturnTestKnob(stopC, "saving snapshots") {
 		return
@@ -559,6 +593,7 @@ func (s *execEngine) execNodes(workerID uint64,
 		return
 	}
 	p.cs.start()
+	commitStart := time.Now()
 	for _, ud := range nodeUpdates {
 		node := nodes[ud.ClusterID]
 		if !node.processRaftUpdate(ud) {
@@ -566,12 +601,16 @@ func (s *execEngine) execNodes(workerID uint64,
 				node.describe())
 		}
 		s.processRaftUpdate(ud)
+		if node.hasStreamSnapshotRequest() {
+			s.streamSnapshotWorkReady.clusterReady(node.clusterID)
+		}
 		if readyToReturnTestKnob(stopC, "committing updates") {
 			return
 		}
 		node.commitRaftUpdate(ud)
 	}
 	p.cs.end()
+	s.metrics.commit.ObserveSince(commitStart)
 	if lazyFreeCycle > 0 {
 		resetNodeUpdate(nodeUpdates)
 	}
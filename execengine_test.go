@@ -195,7 +195,7 @@ func TestTakingSnapshotOnUninitializedNodeWillPanic(t *testing.T) {
 func TestNotReadyTakingSnapshotNodeIsSkippedWhenConcurrencyIsNotSupported(t *testing.T) {
 	n := &node{ss: &snapshotState{}}
 	n.sm = rsm.NewStateMachine(
-		rsm.NewNativeStateMachine(&rsm.RegularStateMachine{}, nil), nil, false, nil)
+		rsm.NewNativeStateMachine(&rsm.RegularStateMachine{}, nil), nil, false, false, nil, nil)
 	if n.concurrentSnapshot() {
 		t.Errorf("concurrency not suppose to be supported")
 	}
@@ -209,7 +209,7 @@ func TestNotReadyTakingSnapshotNodeIsSkippedWhenConcurrencyIsNotSupported(t *tes
 func TestNotReadyTakingSnapshotNodeIsNotSkippedWhenConcurrencyIsSupported(t *testing.T) {
 	n := &node{ss: &snapshotState{}}
 	n.sm = rsm.NewStateMachine(
-		rsm.NewNativeStateMachine(&rsm.ConcurrentStateMachine{}, nil), nil, false, nil)
+		rsm.NewNativeStateMachine(&rsm.ConcurrentStateMachine{}, nil), nil, false, false, nil, nil)
 	if !n.concurrentSnapshot() {
 		t.Errorf("concurrency not supported")
 	}
@@ -228,7 +228,7 @@ func (ds *StateMachineWrapper) BatchedUpdate(ents []sm.Entry) []sm.Entry {
 // Update updates the data store.
 func (ds *StateMachineWrapper) Update(session *rsm.Session,
 	seriesID uint64, index uint64, term uint64,
-	data []byte) uint64 {
+	data []byte) sm.Result {
 	ds.ensureNotDestroyed()
 	var dp *C.uchar
 	dp = nil
@@ -239,10 +239,11 @@ func (ds *StateMachineWrapper) Update(session *rsm.Session,
 		ds.MustHaveClientSeries(session, seriesID)
 	}
 	v := C.UpdateDBStateMachine(ds.dataStore, dp, C.size_t(len(data)))
+	result := sm.Result{Value: uint64(v)}
 	if session != nil {
-		ds.AddResponse(session, seriesID, uint64(v))
+		ds.AddResponse(session, seriesID, result)
 	}
-	return uint64(v)
+	return result
 }
 
 // Lookup queries the data store.
@@ -313,7 +314,7 @@ func (ds *StateMachineWrapper) SaveSnapshot(ctx interface{},
 		plog.Errorf("save header failed %v", err)
 		return 0, err
 	}
-	return uint64(r.size) + smsz + rsm.SnapshotHeaderSize, nil
+	return writer.GetPayloadSize(sz+smsz) + rsm.SnapshotHeaderSize, nil
 }
 
 // ConcurrentSnapshot returns a boolean flag indicating whether the state
@@ -322,6 +323,24 @@ func (ds *StateMachineWrapper) ConcurrentSnapshot() bool {
 	return false
 }
 
+// OnDiskStateMachine returns a boolean flag indicating whether the state
+// machine is an on disk state machine. On disk state machine is not supported
+// in the C++ wrapper.
+func (ds *StateMachineWrapper) OnDiskStateMachine() bool {
+	return false
+}
+
+// Open opens the on disk state machine. This method is not supported in the
+// C++ wrapper.
+func (ds *StateMachineWrapper) Open() (uint64, error) {
+	panic("not supported")
+}
+
+// Sync synchronizes the in-core state with that on disk.
+func (ds *StateMachineWrapper) Sync() error {
+	return nil
+}
+
 // RecoverFromSnapshot recovers the state of the data store from the snapshot
 // file specified by the fp input string.
 func (ds *StateMachineWrapper) RecoverFromSnapshot(fp string,
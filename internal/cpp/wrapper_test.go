@@ -28,6 +28,7 @@ import (
 	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/tests/kvpb"
 	"github.com/lni/dragonboat/internal/utils/leaktest"
+	pb "github.com/lni/dragonboat/raftpb"
 )
 
 func TestManagedObjectCanBeAddedReturnedAndRemoved(t *testing.T) {
@@ -201,7 +202,7 @@ func TestCppSnapshotWorks(t *testing.T) {
 	if v2 != v1+1 || v3 != v2+1 {
 		t.Errorf("Unexpected update result")
 	}
-	writer, err := rsm.NewSnapshotWriter(fp)
+	writer, err := rsm.NewSnapshotWriter(fp, pb.NoCompression)
 	if err != nil {
 		t.Fatalf("failed to create snapshot writer %v", err)
 	}
@@ -15,9 +15,158 @@
 package logdb
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lni/dragonboat/internal/utils/fileutil"
 	"github.com/lni/dragonboat/raftio"
 )
 
+const (
+	// RocksDBKVStoreType is the type name of the RocksDB based key-value store.
+	RocksDBKVStoreType = "rocksdb"
+	// LevelDBKVStoreType is the type name of the LevelDB based key-value store.
+	LevelDBKVStoreType = "leveldb"
+	// PebbleKVStoreType is the type name of the Pebble based key-value store.
+	PebbleKVStoreType = "pebble"
+)
+
+// kvStoreFactory is the factory function used for creating IKvStore instances.
+// When readOnly is true, the key-value store must already exist and it should
+// be opened without modifying it where the key-value store supports that.
+type kvStoreFactory func(dir string, wal string, readOnly bool) (IKvStore, error)
+
+var (
+	// kvStoreFactories contains all key-value stores included in the build.
+	// LevelDB and Pebble are always included, RocksDB is excluded when the
+	// dragonboat_no_rocksdb or the dragonboat_custom_logdb build tag is set.
+	kvStoreFactories = make(map[string]kvStoreFactory)
+	// defaultKVStoreTypes is the list of key-value store types in their order
+	// of preference when no key-value store type is specified.
+	defaultKVStoreTypes = []string{
+		RocksDBKVStoreType,
+		LevelDBKVStoreType,
+		PebbleKVStoreType,
+	}
+)
+
+// registerKVStore is called by key-value store implementations to make them
+// selectable at runtime.
+func registerKVStore(kvType string, f kvStoreFactory) {
+	if _, ok := kvStoreFactories[kvType]; ok {
+		panic(fmt.Sprintf("kv store %s already registered", kvType))
+	}
+	kvStoreFactories[kvType] = f
+}
+
+// GetKVStoreTypes returns the types of all key-value stores included in the
+// build.
+func GetKVStoreTypes() []string {
+	types := make([]string, 0)
+	for kvType := range kvStoreFactories {
+		types = append(types, kvType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// GetKVStoreType returns the type of the key-value store to be used when the
+// specified kvType is requested. The default key-value store type is returned
+// when kvType is empty. An error is returned when the requested key-value
+// store is not included in the build.
+func GetKVStoreType(kvType string) (string, error) {
+	if len(kvType) == 0 {
+		for _, t := range defaultKVStoreTypes {
+			if _, ok := kvStoreFactories[t]; ok {
+				return t, nil
+			}
+		}
+		return "", errors.New("no LogDB kv store included in the build")
+	}
+	if _, ok := kvStoreFactories[kvType]; !ok {
+		return "", fmt.Errorf("LogDB kv store %s not included in the build, "+
+			"available kv stores %v", kvType, GetKVStoreTypes())
+	}
+	return kvType, nil
+}
+
+// CheckKVStoreType returns an error when the default LogDB found in dir was
+// obviously not created by the specified type of key-value store. It is used
+// when the key-value store type has not been recorded for the LogDB. RocksDB
+// always persists its options in OPTIONS files while LevelDB never does, only
+// these two key-value stores are told apart.
+func CheckKVStoreType(dir string, kvType string) error {
+	shardDir := filepath.Join(dir, getShardDirName(0))
+	if !fileutil.Exist(filepath.Join(shardDir, "CURRENT")) {
+		return nil
+	}
+	files, err := ioutil.ReadDir(shardDir)
+	if err != nil {
+		return err
+	}
+	hasOptions := false
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "OPTIONS-") {
+			hasOptions = true
+		}
+	}
+	if (kvType == LevelDBKVStoreType && hasOptions) ||
+		(kvType == RocksDBKVStoreType && !hasOptions) {
+		return fmt.Errorf("LogDB in %s was not created by %s", dir, kvType)
+	}
+	return nil
+}
+
+func newKVStore(kvType string,
+	dir string, wal string, readOnly bool) (IKvStore, error) {
+	kvType, err := GetKVStoreType(kvType)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly && !fileutil.Exist(dir) {
+		return nil, fmt.Errorf("%s kv store dir %s does not exist", kvType, dir)
+	}
+	kvs, err := kvStoreFactories[kvType](dir, wal, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		return &readOnlyKVStore{kvs}, nil
+	}
+	return kvs, nil
+}
+
+// readOnlyKVStore is an IKvStore wrapper that rejects all updates, not all
+// key-value stores can be opened in a read only mode.
+type readOnlyKVStore struct {
+	IKvStore
+}
+
+func (r *readOnlyKVStore) SaveValue(key []byte, value []byte) error {
+	return errReadOnlyLogDB
+}
+
+func (r *readOnlyKVStore) DeleteValue(key []byte) error {
+	return errReadOnlyLogDB
+}
+
+func (r *readOnlyKVStore) CommitWriteBatch(wb IWriteBatch) error {
+	return errReadOnlyLogDB
+}
+
+func (r *readOnlyKVStore) RemoveEntries(firstKey []byte, lastKey []byte) error {
+	return errReadOnlyLogDB
+}
+
+func (r *readOnlyKVStore) Compaction(firstKey []byte, lastKey []byte) error {
+	return errReadOnlyLogDB
+}
+
 // IKvStore is the interface used by the RDB struct to access the underlying
 // Key-Value store.
 type IKvStore interface {
@@ -53,6 +202,30 @@ type IKvStore interface {
 	// store for the specified range [firstKey, lastKey). This method is expected
 	// to complete in the order of seconds.
 	Compaction(firstKey []byte, lastKey []byte) error
+	// GetStats returns the statistics of the key-value store.
+	GetStats() KVStats
+}
+
+// KVStats is the statistics reported by a key-value store. Values not
+// supported by the key-value store are reported as 0.
+type KVStats struct {
+	// PendingCompactionBytes is the estimated number of bytes that need to be
+	// rewritten by compactions.
+	PendingCompactionBytes uint64
+	// MemtableSize is the size of all memtables in bytes.
+	MemtableSize uint64
+	// SSTFileCount is the number of live SST files.
+	SSTFileCount uint64
+}
+
+// parseKVProperty parses the integer property value returned by RocksDB and
+// LevelDB, 0 is returned when the property is not supported.
+func parseKVProperty(v string) uint64 {
+	result, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return result
 }
 
 // IWriteBatch is the interface representing a write batch capable of
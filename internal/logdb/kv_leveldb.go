@@ -12,20 +12,19 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build dragonboat_leveldb
-
 package logdb
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/lni/dragonboat/internal/logdb/levigo"
 	"github.com/lni/dragonboat/raftio"
 )
 
 const (
-	// LogDBType is the logdb type name
-	LogDBType = "sharded-leveldb"
+	// leveldbNumLevels is the number of levels used by LevelDB.
+	leveldbNumLevels = 7
 )
 
 type leveldbWriteBatch struct {
@@ -51,8 +50,11 @@ func (w *leveldbWriteBatch) Count() int {
 	return w.count
 }
 
-func newKVStore(dir string, wal string) (IKvStore, error) {
-	return openLevelDB(dir, wal)
+func init() {
+	registerKVStore(LevelDBKVStoreType,
+		func(dir string, wal string, readOnly bool) (IKvStore, error) {
+			return openLevelDB(dir, wal, readOnly)
+		})
 }
 
 type leveldbKV struct {
@@ -62,9 +64,11 @@ type leveldbKV struct {
 	wo   *levigo.WriteOptions
 }
 
-func openLevelDB(dir string, wal string) (*leveldbKV, error) {
+// openLevelDB opens the LevelDB instance in dir. LevelDB has no read only
+// mode, when readOnly is true it is only prevented from creating a new DB.
+func openLevelDB(dir string, wal string, readOnly bool) (*leveldbKV, error) {
 	opts := levigo.NewOptions()
-	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissing(!readOnly)
 	filter := levigo.NewBloomFilter(10)
 	opts.SetFilterPolicy(filter)
 	db, err := levigo.Open(dir, opts)
@@ -88,6 +92,20 @@ func (r *leveldbKV) Name() string {
 	return "leveldb"
 }
 
+// GetStats returns the statistics of the LevelDB instance. LevelDB doesn't
+// report its pending compaction bytes.
+func (r *leveldbKV) GetStats() KVStats {
+	stats := KVStats{
+		MemtableSize: parseKVProperty(
+			r.db.PropertyValue("leveldb.approximate-memory-usage")),
+	}
+	for i := 0; i < leveldbNumLevels; i++ {
+		p := fmt.Sprintf("leveldb.num-files-at-level%d", i)
+		stats.SSTFileCount += parseKVProperty(r.db.PropertyValue(p))
+	}
+	return stats
+}
+
 // Close closes the RDB object.
 func (r *leveldbKV) Close() error {
 	if r.db != nil {
@@ -106,7 +124,7 @@ func (r *leveldbKV) Close() error {
 	return nil
 }
 
-func iteratorIsValid(iter *levigo.Iterator) bool {
+func leveldbIteratorIsValid(iter *levigo.Iterator) bool {
 	v := iter.Valid()
 	if err := iter.GetError(); err != nil {
 		panic(err)
@@ -118,7 +136,7 @@ func (r *leveldbKV) IterateValue(fk []byte, lk []byte, inc bool,
 	op func(key []byte, data []byte) (bool, error)) {
 	iter := r.db.NewIterator(r.ro)
 	defer iter.Close()
-	for iter.Seek(fk); iteratorIsValid(iter); iter.Next() {
+	for iter.Seek(fk); leveldbIteratorIsValid(iter); iter.Next() {
 		key := iter.Key()
 		val := iter.Value()
 		if inc {
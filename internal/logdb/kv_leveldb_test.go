@@ -13,6 +13,7 @@
 // limitations under the License.
 
 // +build dragonboat_leveldb
+// +build !dragonboat_pebble
 
 package logdb
 
@@ -32,6 +33,12 @@ import (
 	pb "github.com/lni/dragonboat/raftpb"
 )
 
+func init() {
+	// tests in this package use LevelDB as the default key-value store even when
+	// RocksDB is included in the build
+	defaultKVStoreTypes = []string{LevelDBKVStoreType}
+}
+
 func modifyLogDBContent(fp string) bool {
 	idx := int64(0)
 	f, err := os.OpenFile(fp, os.O_RDWR, 0755)
@@ -246,7 +253,7 @@ func TestIteratorWithDiskCorruptionHandled(t *testing.T) {
 		iter := rdb.kvs.(*leveldbKV).db.NewIterator(rdb.kvs.(*leveldbKV).ro)
 		defer iter.Close()
 		iter.Seek(fk.key)
-		for ; iteratorIsValid(iter); iter.Next() {
+		for ; leveldbIteratorIsValid(iter); iter.Next() {
 			val := iter.Value()
 			var e pb.Entry
 			if err := e.Unmarshal(val); err != nil {
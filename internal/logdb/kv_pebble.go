@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build dragonboat_pebble
-
 package logdb
 
 // WARNING: pebble support is expermental, DO NOT USE IT IN PRODUCTION.
@@ -30,11 +28,6 @@ import (
 	"github.com/petermattis/pebble/db"
 )
 
-const (
-	// LogDBType is the logdb type name
-	LogDBType = "sharded-pebble"
-)
-
 type pebbleWriteBatch struct {
 	wb    *pebble.Batch
 	db    *pebble.DB
@@ -60,8 +53,13 @@ func (w *pebbleWriteBatch) Count() int {
 	return w.count
 }
 
-func newKVStore(dir string, wal string) (IKvStore, error) {
-	return openPebbleDB(dir)
+func init() {
+	registerKVStore(PebbleKVStoreType,
+		func(dir string, wal string, readOnly bool) (IKvStore, error) {
+			// pebble has no read only mode, updates are rejected by the caller
+			// when readOnly is true
+			return openPebbleDB(dir)
+		})
 }
 
 type pebbleKV struct {
@@ -93,6 +91,14 @@ func (r *pebbleKV) Name() string {
 	return "pebble"
 }
 
+// GetStats returns the statistics of the Pebble instance. The Pebble version
+// used by dragonboat doesn't expose its metrics, all values are reported as 0.
+// SST files found in the Pebble dir are not counted as obsolete SST files are
+// only removed from the dir after compactions.
+func (r *pebbleKV) GetStats() KVStats {
+	return KVStats{}
+}
+
 // Close closes the RDB object.
 func (r *pebbleKV) Close() error {
 	if r.db != nil {
@@ -102,7 +108,7 @@ func (r *pebbleKV) Close() error {
 	return nil
 }
 
-func iteratorIsValid(iter *pebble.Iterator) bool {
+func pebbleIteratorIsValid(iter *pebble.Iterator) bool {
 	v := iter.Valid()
 	if err := iter.Error(); err != nil {
 		panic(err)
@@ -114,7 +120,7 @@ func (r *pebbleKV) IterateValue(fk []byte, lk []byte, inc bool,
 	op func(key []byte, data []byte) (bool, error)) {
 	iter := r.db.NewIter(r.ro)
 	defer iter.Close()
-	for iter.SeekGE(fk); iteratorIsValid(iter); iter.Next() {
+	for iter.SeekGE(fk); pebbleIteratorIsValid(iter); iter.Next() {
 		key := iter.Key()
 		val := iter.Value()
 		if inc {
@@ -13,6 +13,7 @@
 // limitations under the License.
 
 // +build dragonboat_pebble
+// +build !dragonboat_leveldb
 
 package logdb
 
@@ -31,6 +32,12 @@ import (
 	pb "github.com/lni/dragonboat/raftpb"
 )
 
+func init() {
+	// tests in this package use Pebble as the default key-value store even when
+	// RocksDB is included in the build
+	defaultKVStoreTypes = []string{PebbleKVStoreType}
+}
+
 func testCompactRangeWithCompactionFilterWorks(t *testing.T,
 	f func(raftio.ILogDB, uint64, uint64, uint64)) {
 	dir := "compaction-db-dir"
@@ -245,7 +252,7 @@ func TestIteratorWithDiskCorruptionHandled(t *testing.T) {
 		fk.SetEntryKey(3, 4, 10)
 		iter := rdb.kvs.(*pebbleKV).db.NewIter(rdb.kvs.(*pebbleKV).ro)
 		iter.SeekGE(fk.key)
-		for ; iteratorIsValid(iter); iter.Next() {
+		for ; pebbleIteratorIsValid(iter); iter.Next() {
 			val := iter.Value()
 			var e pb.Entry
 			if err := e.Unmarshal(val); err != nil {
@@ -12,14 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build !dragonboat_leveldb
-// +build !dragonboat_pebble
-// +build !dragonboat_custom_logdb
+// +build !dragonboat_no_rocksdb,!dragonboat_custom_logdb
 
 package logdb
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/lni/dragonboat/internal/logdb/gorocksdb"
 	"github.com/lni/dragonboat/internal/settings"
@@ -27,19 +26,22 @@ import (
 	"github.com/lni/dragonboat/raftio"
 )
 
-const (
-	// LogDBType is the logdb type name
-	LogDBType = "sharded-rocksdb"
-)
-
 var (
 	logDBLRUCacheSize        = int(settings.Soft.RDBLRUCacheSize)
 	maxBackgroundCompactions = int(settings.Soft.RDBMaxBackgroundCompactions)
 	maxBackgroundFlushes     = int(settings.Soft.RDBMaxBackgroundFlushes)
 )
 
-func newKVStore(dir string, wal string) (IKvStore, error) {
-	return openRocksDB(dir, wal)
+const (
+	// rocksdbNumLevels is the number of levels used by RocksDB.
+	rocksdbNumLevels = 7
+)
+
+func init() {
+	registerKVStore(RocksDBKVStoreType,
+		func(dir string, wal string, readOnly bool) (IKvStore, error) {
+			return openRocksDB(dir, wal, readOnly)
+		})
 }
 
 type rocksdbKV struct {
@@ -113,7 +115,7 @@ func getRocksDBOptions(directory string,
 	opts.SetLevel0SlowdownWritesTrigger(17)
 	opts.SetLevel0StopWritesTrigger(24)
 	opts.SetMaxWriteBufferNumber(25)
-	opts.SetNumLevels(7)
+	opts.SetNumLevels(rocksdbNumLevels)
 	// MaxBytesForLevelBase is the total size of L1, should be close to the size
 	// of L0
 	opts.SetMaxBytesForLevelBase(4 * 1024 * 1024 * 1024)
@@ -127,7 +129,7 @@ func getRocksDBOptions(directory string,
 	return opts, bbto, cache
 }
 
-func iteratorIsValid(iter *gorocksdb.Iterator) bool {
+func rocksdbIteratorIsValid(iter *gorocksdb.Iterator) bool {
 	v, err := iter.IsValid()
 	if err != nil {
 		panic(err)
@@ -135,21 +137,28 @@ func iteratorIsValid(iter *gorocksdb.Iterator) bool {
 	return v
 }
 
-func openRocksDB(dir string, wal string) (*rocksdbKV, error) {
+func openRocksDB(dir string, wal string, readOnly bool) (*rocksdbKV, error) {
 	// gorocksdb.OpenDb allows the main db directory to be created on open
 	// but WAL directory must exist before calling Open.
-	if len(wal) > 0 && !fileutil.Exist(wal) {
+	if !readOnly && len(wal) > 0 && !fileutil.Exist(wal) {
 		if err := fileutil.MkdirAll(wal); err != nil {
 			plog.Panicf("cannot create dir for RDB WAL (%v)", err)
 		}
 	}
-	if !fileutil.Exist(dir) {
+	if !readOnly && !fileutil.Exist(dir) {
 		if err := fileutil.MkdirAll(dir); err != nil {
 			plog.Panicf("cannot create dir (%v)", err)
 		}
 	}
 	opts, bbto, cache := getRocksDBOptions(dir, wal)
-	db, err := gorocksdb.OpenDb(opts, dir)
+	var db *gorocksdb.DB
+	var err error
+	if readOnly {
+		opts.SetCreateIfMissing(false)
+		db, err = gorocksdb.OpenDbForReadOnly(opts, dir, false)
+	} else {
+		db, err = gorocksdb.OpenDb(opts, dir)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +183,21 @@ func (r *rocksdbKV) Name() string {
 	return "rocksdb"
 }
 
+// GetStats returns the statistics of the RocksDB instance.
+func (r *rocksdbKV) GetStats() KVStats {
+	stats := KVStats{
+		PendingCompactionBytes: parseKVProperty(
+			r.db.GetProperty("rocksdb.estimate-pending-compaction-bytes")),
+		MemtableSize: parseKVProperty(
+			r.db.GetProperty("rocksdb.cur-size-all-mem-tables")),
+	}
+	for i := 0; i < rocksdbNumLevels; i++ {
+		p := fmt.Sprintf("rocksdb.num-files-at-level%d", i)
+		stats.SSTFileCount += parseKVProperty(r.db.GetProperty(p))
+	}
+	return stats
+}
+
 // Close closes the RDB object.
 func (r *rocksdbKV) Close() error {
 	if r.db != nil {
@@ -202,7 +226,7 @@ func (r *rocksdbKV) IterateValue(fk []byte, lk []byte, inc bool,
 	op func(key []byte, data []byte) (bool, error)) {
 	iter := r.db.NewIterator(r.ro)
 	defer iter.Close()
-	for iter.Seek(fk); iteratorIsValid(iter); iter.Next() {
+	for iter.Seek(fk); rocksdbIteratorIsValid(iter); iter.Next() {
 		key, ok := iter.OKey()
 		if !ok {
 			panic("failed to get key")
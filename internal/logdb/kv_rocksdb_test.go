@@ -14,7 +14,7 @@
 
 // +build !dragonboat_leveldb
 // +build !dragonboat_pebble
-// +build !dragonboat_custom_logdb
+// +build !dragonboat_no_rocksdb,!dragonboat_custom_logdb
 
 package logdb
 
@@ -282,7 +282,7 @@ func TestIteratorWithDiskCorruptionHandled(t *testing.T) {
 		fk.SetEntryKey(3, 4, 10)
 		iter := rdb.kvs.(*rocksdbKV).db.NewIterator(rdb.kvs.(*rocksdbKV).ro)
 		iter.Seek(fk.key)
-		for ; iteratorIsValid(iter); iter.Next() {
+		for ; rocksdbIteratorIsValid(iter); iter.Next() {
 			plog.Infof("here")
 			val := iter.Value()
 			var e pb.Entry
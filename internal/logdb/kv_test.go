@@ -16,6 +16,8 @@ package logdb
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/lni/dragonboat/internal/utils/leaktest"
@@ -23,7 +25,7 @@ import (
 
 func TestKVCanBeCreatedAndClosed(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	kvs, err := newKVStore(RDBTestDirectory, RDBTestDirectory)
+	kvs, err := newKVStore("", RDBTestDirectory, RDBTestDirectory, false)
 	if err != nil {
 		t.Fatalf("failed to open kv rocksdb")
 	}
@@ -33,10 +35,80 @@ func TestKVCanBeCreatedAndClosed(t *testing.T) {
 	}
 }
 
+func TestUnknownKVStoreTypeIsReported(t *testing.T) {
+	if _, err := GetKVStoreType("no-such-kv-store"); err == nil {
+		t.Errorf("unknown kv store type not reported")
+	}
+	_, err := newKVStore("no-such-kv-store",
+		RDBTestDirectory, RDBTestDirectory, false)
+	if err == nil {
+		t.Errorf("unknown kv store type not reported")
+	}
+}
+
+func TestDefaultKVStoreTypeIsUsedByLogDB(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	kvType, err := GetKVStoreType("")
+	if err != nil {
+		t.Fatalf("failed to get default kv store type %v", err)
+	}
+	found := false
+	for _, v := range GetKVStoreTypes() {
+		if v == kvType {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("default kv store type %s not registered", kvType)
+	}
+	db := getNewTestDB("db-dir", "wal-db-dir")
+	defer db.Close()
+	if db.Name() != fmt.Sprintf("sharded-%s", kvType) {
+		t.Errorf("unexpected logdb type %s", db.Name())
+	}
+}
+
+func TestMismatchedLegacyKVStoreTypeIsReported(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	kvType, err := GetKVStoreType("")
+	if err != nil {
+		t.Fatalf("failed to get default kv store type %v", err)
+	}
+	if kvType == PebbleKVStoreType {
+		t.Skip("pebble is not checked")
+	}
+	if err := CheckKVStoreType(RDBTestDirectory, RocksDBKVStoreType); err != nil {
+		t.Errorf("dir without LogDB failed the check, %v", err)
+	}
+	dir := filepath.Join(RDBTestDirectory, getShardDirName(0))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create dir %v", err)
+	}
+	kvs, err := newKVStore(kvType, dir, dir, false)
+	if err != nil {
+		t.Fatalf("failed to open kv store %v", err)
+	}
+	if err := kvs.Close(); err != nil {
+		t.Fatalf("failed to close kv store %v", err)
+	}
+	if err := CheckKVStoreType(RDBTestDirectory, kvType); err != nil {
+		t.Errorf("check failed, %v", err)
+	}
+	other := RocksDBKVStoreType
+	if kvType == RocksDBKVStoreType {
+		other = LevelDBKVStoreType
+	}
+	if err := CheckKVStoreType(RDBTestDirectory, other); err == nil {
+		t.Errorf("mismatched kv store type not reported")
+	}
+}
+
 func runKVTest(t *testing.T, tf func(t *testing.T, kvs IKvStore)) {
 	defer leaktest.AfterTest(t)()
 	defer deleteTestDB()
-	kvs, err := newKVStore(RDBTestDirectory, RDBTestDirectory)
+	kvs, err := newKVStore("", RDBTestDirectory, RDBTestDirectory, false)
 	if err != nil {
 		t.Fatalf("failed to open kv rocksdb")
 	}
@@ -188,3 +260,19 @@ func TestWriteBatchCanBeCleared(t *testing.T) {
 	}
 	runKVTest(t, tf)
 }
+
+func TestKVStatsCanBeReported(t *testing.T) {
+	tf := func(t *testing.T, kvs IKvStore) {
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key%d", i)
+			if err := kvs.SaveValue([]byte(key), make([]byte, 1024)); err != nil {
+				t.Fatalf("failed to save the value")
+			}
+		}
+		stats := kvs.GetStats()
+		if kvs.Name() != "pebble" && stats.MemtableSize == 0 {
+			t.Errorf("memtable size not reported")
+		}
+	}
+	runKVTest(t, tf)
+}
@@ -21,8 +21,21 @@ to import this package.
 package logdb
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lni/dragonboat/internal/utils/fileutil"
 	"github.com/lni/dragonboat/logger"
 	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+const (
+	// LogDBMetadataFilename is the name of the file used for recording the
+	// shard count of the LogDB. The key-value store type is recorded by
+	// NodeHost in its dir status.
+	LogDBMetadataFilename = "dragonboat.logdb"
 )
 
 var (
@@ -35,10 +48,6 @@ func checkDirs(dirs []string, lldirs []string) {
 			plog.Panicf("only 1 regular dir but %d low latency dirs", len(lldirs))
 		}
 	} else if len(dirs) > 1 {
-		if uint64(len(dirs)) != numOfRocksDBInstance {
-			plog.Panicf("%d regular dirs, but expect to have %d rdb instances",
-				len(dirs), numOfRocksDBInstance)
-		}
 		if len(lldirs) > 0 {
 			if len(dirs) != len(lldirs) {
 				plog.Panicf("%v regular dirs, but %v low latency dirs", dirs, lldirs)
@@ -49,17 +58,146 @@ func checkDirs(dirs []string, lldirs []string) {
 	}
 }
 
-// OpenLogDB opens a LogDB instance using the default implementation.
+// OpenLogDB opens a LogDB instance using the default implementation backed by
+// the default key-value store.
 func OpenLogDB(dirs []string, lowLatencyDirs []string) (raftio.ILogDB, error) {
+	return OpenLogDBWithKVStore(dirs, lowLatencyDirs, "", 0)
+}
+
+// OpenLogDBWithKVStore opens a LogDB instance using the default implementation
+// backed by the specified type of key-value store. The shards parameter is the
+// number of shards to use, the value 0 causes the shard count recorded in the
+// LogDB metadata file to be used, or the default shard count when the LogDB is
+// being created.
+func OpenLogDBWithKVStore(dirs []string, lowLatencyDirs []string,
+	kvType string, shards uint64) (raftio.ILogDB, error) {
+	checkDirs(dirs, lowLatencyDirs)
+	kvType, err := GetKVStoreType(kvType)
+	if err != nil {
+		return nil, err
+	}
+	shards, err = getLogDBShards(dirs[0], shards)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openShardedLogDB(dirs, lowLatencyDirs, kvType, shards, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveLogDBMetadata(dirs[0], shards); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenLogDBForReadOnly opens an existing LogDB instance created by the default
+// implementation backed by the specified type of key-value store. All updates
+// to the returned LogDB are rejected, the LogDB metadata is never written and
+// no background compaction is performed. RocksDB is opened in its read only
+// mode, other key-value stores are opened in their normal mode as they don't
+// provide a read only mode.
+func OpenLogDBForReadOnly(dirs []string, lowLatencyDirs []string,
+	kvType string) (raftio.ILogDB, error) {
 	checkDirs(dirs, lowLatencyDirs)
+	kvType, err := GetKVStoreType(kvType)
+	if err != nil {
+		return nil, err
+	}
+	md, err := getLogDBMetadata(dirs[0])
+	if err != nil {
+		return nil, err
+	}
+	if md.Shards == 0 {
+		return nil, fmt.Errorf("no LogDB found in %s", dirs[0])
+	}
+	return openShardedLogDB(dirs, lowLatencyDirs, kvType, md.Shards, true)
+}
+
+func openShardedLogDB(dirs []string, lowLatencyDirs []string,
+	kvType string, shards uint64, readOnly bool) (*ShardedRDB, error) {
+	if len(dirs) > 1 && uint64(len(dirs)) != shards {
+		return nil, fmt.Errorf("%d regular dirs, but expect to have %d shards",
+			len(dirs), shards)
+	}
 	llDirRequired := len(lowLatencyDirs) == 1
 	if len(dirs) == 1 {
-		for i := uint64(1); i < numOfRocksDBInstance; i++ {
+		for i := uint64(1); i < shards; i++ {
 			dirs = append(dirs, dirs[0])
 			if llDirRequired {
 				lowLatencyDirs = append(lowLatencyDirs, lowLatencyDirs[0])
 			}
 		}
 	}
-	return OpenShardedRDB(dirs, lowLatencyDirs)
+	return openShardedRDB(dirs, lowLatencyDirs, kvType, shards, readOnly)
+}
+
+// HasLogDB returns a boolean value indicating whether the specified dir
+// contains LogDB data created by the default implementation.
+func HasLogDB(dir string) bool {
+	md, err := getLogDBMetadata(dir)
+	return err == nil && md.Shards > 0
+}
+
+func getLogDBMetadata(dir string) (pb.LogDBMetadata, error) {
+	md := pb.LogDBMetadata{}
+	if fileutil.HasFlagFile(dir, LogDBMetadataFilename) {
+		err := fileutil.GetFlagFileContent(dir, LogDBMetadataFilename, &md)
+		if err != nil {
+			return pb.LogDBMetadata{}, err
+		}
+		if md.BinVer != raftio.LogDBBinVersion {
+			return pb.LogDBMetadata{}, fmt.Errorf("LogDB bin ver %d, expect %d",
+				md.BinVer, raftio.LogDBBinVersion)
+		}
+		return md, nil
+	}
+	// LogDB created by older versions has no metadata file, it always uses the
+	// default shard count
+	if _, err := os.Stat(filepath.Join(dir, getShardDirName(0))); err == nil {
+		md.Shards = numOfRocksDBInstance
+	} else if !os.IsNotExist(err) {
+		return pb.LogDBMetadata{}, err
+	}
+	return md, nil
+}
+
+func getLogDBShards(dir string, shards uint64) (uint64, error) {
+	md, err := getLogDBMetadata(dir)
+	if err != nil {
+		return 0, err
+	}
+	if md.Shards > 0 {
+		if shards > 0 && shards != md.Shards {
+			return 0, fmt.Errorf("LogDB has %d shards, %d requested, "+
+				"use the repartition op of logdbtool to change the shard count",
+				md.Shards, shards)
+		}
+		return md.Shards, nil
+	}
+	if shards == 0 {
+		if defaultNumOfShards > numOfStepEngineWorker {
+			return numOfStepEngineWorker, nil
+		}
+		return defaultNumOfShards, nil
+	}
+	if shards > numOfStepEngineWorker {
+		return 0, fmt.Errorf("%d shards requested, max allowed %d, the number "+
+			"of step engine workers", shards, numOfStepEngineWorker)
+	}
+	return shards, nil
+}
+
+func saveLogDBMetadata(dir string, shards uint64) error {
+	if fileutil.HasFlagFile(dir, LogDBMetadataFilename) {
+		return nil
+	}
+	md := pb.LogDBMetadata{
+		BinVer: raftio.LogDBBinVersion,
+		Shards: shards,
+	}
+	if err := fileutil.CreateFlagFile(dir, LogDBMetadataFilename, &md); err != nil {
+		return err
+	}
+	return fileutil.SyncDir(dir)
 }
@@ -0,0 +1,202 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lni/dragonboat/internal/utils/fileutil"
+	"github.com/lni/dragonboat/internal/utils/leaktest"
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func openTestShardedDB(dir string,
+	lldir string, shards uint64) (raftio.ILogDB, error) {
+	d := filepath.Join(RDBTestDirectory, dir)
+	lld := filepath.Join(RDBTestDirectory, lldir)
+	os.MkdirAll(d, 0777)
+	os.MkdirAll(lld, 0777)
+	return OpenLogDBWithKVStore([]string{d}, []string{lld}, "", shards)
+}
+
+func TestLogDBShardCountIsRecorded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	db, err := openTestShardedDB("db-dir", "wal-db-dir", 4)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	if len(db.(*ShardedRDB).shards) != 4 {
+		t.Errorf("got %d shards, want 4", len(db.(*ShardedRDB).shards))
+	}
+	db.Close()
+	md, err := getLogDBMetadata(filepath.Join(RDBTestDirectory, "db-dir"))
+	if err != nil {
+		t.Fatalf("failed to get metadata %v", err)
+	}
+	if md.Shards != 4 || md.BinVer != raftio.LogDBBinVersion {
+		t.Errorf("unexpected metadata %v", md)
+	}
+	db, err = openTestShardedDB("db-dir", "wal-db-dir", 0)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	if len(db.(*ShardedRDB).shards) != 4 {
+		t.Errorf("got %d shards, want 4", len(db.(*ShardedRDB).shards))
+	}
+	db.Close()
+	if _, err := openTestShardedDB("db-dir", "wal-db-dir", 8); err == nil {
+		t.Errorf("shard count change not reported")
+	}
+}
+
+func TestDefaultShardCountIsUsedByLogDB(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	db, err := openTestShardedDB("db-dir", "wal-db-dir", 0)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	defer db.Close()
+	if uint64(len(db.(*ShardedRDB).shards)) != defaultNumOfShards {
+		t.Errorf("got %d shards, want %d",
+			len(db.(*ShardedRDB).shards), defaultNumOfShards)
+	}
+}
+
+func TestLogDBWithoutMetadataUsesDefaultShardCount(t *testing.T) {
+	defer deleteTestDB()
+	dir := filepath.Join(RDBTestDirectory, "db-dir")
+	shards, err := getLogDBShards(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to get shard count %v", err)
+	}
+	if shards != defaultNumOfShards {
+		t.Errorf("got %d shards, want %d", shards, defaultNumOfShards)
+	}
+	// LogDB created by older versions
+	if err := fileutil.MkdirAll(filepath.Join(dir, getShardDirName(0))); err != nil {
+		t.Fatalf("failed to create dir %v", err)
+	}
+	shards, err = getLogDBShards(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to get shard count %v", err)
+	}
+	if shards != numOfRocksDBInstance {
+		t.Errorf("got %d shards, want %d", shards, numOfRocksDBInstance)
+	}
+	if _, err := getLogDBShards(dir, defaultNumOfShards); err == nil {
+		t.Errorf("shard count change not reported")
+	}
+}
+
+func TestShardCountCanBeLargerThanDefault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	if defaultNumOfShards >= numOfStepEngineWorker {
+		t.Fatalf("default shard count %d is not lower than the max %d",
+			defaultNumOfShards, numOfStepEngineWorker)
+	}
+	db, err := openTestShardedDB("db-dir", "wal-db-dir", numOfStepEngineWorker)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	defer db.Close()
+	if uint64(len(db.(*ShardedRDB).shards)) != numOfStepEngineWorker {
+		t.Errorf("got %d shards, want %d",
+			len(db.(*ShardedRDB).shards), numOfStepEngineWorker)
+	}
+}
+
+func TestTooManyShardsIsReported(t *testing.T) {
+	defer deleteTestDB()
+	shards := numOfStepEngineWorker + 1
+	if _, err := openTestShardedDB("db-dir", "wal-db-dir", shards); err == nil {
+		t.Errorf("invalid shard count not reported")
+	}
+}
+
+func TestMismatchedLogDBBinVerIsReported(t *testing.T) {
+	defer deleteTestDB()
+	dir := filepath.Join(RDBTestDirectory, "db-dir")
+	if err := fileutil.MkdirAll(dir); err != nil {
+		t.Fatalf("failed to create dir %v", err)
+	}
+	md := pb.LogDBMetadata{BinVer: raftio.LogDBBinVersion + 1, Shards: 1}
+	if err := fileutil.CreateFlagFile(dir, LogDBMetadataFilename, &md); err != nil {
+		t.Fatalf("failed to create metadata file %v", err)
+	}
+	if _, err := openTestShardedDB("db-dir", "wal-db-dir", 0); err == nil {
+		t.Errorf("bin ver mismatch not reported")
+	}
+}
+
+func TestReadOnlyLogDBDoesNotWriteMetadata(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	db, err := openTestShardedDB("db-dir", "wal-db-dir", 2)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	bs := pb.Bootstrap{Join: true, Addresses: map[uint64]string{1: "a1"}}
+	if err := db.SaveBootstrapInfo(1, 1, bs); err != nil {
+		t.Fatalf("failed to save bootstrap info %v", err)
+	}
+	db.Close()
+	dir := filepath.Join(RDBTestDirectory, "db-dir")
+	lldir := filepath.Join(RDBTestDirectory, "wal-db-dir")
+	if err := os.Remove(filepath.Join(dir, LogDBMetadataFilename)); err != nil {
+		t.Fatalf("failed to remove metadata %v", err)
+	}
+	if _, err := OpenLogDBForReadOnly([]string{dir},
+		[]string{lldir}, ""); err == nil {
+		t.Fatalf("LogDB without metadata or default shards opened")
+	}
+	md := pb.LogDBMetadata{BinVer: raftio.LogDBBinVersion, Shards: 2}
+	if err := fileutil.CreateFlagFile(dir, LogDBMetadataFilename, &md); err != nil {
+		t.Fatalf("failed to create metadata file %v", err)
+	}
+	db, err = OpenLogDBForReadOnly([]string{dir}, []string{lldir}, "")
+	if err != nil {
+		t.Fatalf("failed to open read only LogDB %v", err)
+	}
+	defer db.Close()
+	if _, err := db.GetBootstrapInfo(1, 1); err != nil {
+		t.Errorf("failed to get bootstrap info %v", err)
+	}
+	if err := db.SaveBootstrapInfo(2, 1, bs); err != errReadOnlyLogDB {
+		t.Errorf("unexpected error %v", err)
+	}
+	ctx := db.GetLogDBThreadContext()
+	defer ctx.Destroy()
+	ud := pb.Update{ClusterID: 1, NodeID: 1, State: pb.State{Term: 2}}
+	if err := db.SaveRaftState([]pb.Update{ud}, ctx); err != errReadOnlyLogDB {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestReadOnlyLogDBMustExist(t *testing.T) {
+	defer deleteTestDB()
+	dir := filepath.Join(RDBTestDirectory, "db-dir")
+	if _, err := OpenLogDBForReadOnly([]string{dir}, nil, ""); err == nil {
+		t.Errorf("missing LogDB not reported")
+	}
+	if fileutil.HasFlagFile(dir, LogDBMetadataFilename) {
+		t.Errorf("metadata unexpectedly created")
+	}
+}
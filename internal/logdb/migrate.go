@@ -0,0 +1,312 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+var (
+	// migrationBatchSize is the max size in bytes of entries copied in each
+	// SaveRaftState call during migration.
+	migrationBatchSize uint64 = 64 * 1024 * 1024
+	errReadOnlyLogDB          = errors.New("read only LogDB")
+)
+
+// MigrationResult describes the data copied by MigrateLogDB.
+type MigrationResult struct {
+	// Nodes is the number of Raft nodes migrated.
+	Nodes uint64
+	// Entries is the number of Raft log entries migrated.
+	Entries uint64
+	// Snapshots is the number of snapshot records migrated.
+	Snapshots uint64
+}
+
+// readOnlyLogDB is an ILogDB wrapper that rejects all updates, it makes sure
+// that the source LogDB is never modified during migration.
+type readOnlyLogDB struct {
+	raftio.ILogDB
+}
+
+func (db *readOnlyLogDB) SaveBootstrapInfo(clusterID uint64,
+	nodeID uint64, bootstrap pb.Bootstrap) error {
+	return errReadOnlyLogDB
+}
+
+func (db *readOnlyLogDB) SaveRaftState(updates []pb.Update,
+	ctx raftio.IContext) error {
+	return errReadOnlyLogDB
+}
+
+func (db *readOnlyLogDB) RemoveEntriesTo(clusterID uint64,
+	nodeID uint64, index uint64) error {
+	return errReadOnlyLogDB
+}
+
+func (db *readOnlyLogDB) SaveSnapshots(updates []pb.Update) error {
+	return errReadOnlyLogDB
+}
+
+func (db *readOnlyLogDB) DeleteSnapshot(clusterID uint64,
+	nodeID uint64, snapshotIndex uint64) error {
+	return errReadOnlyLogDB
+}
+
+// MigrateLogDB copies all bootstrap info, Raft states, log entries and
+// snapshot records found in the src LogDB to the dst LogDB. The src LogDB is
+// only read, the copied data is verified by comparing entry counts and entry
+// checksums once all data has been written to dst. MigrateLogDB is expected
+// to be used offline when no NodeHost is accessing src or dst.
+func MigrateLogDB(src raftio.ILogDB,
+	dst raftio.ILogDB) (MigrationResult, error) {
+	src = &readOnlyLogDB{src}
+	result := MigrationResult{}
+	nodes, err := src.ListNodeInfo()
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	ctx := dst.GetLogDBThreadContext()
+	defer ctx.Destroy()
+	for _, ni := range nodes {
+		entries, snapshots, err := migrateNode(src, dst, ni, ctx)
+		if err != nil {
+			return MigrationResult{}, err
+		}
+		result.Nodes++
+		result.Entries += entries
+		result.Snapshots += snapshots
+	}
+	for _, ni := range nodes {
+		if err := verifyNode(src, dst, ni); err != nil {
+			return MigrationResult{}, err
+		}
+	}
+	return result, nil
+}
+
+func getLatestSnapshot(snapshots []pb.Snapshot) pb.Snapshot {
+	ss := pb.Snapshot{}
+	for _, v := range snapshots {
+		if v.Index > ss.Index {
+			ss = v
+		}
+	}
+	return ss
+}
+
+func migrateNode(src raftio.ILogDB, dst raftio.ILogDB,
+	ni raftio.NodeInfo, ctx raftio.IContext) (uint64, uint64, error) {
+	cid, nid := ni.ClusterID, ni.NodeID
+	bs, err := src.GetBootstrapInfo(cid, nid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := dst.SaveBootstrapInfo(cid, nid, *bs); err != nil {
+		return 0, 0, err
+	}
+	snapshots, err := src.ListSnapshots(cid, nid)
+	if err != nil {
+		return 0, 0, err
+	}
+	updates := make([]pb.Update, 0, len(snapshots))
+	for _, ss := range snapshots {
+		updates = append(updates,
+			pb.Update{ClusterID: cid, NodeID: nid, Snapshot: ss})
+	}
+	if err := dst.SaveSnapshots(updates); err != nil {
+		return 0, 0, err
+	}
+	ss := getLatestSnapshot(snapshots)
+	rs, err := src.ReadRaftState(cid, nid, ss.Index)
+	if err == raftio.ErrNoSavedLog {
+		return 0, uint64(len(snapshots)), nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	ud := pb.Update{
+		ClusterID: cid,
+		NodeID:    nid,
+		State:     *rs.State,
+		Snapshot:  ss,
+	}
+	if err := saveMigratedUpdate(dst, ud, ctx); err != nil {
+		return 0, 0, err
+	}
+	entries := uint64(0)
+	if err := iterateStoredEntries(src, ni, func(ents []pb.Entry) error {
+		entries += uint64(len(ents))
+		ud := pb.Update{ClusterID: cid, NodeID: nid, EntriesToSave: ents}
+		return saveMigratedUpdate(dst, ud, ctx)
+	}); err != nil {
+		return 0, 0, err
+	}
+	return entries, uint64(len(snapshots)), nil
+}
+
+// iterateStoredEntries invokes f with all entries stored for the specified
+// node in ascending index order, including those older than the latest
+// snapshot. Stored entries are not necessarily continuous, e.g. entries older
+// than a snapshot received from the leader might still be stored, f is invoked
+// with continuous entries only.
+func iterateStoredEntries(db raftio.ILogDB,
+	ni raftio.NodeInfo, f func(ents []pb.Entry) error) error {
+	cid, nid := ni.ClusterID, ni.NodeID
+	low := uint64(0)
+	for {
+		rs, err := db.ReadRaftState(cid, nid, low)
+		if err == raftio.ErrNoSavedLog {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if rs.EntryCount == 0 {
+			return nil
+		}
+		low = rs.FirstIndex
+		high := rs.FirstIndex + rs.EntryCount
+		for low < high {
+			ents, _, err := db.IterateEntries(nil,
+				0, cid, nid, low, high, migrationBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(ents) == 0 {
+				if low == rs.FirstIndex {
+					return fmt.Errorf("%s missing entry %d", logNodeInfo(ni), low)
+				}
+				break
+			}
+			if err := f(ents); err != nil {
+				return err
+			}
+			low = ents[len(ents)-1].Index + 1
+		}
+	}
+}
+
+func saveMigratedUpdate(dst raftio.ILogDB,
+	ud pb.Update, ctx raftio.IContext) error {
+	ctx.Reset()
+	return dst.SaveRaftState([]pb.Update{ud}, ctx)
+}
+
+func verifyNode(src raftio.ILogDB,
+	dst raftio.ILogDB, ni raftio.NodeInfo) error {
+	cid, nid := ni.ClusterID, ni.NodeID
+	if _, err := dst.GetBootstrapInfo(cid, nid); err != nil {
+		return fmt.Errorf("%s bootstrap info not migrated, %v",
+			logNodeInfo(ni), err)
+	}
+	srcSnapshots, err := src.ListSnapshots(cid, nid)
+	if err != nil {
+		return err
+	}
+	dstSnapshots, err := dst.ListSnapshots(cid, nid)
+	if err != nil {
+		return err
+	}
+	if len(srcSnapshots) != len(dstSnapshots) {
+		return fmt.Errorf("%s snapshot count mismatch, src %d, dst %d",
+			logNodeInfo(ni), len(srcSnapshots), len(dstSnapshots))
+	}
+	ss := getLatestSnapshot(srcSnapshots)
+	srcState, err := src.ReadRaftState(cid, nid, ss.Index)
+	if err == raftio.ErrNoSavedLog {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dstState, err := dst.ReadRaftState(cid, nid, ss.Index)
+	if err != nil {
+		return err
+	}
+	if !pb.IsStateEqual(*srcState.State, *dstState.State) {
+		return fmt.Errorf("%s state mismatch", logNodeInfo(ni))
+	}
+	// all stored entries are migrated, compare the same range on both sides
+	srcRange, err := src.ReadRaftState(cid, nid, 0)
+	if err != nil {
+		return err
+	}
+	dstRange, err := dst.ReadRaftState(cid, nid, 0)
+	if err != nil {
+		return err
+	}
+	if srcRange.EntryCount != dstRange.EntryCount {
+		return fmt.Errorf("%s entry count mismatch, src %d, dst %d",
+			logNodeInfo(ni), srcRange.EntryCount, dstRange.EntryCount)
+	}
+	if srcRange.EntryCount == 0 {
+		return nil
+	}
+	if srcRange.FirstIndex != dstRange.FirstIndex {
+		return fmt.Errorf("%s first index mismatch, src %d, dst %d",
+			logNodeInfo(ni), srcRange.FirstIndex, dstRange.FirstIndex)
+	}
+	srcCount, srcChecksum, err := getEntryChecksum(src, ni)
+	if err != nil {
+		return err
+	}
+	dstCount, dstChecksum, err := getEntryChecksum(dst, ni)
+	if err != nil {
+		return err
+	}
+	if srcCount != dstCount {
+		return fmt.Errorf("%s entry count mismatch, src %d, dst %d",
+			logNodeInfo(ni), srcCount, dstCount)
+	}
+	if srcChecksum != dstChecksum {
+		return fmt.Errorf("%s entry checksum mismatch, src %d, dst %d",
+			logNodeInfo(ni), srcChecksum, dstChecksum)
+	}
+	return nil
+}
+
+// getEntryChecksum returns the number of stored entries and the CRC32
+// checksum of those entries.
+func getEntryChecksum(db raftio.ILogDB,
+	ni raftio.NodeInfo) (uint64, uint32, error) {
+	h := crc32.NewIEEE()
+	count := uint64(0)
+	if err := iterateStoredEntries(db, ni, func(ents []pb.Entry) error {
+		for _, e := range ents {
+			data, err := e.Marshal()
+			if err != nil {
+				panic(err)
+			}
+			if _, err := h.Write(data); err != nil {
+				panic(err)
+			}
+		}
+		count += uint64(len(ents))
+		return nil
+	}); err != nil {
+		return 0, 0, err
+	}
+	return count, h.Sum32(), nil
+}
+
+func logNodeInfo(ni raftio.NodeInfo) string {
+	return fmt.Sprintf("[%05d:%05d]", ni.ClusterID, ni.NodeID)
+}
@@ -0,0 +1,289 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lni/dragonboat/internal/utils/leaktest"
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+// lossyLogDB drops the last entry of every saved update.
+type lossyLogDB struct {
+	raftio.ILogDB
+}
+
+func (db *lossyLogDB) SaveRaftState(updates []pb.Update,
+	ctx raftio.IContext) error {
+	for i := range updates {
+		if n := len(updates[i].EntriesToSave); n > 1 {
+			updates[i].EntriesToSave = updates[i].EntriesToSave[:n-1]
+		}
+	}
+	return db.ILogDB.SaveRaftState(updates, ctx)
+}
+
+func populateMigrationTestDB(t *testing.T, db raftio.ILogDB) {
+	for cid := uint64(1); cid <= 3; cid++ {
+		bs := pb.Bootstrap{Join: true, Addresses: map[uint64]string{1: "a1"}}
+		if err := db.SaveBootstrapInfo(cid, 1, bs); err != nil {
+			t.Fatalf("failed to save bootstrap info %v", err)
+		}
+	}
+	ss := pb.Update{ClusterID: 1, NodeID: 1, Snapshot: pb.Snapshot{Index: 5, Term: 1}}
+	if err := db.SaveSnapshots([]pb.Update{ss}); err != nil {
+		t.Fatalf("failed to save snapshot %v", err)
+	}
+	for cid := uint64(1); cid <= 2; cid++ {
+		for i := uint64(1); i <= 100; i++ {
+			ud := pb.Update{
+				ClusterID: cid,
+				NodeID:    1,
+				State:     pb.State{Term: 1, Vote: 1, Commit: i},
+				EntriesToSave: []pb.Entry{
+					{Index: i, Term: 1, Cmd: []byte{byte(i), byte(cid)}},
+				},
+			}
+			if i == 20 {
+				ud.Snapshot = pb.Snapshot{Index: 10, Term: 1}
+			}
+			if err := db.SaveRaftState([]pb.Update{ud},
+				newRDBContext(1, nil)); err != nil {
+				t.Fatalf("failed to save raft state %v", err)
+			}
+		}
+	}
+}
+
+func runMigrationTest(t *testing.T,
+	tf func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB)) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	src := getNewTestDB("db-dir", "wal-db-dir")
+	defer src.Close()
+	dst := getNewTestWALDB("dst-db-dir", "dst-wal-db-dir")
+	defer dst.Close()
+	tf(t, src, dst)
+}
+
+func TestLogDBCanBeMigrated(t *testing.T) {
+	tf := func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB) {
+		populateMigrationTestDB(t, src)
+		result, err := MigrateLogDB(src, dst)
+		if err != nil {
+			t.Fatalf("migration failed %v", err)
+		}
+		if result.Nodes != 3 || result.Snapshots != 3 {
+			t.Errorf("unexpected result %+v", result)
+		}
+		if result.Entries != 200 {
+			t.Errorf("got %d entries, want 200", result.Entries)
+		}
+		nodes, err := dst.ListNodeInfo()
+		if err != nil {
+			t.Fatalf("failed to list node info %v", err)
+		}
+		if len(nodes) != 3 {
+			t.Errorf("got %d nodes, want 3", len(nodes))
+		}
+		for cid := uint64(1); cid <= 2; cid++ {
+			rs, err := dst.ReadRaftState(cid, 1, 10)
+			if err != nil {
+				t.Fatalf("failed to read raft state %v", err)
+			}
+			if rs.State.Commit != 100 || rs.FirstIndex != 10 || rs.EntryCount != 91 {
+				t.Errorf("unexpected raft state %+v", rs)
+			}
+			ents, _, err := dst.IterateEntries(nil,
+				0, cid, 1, 1, 101, math.MaxUint64)
+			if err != nil {
+				t.Fatalf("IterateEntries failed %v", err)
+			}
+			if len(ents) != 100 {
+				t.Fatalf("got %d entries, want 100", len(ents))
+			}
+			for _, e := range ents {
+				if e.Cmd[0] != byte(e.Index) || e.Cmd[1] != byte(cid) {
+					t.Errorf("unexpected entry %v", e)
+				}
+			}
+		}
+		snapshots, err := dst.ListSnapshots(1, 1)
+		if err != nil {
+			t.Fatalf("failed to list snapshots %v", err)
+		}
+		if len(snapshots) != 2 {
+			t.Errorf("got %d snapshots, want 2", len(snapshots))
+		}
+		if _, err := dst.ReadRaftState(3, 1, 0); err != raftio.ErrNoSavedLog {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	runMigrationTest(t, tf)
+}
+
+func TestMigrationCopiesNonContinuousEntries(t *testing.T) {
+	tf := func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB) {
+		bs := pb.Bootstrap{Join: true, Addresses: map[uint64]string{1: "a1"}}
+		if err := src.SaveBootstrapInfo(1, 1, bs); err != nil {
+			t.Fatalf("failed to save bootstrap info %v", err)
+		}
+		save := func(ud pb.Update) {
+			if err := src.SaveRaftState([]pb.Update{ud},
+				newRDBContext(1, nil)); err != nil {
+				t.Fatalf("failed to save raft state %v", err)
+			}
+		}
+		for i := uint64(1); i <= 5; i++ {
+			save(pb.Update{
+				ClusterID:     1,
+				NodeID:        1,
+				State:         pb.State{Term: 1, Commit: i},
+				EntriesToSave: []pb.Entry{{Index: i, Term: 1, Cmd: []byte{byte(i)}}},
+			})
+		}
+		save(pb.Update{
+			ClusterID: 1,
+			NodeID:    1,
+			State:     pb.State{Term: 2, Commit: 20},
+			Snapshot:  pb.Snapshot{Index: 20, Term: 2},
+		})
+		for i := uint64(21); i <= 25; i++ {
+			save(pb.Update{
+				ClusterID:     1,
+				NodeID:        1,
+				State:         pb.State{Term: 2, Commit: i},
+				EntriesToSave: []pb.Entry{{Index: i, Term: 2, Cmd: []byte{byte(i)}}},
+			})
+		}
+		result, err := MigrateLogDB(src, dst)
+		if err != nil {
+			t.Fatalf("migration failed %v", err)
+		}
+		if result.Entries != 10 {
+			t.Errorf("got %d entries, want 10", result.Entries)
+		}
+		for _, r := range [][2]uint64{{1, 6}, {21, 26}} {
+			ents, _, err := dst.IterateEntries(nil,
+				0, 1, 1, r[0], r[1], math.MaxUint64)
+			if err != nil {
+				t.Fatalf("IterateEntries failed %v", err)
+			}
+			if uint64(len(ents)) != r[1]-r[0] {
+				t.Fatalf("got %d entries, want %d", len(ents), r[1]-r[0])
+			}
+			for _, e := range ents {
+				if e.Cmd[0] != byte(e.Index) {
+					t.Errorf("unexpected entry %v", e)
+				}
+			}
+		}
+	}
+	runMigrationTest(t, tf)
+}
+
+func TestLogDBCanBeRepartitioned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	src := getNewTestDB("db-dir", "wal-db-dir")
+	defer src.Close()
+	dst, err := openTestShardedDB("dst-db-dir", "dst-wal-db-dir", 2)
+	if err != nil {
+		t.Fatalf("failed to open LogDB %v", err)
+	}
+	defer dst.Close()
+	populateMigrationTestDB(t, src)
+	result, err := MigrateLogDB(src, dst)
+	if err != nil {
+		t.Fatalf("repartition failed %v", err)
+	}
+	if result.Nodes != 3 || result.Snapshots != 3 || result.Entries != 200 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(dst.(*ShardedRDB).shards) != 2 {
+		t.Errorf("got %d shards, want 2", len(dst.(*ShardedRDB).shards))
+	}
+	for cid := uint64(1); cid <= 2; cid++ {
+		rs, err := dst.ReadRaftState(cid, 1, 10)
+		if err != nil {
+			t.Fatalf("failed to read raft state %v", err)
+		}
+		if rs.EntryCount != 91 {
+			t.Errorf("got %d entries, want 91", rs.EntryCount)
+		}
+	}
+}
+
+func TestMigrationReportsLostEntries(t *testing.T) {
+	tf := func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB) {
+		populateMigrationTestDB(t, src)
+		if _, err := MigrateLogDB(src, &lossyLogDB{dst}); err == nil {
+			t.Errorf("lost entries not reported")
+		}
+	}
+	runMigrationTest(t, tf)
+}
+
+func TestMigrationReportsEntryChecksumMismatch(t *testing.T) {
+	tf := func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB) {
+		populateMigrationTestDB(t, src)
+		if _, err := MigrateLogDB(src, dst); err != nil {
+			t.Fatalf("migration failed %v", err)
+		}
+		ni := raftio.GetNodeInfo(1, 1)
+		if err := verifyNode(src, dst, ni); err != nil {
+			t.Fatalf("verification failed %v", err)
+		}
+		ud := pb.Update{
+			ClusterID:     1,
+			NodeID:        1,
+			EntriesToSave: []pb.Entry{{Index: 100, Term: 2}},
+		}
+		if err := dst.SaveRaftState([]pb.Update{ud},
+			newRDBContext(1, nil)); err != nil {
+			t.Fatalf("failed to save raft state %v", err)
+		}
+		if err := verifyNode(src, dst, ni); err == nil {
+			t.Errorf("entry checksum mismatch not reported")
+		}
+	}
+	runMigrationTest(t, tf)
+}
+
+func TestMigrationSourceIsReadOnly(t *testing.T) {
+	tf := func(t *testing.T, src raftio.ILogDB, dst raftio.ILogDB) {
+		db := &readOnlyLogDB{src}
+		if err := db.SaveBootstrapInfo(1, 1, pb.Bootstrap{}); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		ud := pb.Update{ClusterID: 1, NodeID: 1, State: pb.State{Term: 1}}
+		if err := db.SaveRaftState([]pb.Update{ud}, nil); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := db.SaveSnapshots([]pb.Update{ud}); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := db.RemoveEntriesTo(1, 1, 1); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := db.DeleteSnapshot(1, 1, 1); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	runMigrationTest(t, tf)
+}
@@ -38,6 +38,7 @@ func EnableRangeDelete() {
 // SetLogDBInstanceCount set the number of rocksdb instances to use.
 func SetLogDBInstanceCount(count uint64) {
 	numOfRocksDBInstance = count
+	defaultNumOfShards = count
 }
 
 // SetRDBContextSize set the RDB context related sizes
@@ -35,8 +35,9 @@ type RDB struct {
 	kvs  IKvStore
 }
 
-func openRDB(dir string, wal string) (*RDB, error) {
-	kvs, err := newKVStore(dir, wal)
+func openRDB(kvType string,
+	dir string, wal string, readOnly bool) (*RDB, error) {
+	kvs, err := newKVStore(kvType, dir, wal, readOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -331,6 +332,58 @@ func (r *RDB) listSnapshots(clusterID uint64,
 	return snapshots, nil
 }
 
+// getClusterStats returns the statistics of the specified raft node. The
+// estimated entry bytes is the total size of keys and values of the stored
+// entry batches.
+func (r *RDB) getClusterStats(clusterID uint64,
+	nodeID uint64) (raftio.ClusterStats, error) {
+	stats := raftio.ClusterStats{ClusterID: clusterID, NodeID: nodeID}
+	snapshots, err := r.listSnapshots(clusterID, nodeID)
+	if err != nil {
+		return raftio.ClusterStats{}, err
+	}
+	addSnapshotStats(&stats, snapshots)
+	maxIndex, err := r.readMaxIndex(clusterID, nodeID)
+	if err == raftio.ErrNoSavedLog {
+		return stats, nil
+	}
+	if err != nil {
+		return raftio.ClusterStats{}, err
+	}
+	firstKey := r.keys.get()
+	lastKey := r.keys.get()
+	defer firstKey.Release()
+	defer lastKey.Release()
+	low, high := getBatchIDRange(0, maxIndex+1)
+	firstKey.SetEntryBatchKey(clusterID, nodeID, low)
+	lastKey.SetEntryBatchKey(clusterID, nodeID, high)
+	es := entryStats{}
+	op := func(key []byte, data []byte) (bool, error) {
+		var eb pb.EntryBatch
+		if err := eb.Unmarshal(data); err != nil {
+			panic(err)
+		}
+		if len(eb.Entries) > 1 {
+			eb = restoreBatchFields(eb)
+		}
+		for _, e := range eb.Entries {
+			if e.Index <= maxIndex {
+				es.add(e.Index)
+			}
+		}
+		stats.EntryBytes += uint64(len(key) + len(data))
+		return true, nil
+	}
+	r.kvs.IterateValue(firstKey.Key(), lastKey.Key(), false, op)
+	es.update(&stats)
+	return stats, nil
+}
+
+// getKVStats returns the statistics of the underlying key-value store.
+func (r *RDB) getKVStats() KVStats {
+	return r.kvs.GetStats()
+}
+
 func (r *RDB) getEntryRange(clusterID uint64,
 	nodeID uint64, lastIndex uint64) (uint64, uint64, error) {
 	maxIndex, err := r.readMaxIndex(clusterID, nodeID)
@@ -45,7 +45,25 @@ func deleteTestDB() {
 	os.RemoveAll(RDBTestDirectory)
 }
 
+func getNewTestWALDB(dir string, lldir string) raftio.ILogDB {
+	d := filepath.Join(RDBTestDirectory, dir)
+	lld := filepath.Join(RDBTestDirectory, lldir)
+	os.MkdirAll(d, 0777)
+	os.MkdirAll(lld, 0777)
+	db, err := OpenWALLogDB([]string{d}, []string{lld})
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// runLogDBTest runs the specified test against all ILogDB implementations.
 func runLogDBTest(t *testing.T, tf func(t *testing.T, db raftio.ILogDB)) {
+	runRDBTest(t, tf)
+	runWALDBTest(t, tf)
+}
+
+func runRDBTest(t *testing.T, tf func(t *testing.T, db raftio.ILogDB)) {
 	defer leaktest.AfterTest(t)()
 	dir := "db-dir"
 	lldir := "wal-db-dir"
@@ -55,6 +73,16 @@ func runLogDBTest(t *testing.T, tf func(t *testing.T, db raftio.ILogDB)) {
 	tf(t, db)
 }
 
+func runWALDBTest(t *testing.T, tf func(t *testing.T, db raftio.ILogDB)) {
+	defer leaktest.AfterTest(t)()
+	dir := "db-dir"
+	lldir := "wal-db-dir"
+	db := getNewTestWALDB(dir, lldir)
+	defer deleteTestDB()
+	defer db.Close()
+	tf(t, db)
+}
+
 func TestRDBReturnErrNoBootstrapInfoWhenNoBootstrap(t *testing.T) {
 	tf := func(t *testing.T, db raftio.ILogDB) {
 		bootstrap, err := db.GetBootstrapInfo(1, 2)
@@ -154,7 +182,7 @@ func TestSnapshotHasMaxIndexSet(t *testing.T) {
 			t.Errorf("max index %d, want 3", maxIndex)
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func TestSaveSnapshotTogetherWithUnexpectedEntriesWillPanic(t *testing.T) {
@@ -170,12 +198,19 @@ func TestSaveSnapshotTogetherWithUnexpectedEntriesWillPanic(t *testing.T) {
 			t.Fatalf("failed to save raft state %v", err)
 		}
 	}
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("panic not triggered")
-		}
-	}()
-	runLogDBTest(t, tf)
+	runners := []func(*testing.T, func(*testing.T, raftio.ILogDB)){
+		runRDBTest, runWALDBTest,
+	}
+	for _, run := range runners {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("panic not triggered")
+				}
+			}()
+			run(t, tf)
+		}()
+	}
 }
 
 func TestSnapshotsSavedInSaveRaftState(t *testing.T) {
@@ -263,7 +298,7 @@ func TestSnapshotsSavedInSaveRaftState(t *testing.T) {
 			t.Errorf("max index %d, want 10", maxIndex)
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func TestMaxIndexRuleIsEnforced(t *testing.T) {
@@ -581,7 +616,7 @@ func TestMaxIndexIsUpdated(t *testing.T) {
 			t.Errorf("max index %d, want 11", maxIndex)
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func TestReadAllEntriesOnlyReturnEntriesFromTheSpecifiedNode(t *testing.T) {
@@ -920,7 +955,7 @@ func TestEntryBatchWillNotBeMergedToPreviousBatch(t *testing.T) {
 			t.Errorf("unexpected index %d, want 10", eb.Entries[0].Index)
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func TestEntryBatchMergedNotLastBatch(t *testing.T) {
@@ -983,7 +1018,7 @@ func TestEntryBatchMergedNotLastBatch(t *testing.T) {
 			}
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func TestSaveEntriesWithIndexGap(t *testing.T) {
@@ -1126,7 +1161,7 @@ func TestSaveEntriesAcrossMultipleBatches(t *testing.T) {
 			plog.Infof("idx %d", e.Index)
 		}
 	}
-	runLogDBTest(t, tf)
+	runRDBTest(t, tf)
 }
 
 func testAllWantedEntriesAreAccessible(t *testing.T, first uint64, last uint64) {
@@ -29,8 +29,16 @@ import (
 
 var (
 	useRangeDelete        = settings.Soft.UseRangeDelete
+	// numOfStepEngineWorker is also the max number of shards, updates from each
+	// step engine worker are saved into a single shard.
 	numOfStepEngineWorker = settings.Hard.StepEngineWorkerCount
-	numOfRocksDBInstance  = settings.Hard.LogDBPoolSize
+	// numOfRocksDBInstance is the number of shards used by LogDB created by
+	// older versions that don't record the shard count.
+	numOfRocksDBInstance = settings.Hard.LogDBPoolSize
+	// defaultNumOfShards is the number of shards used when creating a new LogDB
+	// without a specified shard count, it leaves room for hosts with fast
+	// storage devices to use more shards.
+	defaultNumOfShards uint64 = 8
 	// RDBContextValueSize defines the size of byte array managed in RDB context.
 	RDBContextValueSize uint64 = 1024 * 1024 * 64
 )
@@ -38,6 +46,7 @@ var (
 // ShardedRDB is a LogDB implementation using sharded rocksdb instances.
 type ShardedRDB struct {
 	completedCompactions uint64
+	kvType               string
 	shards               []*RDB
 	partitioner          server.IPartitioner
 	compactionCh         chan struct{}
@@ -45,24 +54,49 @@ type ShardedRDB struct {
 	stopper              *syncutil.Stopper
 }
 
-// OpenShardedRDB creates a ShardedRDB instance.
-func OpenShardedRDB(dirs []string, lldirs []string) (*ShardedRDB, error) {
+func getShardDirName(shardID uint64) string {
+	return fmt.Sprintf("logdb-%d", shardID)
+}
+
+// OpenShardedRDB creates a ShardedRDB instance with the specified number of
+// shards backed by the specified type of key-value store.
+func OpenShardedRDB(dirs []string,
+	lldirs []string, kvType string, count uint64) (*ShardedRDB, error) {
+	return openShardedRDB(dirs, lldirs, kvType, count, false)
+}
+
+// openShardedRDB creates a ShardedRDB instance. When readOnly is true, all
+// shards must already exist, updates are rejected and no compaction worker is
+// started.
+func openShardedRDB(dirs []string, lldirs []string,
+	kvType string, count uint64, readOnly bool) (*ShardedRDB, error) {
+	kvType, err := GetKVStoreType(kvType)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || count > numOfStepEngineWorker {
+		return nil, fmt.Errorf("invalid shard count %d", count)
+	}
 	shards := make([]*RDB, 0)
-	for i := uint64(0); i < numOfRocksDBInstance; i++ {
-		dir := filepath.Join(dirs[i], fmt.Sprintf("logdb-%d", i))
+	for i := uint64(0); i < count; i++ {
+		dir := filepath.Join(dirs[i], getShardDirName(i))
 		lldir := ""
 		if len(lldirs) > 0 {
-			lldir = filepath.Join(lldirs[i], fmt.Sprintf("logdb-%d", i))
+			lldir = filepath.Join(lldirs[i], getShardDirName(i))
 		}
-		db, err := openRDB(dir, lldir)
+		db, err := openRDB(kvType, dir, lldir, readOnly)
 		if err != nil {
+			for _, shard := range shards {
+				shard.close()
+			}
 			return nil, err
 		}
 		shards = append(shards, db)
 	}
-	partitioner := server.NewDoubleFixedPartitioner(numOfRocksDBInstance,
+	partitioner := server.NewDoubleFixedPartitioner(count,
 		numOfStepEngineWorker)
 	mw := &ShardedRDB{
+		kvType:       kvType,
 		shards:       shards,
 		partitioner:  partitioner,
 		compactions:  newCompactions(),
@@ -74,15 +108,17 @@ func OpenShardedRDB(dirs []string, lldirs []string) (*ShardedRDB, error) {
 	} else {
 		plog.Infof("RangeDelete is disabled in %s", mw.Name())
 	}
-	mw.stopper.RunWorker(func() {
-		mw.compactionWorkerMain()
-	})
+	if !readOnly {
+		mw.stopper.RunWorker(func() {
+			mw.compactionWorkerMain()
+		})
+	}
 	return mw, nil
 }
 
 // Name returns the type name of the instance.
 func (mw *ShardedRDB) Name() string {
-	return LogDBType
+	return fmt.Sprintf("sharded-%s", mw.kvType)
 }
 
 // GetLogDBThreadContext return a IContext instance.
@@ -185,6 +221,36 @@ func (mw *ShardedRDB) RemoveEntriesTo(clusterID uint64,
 	return nil
 }
 
+// GetLogDBStats returns the statistics of all raft nodes and shards.
+func (mw *ShardedRDB) GetLogDBStats() (raftio.LogDBStats, error) {
+	clusters := make([]raftio.ClusterStats, 0)
+	for _, v := range mw.shards {
+		nodes, err := v.listNodeInfo()
+		if err != nil {
+			return raftio.LogDBStats{}, err
+		}
+		for _, ni := range nodes {
+			stats, err := v.getClusterStats(ni.ClusterID, ni.NodeID)
+			if err != nil {
+				return raftio.LogDBStats{}, err
+			}
+			clusters = append(clusters, stats)
+		}
+	}
+	sortClusterStats(clusters)
+	shards := make([]raftio.ShardStats, 0, len(mw.shards))
+	for i, v := range mw.shards {
+		kvs := v.getKVStats()
+		shards = append(shards, raftio.ShardStats{
+			ShardID:                uint64(i),
+			PendingCompactionBytes: kvs.PendingCompactionBytes,
+			MemtableSize:           kvs.MemtableSize,
+			SSTFileCount:           kvs.SSTFileCount,
+		})
+	}
+	return raftio.LogDBStats{Clusters: clusters, Shards: shards}, nil
+}
+
 // Close closes the ShardedRDB instance.
 func (mw *ShardedRDB) Close() {
 	mw.stopper.Stop()
@@ -0,0 +1,65 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"sort"
+
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+// entryStats accumulates the statistics of stored entries.
+type entryStats struct {
+	count      uint64
+	firstIndex uint64
+	lastIndex  uint64
+}
+
+func (s *entryStats) add(index uint64) {
+	if s.count == 0 || index < s.firstIndex {
+		s.firstIndex = index
+	}
+	if index > s.lastIndex {
+		s.lastIndex = index
+	}
+	s.count++
+}
+
+func (s *entryStats) update(stats *raftio.ClusterStats) {
+	stats.EntryCount = s.count
+	stats.FirstIndex = s.firstIndex
+	stats.LastIndex = s.lastIndex
+}
+
+// addSnapshotStats adds the specified snapshot records to the stats.
+func addSnapshotStats(stats *raftio.ClusterStats, snapshots []pb.Snapshot) {
+	for _, ss := range snapshots {
+		stats.SnapshotCount++
+		stats.SnapshotBytes += ss.FileSize
+		for _, f := range ss.Files {
+			stats.SnapshotBytes += f.FileSize
+		}
+	}
+}
+
+func sortClusterStats(stats []raftio.ClusterStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ClusterID != stats[j].ClusterID {
+			return stats[i].ClusterID < stats[j].ClusterID
+		}
+		return stats[i].NodeID < stats[j].NodeID
+	})
+}
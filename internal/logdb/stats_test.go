@@ -0,0 +1,132 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"testing"
+
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func populateStatsTestDB(t *testing.T, db raftio.ILogDB) {
+	for cid := uint64(2); cid >= 1; cid-- {
+		bs := pb.Bootstrap{Join: true}
+		if err := db.SaveBootstrapInfo(cid, 1, bs); err != nil {
+			t.Fatalf("failed to save bootstrap info %v", err)
+		}
+	}
+	for i := uint64(1); i <= 100; i++ {
+		ud := pb.Update{
+			ClusterID:     1,
+			NodeID:        1,
+			State:         pb.State{Term: 1, Vote: 1, Commit: i},
+			EntriesToSave: []pb.Entry{{Index: i, Term: 1, Cmd: make([]byte, 64)}},
+		}
+		if err := db.SaveRaftState([]pb.Update{ud},
+			newRDBContext(1, nil)); err != nil {
+			t.Fatalf("failed to save raft state %v", err)
+		}
+	}
+	ss := pb.Snapshot{
+		Index:    10,
+		Term:     1,
+		FileSize: 1024,
+		Files:    []*pb.SnapshotFile{{FileSize: 100}},
+	}
+	ud := pb.Update{ClusterID: 1, NodeID: 1, Snapshot: ss}
+	if err := db.SaveSnapshots([]pb.Update{ud}); err != nil {
+		t.Fatalf("failed to save snapshot %v", err)
+	}
+}
+
+func getTestLogDBStats(t *testing.T, db raftio.ILogDB) raftio.LogDBStats {
+	r, ok := db.(raftio.ILogDBStatsReporter)
+	if !ok {
+		t.Fatalf("%s is not a stats reporter", db.Name())
+	}
+	stats, err := r.GetLogDBStats()
+	if err != nil {
+		t.Fatalf("failed to get stats %v", err)
+	}
+	return stats
+}
+
+func TestLogDBStatsCanBeReported(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		populateStatsTestDB(t, db)
+		stats := getTestLogDBStats(t, db)
+		if len(stats.Clusters) != 2 {
+			t.Fatalf("got %d clusters, want 2", len(stats.Clusters))
+		}
+		cs := stats.Clusters[0]
+		if cs.ClusterID != 1 || cs.NodeID != 1 {
+			t.Errorf("unexpected cluster stats order %+v", stats.Clusters)
+		}
+		if cs.EntryCount != 100 || cs.FirstIndex != 1 || cs.LastIndex != 100 {
+			t.Errorf("unexpected entry stats %+v", cs)
+		}
+		if cs.EntryBytes < 100*64 {
+			t.Errorf("unexpected entry bytes %d", cs.EntryBytes)
+		}
+		if cs.SnapshotCount != 1 || cs.SnapshotBytes != 1124 {
+			t.Errorf("unexpected snapshot stats %+v", cs)
+		}
+		empty := raftio.ClusterStats{ClusterID: 2, NodeID: 1}
+		if stats.Clusters[1] != empty {
+			t.Errorf("unexpected cluster stats %+v", stats.Clusters[1])
+		}
+	}
+	runLogDBTest(t, tf)
+}
+
+func TestLogDBStatsExcludeOverwrittenEntries(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		populateStatsTestDB(t, db)
+		ud := pb.Update{
+			ClusterID:     1,
+			NodeID:        1,
+			State:         pb.State{Term: 2, Vote: 1, Commit: 50},
+			EntriesToSave: []pb.Entry{{Index: 60, Term: 2}},
+		}
+		if err := db.SaveRaftState([]pb.Update{ud},
+			newRDBContext(1, nil)); err != nil {
+			t.Fatalf("failed to save raft state %v", err)
+		}
+		stats := getTestLogDBStats(t, db)
+		cs := stats.Clusters[0]
+		if cs.EntryCount != 60 || cs.FirstIndex != 1 || cs.LastIndex != 60 {
+			t.Errorf("unexpected entry stats %+v", cs)
+		}
+	}
+	runLogDBTest(t, tf)
+}
+
+func TestShardStatsAreReported(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		populateStatsTestDB(t, db)
+		stats := getTestLogDBStats(t, db)
+		if uint64(len(stats.Shards)) != defaultNumOfShards {
+			t.Fatalf("got %d shards, want %d",
+				len(stats.Shards), defaultNumOfShards)
+		}
+		for i, s := range stats.Shards {
+			if s.ShardID != uint64(i) {
+				t.Errorf("unexpected shard id %d, want %d", s.ShardID, i)
+			}
+		}
+	}
+	runRDBTest(t, tf)
+}
@@ -0,0 +1,266 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WAL records are stored in append only segment files. Each record has an 8
+// bytes header containing the payload length and the CRC32 checksum of the
+// payload. The payload starts with the record type, the cluster ID and the
+// node ID followed by the record type specific body.
+//
+// | length (4) | crc32 (4) | type (1) | cluster ID (8) | node ID (8) | body |
+
+const (
+	walDirName           = "wal-logdb"
+	walSegmentSuffix     = ".log"
+	walHeaderSize        = 8
+	walPayloadHeaderSize = 17
+)
+
+var (
+	// walSegmentSize is the size in bytes after which a new segment is used.
+	walSegmentSize uint64 = 64 * 1024 * 1024
+	walCRCTable           = crc32.MakeTable(crc32.Castagnoli)
+	// errWALCorrupted indicates that a segment other than the last one is
+	// corrupted.
+	errWALCorrupted = errors.New("corrupted wal segment")
+	errWALTornWrite = errors.New("torn write")
+)
+
+type walRecordType uint8
+
+const (
+	walBootstrap walRecordType = iota + 1
+	walState
+	walEntries
+	walSnapshot
+	walDeleteSnapshot
+	walMaxIndex
+	walRemoveEntries
+	walCheckpoint
+)
+
+type walMarshaler interface {
+	Size() int
+	MarshalTo(data []byte) (int, error)
+}
+
+// walIndex is the body of records that only carry an index value.
+type walIndex uint64
+
+func (v walIndex) Size() int {
+	return 8
+}
+
+func (v walIndex) MarshalTo(data []byte) (int, error) {
+	binary.BigEndian.PutUint64(data, uint64(v))
+	return 8, nil
+}
+
+// walEntryRef is the index range [low, high) of live entries stored in the
+// walEntries record located at the specified offset of a segment.
+type walEntryRef struct {
+	segID  uint64
+	offset uint64
+	low    uint64
+	high   uint64
+}
+
+// walEntryRefs is the body of walCheckpoint records, it lists the locations of
+// all live entries of a raft node.
+type walEntryRefs []walEntryRef
+
+func (v walEntryRefs) Size() int {
+	return 32 * len(v)
+}
+
+func (v walEntryRefs) MarshalTo(data []byte) (int, error) {
+	for i, ref := range v {
+		binary.BigEndian.PutUint64(data[i*32:], ref.segID)
+		binary.BigEndian.PutUint64(data[i*32+8:], ref.offset)
+		binary.BigEndian.PutUint64(data[i*32+16:], ref.low)
+		binary.BigEndian.PutUint64(data[i*32+24:], ref.high)
+	}
+	return v.Size(), nil
+}
+
+type walRecord struct {
+	rt        walRecordType
+	clusterID uint64
+	nodeID    uint64
+	body      []byte
+}
+
+func (r *walRecord) index() uint64 {
+	if len(r.body) != 8 {
+		panic("unexpected body size")
+	}
+	return binary.BigEndian.Uint64(r.body)
+}
+
+func (r *walRecord) entryRefs() (walEntryRefs, error) {
+	if len(r.body)%32 != 0 {
+		return nil, errWALCorrupted
+	}
+	refs := make(walEntryRefs, len(r.body)/32)
+	for i := range refs {
+		refs[i] = walEntryRef{
+			segID:  binary.BigEndian.Uint64(r.body[i*32:]),
+			offset: binary.BigEndian.Uint64(r.body[i*32+8:]),
+			low:    binary.BigEndian.Uint64(r.body[i*32+16:]),
+			high:   binary.BigEndian.Uint64(r.body[i*32+24:]),
+		}
+	}
+	return refs, nil
+}
+
+func growBuffer(buf []byte, n int) []byte {
+	if cap(buf)-len(buf) < n {
+		nb := make([]byte, len(buf), 2*cap(buf)+n)
+		copy(nb, buf)
+		buf = nb
+	}
+	return buf[:len(buf)+n]
+}
+
+func appendWALRecord(buf []byte, rt walRecordType,
+	clusterID uint64, nodeID uint64, body walMarshaler) []byte {
+	sz := walPayloadHeaderSize + body.Size()
+	start := len(buf)
+	buf = growBuffer(buf, walHeaderSize+sz)
+	payload := buf[start+walHeaderSize:]
+	payload[0] = byte(rt)
+	binary.BigEndian.PutUint64(payload[1:], clusterID)
+	binary.BigEndian.PutUint64(payload[9:], nodeID)
+	if _, err := body.MarshalTo(payload[walPayloadHeaderSize:]); err != nil {
+		panic(err)
+	}
+	binary.BigEndian.PutUint32(buf[start:], uint32(sz))
+	binary.BigEndian.PutUint32(buf[start+4:],
+		crc32.Checksum(payload, walCRCTable))
+	return buf
+}
+
+// readWALRecord decodes the record at the beginning of data, it returns the
+// record and its total size in bytes. errWALTornWrite is returned when data
+// doesn't start with a complete and valid record.
+func readWALRecord(data []byte) (walRecord, int, error) {
+	if len(data) < walHeaderSize {
+		return walRecord{}, 0, errWALTornWrite
+	}
+	sz := int(binary.BigEndian.Uint32(data))
+	if sz < walPayloadHeaderSize || len(data)-walHeaderSize < sz {
+		return walRecord{}, 0, errWALTornWrite
+	}
+	payload := data[walHeaderSize : walHeaderSize+sz]
+	if crc32.Checksum(payload, walCRCTable) != binary.BigEndian.Uint32(data[4:]) {
+		return walRecord{}, 0, errWALTornWrite
+	}
+	r := walRecord{
+		rt:        walRecordType(payload[0]),
+		clusterID: binary.BigEndian.Uint64(payload[1:]),
+		nodeID:    binary.BigEndian.Uint64(payload[9:]),
+		body:      payload[walPayloadHeaderSize:],
+	}
+	return r, walHeaderSize + sz, nil
+}
+
+type walSegment struct {
+	id   uint64
+	file *os.File
+	// size is the number of bytes written to the segment
+	size uint64
+	// base is the size of the checkpoint written at the beginning of the
+	// segment
+	base uint64
+	// refs is the number of live entries stored in the segment
+	refs uint64
+}
+
+func getWALSegmentFilename(id uint64) string {
+	return fmt.Sprintf("%020d%s", id, walSegmentSuffix)
+}
+
+func getWALSegmentIDList(dir string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0)
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, walSegmentSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name,
+			walSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func openWALSegment(dir string,
+	id uint64, readOnly bool) (*walSegment, error) {
+	fp := filepath.Join(dir, getWALSegmentFilename(id))
+	flag := os.O_RDWR | os.O_CREATE
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	f, err := os.OpenFile(fp, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &walSegment{id: id, file: f}, nil
+}
+
+func (s *walSegment) write(data []byte) error {
+	if _, err := s.file.WriteAt(data, int64(s.size)); err != nil {
+		return err
+	}
+	s.size += uint64(len(data))
+	return nil
+}
+
+func (s *walSegment) read(offset uint64, length uint64) (walRecord, error) {
+	data := make([]byte, length)
+	if _, err := s.file.ReadAt(data, int64(offset)); err != nil {
+		return walRecord{}, err
+	}
+	r, _, err := readWALRecord(data)
+	if err != nil {
+		return walRecord{}, errWALCorrupted
+	}
+	return r, nil
+}
+
+func (s *walSegment) close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,913 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/lni/dragonboat/internal/utils/fileutil"
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+const (
+	// WALLogDBType is the logdb type name of the WAL based LogDB.
+	WALLogDBType = "wal-logdb"
+)
+
+var (
+	// WALContextValueSize defines the size of byte array managed in the
+	// context returned by WALLogDB.
+	WALContextValueSize uint64 = 1024 * 1024 * 4
+	// walSyncFile is the func used for fsyncing segment files.
+	walSyncFile = func(f *os.File) error { return f.Sync() }
+)
+
+// walEntryPos is the location of a log entry in the WAL.
+type walEntryPos struct {
+	index  uint64
+	segID  uint64
+	offset uint64
+	length uint64
+	pos    uint64
+}
+
+// walNode is the in-memory index of all data saved for a raft node.
+type walNode struct {
+	bootstrap *pb.Bootstrap
+	state     *pb.State
+	snapshots map[uint64]pb.Snapshot
+	hasLog    bool
+	maxIndex  uint64
+	removedTo uint64
+	entries   []walEntryPos
+}
+
+func newWALNode() *walNode {
+	return &walNode{snapshots: make(map[uint64]pb.Snapshot)}
+}
+
+// first returns the position of the first entry with its index no less than
+// the specified index.
+func (n *walNode) first(index uint64) int {
+	return sort.Search(len(n.entries), func(i int) bool {
+		return n.entries[i].index >= index
+	})
+}
+
+// walPendingWrite is a write not yet known to be persisted, its apply func
+// updates the in-memory index once the write is fsynced.
+type walPendingWrite struct {
+	written uint64
+	apply   func()
+}
+
+// WALLogDB is a LogDB implementation using segmented append only log files.
+// All records are checksummed and appended to the active segment, concurrent
+// writers share fsync calls. An in-memory index is maintained to locate saved
+// entries, it only reflects writes that have been fsynced. Segments are
+// deleted as a whole once all entries stored in them are compacted or
+// overwritten.
+type WALLogDB struct {
+	dir      string
+	readOnly bool
+	syncMu   sync.Mutex
+	mu       struct {
+		sync.RWMutex
+		nodes      map[raftio.NodeInfo]*walNode
+		segments   map[uint64]*walSegment
+		segmentIDs []uint64
+		active     *walSegment
+		written    uint64
+		synced     uint64
+		pending    []walPendingWrite
+		// failed is the error that caused the WALLogDB to stop accepting writes
+		failed error
+	}
+}
+
+// HasWALLogDB returns a boolean value indicating whether the specified dir
+// contains WALLogDB data.
+func HasWALLogDB(dir string) bool {
+	return fileutil.Exist(filepath.Join(dir, walDirName))
+}
+
+// OpenWALLogDB creates a WALLogDB instance. Segment files are stored in the
+// first low latency dir when it is specified, or they are stored in the first
+// regular dir.
+func OpenWALLogDB(dirs []string, lowLatencyDirs []string) (*WALLogDB, error) {
+	dir := getWALDir(dirs, lowLatencyDirs)
+	if err := fileutil.MkdirAll(dir); err != nil {
+		return nil, err
+	}
+	db, err := openWALLogDB(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.roll(); err != nil {
+		db.closeSegments()
+		return nil, err
+	}
+	if err := db.removeObsoleteSegments(); err != nil {
+		db.closeSegments()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenWALLogDBForReadOnly opens an existing WALLogDB instance without
+// modifying any of its segment files. Torn writes are ignored rather than
+// discarded, no new segment is created, obsolete segments are not removed and
+// all updates are rejected.
+func OpenWALLogDBForReadOnly(dirs []string,
+	lowLatencyDirs []string) (*WALLogDB, error) {
+	dir := getWALDir(dirs, lowLatencyDirs)
+	if !fileutil.Exist(dir) {
+		return nil, fmt.Errorf("no wal LogDB found in %s", dir)
+	}
+	return openWALLogDB(dir, true)
+}
+
+func getWALDir(dirs []string, lowLatencyDirs []string) string {
+	checkDirs(dirs, lowLatencyDirs)
+	dir := dirs[0]
+	if len(lowLatencyDirs) > 0 {
+		dir = lowLatencyDirs[0]
+	}
+	return filepath.Join(dir, walDirName)
+}
+
+func openWALLogDB(dir string, readOnly bool) (*WALLogDB, error) {
+	db := &WALLogDB{dir: dir, readOnly: readOnly}
+	db.mu.nodes = make(map[raftio.NodeInfo]*walNode)
+	db.mu.segments = make(map[uint64]*walSegment)
+	db.mu.segmentIDs = make([]uint64, 0)
+	if err := db.replay(); err != nil {
+		db.closeSegments()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Name returns the type name of the instance.
+func (db *WALLogDB) Name() string {
+	return WALLogDBType
+}
+
+// Close closes the WALLogDB instance.
+func (db *WALLogDB) Close() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.closeSegments()
+}
+
+// GetLogDBThreadContext return a IContext instance.
+func (db *WALLogDB) GetLogDBThreadContext() raftio.IContext {
+	return newRDBContext(WALContextValueSize, nil)
+}
+
+// ListNodeInfo lists all available NodeInfo found in the log db.
+func (db *WALLogDB) ListNodeInfo() ([]raftio.NodeInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	r := make([]raftio.NodeInfo, 0)
+	for ni, n := range db.mu.nodes {
+		if n.bootstrap != nil {
+			r = append(r, ni)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].ClusterID != r[j].ClusterID {
+			return r[i].ClusterID < r[j].ClusterID
+		}
+		return r[i].NodeID < r[j].NodeID
+	})
+	return r, nil
+}
+
+// SaveBootstrapInfo saves the specified bootstrap info to the log db.
+func (db *WALLogDB) SaveBootstrapInfo(clusterID uint64,
+	nodeID uint64, bootstrap pb.Bootstrap) error {
+	data := appendWALRecord(nil, walBootstrap, clusterID, nodeID, &bootstrap)
+	return db.write(data, func(segID uint64, offset uint64) {
+		n := db.getNode(clusterID, nodeID)
+		n.bootstrap = &bootstrap
+	})
+}
+
+// GetBootstrapInfo returns saved bootstrap info from log db.
+func (db *WALLogDB) GetBootstrapInfo(clusterID uint64,
+	nodeID uint64) (*pb.Bootstrap, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	n, ok := db.mu.nodes[raftio.GetNodeInfo(clusterID, nodeID)]
+	if !ok || n.bootstrap == nil {
+		return nil, raftio.ErrNoBootstrapInfo
+	}
+	bs := *n.bootstrap
+	return &bs, nil
+}
+
+// SaveRaftState saves the raft state and logs found in the raft.Update list
+// to the log db.
+func (db *WALLogDB) SaveRaftState(updates []pb.Update,
+	ctx raftio.IContext) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	data := ctx.GetValueBuffer(0)[:0]
+	locations := make([]walEntryPos, len(updates))
+	for i := range updates {
+		ud := &updates[i]
+		if !pb.IsEmptyState(ud.State) {
+			data = appendWALRecord(data,
+				walState, ud.ClusterID, ud.NodeID, &ud.State)
+		}
+		if !pb.IsEmptySnapshot(ud.Snapshot) {
+			if len(ud.EntriesToSave) > 0 {
+				lastIndex := ud.EntriesToSave[len(ud.EntriesToSave)-1].Index
+				if ud.Snapshot.Index > lastIndex {
+					plog.Panicf("max index not handled, %d, %d",
+						ud.Snapshot.Index, lastIndex)
+				}
+			}
+			data = appendWALRecord(data,
+				walSnapshot, ud.ClusterID, ud.NodeID, &ud.Snapshot)
+			data = appendWALRecord(data, walMaxIndex,
+				ud.ClusterID, ud.NodeID, walIndex(ud.Snapshot.Index))
+		}
+		if len(ud.EntriesToSave) > 0 {
+			start := len(data)
+			eb := pb.EntryBatch{Entries: ud.EntriesToSave}
+			data = appendWALRecord(data, walEntries, ud.ClusterID, ud.NodeID, &eb)
+			locations[i].offset = uint64(start)
+			locations[i].length = uint64(len(data) - start)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return db.write(data, func(segID uint64, offset uint64) {
+		for i := range updates {
+			ud := &updates[i]
+			n := db.getNode(ud.ClusterID, ud.NodeID)
+			if !pb.IsEmptyState(ud.State) {
+				st := ud.State
+				n.state = &st
+			}
+			if !pb.IsEmptySnapshot(ud.Snapshot) {
+				n.snapshots[ud.Snapshot.Index] = ud.Snapshot
+				db.setMaxIndex(n, ud.Snapshot.Index)
+			}
+			if len(ud.EntriesToSave) > 0 {
+				db.appendEntries(n, ud.EntriesToSave, segID,
+					offset+locations[i].offset, locations[i].length)
+			}
+		}
+	})
+}
+
+// IterateEntries returns the continuous Raft log entries of the specified
+// Raft node between the index value range of [low, high) up to a max size
+// limit of maxSize bytes. It returns the located log entries, their total
+// size in bytes and the occurred error.
+func (db *WALLogDB) IterateEntries(ents []pb.Entry,
+	size uint64, clusterID uint64, nodeID uint64, low uint64, high uint64,
+	maxSize uint64) ([]pb.Entry, uint64, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	n, ok := db.mu.nodes[raftio.GetNodeInfo(clusterID, nodeID)]
+	if !ok || !n.hasLog {
+		return ents, size, nil
+	}
+	if high > n.maxIndex+1 {
+		high = n.maxIndex + 1
+	}
+	i := n.first(low)
+	if i == len(n.entries) || n.entries[i].index >= high {
+		return ents, size, nil
+	}
+	expected := low
+	var eb pb.EntryBatch
+	var cached walEntryPos
+	for ; i < len(n.entries) && n.entries[i].index < high; i++ {
+		p := n.entries[i]
+		if p.index != expected {
+			return ents, size, nil
+		}
+		if p.segID != cached.segID || p.offset != cached.offset {
+			if err := db.readEntryBatch(&eb, p); err != nil {
+				return nil, 0, err
+			}
+			cached = p
+		}
+		e := eb.Entries[p.pos]
+		size += uint64(e.SizeUpperLimit())
+		ents = append(ents, e)
+		expected++
+		if size > maxSize {
+			return ents, size, nil
+		}
+	}
+	return ents, entriesSize(ents), nil
+}
+
+// ReadRaftState returns the persistent state of the specified raft node.
+func (db *WALLogDB) ReadRaftState(clusterID uint64,
+	nodeID uint64, lastIndex uint64) (*raftio.RaftState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	n, ok := db.mu.nodes[raftio.GetNodeInfo(clusterID, nodeID)]
+	if !ok || n.state == nil {
+		return nil, raftio.ErrNoSavedLog
+	}
+	firstIndex := lastIndex
+	length := uint64(0)
+	if n.hasLog {
+		firstIndex = 0
+		for i := n.first(lastIndex); i < len(n.entries); i++ {
+			if n.entries[i].index > n.maxIndex {
+				break
+			}
+			if length == 0 {
+				firstIndex = n.entries[i].index
+			}
+			length++
+		}
+	}
+	st := *n.state
+	return &raftio.RaftState{
+		State:      &st,
+		FirstIndex: firstIndex,
+		EntryCount: length,
+	}, nil
+}
+
+// RemoveEntriesTo removes entries associated with the specified raft node up
+// to the specified index. Segments are deleted once all entries stored in them
+// have been removed.
+func (db *WALLogDB) RemoveEntriesTo(clusterID uint64,
+	nodeID uint64, index uint64) error {
+	data := appendWALRecord(nil,
+		walRemoveEntries, clusterID, nodeID, walIndex(index))
+	return db.write(data, func(segID uint64, offset uint64) {
+		db.removeEntries(db.getNode(clusterID, nodeID), index)
+	})
+}
+
+// SaveSnapshots saves all snapshot metadata found in the raft.Update list.
+func (db *WALLogDB) SaveSnapshots(updates []pb.Update) error {
+	var data []byte
+	for i := range updates {
+		ud := &updates[i]
+		if ud.Snapshot.Index > 0 {
+			data = appendWALRecord(data,
+				walSnapshot, ud.ClusterID, ud.NodeID, &ud.Snapshot)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return db.write(data, func(segID uint64, offset uint64) {
+		for _, ud := range updates {
+			if ud.Snapshot.Index > 0 {
+				n := db.getNode(ud.ClusterID, ud.NodeID)
+				n.snapshots[ud.Snapshot.Index] = ud.Snapshot
+			}
+		}
+	})
+}
+
+// DeleteSnapshot removes the specified snapshot metadata from the log db.
+func (db *WALLogDB) DeleteSnapshot(clusterID uint64,
+	nodeID uint64, snapshotIndex uint64) error {
+	data := appendWALRecord(nil,
+		walDeleteSnapshot, clusterID, nodeID, walIndex(snapshotIndex))
+	return db.write(data, func(segID uint64, offset uint64) {
+		delete(db.getNode(clusterID, nodeID).snapshots, snapshotIndex)
+	})
+}
+
+// ListSnapshots lists available snapshots associated with the specified
+// Raft node.
+func (db *WALLogDB) ListSnapshots(clusterID uint64,
+	nodeID uint64) ([]pb.Snapshot, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	snapshots := make([]pb.Snapshot, 0)
+	n, ok := db.mu.nodes[raftio.GetNodeInfo(clusterID, nodeID)]
+	if !ok {
+		return snapshots, nil
+	}
+	for _, ss := range n.snapshots {
+		snapshots = append(snapshots, ss)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Index < snapshots[j].Index
+	})
+	return snapshots, nil
+}
+
+// GetLogDBStats returns the statistics of all raft nodes. The estimated entry
+// bytes of a raft node is the total size of records containing its entries.
+// No shard statistics is reported.
+func (db *WALLogDB) GetLogDBStats() (raftio.LogDBStats, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	clusters := make([]raftio.ClusterStats, 0)
+	for ni, n := range db.mu.nodes {
+		if n.bootstrap == nil {
+			continue
+		}
+		stats := raftio.ClusterStats{ClusterID: ni.ClusterID, NodeID: ni.NodeID}
+		snapshots := make([]pb.Snapshot, 0, len(n.snapshots))
+		for _, ss := range n.snapshots {
+			snapshots = append(snapshots, ss)
+		}
+		addSnapshotStats(&stats, snapshots)
+		es := entryStats{}
+		var last walEntryPos
+		for i, p := range n.entries {
+			es.add(p.index)
+			if i == 0 || p.segID != last.segID || p.offset != last.offset {
+				stats.EntryBytes += p.length
+			}
+			last = p
+		}
+		es.update(&stats)
+		clusters = append(clusters, stats)
+	}
+	sortClusterStats(clusters)
+	return raftio.LogDBStats{Clusters: clusters}, nil
+}
+
+// write appends the specified data to the active segment and waits for it to
+// be fsynced together with data written by other concurrent writers. The
+// in-memory index is updated by the apply func only after the data has been
+// persisted.
+func (db *WALLogDB) write(data []byte,
+	apply func(segID uint64, offset uint64)) error {
+	if db.readOnly {
+		return errReadOnlyLogDB
+	}
+	db.mu.Lock()
+	if db.mu.failed != nil {
+		db.mu.Unlock()
+		return db.mu.failed
+	}
+	if db.mu.active == nil {
+		db.mu.Unlock()
+		return os.ErrClosed
+	}
+	active := db.mu.active
+	if active.size > active.base &&
+		active.size+uint64(len(data)) > walSegmentSize {
+		if err := db.roll(); err != nil {
+			db.mu.Unlock()
+			return err
+		}
+		active = db.mu.active
+	}
+	offset := active.size
+	if err := active.write(data); err != nil {
+		db.mu.Unlock()
+		return err
+	}
+	db.mu.written++
+	written := db.mu.written
+	segID := active.id
+	db.mu.pending = append(db.mu.pending, walPendingWrite{
+		written: written,
+		apply:   func() { apply(segID, offset) },
+	})
+	db.mu.Unlock()
+	return db.sync(written)
+}
+
+// sync makes sure that all writes up to the specified sequence number are
+// persisted and applied to the in-memory index. Writes made by concurrent
+// writers are all covered by a single fsync call. The WALLogDB stops
+// accepting writes once fsync fails as it is no longer known what has been
+// persisted.
+func (db *WALLogDB) sync(written uint64) error {
+	db.syncMu.Lock()
+	defer db.syncMu.Unlock()
+	db.mu.RLock()
+	synced := db.mu.synced
+	failed := db.mu.failed
+	active := db.mu.active
+	target := db.mu.written
+	db.mu.RUnlock()
+	if synced >= written {
+		return nil
+	}
+	if failed != nil {
+		return failed
+	}
+	if active == nil {
+		return os.ErrClosed
+	}
+	// segments are fsynced when they are rolled, only the active segment
+	// needs to be fsynced here
+	err := walSyncFile(active.file)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err != nil {
+		db.fail(err)
+		return err
+	}
+	db.applyPending(target)
+	return db.removeObsoleteSegments()
+}
+
+// applyPending applies all pending writes up to the specified sequence number
+// to the in-memory index in the order they were written.
+func (db *WALLogDB) applyPending(synced uint64) {
+	i := 0
+	for ; i < len(db.mu.pending) && db.mu.pending[i].written <= synced; i++ {
+		db.mu.pending[i].apply()
+	}
+	db.mu.pending = append(db.mu.pending[:0], db.mu.pending[i:]...)
+	if synced > db.mu.synced {
+		db.mu.synced = synced
+	}
+}
+
+func (db *WALLogDB) fail(err error) {
+	plog.Errorf("failed to fsync wal segment, %v", err)
+	db.mu.failed = err
+	db.mu.pending = nil
+}
+
+// roll fsyncs the active segment and starts a new one. All pending writes are
+// applied once the active segment is fsynced, the new segment then begins
+// with a fsynced checkpoint of the in-memory index so any older segment is
+// only required for the live entries stored in it.
+func (db *WALLogDB) roll() error {
+	id := uint64(1)
+	if db.mu.active != nil {
+		if err := walSyncFile(db.mu.active.file); err != nil {
+			db.fail(err)
+			return err
+		}
+		db.applyPending(db.mu.written)
+		id = db.mu.active.id + 1
+	} else if len(db.mu.segmentIDs) > 0 {
+		id = db.mu.segmentIDs[len(db.mu.segmentIDs)-1] + 1
+	}
+	seg, err := openWALSegment(db.dir, id, false)
+	if err != nil {
+		return err
+	}
+	if err := seg.file.Truncate(0); err != nil {
+		seg.close()
+		return err
+	}
+	if err := seg.write(db.getCheckpoint()); err != nil {
+		seg.close()
+		return err
+	}
+	if err := walSyncFile(seg.file); err != nil {
+		seg.close()
+		db.fail(err)
+		return err
+	}
+	if err := fileutil.SyncDir(db.dir); err != nil {
+		seg.close()
+		return err
+	}
+	seg.base = seg.size
+	db.mu.segments[id] = seg
+	db.mu.segmentIDs = append(db.mu.segmentIDs, id)
+	db.mu.active = seg
+	return nil
+}
+
+func (db *WALLogDB) getCheckpoint() []byte {
+	nis := make([]raftio.NodeInfo, 0, len(db.mu.nodes))
+	for ni := range db.mu.nodes {
+		nis = append(nis, ni)
+	}
+	sort.Slice(nis, func(i, j int) bool {
+		if nis[i].ClusterID != nis[j].ClusterID {
+			return nis[i].ClusterID < nis[j].ClusterID
+		}
+		return nis[i].NodeID < nis[j].NodeID
+	})
+	var data []byte
+	for _, ni := range nis {
+		n := db.mu.nodes[ni]
+		cid, nid := ni.ClusterID, ni.NodeID
+		data = appendWALRecord(data, walCheckpoint, cid, nid, getEntryRefs(n))
+		if n.bootstrap != nil {
+			data = appendWALRecord(data, walBootstrap, cid, nid, n.bootstrap)
+		}
+		if n.state != nil {
+			data = appendWALRecord(data, walState, cid, nid, n.state)
+		}
+		indexes := make([]uint64, 0, len(n.snapshots))
+		for index := range n.snapshots {
+			indexes = append(indexes, index)
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+		for _, index := range indexes {
+			ss := n.snapshots[index]
+			data = appendWALRecord(data, walSnapshot, cid, nid, &ss)
+		}
+		if n.hasLog {
+			data = appendWALRecord(data, walMaxIndex, cid, nid, walIndex(n.maxIndex))
+		}
+		if n.removedTo > 0 {
+			data = appendWALRecord(data,
+				walRemoveEntries, cid, nid, walIndex(n.removedTo))
+		}
+	}
+	return data
+}
+
+// getEntryRefs returns the locations of all live entries of the raft node.
+func getEntryRefs(n *walNode) walEntryRefs {
+	refs := make(walEntryRefs, 0)
+	for _, p := range n.entries {
+		if len(refs) > 0 {
+			last := &refs[len(refs)-1]
+			if last.segID == p.segID && last.offset == p.offset &&
+				last.high == p.index {
+				last.high++
+				continue
+			}
+		}
+		refs = append(refs, walEntryRef{
+			segID:  p.segID,
+			offset: p.offset,
+			low:    p.index,
+			high:   p.index + 1,
+		})
+	}
+	return refs
+}
+
+// removeObsoleteSegments deletes all inactive segments that no longer contain
+// any live entry, wherever they are located. All writes made to inactive
+// segments have been fsynced and every segment begins with a checkpoint of
+// the in-memory index, so the replay of remaining segments doesn't depend on
+// deleted ones.
+func (db *WALLogDB) removeObsoleteSegments() error {
+	removed := false
+	ids := db.mu.segmentIDs[:0]
+	for _, id := range db.mu.segmentIDs {
+		seg := db.mu.segments[id]
+		if seg == db.mu.active || seg.refs > 0 {
+			ids = append(ids, id)
+			continue
+		}
+		if err := seg.close(); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(db.dir,
+			getWALSegmentFilename(id))); err != nil {
+			return err
+		}
+		delete(db.mu.segments, id)
+		removed = true
+	}
+	db.mu.segmentIDs = ids
+	if removed {
+		return fileutil.SyncDir(db.dir)
+	}
+	return nil
+}
+
+func (db *WALLogDB) closeSegments() {
+	for _, seg := range db.mu.segments {
+		if err := seg.close(); err != nil {
+			plog.Errorf("failed to close segment %d, %v", seg.id, err)
+		}
+	}
+	db.mu.segments = make(map[uint64]*walSegment)
+	db.mu.segmentIDs = db.mu.segmentIDs[:0]
+	db.mu.active = nil
+	db.mu.pending = nil
+}
+
+// replay rebuilds the in-memory index from all existing segments. A torn
+// write at the end of the last segment is discarded, it is ignored when the
+// WALLogDB is read only.
+func (db *WALLogDB) replay() error {
+	ids, err := getWALSegmentIDList(db.dir)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i, id := range ids {
+		seg, err := openWALSegment(db.dir, id, db.readOnly)
+		if err != nil {
+			return err
+		}
+		db.mu.segments[id] = seg
+		db.mu.segmentIDs = append(db.mu.segmentIDs, id)
+		data, err := ioutil.ReadAll(seg.file)
+		if err != nil {
+			return err
+		}
+		for offset := 0; offset < len(data); {
+			r, sz, err := readWALRecord(data[offset:])
+			if err != nil {
+				if i != len(ids)-1 {
+					return errWALCorrupted
+				}
+				if db.readOnly {
+					plog.Warningf("ignoring %d bytes torn write in segment %d",
+						len(data)-offset, id)
+					break
+				}
+				plog.Warningf("discarding %d bytes torn write in segment %d",
+					len(data)-offset, id)
+				if err := seg.file.Truncate(int64(offset)); err != nil {
+					return err
+				}
+				if err := seg.file.Sync(); err != nil {
+					return err
+				}
+				break
+			}
+			if err := db.applyRecord(r, id, uint64(offset), uint64(sz)); err != nil {
+				return err
+			}
+			offset += sz
+		}
+		seg.size = uint64(len(data))
+	}
+	return nil
+}
+
+func (db *WALLogDB) applyRecord(r walRecord,
+	segID uint64, offset uint64, length uint64) error {
+	n := db.getNode(r.clusterID, r.nodeID)
+	switch r.rt {
+	case walBootstrap:
+		var bs pb.Bootstrap
+		if err := bs.Unmarshal(r.body); err != nil {
+			return err
+		}
+		n.bootstrap = &bs
+	case walState:
+		var st pb.State
+		if err := st.Unmarshal(r.body); err != nil {
+			return err
+		}
+		n.state = &st
+	case walEntries:
+		var eb pb.EntryBatch
+		if err := eb.Unmarshal(r.body); err != nil {
+			return err
+		}
+		db.appendEntries(n, eb.Entries, segID, offset, length)
+	case walSnapshot:
+		var ss pb.Snapshot
+		if err := ss.Unmarshal(r.body); err != nil {
+			return err
+		}
+		n.snapshots[ss.Index] = ss
+	case walDeleteSnapshot:
+		delete(n.snapshots, r.index())
+	case walMaxIndex:
+		db.setMaxIndex(n, r.index())
+	case walRemoveEntries:
+		db.removeEntries(n, r.index())
+	case walCheckpoint:
+		refs, err := r.entryRefs()
+		if err != nil {
+			return err
+		}
+		// metadata is followed by the rest of the checkpoint
+		n.snapshots = make(map[uint64]pb.Snapshot)
+		db.keepEntries(n, refs)
+	default:
+		return errWALCorrupted
+	}
+	return nil
+}
+
+func (db *WALLogDB) getNode(clusterID uint64, nodeID uint64) *walNode {
+	ni := raftio.GetNodeInfo(clusterID, nodeID)
+	n, ok := db.mu.nodes[ni]
+	if !ok {
+		n = newWALNode()
+		db.mu.nodes[ni] = n
+	}
+	return n
+}
+
+func (db *WALLogDB) appendEntries(n *walNode, entries []pb.Entry,
+	segID uint64, offset uint64, length uint64) {
+	maxIndex := uint64(0)
+	for i, e := range entries {
+		db.truncateEntries(n, e.Index)
+		n.entries = append(n.entries, walEntryPos{
+			index:  e.Index,
+			segID:  segID,
+			offset: offset,
+			length: length,
+			pos:    uint64(i),
+		})
+		db.mu.segments[segID].refs++
+		if e.Index > maxIndex {
+			maxIndex = e.Index
+		}
+	}
+	db.setMaxIndex(n, maxIndex)
+}
+
+func (db *WALLogDB) setMaxIndex(n *walNode, index uint64) {
+	n.hasLog = true
+	n.maxIndex = index
+	db.truncateEntries(n, index+1)
+}
+
+// truncateEntries drops entries with their index no less than the specified
+// index.
+func (db *WALLogDB) truncateEntries(n *walNode, index uint64) {
+	i := n.first(index)
+	db.releaseEntries(n.entries[i:])
+	n.entries = n.entries[:i]
+}
+
+// removeEntries drops entries with their index less than the specified index.
+func (db *WALLogDB) removeEntries(n *walNode, index uint64) {
+	if index > n.removedTo {
+		n.removedTo = index
+	}
+	i := n.first(index)
+	if i == 0 {
+		return
+	}
+	db.releaseEntries(n.entries[:i])
+	n.entries = append([]walEntryPos(nil), n.entries[i:]...)
+}
+
+// keepEntries drops entries not referenced by the specified refs. Entries
+// found in older segments might have been overwritten or removed by records
+// stored in deleted segments, they are dropped here.
+func (db *WALLogDB) keepEntries(n *walNode, refs walEntryRefs) {
+	type loc struct {
+		segID  uint64
+		offset uint64
+	}
+	live := make(map[loc]walEntryRef, len(refs))
+	for _, ref := range refs {
+		live[loc{ref.segID, ref.offset}] = ref
+	}
+	entries := make([]walEntryPos, 0, len(n.entries))
+	for _, p := range n.entries {
+		ref, ok := live[loc{p.segID, p.offset}]
+		if ok && p.index >= ref.low && p.index < ref.high {
+			entries = append(entries, p)
+		} else {
+			db.releaseEntries([]walEntryPos{p})
+		}
+	}
+	n.entries = entries
+}
+
+func (db *WALLogDB) releaseEntries(entries []walEntryPos) {
+	for _, p := range entries {
+		db.mu.segments[p.segID].refs--
+	}
+}
+
+func (db *WALLogDB) readEntryBatch(eb *pb.EntryBatch, p walEntryPos) error {
+	seg, ok := db.mu.segments[p.segID]
+	if !ok {
+		panic("segment not found")
+	}
+	r, err := seg.read(p.offset, p.length)
+	if err != nil {
+		return err
+	}
+	if r.rt != walEntries {
+		return errWALCorrupted
+	}
+	*eb = pb.EntryBatch{}
+	return eb.Unmarshal(r.body)
+}
@@ -0,0 +1,487 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdb
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/lni/dragonboat/internal/utils/fileutil"
+	"github.com/lni/dragonboat/internal/utils/leaktest"
+	"github.com/lni/dragonboat/raftio"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func getTestWALDir() string {
+	return filepath.Join(RDBTestDirectory, "wal-db-dir", walDirName)
+}
+
+func reopenTestWALDB(t *testing.T, db raftio.ILogDB) raftio.ILogDB {
+	db.Close()
+	return getNewTestWALDB("db-dir", "wal-db-dir")
+}
+
+func saveTestWALEntries(t *testing.T, db raftio.ILogDB,
+	clusterID uint64, low uint64, high uint64) {
+	for i := low; i < high; i++ {
+		ud := pb.Update{
+			ClusterID:     clusterID,
+			NodeID:        1,
+			State:         pb.State{Term: 1, Commit: i},
+			EntriesToSave: []pb.Entry{{Index: i, Term: 1, Cmd: make([]byte, 128)}},
+		}
+		ctx := newRDBContext(1, nil)
+		if err := db.SaveRaftState([]pb.Update{ud}, ctx); err != nil {
+			t.Fatalf("failed to save raft state %v", err)
+		}
+	}
+}
+
+func checkTestWALEntries(t *testing.T, db raftio.ILogDB,
+	clusterID uint64, low uint64, high uint64) {
+	ents, _, err := db.IterateEntries(nil,
+		0, clusterID, 1, low, high, math.MaxUint64)
+	if err != nil {
+		t.Fatalf("IterateEntries failed %v", err)
+	}
+	if uint64(len(ents)) != high-low {
+		t.Fatalf("got %d entries, want %d", len(ents), high-low)
+	}
+	for i, e := range ents {
+		if e.Index != low+uint64(i) || len(e.Cmd) != 128 {
+			t.Errorf("unexpected entry %v", e)
+		}
+	}
+}
+
+func setTestWALSegmentSize(sz uint64) func() {
+	oldSize := walSegmentSize
+	walSegmentSize = sz
+	return func() {
+		walSegmentSize = oldSize
+	}
+}
+
+func TestWALRecordCanBeEncodedAndDecoded(t *testing.T) {
+	ss := pb.Snapshot{Index: 100, Term: 2, Filepath: "p1"}
+	data := appendWALRecord(nil, walSnapshot, 1, 2, &ss)
+	data = appendWALRecord(data, walMaxIndex, 3, 4, walIndex(100))
+	r, sz, err := readWALRecord(data)
+	if err != nil {
+		t.Fatalf("failed to read record %v", err)
+	}
+	if r.rt != walSnapshot || r.clusterID != 1 || r.nodeID != 2 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	var rss pb.Snapshot
+	if err := rss.Unmarshal(r.body); err != nil {
+		t.Fatalf("failed to unmarshal %v", err)
+	}
+	if rss.Index != 100 || rss.Term != 2 || rss.Filepath != "p1" {
+		t.Errorf("unexpected snapshot %v", rss)
+	}
+	r, _, err = readWALRecord(data[sz:])
+	if err != nil {
+		t.Fatalf("failed to read record %v", err)
+	}
+	if r.rt != walMaxIndex || r.index() != 100 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	data[len(data)-1]++
+	if _, _, err := readWALRecord(data[sz:]); err != errWALTornWrite {
+		t.Errorf("checksum mismatch not reported, %v", err)
+	}
+	if _, _, err := readWALRecord(data[:sz-1]); err != errWALTornWrite {
+		t.Errorf("incomplete record not reported, %v", err)
+	}
+}
+
+func TestWALLogDBCanBeReopened(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		bs := pb.Bootstrap{Join: true, Addresses: map[uint64]string{1: "a1"}}
+		if err := db.SaveBootstrapInfo(1, 1, bs); err != nil {
+			t.Fatalf("failed to save bootstrap info %v", err)
+		}
+		saveTestWALEntries(t, db, 1, 1, 20)
+		ss := pb.Update{ClusterID: 1, NodeID: 1, Snapshot: pb.Snapshot{Index: 5}}
+		if err := db.SaveSnapshots([]pb.Update{ss}); err != nil {
+			t.Fatalf("failed to save snapshot %v", err)
+		}
+		if err := db.RemoveEntriesTo(1, 1, 5); err != nil {
+			t.Fatalf("failed to remove entries %v", err)
+		}
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		ni, err := db.ListNodeInfo()
+		if err != nil {
+			t.Fatalf("failed to list node info %v", err)
+		}
+		if len(ni) != 1 || ni[0] != raftio.GetNodeInfo(1, 1) {
+			t.Errorf("unexpected node info %v", ni)
+		}
+		rbs, err := db.GetBootstrapInfo(1, 1)
+		if err != nil {
+			t.Fatalf("failed to get bootstrap info %v", err)
+		}
+		if !rbs.Join || rbs.Addresses[1] != "a1" {
+			t.Errorf("unexpected bootstrap info %v", rbs)
+		}
+		rs, err := db.ReadRaftState(1, 1, 5)
+		if err != nil {
+			t.Fatalf("failed to read raft state %v", err)
+		}
+		if rs.State.Commit != 19 || rs.FirstIndex != 5 || rs.EntryCount != 15 {
+			t.Errorf("unexpected raft state %+v", rs)
+		}
+		checkTestWALEntries(t, db, 1, 5, 20)
+		snapshots, err := db.ListSnapshots(1, 1)
+		if err != nil {
+			t.Fatalf("failed to list snapshots %v", err)
+		}
+		if len(snapshots) != 1 || snapshots[0].Index != 5 {
+			t.Errorf("unexpected snapshots %v", snapshots)
+		}
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBTornWriteIsDiscarded(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 10)
+		db.Close()
+		ids, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		fp := filepath.Join(getTestWALDir(),
+			getWALSegmentFilename(ids[len(ids)-1]))
+		f, err := os.OpenFile(fp, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("failed to open segment %v", err)
+		}
+		if _, err := f.Write([]byte{0, 0, 1, 0, 1, 2}); err != nil {
+			t.Fatalf("failed to write %v", err)
+		}
+		f.Close()
+		db = getNewTestWALDB("db-dir", "wal-db-dir")
+		checkTestWALEntries(t, db, 1, 1, 10)
+		saveTestWALEntries(t, db, 1, 10, 15)
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		checkTestWALEntries(t, db, 1, 1, 15)
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestReadOnlyWALLogDBDoesNotModifySegments(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 10)
+		db.Close()
+		ids, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		fp := filepath.Join(getTestWALDir(),
+			getWALSegmentFilename(ids[len(ids)-1]))
+		f, err := os.OpenFile(fp, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("failed to open segment %v", err)
+		}
+		if _, err := f.Write([]byte{0, 0, 1, 0, 1, 2}); err != nil {
+			t.Fatalf("failed to write %v", err)
+		}
+		f.Close()
+		fi, err := os.Stat(fp)
+		if err != nil {
+			t.Fatalf("failed to stat segment %v", err)
+		}
+		db, err = OpenWALLogDBForReadOnly(
+			[]string{filepath.Join(RDBTestDirectory, "db-dir")},
+			[]string{filepath.Join(RDBTestDirectory, "wal-db-dir")})
+		if err != nil {
+			t.Fatalf("failed to open read only db %v", err)
+		}
+		checkTestWALEntries(t, db, 1, 1, 10)
+		ud := pb.Update{ClusterID: 1, NodeID: 1, State: pb.State{Term: 2}}
+		if err := db.SaveRaftState([]pb.Update{ud},
+			newRDBContext(1, nil)); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := db.RemoveEntriesTo(1, 1, 5); err != errReadOnlyLogDB {
+			t.Errorf("unexpected error %v", err)
+		}
+		db.Close()
+		newIDs, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if len(newIDs) != len(ids) {
+			t.Errorf("segments changed, %v, %v", ids, newIDs)
+		}
+		nfi, err := os.Stat(fp)
+		if err != nil {
+			t.Fatalf("failed to stat segment %v", err)
+		}
+		if nfi.Size() != fi.Size() {
+			t.Errorf("torn write discarded")
+		}
+		db = getNewTestWALDB("db-dir", "wal-db-dir")
+		defer db.Close()
+		checkTestWALEntries(t, db, 1, 1, 10)
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestReadOnlyWALLogDBMustExist(t *testing.T) {
+	defer deleteTestDB()
+	_, err := OpenWALLogDBForReadOnly(
+		[]string{filepath.Join(RDBTestDirectory, "db-dir")}, nil)
+	if err == nil {
+		t.Errorf("missing wal LogDB not reported")
+	}
+	if fileutil.Exist(filepath.Join(RDBTestDirectory, "db-dir")) {
+		t.Errorf("dir unexpectedly created")
+	}
+}
+
+func TestWALLogDBFailsToOpenWhenOlderSegmentIsCorrupted(t *testing.T) {
+	defer setTestWALSegmentSize(1024)()
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 50)
+		db.Close()
+		ids, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if len(ids) < 3 {
+			t.Fatalf("unexpected segment count %d", len(ids))
+		}
+		fp := filepath.Join(getTestWALDir(), getWALSegmentFilename(ids[0]))
+		f, err := os.OpenFile(fp, os.O_RDWR, 0644)
+		if err != nil {
+			t.Fatalf("failed to open segment %v", err)
+		}
+		if _, err := f.WriteAt([]byte{0xFF}, 100); err != nil {
+			t.Fatalf("failed to write %v", err)
+		}
+		f.Close()
+		rdb, err := OpenWALLogDB(
+			[]string{filepath.Join(RDBTestDirectory, "db-dir")},
+			[]string{filepath.Join(RDBTestDirectory, "wal-db-dir")})
+		if err != errWALCorrupted {
+			t.Errorf("corrupted segment not reported, %v", err)
+		}
+		if rdb != nil {
+			t.Errorf("unexpected db instance")
+		}
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBSegmentsAreRemovedOnceEntriesAreCompacted(t *testing.T) {
+	defer setTestWALSegmentSize(1024)()
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 100)
+		saveTestWALEntries(t, db, 2, 1, 10)
+		ids, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if len(ids) < 10 {
+			t.Fatalf("unexpected segment count %d", len(ids))
+		}
+		if err := db.RemoveEntriesTo(1, 1, 90); err != nil {
+			t.Fatalf("failed to remove entries %v", err)
+		}
+		ids2, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if len(ids2) >= len(ids) {
+			t.Errorf("segments not removed, %d, %d", len(ids2), len(ids))
+		}
+		if ids2[0] <= ids[0] {
+			t.Errorf("oldest segment not removed")
+		}
+		checkTestWALEntries(t, db, 1, 90, 100)
+		checkTestWALEntries(t, db, 2, 1, 10)
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		checkTestWALEntries(t, db, 1, 90, 100)
+		checkTestWALEntries(t, db, 2, 1, 10)
+		rs, err := db.ReadRaftState(1, 1, 90)
+		if err != nil {
+			t.Fatalf("failed to read raft state %v", err)
+		}
+		if rs.State.Commit != 99 || rs.FirstIndex != 90 || rs.EntryCount != 10 {
+			t.Errorf("unexpected raft state %+v", rs)
+		}
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBOverwrittenEntriesAreNotReplayed(t *testing.T) {
+	defer setTestWALSegmentSize(1024)()
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 30)
+		ud := pb.Update{
+			ClusterID:     1,
+			NodeID:        1,
+			EntriesToSave: []pb.Entry{{Index: 10, Term: 2, Cmd: make([]byte, 128)}},
+		}
+		if err := db.SaveRaftState([]pb.Update{ud}, newRDBContext(1, nil)); err != nil {
+			t.Fatalf("failed to save raft state %v", err)
+		}
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		ents, _, err := db.IterateEntries(nil, 0, 1, 1, 1, 30, math.MaxUint64)
+		if err != nil {
+			t.Fatalf("IterateEntries failed %v", err)
+		}
+		if len(ents) != 10 {
+			t.Fatalf("got %d entries, want 10", len(ents))
+		}
+		if ents[9].Index != 10 || ents[9].Term != 2 {
+			t.Errorf("unexpected last entry %v", ents[9])
+		}
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBConcurrentWritesCanBeSaved(t *testing.T) {
+	defer setTestWALSegmentSize(4096)()
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		var wg sync.WaitGroup
+		for i := uint64(1); i <= 8; i++ {
+			wg.Add(1)
+			go func(clusterID uint64) {
+				defer wg.Done()
+				saveTestWALEntries(t, db, clusterID, 1, 50)
+			}(i)
+		}
+		wg.Wait()
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		for i := uint64(1); i <= 8; i++ {
+			checkTestWALEntries(t, db, i, 1, 50)
+		}
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBReturnsErrorAfterClose(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer deleteTestDB()
+	db := getNewTestWALDB("db-dir", "wal-db-dir")
+	db.Close()
+	bs := pb.Bootstrap{Join: true}
+	if err := db.SaveBootstrapInfo(1, 1, bs); err != os.ErrClosed {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWALLogDBIndexIsNotUpdatedWhenFsyncFails(t *testing.T) {
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 10)
+		errFsync := errors.New("fsync failed")
+		oldSyncFile := walSyncFile
+		walSyncFile = func(f *os.File) error { return errFsync }
+		defer func() {
+			walSyncFile = oldSyncFile
+		}()
+		ud := pb.Update{
+			ClusterID:     1,
+			NodeID:        1,
+			State:         pb.State{Term: 1, Commit: 10},
+			EntriesToSave: []pb.Entry{{Index: 10, Term: 1, Cmd: make([]byte, 128)}},
+		}
+		ctx := newRDBContext(1, nil)
+		if err := db.SaveRaftState([]pb.Update{ud}, ctx); err != errFsync {
+			t.Fatalf("fsync error not returned, %v", err)
+		}
+		walSyncFile = oldSyncFile
+		checkTestWALEntries(t, db, 1, 1, 10)
+		rs, err := db.ReadRaftState(1, 1, 1)
+		if err != nil {
+			t.Fatalf("failed to read raft state %v", err)
+		}
+		if rs.State.Commit != 9 || rs.EntryCount != 9 {
+			t.Errorf("unexpected raft state %+v", rs)
+		}
+		if err := db.RemoveEntriesTo(1, 1, 5); err != errFsync {
+			t.Errorf("write accepted after failed fsync, %v", err)
+		}
+		checkTestWALEntries(t, db, 1, 1, 10)
+	}
+	runWALDBTest(t, tf)
+}
+
+func TestWALLogDBIdleClusterDoesNotBlockSegmentRemoval(t *testing.T) {
+	defer setTestWALSegmentSize(1024)()
+	tf := func(t *testing.T, db raftio.ILogDB) {
+		saveTestWALEntries(t, db, 1, 1, 4)
+		ss := pb.Update{ClusterID: 2, NodeID: 1, Snapshot: pb.Snapshot{Index: 1}}
+		if err := db.SaveSnapshots([]pb.Update{ss}); err != nil {
+			t.Fatalf("failed to save snapshot %v", err)
+		}
+		saveTestWALEntries(t, db, 2, 1, 100)
+		if err := db.DeleteSnapshot(2, 1, 1); err != nil {
+			t.Fatalf("failed to delete snapshot %v", err)
+		}
+		saveTestWALEntries(t, db, 2, 100, 110)
+		ids, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if err := db.RemoveEntriesTo(2, 1, 105); err != nil {
+			t.Fatalf("failed to remove entries %v", err)
+		}
+		ids2, err := getWALSegmentIDList(getTestWALDir())
+		if err != nil {
+			t.Fatalf("failed to list segments %v", err)
+		}
+		if len(ids2) > 10 {
+			t.Errorf("segments not removed, %d, %d", len(ids2), len(ids))
+		}
+		if ids2[0] != ids[0] {
+			t.Errorf("segment with live entries removed")
+		}
+		checkTestWALEntries(t, db, 1, 1, 4)
+		checkTestWALEntries(t, db, 2, 105, 110)
+		db = reopenTestWALDB(t, db)
+		defer db.Close()
+		checkTestWALEntries(t, db, 1, 1, 4)
+		checkTestWALEntries(t, db, 2, 105, 110)
+		rs, err := db.ReadRaftState(2, 1, 1)
+		if err != nil {
+			t.Fatalf("failed to read raft state %v", err)
+		}
+		if rs.State.Commit != 109 || rs.FirstIndex != 105 || rs.EntryCount != 5 {
+			t.Errorf("unexpected raft state %+v", rs)
+		}
+		snapshots, err := db.ListSnapshots(2, 1)
+		if err != nil {
+			t.Fatalf("failed to list snapshots %v", err)
+		}
+		if len(snapshots) != 0 {
+			t.Errorf("deleted snapshot restored, %v", snapshots)
+		}
+	}
+	runWALDBTest(t, tf)
+}
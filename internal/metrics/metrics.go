@@ -0,0 +1,421 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package metrics implements a minimal metrics registry with counters, gauges
+and histograms. Collected metrics can be exported in the Prometheus text
+exposition format.
+
+All methods are safe to be invoked on nil Registry, Counter, Gauge and
+Histogram instances, this allows the registry to be optional without having
+to check whether metrics are enabled at each call site.
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+)
+
+var (
+	// LatencyBuckets are the default histogram buckets in seconds used for
+	// latency metrics.
+	LatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005,
+		0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// DurationBuckets are the default histogram buckets in seconds used for
+	// long running operations such as snapshotting.
+	DurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300,
+		600, 1800}
+	// SizeBuckets are the default histogram buckets used for size and count
+	// metrics.
+	SizeBuckets = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048,
+		4096, 8192}
+)
+
+type metric interface {
+	write(w io.Writer, name string, labels string)
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	metrics map[string]metric
+}
+
+// Registry is a collection of named metrics.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry creates a new Registry instance.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter returns the counter identified by the specified name and labels,
+// the counter is created when it doesn't exist. Labels are specified as
+// key value pairs.
+func (r *Registry) Counter(name string,
+	help string, labels ...string) *Counter {
+	if r == nil {
+		return nil
+	}
+	m := r.getOrCreate(name, help, counterType, labels, func() metric {
+		return &Counter{}
+	})
+	return m.(*Counter)
+}
+
+// Gauge returns the gauge identified by the specified name and labels, the
+// gauge is created when it doesn't exist. Labels are specified as key value
+// pairs.
+func (r *Registry) Gauge(name string,
+	help string, labels ...string) *Gauge {
+	if r == nil {
+		return nil
+	}
+	m := r.getOrCreate(name, help, gaugeType, labels, func() metric {
+		return &Gauge{}
+	})
+	return m.(*Gauge)
+}
+
+// GaugeFunc registers a gauge identified by the specified name and labels,
+// its value is obtained by invoking f each time when the registry is written.
+func (r *Registry) GaugeFunc(name string,
+	help string, f func() float64, labels ...string) {
+	if r == nil {
+		return
+	}
+	r.getOrCreate(name, help, gaugeType, labels, func() metric {
+		return gaugeFunc(f)
+	})
+}
+
+// Histogram returns the histogram identified by the specified name and
+// labels, the histogram is created with the specified upper bounds when it
+// doesn't exist. Labels are specified as key value pairs.
+func (r *Registry) Histogram(name string, help string,
+	buckets []float64, labels ...string) *Histogram {
+	if r == nil {
+		return nil
+	}
+	m := r.getOrCreate(name, help, histogramType, labels, func() metric {
+		return newHistogram(buckets)
+	})
+	return m.(*Histogram)
+}
+
+// Remove removes all metrics with the specified label values from the
+// registry. It is typically used to remove metrics owned by a stopped
+// component.
+func (r *Registry) Remove(labels ...string) {
+	if r == nil {
+		return
+	}
+	pairs := labelPairs(labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.families {
+		for key := range f.metrics {
+			if containsAll(key, pairs) {
+				delete(f.metrics, key)
+			}
+		}
+	}
+}
+
+// Write writes all metrics to the specified writer in the Prometheus text
+// exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := r.families[name]
+		if len(f.metrics) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		keys := make([]string, 0, len(f.metrics))
+		for key := range f.metrics {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f.metrics[key].write(bw, f.name, key)
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler returns a http.Handler for serving the metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := r.Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (r *Registry) getOrCreate(name string, help string, typ string,
+	labels []string, create func() metric) metric {
+	key := labelString(labelPairs(labels))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{
+			name:    name,
+			help:    help,
+			typ:     typ,
+			metrics: make(map[string]metric),
+		}
+		r.families[name] = f
+	} else if f.typ != typ {
+		panic(fmt.Sprintf("metric %s registered as %s", name, f.typ))
+	}
+	m, ok := f.metrics[key]
+	if !ok {
+		m = create()
+		f.metrics[key] = m
+	}
+	return m
+}
+
+// Counter is a metric with monotonically increasing value.
+type Counter struct {
+	value uint64
+}
+
+// Inc increases the counter by 1.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increases the counter by v.
+func (c *Counter) Add(v uint64) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.value, v)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() uint64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) write(w io.Writer, name string, labels string) {
+	fmt.Fprintf(w, "%s%s %d\n", name, labels, c.Value())
+}
+
+// Gauge is a metric with arbitrary value.
+type Gauge struct {
+	bits uint64
+}
+
+// Set sets the value of the gauge.
+func (g *Gauge) Set(v float64) {
+	if g == nil {
+		return
+	}
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 {
+	if g == nil {
+		return 0
+	}
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+func (g *Gauge) write(w io.Writer, name string, labels string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(g.Value()))
+}
+
+type gaugeFunc func() float64
+
+func (g gaugeFunc) write(w io.Writer, name string, labels string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(g()))
+}
+
+// Histogram is a metric that samples observed values into buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &Histogram{
+		buckets: b,
+		counts:  make([]uint64, len(b)),
+	}
+}
+
+// Observe adds the specified value to the histogram.
+func (h *Histogram) Observe(v float64) {
+	if h == nil {
+		return
+	}
+	idx := sort.SearchFloat64s(h.buckets, v)
+	h.mu.Lock()
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.count++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// ObserveSince adds the number of seconds elapsed since the specified start
+// time to the histogram.
+func (h *Histogram) ObserveSince(start time.Time) {
+	if h == nil {
+		return
+	}
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observed values.
+func (h *Histogram) Count() uint64 {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) write(w io.Writer, name string, labels string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cumulative := uint64(0)
+	for i, ub := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n",
+			name, withLabel(labels, "le", formatFloat(ub)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n",
+		name, withLabel(labels, "le", "+Inf"), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+func labelPairs(labels []string) []string {
+	if len(labels)%2 != 0 {
+		panic("labels must be specified as key value pairs")
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs,
+			fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabel(labels[i+1])))
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+func labelString(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func containsAll(key string, pairs []string) bool {
+	if len(key) == 0 {
+		return len(pairs) == 0
+	}
+	existing := strings.Split(key[1:len(key)-1], ",")
+	for _, p := range pairs {
+		found := false
+		for _, e := range existing {
+			if e == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func withLabel(labels string, key string, value string) string {
+	pair := fmt.Sprintf("%s=\"%s\"", key, value)
+	if len(labels) == 0 {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+func escapeLabel(v string) string {
+	v = strings.Replace(v, `\`, `\\`, -1)
+	v = strings.Replace(v, "\n", `\n`, -1)
+	return strings.Replace(v, `"`, `\"`, -1)
+}
+
+func escapeHelp(v string) string {
+	v = strings.Replace(v, `\`, `\\`, -1)
+	return strings.Replace(v, "\n", `\n`, -1)
+}
@@ -0,0 +1,141 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNilRegistryCanBeUsed(t *testing.T) {
+	var r *Registry
+	r.Counter("c", "help").Inc()
+	r.Gauge("g", "help").Set(1.0)
+	r.Histogram("h", "help", LatencyBuckets).Observe(1.0)
+	r.GaugeFunc("f", "help", func() float64 { return 1.0 })
+	r.Remove("cluster_id", "1")
+	if err := r.Write(&bytes.Buffer{}); err != nil {
+		t.Errorf("write failed %v", err)
+	}
+}
+
+func TestMetricsAreReturnedByLabels(t *testing.T) {
+	r := NewRegistry()
+	c1 := r.Counter("c", "help", "cluster_id", "1")
+	c2 := r.Counter("c", "help", "cluster_id", "2")
+	if c1 == c2 {
+		t.Fatalf("same counter returned for different labels")
+	}
+	c1.Add(2)
+	if r.Counter("c", "help", "cluster_id", "1").Value() != 2 {
+		t.Errorf("unexpected counter value")
+	}
+	h1 := r.Histogram("h", "help", SizeBuckets, "a", "1", "b", "2")
+	h2 := r.Histogram("h", "help", SizeBuckets, "b", "2", "a", "1")
+	if h1 != h2 {
+		t.Errorf("label order changed the returned metric")
+	}
+}
+
+func TestRegisteringMetricWithDifferentTypeWillPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic not triggered")
+		}
+	}()
+	r := NewRegistry()
+	r.Counter("m", "help")
+	r.Gauge("m", "help")
+}
+
+func TestMetricsCanBeRemoved(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("c", "help", "cluster_id", "1", "node_id", "2").Inc()
+	r.Counter("c", "help", "cluster_id", "2", "node_id", "2").Inc()
+	r.Remove("cluster_id", "1")
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatalf("write failed %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, `cluster_id="1"`) {
+		t.Errorf("metric not removed, %s", out)
+	}
+	if !strings.Contains(out, `cluster_id="2"`) {
+		t.Errorf("metric unexpectedly removed, %s", out)
+	}
+}
+
+func TestPrometheusTextFormat(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("requests_total", "total requests", "cluster_id", "1").Add(3)
+	r.Gauge("queue_length", "queue length").Set(2.5)
+	r.GaugeFunc("func_value", "func value", func() float64 { return 7 })
+	h := r.Histogram("latency_seconds", "latency", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatalf("write failed %v", err)
+	}
+	expected := []string{
+		"# HELP requests_total total requests",
+		"# TYPE requests_total counter",
+		`requests_total{cluster_id="1"} 3`,
+		"# TYPE queue_length gauge",
+		"queue_length 2.5",
+		"func_value 7",
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{le="0.1"} 1`,
+		`latency_seconds_bucket{le="1"} 2`,
+		`latency_seconds_bucket{le="+Inf"} 3`,
+		"latency_seconds_sum 5.55",
+		"latency_seconds_count 3",
+	}
+	out := buf.String()
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("%s not found in output\n%s", line, out)
+		}
+	}
+}
+
+func TestHistogramLabelsIncludeUpperBound(t *testing.T) {
+	r := NewRegistry()
+	r.Histogram("h", "help", []float64{1}, "cluster_id", "1").Observe(1)
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatalf("write failed %v", err)
+	}
+	if !strings.Contains(buf.String(), `h_bucket{cluster_id="1",le="1"} 1`) {
+		t.Errorf("unexpected output %s", buf.String())
+	}
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("c", "help").Inc()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "c 1\n") {
+		t.Errorf("unexpected body %s", rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("unexpected content type")
+	}
+}
@@ -33,6 +33,54 @@ func newEntrySlice(ents []pb.Entry) []pb.Entry {
 	return append(n, ents...)
 }
 
+// makeDroppedEntries returns a copy of the input entries with only those
+// fields required for identifying the proposals. the payloads are not
+// required when reporting dropped proposals.
+func makeDroppedEntries(ents []pb.Entry) []pb.Entry {
+	de := make([]pb.Entry, len(ents))
+	for i, e := range ents {
+		de[i] = pb.Entry{
+			Type:     e.Type,
+			Key:      e.Key,
+			ClientID: e.ClientID,
+			SeriesID: e.SeriesID,
+		}
+	}
+	return de
+}
+
+// makeMetadataEntries returns a copy of the input entries with the payload of
+// all non config change entries removed. witnesses only need the index and
+// term of entries, config change entries are kept as they are required for
+// maintaining the membership.
+func makeMetadataEntries(ents []pb.Entry) []pb.Entry {
+	me := make([]pb.Entry, len(ents))
+	for i, e := range ents {
+		if e.Type == pb.ConfigChangeEntry {
+			me[i] = e
+		} else {
+			me[i] = pb.Entry{
+				Type:  pb.MetadataEntry,
+				Index: e.Index,
+				Term:  e.Term,
+			}
+		}
+	}
+	return me
+}
+
+// makeWitnessSnapshot returns a snapshot that only contains the metadata of
+// the specified snapshot. it is sent to witnesses in place of the regular
+// snapshot.
+func makeWitnessSnapshot(ss pb.Snapshot) pb.Snapshot {
+	return pb.Snapshot{
+		Index:      ss.Index,
+		Term:       ss.Term,
+		Membership: ss.Membership,
+		Witness:    true,
+	}
+}
+
 func checkEntriesToAppend(ents []pb.Entry, toAppend []pb.Entry) {
 	if len(ents) == 0 || len(toAppend) == 0 {
 		return
@@ -103,6 +151,7 @@ func isLocalMessageType(t pb.MessageType) bool {
 func isResponseMessageType(t pb.MessageType) bool {
 	return t == pb.ReplicateResp ||
 		t == pb.RequestVoteResp ||
+		t == pb.RequestPreVoteResp ||
 		t == pb.HeartbeatResp ||
 		t == pb.ReadIndexResp ||
 		t == pb.Unreachable ||
@@ -41,9 +41,12 @@ package raft
 
 import (
 	"sort"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/lni/dragonboat/config"
+	"github.com/lni/dragonboat/internal/server"
 	pb "github.com/lni/dragonboat/raftpb"
 )
 
@@ -56,9 +59,14 @@ type PeerAddress struct {
 // Peer is the interface struct for interacting with the underlying Raft
 // protocol implementation.
 type Peer struct {
-	leaderID  uint64
-	raft      *raft
-	prevState pb.State
+	leaderID       uint64
+	leaderCommit   uint64
+	leaderCommitAt int64
+	raft           *raft
+	prevState      pb.State
+	preferenceMu   sync.Mutex
+	preferred      uint64
+	region         []uint64
 }
 
 // LaunchPeer starts or restarts a Raft node.
@@ -68,8 +76,11 @@ func LaunchPeer(config *config.Config, logdb ILogDB,
 	r := newRaft(config, logdb)
 	rc := &Peer{raft: r}
 	rc.raft.recordLeader = rc.recordLeader
+	rc.raft.recordLeaderCommit = rc.recordLeaderCommit
+	rc.raft.recordPreference = rc.recordPreference
+	rc.recordPreference(r.preferredLeader, r.preferredRegion)
 	_, lastIndex := logdb.GetRange()
-	if newNode && !config.IsObserver {
+	if newNode && !config.IsObserver && !config.IsWitness {
 		r.becomeFollower(1, NoLeader)
 	}
 	plog.Infof("LaunchPeer %s, lastIndex %d, initial %t, newNode %t",
@@ -102,8 +113,21 @@ func (rc *Peer) QuiescedTick() {
 }
 
 // RequestLeaderTransfer makes a request to transfer the leadership to the
-// specified target node.
+// specified target node. When target is NoNode, the leader transfers its
+// leadership to the recently active voting member with the most replicated
+// log entries, the request is ignored on non-leader nodes.
 func (rc *Peer) RequestLeaderTransfer(target uint64) {
+	if target == NoNode {
+		if rc.raft.state != leader {
+			return
+		}
+		target = rc.raft.leaderTransferCandidate()
+		if target == NoNode {
+			plog.Warningf("%s no leader transfer target available",
+				rc.raft.describe())
+			return
+		}
+	}
 	plog.Infof("RequestLeaderTransfer called, target %d", target)
 	rc.raft.Handle(pb.Message{
 		Type: pb.LeaderTransfer,
@@ -113,6 +137,34 @@ func (rc *Peer) RequestLeaderTransfer(target uint64) {
 	})
 }
 
+// SetPreferredLeader sets the node preferred to be the leader, NoNode means
+// there is no preferred leader. The preference is forwarded to the leader
+// which broadcasts it to the rest of the cluster.
+func (rc *Peer) SetPreferredLeader(nodeID uint64) {
+	rc.raft.Handle(newPreferenceMessage(nodeID, rc.raft.preferredRegion))
+}
+
+// SetPreferredRegion sets the voting members located in the preferred region,
+// an empty region means there is no preferred region. The preference is
+// forwarded to the leader which broadcasts it to the rest of the cluster.
+func (rc *Peer) SetPreferredRegion(region []uint64) {
+	rc.raft.Handle(newPreferenceMessage(rc.raft.preferredLeader, region))
+}
+
+// GetPreference returns the preferred leader and the preferred region known
+// to the local node.
+func (rc *Peer) GetPreference() (uint64, []uint64) {
+	rc.preferenceMu.Lock()
+	defer rc.preferenceMu.Unlock()
+	return rc.preferred, append([]uint64(nil), rc.region...)
+}
+
+// SetDraining sets whether the node is being drained, a draining node doesn't
+// accept leadership transferred to it.
+func (rc *Peer) SetDraining(draining bool) {
+	rc.raft.setDraining(draining)
+}
+
 // ProposeEntries proposes specified entries in a batched mode using a single
 // MTPropose message.
 func (rc *Peer) ProposeEntries(ents []pb.Entry) {
@@ -137,6 +189,19 @@ func (rc *Peer) ProposeConfigChange(configChange pb.ConfigChange, key uint64) {
 
 // ApplyConfigChange applies a raft membership change to the local raft node.
 func (rc *Peer) ApplyConfigChange(cc pb.ConfigChange) {
+	if cc.IsJointConfigChange() {
+		data, err := cc.Marshal()
+		if err != nil {
+			panic(err)
+		}
+		rc.raft.Handle(pb.Message{
+			Type:     pb.ConfigChangeEvent,
+			Reject:   false,
+			HintHigh: uint64(cc.Type),
+			Entries:  []pb.Entry{{Type: pb.ConfigChangeEntry, Cmd: data}},
+		})
+		return
+	}
 	if cc.NodeID == NoLeader {
 		rc.raft.clearPendingConfigChange()
 		return
@@ -190,7 +255,8 @@ func (rc *Peer) Handle(m pb.Message) {
 	}
 	_, rok := rc.raft.remotes[m.From]
 	_, ook := rc.raft.observers[m.From]
-	if rok || ook || !isResponseMessageType(m.Type) {
+	_, wok := rc.raft.witnesses[m.From]
+	if rok || ook || wok || !isResponseMessageType(m.Type) {
 		rc.raft.Handle(m)
 	}
 }
@@ -207,6 +273,11 @@ func (rc *Peer) RateLimited() bool {
 	return rc.raft.rl.RateLimited()
 }
 
+// GetRateLimiter returns the rate limiter used by the raft node.
+func (rc *Peer) GetRateLimiter() *server.RateLimiter {
+	return rc.raft.rl
+}
+
 // HasUpdate returns a boolean value indicating whether there is any Update
 // ready to be processed.
 func (rc *Peer) HasUpdate(moreEntriesToApply bool) bool {
@@ -231,6 +302,9 @@ func (rc *Peer) HasUpdate(moreEntriesToApply bool) bool {
 	if len(r.readyToRead) != 0 {
 		return true
 	}
+	if len(r.droppedEntries) > 0 || len(r.droppedReadIndexes) > 0 {
+		return true
+	}
 	return false
 }
 
@@ -243,6 +317,9 @@ func (rc *Peer) Commit(ud pb.Update) {
 	if ud.UpdateCommit.ReadyToRead > 0 {
 		rc.raft.clearReadyToRead()
 	}
+	if ud.UpdateCommit.Dropped {
+		rc.raft.clearDroppedRequests()
+	}
 	rc.entryLog().commitUpdate(ud.UpdateCommit)
 }
 
@@ -271,6 +348,17 @@ func (rc *Peer) GetLeaderID() uint64 {
 	return atomic.LoadUint64(&rc.leaderID)
 }
 
+// GetLeaderCommit returns the highest commit index heard from the leader and
+// the time when the leader was last heard. The returned time is zero when the node has
+// never heard from any leader.
+func (rc *Peer) GetLeaderCommit() (uint64, time.Time) {
+	at := atomic.LoadInt64(&rc.leaderCommitAt)
+	if at == 0 {
+		return 0, time.Time{}
+	}
+	return atomic.LoadUint64(&rc.leaderCommit), time.Unix(0, at)
+}
+
 // NotifyRaftLastApplied passes on the lastApplied index confirmed by the RSM to
 // the raft state machine.
 func (rc *Peer) NotifyRaftLastApplied(lastApplied uint64) {
@@ -281,6 +369,22 @@ func (rc *Peer) recordLeader(leaderID uint64) {
 	atomic.StoreUint64(&rc.leaderID, leaderID)
 }
 
+func (rc *Peer) recordPreference(nodeID uint64, region []uint64) {
+	rc.preferenceMu.Lock()
+	defer rc.preferenceMu.Unlock()
+	rc.preferred = nodeID
+	rc.region = region
+}
+
+func (rc *Peer) recordLeaderCommit(index uint64) {
+	// the commit index in heartbeat messages is capped by the match value of
+	// the receiver, committed index never decreases so the highest one is kept
+	if index > atomic.LoadUint64(&rc.leaderCommit) {
+		atomic.StoreUint64(&rc.leaderCommit, index)
+	}
+	atomic.StoreInt64(&rc.leaderCommitAt, time.Now().UnixNano())
+}
+
 func (rc *Peer) entryLog() *entryLog {
 	return rc.raft.log
 }
@@ -339,6 +443,7 @@ func getUpdateCommit(ud pb.Update) pb.UpdateCommit {
 	uc := pb.UpdateCommit{
 		ReadyToRead: uint64(len(ud.ReadyToReads)),
 		LastApplied: ud.LastApplied,
+		Dropped:     len(ud.DroppedEntries) > 0 || len(ud.DroppedReadIndexes) > 0,
 	}
 	if len(ud.CommittedEntries) > 0 {
 		uc.Processed = ud.CommittedEntries[len(ud.CommittedEntries)-1].Index
@@ -379,6 +484,11 @@ func getUpdate(r *raft,
 	if len(r.readyToRead) > 0 {
 		ud.ReadyToReads = r.readyToRead
 	}
+	if len(r.droppedEntries) > 0 || len(r.droppedReadIndexes) > 0 {
+		ud.DroppedEntries = r.droppedEntries
+		ud.DroppedReadIndexes = r.droppedReadIndexes
+		ud.LeaderHint = r.droppedLeaderHint
+	}
 	ud.UpdateCommit = getUpdateCommit(ud)
 	return ud
 }
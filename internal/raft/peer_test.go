@@ -94,12 +94,92 @@ func TestLeaderIDCanBeReportedBackToPeer(t *testing.T) {
 	}
 }
 
+func TestLeaderCommitCanBeReportedBackToPeer(t *testing.T) {
+	s := NewTestLogDB()
+	p, err := LaunchPeer(newTestConfig(1, 10, 1, s), s, []PeerAddress{{NodeID: 1}}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.raft.hasNotAppliedConfigChange = p.raft.testOnlyHasConfigChangeToApply
+	if _, heardAt := p.GetLeaderCommit(); !heardAt.IsZero() {
+		t.Errorf("unexpected leader commit")
+	}
+	p.raft.becomeFollower(1, 2)
+	p.raft.handleFollowerReplicate(raftpb.Message{From: 2, Commit: 7, LogIndex: 1, LogTerm: 1})
+	commit, heardAt := p.GetLeaderCommit()
+	if commit != 7 || heardAt.IsZero() {
+		t.Errorf("leader commit %d not reported back", commit)
+	}
+	// the commit value of the heartbeat is capped by the match value
+	p.raft.handleFollowerHeartbeat(raftpb.Message{From: 2, Commit: 1})
+	commit, lastHeardAt := p.GetLeaderCommit()
+	if commit != 7 || lastHeardAt.Before(heardAt) {
+		t.Errorf("leader commit %d, want 7", commit)
+	}
+	// the uncapped commit index of the leader is carried in LogTerm
+	p.raft.handleFollowerHeartbeat(raftpb.Message{From: 2, Commit: 1, LogTerm: 9})
+	if commit, _ := p.GetLeaderCommit(); commit != 9 {
+		t.Errorf("leader commit %d, want 9", commit)
+	}
+	p.raft.becomeCandidate()
+	p.raft.becomeLeader()
+	for i := 0; i < 10; i++ {
+		p.raft.appendEntries([]raftpb.Entry{{}})
+	}
+	p.Tick()
+	if commit, _ := p.GetLeaderCommit(); commit != p.raft.log.committed {
+		t.Errorf("leader commit %d, want %d", commit, p.raft.log.committed)
+	}
+}
+
+func TestDroppedRequestsAreIncludedInUpdate(t *testing.T) {
+	s := NewTestLogDB()
+	p, err := LaunchPeer(newTestConfig(1, 10, 1, s), s, []PeerAddress{{NodeID: 1}}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.raft.hasNotAppliedConfigChange = p.raft.testOnlyHasConfigChangeToApply
+	p.raft.becomeFollower(1, NoLeader)
+	p.ProposeEntries([]raftpb.Entry{{Key: 100}})
+	p.ReadIndex(raftpb.SystemCtx{Low: 101, High: 1002})
+	if !p.HasUpdate(true) {
+		t.Fatalf("no update")
+	}
+	ud := p.GetUpdate(true, 0)
+	if len(ud.DroppedEntries) != 1 || len(ud.DroppedReadIndexes) != 1 {
+		t.Fatalf("dropped requests not included")
+	}
+	if !ud.UpdateCommit.Dropped {
+		t.Errorf("dropped flag not set")
+	}
+	p.Commit(ud)
+	if ud := p.GetUpdate(true, 0); len(ud.DroppedEntries) != 0 ||
+		len(ud.DroppedReadIndexes) != 0 {
+		t.Errorf("dropped requests not cleared")
+	}
+}
+
 func TestRaftAPIRequestLeaderTransfer(t *testing.T) {
 	s := NewTestLogDB()
 	p, _ := LaunchPeer(newTestConfig(1, 10, 1, s), s, []PeerAddress{{NodeID: 1}}, true, true)
 	p.RequestLeaderTransfer(1)
 }
 
+func TestLeaderTransferToUnspecifiedTargetCanBeRequested(t *testing.T) {
+	s := NewTestLogDB()
+	p, _ := LaunchPeer(newTestConfig(1, 10, 1, s), s,
+		[]PeerAddress{{NodeID: 1, Address: "a1"}, {NodeID: 2, Address: "a2"}},
+		true, true)
+	p.RequestLeaderTransfer(NoNode)
+	p.raft.becomeCandidate()
+	p.raft.becomeLeader()
+	p.raft.remotes[2].setLastActiveTick(p.raft.tickCount)
+	p.RequestLeaderTransfer(NoNode)
+	if !p.raft.leaderTransfering() || p.raft.leaderTransferTarget != 2 {
+		t.Errorf("leader transfer not started")
+	}
+}
+
 func TestRaftAPIRTT(t *testing.T) {
 	s := NewTestLogDB()
 	p, _ := LaunchPeer(newTestConfig(1, 10, 1, s), s, []PeerAddress{{NodeID: 1}}, true, true)
@@ -46,7 +46,7 @@ const (
 	// NoNode is the flag used to indicate that the node id field is not set.
 	NoNode          uint64 = 0
 	noLimit         uint64 = math.MaxUint64
-	numMessageTypes uint64 = 26
+	numMessageTypes uint64 = 32
 )
 
 var (
@@ -54,8 +54,10 @@ var (
 )
 
 // State is the state of a raft node defined in the raft paper, possible states
-// are leader, follower, candidate and observer. Observer is non-voting member
-// node.
+// are leader, follower, candidate, observer, preCandidate and witness. Observer
+// is non-voting member node, preCandidate is the state of a node in the PreVote
+// phase described in the raft thesis. Witness is a voting member node that
+// only keeps the metadata of log entries, it never becomes a candidate.
 type State uint64
 
 const (
@@ -63,6 +65,8 @@ const (
 	candidate
 	leader
 	observer
+	preCandidate
+	witness
 	numStates
 )
 
@@ -71,6 +75,8 @@ var stateNames = [...]string{
 	"Candidate",
 	"Leader",
 	"Observer",
+	"PreCandidate",
+	"Witness",
 }
 
 func (st State) String() string {
@@ -110,6 +116,12 @@ func (s *Status) IsFollower() bool {
 	return s.NodeState == follower
 }
 
+// IsCandidate returns a boolean value indicating whether the node is a
+// candidate.
+func (s *Status) IsCandidate() bool {
+	return s.NodeState == candidate
+}
+
 // getLocalStatus gets a copy of the current raft status.
 func getLocalStatus(r *raft) Status {
 	return Status{
@@ -135,13 +147,13 @@ func getLocalStatus(r *raft) Status {
 //  * ReadIndex protocol for read-only queries
 //  * leadership transfer
 //  * non-voting members
+//  * witness members that vote without storing payloads
 //  * idempotent updates
 //  * quorum check
 //  * batching
 //  * pipelining
-//
-// Features currently being worked on -
 //  * pre-vote
+//  * lease based read-only queries
 //
 
 //
@@ -180,7 +192,8 @@ func getLocalStatus(r *raft) Status {
 // * pagination support when applying committed entries
 // * making proposals are fully batched
 // * ReadIndex protocol implementation are fully batched
-// * unsafe read-only queries that rely on local clock is not supported
+// * read-only queries that rely on local clock are optional and they require
+//   check quorum to be enabled
 // * non-voting members are implemented as a special raft state
 // * non-voting members can initiate both new proposal and ReadIndex requests
 // * simplified flow control
@@ -196,6 +209,9 @@ type raft struct {
 	rl                        *server.RateLimiter
 	remotes                   map[uint64]*remote
 	observers                 map[uint64]*remote
+	witnesses                 map[uint64]*remote
+	incoming                  map[uint64]struct{}
+	outgoing                  map[uint64]struct{}
 	state                     State
 	votes                     map[uint64]bool
 	msgs                      []pb.Message
@@ -205,7 +221,14 @@ type raft struct {
 	pendingConfigChange       bool
 	readIndex                 *readIndex
 	readyToRead               []pb.ReadyToRead
+	droppedEntries            []pb.Entry
+	droppedReadIndexes        []pb.SystemCtx
+	droppedLeaderHint         uint64
 	checkQuorum               bool
+	preVote                   bool
+	leaseRead                 bool
+	leaseRevoked              bool
+	leaseTimeout              uint64
 	tickCount                 uint64
 	electionTick              uint64
 	heartbeatTick             uint64
@@ -217,6 +240,14 @@ type raft struct {
 	matched                   []uint64
 	hasNotAppliedConfigChange func() bool
 	recordLeader              func(uint64)
+	recordLeaderCommit        func(uint64)
+	recordPreference          func(uint64, []uint64)
+	preferredLeader           uint64
+	preferredRegion           []uint64
+	preferredLeaderTick       uint64
+	preferenceBroadcastTick   uint64
+	preferenceBroadcasted     bool
+	draining                  bool
 }
 
 func newRaft(c *config.Config, logdb ILogDB) *raft {
@@ -235,9 +266,14 @@ func newRaft(c *config.Config, logdb ILogDB) *raft {
 		log:              newEntryLog(logdb, rl),
 		remotes:          make(map[uint64]*remote),
 		observers:        make(map[uint64]*remote),
+		witnesses:        make(map[uint64]*remote),
 		electionTimeout:  c.ElectionRTT,
 		heartbeatTimeout: c.HeartbeatRTT,
 		checkQuorum:      c.CheckQuorum,
+		preVote:          c.PreVote,
+		leaseRead:        c.LeaseRead,
+		preferredLeader:  c.PreferredLeader,
+		preferredRegion:  normalizePreferredRegion(c.PreferredRegion),
 		readIndex:        newReadIndex(),
 		rl:               rl,
 	}
@@ -254,13 +290,25 @@ func newRaft(c *config.Config, logdb ILogDB) *raft {
 			next: 1,
 		}
 	}
+	for p := range members.Witnesses {
+		r.witnesses[p] = &remote{
+			next: 1,
+		}
+	}
+	r.setJointConfig(members.Incoming, members.Outgoing)
 	r.resetMatchValueArray()
+	if r.leaseRead {
+		r.leaseTimeout = c.ElectionRTT - c.ClockDriftRTT - 2
+	}
 	if !pb.IsEmptyState(st) {
 		r.loadState(st)
 	}
 	if c.IsObserver {
 		r.state = observer
 		r.becomeObserver(r.term, NoLeader)
+	} else if c.IsWitness {
+		r.state = witness
+		r.becomeWitness(r.term, NoLeader)
 	} else {
 		// see first paragraph section 5.2 of the raft paper
 		r.becomeFollower(r.term, NoLeader)
@@ -288,7 +336,7 @@ func (r *raft) getApplied() uint64 {
 }
 
 func (r *raft) resetMatchValueArray() {
-	r.matched = make([]uint64, len(r.remotes))
+	r.matched = make([]uint64, r.numVotingMembers())
 }
 
 func (r *raft) describe() string {
@@ -307,6 +355,10 @@ func (r *raft) isObserver() bool {
 	return r.state == observer
 }
 
+func (r *raft) isWitness() bool {
+	return r.state == witness
+}
+
 func (r *raft) setLeaderID(leaderID uint64) {
 	r.leaderID = leaderID
 	if r.recordLeader != nil {
@@ -314,6 +366,245 @@ func (r *raft) setLeaderID(leaderID uint64) {
 	}
 }
 
+// setLeaderCommit records the commit index announced by the leader. It is used
+// to tell how far behind the leader the local node is.
+func (r *raft) setLeaderCommit(index uint64) {
+	if r.recordLeaderCommit != nil {
+		r.recordLeaderCommit(index)
+	}
+}
+
+// setPreference sets the preferred leader and the preferred region. The
+// preferred region is a set of voting members, the leader transfers the
+// leadership to one of them when it is not in the region itself and the
+// preferred leader is not available.
+func (r *raft) setPreference(nodeID uint64, region []uint64) {
+	region = normalizePreferredRegion(region)
+	if nodeID != r.preferredLeader ||
+		!sameNodeIDs(region, r.preferredRegion) {
+		r.preferredLeaderTick = 0
+	}
+	r.preferredLeader = nodeID
+	r.preferredRegion = region
+	if r.recordPreference != nil {
+		r.recordPreference(nodeID, region)
+	}
+}
+
+func (r *raft) setPreferredLeader(nodeID uint64) {
+	r.setPreference(nodeID, r.preferredRegion)
+}
+
+func (r *raft) setPreferredRegion(region []uint64) {
+	r.setPreference(r.preferredLeader, region)
+}
+
+func (r *raft) hasPreference() bool {
+	return r.preferredLeader != NoNode || len(r.preferredRegion) > 0
+}
+
+func (r *raft) inPreferredRegion(nodeID uint64) bool {
+	for _, nid := range r.preferredRegion {
+		if nid == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// setDraining sets whether the node is being drained. A draining node refuses
+// to become the leader through leadership transfers, including those
+// requested by leaders that prefer this node to be the leader, and it doesn't
+// transfer the leadership to its own preferred leader.
+func (r *raft) setDraining(draining bool) {
+	r.draining = draining
+	r.preferredLeaderTick = 0
+}
+
+// preferredTargetReady returns a boolean value indicating whether the
+// specified node is a voting member that is active, not being drained and has
+// all committed entries.
+func (r *raft) preferredTargetReady(nodeID uint64) bool {
+	rp, ok := r.remotes[nodeID]
+	if !ok || rp.draining {
+		return false
+	}
+	return r.recentlyActive(rp) && rp.match >= r.log.committed
+}
+
+// preferredTarget returns the node the leadership should be transferred to
+// according to the preferred leader and the preferred region. NoNode is
+// returned when the leadership should stay on the local node.
+func (r *raft) preferredTarget() uint64 {
+	if r.preferredLeader != NoNode {
+		if r.preferredLeader == r.nodeID {
+			return NoNode
+		}
+		if r.preferredTargetReady(r.preferredLeader) {
+			return r.preferredLeader
+		}
+	}
+	if len(r.preferredRegion) == 0 || r.inPreferredRegion(r.nodeID) {
+		return NoNode
+	}
+	candidate := NoNode
+	match := uint64(0)
+	for _, nid := range r.preferredRegion {
+		if nid == r.nodeID || !r.preferredTargetReady(nid) {
+			continue
+		}
+		if rp := r.remotes[nid]; candidate == NoNode || rp.match > match {
+			candidate = nid
+			match = rp.match
+		}
+	}
+	return candidate
+}
+
+// recentlyActive returns a boolean value indicating whether the remote has
+// been heard from within the last election timeout.
+func (r *raft) recentlyActive(rp *remote) bool {
+	return r.tickCount-rp.getLastActiveTick() < r.electionTimeout
+}
+
+// leaderTransferCandidate returns the recently active voting member with the
+// most replicated log entries, members being drained are skipped. NoNode is
+// returned when there is no such member.
+func (r *raft) leaderTransferCandidate() uint64 {
+	candidate := NoNode
+	match := uint64(0)
+	for _, nid := range r.nodes() {
+		rp, ok := r.remotes[nid]
+		if !ok || nid == r.nodeID || rp.draining || !r.recentlyActive(rp) {
+			continue
+		}
+		if candidate == NoNode || rp.match > match {
+			candidate = nid
+			match = rp.match
+		}
+	}
+	return candidate
+}
+
+// tryTransferToPreferredLeader transfers the leadership to the preferred
+// leader or a node in the preferred region once such a node has been ready
+// for two election timeouts. the wait prevents leadership from flapping when
+// the preferred node is unstable, it also applies again after each failed
+// leadership transfer.
+func (r *raft) tryTransferToPreferredLeader() {
+	if !r.hasPreference() || r.draining || r.leaderTransfering() {
+		r.preferredLeaderTick = 0
+		return
+	}
+	target := r.preferredTarget()
+	if target == NoNode {
+		r.preferredLeaderTick = 0
+		return
+	}
+	r.preferredLeaderTick++
+	if r.preferredLeaderTick < r.electionTimeout*2 {
+		return
+	}
+	r.preferredLeaderTick = 0
+	plog.Infof("%s transferring leadership to preferred node %s",
+		r.describe(), NodeID(target))
+	r.Handle(pb.Message{
+		Type: pb.LeaderTransfer,
+		To:   r.nodeID,
+		From: target,
+		Hint: target,
+	})
+}
+
+// the preference used by the cluster is the one on the leader. it is
+// broadcast by the leader when it becomes the leader, when it is changed and
+// once every election timeout afterwards so nodes that missed an update, e.g.
+// restarted or newly added nodes, converge on the leader's value. nodes that
+// become the leader later keep using the same preference.
+func (r *raft) broadcastPreference() {
+	r.preferenceBroadcasted = true
+	r.preferenceBroadcastTick = 0
+	for id := range r.remotes {
+		if id != r.nodeID {
+			r.sendPreference(id)
+		}
+	}
+	// witnesses can never be the leader, they don't need the preference
+	for id := range r.observers {
+		r.sendPreference(id)
+	}
+}
+
+func (r *raft) sendPreference(nodeID uint64) {
+	m := newPreferenceMessage(r.preferredLeader, r.preferredRegion)
+	m.To = nodeID
+	r.send(m)
+}
+
+func (r *raft) tickPreferenceBroadcast() {
+	if !r.preferenceBroadcasted {
+		return
+	}
+	r.preferenceBroadcastTick++
+	if r.preferenceBroadcastTick >= r.electionTimeout {
+		r.broadcastPreference()
+	}
+}
+
+// newPreferenceMessage returns a PreferredLeader message. the preferred leader
+// is carried in the Hint field, node IDs of the preferred region are carried
+// in the Key fields of its entries.
+func newPreferenceMessage(nodeID uint64, region []uint64) pb.Message {
+	m := pb.Message{
+		Type: pb.PreferredLeader,
+		Hint: nodeID,
+	}
+	for _, nid := range region {
+		m.Entries = append(m.Entries, pb.Entry{Key: nid})
+	}
+	return m
+}
+
+func getPreferredRegion(m pb.Message) []uint64 {
+	var region []uint64
+	for _, e := range m.Entries {
+		region = append(region, e.Key)
+	}
+	return region
+}
+
+// normalizePreferredRegion returns the sorted node IDs of the preferred region
+// with NoNode and duplicated IDs removed.
+func normalizePreferredRegion(region []uint64) []uint64 {
+	var result []uint64
+	for _, nid := range region {
+		if nid != NoNode {
+			result = append(result, nid)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	n := 0
+	for i, nid := range result {
+		if i == 0 || nid != result[n-1] {
+			result[n] = nid
+			n++
+		}
+	}
+	return result[:n]
+}
+
+func sameNodeIDs(a []uint64, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *raft) leaderTransfering() bool {
 	return r.leaderTransferTarget != NoNode && r.state == leader
 }
@@ -322,33 +613,90 @@ func (r *raft) abortLeaderTransfer() {
 	r.leaderTransferTarget = NoNode
 }
 
+func (r *raft) numVotingMembers() int {
+	return len(r.remotes) + len(r.witnesses)
+}
+
 func (r *raft) quorum() int {
-	return len(r.remotes)/2 + 1
+	return r.numVotingMembers()/2 + 1
 }
 
 func (r *raft) isSingleNodeQuorum() bool {
+	if r.isJointConfig() {
+		return r.hasQuorum(func(nid uint64, rp *remote) bool {
+			return nid == r.nodeID
+		})
+	}
 	return r.quorum() == 1
 }
 
 func (r *raft) leaderHasQuorum() bool {
-	c := 0
-	for nid := range r.remotes {
-		if nid == r.nodeID || r.remotes[nid].isActive() {
-			c++
-			r.remotes[nid].setNotActive()
-		}
+	result := r.hasQuorum(func(nid uint64, rp *remote) bool {
+		return nid == r.nodeID || rp.isActive()
+	})
+	for _, rp := range r.remotes {
+		rp.setNotActive()
+	}
+	for _, rp := range r.witnesses {
+		rp.setNotActive()
+	}
+	return result
+}
+
+// hasValidLease returns a boolean value indicating whether the leader holds a
+// valid lease. with check quorum enabled, voting members ignore RequestVote
+// messages for an election timeout after hearing from the leader. each
+// Replicate and Heartbeat message carries the tick count at which it was sent
+// by the leader and the tick count is echoed back in the response, the leader
+// is thus guaranteed to be the only leader for the lease period starting from
+// the moment it sent messages that were later acknowledged by a quorum of
+// voting members.
+func (r *raft) hasValidLease() bool {
+	if !r.leaseRead || r.leaseRevoked ||
+		r.state != leader || r.leaderTransfering() {
+		return false
+	}
+	return r.hasQuorum(func(nid uint64, rp *remote) bool {
+		return nid == r.nodeID || r.withinLease(rp)
+	})
+}
+
+func (r *raft) withinLease(rp *remote) bool {
+	tick := rp.getLeaseTick()
+	return tick > 0 && r.tickCount < tick+r.leaseTimeout
+}
+
+// getLeaseSendTick returns the tick count to be included in Replicate and
+// Heartbeat messages, 0 is returned when lease based read is disabled.
+func (r *raft) getLeaseSendTick() uint64 {
+	if !r.leaseRead {
+		return 0
+	}
+	return r.tickCount
+}
+
+// renewLease records the tick count at which the leader sent the message that
+// has just been acknowledged by the remote. the lease starts at that tick
+// count rather than the one at which the response is received, as the remote
+// can receive the message any time in between.
+func (r *raft) renewLease(rp *remote, sentTick uint64) {
+	if sentTick <= r.tickCount {
+		rp.setLeaseTick(sentTick)
 	}
-	return c >= r.quorum()
 }
 
 func (r *raft) nodes() []uint64 {
-	nodes := make([]uint64, 0, len(r.remotes)+len(r.observers))
+	nodes := make([]uint64, 0,
+		len(r.remotes)+len(r.observers)+len(r.witnesses))
 	for id := range r.remotes {
 		nodes = append(nodes, id)
 	}
 	for id := range r.observers {
 		nodes = append(nodes, id)
 	}
+	for id := range r.witnesses {
+		nodes = append(nodes, id)
+	}
 	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
 	return nodes
 }
@@ -384,6 +732,21 @@ func (r *raft) restore(ss pb.Snapshot) bool {
 			}
 		}
 	}
+	if !r.isWitness() {
+		for nid := range ss.Membership.Witnesses {
+			if nid == r.nodeID {
+				plog.Panicf("%s converting to witness, index %d, committed %d, %+v",
+					r.describe(), ss.Index, r.log.committed, ss)
+			}
+		}
+	} else {
+		_, rok := ss.Membership.Addresses[r.nodeID]
+		_, ook := ss.Membership.Observers[r.nodeID]
+		if rok || ook {
+			plog.Panicf("%s converting from witness, index %d, committed %d, %+v",
+				r.describe(), ss.Index, r.log.committed, ss)
+		}
+	}
 	// p52 of the raft thesis
 	if r.log.matchTerm(ss.Index, ss.Term) {
 		// a snapshot at index X implies that X has been committed
@@ -423,6 +786,18 @@ func (r *raft) restoreRemotes(ss pb.Snapshot) {
 		plog.Infof("%s restored observer progress of %s [%s]",
 			r.describe(), NodeID(id), r.observers[id])
 	}
+	r.witnesses = make(map[uint64]*remote)
+	for id := range ss.Membership.Witnesses {
+		match := uint64(0)
+		next := r.log.lastIndex() + 1
+		if id == r.nodeID {
+			match = next - 1
+		}
+		r.setWitness(id, match, next)
+		plog.Infof("%s restored witness progress of %s [%s]",
+			r.describe(), NodeID(id), r.witnesses[id])
+	}
+	r.setJointConfig(ss.Membership.Incoming, ss.Membership.Outgoing)
 	r.resetMatchValueArray()
 }
 
@@ -479,6 +854,11 @@ func (r *raft) nonLeaderTick() {
 	if r.isObserver() {
 		return
 	}
+	// witness votes but it never starts an election as it doesn't have the
+	// payload of log entries
+	if r.isWitness() {
+		return
+	}
 	// 6th paragraph section 5.2 of the raft paper
 	if !r.selfRemoved() && r.timeForElection() {
 		r.electionTick = 0
@@ -494,6 +874,7 @@ func (r *raft) leaderTick() {
 		panic("leaderTick called on a non-leader node")
 	}
 	r.electionTick++
+	r.setLeaderCommit(r.log.committed)
 	if r.timeForRateLimitCheck() {
 		if r.rl.Enabled() {
 			r.rl.HeartbeatTick()
@@ -512,6 +893,8 @@ func (r *raft) leaderTick() {
 	if timeToAbortLeaderTransfer {
 		r.abortLeaderTransfer()
 	}
+	r.tryTransferToPreferredLeader()
+	r.tickPreferenceBroadcast()
 	r.heartbeatTick++
 	if r.timeForHearbeat() {
 		r.heartbeatTick = 0
@@ -523,6 +906,7 @@ func (r *raft) leaderTick() {
 }
 
 func (r *raft) quiescedTick() {
+	r.tickCount++
 	r.electionTick++
 }
 
@@ -536,13 +920,16 @@ func (r *raft) setRandomizedElectionTimeout() {
 //
 
 func (r *raft) finalizeMessageTerm(m pb.Message) pb.Message {
-	if m.Term == 0 && m.Type == pb.RequestVote {
-		plog.Panicf("sending RequestVote with 0 term")
+	if m.Term == 0 && (m.Type == pb.RequestVote || m.Type == pb.RequestPreVote) {
+		plog.Panicf("sending %s with 0 term", m.Type)
 	}
-	if m.Term > 0 && m.Type != pb.RequestVote {
+	if m.Term > 0 && m.Type != pb.RequestVote && !isPreVoteMessage(m.Type) {
 		plog.Panicf("term unexpectedly set for message type %d", m.Type)
 	}
-	if !isRequestMessage(m.Type) {
+	// term values of PreVote messages are always explicitly set as they are not
+	// necessarily the same as the local term
+	if !isRequestMessage(m.Type) &&
+		!isDroppedRequestMessage(m.Type) && !isPreVoteMessage(m.Type) {
 		m.Term = r.term
 	}
 	return m
@@ -585,7 +972,11 @@ func (r *raft) makeInstallSnapshotMessage(to uint64, m *pb.Message) uint64 {
 	if pb.IsEmptySnapshot(snapshot) {
 		panic("got an empty snapshot")
 	}
-	m.Snapshot = snapshot
+	if _, ok := r.witnesses[to]; ok {
+		m.Snapshot = makeWitnessSnapshot(snapshot)
+	} else {
+		m.Snapshot = snapshot
+	}
 	return snapshot.Index
 }
 
@@ -605,6 +996,8 @@ func (r *raft) makeReplicateMessage(to uint64,
 				next-1+uint64(len(entries)), entries[len(entries)-1].Index)
 		}
 	}
+	// HintHigh is the tick count at which the message is sent, it is echoed
+	// back in the ReplicateResp message for renewing the lease
 	return pb.Message{
 		To:       to,
 		Type:     pb.Replicate,
@@ -612,18 +1005,23 @@ func (r *raft) makeReplicateMessage(to uint64,
 		LogTerm:  term,
 		Entries:  entries,
 		Commit:   r.log.committed,
+		HintHigh: r.getLeaseSendTick(),
 	}, nil
 }
 
 func (r *raft) sendReplicateMessage(to uint64) {
 	var rp *remote
+	toWitness := false
 	if v, ok := r.remotes[to]; ok {
 		rp = v
+	} else if v, ok := r.observers[to]; ok {
+		rp = v
 	} else {
-		rp, ok = r.observers[to]
+		rp, ok = r.witnesses[to]
 		if !ok {
 			panic("failed to get the remote instance")
 		}
+		toWitness = true
 	}
 	if rp.isPaused() {
 		return
@@ -645,6 +1043,9 @@ func (r *raft) sendReplicateMessage(to uint64) {
 			lastIndex := m.Entries[len(m.Entries)-1].Index
 			rp.progress(lastIndex)
 		}
+		if toWitness {
+			m.Entries = makeMetadataEntries(m.Entries)
+		}
 	}
 	r.send(m)
 }
@@ -661,23 +1062,28 @@ func (r *raft) broadcastReplicateMessage() {
 		}
 		r.sendReplicateMessage(nid)
 	}
+	for nid := range r.witnesses {
+		if nid == r.nodeID {
+			panic("witness is trying to broadcast Replicate msg")
+		}
+		r.sendReplicateMessage(nid)
+	}
 }
 
 func (r *raft) sendHeartbeatMessage(to uint64,
-	hint pb.SystemCtx, toObserver bool) {
-	var match uint64
-	if toObserver {
-		match = r.observers[to].match
-	} else {
-		match = r.remotes[to].match
-	}
+	hint pb.SystemCtx, match uint64) {
 	commit := min(match, r.log.committed)
+	// LogIndex is the tick count at which the message is sent, it is echoed
+	// back in the HeartbeatResp message for renewing the lease. LogTerm is the
+	// uncapped commit index of the leader used for tracking staleness.
 	r.send(pb.Message{
 		To:       to,
 		Type:     pb.Heartbeat,
 		Commit:   commit,
 		Hint:     hint.Low,
 		HintHigh: hint.High,
+		LogIndex: r.getLeaseSendTick(),
+		LogTerm:  r.log.committed,
 	})
 }
 
@@ -694,23 +1100,38 @@ func (r *raft) broadcastHeartbeatMessage() {
 
 func (r *raft) broadcastHeartbeatMessageWithHint(ctx pb.SystemCtx) {
 	zeroCtx := pb.SystemCtx{}
-	for id := range r.remotes {
+	for id, rp := range r.remotes {
 		if id != r.nodeID {
-			r.sendHeartbeatMessage(id, ctx, false)
+			r.sendHeartbeatMessage(id, ctx, rp.match)
 		}
 	}
+	// witnesses are voting members, they are required to confirm the
+	// leadership as a part of the ReadIndex protocol
+	for id, rp := range r.witnesses {
+		r.sendHeartbeatMessage(id, ctx, rp.match)
+	}
 	if ctx == zeroCtx {
-		for id := range r.observers {
-			r.sendHeartbeatMessage(id, zeroCtx, true)
+		for id, rp := range r.observers {
+			r.sendHeartbeatMessage(id, zeroCtx, rp.match)
 		}
 	}
 }
 
 func (r *raft) sendTimeoutNowMessage(nodeID uint64) {
-	r.send(pb.Message{
+	// the target is allowed to be elected without waiting for the election
+	// timeout, the lease can no longer be trusted in the current term
+	r.leaseRevoked = true
+	m := pb.Message{
 		Type: pb.TimeoutNow,
 		To:   nodeID,
-	})
+	}
+	if r.hasPreference() {
+		// let the target take over the preference before it becomes the leader
+		pm := newPreferenceMessage(r.preferredLeader, r.preferredRegion)
+		m.Hint = pm.Hint
+		m.Entries = pm.Entries
+	}
+	r.send(m)
 }
 
 //
@@ -743,7 +1164,14 @@ func (r *raft) sortMatchValues() {
 }
 
 func (r *raft) tryCommit() bool {
-	if len(r.remotes) != len(r.matched) {
+	if r.isJointConfig() {
+		q := r.configCommittedIndex(r.incoming)
+		if v := r.configCommittedIndex(r.outgoing); v < q {
+			q = v
+		}
+		return r.log.tryCommit(q, r.term)
+	}
+	if r.numVotingMembers() != len(r.matched) {
 		r.resetMatchValueArray()
 	}
 	idx := 0
@@ -751,8 +1179,12 @@ func (r *raft) tryCommit() bool {
 		r.matched[idx] = v.match
 		idx++
 	}
+	for _, v := range r.witnesses {
+		r.matched[idx] = v.match
+		idx++
+	}
 	r.sortMatchValues()
-	q := r.matched[len(r.remotes)-r.quorum()]
+	q := r.matched[r.numVotingMembers()-r.quorum()]
 	// see p8 raft paper
 	// "Raft never commits log entries from previous terms by counting replicas.
 	// Only log entries from the leader’s current term are committed by counting
@@ -786,6 +1218,15 @@ func (r *raft) becomeObserver(term uint64, leaderID uint64) {
 	plog.Infof("%s became an observer", r.describe())
 }
 
+func (r *raft) becomeWitness(term uint64, leaderID uint64) {
+	if r.state != witness {
+		panic("transitioning to witness state from non-witness")
+	}
+	r.reset(term)
+	r.setLeaderID(leaderID)
+	plog.Infof("%s became a witness", r.describe())
+}
+
 func (r *raft) becomeFollower(term uint64, leaderID uint64) {
 	r.state = follower
 	r.reset(term)
@@ -793,6 +1234,24 @@ func (r *raft) becomeFollower(term uint64, leaderID uint64) {
 	plog.Infof("%s became a follower", r.describe())
 }
 
+// section 9.6 of the raft thesis
+func (r *raft) becomePreCandidate() {
+	if r.state == leader {
+		panic("transitioning to pre-candidate state from leader")
+	}
+	if r.state == observer {
+		panic("observer is becoming pre-candidate")
+	}
+	if r.state == witness {
+		panic("witness is becoming pre-candidate")
+	}
+	// the term and vote are not changed, the node only checks whether it can
+	// win an election before the actual campaign starts
+	r.state = preCandidate
+	r.reset(r.term)
+	plog.Infof("%s became a pre-candidate", r.describe())
+}
+
 func (r *raft) becomeCandidate() {
 	if r.state == leader {
 		panic("transitioning to candidate state from leader")
@@ -800,6 +1259,9 @@ func (r *raft) becomeCandidate() {
 	if r.state == observer {
 		panic("observer is becoming candidate")
 	}
+	if r.state == witness {
+		panic("witness is becoming candidate")
+	}
 	r.state = candidate
 	// 2nd paragraph section 5.2 of the raft paper
 	r.reset(r.term + 1)
@@ -811,9 +1273,15 @@ func (r *raft) becomeLeader() {
 	if r.state == follower {
 		panic("transitioning to leader state from follower")
 	}
+	if r.state == preCandidate {
+		panic("transitioning to leader state from pre-candidate")
+	}
 	if r.state == observer {
 		panic("observer is become leader")
 	}
+	if r.state == witness {
+		panic("witness is becoming leader")
+	}
 	r.state = leader
 	r.reset(r.term)
 	r.setLeaderID(r.nodeID)
@@ -821,6 +1289,14 @@ func (r *raft) becomeLeader() {
 	// p72 of the raft thesis
 	r.appendEntries([]pb.Entry{{Type: pb.ApplicationEntry, Cmd: nil}})
 	plog.Infof("%s became the leader", r.describe())
+	if r.hasPreference() {
+		r.broadcastPreference()
+	}
+	if r.isJointConfig() && !r.hasPendingConfigChange() {
+		// the previous leader failed to have the cluster transitioned out of the
+		// joint config
+		r.proposeLeaveJoint()
+	}
 }
 
 func (r *raft) reset(term uint64) {
@@ -835,12 +1311,17 @@ func (r *raft) reset(term uint64) {
 	r.votes = make(map[uint64]bool)
 	r.electionTick = 0
 	r.heartbeatTick = 0
+	r.preferredLeaderTick = 0
+	r.preferenceBroadcastTick = 0
+	r.preferenceBroadcasted = false
 	r.setRandomizedElectionTimeout()
 	r.readIndex = newReadIndex()
+	r.leaseRevoked = false
 	r.clearPendingConfigChange()
 	r.abortLeaderTransfer()
 	r.resetRemotes()
 	r.resetObservers()
+	r.resetWitnesses()
 	r.resetMatchValueArray()
 }
 
@@ -880,17 +1361,32 @@ func (r *raft) resetObservers() {
 	}
 }
 
+func (r *raft) resetWitnesses() {
+	for id := range r.witnesses {
+		r.witnesses[id] = &remote{
+			next: r.log.lastIndex() + 1,
+		}
+		if id == r.nodeID {
+			r.witnesses[id].match = r.log.lastIndex()
+		}
+	}
+}
+
 //
 // election related functions
 //
 
 func (r *raft) handleVoteResp(from uint64, rejected bool) int {
+	mt := pb.RequestVoteResp
+	if r.state == preCandidate {
+		mt = pb.RequestPreVoteResp
+	}
 	if rejected {
-		plog.Infof("%s received RequestVoteResp rejection from %s at term %d",
-			r.describe(), NodeID(from), r.term)
+		plog.Infof("%s received %s rejection from %s at term %d",
+			r.describe(), mt, NodeID(from), r.term)
 	} else {
-		plog.Infof("%s received RequestVoteResp from %s at term %d",
-			r.describe(), NodeID(from), r.term)
+		plog.Infof("%s received %s from %s at term %d",
+			r.describe(), mt, NodeID(from), r.term)
 	}
 	votedFor := 0
 	if _, ok := r.votes[from]; !ok {
@@ -918,7 +1414,7 @@ func (r *raft) campaign() {
 		hint = r.nodeID
 		r.isLeaderTransferTarget = false
 	}
-	for k := range r.remotes {
+	for _, k := range r.voters() {
 		if k == r.nodeID {
 			continue
 		}
@@ -934,6 +1430,33 @@ func (r *raft) campaign() {
 	}
 }
 
+// preVoteCampaign starts the PreVote phase described in section 9.6 of the
+// raft thesis. RequestPreVote messages are sent with the term the node would
+// use in the actual election, the local term is not incremented.
+func (r *raft) preVoteCampaign() {
+	plog.Infof("%s pre-vote campaign called, remotes len: %d",
+		r.describe(), len(r.remotes))
+	r.becomePreCandidate()
+	r.handleVoteResp(r.nodeID, false)
+	if r.isSingleNodeQuorum() {
+		r.campaign()
+		return
+	}
+	for _, k := range r.voters() {
+		if k == r.nodeID {
+			continue
+		}
+		r.send(pb.Message{
+			Term:     r.term + 1,
+			To:       k,
+			Type:     pb.RequestPreVote,
+			LogIndex: r.log.lastIndex(),
+			LogTerm:  r.log.lastTerm(),
+		})
+		plog.Infof("%s sent RequestPreVote to node %s", r.describe(), NodeID(k))
+	}
+}
+
 //
 // membership management
 //
@@ -943,6 +1466,10 @@ func (r *raft) selfRemoved() bool {
 		_, ok := r.observers[r.nodeID]
 		return !ok
 	}
+	if r.state == witness {
+		_, ok := r.witnesses[r.nodeID]
+		return !ok
+	}
 	_, ok := r.remotes[r.nodeID]
 	return !ok
 }
@@ -953,30 +1480,65 @@ func (r *raft) addNode(nodeID uint64) {
 		// already a voting member
 		return
 	}
-	if rp, ok := r.observers[nodeID]; ok {
-		// promoting to full member with inheriated progress info
-		r.deleteObserver(nodeID)
-		r.remotes[nodeID] = rp
-		// local peer promoted, become follower
-		if nodeID == r.nodeID {
-			r.becomeFollower(r.term, r.leaderID)
-		}
+	if _, ok := r.witnesses[nodeID]; ok {
+		panic("could not promote witness to a full member")
+	}
+	if _, ok := r.observers[nodeID]; ok {
+		r.promoteObserver(nodeID)
 	} else {
 		r.setRemote(nodeID, 0, r.log.lastIndex()+1)
 	}
 }
 
+func (r *raft) promoteObserver(nodeID uint64) {
+	r.clearPendingConfigChange()
+	if _, ok := r.remotes[nodeID]; ok {
+		// already promoted
+		return
+	}
+	rp, ok := r.observers[nodeID]
+	if !ok {
+		plog.Panicf("%s promoting %s, not an observer",
+			r.describe(), NodeID(nodeID))
+	}
+	// promoting to full member with inheriated progress info
+	r.deleteObserver(nodeID)
+	r.remotes[nodeID] = rp
+	r.resetMatchValueArray()
+	// local peer promoted, become follower
+	if nodeID == r.nodeID {
+		r.becomeFollower(r.term, r.leaderID)
+	}
+}
+
 func (r *raft) addObserver(nodeID uint64) {
 	r.clearPendingConfigChange()
 	if _, ok := r.observers[nodeID]; ok {
 		return
 	}
+	if _, ok := r.witnesses[nodeID]; ok {
+		panic("could not convert witness to an observer")
+	}
 	r.setObserver(nodeID, 0, r.log.lastIndex()+1)
 }
 
+func (r *raft) addWitness(nodeID uint64) {
+	r.clearPendingConfigChange()
+	if _, ok := r.witnesses[nodeID]; ok {
+		return
+	}
+	_, rok := r.remotes[nodeID]
+	_, ook := r.observers[nodeID]
+	if rok || ook {
+		panic("could not convert a member to witness")
+	}
+	r.setWitness(nodeID, 0, r.log.lastIndex()+1)
+}
+
 func (r *raft) removeNode(nodeID uint64) {
 	r.deleteRemote(nodeID)
 	r.deleteObserver(nodeID)
+	r.deleteWitness(nodeID)
 	r.clearPendingConfigChange()
 	if r.leaderTransfering() && r.leaderTransferTarget == nodeID {
 		r.abortLeaderTransfer()
@@ -997,6 +1559,11 @@ func (r *raft) deleteObserver(nodeID uint64) {
 	delete(r.observers, nodeID)
 }
 
+func (r *raft) deleteWitness(nodeID uint64) {
+	delete(r.witnesses, nodeID)
+	r.resetMatchValueArray()
+}
+
 func (r *raft) setRemote(nodeID uint64, match uint64, next uint64) {
 	plog.Infof("%s set remote, id %s, match %d, next %d",
 		r.describe(), NodeID(nodeID), match, next)
@@ -1016,6 +1583,211 @@ func (r *raft) setObserver(nodeID uint64, match uint64, next uint64) {
 	}
 }
 
+func (r *raft) setWitness(nodeID uint64, match uint64, next uint64) {
+	plog.Infof("%s set witness, id %s, match %d, next %d",
+		r.describe(), NodeID(nodeID), match, next)
+	r.witnesses[nodeID] = &remote{
+		next:  next,
+		match: match,
+	}
+	r.resetMatchValueArray()
+}
+
+//
+// joint consensus
+//
+// section 4.3 of the raft thesis describes the joint consensus approach for
+// changing multiple members at once. the cluster first transitions into the
+// joint configuration C_old,new, in which both log entry commitment and
+// elections require separate majorities from C_old and C_new. once the entry
+// for entering C_old,new is applied, the leader proposes another entry to have
+// the cluster transitioned into C_new.
+// similar to other membership changes, entries for entering and leaving the
+// joint configuration are executed after being applied. while in the joint
+// configuration, all incoming and outgoing members are kept in remotes,
+// observers and witnesses. C_old consists of all voting members not in the
+// incoming set, C_new consists of all voting members not in the outgoing set.
+//
+
+func (r *raft) setJointConfig(incoming map[uint64]bool,
+	outgoing map[uint64]bool) {
+	r.incoming = make(map[uint64]struct{})
+	r.outgoing = make(map[uint64]struct{})
+	for nid := range incoming {
+		r.incoming[nid] = struct{}{}
+	}
+	for nid := range outgoing {
+		r.outgoing[nid] = struct{}{}
+	}
+}
+
+func (r *raft) isJointConfig() bool {
+	return len(r.incoming) > 0 || len(r.outgoing) > 0
+}
+
+// hasQuorum returns a boolean value indicating whether the voting members
+// selected by f constitute a quorum. both C_old and C_new are checked when in
+// the joint configuration.
+func (r *raft) hasQuorum(f func(uint64, *remote) bool) bool {
+	if !r.isJointConfig() {
+		return r.hasConfigQuorum(nil, f)
+	}
+	return r.hasConfigQuorum(r.incoming, f) && r.hasConfigQuorum(r.outgoing, f)
+}
+
+func (r *raft) hasConfigQuorum(excluded map[uint64]struct{},
+	f func(uint64, *remote) bool) bool {
+	total := 0
+	c := 0
+	check := func(nid uint64, rp *remote) {
+		if _, ok := excluded[nid]; ok {
+			return
+		}
+		total++
+		if f(nid, rp) {
+			c++
+		}
+	}
+	for nid, rp := range r.remotes {
+		check(nid, rp)
+	}
+	for nid, rp := range r.witnesses {
+		check(nid, rp)
+	}
+	return c >= total/2+1
+}
+
+// configCommittedIndex returns the largest index that has been replicated to
+// a majority of the voting members that are not in the excluded set.
+func (r *raft) configCommittedIndex(excluded map[uint64]struct{}) uint64 {
+	matched := make([]uint64, 0, r.numVotingMembers())
+	for nid, rp := range r.remotes {
+		if _, ok := excluded[nid]; !ok {
+			matched = append(matched, rp.match)
+		}
+	}
+	for nid, rp := range r.witnesses {
+		if _, ok := excluded[nid]; !ok {
+			matched = append(matched, rp.match)
+		}
+	}
+	if len(matched) == 0 {
+		plog.Panicf("%s no voting member in config", r.describe())
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i] < matched[j] })
+	return matched[len(matched)-(len(matched)/2+1)]
+}
+
+func (r *raft) electionWon() bool {
+	return r.hasQuorum(func(nid uint64, rp *remote) bool {
+		v, ok := r.votes[nid]
+		return ok && v
+	})
+}
+
+func (r *raft) electionLost() bool {
+	rejected := func(nid uint64, rp *remote) bool {
+		v, ok := r.votes[nid]
+		return ok && !v
+	}
+	if !r.isJointConfig() {
+		return r.hasConfigQuorum(nil, rejected)
+	}
+	return r.hasConfigQuorum(r.incoming, rejected) ||
+		r.hasConfigQuorum(r.outgoing, rejected)
+}
+
+func (r *raft) enterJoint(changes []pb.ConfigChange) {
+	r.clearPendingConfigChange()
+	if r.isJointConfig() {
+		plog.Panicf("%s is already in joint config", r.describe())
+	}
+	incoming := make(map[uint64]struct{})
+	outgoing := make(map[uint64]struct{})
+	for _, cc := range changes {
+		switch cc.Type {
+		case pb.AddNode:
+			if _, ok := r.remotes[cc.NodeID]; !ok {
+				r.addNode(cc.NodeID)
+				incoming[cc.NodeID] = struct{}{}
+			}
+		case pb.PromoteObserver:
+			if _, ok := r.remotes[cc.NodeID]; !ok {
+				r.promoteObserver(cc.NodeID)
+				incoming[cc.NodeID] = struct{}{}
+			}
+		case pb.AddWitness:
+			if _, ok := r.witnesses[cc.NodeID]; !ok {
+				r.addWitness(cc.NodeID)
+				incoming[cc.NodeID] = struct{}{}
+			}
+		case pb.AddObserver:
+			r.addObserver(cc.NodeID)
+		case pb.RemoveNode:
+			_, rok := r.remotes[cc.NodeID]
+			_, ook := r.observers[cc.NodeID]
+			_, wok := r.witnesses[cc.NodeID]
+			if rok || ook || wok {
+				// removed members are kept until leaving the joint config
+				outgoing[cc.NodeID] = struct{}{}
+			}
+		default:
+			plog.Panicf("unexpected config change type %s", cc.Type)
+		}
+	}
+	r.incoming = incoming
+	r.outgoing = outgoing
+	r.resetMatchValueArray()
+	plog.Infof("%s entered joint config, incoming %v, outgoing %v",
+		r.describe(), incoming, outgoing)
+	if r.isJointConfig() && r.state == leader {
+		r.proposeLeaveJoint()
+	}
+}
+
+func (r *raft) leaveJoint() {
+	r.clearPendingConfigChange()
+	if !r.isJointConfig() {
+		plog.Panicf("%s is not in joint config", r.describe())
+	}
+	outgoing := r.outgoing
+	r.incoming = make(map[uint64]struct{})
+	r.outgoing = make(map[uint64]struct{})
+	for nid := range outgoing {
+		r.removeNode(nid)
+	}
+	plog.Infof("%s left joint config", r.describe())
+	if r.state == leader && len(r.remotes) > 0 {
+		if r.tryCommit() {
+			r.broadcastReplicateMessage()
+		}
+	}
+}
+
+func (r *raft) proposeLeaveJoint() {
+	cc := pb.ConfigChange{Type: pb.LeaveJoint}
+	data, err := cc.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	plog.Infof("%s is proposing to leave the joint config", r.describe())
+	r.appendEntries([]pb.Entry{{Type: pb.ConfigChangeEntry, Cmd: data}})
+	r.setPendingConfigChange()
+	r.broadcastReplicateMessage()
+}
+
+// voters returns IDs of all voting members, including witnesses.
+func (r *raft) voters() []uint64 {
+	voters := make([]uint64, 0, r.numVotingMembers())
+	for id := range r.remotes {
+		voters = append(voters, id)
+	}
+	for id := range r.witnesses {
+		voters = append(voters, id)
+	}
+	return voters
+}
+
 //
 // helper methods required for the membership change implementation
 //
@@ -1080,11 +1852,15 @@ func (r *raft) getPendingConfigChangeCount() int {
 
 func (r *raft) handleHeartbeatMessage(m pb.Message) {
 	r.log.commitTo(m.Commit)
+	// Reject is set when this node is being drained, the leader is not going
+	// to transfer the leadership to it
 	r.send(pb.Message{
 		To:       m.From,
 		Type:     pb.HeartbeatResp,
 		Hint:     m.Hint,
 		HintHigh: m.HintHigh,
+		LogIndex: m.LogIndex,
+		Reject:   r.draining,
 	})
 }
 
@@ -1110,8 +1886,9 @@ func (r *raft) handleInstallSnapshotMessage(m pb.Message) {
 
 func (r *raft) handleReplicateMessage(m pb.Message) {
 	resp := pb.Message{
-		To:   m.From,
-		Type: pb.ReplicateResp,
+		To:       m.From,
+		Type:     pb.ReplicateResp,
+		HintHigh: m.HintHigh,
 	}
 	if m.LogIndex < r.log.committed {
 		resp.LogIndex = r.log.committed
@@ -1133,6 +1910,102 @@ func (r *raft) handleReplicateMessage(m pb.Message) {
 	r.send(resp)
 }
 
+//
+// dropped requests
+//
+
+// reportDroppedProposal reports proposals that can not be handled by the
+// local node, e.g. when there is no leader, so the clients don't need to wait
+// for the timeout. proposals forwarded by remote nodes are reported back to
+// the remote node along with the leader hint.
+func (r *raft) reportDroppedProposal(m pb.Message, leaderHint uint64) {
+	entries := makeDroppedEntries(m.Entries)
+	if m.From == NoNode || m.From == r.nodeID {
+		r.droppedEntries = append(r.droppedEntries, entries...)
+		r.droppedLeaderHint = leaderHint
+		return
+	}
+	r.send(pb.Message{
+		To:       m.From,
+		Type:     pb.ProposalDropped,
+		Entries:  entries,
+		LogIndex: leaderHint,
+	})
+}
+
+// reportDroppedReadIndex reports ReadIndex requests that can not be handled,
+// ReadIndex requests forwarded by remote nodes are reported back to the
+// remote node along with the leader hint.
+func (r *raft) reportDroppedReadIndex(m pb.Message, leaderHint uint64) {
+	if m.From == NoNode || m.From == r.nodeID {
+		ctx := pb.SystemCtx{
+			Low:  m.Hint,
+			High: m.HintHigh,
+		}
+		r.droppedReadIndexes = append(r.droppedReadIndexes, ctx)
+		r.droppedLeaderHint = leaderHint
+		return
+	}
+	r.send(pb.Message{
+		To:       m.From,
+		Type:     pb.ReadIndexDropped,
+		Hint:     m.Hint,
+		HintHigh: m.HintHigh,
+		LogIndex: leaderHint,
+	})
+}
+
+func (r *raft) clearDroppedRequests() {
+	r.droppedEntries = nil
+	r.droppedReadIndexes = nil
+	r.droppedLeaderHint = NoLeader
+}
+
+// the leader hint of the ProposalDropped and ReadIndexDropped messages is
+// carried in the LogIndex field.
+func (r *raft) handleNodeProposalDropped(m pb.Message) {
+	r.droppedEntries = append(r.droppedEntries, newEntrySlice(m.Entries)...)
+	r.droppedLeaderHint = m.LogIndex
+}
+
+func (r *raft) handleNodeReadIndexDropped(m pb.Message) {
+	ctx := pb.SystemCtx{
+		Low:  m.Hint,
+		High: m.HintHigh,
+	}
+	r.droppedReadIndexes = append(r.droppedReadIndexes, ctx)
+	r.droppedLeaderHint = m.LogIndex
+}
+
+// handleLeaderPreferredLeader handles preferences set locally and those
+// forwarded by other nodes, the leader's preference is the one used by the
+// cluster.
+func (r *raft) handleLeaderPreferredLeader(m pb.Message) {
+	r.setPreference(m.Hint, getPreferredRegion(m))
+	r.broadcastPreference()
+}
+
+// handleNodePreferredLeader handles the PreferredLeader message on non-leader
+// nodes. preferences broadcast by the leader are adopted, those set locally
+// or received from other nodes are forwarded to the leader.
+func (r *raft) handleNodePreferredLeader(m pb.Message) {
+	region := getPreferredRegion(m)
+	if m.From != NoNode && m.From == r.leaderID {
+		r.setPreference(m.Hint, region)
+		return
+	}
+	if m.From == NoNode {
+		r.setPreference(m.Hint, region)
+	}
+	if r.leaderID == NoLeader {
+		plog.Warningf("%s dropped PreferredLeader as no leader", r.describe())
+		return
+	}
+	fm := newPreferenceMessage(m.Hint, region)
+	fm.To = r.leaderID
+	r.send(fm)
+}
+
 //
 // Step related functions
 //
@@ -1141,13 +2014,22 @@ func isRequestMessage(t pb.MessageType) bool {
 	return t == pb.Propose || t == pb.ReadIndex
 }
 
+func isDroppedRequestMessage(t pb.MessageType) bool {
+	return t == pb.ProposalDropped || t == pb.ReadIndexDropped
+}
+
 func isLeaderMessage(t pb.MessageType) bool {
 	return t == pb.Replicate || t == pb.InstallSnapshot ||
 		t == pb.Heartbeat || t == pb.TimeoutNow || t == pb.ReadIndexResp
 }
 
+func isPreVoteMessage(t pb.MessageType) bool {
+	return t == pb.RequestPreVote || t == pb.RequestPreVoteResp
+}
+
 func (r *raft) dropRequestVoteFromHighTermNode(m pb.Message) bool {
-	if m.Type != pb.RequestVote || !r.checkQuorum || m.Term <= r.term {
+	if (m.Type != pb.RequestVote && m.Type != pb.RequestPreVote) ||
+		!r.checkQuorum || m.Term <= r.term {
 		return false
 	}
 	// see p42 of the raft thesis
@@ -1181,6 +2063,13 @@ func (r *raft) onMessageTermNotMatched(m pb.Message) bool {
 	if m.Term > r.term {
 		plog.Infof("%s received a %s with higher term (%d) from %s",
 			r.describe(), m.Type, m.Term, NodeID(m.From))
+		// see section 9.6 of the raft thesis, the term is not updated when
+		// receiving RequestPreVote or granted RequestPreVoteResp. a granted
+		// RequestPreVoteResp carries the term used in the RequestPreVote message.
+		if m.Type == pb.RequestPreVote ||
+			(m.Type == pb.RequestPreVoteResp && !m.Reject) {
+			return false
+		}
 		leaderID := NoLeader
 		if isLeaderMessage(m.Type) {
 			leaderID = m.From
@@ -1188,14 +2077,31 @@ func (r *raft) onMessageTermNotMatched(m pb.Message) bool {
 
 		if r.isObserver() {
 			r.becomeObserver(m.Term, leaderID)
+		} else if r.isWitness() {
+			r.becomeWitness(m.Term, leaderID)
 		} else {
 			r.becomeFollower(m.Term, leaderID)
 		}
 	} else if m.Term < r.term {
-		if isLeaderMessage(m.Type) && r.checkQuorum {
+		if isLeaderMessage(m.Type) && (r.checkQuorum || r.preVote) {
 			// this corner case is documented in the following etcd test
 			// TestFreeStuckCandidateWithCheckQuorum
+			// with PreVote enabled, the local node might have its term advanced
+			// during a partition and it won't be able to win any election. the
+			// NoOP message helps the leader to learn the higher term and step
+			// down.
 			r.send(pb.Message{To: m.From, Type: pb.NoOP})
+		} else if m.Type == pb.RequestPreVote {
+			// reject the RequestPreVote with the local term so the pre-candidate
+			// can learn that it is behind.
+			plog.Infof("%s rejected RequestPreVote with lower term (%d) from %s",
+				r.describe(), m.Term, NodeID(m.From))
+			r.send(pb.Message{
+				To:     m.From,
+				Type:   pb.RequestPreVoteResp,
+				Term:   r.term,
+				Reject: true,
+			})
 		} else {
 			plog.Infof("%s ignored a %s with lower term (%d) from %s",
 				r.describe(), m.Type, m.Term, NodeID(m.From))
@@ -1207,7 +2113,9 @@ func (r *raft) onMessageTermNotMatched(m pb.Message) bool {
 
 func (r *raft) Handle(m pb.Message) {
 	if !r.onMessageTermNotMatched(m) {
-		r.doubleCheckTermMatched(m.Term)
+		if !isPreVoteMessage(m.Type) {
+			r.doubleCheckTermMatched(m.Term)
+		}
 		r.handle(r, m)
 	} else {
 		plog.Infof("term not matched")
@@ -1254,7 +2162,13 @@ func (r *raft) handleNodeElection(m pb.Message) {
 			return
 		}
 		plog.Infof("%s will campaign at term %d", r.describe(), r.term)
-		r.campaign()
+		// leadership transfer target skips the PreVote phase, see p29 of the
+		// raft thesis, the target is expected to start an election immediately.
+		if r.preVote && !r.isLeaderTransferTarget {
+			r.preVoteCampaign()
+		} else {
+			r.campaign()
+		}
 	} else {
 		plog.Infof("leader node %s ignored Election", r.describe())
 	}
@@ -1283,6 +2197,30 @@ func (r *raft) handleNodeRequestVote(m pb.Message) {
 	r.send(resp)
 }
 
+// section 9.6 of the raft thesis
+func (r *raft) handleNodeRequestPreVote(m pb.Message) {
+	resp := pb.Message{
+		To:   m.From,
+		Type: pb.RequestPreVoteResp,
+	}
+	canGrant := r.canGrantVote(m)
+	isUpToDate := r.log.upToDate(m.LogIndex, m.LogTerm)
+	if canGrant && isUpToDate {
+		// neither the electionTick nor the vote is updated, the node is just
+		// telling the pre-candidate that it would grant the vote
+		plog.Infof("%s cast pre-vote from %s index %d term %d, log term: %d",
+			r.describe(), NodeID(m.From), m.LogIndex, m.Term, m.LogTerm)
+		resp.Term = m.Term
+	} else {
+		plog.Infof("%s rejected pre-vote %s index%d term%d,logterm%d,grant%v,utd%v",
+			r.describe(), NodeID(m.From), m.LogIndex, m.Term,
+			m.LogTerm, canGrant, isUpToDate)
+		resp.Term = r.term
+		resp.Reject = true
+	}
+	r.send(resp)
+}
+
 func (r *raft) handleNodeConfigChange(m pb.Message) {
 	if m.Reject {
 		r.clearPendingConfigChange()
@@ -1296,6 +2234,18 @@ func (r *raft) handleNodeConfigChange(m pb.Message) {
 			r.removeNode(nodeid)
 		case pb.AddObserver:
 			r.addObserver(nodeid)
+		case pb.AddWitness:
+			r.addWitness(nodeid)
+		case pb.PromoteObserver:
+			r.promoteObserver(nodeid)
+		case pb.EnterJoint:
+			var cc pb.ConfigChange
+			if err := cc.Unmarshal(m.Entries[0].Cmd); err != nil {
+				panic(err)
+			}
+			r.enterJoint(cc.Changes)
+		case pb.LeaveJoint:
+			r.leaveJoint()
 		default:
 			panic("unexpected config change type")
 		}
@@ -1334,15 +2284,17 @@ func (r *raft) handleLeaderCheckQuorum(m pb.Message) {
 func (r *raft) handleLeaderPropose(m pb.Message) {
 	if r.selfRemoved() {
 		plog.Warningf("dropping a proposal, local node has been removed")
+		r.reportDroppedProposal(m, NoLeader)
 		return
 	}
 	if r.leaderTransfering() {
 		plog.Warningf("dropping a proposal, leader transfer is ongoing")
+		r.reportDroppedProposal(m, r.leaderTransferTarget)
 		return
 	}
 	for i, e := range m.Entries {
 		if e.Type == pb.ConfigChangeEntry {
-			if r.hasPendingConfigChange() {
+			if r.hasPendingConfigChange() || r.isJointConfig() {
 				plog.Warningf("%s dropped a config change, one is pending",
 					r.describe())
 				m.Entries[i] = pb.Entry{Type: pb.ApplicationEntry}
@@ -1382,6 +2334,7 @@ func (r *raft) addReadyToRead(index uint64, ctx pb.SystemCtx) {
 func (r *raft) handleLeaderReadIndex(m pb.Message) {
 	if r.selfRemoved() {
 		plog.Warningf("dropping a read index request, local node removed")
+		r.reportDroppedReadIndex(m, NoLeader)
 		return
 	}
 	ctx := pb.SystemCtx{
@@ -1394,6 +2347,11 @@ func (r *raft) handleLeaderReadIndex(m pb.Message) {
 			// see raft thesis section 6.4, this is the first step of the ReadIndex
 			// protocol.
 			plog.Warningf("ReadIndex request ignored, no entry committed")
+			r.reportDroppedReadIndex(m, r.nodeID)
+			return
+		}
+		if r.hasValidLease() {
+			r.handleLeaseRead(m, ctx)
 			return
 		}
 		r.readIndex.addRequest(r.log.committed, ctx, m.From)
@@ -1414,8 +2372,27 @@ func (r *raft) handleLeaderReadIndex(m pb.Message) {
 	}
 }
 
+// section 6.4.1 of the raft thesis
+// the leader confirms its leadership using its lease, the committed index is
+// returned without exchanging heartbeat messages with other nodes.
+func (r *raft) handleLeaseRead(m pb.Message, ctx pb.SystemCtx) {
+	if m.From == NoNode || m.From == r.nodeID {
+		r.addReadyToRead(r.log.committed, ctx)
+	} else {
+		r.send(pb.Message{
+			To:       m.From,
+			Type:     pb.ReadIndexResp,
+			LogIndex: r.log.committed,
+			Hint:     m.Hint,
+			HintHigh: m.HintHigh,
+		})
+	}
+}
+
 func (r *raft) handleLeaderReplicateResp(m pb.Message, rp *remote) {
 	rp.setActive()
+	rp.setLastActiveTick(r.tickCount)
+	r.renewLease(rp, m.HintHigh)
 	if !m.Reject {
 		paused := rp.isPaused()
 		if rp.tryUpdate(m.LogIndex) {
@@ -1446,6 +2423,15 @@ func (r *raft) handleLeaderReplicateResp(m pb.Message, rp *remote) {
 
 func (r *raft) handleLeaderHeartbeatResp(m pb.Message, rp *remote) {
 	rp.setActive()
+	rp.setLastActiveTick(r.tickCount)
+	r.renewLease(rp, m.LogIndex)
+	rp.draining = m.Reject
+	if rp.draining && r.leaderTransfering() &&
+		r.leaderTransferTarget == m.From {
+		plog.Infof("%s aborted leader transfer, %s is draining",
+			r.describe(), NodeID(m.From))
+		r.abortLeaderTransfer()
+	}
 	rp.waitToRetry()
 	if rp.match < r.log.lastIndex() {
 		r.sendReplicateMessage(m.From)
@@ -1472,6 +2458,16 @@ func (r *raft) handleLeaderTransfer(m pb.Message, rp *remote) {
 		plog.Warningf("received LeaderTransfer with target pointing to itself")
 		return
 	}
+	if _, ok := r.witnesses[target]; ok {
+		plog.Warningf("LeaderTransfer ignored, target %s is a witness",
+			NodeID(target))
+		return
+	}
+	if rp.draining {
+		plog.Warningf("LeaderTransfer ignored, target %s is draining",
+			NodeID(target))
+		return
+	}
 	r.leaderTransferTarget = target
 	r.electionTick = 0
 	// fast path below
@@ -1486,7 +2482,18 @@ func (r *raft) handleReadIndexLeaderConfirmation(m pb.Message) {
 		Low:  m.Hint,
 		High: m.HintHigh,
 	}
-	ris := r.readIndex.confirm(ctx, m.From, r.quorum())
+	var ris []*readStatus
+	if r.isJointConfig() {
+		ris = r.readIndex.confirmWith(ctx, m.From,
+			func(confirmed map[uint64]struct{}) bool {
+				return r.hasQuorum(func(nid uint64, rp *remote) bool {
+					_, ok := confirmed[nid]
+					return ok || nid == r.nodeID
+				})
+			})
+	} else {
+		ris = r.readIndex.confirm(ctx, m.From, r.quorum())
+	}
 	for _, s := range ris {
 		if s.from == NoNode || s.from == r.nodeID {
 			r.addReadyToRead(s.index, s.ctx)
@@ -1567,6 +2574,22 @@ func (r *raft) handleObserverReadIndexResp(m pb.Message) {
 	r.handleFollowerReadIndexResp(m)
 }
 
+//
+// message handlers used by witness, re-route them to follower handlers
+//
+
+func (r *raft) handleWitnessReplicate(m pb.Message) {
+	r.handleFollowerReplicate(m)
+}
+
+func (r *raft) handleWitnessHeartbeat(m pb.Message) {
+	r.handleFollowerHeartbeat(m)
+}
+
+func (r *raft) handleWitnessSnapshot(m pb.Message) {
+	r.handleFollowerInstallSnapshot(m)
+}
+
 //
 // message handlers used by follower
 //
@@ -1574,6 +2597,7 @@ func (r *raft) handleObserverReadIndexResp(m pb.Message) {
 func (r *raft) handleFollowerPropose(m pb.Message) {
 	if r.leaderID == NoLeader {
 		plog.Warningf("%s dropping proposal as there is no leader", r.describe())
+		r.reportDroppedProposal(m, NoLeader)
 		return
 	}
 	m.To = r.leaderID
@@ -1587,18 +2611,26 @@ func (r *raft) handleFollowerPropose(m pb.Message) {
 func (r *raft) handleFollowerReplicate(m pb.Message) {
 	r.electionTick = 0
 	r.setLeaderID(m.From)
+	r.setLeaderCommit(m.Commit)
 	r.handleReplicateMessage(m)
 }
 
 func (r *raft) handleFollowerHeartbeat(m pb.Message) {
 	r.electionTick = 0
 	r.setLeaderID(m.From)
+	// m.Commit is capped by the match value of this node, it can be far behind
+	// the commit index of the leader when this node is lagging. heartbeats sent
+	// by older versions don't carry the leader's commit index in LogTerm.
+	if m.LogTerm > 0 {
+		r.setLeaderCommit(m.LogTerm)
+	}
 	r.handleHeartbeatMessage(m)
 }
 
 func (r *raft) handleFollowerReadIndex(m pb.Message) {
 	if r.leaderID == NoLeader {
 		plog.Warningf("%s dropped ReadIndex as no leader", r.describe())
+		r.reportDroppedReadIndex(m, NoLeader)
 		return
 	}
 	m.To = r.leaderID
@@ -1636,6 +2668,21 @@ func (r *raft) handleFollowerTimeoutNow(m pb.Message) {
 	// the last paragraph, p29 of the raft thesis mentions that this is nothing
 	// different from the clock moving forward quickly
 	plog.Infof("TimeoutNow received on %d:%d", r.clusterID, r.nodeID)
+	if r.draining {
+		plog.Infof("%s is draining, rejected TimeoutNow from %s",
+			r.describe(), NodeID(m.From))
+		// let the leader know that this node is being drained so it can stop
+		// the ongoing leader transfer
+		r.send(pb.Message{
+			To:     m.From,
+			Type:   pb.HeartbeatResp,
+			Reject: true,
+		})
+		return
+	}
+	if m.Hint != NoNode || len(m.Entries) > 0 {
+		r.setPreference(m.Hint, getPreferredRegion(m))
+	}
 	r.electionTick = r.randomizedElectionTimeout
 	r.isLeaderTransferTarget = true
 	r.tick()
@@ -1656,6 +2703,12 @@ func (r *raft) doubleCheckTermMatched(msgTerm uint64) {
 
 func (r *raft) handleCandidatePropose(m pb.Message) {
 	plog.Warningf("%s dropping proposal, no leader", r.describe())
+	r.reportDroppedProposal(m, NoLeader)
+}
+
+func (r *raft) handleCandidateReadIndex(m pb.Message) {
+	plog.Warningf("%s dropping ReadIndex, no leader", r.describe())
+	r.reportDroppedReadIndex(m, NoLeader)
 }
 
 // when any of the following three methods
@@ -1690,22 +2743,45 @@ func (r *raft) handleCandidateRequestVoteResp(m pb.Message) {
 	plog.Infof("%s received %d votes and %d rejections, quorum is %d",
 		r.describe(), count, len(r.votes)-count, r.quorum())
 	// 3rd paragraph section 5.2 of the raft paper
-	if count == r.quorum() {
+	if r.electionWon() {
 		r.becomeLeader()
 		// get the NoOP entry committed ASAP
 		r.broadcastReplicateMessage()
-	} else if len(r.votes)-count == r.quorum() {
+	} else if r.electionLost() {
 		// etcd raft does this, it is not stated in the raft paper
 		r.becomeFollower(r.term, NoLeader)
 	}
 }
 
+//
+// handler functions used by pre-candidate
+//
+
+func (r *raft) handlePreCandidateRequestPreVoteResp(m pb.Message) {
+	_, ok := r.observers[m.From]
+	if ok {
+		plog.Warningf("dropping a RequestPreVoteResp from observer")
+		return
+	}
+	count := r.handleVoteResp(m.From, m.Reject)
+	plog.Infof("%s received %d pre-votes and %d rejections, quorum is %d",
+		r.describe(), count, len(r.votes)-count, r.quorum())
+	if r.electionWon() {
+		// won the PreVote phase, start the actual election
+		r.campaign()
+	} else if r.electionLost() {
+		r.becomeFollower(r.term, NoLeader)
+	}
+}
+
 func lw(r *raft, f func(m pb.Message, rp *remote)) handlerFunc {
 	w := func(nm pb.Message) {
 		if npr, ok := r.remotes[nm.From]; ok {
 			f(nm, npr)
 		} else if nob, ok := r.observers[nm.From]; ok {
 			f(nm, nob)
+		} else if nwt, ok := r.witnesses[nm.From]; ok {
+			f(nm, nwt)
 		} else {
 			plog.Infof("%s no remote available for %s",
 				r.describe(), NodeID(nm.From))
@@ -1726,14 +2802,35 @@ func (r *raft) initializeHandlerMap() {
 	// candidate
 	r.handlers[candidate][pb.Heartbeat] = r.handleCandidateHeartbeat
 	r.handlers[candidate][pb.Propose] = r.handleCandidatePropose
+	r.handlers[candidate][pb.ReadIndex] = r.handleCandidateReadIndex
 	r.handlers[candidate][pb.Replicate] = r.handleCandidateReplicate
 	r.handlers[candidate][pb.InstallSnapshot] = r.handleCandidateInstallSnapshot
 	r.handlers[candidate][pb.RequestVoteResp] = r.handleCandidateRequestVoteResp
 	r.handlers[candidate][pb.Election] = r.handleNodeElection
 	r.handlers[candidate][pb.RequestVote] = r.handleNodeRequestVote
+	r.handlers[candidate][pb.RequestPreVote] = r.handleNodeRequestPreVote
 	r.handlers[candidate][pb.ConfigChangeEvent] = r.handleNodeConfigChange
 	r.handlers[candidate][pb.LocalTick] = r.handleLocalTick
 	r.handlers[candidate][pb.SnapshotReceived] = r.handleRestoreRemote
+	r.handlers[candidate][pb.ProposalDropped] = r.handleNodeProposalDropped
+	r.handlers[candidate][pb.ReadIndexDropped] = r.handleNodeReadIndexDropped
+	r.handlers[candidate][pb.PreferredLeader] = r.handleNodePreferredLeader
+	// pre-candidate
+	r.handlers[preCandidate][pb.Heartbeat] = r.handleCandidateHeartbeat
+	r.handlers[preCandidate][pb.Propose] = r.handleCandidatePropose
+	r.handlers[preCandidate][pb.ReadIndex] = r.handleCandidateReadIndex
+	r.handlers[preCandidate][pb.Replicate] = r.handleCandidateReplicate
+	r.handlers[preCandidate][pb.InstallSnapshot] = r.handleCandidateInstallSnapshot
+	r.handlers[preCandidate][pb.RequestPreVoteResp] = r.handlePreCandidateRequestPreVoteResp
+	r.handlers[preCandidate][pb.Election] = r.handleNodeElection
+	r.handlers[preCandidate][pb.RequestVote] = r.handleNodeRequestVote
+	r.handlers[preCandidate][pb.RequestPreVote] = r.handleNodeRequestPreVote
+	r.handlers[preCandidate][pb.ConfigChangeEvent] = r.handleNodeConfigChange
+	r.handlers[preCandidate][pb.LocalTick] = r.handleLocalTick
+	r.handlers[preCandidate][pb.SnapshotReceived] = r.handleRestoreRemote
+	r.handlers[preCandidate][pb.ProposalDropped] = r.handleNodeProposalDropped
+	r.handlers[preCandidate][pb.ReadIndexDropped] = r.handleNodeReadIndexDropped
+	r.handlers[preCandidate][pb.PreferredLeader] = r.handleNodePreferredLeader
 	// follower
 	r.handlers[follower][pb.Propose] = r.handleFollowerPropose
 	r.handlers[follower][pb.Replicate] = r.handleFollowerReplicate
@@ -1744,10 +2841,14 @@ func (r *raft) initializeHandlerMap() {
 	r.handlers[follower][pb.InstallSnapshot] = r.handleFollowerInstallSnapshot
 	r.handlers[follower][pb.Election] = r.handleNodeElection
 	r.handlers[follower][pb.RequestVote] = r.handleNodeRequestVote
+	r.handlers[follower][pb.RequestPreVote] = r.handleNodeRequestPreVote
 	r.handlers[follower][pb.TimeoutNow] = r.handleFollowerTimeoutNow
 	r.handlers[follower][pb.ConfigChangeEvent] = r.handleNodeConfigChange
 	r.handlers[follower][pb.LocalTick] = r.handleLocalTick
 	r.handlers[follower][pb.SnapshotReceived] = r.handleRestoreRemote
+	r.handlers[follower][pb.ProposalDropped] = r.handleNodeProposalDropped
+	r.handlers[follower][pb.ReadIndexDropped] = r.handleNodeReadIndexDropped
+	r.handlers[follower][pb.PreferredLeader] = r.handleNodePreferredLeader
 	// leader
 	r.handlers[leader][pb.LeaderHeartbeat] = r.handleLeaderHeartbeat
 	r.handlers[leader][pb.CheckQuorum] = r.handleLeaderCheckQuorum
@@ -1760,9 +2861,13 @@ func (r *raft) initializeHandlerMap() {
 	r.handlers[leader][pb.LeaderTransfer] = lw(r, r.handleLeaderTransfer)
 	r.handlers[leader][pb.Election] = r.handleNodeElection
 	r.handlers[leader][pb.RequestVote] = r.handleNodeRequestVote
+	r.handlers[leader][pb.RequestPreVote] = r.handleNodeRequestPreVote
 	r.handlers[leader][pb.ConfigChangeEvent] = r.handleNodeConfigChange
 	r.handlers[leader][pb.LocalTick] = r.handleLocalTick
 	r.handlers[leader][pb.SnapshotReceived] = r.handleRestoreRemote
+	r.handlers[leader][pb.ProposalDropped] = r.handleNodeProposalDropped
+	r.handlers[leader][pb.ReadIndexDropped] = r.handleNodeReadIndexDropped
+	r.handlers[leader][pb.PreferredLeader] = r.handleLeaderPreferredLeader
 	r.handlers[leader][pb.RateLimit] = r.handleLeaderRateLimit
 	// observer
 	r.handlers[observer][pb.Heartbeat] = r.handleObserverHeartbeat
@@ -1774,6 +2879,18 @@ func (r *raft) initializeHandlerMap() {
 	r.handlers[observer][pb.ConfigChangeEvent] = r.handleNodeConfigChange
 	r.handlers[observer][pb.LocalTick] = r.handleLocalTick
 	r.handlers[observer][pb.SnapshotReceived] = r.handleRestoreRemote
+	r.handlers[observer][pb.ProposalDropped] = r.handleNodeProposalDropped
+	r.handlers[observer][pb.ReadIndexDropped] = r.handleNodeReadIndexDropped
+	r.handlers[observer][pb.PreferredLeader] = r.handleNodePreferredLeader
+	// witness
+	r.handlers[witness][pb.Heartbeat] = r.handleWitnessHeartbeat
+	r.handlers[witness][pb.Replicate] = r.handleWitnessReplicate
+	r.handlers[witness][pb.InstallSnapshot] = r.handleWitnessSnapshot
+	r.handlers[witness][pb.RequestVote] = r.handleNodeRequestVote
+	r.handlers[witness][pb.RequestPreVote] = r.handleNodeRequestPreVote
+	r.handlers[witness][pb.ConfigChangeEvent] = r.handleNodeConfigChange
+	r.handlers[witness][pb.LocalTick] = r.handleLocalTick
+	r.handlers[witness][pb.SnapshotReceived] = r.handleRestoreRemote
 }
 
 func (r *raft) checkHandlerMap() {
@@ -1794,11 +2911,32 @@ func (r *raft) checkHandlerMap() {
 		{candidate, pb.HeartbeatResp},
 		{candidate, pb.SnapshotStatus},
 		{candidate, pb.Unreachable},
+		{candidate, pb.RequestPreVoteResp},
+		{preCandidate, pb.ReplicateResp},
+		{preCandidate, pb.HeartbeatResp},
+		{preCandidate, pb.SnapshotStatus},
+		{preCandidate, pb.Unreachable},
+		{preCandidate, pb.RequestVoteResp},
 		{observer, pb.Election},
 		{observer, pb.RequestVote},
 		{observer, pb.RequestVoteResp},
+		{observer, pb.RequestPreVote},
+		{observer, pb.RequestPreVoteResp},
 		{observer, pb.ReplicateResp},
 		{observer, pb.HeartbeatResp},
+		{witness, pb.Election},
+		{witness, pb.Propose},
+		{witness, pb.ReadIndex},
+		{witness, pb.ReadIndexResp},
+		{witness, pb.TimeoutNow},
+		{witness, pb.LeaderTransfer},
+		{witness, pb.RequestVoteResp},
+		{witness, pb.RequestPreVoteResp},
+		{witness, pb.ReplicateResp},
+		{witness, pb.HeartbeatResp},
+		{witness, pb.ProposalDropped},
+		{witness, pb.ReadIndexDropped},
+		{witness, pb.PreferredLeader},
 	}
 	for _, tt := range checks {
 		f := r.handlers[tt.stateType][tt.msgType]
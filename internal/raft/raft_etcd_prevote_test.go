@@ -0,0 +1,429 @@
+// Copyright 2015 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains tests which verify that the PreVote extension described
+in section 9.6 of the raft thesis is handled by the raft implementation
+correctly. Each test is composed of three parts: init, test and check, the
+same as tests in raft_etcd_paper_test.go.
+*/
+
+//
+// raft_etcd_prevote_test.go is ported from etcd raft for testing purposes.
+// updates have been made to reflect the interface & implementation differences
+//
+
+package raft
+
+import (
+	"testing"
+
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func newPreVoteTestRaft(id uint64, checkQuorum bool) *raft {
+	r := newTestRaft(id, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.becomeFollower(1, NoLeader)
+	r.preVote = true
+	r.checkQuorum = checkQuorum
+	return r
+}
+
+// TestPreVoteCampaignDoesNotIncreaseTerm tests that a node in the PreVote
+// phase sends out RequestPreVote messages with the term it would use in the
+// actual election without incrementing its own term or changing its vote.
+// Reference: section 9.6 of the raft thesis
+func TestPreVoteCampaignDoesNotIncreaseTerm(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.Handle(pb.Message{From: 1, To: 1, Type: pb.Election})
+	if r.state != preCandidate {
+		t.Errorf("state = %s, want %s", r.state, preCandidate)
+	}
+	if r.term != 1 {
+		t.Errorf("term = %d, want 1", r.term)
+	}
+	if r.vote != NoNode {
+		t.Errorf("vote = %d, want %d", r.vote, NoNode)
+	}
+	msgs := r.readMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("len(msgs) = %d, want 2", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Type != pb.RequestPreVote {
+			t.Errorf("#%d: type = %s, want %s", i, m.Type, pb.RequestPreVote)
+		}
+		if m.Term != 2 {
+			t.Errorf("#%d: term = %d, want 2", i, m.Term)
+		}
+	}
+}
+
+// TestPreCandidateBecomesCandidateOnQuorum tests that a pre-candidate starts
+// the actual election once it receives pre-votes from a quorum.
+// Reference: section 9.6 of the raft thesis
+func TestPreCandidateBecomesCandidateOnQuorum(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.Handle(pb.Message{From: 1, To: 1, Type: pb.Election})
+	r.readMessages()
+	r.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestPreVoteResp, Term: 2})
+	if r.state != candidate {
+		t.Errorf("state = %s, want %s", r.state, candidate)
+	}
+	if r.term != 2 {
+		t.Errorf("term = %d, want 2", r.term)
+	}
+	if r.vote != 1 {
+		t.Errorf("vote = %d, want 1", r.vote)
+	}
+	msgs := r.readMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("len(msgs) = %d, want 2", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Type != pb.RequestVote || m.Term != 2 {
+			t.Errorf("#%d: unexpected msg %+v", i, m)
+		}
+	}
+}
+
+// TestPreCandidateRevertsToFollowerOnRejections tests that a pre-candidate
+// becomes a follower once a quorum of nodes rejected its pre-vote requests.
+// Reference: section 9.6 of the raft thesis
+func TestPreCandidateRevertsToFollowerOnRejections(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.Handle(pb.Message{From: 1, To: 1, Type: pb.Election})
+	r.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestPreVoteResp, Term: 1,
+		Reject: true})
+	if r.state != preCandidate {
+		t.Errorf("state = %s, want %s", r.state, preCandidate)
+	}
+	r.Handle(pb.Message{From: 3, To: 1, Type: pb.RequestPreVoteResp, Term: 1,
+		Reject: true})
+	if r.state != follower {
+		t.Errorf("state = %s, want %s", r.state, follower)
+	}
+	if r.term != 1 {
+		t.Errorf("term = %d, want 1", r.term)
+	}
+}
+
+// TestPreCandidateStepsDownOnHigherTermRejection tests that a pre-candidate
+// learns the higher term from a rejected RequestPreVoteResp and becomes a
+// follower.
+func TestPreCandidateStepsDownOnHigherTermRejection(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.Handle(pb.Message{From: 1, To: 1, Type: pb.Election})
+	r.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestPreVoteResp, Term: 5,
+		Reject: true})
+	if r.state != follower {
+		t.Errorf("state = %s, want %s", r.state, follower)
+	}
+	if r.term != 5 {
+		t.Errorf("term = %d, want 5", r.term)
+	}
+}
+
+// TestRejectLowerTermPreVote tests that a node rejects RequestPreVote with a
+// lower term and tells the pre-candidate its own term.
+func TestRejectLowerTermPreVote(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.becomeFollower(5, NoLeader)
+	r.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestPreVote, Term: 3})
+	msgs := r.readMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("len(msgs) = %d, want 1", len(msgs))
+	}
+	m := msgs[0]
+	if m.Type != pb.RequestPreVoteResp || !m.Reject || m.Term != 5 {
+		t.Errorf("unexpected msg %+v", m)
+	}
+	if r.term != 5 {
+		t.Errorf("term = %d, want 5", r.term)
+	}
+}
+
+// TestGrantPreVoteDoesNotChangeState tests that granting a pre-vote doesn't
+// change the term, the vote nor the election tick of the voter.
+func TestGrantPreVoteDoesNotChangeState(t *testing.T) {
+	r := newPreVoteTestRaft(1, false)
+	r.electionTick = 3
+	r.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestPreVote, Term: 2})
+	msgs := r.readMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("len(msgs) = %d, want 1", len(msgs))
+	}
+	m := msgs[0]
+	if m.Type != pb.RequestPreVoteResp || m.Reject || m.Term != 2 {
+		t.Errorf("unexpected msg %+v", m)
+	}
+	if r.term != 1 {
+		t.Errorf("term = %d, want 1", r.term)
+	}
+	if r.vote != NoNode {
+		t.Errorf("vote = %d, want %d", r.vote, NoNode)
+	}
+	if r.electionTick != 3 {
+		t.Errorf("electionTick = %d, want 3", r.electionTick)
+	}
+}
+
+// TestPreVoteWithSplitVote verifies that after split vote, cluster can complete
+// election in next round.
+func TestPreVoteWithSplitVote(t *testing.T) {
+	n1 := newPreVoteTestRaft(1, false)
+	n2 := newPreVoteTestRaft(2, false)
+	n3 := newPreVoteTestRaft(3, false)
+
+	nt := newNetwork(n1, n2, n3)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+
+	// simulate leader down. followers start split vote.
+	nt.isolate(1)
+	nt.send([]pb.Message{
+		{From: 2, To: 2, Type: pb.Election},
+		{From: 3, To: 3, Type: pb.Election},
+	}...)
+
+	// check whether the term values are expected
+	// n2.term == 3
+	// n3.term == 3
+	sm := nt.peers[2].(*raft)
+	if sm.term != 3 {
+		t.Errorf("peer 2 term: %d, want %d", sm.term, 3)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.term != 3 {
+		t.Errorf("peer 3 term: %d, want %d", sm.term, 3)
+	}
+
+	// check state
+	// n2 == candidate
+	// n3 == candidate
+	sm = nt.peers[2].(*raft)
+	if sm.state != candidate {
+		t.Errorf("peer 2 state: %s, want %s", sm.state, candidate)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.state != candidate {
+		t.Errorf("peer 3 state: %s, want %s", sm.state, candidate)
+	}
+
+	// node 2 election timeout first
+	nt.send(pb.Message{From: 2, To: 2, Type: pb.Election})
+
+	// check whether the term values are expected
+	// n2.term == 4
+	// n3.term == 4
+	sm = nt.peers[2].(*raft)
+	if sm.term != 4 {
+		t.Errorf("peer 2 term: %d, want %d", sm.term, 4)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.term != 4 {
+		t.Errorf("peer 3 term: %d, want %d", sm.term, 4)
+	}
+
+	// check state
+	// n2 == leader
+	// n3 == follower
+	sm = nt.peers[2].(*raft)
+	if sm.state != leader {
+		t.Errorf("peer 2 state: %s, want %s", sm.state, leader)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.state != follower {
+		t.Errorf("peer 3 state: %s, want %s", sm.state, follower)
+	}
+}
+
+// TestPreVoteWithCheckQuorum ensures that after a node become pre-candidate,
+// it will checkQuorum correctly.
+func TestPreVoteWithCheckQuorum(t *testing.T) {
+	n1 := newPreVoteTestRaft(1, true)
+	n2 := newPreVoteTestRaft(2, true)
+	n3 := newPreVoteTestRaft(3, true)
+
+	nt := newNetwork(n1, n2, n3)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+
+	// isolate node 1. node 2 and node 3 have leader info
+	nt.isolate(1)
+
+	// check state
+	sm := nt.peers[1].(*raft)
+	if sm.state != leader {
+		t.Fatalf("peer 1 state: %s, want %s", sm.state, leader)
+	}
+	sm = nt.peers[2].(*raft)
+	if sm.state != follower {
+		t.Fatalf("peer 2 state: %s, want %s", sm.state, follower)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.state != follower {
+		t.Fatalf("peer 3 state: %s, want %s", sm.state, follower)
+	}
+
+	// node 2 will ignore node 3's PreVote
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+	nt.send(pb.Message{From: 2, To: 2, Type: pb.Election})
+
+	// Do we have a leader?
+	if n2.state != leader || n3.state != follower {
+		t.Errorf("no leader, n2 %s, n3 %s", n2.state, n3.state)
+	}
+}
+
+// TestNodeWithSmallerTermCanCompleteElection tests the scenario where a node
+// that has been partitioned away (and fallen behind) rejoins the cluster at
+// about the same time the leader node gets partitioned away.
+// Previously the cluster would come to a standstill when run with PreVote
+// enabled.
+func TestNodeWithSmallerTermCanCompleteElection(t *testing.T) {
+	n1 := newPreVoteTestRaft(1, false)
+	n2 := newPreVoteTestRaft(2, false)
+	n3 := newPreVoteTestRaft(3, false)
+
+	// cause a network partition to isolate node 3
+	nt := newNetwork(n1, n2, n3)
+	nt.cut(1, 3)
+	nt.cut(2, 3)
+
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+
+	sm := nt.peers[1].(*raft)
+	if sm.state != leader {
+		t.Errorf("peer 1 state: %s, want %s", sm.state, leader)
+	}
+	sm = nt.peers[2].(*raft)
+	if sm.state != follower {
+		t.Errorf("peer 2 state: %s, want %s", sm.state, follower)
+	}
+
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+	sm = nt.peers[3].(*raft)
+	if sm.state != preCandidate {
+		t.Errorf("peer 3 state: %s, want %s", sm.state, preCandidate)
+	}
+
+	nt.send(pb.Message{From: 2, To: 2, Type: pb.Election})
+
+	// check whether the term values are expected
+	// a.term == 3
+	// b.term == 3
+	// c.term == 1
+	sm = nt.peers[1].(*raft)
+	if sm.term != 3 {
+		t.Errorf("peer 1 term: %d, want %d", sm.term, 3)
+	}
+	sm = nt.peers[2].(*raft)
+	if sm.term != 3 {
+		t.Errorf("peer 2 term: %d, want %d", sm.term, 3)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.term != 1 {
+		t.Errorf("peer 3 term: %d, want %d", sm.term, 1)
+	}
+
+	// check state
+	// a == follower
+	// b == leader
+	// c == pre-candidate
+	sm = nt.peers[1].(*raft)
+	if sm.state != follower {
+		t.Errorf("peer 1 state: %s, want %s", sm.state, follower)
+	}
+	sm = nt.peers[2].(*raft)
+	if sm.state != leader {
+		t.Errorf("peer 2 state: %s, want %s", sm.state, leader)
+	}
+	sm = nt.peers[3].(*raft)
+	if sm.state != preCandidate {
+		t.Errorf("peer 3 state: %s, want %s", sm.state, preCandidate)
+	}
+
+	// recover the network then immediately isolate b which is currently
+	// the leader, this is to emulate the crash of b.
+	nt.recover()
+	nt.cut(2, 1)
+	nt.cut(2, 3)
+
+	// call for election
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+
+	// do we have a leader?
+	sma := nt.peers[1].(*raft)
+	smb := nt.peers[3].(*raft)
+	if sma.state != leader && smb.state != leader {
+		t.Errorf("no leader")
+	}
+}
+
+// TestPartitionedNodeDoesNotDisruptLeader tests that a node partitioned away
+// from the rest of the cluster doesn't keep increasing its term, it rejoins
+// the cluster without forcing the leader to step down.
+func TestPartitionedNodeDoesNotDisruptLeader(t *testing.T) {
+	n1 := newPreVoteTestRaft(1, true)
+	n2 := newPreVoteTestRaft(2, true)
+	n3 := newPreVoteTestRaft(3, true)
+
+	nt := newNetwork(n1, n2, n3)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	if n1.state != leader {
+		t.Fatalf("peer 1 state: %s, want %s", n1.state, leader)
+	}
+	term := n1.term
+
+	nt.isolate(3)
+	for i := 0; i < 10; i++ {
+		nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+	}
+	if n3.term != term {
+		t.Errorf("peer 3 term: %d, want %d", n3.term, term)
+	}
+	if n3.state != preCandidate {
+		t.Errorf("peer 3 state: %s, want %s", n3.state, preCandidate)
+	}
+
+	nt.recover()
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	if n1.state != leader || n1.term != term {
+		t.Errorf("leader disrupted, state %s, term %d", n1.state, n1.term)
+	}
+	if n3.state != follower || n3.leaderID != 1 || n3.term != term {
+		t.Errorf("peer 3 state %s, leader %d, term %d",
+			n3.state, n3.leaderID, n3.term)
+	}
+}
+
+// TestLeaderTransferSkipsPreVote tests that the leader transfer target starts
+// the actual election immediately when it receives the TimeoutNow message.
+// Reference: p29 of the raft thesis
+func TestLeaderTransferSkipsPreVote(t *testing.T) {
+	nt := newNetworkWithConfig(preVoteConfig, nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	lead := nt.peers[1].(*raft)
+	if lead.state != leader {
+		t.Fatalf("peer 1 state: %s, want %s", lead.state, leader)
+	}
+	nt.ignore(pb.RequestPreVote)
+	nt.send(pb.Message{From: 2, To: 1, Hint: 2, Type: pb.LeaderTransfer})
+	checkLeaderTransferState(t, lead, follower, 2)
+	sm := nt.peers[2].(*raft)
+	if sm.state != leader || sm.term != 2 {
+		t.Errorf("peer 2 state %s, term %d", sm.state, sm.term)
+	}
+}
@@ -412,17 +412,18 @@ func TestRemotePaused(t *testing.T) {
 }
 
 func TestLeaderElection(t *testing.T) {
-	testLeaderElection(t)
+	testLeaderElection(t, false)
 }
 
-/*
 func TestLeaderElectionPreVote(t *testing.T) {
 	testLeaderElection(t, true)
 }
-*/
 
-func testLeaderElection(t *testing.T) {
+func testLeaderElection(t *testing.T, preVote bool) {
 	var cfg func(*config.Config)
+	if preVote {
+		cfg = preVoteConfig
+	}
 	tests := []struct {
 		*network
 		state   State
@@ -446,8 +447,16 @@ func testLeaderElection(t *testing.T) {
 		sm := tt.network.peers[1].(*raft)
 		var expState State
 		var expTerm uint64
-		expState = tt.state
-		expTerm = tt.expTerm
+		if tt.state == candidate && preVote {
+			// In pre-vote mode, an election that fails to complete
+			// leaves the node in pre-candidate state without advancing
+			// the term.
+			expState = preCandidate
+			expTerm = 0
+		} else {
+			expState = tt.state
+			expTerm = tt.expTerm
+		}
 
 		if sm.state != expState {
 			t.Errorf("#%d: state = %s, want %s", i, sm.state, expState)
@@ -459,15 +468,22 @@ func testLeaderElection(t *testing.T) {
 }
 
 func TestLeaderCycle(t *testing.T) {
-	testLeaderCycle(t)
+	testLeaderCycle(t, false)
+}
+
+func TestLeaderCyclePreVote(t *testing.T) {
+	testLeaderCycle(t, true)
 }
 
 // testLeaderCycle verifies that each node in a cluster can campaign
 // and be elected in turn. This ensures that elections (including
 // pre-vote) work when not starting from a clean slate (as they do in
 // TestLeaderElection)
-func testLeaderCycle(t *testing.T) {
+func testLeaderCycle(t *testing.T, preVote bool) {
 	var cfg func(*config.Config)
+	if preVote {
+		cfg = preVoteConfig
+	}
 	n := newNetworkWithConfig(cfg, nil, nil, nil)
 	for campaignerID := uint64(1); campaignerID <= 3; campaignerID++ {
 		n.send(pb.Message{From: campaignerID, To: campaignerID, Type: pb.Election})
@@ -491,11 +507,18 @@ func testLeaderCycle(t *testing.T) {
 // log entries, and must overwrite higher-term log entries with
 // lower-term ones.
 func TestLeaderElectionOverwriteNewerLogs(t *testing.T) {
-	testLeaderElectionOverwriteNewerLogs(t)
+	testLeaderElectionOverwriteNewerLogs(t, false)
 }
 
-func testLeaderElectionOverwriteNewerLogs(t *testing.T) {
+func TestLeaderElectionOverwriteNewerLogsPreVote(t *testing.T) {
+	testLeaderElectionOverwriteNewerLogs(t, true)
+}
+
+func testLeaderElectionOverwriteNewerLogs(t *testing.T, preVote bool) {
 	var cfg func(*config.Config)
+	if preVote {
+		cfg = preVoteConfig
+	}
 	// This network represents the results of the following sequence of
 	// events:
 	// - Node 1 won the election in term 1.
@@ -559,14 +582,23 @@ func TestVoteFromAnyState(t *testing.T) {
 	testVoteFromAnyState(t, pb.RequestVote)
 }
 
+func TestPreVoteFromAnyState(t *testing.T) {
+	testVoteFromAnyState(t, pb.RequestPreVote)
+}
+
 func testVoteFromAnyState(t *testing.T, vt pb.MessageType) {
 	for st := State(0); st < numStates; st++ {
+		if vt == pb.RequestPreVote && (st == observer || st == witness) {
+			continue
+		}
 		r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
 		r.term = 1
 
 		switch st {
 		case follower:
 			r.becomeFollower(r.term, 3)
+		case preCandidate:
+			r.becomePreCandidate()
 		case candidate:
 			r.becomeCandidate()
 		case leader:
@@ -592,9 +624,9 @@ func testVoteFromAnyState(t *testing.T, vt pb.MessageType) {
 			t.Errorf("%s,%s: %d response messages, want 1: %+v", vt, st, len(r.msgs), r.msgs)
 		} else {
 			resp := r.msgs[0]
-			if resp.Type != pb.RequestVoteResp {
+			if resp.Type != voteRespMsgType(vt) {
 				t.Errorf("%s,%s: response message is %s, want %s",
-					vt, st, resp.Type, pb.RequestVoteResp)
+					vt, st, resp.Type, voteRespMsgType(vt))
 			}
 			if resp.Reject {
 				t.Errorf("%s,%s: unexpected rejection", vt, st)
@@ -842,17 +874,13 @@ func TestDuelingCandidates(t *testing.T) {
 	}
 }
 
-/*
 func TestDuelingPreCandidates(t *testing.T) {
-	cfgA := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
-	cfgB := newTestConfig(2, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
-	cfgC := newTestConfig(3, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
-	cfgA.PreVote = true
-	cfgB.PreVote = true
-	cfgC.PreVote = true
-	a := newRaft(cfgA)
-	b := newRaft(cfgB)
-	c := newRaft(cfgC)
+	a := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	b := newTestRaft(2, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	c := newTestRaft(3, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	a.preVote = true
+	b.preVote = true
+	c.preVote = true
 
 	nt := newNetwork(a, b, c)
 	nt.cut(1, 3)
@@ -879,19 +907,19 @@ func TestDuelingPreCandidates(t *testing.T) {
 	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
 
 	wlog := &entryLog{
-		stable:    &MemoryStorage{ents: []pb.Entry{{}, {Cmd: nil, Term: 1, Index: 1}}},
+		logdb:     &TestLogDB{entries: []pb.Entry{{Cmd: nil, Term: 1, Index: 1}}},
 		committed: 1,
-		unstable:  unstable{offset: 2},
+		inmem:     inMemory{markerIndex: 2},
 	}
 	tests := []struct {
-		sm      *raft
-		state   State
-		term    uint64
+		sm       *raft
+		state    State
+		term     uint64
 		entryLog *entryLog
 	}{
 		{a, leader, 1, wlog},
 		{b, follower, 1, wlog},
-		{c, follower, 1, newLog(NewTestLogDB())},
+		{c, follower, 1, newEntryLog(NewTestLogDB(), server.NewRateLimiter(0))},
 	}
 
 	for i, tt := range tests {
@@ -911,7 +939,7 @@ func TestDuelingPreCandidates(t *testing.T) {
 			t.Logf("#%d: empty log", i)
 		}
 	}
-}*/
+}
 
 func TestCandidateConcede(t *testing.T) {
 	tt := newNetwork(nil, nil, nil)
@@ -1422,6 +1450,10 @@ func TestRecvMsgVote(t *testing.T) {
 	testRecvMsgVote(t, pb.RequestVote)
 }
 
+func TestRecvMsgPreVote(t *testing.T) {
+	testRecvMsgVote(t, pb.RequestPreVote)
+}
+
 func testRecvMsgVote(t *testing.T, msgType pb.MessageType) {
 	tests := []struct {
 		state   State
@@ -1453,7 +1485,7 @@ func testRecvMsgVote(t *testing.T, msgType pb.MessageType) {
 		{follower, 3, 2, 1, true},
 
 		{leader, 3, 3, 1, true},
-		//{statePreCandidate, 3, 3, 1, true},
+		{preCandidate, 3, 3, 1, true},
 		{candidate, 3, 3, 1, true},
 	}
 
@@ -1473,6 +1505,9 @@ func testRecvMsgVote(t *testing.T, msgType pb.MessageType) {
 			t.Fatalf("#%d: len(msgs) = %d, want 1", i, g)
 			continue
 		}
+		if g := msgs[0].Type; g != voteRespMsgType(msgType) {
+			t.Errorf("#%d, m.Type = %v, want %v", i, g, voteRespMsgType(msgType))
+		}
 		if g := msgs[0].Reject; g != tt.wreject {
 			t.Errorf("#%d, m.Reject = %v, want %v", i, g, tt.wreject)
 		}
@@ -1488,22 +1523,22 @@ func TestStateTransition(t *testing.T) {
 		wlead  uint64
 	}{
 		{follower, follower, true, 1, NoLeader},
-		//{follower, statePreCandidate, true, 0, NoLeader},
+		{follower, preCandidate, true, 0, NoLeader},
 		{follower, candidate, true, 1, NoLeader},
 		{follower, leader, false, 0, NoLeader},
 
-		//{statePreCandidate, follower, true, 0, NoLeader},
-		//{statePreCandidate, statePreCandidate, true, 0, NoLeader},
-		//{statePreCandidate, candidate, true, 1, NoLeader},
-		//{statePreCandidate, leader, true, 0, 1},
+		{preCandidate, follower, true, 0, NoLeader},
+		{preCandidate, preCandidate, true, 0, NoLeader},
+		{preCandidate, candidate, true, 1, NoLeader},
+		{preCandidate, leader, false, 0, NoLeader},
 
 		{candidate, follower, true, 0, NoLeader},
-		//{candidate, statePreCandidate, true, 0, NoLeader},
+		{candidate, preCandidate, true, 0, NoLeader},
 		{candidate, candidate, true, 1, NoLeader},
 		{candidate, leader, true, 0, 1},
 
 		{leader, follower, true, 1, NoLeader},
-		//{leader, statePreCandidate, false, 0, NoLeader},
+		{leader, preCandidate, false, 0, NoLeader},
 		{leader, candidate, false, 1, NoLeader},
 		{leader, leader, true, 0, 1},
 	}
@@ -1527,6 +1562,8 @@ func TestStateTransition(t *testing.T) {
 			switch tt.to {
 			case follower:
 				sm.becomeFollower(tt.wterm, tt.wlead)
+			case preCandidate:
+				sm.becomePreCandidate()
 			case candidate:
 				sm.becomeCandidate()
 			case leader:
@@ -1552,7 +1589,7 @@ func TestAllServerStepdown(t *testing.T) {
 		windex uint64
 	}{
 		{follower, follower, 3, 0},
-		//{statePreCandidate, follower, 3, 0},
+		{preCandidate, follower, 3, 0},
 		{candidate, follower, 3, 0},
 		{leader, follower, 3, 1},
 	}
@@ -1565,6 +1602,8 @@ func TestAllServerStepdown(t *testing.T) {
 		switch tt.state {
 		case follower:
 			sm.becomeFollower(1, NoLeader)
+		case preCandidate:
+			sm.becomePreCandidate()
 		case candidate:
 			sm.becomeCandidate()
 		case leader:
@@ -1727,11 +1766,71 @@ func TestFreeStuckCandidateWithCheckQuorum(t *testing.T) {
 	testFreeStuckCandidateWithCheckQuorum(t)
 }
 
-/*
+// TestFreeStuckCandidateWithCheckQuorumAndPreVote ensures that with PreVote
+// enabled, the isolated node never gets stuck with a higher term. It stays as
+// a pre-candidate and rejoins the cluster as a follower without disrupting the
+// leader.
 func TestFreeStuckCandidateWithCheckQuorumAndPreVote(t *testing.T) {
-	testFreeStuckCandidateWithCheckQuorum(t, true)
+	a := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	b := newTestRaft(2, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	c := newTestRaft(3, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+
+	a.checkQuorum = true
+	b.checkQuorum = true
+	c.checkQuorum = true
+	a.preVote = true
+	b.preVote = true
+	c.preVote = true
+	nt := newNetwork(a, b, c)
+	setRandomizedElectionTimeout(b, b.electionTimeout+1)
+
+	for i := uint64(0); i < b.electionTimeout; i++ {
+		b.tick()
+	}
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+
+	nt.isolate(1)
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+
+	if b.state != follower {
+		t.Errorf("state = %s, want %s", b.state, follower)
+	}
+
+	if c.state != preCandidate {
+		t.Errorf("state = %s, want %s", c.state, preCandidate)
+	}
+
+	if c.term != b.term {
+		t.Errorf("term = %d, want %d", c.term, b.term)
+	}
+
+	// Vote again for safety
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.Election})
+
+	if c.state != preCandidate {
+		t.Errorf("state = %s, want %s", c.state, preCandidate)
+	}
+
+	if c.term != b.term {
+		t.Errorf("term = %d, want %d", c.term, b.term)
+	}
+
+	nt.recover()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+
+	// the leader is not disrupted
+	if a.state != leader {
+		t.Errorf("state = %s, want %s", a.state, leader)
+	}
+
+	if c.state != follower {
+		t.Errorf("state = %s, want %s", c.state, follower)
+	}
+
+	if c.term != a.term {
+		t.Errorf("term = %d, want %d", c.term, a.term)
+	}
 }
-*/
 
 // TestFreeStuckCandidateWithCheckQuorum ensures that a candidate with a higher term
 // can disrupt the leader even if the leader still "officially" holds the lease, The
@@ -1986,8 +2085,9 @@ func TestBcastBeat(t *testing.T) {
 		if m.LogIndex != 0 {
 			t.Fatalf("#%d: prevIndex = %d, want %d", i, m.LogIndex, 0)
 		}
-		if m.LogTerm != 0 {
-			t.Fatalf("#%d: prevTerm = %d, want %d", i, m.LogTerm, 0)
+		// LogTerm is the uncapped commit index of the leader
+		if m.LogTerm != sm.log.committed {
+			t.Fatalf("#%d: prevTerm = %d, want %d", i, m.LogTerm, sm.log.committed)
 		}
 		if wantCommitMap[m.To] == 0 {
 			t.Fatalf("#%d: unexpected to %d", i, m.To)
@@ -2784,6 +2884,17 @@ func entsWithConfig(configFunc func(*config.Config), terms ...uint64) *raft {
 	return sm
 }
 
+func preVoteConfig(c *config.Config) {
+	c.PreVote = true
+}
+
+func voteRespMsgType(t pb.MessageType) pb.MessageType {
+	if t == pb.RequestVote {
+		return pb.RequestVoteResp
+	}
+	return pb.RequestPreVoteResp
+}
+
 // votedWithConfig creates a raft state machine with Vote and Term set
 // to the given value but no log entries (indicating that it voted in
 // the given term but has not received any logs).
@@ -2841,12 +2952,19 @@ func newNetworkWithConfig(configFunc func(*config.Config), peers ...stateMachine
 			for i := range v.observers {
 				observers[i] = true
 			}
+			witnesses := make(map[uint64]bool)
+			for i := range v.witnesses {
+				witnesses[i] = true
+			}
 			v.nodeID = id
 			v.remotes = make(map[uint64]*remote)
 			v.observers = make(map[uint64]*remote)
+			v.witnesses = make(map[uint64]*remote)
 			for i := 0; i < size; i++ {
 				if _, ok := observers[peerAddrs[i]]; ok {
 					v.observers[peerAddrs[i]] = &remote{}
+				} else if _, ok := witnesses[peerAddrs[i]]; ok {
+					v.witnesses[peerAddrs[i]] = &remote{}
 				} else {
 					v.remotes[peerAddrs[i]] = &remote{}
 				}
@@ -3004,3 +3122,21 @@ func newTestObserver(id uint64, peers []uint64, observers []uint64, election, he
 	r.hasNotAppliedConfigChange = r.testOnlyHasConfigChangeToApply
 	return r
 }
+
+func newTestWitness(id uint64, peers []uint64, witnesses []uint64, election, heartbeat int, logdb ILogDB) *raft {
+	cfg := newTestConfig(id, election, heartbeat, logdb)
+	cfg.IsWitness = true
+	r := newRaft(cfg, logdb)
+	if len(r.remotes) == 0 {
+		for _, p := range peers {
+			r.remotes[p] = &remote{next: 1}
+		}
+	}
+	if len(r.witnesses) == 0 {
+		for _, p := range witnesses {
+			r.witnesses[p] = &remote{next: 1}
+		}
+	}
+	r.hasNotAppliedConfigChange = r.testOnlyHasConfigChangeToApply
+	return r
+}
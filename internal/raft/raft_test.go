@@ -19,6 +19,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/lni/dragonboat/config"
 	pb "github.com/lni/dragonboat/raftpb"
 )
 
@@ -246,6 +247,43 @@ func TestObserverCanBePromotedToVotingMember(t *testing.T) {
 	}
 }
 
+func TestObserverPromotionKeepsProgress(t *testing.T) {
+	p := newTestObserver(1, []uint64{1}, []uint64{2}, 10, 1, NewTestLogDB())
+	p.observers[2].match = 10
+	p.observers[2].next = 11
+	p.Handle(pb.Message{
+		Type:     pb.ConfigChangeEvent,
+		Hint:     2,
+		HintHigh: uint64(pb.PromoteObserver),
+	})
+	rp, ok := p.remotes[2]
+	if !ok {
+		t.Fatalf("observer not promoted")
+	}
+	if len(p.observers) != 0 {
+		t.Errorf("observers len: %d, want 0", len(p.observers))
+	}
+	if rp.match != 10 || rp.next != 11 {
+		t.Errorf("match %d, next %d, want 10, 11", rp.match, rp.next)
+	}
+	if len(p.matched) != 2 {
+		t.Errorf("match value array len %d, want 2", len(p.matched))
+	}
+	if p.quorum() != 2 {
+		t.Errorf("quorum %d, want 2", p.quorum())
+	}
+}
+
+func TestPromotingNonObserverWillPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic not triggered")
+		}
+	}()
+	p := newTestRaft(1, []uint64{1}, 10, 1, NewTestLogDB())
+	p.promoteObserver(2)
+}
+
 func TestObserverCanActAsRegularNodeAfterPromotion(t *testing.T) {
 	p := newTestObserver(1, nil, []uint64{1}, 10, 1, NewTestLogDB())
 	if !p.isObserver() {
@@ -571,6 +609,279 @@ func TestObserverCanBeRemoved(t *testing.T) {
 	}
 }
 
+func TestWitnessWillNotStartElection(t *testing.T) {
+	p := newTestWitness(1, []uint64{2, 3}, []uint64{1}, 10, 1, NewTestLogDB())
+	if !p.isWitness() {
+		t.Errorf("not a witness")
+	}
+	for i := uint64(0); i < p.randomizedElectionTimeout*10; i++ {
+		p.tick()
+	}
+	if len(p.msgs) != 0 {
+		t.Errorf("unexpected msg found %+v", p.msgs)
+	}
+	if !p.isWitness() {
+		t.Errorf("witness changed its state")
+	}
+}
+
+func TestWitnessCanVoteInElection(t *testing.T) {
+	p := newTestWitness(1, []uint64{2, 3}, []uint64{1}, 10, 1, NewTestLogDB())
+	p.Handle(pb.Message{From: 2, To: 1, Type: pb.RequestVote, Term: 2, LogTerm: 100, LogIndex: 100})
+	if len(p.msgs) != 1 {
+		t.Fatalf("unexpected msg count %d", len(p.msgs))
+	}
+	if p.msgs[0].Type != pb.RequestVoteResp || p.msgs[0].Reject {
+		t.Errorf("witness didn't grant the vote, %+v", p.msgs[0])
+	}
+	if !p.isWitness() {
+		t.Errorf("witness changed its state")
+	}
+	if p.vote != 2 {
+		t.Errorf("vote %d, want 2", p.vote)
+	}
+}
+
+func TestWitnessIsCountedInQuorum(t *testing.T) {
+	p := newTestRaft(1, []uint64{1, 2}, 10, 1, NewTestLogDB())
+	if p.quorum() != 2 {
+		t.Errorf("quorum %d, want 2", p.quorum())
+	}
+	p.addWitness(3)
+	if p.quorum() != 2 {
+		t.Errorf("quorum %d, want 2", p.quorum())
+	}
+	p.addWitness(4)
+	if p.quorum() != 3 {
+		t.Errorf("quorum %d, want 3", p.quorum())
+	}
+	if len(p.matched) != 4 {
+		t.Errorf("match value array len %d, want 4", len(p.matched))
+	}
+	p.removeNode(4)
+	if p.quorum() != 2 {
+		t.Errorf("quorum %d, want 2", p.quorum())
+	}
+}
+
+func getTestWitnessNetwork() (*raft, *raft, *raft, *network) {
+	p1 := newTestRaft(1, []uint64{1, 2}, 10, 1, NewTestLogDB())
+	p2 := newTestRaft(2, []uint64{1, 2}, 10, 1, NewTestLogDB())
+	p3 := newTestWitness(3, []uint64{1, 2}, []uint64{3}, 10, 1, NewTestLogDB())
+	p1.addWitness(3)
+	p2.addWitness(3)
+	return p1, p2, p3, newNetwork(p1, p2, p3)
+}
+
+func TestWitnessCanHelpToElectLeader(t *testing.T) {
+	p1, _, p3, nt := getTestWitnessNetwork()
+	nt.isolate(2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	if p1.state != leader {
+		t.Errorf("failed to elect leader with witness vote")
+	}
+	if !p3.isWitness() {
+		t.Errorf("witness changed its state")
+	}
+	if p3.leaderID != 1 {
+		t.Errorf("leader id %d, want 1", p3.leaderID)
+	}
+}
+
+func TestWitnessReplicationOnlyContainsMetadata(t *testing.T) {
+	p1, _, p3, nt := getTestWitnessNetwork()
+	nt.isolate(2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	if p1.state != leader {
+		t.Fatalf("failed to start election")
+	}
+	committed := p1.log.committed
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Propose, Entries: []pb.Entry{{Cmd: []byte("test-data")}}})
+	if committed+1 != p1.log.committed {
+		t.Errorf("entry not committed with witness ack")
+	}
+	if committed+1 != p1.witnesses[3].match {
+		t.Errorf("match value not expected: %d", p1.witnesses[3].match)
+	}
+	if p3.log.lastIndex() != p1.log.lastIndex() {
+		t.Errorf("last index %d, want %d", p3.log.lastIndex(), p1.log.lastIndex())
+	}
+	ents, err := p3.log.entries(p3.log.firstIndex(), noLimit)
+	if err != nil {
+		t.Fatalf("failed to get entries %v", err)
+	}
+	for _, e := range ents {
+		if e.Type != pb.MetadataEntry || len(e.Cmd) != 0 {
+			t.Errorf("unexpected entry on witness %+v", e)
+		}
+	}
+}
+
+func TestWitnessReceivesConfigChangeEntries(t *testing.T) {
+	p1 := newTestRaft(1, []uint64{1}, 10, 1, NewTestLogDB())
+	p1.addWitness(2)
+	p1.becomeCandidate()
+	p1.becomeLeader()
+	p1.msgs = nil
+	ents := []pb.Entry{
+		{Type: pb.ApplicationEntry, Cmd: []byte("test-data")},
+		{Type: pb.ConfigChangeEntry, Cmd: []byte("cc-data")},
+	}
+	p1.appendEntries(ents)
+	p1.witnesses[2].becomeReplicate()
+	p1.sendReplicateMessage(2)
+	if len(p1.msgs) != 1 {
+		t.Fatalf("unexpected msg count %d", len(p1.msgs))
+	}
+	m := p1.msgs[0]
+	if m.Type != pb.Replicate {
+		t.Fatalf("unexpected msg type %s", m.Type)
+	}
+	cc := 0
+	for _, e := range m.Entries {
+		if e.Type == pb.ConfigChangeEntry {
+			cc++
+			if string(e.Cmd) != "cc-data" {
+				t.Errorf("config change payload removed")
+			}
+		} else if e.Type != pb.MetadataEntry || len(e.Cmd) != 0 {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	if cc != 1 {
+		t.Errorf("config change entry count %d, want 1", cc)
+	}
+	if string(ents[0].Cmd) != "test-data" {
+		t.Errorf("leader's entry modified")
+	}
+}
+
+func TestWitnessGetsWitnessSnapshot(t *testing.T) {
+	st := NewTestLogDB()
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, st)
+	r.addWitness(3)
+	r.becomeCandidate()
+	r.becomeLeader()
+	ss := pb.Snapshot{
+		Index:    100,
+		Term:     2,
+		Filepath: "test-snapshot-file",
+		Membership: pb.Membership{
+			Addresses: map[uint64]string{1: "a1", 2: "a2"},
+			Witnesses: map[uint64]string{3: "a3"},
+		},
+	}
+	if err := st.ApplySnapshot(ss); err != nil {
+		t.Errorf("apply snapshot failed %v", err)
+	}
+	msg := pb.Message{}
+	if idx := r.makeInstallSnapshotMessage(3, &msg); idx != 100 {
+		t.Errorf("unexpected index %d", idx)
+	}
+	if !msg.Snapshot.Witness || msg.Snapshot.Filepath != "" {
+		t.Errorf("not a witness snapshot")
+	}
+	if msg.Snapshot.Index != 100 || msg.Snapshot.Term != 2 ||
+		len(msg.Snapshot.Membership.Witnesses) != 1 {
+		t.Errorf("unexpected snapshot values")
+	}
+	msg = pb.Message{}
+	r.makeInstallSnapshotMessage(2, &msg)
+	if msg.Snapshot.Witness || msg.Snapshot.Filepath != "test-snapshot-file" {
+		t.Errorf("unexpectedly made a witness snapshot")
+	}
+}
+
+func TestWitnessCanBeRestored(t *testing.T) {
+	members := pb.Membership{
+		Addresses: map[uint64]string{1: "a1", 2: "a2"},
+		Witnesses: map[uint64]string{3: "a3"},
+	}
+	ss := pb.Snapshot{
+		Index:      20,
+		Term:       20,
+		Membership: members,
+		Witness:    true,
+	}
+	p := newTestWitness(3, []uint64{1, 2}, []uint64{3}, 10, 1, NewTestLogDB())
+	if ok := p.restore(ss); !ok {
+		t.Errorf("failed to restore")
+	}
+	p.restoreRemotes(ss)
+	if !p.isWitness() {
+		t.Errorf("not a witness")
+	}
+	if len(p.witnesses) != 1 || len(p.remotes) != 2 {
+		t.Errorf("unexpected membership")
+	}
+}
+
+func TestNodeCanNotBeMovedToWitnessBySnapshot(t *testing.T) {
+	members := pb.Membership{
+		Addresses: map[uint64]string{1: "a1", 2: "a2"},
+		Witnesses: map[uint64]string{3: "a3"},
+	}
+	ss := pb.Snapshot{
+		Index:      20,
+		Term:       20,
+		Membership: members,
+	}
+	p := newTestRaft(3, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("restore didn't cause panic")
+		}
+	}()
+	p.restore(ss)
+}
+
+func TestWitnessCanNotBePromotedToRegularNode(t *testing.T) {
+	p := newTestWitness(1, []uint64{2, 3}, []uint64{1}, 10, 1, NewTestLogDB())
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic not triggered")
+		}
+	}()
+	p.addNode(1)
+}
+
+func TestWitnessCanNotBeConvertedToObserver(t *testing.T) {
+	p := newTestWitness(1, []uint64{2, 3}, []uint64{1}, 10, 1, NewTestLogDB())
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic not triggered")
+		}
+	}()
+	p.addObserver(1)
+}
+
+func TestWitnessCanBeRemoved(t *testing.T) {
+	p := newTestRaft(1, []uint64{1}, 10, 1, NewTestLogDB())
+	p.addWitness(2)
+	if len(p.witnesses) != 1 {
+		t.Errorf("witness not added")
+	}
+	p.removeNode(2)
+	if len(p.witnesses) != 0 {
+		t.Errorf("witness not removed")
+	}
+}
+
+func TestLeaderTransferToWitnessIsIgnored(t *testing.T) {
+	p1, _, _, nt := getTestWitnessNetwork()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	if p1.state != leader {
+		t.Fatalf("failed to start election")
+	}
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.LeaderTransfer, Hint: 3})
+	if p1.leaderTransfering() {
+		t.Errorf("leader transfer to witness started")
+	}
+	if p1.state != leader {
+		t.Errorf("leader changed")
+	}
+}
+
 func TestFollowerTick(t *testing.T) {
 	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
 	r.becomeFollower(10, 2)
@@ -827,6 +1138,16 @@ func testZeroTermRequestVoteMessageCausePanic(t *testing.T, r *raft) {
 	r.finalizeMessageTerm(pb.Message{Type: pb.RequestVote})
 }
 
+func testZeroTermRequestPreVoteMessageCausePanic(t *testing.T, r *raft) {
+	defer func() {
+		if r := recover(); r != nil {
+			return
+		}
+		t.Errorf("panic not triggered")
+	}()
+	r.finalizeMessageTerm(pb.Message{Type: pb.RequestPreVote})
+}
+
 func testNonZeroTermOtherMessageCausePanic(t *testing.T, r *raft) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -848,6 +1169,20 @@ func TestFinalizeMessageTerm(t *testing.T) {
 	}
 }
 
+func TestFinalizeMessageTermKeepsPreVoteTerm(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.becomeFollower(10, 2)
+	testZeroTermRequestPreVoteMessageCausePanic(t, r)
+	msg := r.finalizeMessageTerm(pb.Message{Type: pb.RequestPreVote, Term: 11})
+	if msg.Term != 11 {
+		t.Errorf("term changed, %d, want 11", msg.Term)
+	}
+	msg = r.finalizeMessageTerm(pb.Message{Type: pb.RequestPreVoteResp, Term: 12})
+	if msg.Term != 12 {
+		t.Errorf("term changed, %d, want 12", msg.Term)
+	}
+}
+
 func TestSendSetMessageFromAndTerm(t *testing.T) {
 	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
 	r.becomeFollower(10, 2)
@@ -988,7 +1323,7 @@ func TestSendHeartbeatMessage(t *testing.T) {
 	hint := pb.SystemCtx{Low: 100, High: 200}
 	r.remotes[2].match = 100
 	r.log.committed = 200
-	r.sendHeartbeatMessage(2, hint, false)
+	r.sendHeartbeatMessage(2, hint, r.remotes[2].match)
 	msgs := r.msgs
 	if len(msgs) != 1 {
 		t.Fatalf("unexpected msgs list length")
@@ -997,6 +1332,9 @@ func TestSendHeartbeatMessage(t *testing.T) {
 	if m.Type != pb.Heartbeat || m.Commit != 100 || m.Hint != 100 || m.HintHigh != 200 {
 		t.Errorf("unexpected msg %+v", m)
 	}
+	if m.LogTerm != 200 {
+		t.Errorf("leader commit %d, want 200", m.LogTerm)
+	}
 }
 
 func TestQuorumValue(t *testing.T) {
@@ -1512,6 +1850,117 @@ func TestHandleCandidatePropose(t *testing.T) {
 	}
 }
 
+func TestCandidateReportsDroppedRequests(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.Handle(pb.Message{
+		Type:    pb.Propose,
+		From:    1,
+		Entries: []pb.Entry{{Key: 100, ClientID: 2, SeriesID: 3, Cmd: []byte("test-data")}},
+	})
+	r.Handle(pb.Message{Type: pb.ReadIndex, Hint: 101, HintHigh: 1002})
+	if len(r.msgs) != 0 {
+		t.Errorf("unexpectedly sent message")
+	}
+	if len(r.droppedEntries) != 1 || len(r.droppedReadIndexes) != 1 {
+		t.Fatalf("dropped requests not reported")
+	}
+	e := r.droppedEntries[0]
+	if e.Key != 100 || e.ClientID != 2 || e.SeriesID != 3 || len(e.Cmd) != 0 {
+		t.Errorf("unexpected dropped entry %v", e)
+	}
+	ctx := r.droppedReadIndexes[0]
+	if ctx.Low != 101 || ctx.High != 1002 {
+		t.Errorf("unexpected dropped ReadIndex %v", ctx)
+	}
+	if r.droppedLeaderHint != NoLeader {
+		t.Errorf("unexpected leader hint %d", r.droppedLeaderHint)
+	}
+}
+
+func TestFollowerReportsDroppedRequestsWithoutLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.becomeFollower(1, NoLeader)
+	r.Handle(pb.Message{
+		Type:    pb.Propose,
+		From:    1,
+		Entries: []pb.Entry{{Key: 100}},
+	})
+	r.Handle(pb.Message{Type: pb.ReadIndex, Hint: 101, HintHigh: 1002})
+	if len(r.msgs) != 0 {
+		t.Errorf("unexpectedly sent message")
+	}
+	if len(r.droppedEntries) != 1 || len(r.droppedReadIndexes) != 1 {
+		t.Errorf("dropped requests not reported")
+	}
+}
+
+func TestLeaderReportsDroppedRequestsBackToRemoteNode(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.readMessages()
+	// no entry committed in the current term yet
+	r.Handle(pb.Message{Type: pb.ReadIndex, From: 2, Hint: 101, HintHigh: 1002})
+	r.leaderTransferTarget = 3
+	r.Handle(pb.Message{
+		Type:    pb.Propose,
+		From:    2,
+		Entries: []pb.Entry{{Key: 100, Cmd: []byte("test-data")}},
+	})
+	msgs := r.readMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("got %d msgs, want 2", len(msgs))
+	}
+	m := msgs[0]
+	if m.Type != pb.ReadIndexDropped || m.To != 2 || m.Term != 0 ||
+		m.Hint != 101 || m.HintHigh != 1002 || m.LogIndex != 1 {
+		t.Errorf("unexpected msg %v", m)
+	}
+	m = msgs[1]
+	if m.Type != pb.ProposalDropped || m.To != 2 || m.Term != 0 ||
+		m.LogIndex != 3 || len(m.Entries) != 1 ||
+		m.Entries[0].Key != 100 || len(m.Entries[0].Cmd) != 0 {
+		t.Errorf("unexpected msg %v", m)
+	}
+	if len(r.droppedEntries) != 0 || len(r.droppedReadIndexes) != 0 {
+		t.Errorf("remote requests reported locally")
+	}
+}
+
+func TestDroppedRequestMessagesAreReported(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.becomeFollower(2, 2)
+	r.Handle(pb.Message{
+		Type:     pb.ProposalDropped,
+		From:     2,
+		LogIndex: 3,
+		Entries:  []pb.Entry{{Key: 100}},
+	})
+	r.Handle(pb.Message{
+		Type:     pb.ReadIndexDropped,
+		From:     2,
+		LogIndex: 3,
+		Hint:     101,
+		HintHigh: 1002,
+	})
+	if len(r.droppedEntries) != 1 || r.droppedEntries[0].Key != 100 {
+		t.Errorf("dropped proposal not reported")
+	}
+	if len(r.droppedReadIndexes) != 1 ||
+		r.droppedReadIndexes[0] != (pb.SystemCtx{Low: 101, High: 1002}) {
+		t.Errorf("dropped ReadIndex not reported")
+	}
+	if r.droppedLeaderHint != 3 {
+		t.Errorf("leader hint %d, want 3", r.droppedLeaderHint)
+	}
+	r.clearDroppedRequests()
+	if len(r.droppedEntries) != 0 || len(r.droppedReadIndexes) != 0 ||
+		r.droppedLeaderHint != NoLeader {
+		t.Errorf("dropped requests not cleared")
+	}
+}
+
 func TestCandidateBecomeFollowerOnRecivingLeaderMessage(t *testing.T) {
 	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
 	tests := []struct {
@@ -1788,6 +2237,28 @@ func TestHandleFollowerTimeoutNow(t *testing.T) {
 	}
 }
 
+func TestDrainingFollowerRejectsTimeoutNow(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.becomeFollower(0, 2)
+	r.setDraining(true)
+	r.handleFollowerTimeoutNow(pb.Message{Type: pb.TimeoutNow, From: 2})
+	if r.state != follower {
+		t.Errorf("draining node campaigned")
+	}
+	msgs := r.readMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("unexpected msgs list length %d", len(msgs))
+	}
+	if msgs[0].Type != pb.HeartbeatResp || msgs[0].To != 2 || !msgs[0].Reject {
+		t.Errorf("unexpected msg %+v", msgs[0])
+	}
+	r.setDraining(false)
+	r.handleFollowerTimeoutNow(pb.Message{Type: pb.TimeoutNow, From: 2})
+	if r.state != candidate {
+		t.Errorf("not become candidate")
+	}
+}
+
 func TestHandleFollowerLeaderTransfer(t *testing.T) {
 	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
 	r.becomeFollower(0, 2)
@@ -2010,6 +2481,325 @@ func TestHandleLeaderTransfer(t *testing.T) {
 	}
 }
 
+func TestLeadershipIsTransferredToPreferredLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.setPreferredLeader(2)
+	rp := r.remotes[2]
+	for i := uint64(0); i < r.electionTimeout*2-1; i++ {
+		rp.setLastActiveTick(r.tickCount)
+		rp.match = r.log.lastIndex()
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership transferred too early")
+		}
+	}
+	rp.setLastActiveTick(r.tickCount)
+	r.tick()
+	if !r.leaderTransfering() || r.leaderTransferTarget != 2 {
+		t.Fatalf("leadership not transferred")
+	}
+	found := false
+	for _, m := range r.readMessages() {
+		if m.Type == pb.TimeoutNow && m.To == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("TimeoutNow not sent")
+	}
+}
+
+func TestDrainingLeaderDoesNotTransferToPreferredLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.setPreferredLeader(2)
+	r.setDraining(true)
+	rp := r.remotes[2]
+	for i := uint64(0); i < r.electionTimeout*10; i++ {
+		rp.setLastActiveTick(r.tickCount)
+		rp.match = r.log.lastIndex()
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership unexpectedly transferred")
+		}
+	}
+}
+
+func TestLeaderStopsTransferringToDrainingPreferredLeader(t *testing.T) {
+	nt := newNetwork(nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	lead := nt.peers[1].(*raft)
+	if lead.leaderID != 1 {
+		t.Fatalf("after election leader is %x, want 1", lead.leaderID)
+	}
+	nt.peers[2].(*raft).setDraining(true)
+	lead.setPreferredLeader(2)
+	// the leader is not aware that node 2 is draining yet
+	nt.send(pb.Message{From: 2, To: 1, Hint: 2, Type: pb.LeaderTransfer})
+	if lead.state != leader || lead.leaderTransfering() {
+		t.Fatalf("leader transfer not aborted")
+	}
+	for i := uint64(0); i < lead.electionTimeout*10; i++ {
+		lead.tick()
+		msgs := lead.readMessages()
+		for _, m := range msgs {
+			if m.Type == pb.TimeoutNow {
+				t.Fatalf("leader transfer retried")
+			}
+		}
+		nt.send(msgs...)
+	}
+	if lead.state != leader || lead.leaderTransfering() {
+		t.Errorf("leadership unexpectedly transferred")
+	}
+	nt.peers[2].(*raft).setDraining(false)
+	for i := uint64(0); i < lead.electionTimeout*10; i++ {
+		lead.tick()
+		nt.send(lead.readMessages()...)
+	}
+	if lead.state != follower || lead.leaderID != 2 {
+		t.Errorf("leadership not transferred to the preferred leader")
+	}
+}
+
+func TestLeadershipIsNotTransferredToLaggingPreferredLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.remotes[3].match = r.log.lastIndex()
+	if !r.tryCommit() {
+		t.Fatalf("failed to commit")
+	}
+	r.setPreferredLeader(2)
+	rp := r.remotes[2]
+	for i := uint64(0); i < r.electionTimeout*10; i++ {
+		rp.setLastActiveTick(r.tickCount)
+		// the preferred leader catches up every other tick
+		if i%2 == 0 {
+			rp.match = r.log.committed
+		} else {
+			rp.match = 0
+		}
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership unexpectedly transferred")
+		}
+	}
+}
+
+func TestLeadershipIsNotTransferredToInactivePreferredLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.setPreferredLeader(2)
+	rp := r.remotes[2]
+	rp.match = r.log.lastIndex()
+	for i := uint64(0); i < r.electionTimeout*10; i++ {
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership unexpectedly transferred")
+		}
+	}
+}
+
+func TestLeadershipIsNotTransferredToNonVotingPreferredLeader(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewTestLogDB())
+	r.addObserver(3)
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.setPreferredLeader(3)
+	rp := r.observers[3]
+	for i := uint64(0); i < r.electionTimeout*10; i++ {
+		rp.setLastActiveTick(r.tickCount)
+		rp.match = r.log.lastIndex()
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership unexpectedly transferred")
+		}
+	}
+}
+
+func TestLeadershipIsTransferredToPreferredRegion(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3, 4}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	// the preferred leader is inactive, the region is used instead
+	r.setPreference(2, []uint64{4, 3})
+	for i := uint64(0); i < r.electionTimeout*2-1; i++ {
+		r.remotes[3].setLastActiveTick(r.tickCount)
+		r.remotes[4].setLastActiveTick(r.tickCount)
+		r.remotes[3].match = 0
+		r.remotes[4].match = r.log.lastIndex()
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership transferred too early")
+		}
+	}
+	r.remotes[3].setLastActiveTick(r.tickCount)
+	r.remotes[4].setLastActiveTick(r.tickCount)
+	r.tick()
+	if !r.leaderTransfering() || r.leaderTransferTarget != 4 {
+		t.Fatalf("leadership not transferred to the preferred region")
+	}
+}
+
+func TestLeaderInPreferredRegionKeepsLeadership(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.setPreferredRegion([]uint64{1, 2})
+	rp := r.remotes[2]
+	for i := uint64(0); i < r.electionTimeout*10; i++ {
+		rp.setLastActiveTick(r.tickCount)
+		rp.match = r.log.lastIndex()
+		r.tick()
+		if r.leaderTransfering() {
+			t.Fatalf("leadership unexpectedly transferred")
+		}
+	}
+}
+
+func TestPreferredRegionIsNormalized(t *testing.T) {
+	region := normalizePreferredRegion([]uint64{3, 0, 1, 3, 2, 1})
+	if !reflect.DeepEqual(region, []uint64{1, 2, 3}) {
+		t.Errorf("unexpected region %v", region)
+	}
+	if region := normalizePreferredRegion(nil); len(region) != 0 {
+		t.Errorf("unexpected region %v", region)
+	}
+}
+
+func TestPreferenceMessageCarriesPreferredRegion(t *testing.T) {
+	m := newPreferenceMessage(2, []uint64{3, 4})
+	if m.Type != pb.PreferredLeader || m.Hint != 2 {
+		t.Errorf("unexpected message %v", m)
+	}
+	if region := getPreferredRegion(m); !reflect.DeepEqual(region,
+		[]uint64{3, 4}) {
+		t.Errorf("unexpected region %v", region)
+	}
+}
+
+func TestFollowerForwardsPreferenceToLeader(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	r3 := nt.peers[3].(*raft)
+	r3.Handle(newPreferenceMessage(2, []uint64{2, 3}))
+	nt.send(r3.readMessages()...)
+	for id := uint64(1); id <= 3; id++ {
+		r := nt.peers[id].(*raft)
+		if r.preferredLeader != 2 ||
+			!reflect.DeepEqual(r.preferredRegion, []uint64{2, 3}) {
+			t.Errorf("node %d, preference %d %v not updated",
+				id, r.preferredLeader, r.preferredRegion)
+		}
+	}
+}
+
+func TestNodesConvergeOnLeadersPreference(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	lead := nt.peers[1].(*raft)
+	lead.Handle(newPreferenceMessage(1, nil))
+	nt.send(lead.readMessages()...)
+	// node 3 restarted with a stale preference
+	r3 := nt.peers[3].(*raft)
+	r3.setPreference(2, []uint64{2})
+	for i := uint64(0); i < lead.electionTimeout; i++ {
+		lead.tick()
+		nt.send(lead.readMessages()...)
+	}
+	if r3.preferredLeader != 1 || len(r3.preferredRegion) != 0 {
+		t.Errorf("preference %d %v not converged",
+			r3.preferredLeader, r3.preferredRegion)
+	}
+}
+
+func TestTimeoutNowCarriesPreference(t *testing.T) {
+	lead := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	lead.becomeCandidate()
+	lead.becomeLeader()
+	lead.setPreference(2, []uint64{2, 3})
+	lead.readMessages()
+	lead.sendTimeoutNowMessage(2)
+	msgs := lead.readMessages()
+	if len(msgs) != 1 || msgs[0].Type != pb.TimeoutNow {
+		t.Fatalf("unexpected messages %v", msgs)
+	}
+	r := newTestRaft(2, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
+	r.becomeFollower(lead.term, 1)
+	r.Handle(msgs[0])
+	if r.preferredLeader != 2 ||
+		!reflect.DeepEqual(r.preferredRegion, []uint64{2, 3}) {
+		t.Errorf("preference %d %v not taken over",
+			r.preferredLeader, r.preferredRegion)
+	}
+}
+
+func TestLeadershipDoesNotFlapWithDifferentPreferences(t *testing.T) {
+	// each node is configured to prefer a different node, without converging on
+	// the leader's preference, the leadership would keep moving between them
+	cfgFunc := func(c *config.Config) {
+		c.PreferredLeader = c.NodeID%3 + 1
+	}
+	nt := newNetworkWithConfig(cfgFunc, nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	getLeader := func() *raft {
+		for id := uint64(1); id <= 3; id++ {
+			if r := nt.peers[id].(*raft); r.state == leader {
+				return r
+			}
+		}
+		t.Fatalf("no leader")
+		return nil
+	}
+	changes := 0
+	prev := getLeader().nodeID
+	for i := 0; i < 200; i++ {
+		lead := getLeader()
+		lead.tick()
+		nt.send(lead.readMessages()...)
+		if nid := getLeader().nodeID; nid != prev {
+			changes++
+			prev = nid
+		}
+	}
+	if changes != 1 || prev != 2 {
+		t.Errorf("leader %d, %d leadership changes, want 2, 1", prev, changes)
+	}
+	for id := uint64(1); id <= 3; id++ {
+		if r := nt.peers[id].(*raft); r.preferredLeader != 2 {
+			t.Errorf("node %d prefers %d, want 2", id, r.preferredLeader)
+		}
+	}
+}
+
+func TestLeaderTransferCandidateIsMostUpToDateActiveVoter(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3, 4}, 5, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.addObserver(5)
+	for i := uint64(0); i < r.electionTimeout; i++ {
+		r.tick()
+	}
+	if v := r.leaderTransferCandidate(); v != NoNode {
+		t.Errorf("candidate %d, want %d", v, NoNode)
+	}
+	r.remotes[2].setLastActiveTick(r.tickCount)
+	r.remotes[3].setLastActiveTick(r.tickCount)
+	r.observers[5].setLastActiveTick(r.tickCount)
+	r.remotes[2].match = 1
+	r.remotes[3].match = 2
+	r.remotes[4].match = 3
+	r.observers[5].match = 4
+	if v := r.leaderTransferCandidate(); v != 3 {
+		t.Errorf("candidate %d, want 3", v)
+	}
+}
+
 func TestHandleLeaderHeartbeatResp(t *testing.T) {
 	r := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
 	r.becomeCandidate()
@@ -2140,6 +2930,189 @@ func TestHandleLeaderReadIndex(t *testing.T) {
 	}
 }
 
+func leaseReadConfig(c *config.Config) {
+	c.CheckQuorum = true
+	c.LeaseRead = true
+}
+
+func getLeaseReadTestNetwork(t *testing.T) (*raft, *network) {
+	nt := newNetworkWithConfig(leaseReadConfig, nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	lead := nt.peers[1].(*raft)
+	if lead.state != leader {
+		t.Fatalf("failed to elect leader")
+	}
+	// responses to the heartbeat messages renew the lease
+	lead.tick()
+	lead.readMessages()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	return lead, nt
+}
+
+func TestLeaseReadRequiresCheckQuorum(t *testing.T) {
+	cfg := newTestConfig(1, 10, 1, NewTestLogDB())
+	cfg.LeaseRead = true
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("config without CheckQuorum unexpectedly accepted")
+	}
+	cfg.CheckQuorum = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	cfg.ClockDriftRTT = 8
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("large ClockDriftRTT unexpectedly accepted")
+	}
+}
+
+func TestLeaseReadIsServedLocallyByLeader(t *testing.T) {
+	lead, nt := getLeaseReadTestNetwork(t)
+	if !lead.hasValidLease() {
+		t.Fatalf("no valid lease")
+	}
+	nt.isolate(1)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.ReadIndex, Hint: 101})
+	if len(lead.readyToRead) != 1 {
+		t.Fatalf("ready to read len %d, want 1", len(lead.readyToRead))
+	}
+	if lead.readyToRead[0].Index != lead.log.committed ||
+		lead.readyToRead[0].SystemCtx.Low != 101 {
+		t.Errorf("unexpected ready to read %+v", lead.readyToRead[0])
+	}
+	if lead.readIndex.hasPendingRequest() {
+		t.Errorf("unexpected pending ReadIndex request")
+	}
+}
+
+func TestLeaseReadFromFollower(t *testing.T) {
+	lead, nt := getLeaseReadTestNetwork(t)
+	// only the leader and node 2 can talk to each other, the lease is still
+	// valid as it was renewed before node 3 got partitioned
+	nt.isolate(3)
+	nt.send(pb.Message{From: 2, To: 2, Type: pb.ReadIndex, Hint: 101})
+	p2 := nt.peers[2].(*raft)
+	if len(p2.readyToRead) != 1 {
+		t.Fatalf("ready to read len %d, want 1", len(p2.readyToRead))
+	}
+	if p2.readyToRead[0].Index != lead.log.committed {
+		t.Errorf("unexpected ready to read index %d", p2.readyToRead[0].Index)
+	}
+	if lead.readIndex.hasPendingRequest() {
+		t.Errorf("unexpected pending ReadIndex request")
+	}
+}
+
+func TestLeaseReadFallsBackToReadIndexWhenLeaseExpired(t *testing.T) {
+	lead, nt := getLeaseReadTestNetwork(t)
+	nt.isolate(1)
+	for i := uint64(0); i < lead.leaseTimeout; i++ {
+		lead.tick()
+	}
+	lead.readMessages()
+	if lead.state != leader {
+		t.Fatalf("leader stepped down")
+	}
+	if lead.hasValidLease() {
+		t.Fatalf("lease not expired")
+	}
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.ReadIndex, Hint: 101})
+	if len(lead.readyToRead) != 0 {
+		t.Fatalf("read served with expired lease")
+	}
+	if !lead.readIndex.hasPendingRequest() {
+		t.Fatalf("ReadIndex request not pending")
+	}
+	nt.recover()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	if len(lead.readyToRead) != 1 {
+		t.Fatalf("ready to read len %d, want 1", len(lead.readyToRead))
+	}
+	if !lead.hasValidLease() {
+		t.Errorf("lease not renewed")
+	}
+}
+
+func TestLeaseStartsWhenHeartbeatIsSent(t *testing.T) {
+	lead, _ := getLeaseReadTestNetwork(t)
+	sentTick := lead.tickCount
+	lead.Handle(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	msgs := lead.readMessages()
+	// responses are delayed until the lease is about to expire
+	for i := uint64(0); i < lead.leaseTimeout-1; i++ {
+		lead.tick()
+	}
+	lead.readMessages()
+	count := 0
+	for _, m := range msgs {
+		if m.Type != pb.Heartbeat {
+			continue
+		}
+		count++
+		if m.LogIndex != sentTick {
+			t.Errorf("sent tick %d, want %d", m.LogIndex, sentTick)
+		}
+		lead.Handle(pb.Message{
+			From:     m.To,
+			To:       1,
+			Type:     pb.HeartbeatResp,
+			Term:     lead.term,
+			LogIndex: m.LogIndex,
+		})
+	}
+	if count != 2 {
+		t.Fatalf("got %d heartbeat messages, want 2", count)
+	}
+	if !lead.hasValidLease() {
+		t.Fatalf("no valid lease")
+	}
+	lead.tick()
+	if lead.hasValidLease() {
+		t.Errorf("lease extended by delayed responses")
+	}
+}
+
+func TestLeaseReadNotUsedWhenDisabled(t *testing.T) {
+	cfg := func(c *config.Config) {
+		c.CheckQuorum = true
+	}
+	nt := newNetworkWithConfig(cfg, nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.Election})
+	lead := nt.peers[1].(*raft)
+	lead.tick()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	if lead.hasValidLease() {
+		t.Fatalf("unexpected valid lease")
+	}
+	nt.isolate(1)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.ReadIndex, Hint: 101})
+	if len(lead.readyToRead) != 0 {
+		t.Errorf("read unexpectedly served")
+	}
+}
+
+func TestLeaseIsRevokedByLeaderTransfer(t *testing.T) {
+	lead, nt := getLeaseReadTestNetwork(t)
+	nt.isolate(3)
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.LeaderTransfer, Hint: 3})
+	if !lead.leaderTransfering() {
+		t.Fatalf("leader transfer not started")
+	}
+	for i := uint64(0); i < lead.electionTimeout; i++ {
+		lead.tick()
+	}
+	if lead.leaderTransfering() {
+		t.Fatalf("leader transfer not aborted")
+	}
+	lead.readMessages()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.LeaderHeartbeat})
+	if lead.state != leader {
+		t.Fatalf("leader stepped down")
+	}
+	if lead.hasValidLease() {
+		t.Errorf("lease not revoked")
+	}
+}
+
 func testNodeUpdatesItsRateLimiterHeartbeat(isLeader bool, t *testing.T) {
 	r := newRateLimitedTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewTestLogDB())
 	if isLeader {
@@ -2243,3 +3216,155 @@ func TestRateLimitMessageIsSentByNonLeader(t *testing.T) {
 	testRateLimitMessageIsSentByNonLeader(2, true, t)
 	testRateLimitMessageIsSentByNonLeader(NoLeader, false, t)
 }
+
+func getTestJointConfigChanges() []pb.ConfigChange {
+	return []pb.ConfigChange{
+		{Type: pb.AddNode, NodeID: 4},
+		{Type: pb.AddNode, NodeID: 5},
+		{Type: pb.RemoveNode, NodeID: 2},
+		{Type: pb.RemoveNode, NodeID: 3},
+	}
+}
+
+func TestEnterJointConfig(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.enterJoint(getTestJointConfigChanges())
+	if !r.isJointConfig() {
+		t.Fatalf("not in joint config")
+	}
+	if len(r.remotes) != 5 {
+		t.Errorf("remotes len %d, want 5", len(r.remotes))
+	}
+	if len(r.incoming) != 2 || len(r.outgoing) != 2 {
+		t.Errorf("unexpected incoming %v, outgoing %v", r.incoming, r.outgoing)
+	}
+	if len(r.matched) != 5 {
+		t.Errorf("match value array len %d, want 5", len(r.matched))
+	}
+}
+
+func TestJointConfigCommitRequiresBothQuorums(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.enterJoint(getTestJointConfigChanges())
+	if !r.hasPendingConfigChange() {
+		t.Fatalf("leave joint not proposed")
+	}
+	lastIndex := r.log.lastIndex()
+	r.remotes[2].tryUpdate(lastIndex)
+	r.remotes[3].tryUpdate(lastIndex)
+	if r.tryCommit() {
+		t.Errorf("committed without quorum from the new config")
+	}
+	r.remotes[4].tryUpdate(lastIndex)
+	if !r.tryCommit() {
+		t.Errorf("failed to commit")
+	}
+	if r.log.committed != lastIndex {
+		t.Errorf("committed %d, want %d", r.log.committed, lastIndex)
+	}
+}
+
+func TestJointConfigElectionRequiresBothMajorities(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.enterJoint(getTestJointConfigChanges())
+	r.votes = map[uint64]bool{1: true, 2: true, 3: true}
+	if r.electionWon() {
+		t.Errorf("won election without majority of the new config")
+	}
+	r.votes[4] = true
+	if !r.electionWon() {
+		t.Errorf("failed to win the election")
+	}
+	r.votes = map[uint64]bool{1: true, 4: false, 5: false}
+	if !r.electionLost() {
+		t.Errorf("election not lost")
+	}
+}
+
+func TestLeaveJointConfigRemovesOutgoingMembers(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.enterJoint(getTestJointConfigChanges())
+	r.leaveJoint()
+	if r.isJointConfig() {
+		t.Errorf("still in joint config")
+	}
+	if len(r.remotes) != 3 {
+		t.Errorf("remotes len %d, want 3", len(r.remotes))
+	}
+	for _, nid := range []uint64{1, 4, 5} {
+		if _, ok := r.remotes[nid]; !ok {
+			t.Errorf("node %d not found", nid)
+		}
+	}
+	if r.quorum() != 2 {
+		t.Errorf("quorum %d, want 2", r.quorum())
+	}
+}
+
+func TestObserverCanBePromotedInJointConfig(t *testing.T) {
+	r := newTestObserver(1, []uint64{1}, []uint64{2}, 10, 1, NewTestLogDB())
+	r.observers[2].match = 10
+	r.enterJoint([]pb.ConfigChange{{Type: pb.AddNode, NodeID: 2}})
+	rp, ok := r.remotes[2]
+	if !ok {
+		t.Fatalf("observer not promoted")
+	}
+	if rp.match != 10 {
+		t.Errorf("match %d, want 10", rp.match)
+	}
+	if _, ok := r.incoming[2]; !ok {
+		t.Errorf("promoted observer not in incoming set")
+	}
+}
+
+func TestConfigChangeIsDroppedInJointConfig(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.enterJoint(getTestJointConfigChanges())
+	r.clearPendingConfigChange()
+	cc := pb.ConfigChange{Type: pb.AddNode, NodeID: 6}
+	data, err := cc.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal %v", err)
+	}
+	r.Handle(pb.Message{
+		From:    1,
+		To:      1,
+		Type:    pb.Propose,
+		Entries: []pb.Entry{{Type: pb.ConfigChangeEntry, Cmd: data}},
+	})
+	ents, err := r.log.entries(r.log.lastIndex(), noLimit)
+	if err != nil {
+		t.Fatalf("failed to get entries %v", err)
+	}
+	if ents[0].Type != pb.ApplicationEntry {
+		t.Errorf("config change not dropped")
+	}
+}
+
+func TestNewLeaderProposesLeaveJoint(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewTestLogDB())
+	r.enterJoint(getTestJointConfigChanges())
+	r.becomeCandidate()
+	r.becomeLeader()
+	ents, err := r.log.entries(r.log.lastIndex(), noLimit)
+	if err != nil {
+		t.Fatalf("failed to get entries %v", err)
+	}
+	if ents[0].Type != pb.ConfigChangeEntry {
+		t.Fatalf("leave joint not proposed")
+	}
+	var cc pb.ConfigChange
+	if err := cc.Unmarshal(ents[0].Cmd); err != nil {
+		t.Fatalf("failed to unmarshal %v", err)
+	}
+	if cc.Type != pb.LeaveJoint {
+		t.Errorf("type %s, want %s", cc.Type, pb.LeaveJoint)
+	}
+	if !r.hasPendingConfigChange() {
+		t.Errorf("pending config change flag not set")
+	}
+}
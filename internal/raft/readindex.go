@@ -26,8 +26,8 @@ type readStatus struct {
 }
 
 // readIndex is the struct that implements the ReadIndex protocol described in
-// section 6.4 (with the idea in section 6.4.1 excluded) of Diego Ongaro's PhD
-// thesis.
+// section 6.4 of Diego Ongaro's PhD thesis. the lease based approach described
+// in section 6.4.1 is implemented by the leader in raft.go.
 type readIndex struct {
 	pending map[raftpb.SystemCtx]*readStatus
 	queue   []raftpb.SystemCtx
@@ -76,12 +76,21 @@ func (r *readIndex) peepCtx() raftpb.SystemCtx {
 
 func (r *readIndex) confirm(ctx raftpb.SystemCtx,
 	from uint64, quorum int) []*readStatus {
+	return r.confirmWith(ctx, from, func(confirmed map[uint64]struct{}) bool {
+		return len(confirmed)+1 >= quorum
+	})
+}
+
+// confirmWith is similar to confirm, the specified hasQuorum function is used
+// to check whether the leadership has been confirmed by the confirmed nodes.
+func (r *readIndex) confirmWith(ctx raftpb.SystemCtx, from uint64,
+	hasQuorum func(confirmed map[uint64]struct{}) bool) []*readStatus {
 	p, ok := r.pending[ctx]
 	if !ok {
 		return nil
 	}
 	p.confirmed[from] = struct{}{}
-	if len(p.confirmed)+1 < quorum {
+	if !hasQuorum(p.confirmed) {
 		return nil
 	}
 	done := 0
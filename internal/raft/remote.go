@@ -66,6 +66,13 @@ type remote struct {
 	snapshotIndex uint64
 	state         remoteStateType
 	active        bool
+	// tick count of the most recent response received from the remote
+	lastActiveTick uint64
+	// tick count at which the leader sent the most recent message acknowledged
+	// by the remote
+	leaseTick uint64
+	// whether the remote reported that it is being drained
+	draining bool
 }
 
 func (r *remote) String() string {
@@ -196,3 +203,21 @@ func (r *remote) setActive() {
 func (r *remote) setNotActive() {
 	r.active = false
 }
+
+func (r *remote) setLastActiveTick(tick uint64) {
+	r.lastActiveTick = tick
+}
+
+func (r *remote) getLastActiveTick() uint64 {
+	return r.lastActiveTick
+}
+
+func (r *remote) setLeaseTick(tick uint64) {
+	if tick > r.leaseTick {
+		r.leaseTick = tick
+	}
+}
+
+func (r *remote) getLeaseTick() uint64 {
+	return r.leaseTick
+}
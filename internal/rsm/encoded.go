@@ -0,0 +1,82 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsm
+
+import (
+	"github.com/lni/dragonboat/internal/utils/compression/snappy"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+// The payload of an EncodedEntry starts with a single header byte, the high
+// 4 bits is the encoding version and the low 4 bits is the compression type.
+// The encoded user payload follows the header byte.
+const (
+	encodingVersion0 uint8 = 0
+	versionMask      uint8 = 0xF0
+	compressionMask  uint8 = 0x0F
+)
+
+// GetEncodedPayload returns the payload of an EncodedEntry that carries the
+// specified cmd compressed using the specified compression type.
+func GetEncodedPayload(ct pb.CompressionType, cmd []byte) []byte {
+	switch ct {
+	case pb.NoCompression:
+		dst := make([]byte, len(cmd)+1)
+		dst[0] = getEncodedHeader(ct)
+		copy(dst[1:], cmd)
+		return dst
+	case pb.Snappy:
+		dst := make([]byte, snappy.MaxEncodedLen(len(cmd))+1)
+		dst[0] = getEncodedHeader(ct)
+		return dst[:len(snappy.Encode(dst[1:], cmd))+1]
+	default:
+		plog.Panicf("unknown compression type %d", ct)
+	}
+	panic("not suppose to reach here")
+}
+
+// GetEntryPayload returns the user payload of the specified entry. The
+// payload of an EncodedEntry is decoded.
+func GetEntryPayload(e pb.Entry) []byte {
+	if e.Type != pb.EncodedEntry {
+		return e.Cmd
+	}
+	if len(e.Cmd) == 0 {
+		plog.Panicf("empty payload in encoded entry %d", e.Index)
+	}
+	header := e.Cmd[0]
+	if (header&versionMask)>>4 != encodingVersion0 {
+		plog.Panicf("unknown encoding version %d, entry %d",
+			(header&versionMask)>>4, e.Index)
+	}
+	ct := pb.CompressionType(header & compressionMask)
+	switch ct {
+	case pb.NoCompression:
+		return e.Cmd[1:]
+	case pb.Snappy:
+		cmd, err := snappy.Decode(nil, e.Cmd[1:])
+		if err != nil {
+			plog.Panicf("failed to decode entry %d, %v", e.Index, err)
+		}
+		return cmd
+	default:
+		plog.Panicf("unknown compression type %d, entry %d", ct, e.Index)
+	}
+	panic("not suppose to reach here")
+}
+
+func getEncodedHeader(ct pb.CompressionType) uint8 {
+	return encodingVersion0<<4 | uint8(ct)&compressionMask
+}
@@ -0,0 +1,58 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsm
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func TestEncodedPayloadCanBeDecoded(t *testing.T) {
+	cmd := bytes.Repeat([]byte("test-data"), 128)
+	for _, ct := range []pb.CompressionType{pb.NoCompression, pb.Snappy} {
+		e := pb.Entry{Type: pb.EncodedEntry, Cmd: GetEncodedPayload(ct, cmd)}
+		if pb.CompressionType(e.Cmd[0]&compressionMask) != ct {
+			t.Errorf("compression type not recorded")
+		}
+		if ct == pb.Snappy && len(e.Cmd) >= len(cmd) {
+			t.Errorf("cmd not compressed")
+		}
+		if !bytes.Equal(GetEntryPayload(e), cmd) {
+			t.Errorf("cmd changed, compression type %s", ct)
+		}
+	}
+}
+
+func TestPayloadOfRegularEntryIsNotDecoded(t *testing.T) {
+	cmd := []byte("test-data")
+	e := pb.Entry{Type: pb.ApplicationEntry, Cmd: cmd}
+	if !bytes.Equal(GetEntryPayload(e), cmd) {
+		t.Errorf("cmd changed")
+	}
+}
+
+func TestCorruptedEncodedPayloadCausesPanic(t *testing.T) {
+	cmd := bytes.Repeat([]byte("test-data"), 128)
+	e := pb.Entry{Type: pb.EncodedEntry, Cmd: GetEncodedPayload(pb.Snappy, cmd)}
+	e.Cmd = e.Cmd[:len(e.Cmd)/2]
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("panic not triggered")
+		}
+	}()
+	GetEntryPayload(e)
+}
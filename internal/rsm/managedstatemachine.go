@@ -141,24 +141,28 @@ func (o *OffloadedStatus) SetOffloaded(from From) {
 
 // IManagedStateMachine is the interface used to manage data store.
 type IManagedStateMachine interface {
+	Open() (uint64, error)
 	GetSessionHash() uint64
 	UpdateRespondedTo(*Session, uint64)
 	UnregisterClientID(clientID uint64) uint64
 	RegisterClientID(clientID uint64) uint64
 	ClientRegistered(clientID uint64) (*Session, bool)
-	UpdateRequired(*Session, uint64) (uint64, bool, bool)
-	Update(*Session, uint64, uint64, uint64, []byte) uint64
+	UpdateRequired(*Session, uint64) (sm.Result, bool, bool)
+	Update(*Session, uint64, uint64, uint64, []byte) sm.Result
 	BatchedUpdate([]sm.Entry) []sm.Entry
 	Lookup([]byte) ([]byte, error)
 	GetHash() uint64
 	PrepareSnapshot() (interface{}, error)
 	SaveSessions(w io.Writer) (uint64, error)
+	LoadSessions(r io.Reader) error
+	Sync() error
 	SaveSnapshot(interface{},
 		*SnapshotWriter, []byte, sm.ISnapshotFileCollection) (uint64, error)
 	RecoverFromSnapshot(string, []sm.SnapshotFile) error
 	Offloaded(From)
 	Loaded(From)
 	ConcurrentSnapshot() bool
+	OnDiskStateMachine() bool
 }
 
 // ManagedStateMachineFactory is the factory function type for creating an
@@ -241,15 +245,15 @@ func (ds *SessionManager) ClientRegistered(clientID uint64) (*Session, bool) {
 // UpdateRequired return a tuple of request result, responded before,
 // update required.
 func (ds *SessionManager) UpdateRequired(session *Session,
-	seriesID uint64) (uint64, bool, bool) {
+	seriesID uint64) (sm.Result, bool, bool) {
 	if session.hasResponded(RaftSeriesID(seriesID)) {
-		return 0, true, false
+		return sm.Result{}, true, false
 	}
 	v, ok := session.getResponse(RaftSeriesID(seriesID))
 	if ok {
 		return v, false, false
 	}
-	return 0, false, true
+	return sm.Result{}, false, true
 }
 
 // MustHaveClientSeries checks whether the session manager contains a client
@@ -264,7 +268,7 @@ func (ds *SessionManager) MustHaveClientSeries(session *Session,
 
 // AddResponse adds the specified result to the session.
 func (ds *SessionManager) AddResponse(session *Session,
-	seriesID uint64, result uint64) {
+	seriesID uint64, result sm.Result) {
 	session.addResponse(RaftSeriesID(seriesID), result)
 }
 
@@ -327,9 +331,26 @@ func (ds *NativeStateMachine) ConcurrentSnapshot() bool {
 	return ds.sm.ConcurrentSnapshot()
 }
 
+// OnDiskStateMachine returns a boolean flag indicating whether the managed
+// state machine instance is an on disk state machine.
+func (ds *NativeStateMachine) OnDiskStateMachine() bool {
+	return ds.sm.OnDiskStateMachine()
+}
+
+// Open opens on disk state machine.
+func (ds *NativeStateMachine) Open() (uint64, error) {
+	return ds.sm.Open(ds.done)
+}
+
+// Sync synchronizes the in-core state of the managed state machine with that
+// on disk.
+func (ds *NativeStateMachine) Sync() error {
+	return ds.sm.Sync()
+}
+
 // Update updates the data store.
 func (ds *NativeStateMachine) Update(session *Session,
-	seriesID uint64, index uint64, term uint64, data []byte) uint64 {
+	seriesID uint64, index uint64, term uint64, data []byte) sm.Result {
 	if session != nil {
 		_, ok := session.getResponse(RaftSeriesID(seriesID))
 		if ok {
@@ -411,7 +432,7 @@ func (ds *NativeStateMachine) SaveSnapshot(
 	if err = writer.SaveHeader(smsz, sz); err != nil {
 		return 0, err
 	}
-	return sz + smsz + SnapshotHeaderSize, nil
+	return writer.GetPayloadSize(sz+smsz) + SnapshotHeaderSize, nil
 }
 
 // RecoverFromSnapshot recovers the state of the data store from the snapshot
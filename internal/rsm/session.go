@@ -20,6 +20,7 @@ import (
 	"io"
 
 	"github.com/lni/dragonboat/internal/utils/cache/biogo/store/llrb"
+	sm "github.com/lni/dragonboat/statemachine"
 )
 
 // RaftClientID is the type used as client id in sessions.
@@ -45,6 +46,14 @@ func (a *RaftClientID) Compare(b llrb.Comparable) int {
 
 // Session is the session object maintained on the raft side.
 type Session struct {
+	ClientID      RaftClientID
+	RespondedUpTo RaftSeriesID
+	History       map[RaftSeriesID]sm.Result
+}
+
+// legacySession is the session format used by snapshots created before the
+// Data field of sm.Result was recorded, only the result values were cached.
+type legacySession struct {
 	ClientID      RaftClientID
 	RespondedUpTo RaftSeriesID
 	History       map[RaftSeriesID]uint64
@@ -53,16 +62,16 @@ type Session struct {
 func newSession(id RaftClientID) *Session {
 	return &Session{
 		ClientID: id,
-		History:  make(map[RaftSeriesID]uint64),
+		History:  make(map[RaftSeriesID]sm.Result),
 	}
 }
 
-func (s *Session) getResponse(id RaftSeriesID) (uint64, bool) {
+func (s *Session) getResponse(id RaftSeriesID) (sm.Result, bool) {
 	v, ok := s.History[id]
 	return v, ok
 }
 
-func (s *Session) addResponse(id RaftSeriesID, resp uint64) {
+func (s *Session) addResponse(id RaftSeriesID, resp sm.Result) {
 	_, ok := s.History[id]
 	if !ok {
 		s.History[id] = resp
@@ -137,7 +146,20 @@ func createSessionFromSnapshot(reader io.Reader) (*Session, error) {
 	}
 	s := newSession(0)
 	if err := json.Unmarshal(data, s); err != nil {
-		panic(err)
+		return createSessionFromLegacyData(data), nil
 	}
 	return s, nil
 }
+
+func createSessionFromLegacyData(data []byte) *Session {
+	ls := &legacySession{}
+	if err := json.Unmarshal(data, ls); err != nil {
+		panic(err)
+	}
+	s := newSession(ls.ClientID)
+	s.RespondedUpTo = ls.RespondedUpTo
+	for id, v := range ls.History {
+		s.History[id] = sm.Result{Value: v}
+	}
+	return s
+}
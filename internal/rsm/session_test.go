@@ -19,8 +19,11 @@ package rsm
 
 import (
 	"bytes"
+	"encoding/binary"
 	"reflect"
 	"testing"
+
+	sm "github.com/lni/dragonboat/statemachine"
 )
 
 func TestResponseCanBeAdded(t *testing.T) {
@@ -39,14 +42,14 @@ func TestResponseCanBeAdded(t *testing.T) {
 	for i, tt := range tests {
 		s := newSession(0)
 		for idx := range tt.seriesNumList {
-			s.addResponse(tt.seriesNumList[idx], tt.valueList[idx])
+			s.addResponse(tt.seriesNumList[idx], sm.Result{Value: tt.valueList[idx]})
 		}
 		if len(s.History) != tt.size {
 			t.Errorf("i %d, size %d, want %d", i, len(s.History), tt.size)
 		}
 		v, ok := s.getResponse(tt.testSeriesNum)
-		if v != tt.expectedValue {
-			t.Errorf("i %d, v %d, want %d", i, v, tt.expectedValue)
+		if v.Value != tt.expectedValue {
+			t.Errorf("i %d, v %d, want %d", i, v.Value, tt.expectedValue)
 		}
 		if ok != tt.expectedResult {
 			t.Errorf("i %d, v %t, want %t", i, ok, tt.expectedResult)
@@ -73,7 +76,7 @@ func TestCachedResponseDataCanBeCleared(t *testing.T) {
 	for i, tt := range tests {
 		s := newSession(0)
 		for idx := range tt.seriesNumList {
-			s.addResponse(tt.seriesNumList[idx], tt.valueList[idx])
+			s.addResponse(tt.seriesNumList[idx], sm.Result{Value: tt.valueList[idx]})
 		}
 		s.clearTo(tt.clearTo)
 		if len(s.History) != tt.sizeAfterClear {
@@ -105,7 +108,7 @@ func TestWhetherResponsedCanBeReturned(t *testing.T) {
 	for i, tt := range tests {
 		s := newSession(0)
 		for idx := range tt.seriesNumList {
-			s.addResponse(tt.seriesNumList[idx], tt.valueList[idx])
+			s.addResponse(tt.seriesNumList[idx], sm.Result{Value: tt.valueList[idx]})
 		}
 
 		s.clearTo(tt.clearTo)
@@ -128,7 +131,7 @@ func TestSessionCanBeSavedAndRestored(t *testing.T) {
 	for i, tt := range tests {
 		s := newSession(0)
 		for idx := range tt.seriesNumList {
-			s.addResponse(tt.seriesNumList[idx], tt.valueList[idx])
+			s.addResponse(tt.seriesNumList[idx], sm.Result{Value: tt.valueList[idx]})
 		}
 		snapshot := &bytes.Buffer{}
 		s.save(snapshot)
@@ -143,3 +146,40 @@ func TestSessionCanBeSavedAndRestored(t *testing.T) {
 		}
 	}
 }
+
+func TestResultDataIsSavedAndRestored(t *testing.T) {
+	s := newSession(1)
+	s.addResponse(1, sm.Result{Value: 100, Data: []byte("test-data")})
+	snapshot := &bytes.Buffer{}
+	if _, err := s.save(snapshot); err != nil {
+		t.Fatalf("failed to save session %v", err)
+	}
+	newS, err := createSessionFromSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("failed to create session from snapshot, %v", err)
+	}
+	v, ok := newS.getResponse(1)
+	if !ok || v.Value != 100 || string(v.Data) != "test-data" {
+		t.Errorf("unexpected result %v", v)
+	}
+}
+
+func TestSessionCanBeRestoredFromLegacyFormat(t *testing.T) {
+	data := []byte(`{"ClientID":1,"RespondedUpTo":2,"History":{"3":100}}`)
+	snapshot := &bytes.Buffer{}
+	lenbuf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(lenbuf, uint64(len(data)))
+	snapshot.Write(lenbuf)
+	snapshot.Write(data)
+	s, err := createSessionFromSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("failed to create session from snapshot, %v", err)
+	}
+	if s.ClientID != 1 || s.RespondedUpTo != 2 {
+		t.Errorf("unexpected session %v", s)
+	}
+	v, ok := s.getResponse(3)
+	if !ok || v.Value != 100 || v.Data != nil {
+		t.Errorf("unexpected result %v", v)
+	}
+}
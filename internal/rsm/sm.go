@@ -20,9 +20,10 @@ import (
 	sm "github.com/lni/dragonboat/statemachine"
 )
 
-// IStateMachine is an adapter interface for underlying IStateMachine or
-// IConcurrentStateMachine instances.
+// IStateMachine is an adapter interface for underlying IStateMachine,
+// IConcurrentStateMachine or IOnDiskStateMachine instances.
 type IStateMachine interface {
+	Open(<-chan struct{}) (uint64, error)
 	Update(entries []sm.Entry) []sm.Entry
 	Lookup(query []byte) ([]byte, error)
 	PrepareSnapshot() (interface{}, error)
@@ -33,7 +34,9 @@ type IStateMachine interface {
 		[]sm.SnapshotFile, <-chan struct{}) error
 	Close()
 	GetHash() uint64
+	Sync() error
 	ConcurrentSnapshot() bool
+	OnDiskStateMachine() bool
 }
 
 // RegularStateMachine is a regular state machine not capable of taking
@@ -47,6 +50,11 @@ func NewRegularStateMachine(sm sm.IStateMachine) *RegularStateMachine {
 	return &RegularStateMachine{sm: sm}
 }
 
+// Open opens the state machine.
+func (sm *RegularStateMachine) Open(stopc <-chan struct{}) (uint64, error) {
+	panic("Open called on RegularStateMachine")
+}
+
 // Update updates the state machine.
 func (sm *RegularStateMachine) Update(entries []sm.Entry) []sm.Entry {
 	if len(entries) != 1 {
@@ -94,12 +102,23 @@ func (sm *RegularStateMachine) GetHash() uint64 {
 	return sm.sm.GetHash()
 }
 
+// Sync synchronizes the in-core state with that on disk.
+func (sm *RegularStateMachine) Sync() error {
+	return nil
+}
+
 // ConcurrentSnapshot returns a boolean flag indicating whether the state
 // machine is capable of taking concurrent snapshot.
 func (sm *RegularStateMachine) ConcurrentSnapshot() bool {
 	return false
 }
 
+// OnDiskStateMachine returns a boolean flag indicating whether the state
+// machine is an on disk state machine.
+func (sm *RegularStateMachine) OnDiskStateMachine() bool {
+	return false
+}
+
 // ConcurrentStateMachine is an IStateMachine type capable of taking concurrent
 // snapshots.
 type ConcurrentStateMachine struct {
@@ -111,6 +130,11 @@ func NewConcurrentStateMachine(sm sm.IConcurrentStateMachine) *ConcurrentStateMa
 	return &ConcurrentStateMachine{sm: sm}
 }
 
+// Open opens the state machine.
+func (sm *ConcurrentStateMachine) Open(stopc <-chan struct{}) (uint64, error) {
+	panic("Open called on ConcurrentStateMachine")
+}
+
 // Update updates the state machine.
 func (sm *ConcurrentStateMachine) Update(entries []sm.Entry) []sm.Entry {
 	return sm.sm.Update(entries)
@@ -150,8 +174,194 @@ func (sm *ConcurrentStateMachine) GetHash() uint64 {
 	return sm.sm.GetHash()
 }
 
+// Sync synchronizes the in-core state with that on disk.
+func (sm *ConcurrentStateMachine) Sync() error {
+	return nil
+}
+
 // ConcurrentSnapshot returns a boolean flag indicating whether the state
 // machine is capable of taking concurrent snapshot.
 func (sm *ConcurrentStateMachine) ConcurrentSnapshot() bool {
 	return true
 }
+
+// OnDiskStateMachine returns a boolean flag indicating whether the state
+// machine is an on disk state machine.
+func (sm *ConcurrentStateMachine) OnDiskStateMachine() bool {
+	return false
+}
+
+// OnDiskStateMachine is the type to represent an on disk state machine.
+type OnDiskStateMachine struct {
+	sm           sm.IOnDiskStateMachine
+	opened       bool
+	initialIndex uint64
+}
+
+// NewOnDiskStateMachine creates and returns an on disk state machine.
+func NewOnDiskStateMachine(sm sm.IOnDiskStateMachine) *OnDiskStateMachine {
+	return &OnDiskStateMachine{sm: sm}
+}
+
+// Open opens the state machine.
+func (sm *OnDiskStateMachine) Open(stopc <-chan struct{}) (uint64, error) {
+	if sm.opened {
+		panic("Open called more than once on OnDiskStateMachine")
+	}
+	sm.opened = true
+	index, err := sm.sm.Open(stopc)
+	if err != nil {
+		return 0, err
+	}
+	sm.initialIndex = index
+	return index, nil
+}
+
+// Update updates the state machine.
+func (sm *OnDiskStateMachine) Update(entries []sm.Entry) []sm.Entry {
+	if !sm.opened {
+		panic("Update called before Open")
+	}
+	if len(entries) > 0 && entries[0].Index <= sm.initialIndex {
+		plog.Panicf("applying entry %d, initial index %d",
+			entries[0].Index, sm.initialIndex)
+	}
+	return sm.sm.Update(entries)
+}
+
+// Lookup queries the state machine.
+func (sm *OnDiskStateMachine) Lookup(query []byte) ([]byte, error) {
+	if !sm.opened {
+		panic("Lookup called before Open")
+	}
+	return sm.sm.Lookup(query)
+}
+
+// PrepareSnapshot makes preparations for taking concurrent snapshot.
+func (sm *OnDiskStateMachine) PrepareSnapshot() (interface{}, error) {
+	if !sm.opened {
+		panic("PrepareSnapshot called before Open")
+	}
+	return sm.sm.PrepareSnapshot()
+}
+
+// SaveSnapshot saves the snapshot. External files are not supported by on
+// disk state machines, the ISnapshotFileCollection instance is ignored.
+func (sm *OnDiskStateMachine) SaveSnapshot(ctx interface{},
+	w io.Writer, fc sm.ISnapshotFileCollection,
+	stopc <-chan struct{}) (uint64, error) {
+	if !sm.opened {
+		panic("SaveSnapshot called before Open")
+	}
+	return sm.sm.SaveSnapshot(ctx, w, stopc)
+}
+
+// RecoverFromSnapshot recovers the state machine from a snapshot.
+func (sm *OnDiskStateMachine) RecoverFromSnapshot(r io.Reader,
+	fs []sm.SnapshotFile, stopc <-chan struct{}) error {
+	if !sm.opened {
+		panic("RecoverFromSnapshot called before Open")
+	}
+	return sm.sm.RecoverFromSnapshot(r, stopc)
+}
+
+// Close closes the state machine.
+func (sm *OnDiskStateMachine) Close() {
+	sm.sm.Close()
+}
+
+// GetHash returns the uint64 hash value representing the state of a state
+// machine.
+func (sm *OnDiskStateMachine) GetHash() uint64 {
+	return sm.sm.GetHash()
+}
+
+// Sync synchronizes the in-core state with that on disk.
+func (sm *OnDiskStateMachine) Sync() error {
+	if !sm.opened {
+		panic("Sync called before Open")
+	}
+	return sm.sm.Sync()
+}
+
+// ConcurrentSnapshot returns a boolean flag indicating whether the state
+// machine is capable of taking concurrent snapshot.
+func (sm *OnDiskStateMachine) ConcurrentSnapshot() bool {
+	return true
+}
+
+// OnDiskStateMachine returns a boolean flag indicating whether the state
+// machine is an on disk state machine.
+func (sm *OnDiskStateMachine) OnDiskStateMachine() bool {
+	return true
+}
+
+// WitnessStateMachine is the state machine used by witness nodes. Witness
+// nodes only receive the metadata of log entries, nothing is ever applied
+// into the user state machine.
+type WitnessStateMachine struct{}
+
+// NewWitnessStateMachine creates a new WitnessStateMachine instance.
+func NewWitnessStateMachine() *WitnessStateMachine {
+	return &WitnessStateMachine{}
+}
+
+// Open opens the state machine.
+func (sm *WitnessStateMachine) Open(stopc <-chan struct{}) (uint64, error) {
+	panic("Open called on WitnessStateMachine")
+}
+
+// Update updates the state machine.
+func (sm *WitnessStateMachine) Update(entries []sm.Entry) []sm.Entry {
+	panic("Update called on WitnessStateMachine")
+}
+
+// Lookup queries the state machine.
+func (sm *WitnessStateMachine) Lookup(query []byte) ([]byte, error) {
+	panic("Lookup called on WitnessStateMachine")
+}
+
+// PrepareSnapshot makes preparations for taking concurrent snapshot.
+func (sm *WitnessStateMachine) PrepareSnapshot() (interface{}, error) {
+	panic("PrepareSnapshot called on WitnessStateMachine")
+}
+
+// SaveSnapshot saves the snapshot.
+func (sm *WitnessStateMachine) SaveSnapshot(ctx interface{},
+	w io.Writer, fc sm.ISnapshotFileCollection,
+	stopc <-chan struct{}) (uint64, error) {
+	panic("SaveSnapshot called on WitnessStateMachine")
+}
+
+// RecoverFromSnapshot recovers the state machine from a snapshot.
+func (sm *WitnessStateMachine) RecoverFromSnapshot(r io.Reader,
+	fs []sm.SnapshotFile, stopc <-chan struct{}) error {
+	panic("RecoverFromSnapshot called on WitnessStateMachine")
+}
+
+// Close closes the state machine.
+func (sm *WitnessStateMachine) Close() {
+}
+
+// GetHash returns the uint64 hash value representing the state of a state
+// machine.
+func (sm *WitnessStateMachine) GetHash() uint64 {
+	return 0
+}
+
+// Sync synchronizes the in-core state with that on disk.
+func (sm *WitnessStateMachine) Sync() error {
+	return nil
+}
+
+// ConcurrentSnapshot returns a boolean flag indicating whether the state
+// machine is capable of taking concurrent snapshot.
+func (sm *WitnessStateMachine) ConcurrentSnapshot() bool {
+	return false
+}
+
+// OnDiskStateMachine returns a boolean flag indicating whether the state
+// machine is an on disk state machine.
+func (sm *WitnessStateMachine) OnDiskStateMachine() bool {
+	return false
+}
@@ -20,11 +20,13 @@ import (
 	"hash"
 	"hash/crc32"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/lni/dragonboat/internal/settings"
+	"github.com/lni/dragonboat/internal/utils/compression/snappy"
 	"github.com/lni/dragonboat/internal/utils/fileutil"
 	pb "github.com/lni/dragonboat/raftpb"
 )
@@ -32,6 +34,8 @@ import (
 const (
 	// current snapshot binary format version.
 	currentSnapshotVersion = 1
+	// snapshot binary format version used when the payload is compressed.
+	compressedSnapshotVersion = 2
 	// SnapshotHeaderSize is the size of snapshot in number of bytes.
 	SnapshotHeaderSize = settings.SnapshotHeaderSize
 	// which checksum type to use.
@@ -62,15 +66,62 @@ func getDefaultChecksum() hash.Hash {
 	return getChecksum(getChecksumType())
 }
 
-// SnapshotWriter is an io.Writer used to write snapshot file.
-type SnapshotWriter struct {
+func getSnapshotVersion(ct pb.CompressionType) uint64 {
+	if ct == pb.NoCompression {
+		return currentSnapshotVersion
+	}
+	return compressedSnapshotVersion
+}
+
+// checksumWriter writes to the underlying file and updates the checksum of
+// all written data.
+type checksumWriter struct {
+	h    hash.Hash
+	file *os.File
+	sz   uint64
+}
+
+func (cw *checksumWriter) Write(data []byte) (int, error) {
+	if _, err := cw.h.Write(data); err != nil {
+		panic(err)
+	}
+	n, err := cw.file.Write(data)
+	cw.sz += uint64(n)
+	return n, err
+}
+
+// checksumReader reads from the underlying file and updates the checksum of
+// all read data.
+type checksumReader struct {
 	h    hash.Hash
 	file *os.File
+}
+
+func (cr *checksumReader) Read(data []byte) (int, error) {
+	n, err := cr.file.Read(data)
+	if err != nil {
+		return n, err
+	}
+	if _, err = cr.h.Write(data[:n]); err != nil {
+		panic(err)
+	}
+	return n, nil
+}
+
+// SnapshotWriter is an io.Writer used to write snapshot file. Data written to
+// the SnapshotWriter is compressed when a compression type other than
+// NoCompression is specified.
+type SnapshotWriter struct {
+	cw   *checksumWriter
+	sw   *snappy.Writer
+	ct   pb.CompressionType
+	file *os.File
 	fp   string
 }
 
 // NewSnapshotWriter creates a new snapshot writer instance.
-func NewSnapshotWriter(fp string) (*SnapshotWriter, error) {
+func NewSnapshotWriter(fp string,
+	ct pb.CompressionType) (*SnapshotWriter, error) {
 	f, err := os.OpenFile(fp,
 		os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileutil.DefaultFileMode)
 	if err != nil {
@@ -84,10 +135,18 @@ func NewSnapshotWriter(fp string) (*SnapshotWriter, error) {
 		return nil, err
 	}
 	sw := &SnapshotWriter{
-		h:    getDefaultChecksum(),
+		cw:   &checksumWriter{h: getDefaultChecksum(), file: f},
+		ct:   ct,
 		file: f,
 		fp:   fp,
 	}
+	switch ct {
+	case pb.NoCompression:
+	case pb.Snappy:
+		sw.sw = snappy.NewBufferedWriter(sw.cw)
+	default:
+		plog.Panicf("unknown compression type %d", ct)
+	}
 	return sw, nil
 }
 
@@ -104,21 +163,37 @@ func (sw *SnapshotWriter) Close() error {
 
 // Write writes the specified data to the snapshot.
 func (sw *SnapshotWriter) Write(data []byte) (int, error) {
-	if _, err := sw.h.Write(data); err != nil {
-		panic(err)
+	if sw.sw != nil {
+		return sw.sw.Write(data)
 	}
-	return sw.file.Write(data)
+	return sw.cw.Write(data)
+}
+
+// GetPayloadSize returns the number of bytes used for storing a payload of
+// the specified size in the snapshot file. It can only be invoked after the
+// header is saved.
+func (sw *SnapshotWriter) GetPayloadSize(sz uint64) uint64 {
+	if sw.sw != nil {
+		return sw.cw.sz
+	}
+	return sz
 }
 
 // SaveHeader saves the snapshot header to the snapshot.
 func (sw *SnapshotWriter) SaveHeader(smsz uint64, sz uint64) error {
+	if sw.sw != nil {
+		if err := sw.sw.Close(); err != nil {
+			return err
+		}
+	}
 	sh := pb.SnapshotHeader{
 		SessionSize:     smsz,
 		DataStoreSize:   sz,
 		UnreliableTime:  uint64(time.Now().UnixNano()),
-		PayloadChecksum: sw.h.Sum(nil),
+		PayloadChecksum: sw.cw.h.Sum(nil),
 		ChecksumType:    getChecksumType(),
-		Version:         currentSnapshotVersion,
+		Version:         getSnapshotVersion(sw.ct),
+		CompressionType: sw.ct,
 	}
 	data, err := sh.Marshal()
 	if err != nil {
@@ -151,9 +226,29 @@ func (sw *SnapshotWriter) SaveHeader(smsz uint64, sz uint64) error {
 	return nil
 }
 
-// SnapshotReader is an io.Reader for reading from snapshot files.
+// SaveDummySnapshot saves a dummy snapshot that only contains the sessions.
+// Dummy snapshots are used by on disk state machines as their state machine
+// data is never included in local snapshots.
+func SaveDummySnapshot(writer *SnapshotWriter, session []byte) (uint64, error) {
+	n, err := writer.Write(session)
+	if err != nil {
+		return 0, err
+	}
+	if n != len(session) {
+		return 0, io.ErrShortWrite
+	}
+	smsz := uint64(len(session))
+	if err := writer.SaveHeader(smsz, 0); err != nil {
+		return 0, err
+	}
+	return writer.GetPayloadSize(smsz) + SnapshotHeaderSize, nil
+}
+
+// SnapshotReader is an io.Reader for reading from snapshot files. Compressed
+// snapshot payload is transparently decompressed.
 type SnapshotReader struct {
-	h    hash.Hash
+	cr   *checksumReader
+	r    io.Reader
 	file *os.File
 }
 
@@ -198,7 +293,15 @@ func (sr *SnapshotReader) GetHeader() (pb.SnapshotHeader, error) {
 	if err := r.Unmarshal(data); err != nil {
 		panic(err)
 	}
-	sr.h = getChecksum(r.ChecksumType)
+	sr.cr = &checksumReader{h: getChecksum(r.ChecksumType), file: sr.file}
+	switch r.CompressionType {
+	case pb.NoCompression:
+		sr.r = sr.cr
+	case pb.Snappy:
+		sr.r = snappy.NewReader(sr.cr)
+	default:
+		plog.Panicf("unknown compression type %d", r.CompressionType)
+	}
 	offset, err := sr.file.Seek(int64(SnapshotHeaderSize), 0)
 	if err != nil {
 		return empty, err
@@ -211,20 +314,20 @@ func (sr *SnapshotReader) GetHeader() (pb.SnapshotHeader, error) {
 
 // Read reads up to len(data) bytes from the snapshot file.
 func (sr *SnapshotReader) Read(data []byte) (int, error) {
-	n, err := sr.file.Read(data)
-	if err != nil {
-		return n, err
-	}
-	if _, err = sr.h.Write(data[:n]); err != nil {
-		panic(err)
-	}
-	return n, nil
+	return sr.r.Read(data)
 }
 
 // ValidatePayload validates whether the snapshot content matches the checksum
 // recorded in the header.
 func (sr *SnapshotReader) ValidatePayload(header pb.SnapshotHeader) {
-	checksum := sr.h.Sum(nil)
+	if header.CompressionType != pb.NoCompression {
+		// the decompressed data might have been fully consumed before reaching
+		// the end of the file, the rest of the file is required by the checksum.
+		if _, err := io.Copy(ioutil.Discard, sr.cr); err != nil {
+			panic(err)
+		}
+	}
+	checksum := sr.cr.h.Sum(nil)
 	if !bytes.Equal(checksum, header.PayloadChecksum) {
 		panic("corrupted snapshot payload")
 	}
@@ -247,7 +350,7 @@ func (sr *SnapshotReader) ValidateHeader(header pb.SnapshotHeader) {
 	if !bytes.Equal(headerChecksum, checksum) {
 		panic("corrupted snapshot header")
 	}
-	if header.Version != currentSnapshotVersion {
+	if header.Version != getSnapshotVersion(header.CompressionType) {
 		panic("unknown version")
 	}
 }
@@ -20,6 +20,8 @@ import (
 	"math/rand"
 	"os"
 	"testing"
+
+	pb "github.com/lni/dragonboat/raftpb"
 )
 
 const (
@@ -29,7 +31,7 @@ const (
 )
 
 func TestSnapshotWriterCanBeCreated(t *testing.T) {
-	w, err := NewSnapshotWriter(testSnapshotFilename)
+	w, err := NewSnapshotWriter(testSnapshotFilename, pb.NoCompression)
 	if err != nil {
 		t.Fatalf("failed to create snapshot writer %v", err)
 	}
@@ -45,7 +47,7 @@ func TestSnapshotWriterCanBeCreated(t *testing.T) {
 }
 
 func TestSaveHeaderSavesTheHeader(t *testing.T) {
-	w, err := NewSnapshotWriter(testSnapshotFilename)
+	w, err := NewSnapshotWriter(testSnapshotFilename, pb.NoCompression)
 	if err != nil {
 		t.Fatalf("failed to create snapshot writer %v", err)
 	}
@@ -62,7 +64,7 @@ func TestSaveHeaderSavesTheHeader(t *testing.T) {
 	if err != nil || m != len(storeData) {
 		t.Fatalf("failed to write the store data")
 	}
-	storeChecksum := w.h.Sum(nil)
+	storeChecksum := w.cw.h.Sum(nil)
 	if err := w.SaveHeader(uint64(n), uint64(m)); err != nil {
 		t.Fatalf("%v", err)
 	}
@@ -91,9 +93,9 @@ func TestSaveHeaderSavesTheHeader(t *testing.T) {
 	}
 }
 
-func makeTestSnapshotFile(t *testing.T, ssz uint64,
-	psz uint64) (*SnapshotWriter, []byte, []byte) {
-	w, err := NewSnapshotWriter(testSnapshotFilename)
+func makeTestSnapshotFile(t *testing.T, ssz uint64, psz uint64,
+	ct pb.CompressionType) (*SnapshotWriter, []byte, []byte) {
+	w, err := NewSnapshotWriter(testSnapshotFilename, ct)
 	if err != nil {
 		t.Fatalf("failed to create snapshot writer %v", err)
 	}
@@ -120,7 +122,8 @@ func makeTestSnapshotFile(t *testing.T, ssz uint64,
 }
 
 func createTestSnapshotFile(t *testing.T) (*SnapshotWriter, []byte, []byte) {
-	return makeTestSnapshotFile(t, testSessionSize, testPayloadSize)
+	return makeTestSnapshotFile(t,
+		testSessionSize, testPayloadSize, pb.NoCompression)
 }
 
 func TestCorruptedHeaderWillBeDetected(t *testing.T) {
@@ -250,7 +253,7 @@ func TestSingleBlockSnapshotValidation(t *testing.T) {
 }
 
 func TestMultiBlockSnapshotValidation(t *testing.T) {
-	makeTestSnapshotFile(t, 1024*1024, 1024*1024*8)
+	makeTestSnapshotFile(t, 1024*1024, 1024*1024*8, pb.NoCompression)
 	defer os.RemoveAll(testSnapshotFilename)
 	data, err := readTestSnapshot(testSnapshotFilename, 1024*1024*10)
 	if err != nil {
@@ -280,3 +283,80 @@ func TestMultiBlockSnapshotValidation(t *testing.T) {
 		t.Fatalf("validation failed to pick up the corrupted snapshot")
 	}
 }
+
+func TestCompressedSnapshotCanBeRead(t *testing.T) {
+	_, sessionData, storeData := makeTestSnapshotFile(t,
+		1024, 1024*1024, pb.Snappy)
+	defer os.RemoveAll(testSnapshotFilename)
+	r, err := NewSnapshotReader(testSnapshotFilename)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer r.Close()
+	header, err := r.GetHeader()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if header.CompressionType != pb.Snappy ||
+		header.Version != compressedSnapshotVersion {
+		t.Errorf("unexpected header %+v", header)
+	}
+	r.ValidateHeader(header)
+	s := make([]byte, len(sessionData))
+	p := make([]byte, len(storeData))
+	if _, err := io.ReadFull(r, s); err != nil {
+		t.Fatalf("failed to get session data %v", err)
+	}
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Fatalf("failed to get payload data %v", err)
+	}
+	r.ValidatePayload(header)
+	if !bytes.Equal(sessionData, s) || !bytes.Equal(storeData, p) {
+		t.Errorf("snapshot data changed")
+	}
+}
+
+func TestCompressedSnapshotPayloadSize(t *testing.T) {
+	w, err := NewSnapshotWriter(testSnapshotFilename, pb.Snappy)
+	if err != nil {
+		t.Fatalf("failed to create snapshot writer %v", err)
+	}
+	defer os.RemoveAll(testSnapshotFilename)
+	data := make([]byte, 1024*1024)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("write failed %v", err)
+	}
+	if err := w.SaveHeader(0, uint64(len(data))); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	sz := w.GetPayloadSize(uint64(len(data)))
+	if sz == 0 || sz >= uint64(len(data)) {
+		t.Errorf("payload not compressed, size %d", sz)
+	}
+	fi, err := os.Stat(testSnapshotFilename)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if uint64(fi.Size()) != sz+SnapshotHeaderSize {
+		t.Errorf("file size %d, want %d", fi.Size(), sz+SnapshotHeaderSize)
+	}
+}
+
+func TestCompressedSnapshotValidation(t *testing.T) {
+	makeTestSnapshotFile(t, 1024, 1024*1024, pb.Snappy)
+	defer os.RemoveAll(testSnapshotFilename)
+	data, err := readTestSnapshot(testSnapshotFilename, 1024*1024*2)
+	if err != nil {
+		t.Fatalf("failed to get snapshot data %v", err)
+	}
+	v := NewSnapshotValidator()
+	if !v.AddChunk(data, 0) {
+		t.Fatalf("failed to add chunk")
+	}
+	if !v.Validate() {
+		t.Fatalf("validation failed")
+	}
+}
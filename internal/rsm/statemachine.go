@@ -30,6 +30,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/lni/dragonboat/config"
 	"github.com/lni/dragonboat/internal/raft"
 	"github.com/lni/dragonboat/internal/server"
 	"github.com/lni/dragonboat/internal/settings"
@@ -54,6 +55,40 @@ var (
 	batchedEntryApply       bool   = settings.Soft.BatchedEntryApply
 )
 
+// SnapshotRequestType is the type of a snapshot request.
+type SnapshotRequestType uint64
+
+const (
+	// PeriodicSnapshot is the value to indicate periodic snapshot.
+	PeriodicSnapshot SnapshotRequestType = iota
+	// UserRequestedSnapshot is the value to indicate user requested snapshot.
+	UserRequestedSnapshot
+	// ExportedSnapshot is the value to indicate exported snapshot.
+	ExportedSnapshot
+)
+
+// SnapshotRequest is the type for describing the details of a snapshot
+// request.
+type SnapshotRequest struct {
+	Type               SnapshotRequestType
+	Key                uint64
+	Path               string
+	OverrideCompaction bool
+	CompactionOverhead uint64
+}
+
+// IsPeriodicSnapshot returns a boolean value indicating whether the snapshot
+// request is for a periodic snapshot.
+func (r *SnapshotRequest) IsPeriodicSnapshot() bool {
+	return r.Type == PeriodicSnapshot
+}
+
+// IsExportedSnapshot returns a boolean value indicating whether the snapshot
+// request is for exporting a snapshot to an external directory.
+func (r *SnapshotRequest) IsExportedSnapshot() bool {
+	return r.Type == ExportedSnapshot
+}
+
 // SnapshotMeta is the metadata of a snapshot.
 type SnapshotMeta struct {
 	Index      uint64
@@ -61,6 +96,8 @@ type SnapshotMeta struct {
 	Membership pb.Membership
 	Session    *bytes.Buffer
 	Ctx        interface{}
+	Dummy      bool
+	Request    SnapshotRequest
 }
 
 // Commit is the processing units that can be handled by StateMachines.
@@ -69,6 +106,7 @@ type Commit struct {
 	SnapshotAvailable bool
 	InitialSnapshot   bool
 	SnapshotRequested bool
+	SnapshotRequest   SnapshotRequest
 	Entries           []pb.Entry
 }
 
@@ -79,7 +117,7 @@ type SMFactoryFunc func(clusterID uint64,
 // INodeProxy is the interface used as proxy to a nodehost.
 type INodeProxy interface {
 	RestoreRemotes(pb.Snapshot)
-	ApplyUpdate(pb.Entry, uint64, bool, bool, bool)
+	ApplyUpdate(pb.Entry, sm.Result, bool, bool, bool)
 	ApplyConfigChange(pb.ConfigChange)
 	ConfigChangeProcessed(uint64, bool)
 	NodeID() uint64
@@ -93,6 +131,8 @@ type ISnapshotter interface {
 	GetFilePath(uint64) string
 	Save(IManagedStateMachine,
 		*SnapshotMeta) (*pb.Snapshot, *server.SnapshotEnv, error)
+	Stream(IManagedStateMachine,
+		*SnapshotMeta, uint64) (*pb.Snapshot, *server.SnapshotEnv, error)
 	IsNoSnapshotError(error) bool
 }
 
@@ -106,8 +146,11 @@ type StateMachine struct {
 	index              uint64
 	term               uint64
 	snapshotIndex      uint64
+	onDiskInitIndex    uint64
 	members            *pb.Membership
 	ordered            bool
+	witness            bool
+	validator          config.IConfigChangeValidator
 	commitC            chan Commit
 	aborted            bool
 	batchedLastApplied struct {
@@ -116,21 +159,29 @@ type StateMachine struct {
 	}
 }
 
-// NewStateMachine creates a new application state machine object.
+// NewStateMachine creates a new application state machine object. The witness
+// flag indicates whether the state machine belongs to a witness node, such
+// state machine only maintains the membership and sessions. The optional
+// validator is used for validating membership changes before they are applied.
 func NewStateMachine(sm IManagedStateMachine,
-	snapshotter ISnapshotter, ordered bool,
-	proxy INodeProxy) *StateMachine {
+	snapshotter ISnapshotter, ordered bool, witness bool,
+	proxy INodeProxy, validator config.IConfigChangeValidator) *StateMachine {
 	a := &StateMachine{
 		snapshotter: snapshotter,
 		sm:          sm,
 		commitC:     make(chan Commit, commitChanLength),
 		ordered:     ordered,
+		witness:     witness,
 		node:        proxy,
+		validator:   validator,
 	}
 	a.members = &pb.Membership{
 		Addresses: make(map[uint64]string),
 		Observers: make(map[uint64]string),
+		Witnesses: make(map[uint64]string),
 		Removed:   make(map[uint64]bool),
+		Incoming:  make(map[uint64]bool),
+		Outgoing:  make(map[uint64]bool),
 	}
 	return a
 }
@@ -200,9 +251,20 @@ func (s *StateMachine) recoverSnapshot(ss pb.Snapshot,
 	}
 	plog.Infof("%s restarting at term %d, index %d, %s, initial snapshot %t",
 		s.describe(), ss.Term, ss.Index, snapshotInfo(ss), initial)
-	snapshotFiles := getSnapshotFiles(ss)
 	fn := s.snapshotter.GetFilePath(ss.Index)
-	if err := s.sm.RecoverFromSnapshot(fn, snapshotFiles); err != nil {
+	var err error
+	if ss.Witness {
+		// witness snapshots only contain the membership, there is no snapshot
+		// file to be loaded
+		if !s.witness {
+			plog.Panicf("%s got a witness snapshot %d", s.describe(), ss.Index)
+		}
+	} else if s.sessionOnlySnapshot(ss) {
+		err = s.recoverSessions(fn)
+	} else {
+		err = s.sm.RecoverFromSnapshot(fn, getSnapshotFiles(ss))
+	}
+	if err != nil {
 		plog.Infof("%s called RecoverFromSnapshot %d, returned %v",
 			s.describe(), ss.Index, err)
 		if err == sm.ErrSnapshotStopped {
@@ -220,6 +282,77 @@ func (s *StateMachine) recoverSnapshot(ss pb.Snapshot,
 	return true, 0, nil
 }
 
+// sessionOnlySnapshot returns a boolean flag indicating whether only the
+// sessions should be recovered from the specified snapshot. This is the case
+// for witness nodes and for on disk state machines when the snapshot has
+// already been covered by the state persisted on disk.
+func (s *StateMachine) sessionOnlySnapshot(ss pb.Snapshot) bool {
+	// witness never has any state machine data in its snapshots
+	if s.witness {
+		return true
+	}
+	if !s.OnDiskStateMachine() {
+		if ss.Dummy {
+			plog.Panicf("%s got a dummy snapshot %d", s.describe(), ss.Index)
+		}
+		return false
+	}
+	if ss.Index <= s.onDiskInitIndex {
+		return true
+	}
+	if ss.Dummy {
+		plog.Panicf("%s on disk state machine at %d, behind dummy snapshot %d",
+			s.describe(), s.onDiskInitIndex, ss.Index)
+	}
+	return false
+}
+
+func (s *StateMachine) recoverSessions(fp string) (err error) {
+	reader, err := NewSnapshotReader(fp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := reader.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	header, err := reader.GetHeader()
+	if err != nil {
+		return err
+	}
+	reader.ValidateHeader(header)
+	if err := s.sm.LoadSessions(reader); err != nil {
+		return err
+	}
+	if header.DataStoreSize == 0 {
+		reader.ValidatePayload(header)
+	}
+	return nil
+}
+
+// OpenOnDiskStateMachine opens the on disk state machine. It returns the
+// index of the last entry persisted by the on disk state machine.
+func (s *StateMachine) OpenOnDiskStateMachine() (uint64, error) {
+	if !s.OnDiskStateMachine() {
+		panic("OpenOnDiskStateMachine called on non on disk state machine")
+	}
+	index, err := s.sm.Open()
+	if err != nil {
+		plog.Errorf("%s failed to open on disk state machine, %v",
+			s.describe(), err)
+		if err == sm.ErrOpenStopped {
+			s.mu.Lock()
+			s.aborted = true
+			s.mu.Unlock()
+		}
+		return 0, err
+	}
+	plog.Infof("%s opened on disk state machine, index %d", s.describe(), index)
+	s.onDiskInitIndex = index
+	return index, nil
+}
+
 // GetLastApplied returns the last applied value.
 func (s *StateMachine) GetLastApplied() uint64 {
 	s.mu.RLock()
@@ -283,11 +416,12 @@ func (s *StateMachine) concurrentLookup(query []byte) ([]byte, error) {
 
 // GetMembership returns the membership info maintained by the state machine.
 func (s *StateMachine) GetMembership() (map[uint64]string,
-	map[uint64]string, map[uint64]struct{}, uint64) {
+	map[uint64]string, map[uint64]string, map[uint64]struct{}, uint64) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	members := make(map[uint64]string)
 	observers := make(map[uint64]string)
+	witnesses := make(map[uint64]string)
 	removed := make(map[uint64]struct{})
 	for nid, addr := range s.members.Addresses {
 		members[nid] = addr
@@ -295,10 +429,13 @@ func (s *StateMachine) GetMembership() (map[uint64]string,
 	for nid, addr := range s.members.Observers {
 		observers[nid] = addr
 	}
+	for nid, addr := range s.members.Witnesses {
+		witnesses[nid] = addr
+	}
 	for nid := range s.members.Removed {
 		removed[nid] = struct{}{}
 	}
-	return members, observers, removed, s.members.ConfigChangeId
+	return members, observers, witnesses, removed, s.members.ConfigChangeId
 }
 
 // ConcurrentSnapshot returns a boolean flag indicating whether the state
@@ -307,13 +444,50 @@ func (s *StateMachine) ConcurrentSnapshot() bool {
 	return s.sm.ConcurrentSnapshot()
 }
 
-// SaveSnapshot creates a snapshot.
-func (s *StateMachine) SaveSnapshot() (*pb.Snapshot,
+// OnDiskStateMachine returns a boolean flag indicating whether the managed
+// state machine is an on disk state machine.
+func (s *StateMachine) OnDiskStateMachine() bool {
+	return s.sm.OnDiskStateMachine()
+}
+
+// SaveSnapshot creates a snapshot as specified by the snapshot request.
+// Exported snapshots are always full snapshots, they are not tracked by the
+// state machine and can be requested even when there is no applied entry since
+// the last snapshot.
+func (s *StateMachine) SaveSnapshot(req SnapshotRequest) (*pb.Snapshot,
 	*server.SnapshotEnv, error) {
 	if s.sm.ConcurrentSnapshot() {
-		return s.saveConcurrentSnapshot()
+		return s.saveConcurrentSnapshot(req)
+	}
+	return s.saveSnapshot(req)
+}
+
+// StreamSnapshot creates a full snapshot of the on disk state machine for the
+// specified remote node. Such snapshot is not saved into the LogDB and the
+// returned snapshot env is expected to be removed by the caller once the
+// snapshot is no longer required.
+func (s *StateMachine) StreamSnapshot(to uint64) (*pb.Snapshot,
+	*server.SnapshotEnv, error) {
+	if !s.OnDiskStateMachine() {
+		panic("StreamSnapshot called on non on disk state machine")
+	}
+	var meta *SnapshotMeta
+	if err := func() error {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		if s.aborted {
+			return sm.ErrSnapshotStopped
+		}
+		ctx, err := s.sm.PrepareSnapshot()
+		if err != nil {
+			return err
+		}
+		meta = s.getSnapshotMeta(ctx)
+		return nil
+	}(); err != nil {
+		return nil, nil, err
 	}
-	return s.saveSnapshot()
+	return s.snapshotter.Stream(s.sm, meta, to)
 }
 
 // GetHash returns the state machine hash.
@@ -422,27 +596,28 @@ func (s *StateMachine) updateLastApplied(index uint64, term uint64) {
 	s.term = term
 }
 
-func (s *StateMachine) checkSnapshotStatus() error {
+func (s *StateMachine) checkSnapshotStatus(req SnapshotRequest) error {
 	if s.aborted {
 		return sm.ErrSnapshotStopped
 	}
 	if s.index < s.snapshotIndex {
 		panic("s.index < s.snapshotIndex")
 	}
-	if s.index > 0 && s.index == s.snapshotIndex {
+	if !req.IsExportedSnapshot() &&
+		s.index > 0 && s.index == s.snapshotIndex {
 		return raft.ErrSnapshotOutOfDate
 	}
 	return nil
 }
 
-func (s *StateMachine) saveConcurrentSnapshot() (*pb.Snapshot,
+func (s *StateMachine) saveConcurrentSnapshot(req SnapshotRequest) (*pb.Snapshot,
 	*server.SnapshotEnv, error) {
 	var err error
 	var meta *SnapshotMeta
 	if err := func() error {
 		s.mu.RLock()
 		defer s.mu.RUnlock()
-		meta, err = s.prepareSnapshot()
+		meta, err = s.prepareSnapshot(req)
 		return err
 	}(); err != nil {
 		return nil, nil, err
@@ -450,11 +625,11 @@ func (s *StateMachine) saveConcurrentSnapshot() (*pb.Snapshot,
 	return s.doSaveSnapshot(meta)
 }
 
-func (s *StateMachine) saveSnapshot() (*pb.Snapshot,
+func (s *StateMachine) saveSnapshot(req SnapshotRequest) (*pb.Snapshot,
 	*server.SnapshotEnv, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	meta, err := s.prepareSnapshot()
+	meta, err := s.prepareSnapshot(req)
 	if err != nil {
 		plog.Errorf("prepare snapshot failed %v", err)
 		return nil, nil, err
@@ -462,19 +637,42 @@ func (s *StateMachine) saveSnapshot() (*pb.Snapshot,
 	return s.doSaveSnapshot(meta)
 }
 
-func (s *StateMachine) prepareSnapshot() (*SnapshotMeta, error) {
-	if err := s.checkSnapshotStatus(); err != nil {
+func (s *StateMachine) prepareSnapshot(req SnapshotRequest) (*SnapshotMeta,
+	error) {
+	if err := s.checkSnapshotStatus(req); err != nil {
 		return nil, err
 	}
 	var err error
 	var ctx interface{}
+	if s.witness {
+		if req.IsExportedSnapshot() {
+			plog.Panicf("witness snapshot can not be exported")
+		}
+		// witness doesn't have any state machine data to be included
+		meta := s.getSnapshotMeta(nil)
+		meta.Dummy = true
+		return meta, nil
+	}
+	if s.OnDiskStateMachine() && !req.IsExportedSnapshot() {
+		// the state machine data is never included in local snapshots of on
+		// disk state machines, make sure it has been persisted before the
+		// dummy snapshot allows raft log to be compacted.
+		if err := s.sm.Sync(); err != nil {
+			panic(err)
+		}
+		meta := s.getSnapshotMeta(nil)
+		meta.Dummy = true
+		return meta, nil
+	}
 	if s.ConcurrentSnapshot() {
 		ctx, err = s.sm.PrepareSnapshot()
 		if err != nil {
 			panic(err)
 		}
 	}
-	return s.getSnapshotMeta(ctx), nil
+	meta := s.getSnapshotMeta(ctx)
+	meta.Request = req
+	return meta, nil
 }
 
 func (s *StateMachine) doSaveSnapshot(meta *SnapshotMeta) (*pb.Snapshot,
@@ -484,7 +682,11 @@ func (s *StateMachine) doSaveSnapshot(meta *SnapshotMeta) (*pb.Snapshot,
 		plog.Errorf("save snapshot failed %v", err)
 		return nil, env, err
 	}
-	s.snapshotIndex = meta.Index
+	// exported snapshots are not managed by the snapshotter, they don't affect
+	// when the next local snapshot can be taken
+	if !meta.Request.IsExportedSnapshot() {
+		s.snapshotIndex = meta.Index
+	}
 	return snapshot, env, nil
 }
 
@@ -530,17 +732,19 @@ func (s *StateMachine) handleCommitRec(ent pb.Entry, lastInBatch bool) {
 		if !ent.IsSessionManaged() {
 			if ent.IsEmpty() {
 				s.handleNoOP(ent)
-				s.node.ApplyUpdate(ent, 0, false, true, lastInBatch)
+				s.node.ApplyUpdate(ent, sm.Result{}, false, true, lastInBatch)
 			} else {
 				panic("not session managed, not empty")
 			}
 		} else {
 			if ent.IsNewSessionRequest() {
 				smResult := s.handleRegisterSession(ent)
-				s.node.ApplyUpdate(ent, smResult, smResult == 0, false, lastInBatch)
+				s.node.ApplyUpdate(ent,
+					sm.Result{Value: smResult}, smResult == 0, false, lastInBatch)
 			} else if ent.IsEndOfSessionRequest() {
 				smResult := s.handleUnregisterSession(ent)
-				s.node.ApplyUpdate(ent, smResult, smResult == 0, false, lastInBatch)
+				s.node.ApplyUpdate(ent,
+					sm.Result{Value: smResult}, smResult == 0, false, lastInBatch)
 			} else {
 				smResult, ignored, rejected := s.handleUpdate(ent)
 				if !ignored {
@@ -559,7 +763,7 @@ func (s *StateMachine) handleCommitRec(ent pb.Entry, lastInBatch bool) {
 }
 
 func (s *StateMachine) onUpdateApplied(ent pb.Entry,
-	result uint64, ignored bool, rejected bool, lastInBatch bool) {
+	result sm.Result, ignored bool, rejected bool, lastInBatch bool) {
 	if !ignored {
 		s.node.ApplyUpdate(ent, result, rejected, ignored, lastInBatch)
 	}
@@ -570,13 +774,25 @@ func (s *StateMachine) handleBatchedNoOPEntries(ents []pb.Entry,
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, ent := range ents {
-		entries = append(entries, sm.Entry{Index: ent.Index, Cmd: ent.Cmd})
+		if !s.entryInInitDiskSM(ent.Index) {
+			entries = append(entries, sm.Entry{Index: ent.Index, Cmd: GetEntryPayload(ent)})
+		}
 		s.updateLastApplied(ent.Index, ent.Term)
 	}
-	results := s.sm.BatchedUpdate(entries)
-	for idx, ent := range results {
+	// entries already applied by the on disk state machine are always at the
+	// beginning of the batch
+	skipped := len(ents) - len(entries)
+	for idx := 0; idx < skipped; idx++ {
 		lastInBatch := idx == len(ents)-1
-		s.onUpdateApplied(ents[idx], ent.Result, false, false, lastInBatch)
+		s.onUpdateApplied(ents[idx], sm.Result{}, false, false, lastInBatch)
+	}
+	if len(entries) > 0 {
+		results := s.sm.BatchedUpdate(entries)
+		for idx, ent := range results {
+			lastInBatch := idx+skipped == len(ents)-1
+			s.onUpdateApplied(ents[idx+skipped],
+				ent.Result, false, false, lastInBatch)
+		}
 	}
 	if len(ents) > 0 {
 		s.setBatchedLastApplied(ents[len(ents)-1].Index)
@@ -595,14 +811,14 @@ func (s *StateMachine) handleAllUpdateEntries(ents []pb.Entry) {
 		if !entry.IsNoOPSession() {
 			session, ok = s.sm.ClientRegistered(entry.ClientID)
 			if !ok {
-				s.onUpdateApplied(entry, 0, false, true, lastInBatch)
+				s.onUpdateApplied(entry, sm.Result{}, false, true, lastInBatch)
 				continue
 			}
 			s.sm.UpdateRespondedTo(session, entry.RespondedTo)
 			result, responded, updateRequired := s.sm.UpdateRequired(session,
 				entry.SeriesID)
 			if responded {
-				s.onUpdateApplied(entry, 0, true, false, lastInBatch)
+				s.onUpdateApplied(entry, sm.Result{}, true, false, lastInBatch)
 				continue
 			}
 			if !updateRequired {
@@ -613,8 +829,13 @@ func (s *StateMachine) handleAllUpdateEntries(ents []pb.Entry) {
 		if !entry.IsNoOPSession() && session == nil {
 			panic("session is nil")
 		}
+		if s.entryInInitDiskSM(entry.Index) {
+			s.skipUpdate(session, entry)
+			s.onUpdateApplied(entry, sm.Result{}, false, false, lastInBatch)
+			continue
+		}
 		result := s.sm.Update(session,
-			entry.SeriesID, entry.Index, entry.Term, entry.Cmd)
+			entry.SeriesID, entry.Index, entry.Term, GetEntryPayload(entry))
 		s.onUpdateApplied(entry, result, false, false, lastInBatch)
 	}
 	if len(ents) > 0 {
@@ -623,7 +844,7 @@ func (s *StateMachine) handleAllUpdateEntries(ents []pb.Entry) {
 }
 
 func (s *StateMachine) isConfChangeUpToDate(cc pb.ConfigChange) bool {
-	if !s.ordered || cc.Initialize {
+	if !s.ordered || cc.Initialize || cc.Type == pb.LeaveJoint {
 		return true
 	}
 	if s.members.ConfigChangeId == cc.ConfigChangeId {
@@ -633,7 +854,8 @@ func (s *StateMachine) isConfChangeUpToDate(cc pb.ConfigChange) bool {
 }
 
 func (s *StateMachine) isAddingRemovedNode(cc pb.ConfigChange) bool {
-	if cc.Type == pb.AddNode || cc.Type == pb.AddObserver {
+	if cc.Type == pb.AddNode ||
+		cc.Type == pb.AddObserver || cc.Type == pb.AddWitness {
 		_, ok := s.members.Removed[cc.NodeID]
 		return ok
 	}
@@ -664,6 +886,15 @@ func (s *StateMachine) isAddingExistingMember(cc pb.ConfigChange) bool {
 			}
 		}
 	}
+	if cc.Type == pb.AddWitness {
+		plog.Infof("%s adding witness %d:%s, existing members: %v",
+			s.describe(), cc.NodeID, string(cc.Address), s.members.Addresses)
+		for _, addr := range s.members.Witnesses {
+			if addressEqual(addr, string(cc.Address)) {
+				return true
+			}
+		}
+	}
 	return false
 }
 
@@ -675,6 +906,220 @@ func (s *StateMachine) isAddingNodeAsObserver(cc pb.ConfigChange) bool {
 	return false
 }
 
+func (s *StateMachine) isAddingWitnessAsMember(cc pb.ConfigChange) bool {
+	if cc.Type == pb.AddNode || cc.Type == pb.AddObserver {
+		_, ok := s.members.Witnesses[cc.NodeID]
+		return ok
+	}
+	return false
+}
+
+func (s *StateMachine) isAddingMemberAsWitness(cc pb.ConfigChange) bool {
+	if cc.Type == pb.AddWitness {
+		_, nok := s.members.Addresses[cc.NodeID]
+		_, ook := s.members.Observers[cc.NodeID]
+		return nok || ook
+	}
+	return false
+}
+
+func (s *StateMachine) isPromotingNonObserver(cc pb.ConfigChange) bool {
+	if cc.Type == pb.PromoteObserver {
+		_, ok := s.members.Observers[cc.NodeID]
+		return !ok
+	}
+	return false
+}
+
+func (s *StateMachine) isMember(nodeID uint64) bool {
+	_, nok := s.members.Addresses[nodeID]
+	_, ook := s.members.Observers[nodeID]
+	_, wok := s.members.Witnesses[nodeID]
+	return nok || ook || wok
+}
+
+// isValidJointConfigChange checks whether the changes included in the
+// specified EnterJoint config change can be applied. the checks used for
+// regular config changes are applied to each individual change.
+func (s *StateMachine) isValidJointConfigChange(cc pb.ConfigChange) bool {
+	if len(cc.Changes) == 0 {
+		plog.Warningf("%s rejected joint ConfChange with no change",
+			s.describe())
+		return false
+	}
+	voters := len(s.members.Addresses) + len(s.members.Witnesses)
+	nodes := make(map[uint64]struct{})
+	for _, c := range cc.Changes {
+		if c.IsJointConfigChange() {
+			plog.Warningf("%s rejected nested joint ConfChange", s.describe())
+			return false
+		}
+		if _, ok := nodes[c.NodeID]; ok {
+			plog.Warningf("%s rejected joint ConfChange, node %s repeated",
+				s.describe(), logutil.NodeID(c.NodeID))
+			return false
+		}
+		nodes[c.NodeID] = struct{}{}
+		_, isObserver := s.members.Observers[c.NodeID]
+		if c.Type == pb.AddNode {
+			if _, ok := s.members.Addresses[c.NodeID]; ok {
+				plog.Warningf("%s rejected joint ConfChange, node %s is a member",
+					s.describe(), logutil.NodeID(c.NodeID))
+				return false
+			}
+			if len(c.Address) == 0 && !isObserver {
+				plog.Warningf("%s rejected joint ConfChange, empty address for %s",
+					s.describe(), logutil.NodeID(c.NodeID))
+				return false
+			}
+		}
+		if s.isAddingRemovedNode(c) || s.isAddingExistingMember(c) ||
+			s.isAddingNodeAsObserver(c) || s.isAddingWitnessAsMember(c) ||
+			s.isAddingMemberAsWitness(c) || s.isPromotingNonObserver(c) {
+			plog.Warningf("%s rejected joint ConfChange, type %s, node %s",
+				s.describe(), c.Type, logutil.NodeID(c.NodeID))
+			return false
+		}
+		switch c.Type {
+		case pb.AddNode, pb.PromoteObserver:
+			voters++
+		case pb.AddWitness:
+			if _, ok := s.members.Witnesses[c.NodeID]; !ok {
+				voters++
+			}
+		case pb.RemoveNode:
+			_, nok := s.members.Addresses[c.NodeID]
+			_, wok := s.members.Witnesses[c.NodeID]
+			if nok || wok {
+				voters--
+			}
+		}
+	}
+	if voters == 0 {
+		plog.Warningf("%s rejected joint ConfChange, no voting member left",
+			s.describe())
+		return false
+	}
+	return true
+}
+
+func (s *StateMachine) enterJointLocked(cc pb.ConfigChange, index uint64) {
+	s.members.ConfigChangeId = index
+	s.node.ApplyConfigChange(cc)
+	for _, c := range cc.Changes {
+		switch c.Type {
+		case pb.AddNode:
+			addr := string(c.Address)
+			if oaddr, ok := s.members.Observers[c.NodeID]; ok {
+				delete(s.members.Observers, c.NodeID)
+				addr = oaddr
+			}
+			s.members.Addresses[c.NodeID] = addr
+			s.members.Incoming[c.NodeID] = true
+		case pb.PromoteObserver:
+			s.members.Addresses[c.NodeID] = s.members.Observers[c.NodeID]
+			delete(s.members.Observers, c.NodeID)
+			s.members.Incoming[c.NodeID] = true
+		case pb.AddWitness:
+			if _, ok := s.members.Witnesses[c.NodeID]; !ok {
+				s.members.Witnesses[c.NodeID] = string(c.Address)
+				s.members.Incoming[c.NodeID] = true
+			}
+		case pb.AddObserver:
+			s.members.Observers[c.NodeID] = string(c.Address)
+		case pb.RemoveNode:
+			if s.isMember(c.NodeID) {
+				// removed when leaving the joint config
+				s.members.Outgoing[c.NodeID] = true
+			} else {
+				s.members.Removed[c.NodeID] = true
+			}
+		default:
+			panic("unknown config change type")
+		}
+	}
+}
+
+func (s *StateMachine) leaveJointLocked(cc pb.ConfigChange, index uint64) {
+	outgoing := make([]uint64, 0)
+	for nid := range s.members.Outgoing {
+		outgoing = append(outgoing, nid)
+	}
+	sort.Slice(outgoing, func(i, j int) bool {
+		return outgoing[i] < outgoing[j]
+	})
+	for _, nid := range outgoing {
+		cc.Changes = append(cc.Changes,
+			pb.ConfigChange{Type: pb.RemoveNode, NodeID: nid})
+	}
+	s.members.ConfigChangeId = index
+	s.node.ApplyConfigChange(cc)
+	for _, nid := range outgoing {
+		delete(s.members.Addresses, nid)
+		delete(s.members.Observers, nid)
+		delete(s.members.Witnesses, nid)
+		s.members.Removed[nid] = true
+	}
+	s.members.Incoming = make(map[uint64]bool)
+	s.members.Outgoing = make(map[uint64]bool)
+}
+
+func (s *StateMachine) handleJointConfigChange(ent pb.Entry,
+	cc pb.ConfigChange) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ccid := cc.ConfigChangeId
+	upToDateCC := s.isConfChangeUpToDate(cc)
+	joint := s.members.IsJoint()
+	s.updateLastApplied(ent.Index, ent.Term)
+	if cc.Type == pb.EnterJoint {
+		if !upToDateCC {
+			plog.Warningf("%s rejected out-of-order ConfChange ccid %d, type %s, index %d",
+				s.describe(), ccid, cc.Type, ent.Index)
+			return false
+		}
+		if joint {
+			plog.Warningf("%s rejected ConfChange ccid %d, already in joint config",
+				s.describe(), ccid)
+			return false
+		}
+		if !s.isValidJointConfigChange(cc) {
+			return false
+		}
+		if !s.isConfigChangeAuthorized(cc, ent.Index) {
+			return false
+		}
+		s.enterJointLocked(cc, ent.Index)
+		plog.Infof("%s applied ConfChange EnterJoint ccid %d, index %d, "+
+			"incoming %v, outgoing %v", s.describe(), ccid, ent.Index,
+			s.members.Incoming, s.members.Outgoing)
+		return true
+	}
+	if !joint {
+		plog.Warningf("%s rejected LeaveJoint, not in joint config, index %d",
+			s.describe(), ent.Index)
+		return false
+	}
+	s.leaveJointLocked(cc, ent.Index)
+	plog.Infof("%s applied ConfChange LeaveJoint, index %d",
+		s.describe(), ent.Index)
+	return true
+}
+
+func (s *StateMachine) isConfigChangeAuthorized(cc pb.ConfigChange,
+	index uint64) bool {
+	if s.validator == nil {
+		return true
+	}
+	err := s.validator.ValidateConfigChange(cc, deepCopyMembership(*s.members))
+	if err != nil {
+		plog.Warningf("%s rejected ConfChange ccid %d, type %s, index %d, %v",
+			s.describe(), cc.ConfigChangeId, cc.Type, index, err)
+		return false
+	}
+	return true
+}
+
 func (s *StateMachine) applyConfigChangeLocked(cc pb.ConfigChange,
 	index uint64) {
 	s.members.ConfigChangeId = index
@@ -691,14 +1136,29 @@ func (s *StateMachine) applyConfigChangeLocked(cc pb.ConfigChange,
 			nodeAddr = addr
 		}
 		s.members.Addresses[cc.NodeID] = nodeAddr
+	case pb.PromoteObserver:
+		addr, ok := s.members.Observers[cc.NodeID]
+		if !ok {
+			panic("not suppose to reach here")
+		}
+		delete(s.members.Observers, cc.NodeID)
+		s.members.Addresses[cc.NodeID] = addr
 	case pb.AddObserver:
 		if _, ok := s.members.Addresses[cc.NodeID]; ok {
 			panic("not suppose to reach here")
 		}
 		s.members.Observers[cc.NodeID] = string(cc.Address)
+	case pb.AddWitness:
+		_, nok := s.members.Addresses[cc.NodeID]
+		_, ook := s.members.Observers[cc.NodeID]
+		if nok || ook {
+			panic("not suppose to reach here")
+		}
+		s.members.Witnesses[cc.NodeID] = string(cc.Address)
 	case pb.RemoveNode:
 		delete(s.members.Addresses, cc.NodeID)
 		delete(s.members.Observers, cc.NodeID)
+		delete(s.members.Witnesses, cc.NodeID)
 		s.members.Removed[cc.NodeID] = true
 	default:
 		panic("unknown config change type")
@@ -710,20 +1170,37 @@ func (s *StateMachine) handleConfigChange(ent pb.Entry) bool {
 	if err := cc.Unmarshal(ent.Cmd); err != nil {
 		panic(err)
 	}
+	if cc.IsJointConfigChange() {
+		return s.handleJointConfigChange(ent, cc)
+	}
 	if cc.Type == pb.AddNode && len(cc.Address) == 0 {
 		panic("empty address in AddNode request")
 	}
 	accepted := false
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.members.IsJoint() {
+		s.updateLastApplied(ent.Index, ent.Term)
+		plog.Warningf("%s rejected ConfChange ccid %d, type %s, in joint config",
+			s.describe(), cc.ConfigChangeId, cc.Type)
+		return false
+	}
 	// order id requested by user
 	ccid := cc.ConfigChangeId
 	nodeBecomingObserver := s.isAddingNodeAsObserver(cc)
+	witnessBecomingMember := s.isAddingWitnessAsMember(cc)
+	memberBecomingWitness := s.isAddingMemberAsWitness(cc)
 	alreadyMember := s.isAddingExistingMember(cc)
 	addRemovedNode := s.isAddingRemovedNode(cc)
+	promotingNonObserver := s.isPromotingNonObserver(cc)
 	upToDateCC := s.isConfChangeUpToDate(cc)
 	s.updateLastApplied(ent.Index, ent.Term)
-	if upToDateCC && !addRemovedNode && !alreadyMember && !nodeBecomingObserver {
+	if upToDateCC && !addRemovedNode && !alreadyMember &&
+		!nodeBecomingObserver && !witnessBecomingMember &&
+		!memberBecomingWitness && !promotingNonObserver {
+		if !s.isConfigChangeAuthorized(cc, ent.Index) {
+			return false
+		}
 		// current entry index, it will be recorded as the conf change id of the members
 		s.applyConfigChangeLocked(cc, ent.Index)
 		if cc.Type == pb.AddNode {
@@ -737,6 +1214,13 @@ func (s *StateMachine) handleConfigChange(ent pb.Entry) bool {
 			plog.Infof("%s applied ConfChange Add Observer ccid %d, node %s index %d address %s",
 				s.describe(), ccid, logutil.NodeID(cc.NodeID),
 				ent.Index, string(cc.Address))
+		} else if cc.Type == pb.AddWitness {
+			plog.Infof("%s applied ConfChange Add Witness ccid %d, node %s index %d address %s",
+				s.describe(), ccid, logutil.NodeID(cc.NodeID),
+				ent.Index, string(cc.Address))
+		} else if cc.Type == pb.PromoteObserver {
+			plog.Infof("%s applied ConfChange Promote Observer ccid %d, node %s index %d",
+				s.describe(), ccid, logutil.NodeID(cc.NodeID), ent.Index)
 		} else {
 			plog.Panicf("unknown cc.Type value")
 		}
@@ -756,6 +1240,18 @@ func (s *StateMachine) handleConfigChange(ent pb.Entry) bool {
 			plog.Warningf("%s rejected adding existing member as observer ccid %d "+
 				"node id %d, index %d, address %s",
 				s.describe(), ccid, cc.NodeID, ent.Index, cc.Address)
+		} else if witnessBecomingMember {
+			plog.Warningf("%s rejected adding witness as node or observer ccid %d "+
+				"node id %d, index %d, address %s",
+				s.describe(), ccid, cc.NodeID, ent.Index, cc.Address)
+		} else if memberBecomingWitness {
+			plog.Warningf("%s rejected adding existing member as witness ccid %d "+
+				"node id %d, index %d, address %s",
+				s.describe(), ccid, cc.NodeID, ent.Index, cc.Address)
+		} else if promotingNonObserver {
+			plog.Warningf("%s rejected promoting non-observer ccid %d "+
+				"node id %d, index %d",
+				s.describe(), ccid, cc.NodeID, ent.Index)
 		} else {
 			plog.Panicf("config change rejected for unknown reasons")
 		}
@@ -796,10 +1292,10 @@ func (s *StateMachine) handleNoOP(ent pb.Entry) {
 }
 
 // result a tuple of (result, should ignore, rejected)
-func (s *StateMachine) handleUpdate(ent pb.Entry) (uint64, bool, bool) {
+func (s *StateMachine) handleUpdate(ent pb.Entry) (sm.Result, bool, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	var result uint64
+	var result sm.Result
 	var ok bool
 	var session *Session
 	s.updateLastApplied(ent.Index, ent.Term)
@@ -807,14 +1303,14 @@ func (s *StateMachine) handleUpdate(ent pb.Entry) (uint64, bool, bool) {
 		session, ok = s.sm.ClientRegistered(ent.ClientID)
 		if !ok {
 			// client is expected to crash
-			return 0, false, true
+			return sm.Result{}, false, true
 		}
 		s.sm.UpdateRespondedTo(session, ent.RespondedTo)
 		result, responded, updateRequired := s.sm.UpdateRequired(session,
 			ent.SeriesID)
 		if responded {
 			// should ignore. client is expected to timeout
-			return 0, true, false
+			return sm.Result{}, true, false
 		}
 		if !updateRequired {
 			// server responded, client never confirmed
@@ -826,10 +1322,33 @@ func (s *StateMachine) handleUpdate(ent pb.Entry) (uint64, bool, bool) {
 	if !ent.IsNoOPSession() && session == nil {
 		panic("session not found")
 	}
-	result = s.sm.Update(session, ent.SeriesID, ent.Index, ent.Term, ent.Cmd)
+	if s.entryInInitDiskSM(ent.Index) {
+		s.skipUpdate(session, ent)
+		return sm.Result{}, false, false
+	}
+	result = s.sm.Update(session,
+		ent.SeriesID, ent.Index, ent.Term, GetEntryPayload(ent))
 	return result, false, false
 }
 
+// entryInInitDiskSM returns a boolean flag indicating whether the entry
+// identified by the specified index has already been applied into the on disk
+// state machine before it was opened.
+func (s *StateMachine) entryInInitDiskSM(index uint64) bool {
+	if !s.OnDiskStateMachine() {
+		return false
+	}
+	return index <= s.onDiskInitIndex
+}
+
+// skipUpdate records an empty result in the session for an entry that won't
+// be applied again into the on disk state machine.
+func (s *StateMachine) skipUpdate(session *Session, ent pb.Entry) {
+	if session != nil {
+		session.addResponse(RaftSeriesID(ent.SeriesID), sm.Result{})
+	}
+}
+
 func (s *StateMachine) describe() string {
 	return logutil.DescribeSM(s.node.ClusterID(), s.node.NodeID())
 }
@@ -840,6 +1359,9 @@ func deepCopyMembership(m pb.Membership) pb.Membership {
 		Addresses:      make(map[uint64]string),
 		Removed:        make(map[uint64]bool),
 		Observers:      make(map[uint64]string),
+		Witnesses:      make(map[uint64]string),
+		Incoming:       make(map[uint64]bool),
+		Outgoing:       make(map[uint64]bool),
 	}
 	for nid, addr := range m.Addresses {
 		c.Addresses[nid] = addr
@@ -850,6 +1372,15 @@ func deepCopyMembership(m pb.Membership) pb.Membership {
 	for nid, addr := range m.Observers {
 		c.Observers[nid] = addr
 	}
+	for nid, addr := range m.Witnesses {
+		c.Witnesses[nid] = addr
+	}
+	for nid := range m.Incoming {
+		c.Incoming[nid] = true
+	}
+	for nid := range m.Outgoing {
+		c.Outgoing[nid] = true
+	}
 	return c
 }
 
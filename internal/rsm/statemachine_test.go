@@ -18,6 +18,7 @@
 package rsm
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -57,6 +58,7 @@ type testNodeProxy struct {
 	reject             bool
 	accept             bool
 	smResult           uint64
+	smData             []byte
 	index              uint64
 	rejected           bool
 	ignored            bool
@@ -65,6 +67,8 @@ type testNodeProxy struct {
 	addPeerCount       uint64
 	addObserver        bool
 	addObserverCount   uint64
+	addWitness         bool
+	addWitnessCount    uint64
 }
 
 func newTestNodeProxy() *testNodeProxy {
@@ -72,8 +76,9 @@ func newTestNodeProxy() *testNodeProxy {
 }
 
 func (p *testNodeProxy) ApplyUpdate(entry pb.Entry,
-	result uint64, rejected bool, ignored bool, notifyReadClient bool) {
-	p.smResult = result
+	result sm.Result, rejected bool, ignored bool, notifyReadClient bool) {
+	p.smResult = result.Value
+	p.smData = result.Data
 	p.index = entry.Index
 	p.rejected = rejected
 	p.ignored = ignored
@@ -99,6 +104,9 @@ func (p *testNodeProxy) ApplyConfigChange(cc pb.ConfigChange) {
 	} else if cc.Type == pb.AddObserver {
 		p.addObserver = true
 		p.addObserverCount++
+	} else if cc.Type == pb.AddWitness {
+		p.addWitness = true
+		p.addWitnessCount++
 	} else if cc.Type == pb.RemoveNode {
 		p.removePeer = true
 	}
@@ -118,6 +126,7 @@ func (p *testNodeProxy) ClusterID() uint64 { return 1 }
 type testSnapshotter struct {
 	index    uint64
 	dataSize uint64
+	dummy    bool
 }
 
 func newTestSnapshotter() *testSnapshotter {
@@ -138,6 +147,7 @@ func (s *testSnapshotter) GetSnapshot(index uint64) (pb.Snapshot, error) {
 		Membership: pb.Membership{
 			Addresses: address,
 		},
+		Dummy: s.dummy,
 	}
 	return snap, nil
 }
@@ -175,7 +185,7 @@ func (s *testSnapshotter) Save(savable IManagedStateMachine,
 	env := server.NewSnapshotEnv(f, 1, 1, s.index, 1, server.SnapshottingMode)
 	fn := fmt.Sprintf("snapshot-test.%s", snapshotFileSuffix)
 	fp := filepath.Join(testSnapshotterDir, fn)
-	writer, err := NewSnapshotWriter(fp)
+	writer, err := NewSnapshotWriter(fp, pb.NoCompression)
 	if err != nil {
 		return nil, env, err
 	}
@@ -185,8 +195,14 @@ func (s *testSnapshotter) Save(savable IManagedStateMachine,
 		}
 	}()
 	session := meta.Session.Bytes()
-	sz, err := savable.SaveSnapshot(nil, writer, session, nil)
+	var sz uint64
+	if meta.Dummy {
+		sz, err = SaveDummySnapshot(writer, session)
+	} else {
+		sz, err = savable.SaveSnapshot(meta.Ctx, writer, session, nil)
+	}
 	s.dataSize = sz
+	s.dummy = meta.Dummy
 	if err != nil {
 		return nil, env, err
 	}
@@ -196,17 +212,26 @@ func (s *testSnapshotter) Save(savable IManagedStateMachine,
 		Membership: meta.Membership,
 		Index:      meta.Index,
 		Term:       meta.Term,
+		Dummy:      meta.Dummy,
 	}
 	return ss, env, nil
 }
 
+func (s *testSnapshotter) Stream(savable IManagedStateMachine,
+	meta *SnapshotMeta, to uint64) (*pb.Snapshot, *server.SnapshotEnv, error) {
+	if meta.Dummy {
+		panic("streaming a dummy snapshot")
+	}
+	return s.Save(savable, meta)
+}
+
 func runSMTest(t *testing.T, tf func(t *testing.T, sm *StateMachine)) {
 	defer leaktest.AfterTest(t)()
 	store := tests.NewKVTest(1, 1)
 	ds := NewNativeStateMachine(&RegularStateMachine{sm: store}, make(chan struct{}))
 	nodeProxy := newTestNodeProxy()
 	snapshotter := newTestSnapshotter()
-	sm := NewStateMachine(ds, snapshotter, false, nodeProxy)
+	sm := NewStateMachine(ds, snapshotter, false, false, nodeProxy, nil)
 	tf(t, sm)
 }
 
@@ -220,7 +245,7 @@ func runSMTest2(t *testing.T,
 	ds := NewNativeStateMachine(&RegularStateMachine{sm: store}, make(chan struct{}))
 	nodeProxy := newTestNodeProxy()
 	snapshotter := newTestSnapshotter()
-	sm := NewStateMachine(ds, snapshotter, false, nodeProxy)
+	sm := NewStateMachine(ds, snapshotter, false, false, nodeProxy, nil)
 	tf(t, sm, ds, nodeProxy, snapshotter, store)
 }
 
@@ -232,7 +257,7 @@ func TestUpdatesCanBeBatched(t *testing.T) {
 	ds := NewNativeStateMachine(&ConcurrentStateMachine{sm: store}, make(chan struct{}))
 	nodeProxy := newTestNodeProxy()
 	snapshotter := newTestSnapshotter()
-	sm := NewStateMachine(ds, snapshotter, false, nodeProxy)
+	sm := NewStateMachine(ds, snapshotter, false, false, nodeProxy, nil)
 	e1 := pb.Entry{
 		ClientID: 123,
 		SeriesID: client.NoOPSeriesID,
@@ -276,7 +301,7 @@ func TestUpdatesNotBatchedWhenNotAllNoOPUpdates(t *testing.T) {
 	ds := NewNativeStateMachine(&ConcurrentStateMachine{sm: store}, make(chan struct{}))
 	nodeProxy := newTestNodeProxy()
 	snapshotter := newTestSnapshotter()
-	sm := NewStateMachine(ds, snapshotter, false, nodeProxy)
+	sm := NewStateMachine(ds, snapshotter, false, false, nodeProxy, nil)
 	e1 := pb.Entry{
 		ClientID: 123,
 		SeriesID: client.NoOPSeriesID,
@@ -390,7 +415,7 @@ func TestGetMembership(t *testing.T) {
 			},
 			ConfigChangeId: 12345,
 		}
-		m, o, r, cid := sm.GetMembership()
+		m, o, _, r, cid := sm.GetMembership()
 		if cid != 12345 {
 			t.Errorf("unexpected cid value")
 		}
@@ -419,7 +444,7 @@ func TestGetMembershipNodes(t *testing.T) {
 			},
 			ConfigChangeId: 12345,
 		}
-		n, _, _, _ := sm.GetMembership()
+		n, _, _, _, _ := sm.GetMembership()
 		if len(n) != 2 {
 			t.Errorf("unexpected len")
 		}
@@ -672,6 +697,427 @@ func TestObserverPromoteToNodeWithDifferentAddressIsHandled(t *testing.T) {
 	runSMTest2(t, tf)
 }
 
+func TestWitnessCanBeAdded(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		applyConfigChangeEntry(sm,
+			1,
+			pb.AddWitness,
+			4,
+			"localhost:1010",
+			123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if sm.GetLastApplied() != 123 {
+			t.Errorf("last applied %d, want 123", sm.GetLastApplied())
+		}
+		if !nodeProxy.accept {
+			t.Errorf("accept not called")
+		}
+		if nodeProxy.addPeer || nodeProxy.addObserver {
+			t.Errorf("add peer/observer unexpectedly called")
+		}
+		if !nodeProxy.addWitness {
+			t.Errorf("add witness not called")
+		}
+		_, _, witnesses, _, _ := sm.GetMembership()
+		if v, ok := witnesses[4]; !ok || v != "localhost:1010" {
+			t.Errorf("witness not recorded in membership")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestWitnessCanNotBePromotedToNodeOrObserver(t *testing.T) {
+	for _, cct := range []pb.ConfigChangeType{pb.AddNode, pb.AddObserver} {
+		tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+			nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+			sm.members.Witnesses[4] = "localhost:1010"
+			applyConfigChangeEntry(sm,
+				1,
+				cct,
+				4,
+				"localhost:1010",
+				123)
+			batch := make([]Commit, 0, 8)
+			sm.Handle(batch, nil)
+			if !nodeProxy.reject {
+				t.Errorf("invalid cc not rejected")
+			}
+			if len(sm.members.Addresses) != 0 || len(sm.members.Observers) != 0 {
+				t.Errorf("witness unexpectedly converted")
+			}
+		}
+		runSMTest2(t, tf)
+	}
+}
+
+func TestMemberCanNotBeAddedAsWitness(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[4] = "localhost:1010"
+		applyConfigChangeEntry(sm,
+			1,
+			pb.AddWitness,
+			4,
+			"localhost:1010",
+			123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject {
+			t.Errorf("invalid cc not rejected")
+		}
+		if nodeProxy.addWitness {
+			t.Errorf("add witness unexpectedly called")
+		}
+		if len(sm.members.Witnesses) != 0 {
+			t.Errorf("node unexpectedly converted to witness")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestAddingRemovedNodeAsWitnessWillBeRejected(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Removed[2] = true
+		applyConfigChangeEntry(sm,
+			1,
+			pb.AddWitness,
+			2,
+			"a1",
+			123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject {
+			t.Errorf("not rejected")
+		}
+		if nodeProxy.addWitness {
+			t.Errorf("add witness unexpectedly called")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestWitnessCanBeRemoved(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Witnesses[4] = "localhost:1010"
+		applyConfigChangeEntry(sm,
+			1,
+			pb.RemoveNode,
+			4,
+			"",
+			123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.accept {
+			t.Errorf("accept not called")
+		}
+		if len(sm.members.Witnesses) != 0 {
+			t.Errorf("witness not removed")
+		}
+		if _, ok := sm.members.Removed[4]; !ok {
+			t.Errorf("removed witness not recorded as removed")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestObserverCanBePromoted(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1001"
+		sm.members.Observers[2] = "localhost:1002"
+		applyConfigChangeEntry(sm,
+			1,
+			pb.PromoteObserver,
+			2,
+			"",
+			123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.accept {
+			t.Errorf("accept not called")
+		}
+		if !nodeProxy.applyConfChange {
+			t.Errorf("config change not applied")
+		}
+		if len(sm.members.Observers) != 0 {
+			t.Errorf("observer not removed")
+		}
+		if sm.members.Addresses[2] != "localhost:1002" {
+			t.Errorf("address not moved to members")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestPromotingNonObserverWillBeRejected(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1001"
+		sm.members.Witnesses[3] = "localhost:1003"
+		for _, nid := range []uint64{1, 2, 3} {
+			nodeProxy.reject = false
+			applyConfigChangeEntry(sm,
+				1,
+				pb.PromoteObserver,
+				nid,
+				"",
+				123+nid)
+			batch := make([]Commit, 0, 8)
+			sm.Handle(batch, nil)
+			if !nodeProxy.reject {
+				t.Errorf("promoting %d not rejected", nid)
+			}
+		}
+		if nodeProxy.applyConfChange {
+			t.Errorf("config change unexpectedly applied")
+		}
+		if len(sm.members.Addresses) != 1 {
+			t.Errorf("members unexpectedly changed")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func applyJointConfigChangeEntry(sm *StateMachine, configChangeID uint64,
+	configType pb.ConfigChangeType, changes []pb.ConfigChange, index uint64) {
+	cc := pb.ConfigChange{
+		ConfigChangeId: configChangeID,
+		Type:           configType,
+		Changes:        changes,
+	}
+	data, err := cc.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	e := pb.Entry{
+		Cmd:   data,
+		Type:  pb.ConfigChangeEntry,
+		Index: index,
+		Term:  1,
+	}
+	sm.index = index - 1
+	sm.CommitC() <- Commit{Entries: []pb.Entry{e}}
+}
+
+func getTestJointConfigChanges() []pb.ConfigChange {
+	return []pb.ConfigChange{
+		{Type: pb.AddNode, NodeID: 4, Address: "localhost:1004"},
+		{Type: pb.AddNode, NodeID: 5},
+		{Type: pb.RemoveNode, NodeID: 2},
+	}
+}
+
+func TestJointConfigChangeCanBeApplied(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1001"
+		sm.members.Addresses[2] = "localhost:1002"
+		sm.members.Observers[5] = "localhost:1005"
+		applyJointConfigChangeEntry(sm,
+			1, pb.EnterJoint, getTestJointConfigChanges(), 123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.accept {
+			t.Fatalf("accept not called")
+		}
+		if !sm.members.IsJoint() {
+			t.Fatalf("not in joint config")
+		}
+		if len(sm.members.Addresses) != 4 {
+			t.Errorf("addresses %v, want 4 members", sm.members.Addresses)
+		}
+		if sm.members.Addresses[5] != "localhost:1005" {
+			t.Errorf("observer address not used for the promoted node")
+		}
+		if len(sm.members.Observers) != 0 {
+			t.Errorf("observer not promoted")
+		}
+		if len(sm.members.Incoming) != 2 || len(sm.members.Outgoing) != 1 {
+			t.Errorf("incoming %v, outgoing %v",
+				sm.members.Incoming, sm.members.Outgoing)
+		}
+		if sm.members.ConfigChangeId != 123 {
+			t.Errorf("ccid %d, want 123", sm.members.ConfigChangeId)
+		}
+		nodeProxy.accept = false
+		applyJointConfigChangeEntry(sm, 0, pb.LeaveJoint, nil, 124)
+		sm.Handle(batch, nil)
+		if !nodeProxy.accept {
+			t.Fatalf("accept not called")
+		}
+		if sm.members.IsJoint() {
+			t.Errorf("still in joint config")
+		}
+		if _, ok := sm.members.Addresses[2]; ok {
+			t.Errorf("outgoing node not removed")
+		}
+		if _, ok := sm.members.Removed[2]; !ok {
+			t.Errorf("outgoing node not recorded as removed")
+		}
+		if len(sm.members.Addresses) != 3 {
+			t.Errorf("addresses %v, want 3 members", sm.members.Addresses)
+		}
+		if sm.GetLastApplied() != 124 {
+			t.Errorf("last applied %d, want 124", sm.GetLastApplied())
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestInvalidJointConfigChangeIsRejected(t *testing.T) {
+	tests := []struct {
+		changes []pb.ConfigChange
+	}{
+		{nil},
+		{[]pb.ConfigChange{{Type: pb.AddNode, NodeID: 1, Address: "a1"}}},
+		{[]pb.ConfigChange{{Type: pb.AddNode, NodeID: 3}}},
+		{[]pb.ConfigChange{{Type: pb.AddObserver, NodeID: 1, Address: "a1"}}},
+		{[]pb.ConfigChange{{Type: pb.AddWitness, NodeID: 1, Address: "a1"}}},
+		{[]pb.ConfigChange{{Type: pb.AddNode, NodeID: 6, Address: "a6"}}},
+		{[]pb.ConfigChange{{Type: pb.LeaveJoint}}},
+		{[]pb.ConfigChange{
+			{Type: pb.AddNode, NodeID: 3, Address: "a3"},
+			{Type: pb.RemoveNode, NodeID: 3},
+		}},
+		{[]pb.ConfigChange{
+			{Type: pb.RemoveNode, NodeID: 1},
+			{Type: pb.RemoveNode, NodeID: 2},
+		}},
+	}
+	for idx, tt := range tests {
+		tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+			nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+			sm.members.Addresses[1] = "localhost:1001"
+			sm.members.Addresses[2] = "localhost:1002"
+			sm.members.Removed[6] = true
+			applyJointConfigChangeEntry(sm, 1, pb.EnterJoint, tt.changes, 123)
+			batch := make([]Commit, 0, 8)
+			sm.Handle(batch, nil)
+			if !nodeProxy.reject {
+				t.Errorf("%d, invalid joint cc not rejected", idx)
+			}
+			if nodeProxy.applyConfChange {
+				t.Errorf("%d, config change unexpectedly applied", idx)
+			}
+			if sm.members.IsJoint() {
+				t.Errorf("%d, unexpectedly entered joint config", idx)
+			}
+		}
+		runSMTest2(t, tf)
+	}
+}
+
+func TestConfigChangeIsRejectedInJointConfig(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1001"
+		sm.members.Addresses[2] = "localhost:1002"
+		sm.members.Outgoing[2] = true
+		applyConfigChangeEntry(sm, 1, pb.AddNode, 3, "localhost:1003", 123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject {
+			t.Errorf("config change not rejected")
+		}
+		if nodeProxy.applyConfChange {
+			t.Errorf("config change unexpectedly applied")
+		}
+		if sm.GetLastApplied() != 123 {
+			t.Errorf("last applied %d, want 123", sm.GetLastApplied())
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+type testConfigChangeValidator struct {
+	members []pb.Membership
+}
+
+func (v *testConfigChangeValidator) ValidateConfigChange(cc pb.ConfigChange,
+	members pb.Membership) error {
+	v.members = append(v.members, members)
+	for _, c := range append([]pb.ConfigChange{cc}, cc.Changes...) {
+		if c.Type == pb.AddNode && c.Address != "localhost:1003" {
+			return errors.New("unknown address")
+		}
+	}
+	return nil
+}
+
+func TestConfigChangeCanBeRejectedByValidator(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		validator := &testConfigChangeValidator{}
+		sm.validator = validator
+		sm.members.Addresses[1] = "localhost:1001"
+		applyConfigChangeEntry(sm, 1, pb.AddNode, 4, "localhost:1004", 123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject || nodeProxy.applyConfChange {
+			t.Fatalf("config change not rejected")
+		}
+		if _, ok := sm.members.Addresses[4]; ok {
+			t.Errorf("members unexpectedly updated")
+		}
+		if sm.GetLastApplied() != 123 {
+			t.Errorf("last applied %d, want 123", sm.GetLastApplied())
+		}
+		applyConfigChangeEntry(sm, 1, pb.AddNode, 3, "localhost:1003", 124)
+		sm.Handle(batch, nil)
+		if !nodeProxy.accept || !nodeProxy.applyConfChange {
+			t.Fatalf("config change not accepted")
+		}
+		if len(validator.members) != 2 {
+			t.Fatalf("validator invoked %d times, want 2", len(validator.members))
+		}
+		m := validator.members[1]
+		if len(m.Addresses) != 1 || m.Addresses[1] != "localhost:1001" {
+			t.Errorf("unexpected membership %v", m.Addresses)
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestJointConfigChangeCanBeRejectedByValidator(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.validator = &testConfigChangeValidator{}
+		sm.members.Addresses[1] = "localhost:1001"
+		sm.members.Addresses[2] = "localhost:1002"
+		changes := []pb.ConfigChange{
+			{Type: pb.AddNode, NodeID: 4, Address: "localhost:1004"},
+		}
+		applyJointConfigChangeEntry(sm, 1, pb.EnterJoint, changes, 123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject {
+			t.Errorf("joint config change not rejected")
+		}
+		if sm.members.IsJoint() {
+			t.Errorf("unexpectedly in joint config")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
+func TestLeaveJointIsRejectedWhenNotInJointConfig(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1001"
+		applyJointConfigChangeEntry(sm, 0, pb.LeaveJoint, nil, 123)
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if !nodeProxy.reject {
+			t.Errorf("leave joint not rejected")
+		}
+	}
+	runSMTest2(t, tf)
+}
+
 func TestHandleConfChangeRemoveNode(t *testing.T) {
 	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
 		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
@@ -900,7 +1346,7 @@ func TestSnapshotCanBeApplied(t *testing.T) {
 		store.(*tests.KVTest).KVStore["test-key2"] = "test-value2"
 		sm.index = 3
 		hash1 := sm.GetHash()
-		ss, _, err := sm.SaveSnapshot()
+		ss, _, err := sm.SaveSnapshot(SnapshotRequest{})
 		if err != nil {
 			t.Fatalf("failed to make snapshot %v", err)
 		}
@@ -912,7 +1358,7 @@ func TestSnapshotCanBeApplied(t *testing.T) {
 		ds2 := NewNativeStateMachine(&RegularStateMachine{sm: store2}, make(chan struct{}))
 		nodeProxy2 := newTestNodeProxy()
 		snapshotter2 := newTestSnapshotter()
-		sm2 := NewStateMachine(ds2, snapshotter2, false, nodeProxy2)
+		sm2 := NewStateMachine(ds2, snapshotter2, false, false, nodeProxy2, nil)
 		if len(sm2.members.Addresses) != 0 {
 			t.Errorf("unexpected member length")
 		}
@@ -952,7 +1398,7 @@ func TestMembersAreSavedWhenMakingSnapshot(t *testing.T) {
 		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
 		sm.members.Addresses[1] = "localhost:1"
 		sm.members.Addresses[2] = "localhost:2"
-		ss, _, err := sm.SaveSnapshot()
+		ss, _, err := sm.SaveSnapshot(SnapshotRequest{})
 		if err != nil {
 			t.Errorf("failed to make snapshot %v", err)
 		}
@@ -986,11 +1432,11 @@ func TestSnapshotTwiceIsHandled(t *testing.T) {
 			t.Errorf("last applied %d, want %d",
 				sm.GetLastApplied(), e.Index)
 		}
-		_, _, err := sm.SaveSnapshot()
+		_, _, err := sm.SaveSnapshot(SnapshotRequest{})
 		if err != nil {
 			t.Errorf("failed to make snapshot %v", err)
 		}
-		_, _, err = sm.SaveSnapshot()
+		_, _, err = sm.SaveSnapshot(SnapshotRequest{})
 		if err != raft.ErrSnapshotOutOfDate {
 			t.Errorf("snapshot twice completed, %v", err)
 		}
@@ -998,6 +1444,36 @@ func TestSnapshotTwiceIsHandled(t *testing.T) {
 	runSMTest2(t, tf)
 }
 
+func TestExportedSnapshotIsNotAffectedByPreviousSnapshot(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
+		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
+		sm.members.Addresses[1] = "localhost:1"
+		batch := make([]Commit, 0, 8)
+		e := applyTestEntry(sm, 12345, client.NoOPSeriesID, 1, 0, getTestKVData())
+		sm.Handle(batch, nil)
+		if _, _, err := sm.SaveSnapshot(SnapshotRequest{}); err != nil {
+			t.Fatalf("failed to make snapshot %v", err)
+		}
+		req := SnapshotRequest{Type: ExportedSnapshot, Path: testSnapshotterDir}
+		ss, _, err := sm.SaveSnapshot(req)
+		if err != nil {
+			t.Fatalf("failed to export snapshot %v", err)
+		}
+		if ss.Index != e.Index {
+			t.Errorf("index %d, want %d", ss.Index, e.Index)
+		}
+		e = applyTestEntry(sm, 12345, client.NoOPSeriesID, 2, 0, getTestKVData())
+		sm.Handle(batch, nil)
+		if _, _, err := sm.SaveSnapshot(req); err != nil {
+			t.Fatalf("failed to export snapshot %v", err)
+		}
+		if sm.snapshotIndex != e.Index-1 {
+			t.Errorf("snapshot index %d, want %d", sm.snapshotIndex, e.Index-1)
+		}
+	}
+	runSMTest2(t, tf)
+}
+
 func applySessionRegisterEntry(sm *StateMachine,
 	clientID uint64, index uint64) pb.Entry {
 	e := pb.Entry{
@@ -1225,6 +1701,45 @@ func TestDuplicatedUpdateWillNotBeAppliedTwice(t *testing.T) {
 	runSMTest2(t, tf)
 }
 
+type dataResultKVTest struct {
+	*tests.KVTest
+}
+
+func (s *dataResultKVTest) Update(data []byte) sm.Result {
+	result := s.KVTest.Update(data)
+	result.Data = []byte(fmt.Sprintf("result-%d", s.Count))
+	return result
+}
+
+func TestDuplicatedUpdateReturnsCachedResultData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store := &dataResultKVTest{tests.NewKVTest(1, 1).(*tests.KVTest)}
+	ds := NewNativeStateMachine(&RegularStateMachine{sm: store}, make(chan struct{}))
+	nodeProxy := newTestNodeProxy()
+	sm := NewStateMachine(ds, newTestSnapshotter(), false, false, nodeProxy, nil)
+	applySessionRegisterEntry(sm, 12345, 789)
+	batch := make([]Commit, 0, 8)
+	sm.Handle(batch, nil)
+	data := getTestKVData()
+	applyTestEntry(sm, 12345, 1, 790, 0, data)
+	sm.Handle(batch, nil)
+	if string(nodeProxy.smData) != "result-1" {
+		t.Fatalf("result data %s, want result-1", nodeProxy.smData)
+	}
+	nodeProxy.smData = nil
+	applyTestEntry(sm, 12345, 1, 791, 0, data)
+	sm.Handle(batch, nil)
+	if store.Count != 1 {
+		t.Errorf("store update invoked twice, not expected")
+	}
+	if nodeProxy.smResult != uint64(len(data)) {
+		t.Errorf("smResult %d, want %d", nodeProxy.smResult, len(data))
+	}
+	if string(nodeProxy.smData) != "result-1" {
+		t.Errorf("cached result data %s, want result-1", nodeProxy.smData)
+	}
+}
+
 func TestRespondedUpdateWillNotBeAppliedTwice(t *testing.T) {
 	tf := func(t *testing.T, sm *StateMachine, ds IManagedStateMachine,
 		nodeProxy *testNodeProxy, snapshotter *testSnapshotter, store sm.IStateMachine) {
@@ -1322,3 +1837,189 @@ func TestGetSnapshotFilesReturnFiles(t *testing.T) {
 		t.Errorf("unexpected file value")
 	}
 }
+
+func runOnDiskSMTest(t *testing.T, initialApplied uint64,
+	tf func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM)) {
+	defer leaktest.AfterTest(t)()
+	createTestDir()
+	defer removeTestDir()
+	store := tests.NewFakeDiskSM(initialApplied)
+	ds := NewNativeStateMachine(NewOnDiskStateMachine(store), make(chan struct{}))
+	nodeProxy := newTestNodeProxy()
+	snapshotter := newTestSnapshotter()
+	sm := NewStateMachine(ds, snapshotter, false, false, nodeProxy, nil)
+	index, err := sm.OpenOnDiskStateMachine()
+	if err != nil {
+		t.Fatalf("failed to open %v", err)
+	}
+	if index != initialApplied {
+		t.Fatalf("index %d, want %d", index, initialApplied)
+	}
+	tf(t, sm, store)
+}
+
+func getNoOPSessionEntries(first uint64, last uint64) []pb.Entry {
+	ents := make([]pb.Entry, 0)
+	for i := first; i <= last; i++ {
+		e := pb.Entry{
+			ClientID: 123,
+			SeriesID: client.NoOPSeriesID,
+			Index:    i,
+			Term:     1,
+		}
+		ents = append(ents, e)
+	}
+	return ents
+}
+
+func TestOnDiskSMSkipsAppliedEntriesInBatchedUpdate(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM) {
+		sm.CommitC() <- Commit{Entries: getNoOPSessionEntries(1, 5)}
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if sm.GetLastApplied() != 5 {
+			t.Errorf("last applied %d, want 5", sm.GetLastApplied())
+		}
+		if store.GetHash() != 2 {
+			t.Errorf("update count %d, want 2", store.GetHash())
+		}
+	}
+	runOnDiskSMTest(t, 3, tf)
+}
+
+func TestOnDiskSMSkipsAppliedEntries(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM) {
+		ents := getNoOPSessionEntries(1, 5)
+		ents[0].SeriesID = client.SeriesIDForRegister
+		sm.CommitC() <- Commit{Entries: ents}
+		batch := make([]Commit, 0, 8)
+		sm.Handle(batch, nil)
+		if sm.GetLastApplied() != 5 {
+			t.Errorf("last applied %d, want 5", sm.GetLastApplied())
+		}
+		if store.GetHash() != 1 {
+			t.Errorf("update count %d, want 1", store.GetHash())
+		}
+		if _, ok := sm.sm.ClientRegistered(123); !ok {
+			t.Errorf("session not registered")
+		}
+	}
+	runOnDiskSMTest(t, 4, tf)
+}
+
+func TestOnDiskSMSavesDummySnapshot(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM) {
+		sm.members.Addresses[1] = "localhost:1"
+		sm.CommitC() <- Commit{Entries: getNoOPSessionEntries(1, 5)}
+		sm.Handle(make([]Commit, 0, 8), nil)
+		ss, _, err := sm.SaveSnapshot(SnapshotRequest{})
+		if err != nil {
+			t.Fatalf("failed to save snapshot %v", err)
+		}
+		if !ss.Dummy {
+			t.Errorf("not a dummy snapshot")
+		}
+		if ss.Index != 5 {
+			t.Errorf("index %d, want 5", ss.Index)
+		}
+		if store.SyncCount != 1 {
+			t.Errorf("sync count %d, want 1", store.SyncCount)
+		}
+		// restart with all entries persisted by the on disk state machine
+		store2 := tests.NewFakeDiskSM(5)
+		ds2 := NewNativeStateMachine(NewOnDiskStateMachine(store2),
+			make(chan struct{}))
+		sm2 := NewStateMachine(ds2, sm.snapshotter, false, false, newTestNodeProxy(), nil)
+		if _, err := sm2.OpenOnDiskStateMachine(); err != nil {
+			t.Fatalf("open failed %v", err)
+		}
+		index, err := sm2.RecoverFromSnapshot(Commit{Index: ss.Index})
+		if err != nil {
+			t.Fatalf("recover from snapshot failed %v", err)
+		}
+		if index != 5 || sm2.GetLastApplied() != 5 {
+			t.Errorf("unexpected index %d", index)
+		}
+	}
+	runOnDiskSMTest(t, 0, tf)
+}
+
+func TestOnDiskSMCanExportFullSnapshot(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM) {
+		sm.members.Addresses[1] = "localhost:1"
+		sm.CommitC() <- Commit{Entries: getNoOPSessionEntries(1, 5)}
+		sm.Handle(make([]Commit, 0, 8), nil)
+		req := SnapshotRequest{Type: ExportedSnapshot, Path: testSnapshotterDir}
+		ss, _, err := sm.SaveSnapshot(req)
+		if err != nil {
+			t.Fatalf("failed to export snapshot %v", err)
+		}
+		if ss.Dummy {
+			t.Errorf("unexpected dummy snapshot")
+		}
+		if ss.Index != 5 {
+			t.Errorf("index %d, want 5", ss.Index)
+		}
+		if sm.snapshotIndex != 0 {
+			t.Errorf("snapshot index %d, want 0", sm.snapshotIndex)
+		}
+	}
+	runOnDiskSMTest(t, 0, tf)
+}
+
+func TestOnDiskSMCanStreamSnapshot(t *testing.T) {
+	tf := func(t *testing.T, sm *StateMachine, store *tests.FakeDiskSM) {
+		sm.members.Addresses[1] = "localhost:1"
+		sm.CommitC() <- Commit{Entries: getNoOPSessionEntries(1, 5)}
+		sm.Handle(make([]Commit, 0, 8), nil)
+		ss, _, err := sm.StreamSnapshot(2)
+		if err != nil {
+			t.Fatalf("failed to stream snapshot %v", err)
+		}
+		if ss.Dummy {
+			t.Errorf("unexpected dummy snapshot")
+		}
+		store2 := tests.NewFakeDiskSM(1)
+		ds2 := NewNativeStateMachine(NewOnDiskStateMachine(store2),
+			make(chan struct{}))
+		sm2 := NewStateMachine(ds2, sm.snapshotter, false, false, newTestNodeProxy(), nil)
+		if _, err := sm2.OpenOnDiskStateMachine(); err != nil {
+			t.Fatalf("open failed %v", err)
+		}
+		if _, err := sm2.RecoverFromSnapshot(Commit{Index: ss.Index}); err != nil {
+			t.Fatalf("recover from snapshot failed %v", err)
+		}
+		if store2.GetHash() != 5 {
+			t.Errorf("state not recovered, count %d", store2.GetHash())
+		}
+	}
+	runOnDiskSMTest(t, 0, tf)
+}
+
+func TestWitnessSMAppliesMetadataEntriesAndSavesDummySnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	createTestDir()
+	defer removeTestDir()
+	ds := NewNativeStateMachine(NewWitnessStateMachine(), make(chan struct{}))
+	sm := NewStateMachine(ds, newTestSnapshotter(), false, true, newTestNodeProxy(), nil)
+	sm.members.Addresses[1] = "localhost:1"
+	ents := make([]pb.Entry, 0)
+	for i := uint64(1); i <= 5; i++ {
+		ents = append(ents, pb.Entry{Type: pb.MetadataEntry, Index: i, Term: 1})
+	}
+	sm.CommitC() <- Commit{Entries: ents}
+	sm.Handle(make([]Commit, 0, 8), nil)
+	if sm.GetLastApplied() != 5 {
+		t.Errorf("last applied %d, want 5", sm.GetLastApplied())
+	}
+	ss, _, err := sm.SaveSnapshot(SnapshotRequest{})
+	if err != nil {
+		t.Fatalf("failed to save snapshot %v", err)
+	}
+	if !ss.Dummy {
+		t.Errorf("not a dummy snapshot")
+	}
+	if ss.Index != 5 {
+		t.Errorf("index %d, want 5", ss.Index)
+	}
+}
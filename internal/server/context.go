@@ -166,21 +166,68 @@ func (sc *Context) PrepareSnapshotDir(deploymentID uint64,
 }
 
 // CheckNodeHostDir checks whether NodeHost dir is owned by the
-// current nodehost.
-func (sc *Context) CheckNodeHostDir(did uint64, addr string) {
+// current nodehost and whether it was created using the specified type of
+// LogDB. The LogDB type is the key-value store type of the default LogDB or
+// the name of the LogDB created by the LogDBFactory. It is recorded when the
+// NodeHost dir doesn't have its LogDB type recorded yet, the LogDB type check
+// is skipped when logdbType is empty.
+func (sc *Context) CheckNodeHostDir(did uint64,
+	addr string, logdbType string) {
 	dirs, lldirs := sc.GetLogDBDirs(did)
 	for i := 0; i < len(dirs); i++ {
-		sc.checkDirAddressMatch(dirs[i], did, addr)
-		sc.checkDirAddressMatch(lldirs[i], did, addr)
+		sc.checkDirAddressMatch(dirs[i], did, addr, logdbType)
+		sc.checkDirAddressMatch(lldirs[i], did, addr, logdbType)
 	}
 }
 
+// GetNodeHostDirLogDBType returns the LogDB type recorded in the NodeHost dir
+// status found in dir. An empty string is returned when there is no NodeHost
+// dir status or no LogDB type has been recorded.
+func GetNodeHostDirLogDBType(dir string) (string, error) {
+	if !fileutil.HasFlagFile(dir, dragonboatAddressFilename) {
+		return "", nil
+	}
+	status := raftpb.RaftDataStatus{}
+	err := fileutil.GetFlagFileContent(dir, dragonboatAddressFilename, &status)
+	if err != nil {
+		return "", err
+	}
+	return status.KvStoreType, nil
+}
+
+// CopyNodeHostDirStatus copies the NodeHost dir status found in srcDir to
+// dstDir with the recorded LogDB type set to logdbType. It is used by offline
+// tools that move LogDB data to a different dir.
+func CopyNodeHostDirStatus(srcDir string,
+	dstDir string, logdbType string) error {
+	status := raftpb.RaftDataStatus{}
+	err := fileutil.GetFlagFileContent(srcDir, dragonboatAddressFilename, &status)
+	if err != nil {
+		return err
+	}
+	status.KvStoreType = logdbType
+	return fileutil.CreateFlagFile(dstDir, dragonboatAddressFilename, &status)
+}
+
 func (sc *Context) getDeploymentIDSubDirName(did uint64) string {
 	return fmt.Sprintf("%020d", did)
 }
 
+// replaceDirStatus atomically replaces the NodeHost dir status found in dir.
+func replaceDirStatus(dir string, status *raftpb.RaftDataStatus) error {
+	tmp := dragonboatAddressFilename + ".tmp"
+	if err := fileutil.CreateFlagFile(dir, tmp, status); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(dir, tmp),
+		filepath.Join(dir, dragonboatAddressFilename)); err != nil {
+		return err
+	}
+	return fileutil.SyncDir(dir)
+}
+
 func (sc *Context) checkDirAddressMatch(dir string,
-	deploymentID uint64, addr string) {
+	deploymentID uint64, addr string, logdbType string) {
 	fp := filepath.Join(dir, dragonboatAddressFilename)
 	se := func(s1 string, s2 string) bool {
 		return strings.ToLower(strings.TrimSpace(s1)) ==
@@ -188,9 +235,10 @@ func (sc *Context) checkDirAddressMatch(dir string,
 	}
 	if _, err := os.Stat(fp); os.IsNotExist(err) {
 		status := raftpb.RaftDataStatus{
-			Address:  addr,
-			BinVer:   raftio.LogDBBinVersion,
-			HardHash: settings.Hard.Hash(),
+			Address:     addr,
+			BinVer:      raftio.LogDBBinVersion,
+			HardHash:    settings.Hard.Hash(),
+			KvStoreType: logdbType,
 		}
 		err = fileutil.CreateFlagFile(dir, dragonboatAddressFilename, &status)
 		if err != nil {
@@ -213,5 +261,18 @@ func (sc *Context) checkDirAddressMatch(dir string,
 		if status.HardHash != settings.Hard.Hash() {
 			plog.Panicf("had hash mismatch, hard settings changed?")
 		}
+		if len(logdbType) == 0 {
+			return
+		}
+		if len(status.KvStoreType) == 0 {
+			// dirs created by older versions don't have the LogDB type recorded
+			status.KvStoreType = logdbType
+			if err := replaceDirStatus(dir, &status); err != nil {
+				panic(err)
+			}
+		} else if status.KvStoreType != logdbType {
+			plog.Panicf("nodehost data dirs created using %s LogDB, "+
+				"but %s is configured", status.KvStoreType, logdbType)
+		}
 	}
 }
@@ -41,6 +41,30 @@ func getTestNodeHostConfig() config.NodeHostConfig {
 }
 
 func TestNodeHostDirectoryWorksWhenEverythingMatches(t *testing.T) {
+	defer os.RemoveAll(singleNodeHostTestDir)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("panic not expected")
+		}
+	}()
+	c := getTestNodeHostConfig()
+	ctx := NewContext(c)
+	ctx.CreateNodeHostDir(testDeploymentID)
+	dirs, _ := ctx.GetLogDBDirs(testDeploymentID)
+	status := raftpb.RaftDataStatus{
+		Address:     testAddress,
+		BinVer:      raftio.LogDBBinVersion,
+		HardHash:    settings.Hard.Hash(),
+		KvStoreType: "rocksdb",
+	}
+	err := fileutil.CreateFlagFile(dirs[0], dragonboatAddressFilename, &status)
+	if err != nil {
+		t.Errorf("failed to create flag file %v", err)
+	}
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "rocksdb")
+}
+
+func TestNodeHostDirectoryWithoutKVStoreTypeIsBackfilled(t *testing.T) {
 	defer os.RemoveAll(singleNodeHostTestDir)
 	defer func() {
 		if r := recover(); r != nil {
@@ -60,7 +84,78 @@ func TestNodeHostDirectoryWorksWhenEverythingMatches(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to create flag file %v", err)
 	}
-	ctx.CheckNodeHostDir(testDeploymentID, testAddress)
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "pebble")
+	logdbType, err := GetNodeHostDirLogDBType(dirs[0])
+	if err != nil {
+		t.Fatalf("failed to get LogDB type %v", err)
+	}
+	if logdbType != "pebble" {
+		t.Errorf("LogDB type %s, want pebble", logdbType)
+	}
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "")
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("didn't panic when LogDB type does not match")
+			}
+		}()
+		ctx.CheckNodeHostDir(testDeploymentID, testAddress, "rocksdb")
+	}()
+}
+
+func TestNodeHostDirectoryRecordsKVStoreType(t *testing.T) {
+	defer os.RemoveAll(singleNodeHostTestDir)
+	c := getTestNodeHostConfig()
+	ctx := NewContext(c)
+	ctx.CreateNodeHostDir(testDeploymentID)
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "leveldb")
+	dirs, _ := ctx.GetLogDBDirs(testDeploymentID)
+	status := raftpb.RaftDataStatus{}
+	err := fileutil.GetFlagFileContent(dirs[0], dragonboatAddressFilename, &status)
+	if err != nil {
+		t.Fatalf("failed to get flag file content %v", err)
+	}
+	if status.KvStoreType != "leveldb" {
+		t.Errorf("kv store type %s, want leveldb", status.KvStoreType)
+	}
+}
+
+func TestNodeHostDirStatusCanBeCopied(t *testing.T) {
+	defer os.RemoveAll(singleNodeHostTestDir)
+	c := getTestNodeHostConfig()
+	ctx := NewContext(c)
+	ctx.CreateNodeHostDir(testDeploymentID)
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "rocksdb")
+	dirs, _ := ctx.GetLogDBDirs(testDeploymentID)
+	dstDir := singleNodeHostTestDir + "/copied"
+	if err := fileutil.MkdirAll(dstDir); err != nil {
+		t.Fatalf("failed to create dir %v", err)
+	}
+	if err := CopyNodeHostDirStatus(dirs[0], dstDir, "pebble"); err != nil {
+		t.Fatalf("failed to copy status %v", err)
+	}
+	status := raftpb.RaftDataStatus{}
+	err := fileutil.GetFlagFileContent(dstDir, dragonboatAddressFilename, &status)
+	if err != nil {
+		t.Fatalf("failed to get flag file content %v", err)
+	}
+	if status.Address != testAddress || status.KvStoreType != "pebble" {
+		t.Errorf("unexpected status %v", status)
+	}
+}
+
+func TestNodeHostDirectoryDetectsMismatchedKVStoreType(t *testing.T) {
+	defer os.RemoveAll(singleNodeHostTestDir)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic when kv store type does not match")
+		}
+	}()
+	c := getTestNodeHostConfig()
+	ctx := NewContext(c)
+	ctx.CreateNodeHostDir(testDeploymentID)
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "rocksdb")
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "pebble")
 }
 
 func TestNodeHostDirectoryDetectsMismatchedBinVer(t *testing.T) {
@@ -90,5 +185,5 @@ func testNodeHostDirectoryDetectsMismatches(t *testing.T, addr string, binVer ui
 	if err != nil {
 		t.Errorf("failed to create flag file %v", err)
 	}
-	ctx.CheckNodeHostDir(testDeploymentID, testAddress)
+	ctx.CheckNodeHostDir(testDeploymentID, testAddress, "")
 }
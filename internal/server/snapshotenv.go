@@ -29,6 +29,7 @@ import (
 var (
 	genTmpDirSuffix    = "generating"
 	recvTmpDirSuffix   = "receiving"
+	strmTmpDirSuffix   = "streaming"
 	snapshotFileSuffix = "gbsnap"
 )
 
@@ -44,6 +45,9 @@ const (
 	SnapshottingMode Mode = iota
 	// ReceivingMode is the mode used when receiving snapshots from remote nodes.
 	ReceivingMode
+	// StreamingMode is the mode used when generating snapshots to be streamed
+	// to remote nodes.
+	StreamingMode
 )
 
 // GetSnapshotDirName returns the snapshot dir name for the snapshot captured
@@ -101,6 +105,8 @@ func NewSnapshotEnv(f GetSnapshotDirFunc,
 	var tmpSuffix string
 	if mode == SnapshottingMode {
 		tmpSuffix = genTmpDirSuffix
+	} else if mode == StreamingMode {
+		tmpSuffix = strmTmpDirSuffix
 	} else {
 		tmpSuffix = recvTmpDirSuffix
 	}
@@ -68,9 +68,11 @@ type hard struct {
 	// changes, typically each in its own goroutine. This turn determines how
 	// nodes are partitioned to different entry cache and rdb instances.
 	StepEngineWorkerCount uint64
-	// LogDBPoolSize defines how many rdb instances to use. We use multiple rdb
-	// instance so different disks can be used together to get better combined IO
-	// performance.
+	// LogDBPoolSize defines the number of rdb instances used by LogDB created by
+	// older versions which don't record the number of rdb instances. We use
+	// multiple rdb instance so different disks can be used together to get
+	// better combined IO performance. The number of rdb instances used by new
+	// LogDB is set in NodeHostConfig and recorded by LogDB.
 	LogDBPoolSize uint64
 	// LRUMaxSessionCount is the max number of client sessions that can be
 	// concurrently held and managed by each raft cluster.
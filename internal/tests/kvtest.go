@@ -134,7 +134,7 @@ func (s *KVTest) Lookup(key []byte) []byte {
 }
 
 // Update updates the object using the specified committed raft entry.
-func (s *KVTest) Update(data []byte) uint64 {
+func (s *KVTest) Update(data []byte) statemachine.Result {
 	s.Count++
 	if s.aborted {
 		panic("update() called after abort set to true")
@@ -151,7 +151,7 @@ func (s *KVTest) Update(data []byte) uint64 {
 	s.updateStore(dataKv.GetKey(), dataKv.GetVal())
 	s.pbkvPool.Put(dataKv)
 
-	return uint64(len(data))
+	return statemachine.Result{Value: uint64(len(data))}
 }
 
 func (s *KVTest) saveExternalFile(fileCollection statemachine.ISnapshotFileCollection) {
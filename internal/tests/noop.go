@@ -34,11 +34,11 @@ func (n *NoOP) Lookup(key []byte) []byte {
 }
 
 // Update updates the object.
-func (n *NoOP) Update(data []byte) uint64 {
+func (n *NoOP) Update(data []byte) statemachine.Result {
 	if n.MillisecondToSleep > 0 {
 		time.Sleep(time.Duration(n.MillisecondToSleep) * time.Millisecond)
 	}
-	return uint64(len(data))
+	return statemachine.Result{Value: uint64(len(data))}
 }
 
 // SaveSnapshot saves the state of the object to the provided io.Writer object.
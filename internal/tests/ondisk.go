@@ -0,0 +1,115 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	sm "github.com/lni/dragonboat/statemachine"
+)
+
+// FakeDiskSM is a test state machine used for testing on disk SM related
+// features. Its state is kept in memory, the initialApplied value is used to
+// simulate the index of the last entry persisted on disk.
+type FakeDiskSM struct {
+	initialApplied uint64
+	applied        uint64
+	count          uint64
+	SyncCount      uint64
+	aborted        bool
+}
+
+// NewFakeDiskSM creates and returns a new FakeDiskSM instance.
+func NewFakeDiskSM(initialApplied uint64) *FakeDiskSM {
+	return &FakeDiskSM{initialApplied: initialApplied}
+}
+
+// Open opens the state machine.
+func (f *FakeDiskSM) Open(stopc <-chan struct{}) (uint64, error) {
+	f.applied = f.initialApplied
+	return f.initialApplied, nil
+}
+
+// Update updates the state machine.
+func (f *FakeDiskSM) Update(ents []sm.Entry) []sm.Entry {
+	for idx, e := range ents {
+		if e.Index <= f.applied {
+			panic("already applied")
+		}
+		f.applied = e.Index
+		f.count++
+		ents[idx].Result = sm.Result{Value: f.count}
+	}
+	return ents
+}
+
+// Lookup queries the state machine.
+func (f *FakeDiskSM) Lookup(query []byte) ([]byte, error) {
+	result := make([]byte, 8)
+	binary.LittleEndian.PutUint64(result, f.count)
+	return result, nil
+}
+
+// Sync synchronizes the state machine.
+func (f *FakeDiskSM) Sync() error {
+	f.SyncCount++
+	return nil
+}
+
+// PrepareSnapshot prepares snapshotting.
+func (f *FakeDiskSM) PrepareSnapshot() (interface{}, error) {
+	pit := &FakeDiskSM{applied: f.applied, count: f.count}
+	return pit, nil
+}
+
+// SaveSnapshot saves the state to a snapshot.
+func (f *FakeDiskSM) SaveSnapshot(ctx interface{},
+	w io.Writer, stopc <-chan struct{}) (uint64, error) {
+	pit := ctx.(*FakeDiskSM)
+	v := make([]byte, 16)
+	binary.LittleEndian.PutUint64(v, pit.applied)
+	binary.LittleEndian.PutUint64(v[8:], pit.count)
+	if _, err := w.Write(v); err != nil {
+		return 0, err
+	}
+	return uint64(len(v)), nil
+}
+
+// RecoverFromSnapshot recovers the state of the state machine from a snapshot.
+func (f *FakeDiskSM) RecoverFromSnapshot(r io.Reader,
+	stopc <-chan struct{}) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data) != 16 {
+		panic("unexpected snapshot data size")
+	}
+	f.applied = binary.LittleEndian.Uint64(data)
+	f.count = binary.LittleEndian.Uint64(data[8:])
+	return nil
+}
+
+// Close closes the state machine.
+func (f *FakeDiskSM) Close() {
+	f.aborted = true
+}
+
+// GetHash returns the hash of the state.
+func (f *FakeDiskSM) GetHash() uint64 {
+	return f.count
+}
@@ -144,7 +144,7 @@ func createSnapshot(locator *snapshotLocator, clusterID uint64) pb.Message {
 			Term:     100,
 		},
 	}
-	writer, err := rsm.NewSnapshotWriter(m.Snapshot.Filepath)
+	writer, err := rsm.NewSnapshotWriter(m.Snapshot.Filepath, pb.NoCompression)
 	if err != nil {
 		panic(err)
 	}
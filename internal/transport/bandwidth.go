@@ -0,0 +1,80 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter limits the throughput of snapshot data shared by all
+// snapshot streams in the same direction. Each transfer reserves the next
+// available time slot for its data, concurrent streams reserving one chunk at
+// a time are served in turn and thus fairly share the bandwidth.
+type bandwidthLimiter struct {
+	mu struct {
+		sync.Mutex
+		// bytes per second, 0 means unlimited
+		rate uint64
+		next time.Time
+	}
+}
+
+func newBandwidthLimiter(rate uint64) *bandwidthLimiter {
+	l := &bandwidthLimiter{}
+	l.mu.rate = rate
+	return l
+}
+
+func (l *bandwidthLimiter) setRate(rate uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.rate = rate
+	l.mu.next = time.Time{}
+}
+
+// reserve reserves the time slot for transferring sz bytes and returns how
+// long to wait before the transfer can start.
+func (l *bandwidthLimiter) reserve(sz uint64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.rate == 0 {
+		return 0
+	}
+	if l.mu.next.Before(now) {
+		l.mu.next = now
+	}
+	start := l.mu.next
+	d := time.Duration(sz * uint64(time.Second) / l.mu.rate)
+	l.mu.next = start.Add(d)
+	return start.Sub(now)
+}
+
+// wait blocks until sz bytes are allowed to be transferred. It returns false
+// when stopc is closed before that.
+func (l *bandwidthLimiter) wait(sz uint64, stopc <-chan struct{}) bool {
+	d := l.reserve(sz, time.Now())
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopc:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnlimitedBandwidthLimiterNeverWaits(t *testing.T) {
+	l := newBandwidthLimiter(0)
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		if d := l.reserve(1024*1024, now); d != 0 {
+			t.Fatalf("unexpected wait %s", d)
+		}
+	}
+}
+
+func TestBandwidthLimiterReservesTimeSlots(t *testing.T) {
+	l := newBandwidthLimiter(1000)
+	now := time.Now()
+	expected := []time.Duration{0, time.Second, 3 * time.Second}
+	sizes := []uint64{1000, 2000, 500}
+	for idx, sz := range sizes {
+		if d := l.reserve(sz, now); d != expected[idx] {
+			t.Errorf("%d, got %s, want %s", idx, d, expected[idx])
+		}
+	}
+	// unused slots in the past are not accumulated
+	if d := l.reserve(1000, now.Add(time.Minute)); d != 0 {
+		t.Errorf("unexpected wait %s", d)
+	}
+}
+
+func TestBandwidthIsSharedByStreams(t *testing.T) {
+	l := newBandwidthLimiter(1000)
+	now := time.Now()
+	// two streams each reserving one chunk at a time are served in turn
+	waits := make(map[int][]time.Duration)
+	for i := 0; i < 6; i++ {
+		stream := i % 2
+		waits[stream] = append(waits[stream], l.reserve(500, now))
+	}
+	for stream, w := range waits {
+		for idx, d := range w {
+			want := time.Duration(2*idx+stream) * 500 * time.Millisecond
+			if d != want {
+				t.Errorf("stream %d chunk %d, got %s, want %s",
+					stream, idx, d, want)
+			}
+		}
+	}
+}
+
+func TestBandwidthLimitCanBeChanged(t *testing.T) {
+	l := newBandwidthLimiter(1000)
+	now := time.Now()
+	l.reserve(10000, now)
+	l.setRate(2000)
+	if d := l.reserve(2000, now); d != 0 {
+		t.Errorf("unexpected wait %s", d)
+	}
+	if d := l.reserve(2000, now); d != time.Second {
+		t.Errorf("got %s, want 1s", d)
+	}
+	l.setRate(0)
+	if d := l.reserve(2000, now); d != 0 {
+		t.Errorf("unexpected wait %s", d)
+	}
+}
+
+func TestBandwidthLimiterWaitCanBeStopped(t *testing.T) {
+	l := newBandwidthLimiter(1)
+	stopc := make(chan struct{})
+	if !l.wait(1, stopc) {
+		t.Fatalf("first wait unexpectedly failed")
+	}
+	close(stopc)
+	if l.wait(1, stopc) {
+		t.Errorf("wait not stopped")
+	}
+}
@@ -15,8 +15,12 @@
 package transport
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"hash/fnv"
 	"io"
 	"path/filepath"
 	"sync"
@@ -43,38 +47,108 @@ func snapshotKey(c pb.SnapshotChunk) string {
 	return fmt.Sprintf("%d:%d:%d", c.ClusterId, c.NodeId, c.Index)
 }
 
+func getChunkChecksum(data []byte) []byte {
+	h := crc32.NewIEEE()
+	if _, err := h.Write(data); err != nil {
+		panic(err)
+	}
+	return h.Sum(nil)
+}
+
+// validChunkChecksum returns a boolean flag indicating whether the chunk data
+// matches its checksum. Chunks sent by older versions have no checksum.
+func validChunkChecksum(chunk pb.SnapshotChunk) bool {
+	if chunk.Checksum == nil {
+		return true
+	}
+	return bytes.Equal(chunk.Checksum, getChunkChecksum(chunk.Data))
+}
+
+// getSnapshotID returns the identity of the snapshot that the specified first
+// chunk belongs to. The first chunk starts with the snapshot header, which
+// includes the checksum of the snapshot payload, snapshots with the same index
+// and term but different content thus have different IDs.
+func getSnapshotID(chunk pb.SnapshotChunk) uint64 {
+	if chunk.ChunkId != 0 {
+		panic("first chunk must be used to identify the snapshot")
+	}
+	h := fnv.New64a()
+	if _, err := h.Write(chunk.Data); err != nil {
+		panic(err)
+	}
+	sz := make([]byte, 8)
+	binary.BigEndian.PutUint64(sz, chunk.FileSize)
+	if _, err := h.Write(sz); err != nil {
+		panic(err)
+	}
+	return h.Sum64()
+}
+
 type tracked struct {
 	firstChunk pb.SnapshotChunk
 	extraFiles []*pb.SnapshotFile
 	validator  *rsm.SnapshotValidator
+	id         uint64
 	nextChunk  uint64
+	reported   uint64
 	tick       uint64
+	// resumeChunk is the first chunk sent again by the sender, it is kept
+	// until the sender resumes the transfer so the transfer can be started
+	// over when the sender doesn't.
+	resumeChunk *pb.SnapshotChunk
 }
 
-// chunks managed on the receiving side
+// resumable returns a boolean flag indicating whether the transfer of the
+// snapshot can be resumed from the next chunk when the sender starts over
+// from the specified first chunk.
+func (td *tracked) resumable(chunk pb.SnapshotChunk) bool {
+	fc := td.firstChunk
+	return td.nextChunk > 1 &&
+		fc.From == chunk.From &&
+		fc.Term == chunk.Term &&
+		fc.FileSize == chunk.FileSize &&
+		fc.ChunkCount == chunk.ChunkCount &&
+		td.id == getSnapshotID(chunk)
+}
+
+// snapshotLock serializes chunks of the same snapshot received from different
+// streams.
+type snapshotLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// chunks managed on the receiving side. chunks is shared by all snapshot
+// streams, partially received snapshots are kept when the stream is broken so
+// the sender can resume the transfer from the last persisted chunk.
 type chunks struct {
 	currentTick     uint64
 	validate        bool
 	getSnapshotDir  server.GetSnapshotDirFunc
 	onReceive       func(pb.MessageBatch)
-	confirm         func(uint64, uint64, uint64)
+	confirm         func(uint64, uint64, uint64, uint64)
+	progress        func(pb.Message)
 	getDeploymentID func() uint64
 	tracked         map[string]*tracked
+	locks           map[string]*snapshotLock
 	timeoutTick     uint64
 	gcTick          uint64
 	mu              sync.Mutex
 }
 
 func newSnapshotChunks(onReceive func(pb.MessageBatch),
-	confirm func(uint64, uint64, uint64),
+	confirm func(uint64, uint64, uint64, uint64),
+	progress func(pb.Message),
 	getDeploymentID func() uint64,
 	getSnapshotDirFunc server.GetSnapshotDirFunc) *chunks {
 	return &chunks{
 		validate:        true,
 		onReceive:       onReceive,
 		confirm:         confirm,
+		progress:        progress,
 		getDeploymentID: getDeploymentID,
 		tracked:         make(map[string]*tracked),
+		locks:           make(map[string]*snapshotLock),
 		timeoutTick:     snapshotChunkTimeoutTick,
 		gcTick:          gcIntervalTick,
 		getSnapshotDir:  getSnapshotDirFunc,
@@ -95,6 +169,7 @@ func (c *chunks) Tick() {
 	if ct%c.gcTick == 0 {
 		c.gc()
 	}
+	c.reportProgress()
 }
 
 func (c *chunks) Close() {
@@ -117,6 +192,36 @@ func (c *chunks) gc() {
 	}
 }
 
+// reportProgress reports the next expected chunk of snapshots that made
+// progress since the last report to their senders.
+func (c *chunks) reportProgress() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, td := range c.tracked {
+		if td.nextChunk > td.reported {
+			c.sendProgress(td)
+		}
+	}
+}
+
+func (c *chunks) sendProgress(td *tracked) {
+	td.reported = td.nextChunk
+	if c.progress == nil {
+		return
+	}
+	fc := td.firstChunk
+	c.progress(pb.Message{
+		Type:      pb.SnapshotProgress,
+		ClusterId: fc.ClusterId,
+		From:      fc.NodeId,
+		To:        fc.From,
+		LogIndex:  fc.Index,
+		LogTerm:   fc.Term,
+		Hint:      td.nextChunk,
+		HintHigh:  td.id,
+	})
+}
+
 func (c *chunks) getCurrentTick() uint64 {
 	return atomic.LoadUint64(&c.currentTick)
 }
@@ -138,6 +243,17 @@ func (c *chunks) onNewChunk(key string,
 	defer c.mu.Unlock()
 	if chunk.ChunkId == 0 {
 		plog.Infof("new snapshot chunk 0, key %s", key)
+		if td != nil && td.resumable(chunk) {
+			// the sender started over, let it resume from the next chunk
+			plog.Infof("snapshot %s can be resumed from chunk %d",
+				key, td.nextChunk)
+			rc := chunk
+			rc.Data = append([]byte(nil), chunk.Data...)
+			td.resumeChunk = &rc
+			c.sendProgress(td)
+			td.tick = c.getCurrentTick()
+			return false
+		}
 		if td != nil {
 			plog.Warningf("removing unclaimed chunks %s", key)
 			c.deleteTempChunkDir(td.firstChunk)
@@ -145,10 +261,13 @@ func (c *chunks) onNewChunk(key string,
 		td = &tracked{
 			firstChunk: chunk,
 			validator:  rsm.NewSnapshotValidator(),
+			id:         getSnapshotID(chunk),
 			nextChunk:  1,
 			extraFiles: make([]*pb.SnapshotFile, 0),
 		}
 		c.tracked[key] = td
+		// let the sender know that the transfer starts from the beginning
+		c.sendProgress(td)
 	} else {
 		if td == nil {
 			// not tracked,
@@ -159,6 +278,7 @@ func (c *chunks) onNewChunk(key string,
 			return false
 		}
 		td.nextChunk = chunk.ChunkId + 1
+		td.resumeChunk = nil
 	}
 	if chunk.FileChunkId == 0 && chunk.HasFileInfo {
 		td.extraFiles = append(td.extraFiles, &chunk.FileInfo)
@@ -167,14 +287,76 @@ func (c *chunks) onNewChunk(key string,
 	return true
 }
 
+// lockSnapshot locks the snapshot identified by the key so its chunks are
+// processed one at a time, chunks of other snapshots are not blocked.
+func (c *chunks) lockSnapshot(key string) *snapshotLock {
+	c.mu.Lock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = &snapshotLock{}
+		c.locks[key] = l
+	}
+	l.refs++
+	c.mu.Unlock()
+	l.mu.Lock()
+	return l
+}
+
+func (c *chunks) unlockSnapshot(key string, l *snapshotLock) {
+	l.mu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(c.locks, key)
+	}
+}
+
+// getRestartedTransfer returns the first chunk sent again by the sender when
+// the sender didn't resume the transfer, e.g. because it didn't get the
+// progress report in time. The partially received snapshot is removed so
+// the transfer can be started over from the returned first chunk.
+func (c *chunks) getRestartedTransfer(key string,
+	chunk pb.SnapshotChunk) (pb.SnapshotChunk, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	td := c.tracked[key]
+	if td == nil || td.resumeChunk == nil ||
+		chunk.ChunkId != 1 || chunk.From != td.resumeChunk.From {
+		return pb.SnapshotChunk{}, false
+	}
+	fc := *td.resumeChunk
+	c.deleteTempChunkDir(td.firstChunk)
+	c.resetSnapshotLocked(key)
+	return fc, true
+}
+
 func (c *chunks) addChunk(chunk pb.SnapshotChunk) {
 	key := snapshotKey(chunk)
+	l := c.lockSnapshot(key)
+	defer c.unlockSnapshot(key, l)
+	if !validChunkChecksum(chunk) {
+		plog.Warningf("ignored a chunk with invalid checksum %s", key)
+		return
+	}
+	if fc, ok := c.getRestartedTransfer(key, chunk); ok {
+		plog.Infof("transfer of %s not resumed by the sender, starting over", key)
+		c.processChunk(key, fc)
+	}
+	c.processChunk(key, chunk)
+}
+
+func (c *chunks) processChunk(key string, chunk pb.SnapshotChunk) {
+	c.mu.Lock()
 	td := c.tracked[key]
+	c.mu.Unlock()
 	if !c.onNewChunk(key, td, chunk) {
 		plog.Warningf("ignored a chunk belongs to %s", key)
 		return
 	}
+	c.mu.Lock()
 	td = c.tracked[key]
+	c.mu.Unlock()
 	if c.validate && !td.validator.AddChunk(chunk.Data, chunk.ChunkId) {
 		plog.Warningf("ignored a invalid chunk %s", key)
 		return
@@ -207,7 +389,7 @@ func (c *chunks) addChunk(chunk pb.SnapshotChunk) {
 			logutil.DescribeNode(chunk.ClusterId, chunk.NodeId),
 			chunk.From, chunk.Index, chunk.Term)
 		c.onReceive(snapshotMessage)
-		c.confirm(chunk.ClusterId, chunk.NodeId, chunk.From)
+		c.confirm(chunk.ClusterId, chunk.NodeId, chunk.From, chunk.Index)
 	}
 }
 
@@ -322,3 +504,25 @@ func (c *chunks) toMessage(chunk pb.SnapshotChunk,
 		Requests:     []pb.Message{m},
 	}
 }
+
+// chunkSink is the IChunkSink returned to the Raft RPC module for each
+// snapshot stream. All streams share the chunks instance owned by the
+// Transport, which is also responsible for ticking and closing it. Received
+// chunks are throttled by the limiter, the stream is not read while waiting
+// so the sender is slowed down as well.
+type chunkSink struct {
+	chunks  *chunks
+	limiter *bandwidthLimiter
+	stopc   <-chan struct{}
+}
+
+func (s *chunkSink) Close() {}
+
+func (s *chunkSink) AddChunk(chunk pb.SnapshotChunk) {
+	if s.limiter != nil && !s.limiter.wait(uint64(len(chunk.Data)), s.stopc) {
+		return
+	}
+	s.chunks.AddChunk(chunk)
+}
+
+func (s *chunkSink) Tick() {}
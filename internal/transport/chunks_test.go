@@ -92,8 +92,8 @@ func runChunkTest(t *testing.T, fn func(*testing.T, *chunks, *testMessageHandler
 	defer tt.cleanup()
 	handler := newTestMessageHandler()
 	trans.SetMessageHandler(handler)
-	chunks := newSnapshotChunks(trans.handleRequest,
-		trans.snapshotReceived, getTestDeploymentID, trans.snapshotLocator)
+	chunks := newSnapshotChunks(trans.handleRequest, trans.snapshotReceived,
+		trans.sendSnapshotProgress, getTestDeploymentID, trans.snapshotLocator)
 	fn(t, chunks, handler)
 }
 
@@ -331,6 +331,187 @@ func TestAddingFirstChunkAgainResetsTempFile(t *testing.T) {
 	runChunkTest(t, fn)
 }
 
+func TestChunkWithInvalidChecksumIsIgnored(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		inputs := getTestChunks()
+		chunks.validate = false
+		inputs[0].Checksum = getChunkChecksum(inputs[0].Data)
+		inputs[0].Data[0] = inputs[0].Data[0] + 1
+		chunks.addChunk(inputs[0])
+		if _, ok := chunks.tracked[snapshotKey(inputs[0])]; ok {
+			t.Errorf("corrupted chunk not ignored")
+		}
+		inputs[0].Checksum = getChunkChecksum(inputs[0].Data)
+		chunks.addChunk(inputs[0])
+		if _, ok := chunks.tracked[snapshotKey(inputs[0])]; !ok {
+			t.Errorf("chunk not accepted")
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestSnapshotTransferCanBeResumed(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		reported := make([]raftpb.Message, 0)
+		chunks.progress = func(m raftpb.Message) {
+			reported = append(reported, m)
+		}
+		inputs := getTestChunks()
+		chunks.validate = false
+		for _, c := range inputs[:3] {
+			chunks.addChunk(c)
+		}
+		// the sender starts over after losing the connection
+		chunks.addChunk(inputs[0])
+		key := snapshotKey(inputs[0])
+		td, ok := chunks.tracked[key]
+		if !ok || td.nextChunk != 3 {
+			t.Fatalf("partially received snapshot not kept")
+		}
+		// chunk 0 is reported when first received and when received again
+		if len(reported) != 2 {
+			t.Fatalf("progress not reported")
+		}
+		m := reported[1]
+		if m.Type != raftpb.SnapshotProgress || m.Hint != 3 ||
+			m.HintHigh != getSnapshotID(inputs[0]) ||
+			m.To != inputs[0].From || m.From != inputs[0].NodeId ||
+			m.LogIndex != inputs[0].Index || m.LogTerm != inputs[0].Term {
+			t.Errorf("unexpected progress %+v", m)
+		}
+		chunks.addChunk(inputs[3])
+		// chunks already persisted are ignored
+		chunks.addChunk(inputs[1])
+		for _, c := range inputs[4:] {
+			chunks.addChunk(c)
+		}
+		if _, ok := chunks.tracked[key]; ok {
+			t.Errorf("snapshot still tracked")
+		}
+		if handler.getSnapshotCount(100, 2) != 1 {
+			t.Errorf("got %d, want %d", handler.getSnapshotCount(100, 2), 1)
+		}
+		if !checkTestSnapshotFile(chunks, inputs[0], settings.SnapshotHeaderSize*10) {
+			t.Errorf("failed to generate the final snapshot file")
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestTransferIsStartedOverWhenNotResumedBySender(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		inputs := getTestChunks()
+		chunks.validate = false
+		for _, c := range inputs[:3] {
+			chunks.addChunk(c)
+		}
+		chunks.addChunk(inputs[0])
+		key := snapshotKey(inputs[0])
+		if td := chunks.tracked[key]; td.nextChunk != 3 || td.resumeChunk == nil {
+			t.Fatalf("transfer not resumable")
+		}
+		// the sender didn't get the progress report and sends all chunks
+		chunks.addChunk(inputs[1])
+		td := chunks.tracked[key]
+		if td.nextChunk != 2 || td.resumeChunk != nil {
+			t.Fatalf("transfer not started over, next chunk %d", td.nextChunk)
+		}
+		for _, c := range inputs[2:] {
+			chunks.addChunk(c)
+		}
+		if handler.getSnapshotCount(100, 2) != 1 {
+			t.Errorf("got %d, want %d", handler.getSnapshotCount(100, 2), 1)
+		}
+		if !checkTestSnapshotFile(chunks, inputs[0], settings.SnapshotHeaderSize*10) {
+			t.Errorf("failed to generate the final snapshot file")
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestFirstChunkOfAnotherSnapshotResetsTheTransfer(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		inputs := getTestChunks()
+		chunks.validate = false
+		for _, c := range inputs[:3] {
+			chunks.addChunk(c)
+		}
+		// same index, term and size, different content
+		fc := getTestChunks()[0]
+		chunks.addChunk(fc)
+		td := chunks.tracked[snapshotKey(fc)]
+		if td.nextChunk != 1 || td.id != getSnapshotID(fc) {
+			t.Errorf("transfer not reset")
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestSnapshotLockIsReleased(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		l1 := chunks.lockSnapshot("k1")
+		// other snapshots are not blocked
+		l2 := chunks.lockSnapshot("k2")
+		chunks.unlockSnapshot("k2", l2)
+		chunks.unlockSnapshot("k1", l1)
+		if len(chunks.locks) != 0 {
+			t.Errorf("locks not released, %v", chunks.locks)
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestFirstChunkFromAnotherSenderResetsTheTransfer(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		inputs := getTestChunks()
+		chunks.validate = false
+		for _, c := range inputs[:3] {
+			chunks.addChunk(c)
+		}
+		inputs = getTestChunks()
+		for idx := range inputs {
+			inputs[idx].From = 13
+		}
+		chunks.addChunk(inputs[0])
+		td := chunks.tracked[snapshotKey(inputs[0])]
+		if td.nextChunk != 1 || td.firstChunk.From != 13 {
+			t.Errorf("transfer not reset")
+		}
+	}
+	runChunkTest(t, fn)
+}
+
+func TestProgressIsReportedOnTick(t *testing.T) {
+	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
+		reported := make([]raftpb.Message, 0)
+		chunks.progress = func(m raftpb.Message) {
+			reported = append(reported, m)
+		}
+		inputs := getTestChunks()
+		chunks.validate = false
+		chunks.addChunk(inputs[0])
+		if len(reported) != 1 || reported[0].Hint != 1 {
+			t.Fatalf("chunk 0 not reported, %v", reported)
+		}
+		chunks.addChunk(inputs[1])
+		chunks.Tick()
+		if len(reported) != 2 || reported[1].Hint != 2 {
+			t.Fatalf("progress not reported, %v", reported)
+		}
+		// no progress, nothing to report
+		chunks.Tick()
+		if len(reported) != 2 {
+			t.Errorf("unexpected progress report")
+		}
+		chunks.addChunk(inputs[2])
+		chunks.Tick()
+		if len(reported) != 3 || reported[2].Hint != 3 {
+			t.Errorf("progress not reported, %v", reported)
+		}
+	}
+	runChunkTest(t, fn)
+}
+
 func TestSnapshotWithExternalFilesAreHandledByChunks(t *testing.T) {
 	fn := func(t *testing.T, chunks *chunks, handler *testMessageHandler) {
 		chunks.validate = false
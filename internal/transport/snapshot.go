@@ -38,6 +38,7 @@ package transport
 import (
 	"errors"
 	"sync/atomic"
+	"time"
 
 	"github.com/lni/dragonboat/internal/settings"
 	"github.com/lni/dragonboat/internal/utils/fileutil"
@@ -48,8 +49,27 @@ import (
 
 var (
 	maxSnapshotCount = settings.Soft.MaxSnapshotCount
+	// snapshotResumeTimeout is the maximum time to wait for the receiver to
+	// confirm that the transfer of a snapshot can be resumed
+	snapshotResumeTimeout = 5 * time.Second
 )
 
+type progressKey struct {
+	clusterID uint64
+	nodeID    uint64
+}
+
+// snapshotProgress is the progress of a snapshot transfer reported by the
+// receiving node. next is the ID of the next chunk expected by the receiver,
+// id is the identity of the snapshot as returned by getSnapshotID.
+type snapshotProgress struct {
+	index   uint64
+	term    uint64
+	id      uint64
+	next    uint64
+	updated time.Time
+}
+
 // ASyncSendSnapshot sends raft snapshot message to its target.
 func (t *Transport) ASyncSendSnapshot(m pb.Message) bool {
 	if !t.asyncSendSnapshot(m) {
@@ -152,6 +172,7 @@ func (t *Transport) connectAndProcessSnapshot(clusterID uint64,
 			plog.Warningf("failed to get snapshot client to %s",
 				logutil.DescribeNode(clusterID, toNodeID))
 			t.sendSnapshotNotification(clusterID, toNodeID, true)
+			t.connectionFailed(remoteHost, true)
 			return err
 		}
 		defer conn.Close()
@@ -217,7 +238,17 @@ func (t *Transport) processSnapshotQueue(ch <-chan pb.SnapshotChunk,
 					continue
 				}
 				failed := false
+				start := time.Now()
+				resumeFrom := uint64(0)
 				for _, curChunk := range chunks {
+					if curChunk.ChunkId < resumeFrom {
+						// resumed transfer, the chunk has already been persisted by
+						// the receiver
+						continue
+					}
+					if !t.sendLimiter.wait(curChunk.ChunkSize, t.stopper.ShouldStop()) {
+						return nil
+					}
 					chunkData := make([]byte, snapChunkSize)
 					idx++
 					data, err := loadSnapshotChunkData(curChunk, chunkData)
@@ -230,10 +261,13 @@ func (t *Transport) processSnapshotQueue(ch <-chan pb.SnapshotChunk,
 						break
 					}
 					curChunk.Data = data
+					curChunk.Checksum = getChunkChecksum(data)
 					curChunk.DeploymentId = deploymentID
+					sent := time.Now()
 					if err := t.sendSnapshotChunk(curChunk, conn); err != nil {
 						plog.Debugf("snapshot to %s failed",
 							logutil.DescribeNode(chunk.ClusterId, chunk.NodeId))
+						t.clearSnapshotProgress(chunk, start)
 						t.sendSnapshotNotification(chunk.ClusterId, chunk.NodeId, true)
 						return err
 					}
@@ -242,9 +276,18 @@ func (t *Transport) processSnapshotQueue(ch <-chan pb.SnapshotChunk,
 					if t.streamChunkSent != nil {
 						t.streamChunkSent(curChunk)
 					}
+					if curChunk.ChunkId == 0 {
+						resumeFrom = t.getResumeChunkID(curChunk, sent)
+					}
 				}
 				plog.Debugf("snapshot to %s processed, failed to send value %t",
 					logutil.DescribeNode(chunk.ClusterId, chunk.NodeId), failed)
+				if !failed {
+					t.metrics.snapshotSent(start)
+					t.clearSnapshotProgress(chunk, time.Now())
+				} else {
+					t.clearSnapshotProgress(chunk, start)
+				}
 				t.sendSnapshotNotification(chunk.ClusterId, chunk.NodeId, failed)
 				chunks = make([]pb.SnapshotChunk, 0)
 			}
@@ -252,6 +295,110 @@ func (t *Transport) processSnapshotQueue(ch <-chan pb.SnapshotChunk,
 	}
 }
 
+// sendSnapshotProgress sends the progress of a snapshot being received to
+// the sender of the snapshot.
+func (t *Transport) sendSnapshotProgress(m pb.Message) {
+	if !t.ASyncSend(m) {
+		plog.Debugf("failed to send snapshot progress to %s",
+			logutil.DescribeNode(m.ClusterId, m.To))
+	}
+}
+
+// handleSnapshotProgress records the snapshot progress reported by remote
+// nodes and returns other received messages.
+func (t *Transport) handleSnapshotProgress(reqs []pb.Message) []pb.Message {
+	found := false
+	for _, m := range reqs {
+		if m.Type == pb.SnapshotProgress {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return reqs
+	}
+	result := make([]pb.Message, 0, len(reqs))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range reqs {
+		if m.Type != pb.SnapshotProgress {
+			result = append(result, m)
+			continue
+		}
+		key := progressKey{clusterID: m.ClusterId, nodeID: m.From}
+		t.mu.progress[key] = snapshotProgress{
+			index:   m.LogIndex,
+			term:    m.LogTerm,
+			id:      m.HintHigh,
+			next:    m.Hint,
+			updated: time.Now(),
+		}
+	}
+	return result
+}
+
+// getSnapshotProgress returns the ID of the next chunk expected by the
+// receiver of the snapshot that the specified first chunk belongs to. Only
+// progress reported after the specified time is returned.
+func (t *Transport) getSnapshotProgress(c pb.SnapshotChunk,
+	since time.Time) (uint64, bool) {
+	id := getSnapshotID(c)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := progressKey{clusterID: c.ClusterId, nodeID: c.NodeId}
+	p, ok := t.mu.progress[key]
+	if !ok || p.index != c.Index || p.term != c.Term ||
+		p.id != id || p.updated.Before(since) {
+		return 0, false
+	}
+	return p.next, true
+}
+
+// getResumeChunkID returns the ID of the chunk from which the transfer of the
+// snapshot continues after sending the specified first chunk at the specified
+// time. Chunks are only skipped when the receiver confirms, after receiving
+// the first chunk, that it still has the chunks persisted by an earlier
+// transfer. 0 is returned when there is nothing to resume or when no such
+// confirmation is received in time, all chunks are sent again in that case.
+func (t *Transport) getResumeChunkID(c pb.SnapshotChunk,
+	sent time.Time) uint64 {
+	if next, ok := t.getSnapshotProgress(c, time.Time{}); !ok || next <= 1 {
+		return 0
+	}
+	timer := time.NewTimer(snapshotResumeTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if next, ok := t.getSnapshotProgress(c, sent); ok {
+			return next
+		}
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			plog.Warningf("transfer of snapshot to %s not confirmed, sending all",
+				logutil.DescribeNode(c.ClusterId, c.NodeId))
+			return 0
+		case <-t.stopper.ShouldStop():
+			return 0
+		}
+	}
+}
+
+// clearSnapshotProgress removes the recorded progress of the snapshot
+// transfer if it hasn't been updated since the specified time. Progress
+// reported during a failed transfer is kept so the next transfer of the same
+// snapshot can be resumed.
+func (t *Transport) clearSnapshotProgress(c pb.SnapshotChunk,
+	since time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := progressKey{clusterID: c.ClusterId, nodeID: c.NodeId}
+	if p, ok := t.mu.progress[key]; ok && p.updated.Before(since) {
+		delete(t.mu.progress, key)
+	}
+}
+
 func (t *Transport) cleanup(ch <-chan pb.SnapshotChunk) {
 	for {
 		select {
@@ -51,6 +51,7 @@ import (
 	"time"
 
 	"github.com/lni/dragonboat/config"
+	"github.com/lni/dragonboat/internal/metrics"
 	"github.com/lni/dragonboat/internal/server"
 	"github.com/lni/dragonboat/internal/settings"
 	"github.com/lni/dragonboat/internal/utils/logutil"
@@ -116,6 +117,7 @@ type ITransport interface {
 	RemoveMessageHandler()
 	ASyncSend(pb.Message) bool
 	ASyncSendSnapshot(pb.Message) bool
+	SetSnapshotBandwidthLimit(uint64, uint64)
 	Stop()
 }
 
@@ -173,7 +175,12 @@ type Transport struct {
 		chunks      map[string]chan pb.SnapshotChunk
 		chunksClose map[string]chan struct{}
 		breakers    map[string]*circuit.Breaker
+		// snapshot transfer progress reported by remote nodes
+		progress map[progressKey]snapshotProgress
 	}
+	chunks              *chunks
+	sendLimiter         *bandwidthLimiter
+	recvLimiter         *bandwidthLimiter
 	serverCtx           *server.Context
 	nhConfig            config.NodeHostConfig
 	sourceAddress       string
@@ -191,6 +198,46 @@ type Transport struct {
 	snapshotCount       int32
 	streamConnections   uint64
 	snapshotQueueMu     sync.Mutex
+	metrics             *transportMetrics
+	raftEvents          raftio.IRaftEventListener
+}
+
+type transportMetrics struct {
+	noAddress      *metrics.Counter
+	unreachable    *metrics.Counter
+	queueFull      *metrics.Counter
+	noDeploymentID *metrics.Counter
+	snapshotSend   *metrics.Histogram
+}
+
+func (m *transportMetrics) droppedNoAddress() {
+	if m != nil {
+		m.noAddress.Inc()
+	}
+}
+
+func (m *transportMetrics) droppedUnreachable() {
+	if m != nil {
+		m.unreachable.Inc()
+	}
+}
+
+func (m *transportMetrics) droppedQueueFull() {
+	if m != nil {
+		m.queueFull.Inc()
+	}
+}
+
+func (m *transportMetrics) droppedNoDeploymentID(count int) {
+	if m != nil {
+		m.noDeploymentID.Add(uint64(count))
+	}
+}
+
+func (m *transportMetrics) snapshotSent(start time.Time) {
+	if m != nil {
+		m.snapshotSend.ObserveSince(start)
+	}
 }
 
 // NewTransport creates a new Transport object.
@@ -206,10 +253,17 @@ func NewTransport(nhConfig config.NodeHostConfig, ctx *server.Context,
 		stopper:           stopper,
 		snapshotLocator:   locator,
 		streamConnections: rpcStreamConnections,
+		sendLimiter:       newBandwidthLimiter(nhConfig.MaxSnapshotSendBytesPerSecond),
+		recvLimiter:       newBandwidthLimiter(nhConfig.MaxSnapshotRecvBytesPerSecond),
 	}
+	t.chunks = newSnapshotChunks(t.handleRequest, t.snapshotReceived,
+		t.sendSnapshotProgress, t.getDeploymentID, t.snapshotLocator)
 	sinkFactory := func() raftio.IChunkSink {
-		return newSnapshotChunks(t.handleRequest,
-			t.snapshotReceived, t.getDeploymentID, t.snapshotLocator)
+		return &chunkSink{
+			chunks:  t.chunks,
+			limiter: t.recvLimiter,
+			stopc:   t.stopper.ShouldStop(),
+		}
 	}
 	raftRPC := createTransportRPC(nhConfig, t.handleRequest, sinkFactory)
 	plog.Infof("Raft RPC type: %s", raftRPC.Name())
@@ -222,6 +276,19 @@ func NewTransport(nhConfig config.NodeHostConfig, ctx *server.Context,
 	t.mu.chunks = make(map[string]chan pb.SnapshotChunk)
 	t.mu.chunksClose = make(map[string]chan struct{})
 	t.mu.breakers = make(map[string]*circuit.Breaker)
+	t.mu.progress = make(map[progressKey]snapshotProgress)
+	t.stopper.RunWorker(func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.chunks.Tick()
+			case <-t.stopper.ShouldStop():
+				return
+			}
+		}
+	})
 	return t
 }
 
@@ -235,6 +302,54 @@ func (t *Transport) GetRaftRPC() raftio.IRaftRPC {
 	return t.raftRPC
 }
 
+// SetRaftEventListener sets the listener to be notified on received snapshots
+// and failed connections. It must be called before the transport is used for
+// sending messages.
+func (t *Transport) SetRaftEventListener(l raftio.IRaftEventListener) {
+	t.raftEvents = l
+}
+
+// SetMetrics sets the metrics registry used for recording transport metrics.
+// It must be called before the transport is used for sending messages.
+func (t *Transport) SetMetrics(r *metrics.Registry) {
+	if r == nil {
+		return
+	}
+	dropped := func(reason string) *metrics.Counter {
+		return r.Counter("dragonboat_transport_dropped_messages_total",
+			"Number of raft messages dropped by the transport.", "reason", reason)
+	}
+	t.metrics = &transportMetrics{
+		noAddress:      dropped("no_address"),
+		unreachable:    dropped("unreachable"),
+		queueFull:      dropped("queue_full"),
+		noDeploymentID: dropped("no_deployment_id"),
+		snapshotSend: r.Histogram("dragonboat_snapshot_send_duration_seconds",
+			"Time taken to send snapshots to remote nodes.",
+			metrics.DurationBuckets),
+	}
+	r.GaugeFunc("dragonboat_transport_send_queue_depth",
+		"Number of raft messages waiting in send queues.", t.sendQueueDepth)
+}
+
+func (t *Transport) sendQueueDepth() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	depth := 0
+	for _, ch := range t.mu.queues {
+		depth += len(ch)
+	}
+	return float64(depth)
+}
+
+// SetSnapshotBandwidthLimit sets the maximum bytes per second of snapshot
+// data sent to and received from remote NodeHost instances. The bandwidth is
+// shared by all concurrent snapshot streams, 0 means unlimited.
+func (t *Transport) SetSnapshotBandwidthLimit(send uint64, recv uint64) {
+	t.sendLimiter.setRate(send)
+	t.recvLimiter.setRate(recv)
+}
+
 // SetPreSendMessageBatchHook set the SendMessageBatch hook.
 // This function is only expected to be used in monkey testing.
 func (t *Transport) SetPreSendMessageBatchHook(h SendMessageBatchFunc) {
@@ -252,6 +367,7 @@ func (t *Transport) Stop() {
 	t.cancel()
 	t.stopper.Stop()
 	t.raftRPC.Stop()
+	t.chunks.Close()
 }
 
 // GetCircuitBreaker returns the circuit breaker used for the specified
@@ -297,6 +413,10 @@ func (t *Transport) handleRequest(req pb.MessageBatch) {
 			req.BinVer, raftio.RPCBinVersion)
 		return
 	}
+	req.Requests = t.handleSnapshotProgress(req.Requests)
+	if len(req.Requests) == 0 {
+		return
+	}
 	handler := t.handler.Load()
 	if handler == nil {
 		return
@@ -313,7 +433,10 @@ func (t *Transport) handleRequest(req pb.MessageBatch) {
 }
 
 func (t *Transport) snapshotReceived(clusterID uint64,
-	nodeID uint64, from uint64) {
+	nodeID uint64, from uint64, index uint64) {
+	if t.raftEvents != nil {
+		t.raftEvents.SnapshotReceived(clusterID, nodeID, from, index)
+	}
 	if t.handlerRemoved() {
 		return
 	}
@@ -324,6 +447,12 @@ func (t *Transport) snapshotReceived(clusterID uint64,
 	handler.(IRaftMessageHandler).HandleSnapshot(clusterID, nodeID, from)
 }
 
+func (t *Transport) connectionFailed(addr string, snapshot bool) {
+	if t.raftEvents != nil {
+		t.raftEvents.ConnectionFailed(addr, snapshot)
+	}
+}
+
 func (t *Transport) sendUnreachableNotification(addr string) {
 	if t.handlerRemoved() {
 		return
@@ -345,7 +474,7 @@ func (t *Transport) sendUnreachableNotification(addr string) {
 // The generic async send Go pattern used in ASyncSend is found in CockroachDB's
 // codebase.
 func (t *Transport) ASyncSend(req pb.Message) bool {
-	if req.Type == pb.InstallSnapshot {
+	if req.Type == pb.InstallSnapshot && !req.Snapshot.Witness {
 		panic("snapshot message must be sent via its own channel.")
 	}
 	toNodeID := req.To
@@ -355,10 +484,12 @@ func (t *Transport) ASyncSend(req pb.Message) bool {
 	if err != nil {
 		plog.Warningf("node %s do not have the address for %s, dropping a message",
 			t.sourceAddress, logutil.DescribeNode(clusterID, toNodeID))
+		t.metrics.droppedNoAddress()
 		return false
 	}
 	// fail fast
 	if !t.GetCircuitBreaker(addr).Ready() {
+		t.metrics.droppedUnreachable()
 		return false
 	}
 	// get the channel, create it in case it is not in the queue map
@@ -385,6 +516,7 @@ func (t *Transport) ASyncSend(req pb.Message) bool {
 	case ch <- req:
 		return true
 	default:
+		t.metrics.droppedQueueFull()
 		return false
 	}
 }
@@ -401,6 +533,7 @@ func (t *Transport) connectAndProcess(clusterID uint64, toNodeID uint64,
 		if err != nil {
 			plog.Errorf("Nodehost %s failed to get a connection to %s, %v",
 				t.sourceAddress, remoteHost, err)
+			t.connectionFailed(remoteHost, false)
 			return err
 		}
 		defer conn.Close()
@@ -467,6 +600,7 @@ func (t *Transport) processQueue(clusterID uint64, toNodeID uint64,
 				batch.DeploymentId = deploymentID
 			} else {
 				plog.Warningf("Messages dropped as no valid deployment id set")
+				t.metrics.droppedNoDeploymentID(len(requests))
 				requests = requests[:0]
 				continue
 			}
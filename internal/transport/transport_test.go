@@ -112,7 +112,7 @@ func (g *getTestSnapshotDirStruct) generateSnapshotFile(clusterID uint64,
 	fp := filepath.Join(snapDir, filename)
 	data := make([]byte, sz)
 	rand.Read(data)
-	writer, err := rsm.NewSnapshotWriter(fp)
+	writer, err := rsm.NewSnapshotWriter(fp, raftpb.NoCompression)
 	if err != nil {
 		panic(err)
 	}
@@ -532,6 +532,99 @@ func TestMessageBatchWithNotMatchedBinVerAreDropped(t *testing.T) {
 	testMessageBatchWithNotMatchedDBVAreDropped(t, f, false)
 }
 
+func TestSnapshotProgressIsRecordedByTransport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	trans, _, stopper, tt := newTestTransport(false)
+	defer trans.serverCtx.Stop()
+	defer tt.cleanup()
+	defer trans.Stop()
+	defer stopper.Stop()
+	start := time.Now()
+	chunk := raftpb.SnapshotChunk{ClusterId: 100, NodeId: 2, Index: 200, Term: 3}
+	reqs := []raftpb.Message{
+		{Type: raftpb.Heartbeat, ClusterId: 100, From: 2},
+		{
+			Type:      raftpb.SnapshotProgress,
+			ClusterId: 100,
+			From:      2,
+			LogIndex:  200,
+			LogTerm:   3,
+			Hint:      5,
+			HintHigh:  getSnapshotID(chunk),
+		},
+	}
+	result := trans.handleSnapshotProgress(reqs)
+	if len(result) != 1 || result[0].Type != raftpb.Heartbeat {
+		t.Fatalf("unexpected result %v", result)
+	}
+	if next, ok := trans.getSnapshotProgress(chunk, start); !ok || next != 5 {
+		t.Errorf("progress not recorded, %d, %t", next, ok)
+	}
+	if _, ok := trans.getSnapshotProgress(chunk, time.Now()); ok {
+		t.Errorf("progress reported before the specified time returned")
+	}
+	chunk.Data = []byte{1}
+	if _, ok := trans.getSnapshotProgress(chunk, start); ok {
+		t.Errorf("progress of another snapshot returned")
+	}
+	chunk.Data = nil
+	chunk.Index = 300
+	if _, ok := trans.getSnapshotProgress(chunk, start); ok {
+		t.Errorf("progress of another snapshot returned")
+	}
+	chunk.Index = 200
+	// progress reported after the start of the transfer is kept
+	trans.clearSnapshotProgress(chunk, start)
+	if _, ok := trans.getSnapshotProgress(chunk, start); !ok {
+		t.Errorf("progress unexpectedly cleared")
+	}
+	trans.clearSnapshotProgress(chunk, time.Now())
+	if _, ok := trans.getSnapshotProgress(chunk, start); ok {
+		t.Errorf("progress not cleared")
+	}
+}
+
+func TestSnapshotTransferIsOnlyResumedWhenConfirmed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	trans, _, stopper, tt := newTestTransport(false)
+	defer trans.serverCtx.Stop()
+	defer tt.cleanup()
+	defer trans.Stop()
+	defer stopper.Stop()
+	timeout := snapshotResumeTimeout
+	snapshotResumeTimeout = 50 * time.Millisecond
+	defer func() {
+		snapshotResumeTimeout = timeout
+	}()
+	chunk := raftpb.SnapshotChunk{ClusterId: 100, NodeId: 2, Index: 200, Term: 3}
+	progress := raftpb.Message{
+		Type:      raftpb.SnapshotProgress,
+		ClusterId: 100,
+		From:      2,
+		LogIndex:  200,
+		LogTerm:   3,
+		Hint:      5,
+		HintHigh:  getSnapshotID(chunk),
+	}
+	trans.handleSnapshotProgress([]raftpb.Message{progress})
+	// progress of the earlier transfer is not confirmed by the receiver
+	if next := trans.getResumeChunkID(chunk, time.Now()); next != 0 {
+		t.Errorf("transfer resumed from %d without confirmation", next)
+	}
+	sent := time.Now()
+	trans.handleSnapshotProgress([]raftpb.Message{progress})
+	if next := trans.getResumeChunkID(chunk, sent); next != 5 {
+		t.Errorf("transfer resumed from %d, want 5", next)
+	}
+	// the receiver lost the partially received snapshot
+	sent = time.Now()
+	progress.Hint = 1
+	trans.handleSnapshotProgress([]raftpb.Message{progress})
+	if next := trans.getResumeChunkID(chunk, sent); next != 0 {
+		t.Errorf("transfer resumed from %d, want 0", next)
+	}
+}
+
 func TestCircuitBreaker(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	breaker := netutil.NewBreaker()
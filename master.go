@@ -44,6 +44,13 @@ type ClusterInfo struct {
 	// Incomplete is a boolean flag indicating whether the ClusterInfo record
 	// has the Nodes map intentionally omitted to save bandwidth
 	Incomplete bool
+	// PreferredLeader is the NodeID of the preferred leader known to this
+	// node, it converges on the value used by the leader. 0 means there is no
+	// preferred leader.
+	PreferredLeader uint64
+	// PreferredRegion is the list of NodeIDs in the preferred region known to
+	// this node, it converges on the value used by the leader.
+	PreferredRegion []uint64
 }
 
 // NodeHostInfo provides info about the NodeHost, including its managed Raft
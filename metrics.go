@@ -0,0 +1,217 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dragonboat
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lni/dragonboat/internal/metrics"
+	"github.com/lni/dragonboat/internal/raft"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+const (
+	metricsPath = "/metrics"
+)
+
+// engineMetrics are the metrics recorded by the execEngine. all histograms
+// are nil when metrics are not enabled.
+type engineMetrics struct {
+	step        *metrics.Histogram
+	commit      *metrics.Histogram
+	apply       *metrics.Histogram
+	save        *metrics.Histogram
+	saveUpdates *metrics.Histogram
+	saveEntries *metrics.Histogram
+}
+
+func newEngineMetrics(r *metrics.Registry) *engineMetrics {
+	return &engineMetrics{
+		step: r.Histogram("dragonboat_raft_step_duration_seconds",
+			"Time taken to step raft nodes in a worker iteration.",
+			metrics.LatencyBuckets),
+		commit: r.Histogram("dragonboat_raft_commit_duration_seconds",
+			"Time taken to process and commit raft updates in a worker iteration.",
+			metrics.LatencyBuckets),
+		apply: r.Histogram("dragonboat_sm_apply_duration_seconds",
+			"Time taken to apply committed entries in a worker iteration.",
+			metrics.LatencyBuckets),
+		save: r.Histogram("dragonboat_logdb_save_raft_state_duration_seconds",
+			"Time taken by the SaveRaftState method of the LogDB.",
+			metrics.LatencyBuckets),
+		saveUpdates: r.Histogram("dragonboat_logdb_save_raft_state_batch_updates",
+			"Number of raft updates saved in each SaveRaftState call.",
+			metrics.SizeBuckets),
+		saveEntries: r.Histogram("dragonboat_logdb_save_raft_state_batch_entries",
+			"Number of entries saved in each SaveRaftState call.",
+			metrics.SizeBuckets),
+	}
+}
+
+func (m *engineMetrics) raftStateSaved(start time.Time, updates []pb.Update) {
+	if m.save == nil {
+		return
+	}
+	m.save.ObserveSince(start)
+	entries := 0
+	for _, ud := range updates {
+		entries += len(ud.EntriesToSave)
+	}
+	m.saveUpdates.Observe(float64(len(updates)))
+	m.saveEntries.Observe(float64(entries))
+}
+
+// nodeMetrics are the per node metrics. nodeMetrics is nil when metrics are
+// not enabled.
+type nodeMetrics struct {
+	proposals       *metrics.Counter
+	reads           *metrics.Counter
+	elections       *metrics.Counter
+	leaderChanges   *metrics.Counter
+	snapshotSave    *metrics.Histogram
+	snapshotRecover *metrics.Histogram
+	inMemLogSize    *metrics.Gauge
+	rateLimited     *metrics.Gauge
+	leaderID        uint64
+	term            uint64
+	candidate       bool
+}
+
+func newNodeMetrics(r *metrics.Registry,
+	clusterID uint64, nodeID uint64) *nodeMetrics {
+	if r == nil {
+		return nil
+	}
+	labels := []string{
+		"cluster_id", strconv.FormatUint(clusterID, 10),
+		"node_id", strconv.FormatUint(nodeID, 10),
+	}
+	return &nodeMetrics{
+		proposals: r.Counter("dragonboat_proposals_total",
+			"Number of proposals made.", labels...),
+		reads: r.Counter("dragonboat_reads_total",
+			"Number of ReadIndex based reads made.", labels...),
+		elections: r.Counter("dragonboat_elections_total",
+			"Number of elections started by the node.", labels...),
+		leaderChanges: r.Counter("dragonboat_leader_changes_total",
+			"Number of leader changes observed by the node.", labels...),
+		snapshotSave: r.Histogram("dragonboat_snapshot_save_duration_seconds",
+			"Time taken to save snapshots.", metrics.DurationBuckets, labels...),
+		snapshotRecover: r.Histogram(
+			"dragonboat_snapshot_recover_duration_seconds",
+			"Time taken to recover from snapshots.",
+			metrics.DurationBuckets, labels...),
+		inMemLogSize: r.Gauge("dragonboat_rate_limiter_in_mem_log_size_bytes",
+			"In memory log size tracked by the rate limiter.", labels...),
+		rateLimited: r.Gauge("dragonboat_rate_limiter_limited",
+			"Whether the node is currently rate limited.", labels...),
+	}
+}
+
+func (m *nodeMetrics) proposed() {
+	if m != nil {
+		m.proposals.Inc()
+	}
+}
+
+func (m *nodeMetrics) proposedBatch(count int) {
+	if m != nil {
+		m.proposals.Add(uint64(count))
+	}
+}
+
+func (m *nodeMetrics) read() {
+	if m != nil {
+		m.reads.Inc()
+	}
+}
+
+func (m *nodeMetrics) snapshotSaved(start time.Time) {
+	if m != nil {
+		m.snapshotSave.ObserveSince(start)
+	}
+}
+
+func (m *nodeMetrics) snapshotRecovered(start time.Time) {
+	if m != nil {
+		m.snapshotRecover.ObserveSince(start)
+	}
+}
+
+// update records the raft status and the rate limiter state. it is suppose
+// to be called by the step worker while holding the raftMu.
+func (m *nodeMetrics) update(peer *raft.Peer) {
+	if m == nil {
+		return
+	}
+	status := peer.LocalStatus()
+	if status.LeaderID != m.leaderID {
+		if status.LeaderID != raft.NoLeader {
+			m.leaderChanges.Inc()
+		}
+		m.leaderID = status.LeaderID
+	}
+	candidate := status.IsCandidate()
+	if candidate && (!m.candidate || status.Term != m.term) {
+		m.elections.Inc()
+	}
+	m.candidate = candidate
+	m.term = status.Term
+	rl := peer.GetRateLimiter()
+	if rl.Enabled() {
+		m.inMemLogSize.Set(float64(rl.GetInMemLogSize()))
+		if rl.RateLimited() {
+			m.rateLimited.Set(1)
+		} else {
+			m.rateLimited.Set(0)
+		}
+	}
+}
+
+func (nh *NodeHost) startMetricsServer() {
+	if nh.metrics == nil {
+		return
+	}
+	ln, err := net.Listen("tcp", nh.nhConfig.MetricsAddress)
+	if err != nil {
+		plog.Panicf("failed to listen on metrics address %s, %v",
+			nh.nhConfig.MetricsAddress, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, nh.metrics.Handler())
+	nh.metricsServer = &http.Server{Handler: mux}
+	plog.Infof("metrics served at http://%s%s", ln.Addr(), metricsPath)
+	nh.stopper.RunWorker(func() {
+		if err := nh.metricsServer.Serve(ln); err != http.ErrServerClosed {
+			plog.Errorf("metrics server failed, %v", err)
+		}
+	})
+}
+
+func (nh *NodeHost) stopMetricsServer() {
+	if nh.metricsServer != nil {
+		if err := nh.metricsServer.Close(); err != nil {
+			plog.Errorf("failed to close the metrics server, %v", err)
+		}
+	}
+}
+
+func (nh *NodeHost) removeNodeMetrics(clusterID uint64, nodeID uint64) {
+	nh.metrics.Remove("cluster_id", strconv.FormatUint(clusterID, 10),
+		"node_id", strconv.FormatUint(nodeID, 10))
+}
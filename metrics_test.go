@@ -0,0 +1,73 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !dragonboat_slowtest
+// +build !dragonboat_errorinjectiontest
+
+package dragonboat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lni/dragonboat/internal/metrics"
+	pb "github.com/lni/dragonboat/raftpb"
+)
+
+func TestMetricsCanBeUsedWhenNotEnabled(t *testing.T) {
+	em := newEngineMetrics(nil)
+	em.step.ObserveSince(time.Now())
+	em.raftStateSaved(time.Now(), []pb.Update{{}})
+	nm := newNodeMetrics(nil, 1, 2)
+	if nm != nil {
+		t.Errorf("node metrics unexpectedly created")
+	}
+	nm.proposed()
+	nm.read()
+	nm.snapshotSaved(time.Now())
+	nm.snapshotRecovered(time.Now())
+	nm.update(nil)
+}
+
+func TestRaftStateSavedIsRecorded(t *testing.T) {
+	r := metrics.NewRegistry()
+	em := newEngineMetrics(r)
+	updates := []pb.Update{
+		{EntriesToSave: []pb.Entry{{Index: 1}, {Index: 2}}},
+		{EntriesToSave: []pb.Entry{{Index: 3}}},
+	}
+	em.raftStateSaved(time.Now(), updates)
+	if em.save.Count() != 1 {
+		t.Errorf("save latency not recorded")
+	}
+	if em.saveUpdates.Count() != 1 || em.saveEntries.Count() != 1 {
+		t.Errorf("batch sizes not recorded")
+	}
+}
+
+func TestNodeMetricsAreLabelled(t *testing.T) {
+	r := metrics.NewRegistry()
+	nm := newNodeMetrics(r, 1, 2)
+	nm.proposed()
+	nm.proposed()
+	nm.read()
+	c := r.Counter("dragonboat_proposals_total", "", "cluster_id", "1", "node_id", "2")
+	if c.Value() != 2 {
+		t.Errorf("proposal count %d, want 2", c.Value())
+	}
+	c = r.Counter("dragonboat_reads_total", "", "node_id", "2", "cluster_id", "1")
+	if c.Value() != 1 {
+		t.Errorf("read count %d, want 1", c.Value())
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/lni/dragonboat/client"
 	"github.com/lni/dragonboat/config"
 	"github.com/lni/dragonboat/internal/logdb"
+	"github.com/lni/dragonboat/internal/metrics"
 	"github.com/lni/dragonboat/internal/raft"
 	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/server"
@@ -32,6 +33,7 @@ import (
 	"github.com/lni/dragonboat/raftio"
 	pb "github.com/lni/dragonboat/raftpb"
 	sm "github.com/lni/dragonboat/statemachine"
+	"github.com/lni/dragonboat/tracing"
 )
 
 const (
@@ -48,9 +50,11 @@ var (
 type node struct {
 	readReqCount         uint64
 	leaderID             uint64
+	draining             uint32
 	raftAddress          string
 	config               config.Config
 	confChangeC          <-chan *RequestState
+	snapshotC            <-chan rsm.SnapshotRequest
 	commitC              chan<- rsm.Commit
 	mq                   *server.MessageQueue
 	lastApplied          uint64
@@ -64,6 +68,7 @@ type node struct {
 	pendingProposals     *pendingProposal
 	pendingReadIndexes   *pendingReadIndex
 	pendingConfigChange  *pendingConfigChange
+	pendingSnapshot      *pendingSnapshot
 	raftMu               sync.Mutex
 	node                 *raft.Peer
 	logreader            *logdb.LogReader
@@ -78,10 +83,23 @@ type node struct {
 	closeOnce            sync.Once
 	ss                   *snapshotState
 	snapshotLock         *syncutil.Lock
+	metrics              *nodeMetrics
+	raftEvents           *raftEventListener
+	traces               *proposalTracer
+	staleness            *staleReadTracker
+	notifiedLeaderID     uint64
+	notifiedTerm         uint64
 	initializedMu        struct {
 		sync.Mutex
 		initialized bool
 	}
+	streamMu struct {
+		sync.Mutex
+		requests []pb.Message
+		// envs contains snapshot environments of the snapshots being streamed,
+		// their temp dirs are removed once the transfers are completed
+		envs map[uint64]*server.SnapshotEnv
+	}
 	quiesceManager
 }
 
@@ -98,14 +116,20 @@ func newNode(raftAddress string,
 	requestStatePool *sync.Pool,
 	config config.Config,
 	tickMillisecond uint64,
-	ldb raftio.ILogDB) *node {
+	ldb raftio.ILogDB,
+	registry *metrics.Registry,
+	raftEvents *raftEventListener,
+	tracer tracing.ITracer) *node {
 	proposals := newEntryQueue(incomingProposalsMaxLen, lazyFreeCycle)
 	readIndexes := newReadIndexQueue(incomingReadIndexMaxLen)
 	confChangeC := make(chan *RequestState, 1)
+	snapshotC := make(chan rsm.SnapshotRequest, 1)
 	pp := newPendingProposal(requestStatePool,
-		proposals, config.ClusterID, config.NodeID, raftAddress, tickMillisecond)
+		proposals, config.ClusterID, config.NodeID, raftAddress,
+		tickMillisecond, config.EntryCompressionType)
 	pscr := newPendingReadIndex(requestStatePool, readIndexes, tickMillisecond)
 	pcc := newPendingConfigChange(confChangeC, tickMillisecond)
+	ps := newPendingSnapshot(snapshotC, tickMillisecond)
 	lr := logdb.NewLogReader(config.ClusterID, config.NodeID, ldb)
 	rc := &node{
 		config:              config,
@@ -113,11 +137,13 @@ func newNode(raftAddress string,
 		incomingProposals:   proposals,
 		incomingReadIndexes: readIndexes,
 		confChangeC:         confChangeC,
+		snapshotC:           snapshotC,
 		commitReady:         commitReady,
 		stopc:               stopc,
 		pendingProposals:    pp,
 		pendingReadIndexes:  pscr,
 		pendingConfigChange: pcc,
+		pendingSnapshot:     ps,
 		nodeRegistry:        nodeRegistry,
 		snapshotter:         snapshotter,
 		logreader:           lr,
@@ -126,6 +152,10 @@ func newNode(raftAddress string,
 		logdb:               ldb,
 		snapshotLock:        syncutil.NewLock(),
 		ss:                  &snapshotState{},
+		metrics:             newNodeMetrics(registry, config.ClusterID, config.NodeID),
+		raftEvents:          raftEvents,
+		traces:              newProposalTracer(tracer),
+		staleness:           newStaleReadTracker(),
 		quiesceManager: quiesceManager{
 			electionTick: config.ElectionRTT * 2,
 			enabled:      config.Quiesce,
@@ -133,9 +163,12 @@ func newNode(raftAddress string,
 			nodeID:       config.NodeID,
 		},
 	}
+	rc.streamMu.envs = make(map[uint64]*server.SnapshotEnv)
 	nodeProxy := newNodeProxy(rc)
 	ordered := config.OrderedConfigChange
-	sm := rsm.NewStateMachine(dataStore, snapshotter, ordered, nodeProxy)
+	sm := rsm.NewStateMachine(dataStore,
+		snapshotter, ordered, config.IsWitness, nodeProxy,
+		config.ConfigChangeValidator)
 	rc.commitC = sm.CommitC()
 	rc.sm = sm
 	rc.startRaft(config, rc.logreader, peers, initialMember)
@@ -159,9 +192,12 @@ func (rc *node) startRaft(cc config.Config,
 
 func (rc *node) close() {
 	rc.requestRemoval()
+	rc.removeStreamSnapshotEnvs()
 	rc.pendingReadIndexes.close()
 	rc.pendingProposals.close()
 	rc.pendingConfigChange.close()
+	rc.pendingSnapshot.close()
+	rc.raftEvents.NodeUnloaded(rc.clusterID, rc.nodeID)
 }
 
 func (rc *node) stopped() bool {
@@ -188,8 +224,22 @@ func (rc *node) concurrentSnapshot() bool {
 	return rc.sm.ConcurrentSnapshot()
 }
 
+func (rc *node) onDiskStateMachine() bool {
+	return rc.sm.OnDiskStateMachine()
+}
+
+func (rc *node) isWitness() bool {
+	return rc.config.IsWitness
+}
+
 func (rc *node) proposeSession(session *client.Session,
 	handler ICompleteHandler, timeout time.Duration) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
 	if !session.ValidForSessionOp(rc.clusterID) {
 		return nil, ErrInvalidSession
 	}
@@ -199,29 +249,100 @@ func (rc *node) proposeSession(session *client.Session,
 func (rc *node) propose(session *client.Session,
 	cmd []byte, handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
+	return rc.proposeWithTrace(session, cmd, handler, timeout, nil)
+}
+
+func (rc *node) proposeWithTrace(session *client.Session,
+	cmd []byte, handler ICompleteHandler,
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
 	if !session.ValidForProposal(rc.clusterID) {
 		return nil, ErrInvalidSession
 	}
-	return rc.pendingProposals.propose(session, cmd, handler, timeout)
+	rs, err := rc.pendingProposals.proposeWithTrace(session,
+		cmd, handler, timeout, trace)
+	if err == nil {
+		rc.metrics.proposed()
+	}
+	return rs, err
+}
+
+func (rc *node) proposeBatch(session *client.Session,
+	cmds [][]byte, timeout time.Duration) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
+	if !session.ValidForProposal(rc.clusterID) ||
+		session.SeriesID != client.NoOPSeriesID {
+		return nil, ErrInvalidSession
+	}
+	if len(cmds) == 0 {
+		return nil, ErrInvalidOperation
+	}
+	rs, err := rc.pendingProposals.proposeBatch(session, cmds, timeout)
+	if err == nil {
+		rc.metrics.proposedBatch(len(cmds))
+	}
+	return rs, err
 }
 
 func (rc *node) read(handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
-	rs, err := rc.pendingReadIndexes.read(handler, timeout)
+	return rc.readWithTrace(handler, timeout, nil)
+}
+
+func (rc *node) readWithTrace(handler ICompleteHandler,
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
+	rs, err := rc.pendingReadIndexes.readWithTrace(handler, timeout, trace)
 	if err == nil {
 		rs.node = rc
 		rc.increaseReadReqCount()
+		rc.metrics.read()
 	}
 	return rs, err
 }
 
 func (rc *node) requestLeaderTransfer(nodeID uint64) {
+	rc.raftMu.Lock()
+	defer rc.raftMu.Unlock()
 	rc.node.RequestLeaderTransfer(nodeID)
 }
 
+func (rc *node) setPreferredLeader(nodeID uint64) {
+	rc.raftMu.Lock()
+	defer rc.raftMu.Unlock()
+	rc.node.SetPreferredLeader(nodeID)
+}
+
+func (rc *node) setPreferredRegion(region []uint64) {
+	rc.raftMu.Lock()
+	defer rc.raftMu.Unlock()
+	rc.node.SetPreferredRegion(region)
+}
+
 func (rc *node) requestConfigChange(cct pb.ConfigChangeType,
 	nodeID uint64, addr string, orderID uint64,
 	timeout time.Duration) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
 	cc := pb.ConfigChange{
 		Type:           cct,
 		NodeID:         nodeID,
@@ -231,6 +352,35 @@ func (rc *node) requestConfigChange(cct pb.ConfigChangeType,
 	return rc.pendingConfigChange.request(cc, timeout)
 }
 
+func (rc *node) requestMembershipChange(changes []pb.ConfigChange,
+	orderID uint64, timeout time.Duration) (*RequestState, error) {
+	if rc.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	if rc.isDraining() {
+		return nil, ErrNodeHostDraining
+	}
+	cc := pb.ConfigChange{
+		Type:           pb.EnterJoint,
+		ConfigChangeId: orderID,
+		Changes:        changes,
+	}
+	return rc.pendingConfigChange.request(cc, timeout)
+}
+
+func (rc *node) requestSnapshot(opt SnapshotOption,
+	timeout time.Duration) (*RequestState, error) {
+	st := rsm.UserRequestedSnapshot
+	if opt.Exported {
+		if rc.isWitness() {
+			return nil, ErrInvalidOperation
+		}
+		st = rsm.ExportedSnapshot
+	}
+	return rc.pendingSnapshot.request(st, opt.ExportPath,
+		opt.OverrideCompactionOverhead, opt.CompactionOverhead, timeout)
+}
+
 func (rc *node) requestDeleteNodeWithOrderID(nodeID uint64,
 	orderID uint64, timeout time.Duration) (*RequestState, error) {
 	return rc.requestConfigChange(pb.RemoveNode,
@@ -259,6 +409,45 @@ func (rc *node) requestAddObserverWithOrderID(nodeID uint64,
 		nodeID, addr, orderID, timeout)
 }
 
+func (rc *node) requestPromoteObserverWithOrderID(nodeID uint64,
+	orderID uint64, timeout time.Duration) (*RequestState, error) {
+	return rc.requestConfigChange(pb.PromoteObserver,
+		nodeID, "", orderID, timeout)
+}
+
+func (rc *node) requestAddWitnessWithOrderID(nodeID uint64,
+	addr string, orderID uint64, timeout time.Duration) (*RequestState, error) {
+	return rc.requestConfigChange(pb.AddWitness,
+		nodeID, addr, orderID, timeout)
+}
+
+func (rc *node) setDraining(draining bool) {
+	v := uint32(0)
+	if draining {
+		v = 1
+	}
+	rc.raftMu.Lock()
+	defer rc.raftMu.Unlock()
+	atomic.StoreUint32(&rc.draining, v)
+	rc.node.SetDraining(draining)
+}
+
+func (rc *node) isDraining() bool {
+	return atomic.LoadUint32(&rc.draining) == 1
+}
+
+// drained returns a boolean value indicating whether the local node is not
+// the leader and has no in-flight request.
+func (rc *node) drained() bool {
+	leaderID, ok := rc.getLeaderID()
+	if ok && leaderID == rc.nodeID {
+		return false
+	}
+	return rc.pendingProposals.pendingCount() == 0 &&
+		rc.pendingReadIndexes.pendingCount() == 0 &&
+		rc.pendingConfigChange.pendingCount() == 0
+}
+
 func (rc *node) getLeaderID() (uint64, bool) {
 	v := rc.node.GetLeaderID()
 	return v, v != raft.NoLeader
@@ -321,8 +510,11 @@ func (rc *node) publishEntries(ents []pb.Entry) bool {
 	return true
 }
 
-func (rc *node) publishTakeSnapshotRequest() bool {
-	rec := rsm.Commit{SnapshotRequested: true}
+func (rc *node) publishTakeSnapshotRequest(req rsm.SnapshotRequest) bool {
+	rec := rsm.Commit{
+		SnapshotRequested: true,
+		SnapshotRequest:   req,
+	}
 	return rc.publishCommitRec(rec)
 }
 
@@ -359,6 +551,20 @@ func (rc *node) replayLog(clusterID uint64, nodeID uint64) bool {
 			plog.Panicf("failed to apply snapshot %v", err)
 		}
 	}
+	if rc.onDiskStateMachine() {
+		// entries already persisted by the on disk state machine are not going
+		// to be applied again, see rsm.StateMachine for details.
+		index, err := rc.sm.OpenOnDiskStateMachine()
+		if err != nil {
+			panic(err)
+		}
+		if snapshot.Dummy && index < snapshot.Index {
+			plog.Panicf("%s on disk state machine index %d, dummy snapshot %d",
+				rc.describe(), index, snapshot.Index)
+		}
+		plog.Infof("%s will not apply entries up to index %d again",
+			rc.describe(), index)
+	}
 	rs, err := rc.logdb.ReadRaftState(clusterID, nodeID, snapshot.Index)
 	if err == raftio.ErrNoSavedLog {
 		return true
@@ -398,39 +604,59 @@ func isSoftSnapshotError(err error) bool {
 	return err == raft.ErrCompacted || err == raft.ErrSnapshotOutOfDate
 }
 
-func (rc *node) saveSnapshot() {
+func (rc *node) saveSnapshot(rec rsm.Commit) {
+	index := rc.doSaveSnapshot(rec.SnapshotRequest)
+	rc.snapshotRequestDone(rec.SnapshotRequest, index)
+}
+
+func (rc *node) snapshotRequestDone(req rsm.SnapshotRequest, index uint64) {
+	if req.IsPeriodicSnapshot() {
+		return
+	}
+	rc.pendingSnapshot.apply(req.Key, index == 0, index)
+}
+
+func (rc *node) doSaveSnapshot(req rsm.SnapshotRequest) uint64 {
 	// this is suppose to be called in snapshot worker thread.
 	// calling this rc.sm.GetLastApplied() won't block the raft sm.
-	if rc.sm.GetLastApplied() <= rc.ss.getSnapshotIndex() {
+	if !req.IsExportedSnapshot() &&
+		rc.sm.GetLastApplied() <= rc.ss.getSnapshotIndex() {
 		// a snapshot has been published to the sm but not applied yet
 		// or the snapshot has been applied and there is no further progress
-		return
+		return 0
 	}
-	ss, ssenv, err := rc.sm.SaveSnapshot()
+	start := time.Now()
+	ss, ssenv, err := rc.sm.SaveSnapshot(req)
 	if err != nil {
 		if err == sm.ErrSnapshotStopped {
 			ssenv.MustRemoveTempDir()
 			plog.Infof("%s aborted SaveSnapshot", rc.describe())
-			return
+			return 0
 		} else if isSoftSnapshotError(err) {
-			return
+			return 0
 		}
 		panic(err)
 	}
 	plog.Infof("%s snapshotted, index %d, term %d, file count %d",
 		rc.describe(), ss.Index, ss.Term, len(ss.Files))
-	if err := rc.snapshotter.Commit(*ss); err != nil {
+	if err := rc.snapshotter.Commit(*ss, req); err != nil {
 		if err == errSnapshotOutOfDate {
 			plog.Warningf("snapshot aborted on %s, idx %d", rc.describe(), ss.Index)
 			ssenv.MustRemoveTempDir()
-			return
+			return 0
 		}
 		// this can only happen in monkey test
 		if err == sm.ErrSnapshotStopped {
-			return
+			return 0
 		}
 		panic(err)
 	}
+	rc.metrics.snapshotSaved(start)
+	if req.IsExportedSnapshot() {
+		// exported snapshots are not recorded in LogDB, they can't be used for
+		// log compaction
+		return ss.Index
+	}
 	if !ss.Validate() {
 		plog.Panicf("invalid snapshot %v", ss)
 	}
@@ -439,18 +665,24 @@ func (rc *node) saveSnapshot() {
 		if !isSoftSnapshotError(err) {
 			panic(err)
 		} else {
-			return
+			return 0
 		}
 	}
-	if ss.Index > rc.config.CompactionOverhead {
-		rc.ss.setCompactLogTo(ss.Index - rc.config.CompactionOverhead)
+	compactionOverhead := rc.config.CompactionOverhead
+	if req.OverrideCompaction {
+		compactionOverhead = req.CompactionOverhead
+	}
+	if ss.Index > compactionOverhead {
+		rc.ss.setCompactLogTo(ss.Index - compactionOverhead)
 	}
 	rc.ss.setSnapshotIndex(ss.Index)
+	return ss.Index
 }
 
 func (rc *node) recoverFromSnapshot(rec rsm.Commit) (uint64, bool) {
 	rc.snapshotLock.Lock()
 	defer rc.snapshotLock.Unlock()
+	start := time.Now()
 	index, err := rc.sm.RecoverFromSnapshot(rec)
 	if err == sm.ErrSnapshotStopped {
 		plog.Infof("%s aborted its RecoverFromSnapshot", rc.describe())
@@ -459,9 +691,122 @@ func (rc *node) recoverFromSnapshot(rec rsm.Commit) (uint64, bool) {
 	if err != nil {
 		panic(err)
 	}
+	if index > 0 {
+		rc.metrics.snapshotRecovered(start)
+		rc.raftEvents.SnapshotRecovered(rc.clusterID, rc.nodeID, index)
+	}
 	return index, false
 }
 
+func (rc *node) addStreamSnapshotRequest(m pb.Message) {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	rc.streamMu.requests = append(rc.streamMu.requests, m)
+}
+
+func (rc *node) getStreamSnapshotRequests() []pb.Message {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	requests := rc.streamMu.requests
+	rc.streamMu.requests = nil
+	return requests
+}
+
+func (rc *node) hasStreamSnapshotRequest() bool {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	return len(rc.streamMu.requests) > 0
+}
+
+// streamSnapshot generates full snapshots of the on disk state machine for
+// remote nodes that require one. It is suppose to be called in the snapshot
+// worker thread.
+func (rc *node) streamSnapshot() {
+	for _, m := range rc.getStreamSnapshotRequests() {
+		rc.removeStreamSnapshotEnv(m.To)
+		ss, env, err := rc.sm.StreamSnapshot(m.To)
+		if err != nil {
+			if env != nil {
+				env.MustRemoveTempDir()
+			}
+			if err == sm.ErrSnapshotStopped {
+				plog.Infof("%s aborted StreamSnapshot", rc.describe())
+				return
+			}
+			plog.Errorf("%s failed to stream snapshot to %d, %v",
+				rc.describe(), m.To, err)
+			rc.reportStreamSnapshotFailure(m.To)
+			continue
+		}
+		plog.Infof("%s generated snapshot %d for %s, size %d",
+			rc.describe(), ss.Index,
+			logutil.DescribeNode(rc.clusterID, m.To), ss.FileSize)
+		if !rc.addStreamSnapshotEnv(m.To, env) {
+			plog.Infof("%s stopped, streamed snapshot not sent", rc.describe())
+			return
+		}
+		m.Snapshot = *ss
+		rc.sendRaftMessage(m)
+	}
+}
+
+// addStreamSnapshotEnv records the snapshot environment of the snapshot about
+// to be streamed to the specified node. The temp dir is removed and false is
+// returned when the node has been stopped.
+func (rc *node) addStreamSnapshotEnv(nodeID uint64,
+	env *server.SnapshotEnv) bool {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	if rc.stopped() {
+		env.MustRemoveTempDir()
+		return false
+	}
+	rc.streamMu.envs[nodeID] = env
+	return true
+}
+
+// removeStreamSnapshotEnv removes the temp dir of the snapshot streamed to
+// the specified node. It is called once the transfer is completed or when
+// another snapshot is about to be streamed to the same node.
+func (rc *node) removeStreamSnapshotEnv(nodeID uint64) {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	if env, ok := rc.streamMu.envs[nodeID]; ok {
+		env.MustRemoveTempDir()
+		delete(rc.streamMu.envs, nodeID)
+	}
+}
+
+func (rc *node) removeStreamSnapshotEnvs() {
+	rc.streamMu.Lock()
+	defer rc.streamMu.Unlock()
+	for nodeID, env := range rc.streamMu.envs {
+		env.MustRemoveTempDir()
+		delete(rc.streamMu.envs, nodeID)
+	}
+}
+
+// reportWitnessSnapshotSent reports that the witness snapshot has been sent to
+// the specified witness node. witness snapshots only contain metadata, they are
+// sent as regular messages without the snapshot streaming protocol.
+func (rc *node) reportWitnessSnapshotSent(nodeID uint64) {
+	m := pb.Message{
+		Type:   pb.SnapshotStatus,
+		From:   nodeID,
+		Reject: false,
+	}
+	rc.mq.Add(m)
+}
+
+func (rc *node) reportStreamSnapshotFailure(nodeID uint64) {
+	m := pb.Message{
+		Type:   pb.SnapshotStatus,
+		From:   nodeID,
+		Reject: true,
+	}
+	rc.mq.Add(m)
+}
+
 func (rc *node) saveSnapshotDone() {
 	rc.ss.notifySnapshotStatus(true, false, false, 0)
 	rc.commitReady(rc.clusterID)
@@ -479,6 +824,7 @@ func (rc *node) recoverFromSnapshotDone() {
 
 func (rc *node) handleCommit(batch []rsm.Commit,
 	entries []sm.Entry) (rsm.Commit, bool) {
+	rc.traces.applyStarted()
 	return rc.sm.Handle(batch, entries)
 }
 
@@ -516,7 +862,7 @@ func isFreeOrderMessage(m pb.Message) bool {
 }
 
 func (rc *node) sendEnterQuiesceMessages() {
-	nodes, _, _, _ := rc.sm.GetMembership()
+	nodes, _, _, _, _ := rc.sm.GetMembership()
 	for nodeID := range nodes {
 		if nodeID != rc.nodeID {
 			msg := pb.Message{
@@ -534,6 +880,17 @@ func (rc *node) sendMessages(msgs []pb.Message) {
 	for _, msg := range msgs {
 		if !isFreeOrderMessage(msg) {
 			msg.ClusterId = rc.clusterID
+			if msg.Type == pb.InstallSnapshot && msg.Snapshot.Witness {
+				rc.sendRaftMessage(msg)
+				rc.reportWitnessSnapshotSent(msg.To)
+				continue
+			}
+			if msg.Type == pb.InstallSnapshot && msg.Snapshot.Dummy {
+				// dummy snapshots of on disk state machines can't be sent to remote
+				// nodes, full snapshots are generated by the snapshot worker
+				rc.addStreamSnapshotRequest(msg)
+				continue
+			}
 			rc.sendRaftMessage(msg)
 		}
 	}
@@ -561,6 +918,7 @@ func (rc *node) getUpdate() (pb.Update, bool) {
 		for idx := range ud.Messages {
 			ud.Messages[idx].ClusterId = rc.clusterID
 		}
+		rc.traces.appended(ud.EntriesToSave)
 		rc.confirmedLastApplied = rc.lastApplied
 		return ud, true
 	}
@@ -574,6 +932,22 @@ func (rc *node) processReadyToRead(ud pb.Update) {
 	}
 }
 
+// processDroppedRequests notifies clients of dropped requests so they don't
+// have to wait until their requests time out.
+func (rc *node) processDroppedRequests(ud pb.Update) {
+	for _, e := range ud.DroppedEntries {
+		if e.Type == pb.ConfigChangeEntry {
+			rc.pendingConfigChange.dropped(e.Key, ud.LeaderHint)
+		} else {
+			rc.pendingProposals.dropped(e.ClientID,
+				e.SeriesID, e.Key, ud.LeaderHint)
+		}
+	}
+	for _, ctx := range ud.DroppedReadIndexes {
+		rc.pendingReadIndexes.dropped(ctx, ud.LeaderHint)
+	}
+}
+
 func (rc *node) processSnapshot(ud pb.Update) bool {
 	if !pb.IsEmptySnapshot(ud.Snapshot) {
 		if rc.stopped() {
@@ -597,17 +971,19 @@ func (rc *node) applyRaftUpdates(ud pb.Update) bool {
 	if ok := rc.publishEntries(toApply); !ok {
 		return false
 	}
+	rc.traces.committed(toApply)
 	return true
 }
 
 func (rc *node) processRaftUpdate(ud pb.Update) bool {
+	rc.traces.saved(ud.EntriesToSave)
 	rc.logreader.Append(ud.EntriesToSave)
 	rc.sendMessages(ud.Messages)
 	if err := rc.compactLog(); err != nil {
 		panic(err)
 	}
 	if required := rc.saveSnapshotRequired(ud.LastApplied); required {
-		return rc.publishTakeSnapshotRequest()
+		return rc.publishTakeSnapshotRequest(rsm.SnapshotRequest{})
 	}
 	return true
 }
@@ -636,12 +1012,59 @@ func (rc *node) stepNode() (pb.Update, bool) {
 			if rc.newQuiesceState() {
 				rc.sendEnterQuiesceMessages()
 			}
+			rc.metrics.update(rc.node)
+			rc.notifyLeaderUpdate()
+			rc.updateStaleness()
 			return rc.getUpdate()
 		}
 	}
 	return pb.Update{}, false
 }
 
+// notifyLeaderUpdate raises the LeaderUpdated event when the leader or the
+// term observed by the node changed. it is suppose to be called by the step
+// worker while holding the raftMu.
+func (rc *node) notifyLeaderUpdate() {
+	if rc.raftEvents == nil {
+		return
+	}
+	status := rc.node.LocalStatus()
+	if status.LeaderID == rc.notifiedLeaderID &&
+		status.Term == rc.notifiedTerm {
+		return
+	}
+	rc.notifiedLeaderID = status.LeaderID
+	rc.notifiedTerm = status.Term
+	rc.raftEvents.LeaderUpdated(rc.clusterID,
+		rc.nodeID, status.Term, status.LeaderID)
+}
+
+// updateStaleness samples the commit index heard from the leader. it is
+// suppose to be called by the step worker while holding the raftMu.
+func (rc *node) updateStaleness() {
+	commit, heardAt := rc.node.GetLeaderCommit()
+	rc.staleness.update(commit, heardAt, rc.lastApplied)
+}
+
+// staleRead queries the local state machine when its last applied index is
+// no more than maxLag entries behind the commit index heard from the leader.
+func (rc *node) staleRead(query []byte, maxLag uint64) ([]byte, error) {
+	if rc.staleness.lagged(rc.sm.GetLastApplied(), maxLag) {
+		return nil, ErrTooStale
+	}
+	return rc.sm.Lookup(query)
+}
+
+// staleReadWithin queries the local state machine when it is known to have
+// all entries committed more than maxDelay ago.
+func (rc *node) staleReadWithin(query []byte,
+	maxDelay time.Duration) ([]byte, error) {
+	if rc.staleness.delayed(maxDelay, time.Now()) {
+		return nil, ErrTooStale
+	}
+	return rc.sm.Lookup(query)
+}
+
 func (rc *node) handleEvents() bool {
 	hasEvent := false
 	lastApplied := rc.updateBatchedLastApplied()
@@ -660,10 +1083,15 @@ func (rc *node) handleEvents() bool {
 	if rc.handleProposals() {
 		hasEvent = true
 	}
+	if rc.handleSnapshotRequest() {
+		hasEvent = true
+	}
 	if hasEvent {
 		if rc.expireNotified != rc.tickCount {
 			rc.pendingProposals.gc()
 			rc.pendingConfigChange.gc()
+			rc.pendingSnapshot.gc()
+			rc.traces.gc(time.Now())
 			rc.expireNotified = rc.tickCount
 		}
 		rc.pendingReadIndexes.applied(lastApplied)
@@ -679,6 +1107,7 @@ func (rc *node) handleProposals() bool {
 	}
 	entries := rc.incomingProposals.get(rc.rateLimited)
 	if len(entries) > 0 {
+		rc.traces.dequeued(entries, rc.pendingProposals.getTrace)
 		rc.node.ProposeEntries(entries)
 		return true
 	}
@@ -688,6 +1117,7 @@ func (rc *node) handleProposals() bool {
 func (rc *node) handleReadIndexRequests() bool {
 	reqs := rc.incomingReadIndexes.get()
 	if len(reqs) > 0 {
+		rc.traceReadIndexRequests(reqs)
 		rc.recordActivity(pb.ReadIndex)
 		ctx := rc.pendingReadIndexes.peepNextCtx()
 		rc.pendingReadIndexes.addPendingRead(ctx, reqs)
@@ -697,6 +1127,19 @@ func (rc *node) handleReadIndexRequests() bool {
 	return false
 }
 
+func (rc *node) traceReadIndexRequests(reqs []*RequestState) {
+	if rc.traces == nil {
+		return
+	}
+	now := time.Now()
+	for _, req := range reqs {
+		if t := req.trace; t != nil {
+			t.stage(readIndexQueueSpan, t.queued)
+			t.dequeued = now
+		}
+	}
+}
+
 func (rc *node) handleConfigChangeMessage() bool {
 	if len(rc.confChangeC) == 0 {
 		return false
@@ -721,6 +1164,27 @@ func (rc *node) handleConfigChangeMessage() bool {
 	return true
 }
 
+func (rc *node) handleSnapshotRequest() bool {
+	// the node worker is the only producer of the commitC, when there is a free
+	// slot in it, publishing the snapshot request below will not block
+	if len(rc.snapshotC) == 0 || len(rc.commitC) == cap(rc.commitC) {
+		return false
+	}
+	select {
+	case req, ok := <-rc.snapshotC:
+		if !ok {
+			rc.snapshotC = nil
+			return false
+		}
+		plog.Infof("user requested snapshot on %s", rc.describe())
+		return rc.publishTakeSnapshotRequest(req)
+	case <-rc.stopc:
+		return false
+	default:
+		return false
+	}
+}
+
 func (rc *node) isBusySnapshotting() bool {
 	snapshotting := rc.ss.takingSnapshot() || rc.ss.recoveringFromSnapshot()
 	return snapshotting && rc.sm.CommitChanBusy()
@@ -766,6 +1230,9 @@ func (rc *node) handleReceivedMessages() bool {
 					m.ClusterId, rc.clusterID)
 			}
 			rc.tryRecordNodeActivity(m)
+			if m.Type == pb.ReplicateResp {
+				rc.traces.replicated(m)
+			}
 			rc.node.Handle(m)
 		}
 	}
@@ -790,6 +1257,7 @@ func (rc *node) handleMessage(m pb.Message) bool {
 	case pb.SnapshotStatus:
 		plog.Debugf("%s ReportSnapshot from %d, rejected %t",
 			rc.describe(), m.From, m.Reject)
+		rc.removeStreamSnapshotEnv(m.From)
 		rc.node.ReportSnapshotStatus(m.From, m.Reject)
 	case pb.Unreachable:
 		if logUnreachable {
@@ -804,7 +1272,7 @@ func (rc *node) handleMessage(m pb.Message) bool {
 }
 
 func (rc *node) applyUpdate(entry pb.Entry,
-	result uint64, rejected bool, ignored bool, notifyReadClient bool) {
+	result sm.Result, rejected bool, ignored bool, notifyReadClient bool) {
 	if notifyReadClient {
 		rc.pendingReadIndexes.applied(entry.Index)
 	}
@@ -812,6 +1280,7 @@ func (rc *node) applyUpdate(entry pb.Entry,
 		if entry.Key == 0 {
 			plog.Panicf("key is 0")
 		}
+		rc.traces.applied(entry.Key)
 		rc.pendingProposals.applied(entry.ClientID,
 			entry.SeriesID, entry.Key, result, rejected)
 	}
@@ -821,11 +1290,35 @@ func (rc *node) applyConfigChange(cc pb.ConfigChange) {
 	rc.raftMu.Lock()
 	defer rc.raftMu.Unlock()
 	rc.node.ApplyConfigChange(cc)
+	if cc.IsJointConfigChange() {
+		// members removed by the joint config change are only included in the
+		// LeaveJoint config change
+		for _, c := range cc.Changes {
+			if cc.Type == pb.EnterJoint && c.Type == pb.RemoveNode {
+				continue
+			}
+			rc.updateNodeRegistry(c)
+		}
+	} else {
+		rc.updateNodeRegistry(cc)
+	}
+	rc.raftEvents.MembershipChanged(rc.clusterID, rc.nodeID)
+}
+
+func (rc *node) updateNodeRegistry(cc pb.ConfigChange) {
 	switch cc.Type {
 	case pb.AddNode:
+		if len(cc.Address) == 0 {
+			// promoted observer
+			return
+		}
 		rc.nodeRegistry.AddNode(rc.clusterID, cc.NodeID, string(cc.Address))
+	case pb.PromoteObserver:
+		// the address of the promoted observer is already registered
 	case pb.AddObserver:
 		rc.nodeRegistry.AddNode(rc.clusterID, cc.NodeID, string(cc.Address))
+	case pb.AddWitness:
+		rc.nodeRegistry.AddNode(rc.clusterID, cc.NodeID, string(cc.Address))
 	case pb.RemoveNode:
 		if cc.NodeID == rc.nodeID {
 			plog.Infof("%s applied ConfChange Remove for itself", rc.describe())
@@ -851,6 +1344,9 @@ func (rc *node) restoreRemotes(snapshot pb.Snapshot) {
 	for nid, addr := range snapshot.Membership.Observers {
 		rc.nodeRegistry.AddNode(rc.clusterID, nid, addr)
 	}
+	for nid, addr := range snapshot.Membership.Witnesses {
+		rc.nodeRegistry.AddNode(rc.clusterID, nid, addr)
+	}
 	for nid := range snapshot.Membership.Removed {
 		if nid == rc.nodeID {
 			rc.nodeRegistry.RemoveCluster(rc.clusterID)
@@ -859,6 +1355,7 @@ func (rc *node) restoreRemotes(snapshot pb.Snapshot) {
 	}
 	rc.node.RestoreRemotes(snapshot)
 	rc.captureClusterConfig()
+	rc.raftEvents.MembershipChanged(rc.clusterID, rc.nodeID)
 }
 
 func (rc *node) setInitialStatus(index uint64) {
@@ -899,7 +1396,7 @@ func (rc *node) captureClusterConfig() {
 	// this can only be called when RSM is not stepping any updates
 	// currently it is called from a RSM step function and from
 	// ApplySnapshot
-	nodes, _, _, index := rc.sm.GetMembership()
+	nodes, _, _, _, index := rc.sm.GetMembership()
 	if len(nodes) == 0 {
 		plog.Panicf("empty nodes")
 	}
@@ -917,11 +1414,14 @@ func (rc *node) captureClusterConfig() {
 
 func (rc *node) getClusterInfo() *ClusterInfo {
 	v := rc.clusterInfo.Load()
+	preferredLeader, preferredRegion := rc.node.GetPreference()
 	if v == nil {
 		return &ClusterInfo{
-			ClusterID: rc.clusterID,
-			NodeID:    rc.nodeID,
-			Pending:   true,
+			ClusterID:       rc.clusterID,
+			NodeID:          rc.nodeID,
+			Pending:         true,
+			PreferredLeader: preferredLeader,
+			PreferredRegion: preferredRegion,
 		}
 	}
 	ci := v.(*ClusterInfo)
@@ -931,6 +1431,8 @@ func (rc *node) getClusterInfo() *ClusterInfo {
 		IsLeader:          rc.isLeader(),
 		ConfigChangeIndex: ci.ConfigChangeIndex,
 		Nodes:             ci.Nodes,
+		PreferredLeader:   preferredLeader,
+		PreferredRegion:   preferredRegion,
 	}
 }
 
@@ -971,7 +1473,7 @@ func (rc *node) getMembershipHash() uint64 {
 func (rc *node) dumpRaftInfoToLog() {
 	if rc.node != nil {
 		addrMap := make(map[uint64]string)
-		nodes, _, _, _ := rc.sm.GetMembership()
+		nodes, _, _, _, _ := rc.sm.GetMembership()
 		for nodeID := range nodes {
 			if nodeID == rc.nodeID {
 				addrMap[nodeID] = rc.raftAddress
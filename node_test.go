@@ -56,7 +56,7 @@ const (
 )
 
 func getMemberNodes(r *rsm.StateMachine) []uint64 {
-	m, _, _, _ := r.GetMembership()
+	m, _, _, _, _ := r.GetMembership()
 	n := make([]uint64, 0)
 	for nid := range m {
 		n = append(n, nid)
@@ -205,7 +205,8 @@ func doGetTestRaftNodes(startID uint64, count int, ordered bool,
 		rootDirFunc := func(cid uint64, nid uint64) string {
 			return snapdir
 		}
-		snapshotter := newSnapshotter(testClusterID, i, rootDirFunc, ldb, nil)
+		snapshotter := newSnapshotter(testClusterID, i,
+			rootDirFunc, ldb, nil, nil, pb.NoCompression)
 		// create the sm
 		sm := &tests.NoOP{}
 		ds := rsm.NewNativeStateMachine(rsm.NewRegularStateMachine(sm), make(chan struct{}))
@@ -236,7 +237,10 @@ func doGetTestRaftNodes(startID uint64, count int, ordered bool,
 			requestStatePool,
 			config,
 			tickMillisecond,
-			ldb)
+			ldb,
+			nil,
+			nil,
+			nil)
 		nodes = append(nodes, node)
 		smList = append(smList, node.sm)
 	}
@@ -299,7 +303,7 @@ func step(nodes []*node) bool {
 		running := node.processRaftUpdate(ud)
 		node.commitRaftUpdate(ud)
 		if ud.LastApplied-node.ss.getReqSnapshotIndex() > node.config.SnapshotEntries {
-			node.saveSnapshot()
+			node.saveSnapshot(rsm.Commit{})
 		}
 		if running {
 			commitRec, snapshotRequired := node.sm.Handle(make([]rsm.Commit, 0), nil)
@@ -309,7 +313,7 @@ func step(nodes []*node) bool {
 						panic(err)
 					}
 				} else if commitRec.SnapshotRequested {
-					node.saveSnapshot()
+					node.saveSnapshot(commitRec)
 				}
 			}
 		}
@@ -379,6 +383,51 @@ func stopNodes(nodes []*node) {
 	}
 }
 
+func TestStreamSnapshotTempDirIsRemoved(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer cleanupTestDir()
+	nodes, _, _, ldb := getTestRaftNodes(1)
+	defer ldb.Close()
+	n := nodes[0]
+	f := func(cid uint64, nid uint64) string {
+		return filepath.Join(raftTestTopDir, "stream-snapshot")
+	}
+	getEnv := func(to uint64) *server.SnapshotEnv {
+		env := server.NewSnapshotEnv(f, 1, 1, 100, to, server.StreamingMode)
+		if err := env.CreateTempDir(); err != nil {
+			t.Fatalf("failed to create temp dir %v", err)
+		}
+		if !n.addStreamSnapshotEnv(to, env) {
+			t.Fatalf("failed to add env")
+		}
+		return env
+	}
+	completed := getEnv(2)
+	pending := getEnv(3)
+	// the transfer to node 2 is completed
+	n.handleMessage(pb.Message{Type: pb.SnapshotStatus, From: 2})
+	if _, err := os.Stat(completed.GetTempDir()); !os.IsNotExist(err) {
+		t.Errorf("temp dir not removed after the transfer, %v", err)
+	}
+	if _, err := os.Stat(pending.GetTempDir()); err != nil {
+		t.Errorf("temp dir of the ongoing transfer removed, %v", err)
+	}
+	stopNodes(nodes)
+	if _, err := os.Stat(pending.GetTempDir()); !os.IsNotExist(err) {
+		t.Errorf("temp dir not removed after the node is closed, %v", err)
+	}
+	env := server.NewSnapshotEnv(f, 1, 1, 200, 2, server.StreamingMode)
+	if err := env.CreateTempDir(); err != nil {
+		t.Fatalf("failed to create temp dir %v", err)
+	}
+	if n.addStreamSnapshotEnv(2, env) {
+		t.Errorf("env added to a closed node")
+	}
+	if _, err := os.Stat(env.GetTempDir()); !os.IsNotExist(err) {
+		t.Errorf("temp dir not removed, %v", err)
+	}
+}
+
 func TestNodeCanBeCreatedAndStarted(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer cleanupTestDir()
@@ -418,7 +467,7 @@ func getProposalTestClient(n *node,
 	stepNodes(nodes, smList, router, 5000)
 	select {
 	case v := <-rs.CompletedC:
-		if v.Completed() && v.GetResult() == cs.ClientID {
+		if v.Completed() && v.GetResult().Value == cs.ClientID {
 			cs.PrepareForPropose()
 			return cs, true
 		}
@@ -443,7 +492,7 @@ func closeProposalTestClient(n *node,
 	stepNodes(nodes, smList, router, 5000)
 	select {
 	case v := <-rs.CompletedC:
-		if v.Completed() && v.GetResult() == session.ClientID {
+		if v.Completed() && v.GetResult().Value == session.ClientID {
 			return
 		}
 	case <-n.stopc:
@@ -472,8 +521,8 @@ func makeCheckedTestProposal(t *testing.T, session *client.Session,
 			t.Errorf("got %d, want %d", v, expectedCode)
 		}
 		if checkResult {
-			if v.GetResult() != expectedResult {
-				t.Errorf("result %d, want %d", v.GetResult(), expectedResult)
+			if v.GetResult().Value != expectedResult {
+				t.Errorf("result %d, want %d", v.GetResult().Value, expectedResult)
 			}
 		}
 	default:
@@ -601,11 +650,45 @@ func TestLeaderIDCanBeQueried(t *testing.T) {
 	runRaftNodeTest(t, false, tf)
 }
 
+func TestLaggingFollowerIsTooStaleForStaleRead(t *testing.T) {
+	tf := func(t *testing.T, nodes []*node,
+		smList []*rsm.StateMachine, router *testMessageRouter, ldb raftio.ILogDB) {
+		leaderID, ok := nodes[0].getLeaderID()
+		if !ok {
+			t.Fatalf("failed to get leader id")
+		}
+		var follower *node
+		for _, n := range nodes {
+			if n.nodeID != leaderID {
+				follower = n
+				break
+			}
+		}
+		status := follower.node.LocalStatus()
+		applied := follower.sm.GetLastApplied()
+		// the commit value is capped by the match value of the lagging follower,
+		// the commit index of the leader is far ahead
+		follower.node.Handle(pb.Message{
+			Type:    pb.Heartbeat,
+			From:    leaderID,
+			To:      follower.nodeID,
+			Term:    status.Term,
+			Commit:  applied,
+			LogTerm: applied + 1000,
+		})
+		follower.updateStaleness()
+		if _, err := follower.staleRead(nil, 100); err != ErrTooStale {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	runRaftNodeTest(t, false, tf)
+}
+
 func TestMembershipCanBeLocallyRead(t *testing.T) {
 	tf := func(t *testing.T, nodes []*node,
 		smList []*rsm.StateMachine, router *testMessageRouter, ldb raftio.ILogDB) {
 		n := nodes[0]
-		v, _, _, _ := n.sm.GetMembership()
+		v, _, _, _, _ := n.sm.GetMembership()
 		if len(v) != 3 {
 			t.Errorf("unexpected member count %d", len(v))
 		}
@@ -1144,7 +1227,7 @@ func TestNodeCanBeAddedWhenOrderIsEnforced(t *testing.T) {
 			t.Errorf("node members not expected: %v", getMemberNodes(node.sm))
 		}
 	}
-	_, _, _, ccid := n.sm.GetMembership()
+	_, _, _, _, ccid := n.sm.GetMembership()
 	rs, err = n.requestAddNodeWithOrderID(5, "a5:5", ccid, time.Duration(2*time.Second))
 	if err != nil {
 		t.Fatalf("request to add node failed")
@@ -1186,7 +1269,7 @@ func TestNodeCanBeDeletedWhenOrderIsEnforced(t *testing.T) {
 			t.Errorf("node members not expected: %v", getMemberNodes(node.sm))
 		}
 	}
-	_, _, _, ccid := n.sm.GetMembership()
+	_, _, _, _, ccid := n.sm.GetMembership()
 	rs, err = n.requestDeleteNodeWithOrderID(2, ccid, time.Duration(2*time.Second))
 	if err != nil {
 		t.Fatalf("request to add node failed")
@@ -1288,8 +1371,8 @@ func TestSnapshotCanBeMadeTwice(t *testing.T) {
 		closeProposalTestClient(n, nodes, smList, router, session)
 		// check we do have snapshots saved on disk
 		for _, node := range nodes {
-			node.saveSnapshot()
-			node.saveSnapshot()
+			node.saveSnapshot(rsm.Commit{})
+			node.saveSnapshot(rsm.Commit{})
 		}
 	}
 	runRaftNodeTest(t, false, tf)
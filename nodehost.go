@@ -73,6 +73,7 @@ package dragonboat // github.com/lni/dragonboat
 import (
 	"context"
 	"errors"
+	"net/http"
 	"reflect"
 	"runtime"
 	"sync"
@@ -83,6 +84,7 @@ import (
 	"github.com/lni/dragonboat/config"
 	"github.com/lni/dragonboat/internal/cpp"
 	"github.com/lni/dragonboat/internal/logdb"
+	"github.com/lni/dragonboat/internal/metrics"
 	"github.com/lni/dragonboat/internal/raft"
 	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/server"
@@ -97,6 +99,7 @@ import (
 	"github.com/lni/dragonboat/raftio"
 	pb "github.com/lni/dragonboat/raftpb"
 	sm "github.com/lni/dragonboat/statemachine"
+	"github.com/lni/dragonboat/tracing"
 )
 
 const (
@@ -138,6 +141,15 @@ var (
 	// ErrInvalidDeadline indicates that the specified deadline is invalid, e.g.
 	// time in the past.
 	ErrInvalidDeadline = errors.New("invalid deadline")
+	// ErrInvalidOperation indicates that the requested operation is not allowed
+	// on the specified node, e.g. making proposals on a witness node.
+	ErrInvalidOperation = errors.New("invalid operation")
+	// ErrInvalidOption indicates that the specified option is invalid, e.g. the
+	// export path of a snapshot request doesn't exist.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrLogDBStatsNotSupported indicates that the Log DB used by NodeHost
+	// doesn't implement the raftio.ILogDBStatsReporter interface.
+	ErrLogDBStatsNotSupported = errors.New("LogDB stats not supported")
 )
 
 // MasterClientFactoryFunc is the factory function for creating a new
@@ -175,6 +187,10 @@ type NodeHost struct {
 	msgHandler       *messageHandler
 	initializedC     chan struct{}
 	transportLatency *sample
+	metrics          *metrics.Registry
+	metricsServer    *http.Server
+	raftEvents       *raftEventListener
+	tracer           tracing.ITracer
 }
 
 // NewNodeHost creates a new NodeHost instance. The returned NodeHost instance
@@ -206,6 +222,13 @@ func NewNodeHostWithMasterClientFactory(nhConfig config.NodeHostConfig,
 	nh.snapshotStatus = newSnapshotFeedback(nh.pushSnapshotStatus)
 	nh.msgHandler = newNodeHostMessageHandler(nh)
 	nh.clusterMu.requests = make(map[uint64]*server.MessageQueue)
+	if len(nhConfig.MetricsAddress) > 0 {
+		nh.metrics = metrics.NewRegistry()
+	}
+	nh.raftEvents = newRaftEventListener(nhConfig.RaftEventListener)
+	if _, noop := nhConfig.Tracer.(tracing.NoopTracer); !noop {
+		nh.tracer = nhConfig.Tracer
+	}
 	nh.createPools()
 	nh.createTransport()
 	if nhConfig.MasterMode() {
@@ -262,6 +285,7 @@ func NewNodeHostWithMasterClientFactory(nhConfig config.NodeHostConfig,
 	nh.stopper.RunWorker(func() {
 		nh.tickWorkerMain()
 	})
+	nh.startMetricsServer()
 	nh.logNodeHostDetails()
 	return nh
 }
@@ -303,6 +327,7 @@ func (nh *NodeHost) Stop() {
 		}
 	}
 	nh.cancel()
+	nh.stopMetricsServer()
 	plog.Debugf("%s is going to stop the nh stopper", nh.describe())
 	if nh.duStopper != nil {
 		nh.duStopper.Stop()
@@ -318,6 +343,7 @@ func (nh *NodeHost) Stop() {
 	plog.Debugf("%s is going to stop the tranport module", nh.describe())
 	nh.transport.Stop()
 	plog.Debugf("%s transport module stopped", nh.describe())
+	nh.raftEvents.stop()
 	if nh.logdb != nil {
 		nh.logdb.Close()
 	} else {
@@ -383,6 +409,25 @@ func (nh *NodeHost) StartConcurrentCluster(nodes map[uint64]string,
 	return nh.startCluster(nodes, join, cf, stopc, config)
 }
 
+// StartOnDiskCluster is similar to the StartCluster method but it is used to
+// add and start a Raft node backed by an IOnDiskStateMachine.
+//
+// The Open method of the IOnDiskStateMachine instance is invoked before this
+// method returns, Raft Log entries up to the index returned by the Open method
+// will not be applied into the state machine again.
+func (nh *NodeHost) StartOnDiskCluster(nodes map[uint64]string,
+	join bool,
+	createStateMachine func(uint64, uint64) sm.IOnDiskStateMachine,
+	config config.Config) error {
+	stopc := make(chan struct{})
+	cf := func(clusterID uint64, nodeID uint64,
+		done <-chan struct{}) rsm.IManagedStateMachine {
+		sm := createStateMachine(clusterID, nodeID)
+		return rsm.NewNativeStateMachine(rsm.NewOnDiskStateMachine(sm), done)
+	}
+	return nh.startCluster(nodes, join, cf, stopc, config)
+}
+
 // StartClusterUsingPlugin adds a new cluster node to the NodeHost and start
 // running the new node. Different from the StartCluster method in which you
 // specify the factory function used for creating the IStateMachine instance,
@@ -419,7 +464,7 @@ func (nh *NodeHost) StopNode(clusterID uint64, nodeID uint64) error {
 }
 
 // SyncPropose makes a synchronous proposal on the Raft cluster specified by
-// the input client session object. It returns the result code returned by
+// the input client session object. It returns the sm.Result value returned by
 // IStateMachine's Update method, or the error encountered. The input byte slice
 // can be reused for other purposes immediate after the return of this method.
 //
@@ -432,34 +477,44 @@ func (nh *NodeHost) StopNode(clusterID uint64, nodeID uint64) error {
 // the Raft paper recommends to crash the client in this highly unlikely
 // event. When the proposal completed successfully, caller must call
 // client.ProposalCompleted() to get it ready to be used in future proposals.
+//
+// When a Tracer is specified in NodeHostConfig, the proposal is traced and
+// its spans are children of the span carried by ctx, see the tracing package
+// for details.
 func (nh *NodeHost) SyncPropose(ctx context.Context,
-	session *client.Session, cmd []byte) (uint64, error) {
+	session *client.Session, cmd []byte) (result sm.Result, err error) {
 	timeout, err := getTimeoutFromContext(ctx)
 	if err != nil {
-		return 0, err
+		return sm.Result{}, err
 	}
-	rs, err := nh.Propose(session, cmd, timeout)
+	trace := newRequestTrace(ctx, nh.tracer, syncProposeSpan, session.ClusterID)
+	defer func() {
+		trace.finish(err)
+	}()
+	rs, err := nh.propose(session, cmd, nil, timeout, trace)
 	if err != nil {
-		return 0, err
+		return sm.Result{}, err
 	}
 	select {
 	case s := <-rs.CompletedC:
 		if s.Timeout() {
-			return 0, ErrTimeout
+			return sm.Result{}, ErrTimeout
 		} else if s.Completed() {
 			rs.Release()
 			return s.GetResult(), nil
 		} else if s.Terminated() {
-			return 0, ErrClusterClosed
+			return sm.Result{}, ErrClusterClosed
 		} else if s.Rejected() {
-			return 0, ErrInvalidSession
+			return sm.Result{}, ErrInvalidSession
+		} else if s.Dropped() {
+			return sm.Result{}, getDroppedError(s)
 		}
 		panic("unknown CompletedC value")
 	case <-ctx.Done():
 		if ctx.Err() == context.Canceled {
-			return 0, ErrCanceled
+			return sm.Result{}, ErrCanceled
 		} else if ctx.Err() == context.DeadlineExceeded {
-			return 0, ErrTimeout
+			return sm.Result{}, ErrTimeout
 		}
 		panic("unknown ctx error")
 	}
@@ -469,11 +524,22 @@ func (nh *NodeHost) SyncPropose(ctx context.Context,
 // cluster. The query byte slice specifies what to query, it will be passed to
 // the Lookup method of the IStateMachine after the system determines that it is
 // safe to perform the local read on IStateMachine. It returns the query result
-// from IStateMachine's Lookup method or the error encountered.
+// from IStateMachine's Lookup method or the error encountered. Similar to the
+// ReadIndex method, the leader lease is used when LeaseRead is enabled.
+//
+// When a Tracer is specified in NodeHostConfig, the read is traced and its
+// spans are children of the span carried by ctx, see the tracing package for
+// details.
 func (nh *NodeHost) SyncRead(ctx context.Context, clusterID uint64,
-	query []byte) ([]byte, error) {
-	v, err := nh.linearizableRead(ctx, clusterID,
+	query []byte) (result []byte, err error) {
+	trace := newRequestTrace(ctx, nh.tracer, syncReadSpan, clusterID)
+	defer func() {
+		trace.finish(err)
+	}()
+	v, err := nh.linearizableRead(ctx, clusterID, trace,
 		func(node *node) (interface{}, error) {
+			start := time.Now()
+			defer trace.stage(smLookupSpan, start)
 			data, err := node.sm.Lookup(query)
 			if err == rsm.ErrClusterClosed {
 				return nil, ErrClusterClosed
@@ -501,6 +567,9 @@ type Membership struct {
 	// Observers is a map of NodeID values to NodeHost Raft addresses for all
 	// observers.
 	Observers map[uint64]string
+	// Witnesses is a map of NodeID values to NodeHost Raft addresses for all
+	// witnesses.
+	Witnesses map[uint64]string
 	// Removed is a set of NodeID values that have been removed from the Raft
 	// cluster. They are not allowed to be added back to the cluster.
 	Removed map[uint64]struct{}
@@ -512,12 +581,14 @@ type Membership struct {
 // only return after its confirmed completion, failure or timeout.
 func (nh *NodeHost) GetClusterMembership(ctx context.Context,
 	clusterID uint64) (*Membership, error) {
-	v, err := nh.linearizableRead(ctx, clusterID,
+	v, err := nh.linearizableRead(ctx, clusterID, nil,
 		func(node *node) (interface{}, error) {
-			members, observers, removed, confChangeID := node.sm.GetMembership()
+			members, observers, witnesses, removed,
+				confChangeID := node.sm.GetMembership()
 			membership := &Membership{
 				Nodes:          members,
 				Observers:      observers,
+				Witnesses:      witnesses,
 				Removed:        removed,
 				ConfigChangeID: confChangeID,
 			}
@@ -542,6 +613,21 @@ func (nh *NodeHost) GetLeaderID(clusterID uint64) (uint64, bool, error) {
 	return nodeID, valid, nil
 }
 
+// GetLogDBStats returns the statistics of the Log DB used by NodeHost. The
+// per Raft node statistics include the stored entry count, first and last
+// entry index, estimated entry bytes, snapshot record count and snapshot bytes.
+// When the built-in Log DB is used, statistics reported by the key-value store
+// of each Log DB shard are also included. GetLogDBStats scans all stored log
+// entries, it is not suppose to be frequently called. ErrLogDBStatsNotSupported
+// is returned when the Log DB is not capable of reporting its statistics.
+func (nh *NodeHost) GetLogDBStats() (raftio.LogDBStats, error) {
+	r, ok := nh.logdb.(raftio.ILogDBStatsReporter)
+	if !ok {
+		return raftio.LogDBStats{}, ErrLogDBStatsNotSupported
+	}
+	return r.GetLogDBStats()
+}
+
 // GetNoOPSession returns a NO-OP client session ready to be used for
 // making proposals. The NO-OP client session is a dummy client session that
 // will not be checked or enforced. Use this No-OP client session when you
@@ -580,7 +666,7 @@ func (nh *NodeHost) GetNewSession(ctx context.Context,
 	}
 	select {
 	case r := <-rs.CompletedC:
-		if r.Completed() && r.GetResult() == cs.ClientID {
+		if r.Completed() && r.GetResult().Value == cs.ClientID {
 			cs.PrepareForPropose()
 			return cs, nil
 		} else if r.Rejected() {
@@ -589,9 +675,11 @@ func (nh *NodeHost) GetNewSession(ctx context.Context,
 			return nil, ErrTimeout
 		} else if r.Terminated() {
 			return nil, ErrClusterClosed
+		} else if r.Dropped() {
+			return nil, getDroppedError(r)
 		}
 		plog.Panicf("unknown code value %v, result %d, client id %d",
-			r, r.GetResult(), cs.ClientID)
+			r, r.GetResult().Value, cs.ClientID)
 	case <-ctx.Done():
 		if ctx.Err() == context.Canceled {
 			return nil, ErrCanceled
@@ -620,7 +708,7 @@ func (nh *NodeHost) CloseSession(ctx context.Context,
 	}
 	select {
 	case r := <-rs.CompletedC:
-		if r.Completed() && r.GetResult() == session.ClientID {
+		if r.Completed() && r.GetResult().Value == session.ClientID {
 			return nil
 		} else if r.Rejected() {
 			return ErrRejected
@@ -628,6 +716,8 @@ func (nh *NodeHost) CloseSession(ctx context.Context,
 			return ErrTimeout
 		} else if r.Terminated() {
 			return ErrClusterClosed
+		} else if r.Dropped() {
+			return getDroppedError(r)
 		}
 		plog.Panicf("unknown v code %v, client id %d",
 			r, session.ClientID)
@@ -663,7 +753,36 @@ func (nh *NodeHost) CloseSession(ctx context.Context,
 // session ready to be used in future proposals.
 func (nh *NodeHost) Propose(session *client.Session, cmd []byte,
 	timeout time.Duration) (*RequestState, error) {
-	return nh.propose(session, cmd, nil, timeout)
+	return nh.propose(session, cmd, nil, timeout, nil)
+}
+
+// ProposeBatch starts an asynchronous proposal on the Raft cluster specified in
+// the Session object to propose all specified commands in one go. Commands in
+// the batch are added to the Raft log in the same order as they are specified
+// and a single RequestState instance is returned for the whole batch. The
+// input byte slices can be reused for other purposes immediate after the
+// return of this method.
+//
+// The batch is completed after all commands in the batch have been applied,
+// the GetBatchResults method of the received RequestResult can then be used
+// to access the value returned by the Update method of the IStateMachine
+// instance for each command. The batch is reported as timed out when any
+// command in the batch is not applied before the timeout.
+//
+// Only NO-OP client sessions can be used for making batch proposals,
+// ErrInvalidSession is returned when a regular client session is specified.
+// ErrPayloadTooBig is returned when any command is too big or when there are
+// more commands than the incoming proposal queue can hold, empty batches are
+// rejected with ErrInvalidOperation.
+func (nh *NodeHost) ProposeBatch(session *client.Session, cmds [][]byte,
+	timeout time.Duration) (*RequestState, error) {
+	v, ok := nh.getCluster(session.ClusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	req, err := v.proposeBatch(session, cmds, timeout)
+	nh.execEngine.setNodeReady(session.ClusterID)
+	return req, err
 }
 
 // ProposeSession starts an asynchronous proposal on the specified cluster
@@ -690,9 +809,14 @@ func (nh *NodeHost) ProposeSession(session *client.Session,
 // ReadIndex operation. On a successful completion, the ReadLocal method can
 // then be invoked to query the state of the IStateMachine to complete the read
 // operation with linearizability guarantee.
+//
+// When the cluster is configured with the LeaseRead option, the leader node
+// confirms its leadership using its lease and skips the heartbeat round trip
+// required by the ReadIndex protocol. It automatically falls back to the
+// ReadIndex protocol when the lease has expired.
 func (nh *NodeHost) ReadIndex(clusterID uint64,
 	timeout time.Duration) (*RequestState, error) {
-	rs, _, err := nh.readIndex(clusterID, nil, timeout)
+	rs, _, err := nh.readIndex(clusterID, nil, timeout, nil)
 	return rs, err
 }
 
@@ -707,6 +831,9 @@ func (nh *NodeHost) ReadLocal(clusterID uint64,
 	if !ok {
 		return nil, ErrClusterNotFound
 	}
+	if v.isWitness() {
+		return nil, ErrInvalidOperation
+	}
 	// translate the rsm.ErrClusterClosed to ErrClusterClosed
 	// internally, the IManagedStateMachine might obtain a RLock before performing
 	// the local read. The critical section is used to make sure we don't read
@@ -738,6 +865,54 @@ func (nh *NodeHost) ReadLocalNode(rs *RequestState,
 	return data, err
 }
 
+// StaleRead queries the specified Raft node without contacting the leader.
+// The query is only served when the last applied index of the local state
+// machine is no more than maxLag entries behind the most recent commit index
+// the node heard from the leader, ErrTooStale is returned otherwise. It can be
+// called on followers and observers to serve reads with bounded staleness.
+//
+// The commit index heard from the leader is not updated when the node is
+// partitioned from the leader, use StaleReadWithin to also bound the staleness
+// in time.
+func (nh *NodeHost) StaleRead(clusterID uint64,
+	query []byte, maxLag uint64) ([]byte, error) {
+	v, ok := nh.getClusterNotLocked(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	if v.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	data, err := v.staleRead(query, maxLag)
+	if err == rsm.ErrClusterClosed {
+		return nil, ErrClusterClosed
+	}
+	return data, err
+}
+
+// StaleReadWithin queries the specified Raft node without contacting the
+// leader. The query is only served when the local state machine is known to
+// have applied all entries committed by the leader more than maxDelay ago,
+// ErrTooStale is returned otherwise. The staleness is tracked using the
+// commit index carried by messages from the leader, maxDelay should thus be
+// larger than the heartbeat interval. Nodes in quiesced clusters do not
+// receive heartbeats and will soon be considered as too stale.
+func (nh *NodeHost) StaleReadWithin(clusterID uint64,
+	query []byte, maxDelay time.Duration) ([]byte, error) {
+	v, ok := nh.getClusterNotLocked(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	if v.isWitness() {
+		return nil, ErrInvalidOperation
+	}
+	data, err := v.staleReadWithin(query, maxDelay)
+	if err == rsm.ErrClusterClosed {
+		return nil, ErrClusterClosed
+	}
+	return data, err
+}
+
 // RequestDeleteNode is a Raft cluster membership change method for requesting
 // the specified node to be removed from the specified Raft cluster. It starts
 // an asynchronous request to remove the node from the Raft cluster membership
@@ -809,9 +984,9 @@ func (nh *NodeHost) RequestAddNode(clusterID uint64,
 // Such observer is able to receive replicated states from the leader node, but
 // it is not allowed to vote for leader, it is not considered as a part of
 // the quorum when replicating state. An observer can be promoted to a regular
-// node with voting power by making a RequestAddNode call using its clusterID
-// and nodeID values. An observer can be removed from the cluster by calling
-// RequestDeleteNode with its clusterID and nodeID values.
+// node with voting power by making a RequestPromoteObserver or RequestAddNode
+// call using its clusterID and nodeID values. An observer can be removed from
+// the cluster by calling RequestDeleteNode with its clusterID and nodeID values.
 //
 // Application should later call StartCluster with config.Config.IsObserver
 // set to true on the right NodeHost to actually start the observer instance.
@@ -836,6 +1011,218 @@ func (nh *NodeHost) RequestAddObserver(clusterID uint64,
 	return req, err
 }
 
+// RequestPromoteObserver is a Raft cluster membership change method for
+// requesting the specified observer to be promoted to a regular node with
+// voting power. It starts an asynchronous request to promote the observer.
+// Application can wait on the CompleteC member of the returned RequestState
+// instance to get notified for the outcome.
+//
+// The promoted node keeps its NodeID, RaftAddress and replication progress, so
+// there is no need to restart it or to transfer another snapshot to it. The
+// request is rejected when the specified node is not an observer. When the raft
+// cluster is created with the OrderedConfigChange config flag set as false, the
+// configChangeIndex parameter is ignored. Otherwise, it should be set to the
+// most recent Config Change Index value returned by the GetClusterMembership
+// method. The requested promote observer operation will be rejected if other
+// membership change has been applied since the call to the
+// GetClusterMembership method.
+func (nh *NodeHost) RequestPromoteObserver(clusterID uint64,
+	nodeID uint64, configChangeIndex uint64,
+	timeout time.Duration) (*RequestState, error) {
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	req, err := v.requestPromoteObserverWithOrderID(nodeID,
+		configChangeIndex, timeout)
+	nh.execEngine.setNodeReady(clusterID)
+	return req, err
+}
+
+// RequestAddWitness is a Raft cluster membership change method for requesting
+// the specified node to be added to the specified Raft cluster as a witness.
+// It starts an asynchronous request to add the specified node as a witness.
+//
+// A witness votes in elections and acknowledges replicated entries, it is thus
+// considered as a part of the quorum. Different from regular nodes, a witness
+// only receives the index and term values of replicated entries, it never
+// applies any entry into the state machine and it is never going to become
+// the leader. A witness can not be promoted to a regular node or an observer.
+// A witness can be removed from the cluster by calling RequestDeleteNode with
+// its clusterID and nodeID values.
+//
+// Application should later call StartCluster with config.Config.IsWitness
+// set to true on the right NodeHost to actually start the witness instance.
+//
+// The input address parameter is the RaftAddress of the NodeHost where the new
+// witness being added will be running. When the raft cluster is created with
+// the OrderedConfigChange config flag set as false, the configChangeIndex
+// parameter is ignored. Otherwise, it should be set to the most recent Config
+// Change Index value returned by the GetClusterMembership method. The requested
+// add witness operation will be rejected if other membership change has been
+// applied since the call to the GetClusterMembership method.
+func (nh *NodeHost) RequestAddWitness(clusterID uint64,
+	nodeID uint64, address string, configChangeIndex uint64,
+	timeout time.Duration) (*RequestState, error) {
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	req, err := v.requestAddWitnessWithOrderID(nodeID,
+		address, configChangeIndex, timeout)
+	nh.execEngine.setNodeReady(clusterID)
+	return req, err
+}
+
+// MembershipChangeType is the type of a single change included in a
+// RequestMembershipChange request.
+type MembershipChangeType uint64
+
+const (
+	// AddNodeChange adds the specified node as a regular node, or promotes
+	// the specified observer to a regular node.
+	AddNodeChange MembershipChangeType = iota
+	// RemoveNodeChange removes the specified node from the cluster.
+	RemoveNodeChange
+	// AddObserverChange adds the specified node as an observer.
+	AddObserverChange
+	// AddWitnessChange adds the specified node as a witness.
+	AddWitnessChange
+	// PromoteObserverChange promotes the specified observer to a regular node.
+	PromoteObserverChange
+)
+
+// MembershipChange is a single change included in a RequestMembershipChange
+// request.
+type MembershipChange struct {
+	// Type is the type of the change.
+	Type MembershipChangeType
+	// NodeID is the NodeID of the node to be changed.
+	NodeID uint64
+	// Address is the RaftAddress of the NodeHost where the node being added
+	// will be running. It is ignored for RemoveNodeChange and when promoting
+	// an observer.
+	Address string
+}
+
+// RequestMembershipChange is a Raft cluster membership change method for
+// requesting multiple nodes to be added, removed or promoted in a single
+// request. It starts an asynchronous request to have the membership changes
+// applied using the joint consensus approach described in section 4.3 of the
+// raft thesis. Application can wait on the CompleteC member of the returned
+// RequestState instance to get notified for the outcome.
+//
+// The Raft cluster first transitions into a joint configuration in which
+// both the old and the new membership are required to agree on elections and
+// log commitment, the returned RequestState is completed once the joint
+// configuration is applied. The leader then automatically transitions the
+// cluster into the new membership, nodes being removed stay in the cluster
+// until such transition is done. All other membership change requests are
+// rejected when the cluster is in the joint configuration.
+//
+// Each change is subject to the same restrictions as the RequestAddNode,
+// RequestDeleteNode, RequestAddObserver, RequestAddWitness and
+// RequestPromoteObserver methods, a node can be included at most once in the
+// changes list. The whole request is rejected when any of the changes can not
+// be applied. When the raft cluster is created with the OrderedConfigChange
+// config flag set as false, the configChangeIndex parameter is ignored.
+// Otherwise, it should be set to the most recent Config Change Index value
+// returned by the GetClusterMembership method.
+func (nh *NodeHost) RequestMembershipChange(clusterID uint64,
+	changes []MembershipChange, configChangeIndex uint64,
+	timeout time.Duration) (*RequestState, error) {
+	if len(changes) == 0 {
+		return nil, ErrInvalidOption
+	}
+	ccs := make([]pb.ConfigChange, 0, len(changes))
+	for _, c := range changes {
+		var cct pb.ConfigChangeType
+		switch c.Type {
+		case AddNodeChange:
+			cct = pb.AddNode
+		case RemoveNodeChange:
+			cct = pb.RemoveNode
+		case AddObserverChange:
+			cct = pb.AddObserver
+		case AddWitnessChange:
+			cct = pb.AddWitness
+		case PromoteObserverChange:
+			cct = pb.PromoteObserver
+		default:
+			return nil, ErrInvalidOption
+		}
+		ccs = append(ccs, pb.ConfigChange{
+			Type:    cct,
+			NodeID:  c.NodeID,
+			Address: c.Address,
+		})
+	}
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	req, err := v.requestMembershipChange(ccs, configChangeIndex, timeout)
+	nh.execEngine.setNodeReady(clusterID)
+	return req, err
+}
+
+// SnapshotOption is the option type used when requesting a snapshot to be
+// created using the RequestSnapshot method.
+type SnapshotOption struct {
+	// CompactionOverhead is the compaction overhead value to use for the
+	// requested snapshot. It is only used when the OverrideCompactionOverhead
+	// field is set to true. See the godoc on config.Config.CompactionOverhead
+	// for more details.
+	CompactionOverhead uint64
+	// OverrideCompactionOverhead determines whether the CompactionOverhead
+	// value specified in config.Config is overridden by the CompactionOverhead
+	// field above. Setting the CompactionOverhead to 0 and this field to true
+	// allows all Raft log entries covered by the snapshot to be compacted.
+	OverrideCompactionOverhead bool
+	// ExportPath is the path of an existing directory where the exported
+	// snapshot will be saved into. It is only used when the Exported field is
+	// set to true.
+	ExportPath string
+	// Exported determines whether the requested snapshot is exported to the
+	// ExportPath directory. Exported snapshots are full snapshots that are not
+	// managed by the system, they are not recorded in the LogDB and they can't be
+	// used for log compaction.
+	Exported bool
+}
+
+// DefaultSnapshotOption is the default SnapshotOption value to use when
+// requesting a snapshot to be created using the RequestSnapshot method.
+var DefaultSnapshotOption SnapshotOption
+
+// RequestSnapshot requests a snapshot to be created for the specified Raft
+// cluster. Application can wait on the CompleteC member of the returned
+// RequestState instance to get notified for the outcome. Once completed, the
+// index of the created snapshot can be obtained by calling the GetResult
+// method of the received RequestResult instance. The request is rejected when
+// there is no applied entry since the last snapshot or when another snapshot
+// is being created. Note that exported snapshots are always created regardless
+// of whether there is any progress since the last snapshot.
+//
+// Snapshots created by the RequestSnapshot method reuse the same process as
+// those automatically created based on config.Config.SnapshotEntries, such
+// snapshots can be used to compact the Raft log as specified in the opt
+// parameter. Exported snapshots are saved into the specified ExportPath
+// directory instead.
+func (nh *NodeHost) RequestSnapshot(clusterID uint64,
+	opt SnapshotOption, timeout time.Duration) (*RequestState, error) {
+	if opt.Exported &&
+		(len(opt.ExportPath) == 0 || !fileutil.Exist(opt.ExportPath)) {
+		return nil, ErrInvalidOption
+	}
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	req, err := v.requestSnapshot(opt, timeout)
+	nh.execEngine.setNodeReady(clusterID)
+	return req, err
+}
+
 // RequestLeaderTransfer makes a request to transfer the leadership of the
 // specified Raft cluster to the target node identified by targetNodeID. It
 // returns an error if the request fails to be started. There is no guarantee
@@ -853,6 +1240,84 @@ func (nh *NodeHost) RequestLeaderTransfer(clusterID uint64,
 	return nil
 }
 
+// SetPreferredLeader sets the node preferred to be the leader of the specified
+// Raft cluster, it overrides the PreferredLeader value in the config.Config
+// used for starting the cluster. The leader automatically transfers the
+// leadership to the preferred node once it is active and caught up. Setting
+// nodeID to 0 removes the preference. SetPreferredLeader can be called on any
+// NodeHost managing a node of the cluster, the preference is forwarded to the
+// leader which broadcasts it to all other nodes. The preference is dropped
+// when the cluster has no leader, it is not persisted and the preference set
+// in the config.Config is used again after restarting a node until the node
+// hears from the leader.
+func (nh *NodeHost) SetPreferredLeader(clusterID uint64, nodeID uint64) error {
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return ErrClusterNotFound
+	}
+	v.setPreferredLeader(nodeID)
+	return nil
+}
+
+// SetPreferredRegion sets the NodeIDs of the voting members located in the
+// region preferred to host the leader of the specified Raft cluster, it
+// overrides the PreferredRegion value in the config.Config used for starting
+// the cluster. A leader outside of the preferred region automatically
+// transfers the leadership to the most caught up active node in the region.
+// Setting an empty region removes the preference. The preferred region is
+// forwarded to and broadcast by the leader in the same way as the preference
+// set by SetPreferredLeader.
+func (nh *NodeHost) SetPreferredRegion(clusterID uint64,
+	region []uint64) error {
+	v, ok := nh.getCluster(clusterID)
+	if !ok {
+		return ErrClusterNotFound
+	}
+	v.setPreferredRegion(region)
+	return nil
+}
+
+// Drain prepares the NodeHost to be stopped, e.g. before restarting it during
+// a rolling upgrade. Once Drain is called, local nodes stop accepting new
+// proposals, reads and membership change requests by returning
+// ErrNodeHostDraining. Leaderships of local nodes are transferred to other
+// recently active voting members, Drain then waits for all in-flight requests
+// to complete. A nil return value indicates that the NodeHost is safe to be
+// stopped. When ctx is done before that, ErrTimeout or ErrCanceled is
+// returned and local nodes resume accepting new requests.
+//
+// Clusters without any other active voting member prevent the NodeHost from
+// being drained. Draining local nodes refuse leaderships transferred to them,
+// including transfers to a preferred leader set on other NodeHost instances.
+// Local nodes can still become the leader again when elected by other
+// members, such leaderships are transferred away again.
+func (nh *NodeHost) Drain(ctx context.Context) error {
+	if _, err := getTimeoutFromContext(ctx); err != nil {
+		return err
+	}
+	plog.Infof("%s is being drained", nh.describe())
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+	for {
+		if nh.drainClusters() {
+			plog.Infof("%s has been drained", nh.describe())
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			nh.forEachCluster(func(cid uint64, node *node) bool {
+				node.setDraining(false)
+				return true
+			})
+			if ctx.Err() == context.Canceled {
+				return ErrCanceled
+			}
+			return ErrTimeout
+		}
+	}
+}
+
 // GetNodeUser returns an INodeUser instance ready to be used to directly make
 // proposals or read index operations without locating the node repeatedly in
 // the NodeHost. A possible use case is when loading a large data set say with
@@ -883,9 +1348,17 @@ func (nh *NodeHost) HasNodeInfo(clusterID uint64, nodeID uint64) bool {
 	return true
 }
 
+// SetSnapshotBandwidthLimit sets the maximum bytes per second allowed for
+// sending and receiving snapshot data on the current NodeHost instance. The
+// limits are shared by all concurrent snapshot streams in the same direction,
+// 0 means unlimited. The new limits take effect immediately.
+func (nh *NodeHost) SetSnapshotBandwidthLimit(send uint64, recv uint64) {
+	nh.transport.SetSnapshotBandwidthLimit(send, recv)
+}
+
 func (nh *NodeHost) propose(s *client.Session,
 	cmd []byte, handler ICompleteHandler,
-	timeout time.Duration) (*RequestState, error) {
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
 	var st time.Time
 	sampled := delaySampled(s)
 	if sampled {
@@ -896,7 +1369,7 @@ func (nh *NodeHost) propose(s *client.Session,
 		return nil, ErrClusterNotFound
 	}
 	v := c.(*node)
-	req, err := v.propose(s, cmd, handler, timeout)
+	req, err := v.proposeWithTrace(s, cmd, handler, timeout, trace)
 	nh.execEngine.setNodeReady(s.ClusterID)
 	if sampled {
 		nh.execEngine.ProposeDelay(s.ClusterID, st)
@@ -905,13 +1378,13 @@ func (nh *NodeHost) propose(s *client.Session,
 }
 
 func (nh *NodeHost) readIndex(clusterID uint64,
-	handler ICompleteHandler,
-	timeout time.Duration) (*RequestState, *node, error) {
+	handler ICompleteHandler, timeout time.Duration,
+	trace *requestTrace) (*RequestState, *node, error) {
 	n, ok := nh.getClusterNotLocked(clusterID)
 	if !ok {
 		return nil, nil, ErrClusterNotFound
 	}
-	req, err := n.read(handler, timeout)
+	req, err := n.readWithTrace(handler, timeout, trace)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -920,13 +1393,13 @@ func (nh *NodeHost) readIndex(clusterID uint64,
 }
 
 func (nh *NodeHost) linearizableRead(ctx context.Context,
-	clusterID uint64,
+	clusterID uint64, trace *requestTrace,
 	f func(n *node) (interface{}, error)) (interface{}, error) {
 	timeout, err := getTimeoutFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	rs, node, err := nh.readIndex(clusterID, nil, timeout)
+	rs, node, err := nh.readIndex(clusterID, nil, timeout, trace)
 	if err != nil {
 		return nil, err
 	}
@@ -939,6 +1412,8 @@ func (nh *NodeHost) linearizableRead(ctx context.Context,
 			return f(node)
 		} else if s.Terminated() {
 			return nil, ErrClusterClosed
+		} else if s.Dropped() {
+			return nil, getDroppedError(s)
 		}
 		panic("unknown completedc code")
 	case <-ctx.Done():
@@ -951,6 +1426,23 @@ func (nh *NodeHost) linearizableRead(ctx context.Context,
 	}
 }
 
+// drainClusters stops all local nodes from accepting new requests and
+// requests local leaders to transfer their leaderships. It returns a boolean
+// value indicating whether all local nodes have been drained.
+func (nh *NodeHost) drainClusters() bool {
+	drained := true
+	nh.forEachCluster(func(cid uint64, node *node) bool {
+		node.setDraining(true)
+		if !node.drained() {
+			drained = false
+			node.requestLeaderTransfer(raft.NoNode)
+			nh.execEngine.setNodeReady(cid)
+		}
+		return true
+	})
+	return drained
+}
+
 func (nh *NodeHost) getClusterNotLocked(clusterID uint64) (*node, bool) {
 	v, ok := nh.clusterMu.clusters.Load(clusterID)
 	if !ok {
@@ -1100,10 +1592,18 @@ func (nh *NodeHost) startCluster(nodes map[uint64]string,
 		return nh.serverCtx.GetSnapshotDir(nh.deploymentID, cid, nid)
 	}
 	snapshotter := newSnapshotter(clusterID, nodeID,
-		getSnapshotDirFunc, nh.logdb, stopc)
+		getSnapshotDirFunc, nh.logdb, stopc, nh.raftEvents,
+		config.SnapshotCompressionType)
 	if err := snapshotter.ProcessOrphans(); err != nil {
 		panic(err)
 	}
+	if config.IsWitness {
+		// the user state machine is never created on witness nodes
+		createStateMachine = func(clusterID uint64, nodeID uint64,
+			done <-chan struct{}) rsm.IManagedStateMachine {
+			return rsm.NewNativeStateMachine(rsm.NewWitnessStateMachine(), done)
+		}
+	}
 	rn := newNode(nh.nhConfig.RaftAddress,
 		addresses,
 		initialMember,
@@ -1117,7 +1617,10 @@ func (nh *NodeHost) startCluster(nodes map[uint64]string,
 		nh.rsPool[nodeID%rsPoolSize],
 		config,
 		nh.nhConfig.RTTMillisecond,
-		nh.logdb)
+		nh.logdb,
+		nh.metrics,
+		nh.raftEvents,
+		nh.tracer)
 	nh.clusterMu.clusters.Store(clusterID, rn)
 	nh.clusterMu.requests[clusterID] = queue
 	nh.clusterMu.csi++
@@ -1141,27 +1644,54 @@ func (nh *NodeHost) createPools() {
 func (nh *NodeHost) createLogDB(nhConfig config.NodeHostConfig,
 	deploymentID uint64) {
 	nhDirs, walDirs := nh.serverCtx.CreateNodeHostDir(deploymentID)
-	nh.serverCtx.CheckNodeHostDir(deploymentID, nh.nhConfig.RaftAddress)
-	var factory config.LogDBFactoryFunc
 	if nhConfig.LogDBFactory != nil {
-		factory = nhConfig.LogDBFactory
-	} else {
-		factory = logdb.OpenLogDB
+		// the type of LogDB created by the factory is only known once it is
+		// opened, it is checked before any raft node is started
+		nh.serverCtx.CheckNodeHostDir(deploymentID, nh.nhConfig.RaftAddress, "")
+		ldb, err := nhConfig.LogDBFactory(nhDirs, walDirs)
+		if err != nil {
+			panic(err)
+		}
+		nh.serverCtx.CheckNodeHostDir(deploymentID,
+			nh.nhConfig.RaftAddress, ldb.Name())
+		plog.Infof("logdb type name: %s", ldb.Name())
+		nh.logdb = ldb
+		return
+	}
+	kvType, err := logdb.GetKVStoreType(nhConfig.LogDBKVStoreType)
+	if err != nil {
+		panic(err)
+	}
+	recorded, err := server.GetNodeHostDirLogDBType(nhDirs[0])
+	if err != nil {
+		panic(err)
 	}
-	logdb, err := factory(nhDirs, walDirs)
+	if len(recorded) == 0 {
+		if err := logdb.CheckKVStoreType(nhDirs[0], kvType); err != nil {
+			panic(err)
+		}
+	}
+	nh.serverCtx.CheckNodeHostDir(deploymentID, nh.nhConfig.RaftAddress, kvType)
+	ldb, err := logdb.OpenLogDBWithKVStore(nhDirs,
+		walDirs, kvType, nhConfig.LogDBShards)
 	if err != nil {
 		panic(err)
 	}
-	plog.Infof("logdb type name: %s", logdb.Name())
-	nh.logdb = logdb
+	plog.Infof("logdb type name: %s", ldb.Name())
+	nh.logdb = ldb
 }
 
 func (nh *NodeHost) createTransport() {
 	getSnapshotDirFunc := func(cid uint64, nid uint64) string {
 		return nh.serverCtx.GetSnapshotDir(nh.deploymentID, cid, nid)
 	}
-	nh.transport = transport.NewTransport(nh.nhConfig,
+	t := transport.NewTransport(nh.nhConfig,
 		nh.serverCtx, nh.nodes, getSnapshotDirFunc)
+	t.SetMetrics(nh.metrics)
+	if nh.raftEvents != nil {
+		t.SetRaftEventListener(nh.raftEvents)
+	}
+	nh.transport = t
 	nh.transport.SetMessageHandler(nh.msgHandler)
 }
 
@@ -1182,6 +1712,7 @@ func (nh *NodeHost) stopNode(clusterID uint64,
 	nh.clusterMu.csi++
 	cluster.close()
 	cluster.notifyOffloaded(rsm.FromNodeHost)
+	nh.removeNodeMetrics(clusterID, cluster.nodeID)
 	return nil
 }
 
@@ -1325,7 +1856,8 @@ func (nh *NodeHost) initialize(ctx context.Context,
 		}
 		nh.createLogDB(nhConfig, did)
 	}
-	nh.execEngine = newExecEngine(nh, nh.serverCtx, nh.logdb, nh.sendNoOPMessage)
+	nh.execEngine = newExecEngine(nh,
+		nh.serverCtx, nh.logdb, nh.sendNoOPMessage, nh.metrics)
 	nh.setRegion(ctx)
 	nh.setInitialized()
 	return nil
@@ -1407,7 +1939,8 @@ func (nh *NodeHost) asyncSendRaftRequest(msg pb.Message) {
 	if nh.isPartitioned() {
 		return
 	}
-	if msg.Type != pb.InstallSnapshot {
+	// witness snapshots only contain metadata, they are sent as regular messages
+	if msg.Type != pb.InstallSnapshot || msg.Snapshot.Witness {
 		nh.transport.ASyncSend(msg)
 		nh.checkTransportLatency(msg.ClusterId, msg.To, msg.From, msg.Term)
 	} else {
@@ -1773,9 +2306,9 @@ func logBuildTagsAndVersion() {
 		devstr = "Dev"
 	}
 	plog.Infof("go version: %s", runtime.Version())
-	plog.Infof("dragonboat version: %d.%d.%d (%s), raftlog type: %s, logdb type: %s",
+	plog.Infof("dragonboat version: %d.%d.%d (%s), raftlog type: %s, logdb kv stores: %v",
 		DragonboatMajor, DragonboatMinor, DragonboatPatch, devstr,
-		raft.RaftLogTypeName, logdb.LogDBType)
+		raft.RaftLogTypeName, logdb.GetKVStoreTypes())
 	plog.Infof("raft entry encoding scheme: %s", pb.RaftEntryEncodingScheme)
 	if runtime.GOOS == "darwin" {
 		plog.Warningf("Running on darwin, don't use darwin for production purposes")
@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"github.com/lni/dragonboat/config"
+	"github.com/lni/dragonboat/internal/server"
 	"github.com/lni/dragonboat/internal/settings"
 	"github.com/lni/dragonboat/internal/tests"
 	"github.com/lni/dragonboat/internal/transport"
@@ -345,6 +346,43 @@ func TestLogDBCanBeExtended(t *testing.T) {
 	}
 }
 
+func TestLogDBFactoryTypeIsRecorded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer os.RemoveAll(singleNodeHostTestDir)
+	os.RemoveAll(singleNodeHostTestDir)
+	c := getTestNodeHostConfig()
+	c.LogDBFactory = func([]string, []string) (raftio.ILogDB, error) {
+		return &noopLogDB{}, nil
+	}
+	nh := NewNodeHost(*c)
+	defer nh.Stop()
+	dirs, lldirs := nh.serverCtx.GetLogDBDirs(nh.deploymentID)
+	for _, dir := range append(dirs, lldirs...) {
+		logdbType, err := server.GetNodeHostDirLogDBType(dir)
+		if err != nil {
+			t.Fatalf("failed to get LogDB type %v", err)
+		}
+		if logdbType != "noopLogDB" {
+			t.Errorf("LogDB type %s, want noopLogDB", logdbType)
+		}
+	}
+}
+
+func TestLogDBStatsNotSupportedIsReported(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer os.RemoveAll(singleNodeHostTestDir)
+	os.RemoveAll(singleNodeHostTestDir)
+	c := getTestNodeHostConfig()
+	c.LogDBFactory = func([]string, []string) (raftio.ILogDB, error) {
+		return &noopLogDB{}, nil
+	}
+	nh := NewNodeHost(*c)
+	defer nh.Stop()
+	if _, err := nh.GetLogDBStats(); err != ErrLogDBStatsNotSupported {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 func TestTCPTransportIsUsedByDefault(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer os.RemoveAll(singleNodeHostTestDir)
@@ -526,8 +564,8 @@ func (n *PST) Lookup(key []byte) []byte {
 }
 
 // Update updates the object.
-func (n *PST) Update(data []byte) uint64 {
-	return uint64(len(data))
+func (n *PST) Update(data []byte) sm.Result {
+	return sm.Result{Value: uint64(len(data))}
 }
 
 // SaveSnapshot saves the state of the object to the provided io.Writer object.
@@ -1079,7 +1117,7 @@ func TestNodeHostSyncIOAPIs(t *testing.T) {
 		if err != nil {
 			t.Errorf("make proposal failed %v", err)
 		}
-		if v != 128 {
+		if v.Value != 128 {
 			t.Errorf("unexpected result")
 		}
 		data, err := nh.SyncRead(ctx, 2, make([]byte, 128))
@@ -1231,6 +1269,111 @@ func TestNodeHostLeadershipTransfer(t *testing.T) {
 	singleNodeHostTest(t, tf)
 }
 
+func TestNodeHostLogDBStatsCanBeReported(t *testing.T) {
+	tf := func(t *testing.T, nh *NodeHost) {
+		cs := nh.GetNoOPSession(2)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if _, err := nh.SyncPropose(ctx, cs, make([]byte, 16)); err != nil {
+			t.Fatalf("make proposal failed %v", err)
+		}
+		stats, err := nh.GetLogDBStats()
+		if err != nil {
+			t.Fatalf("failed to get LogDB stats %v", err)
+		}
+		found := false
+		for _, v := range stats.Clusters {
+			if v.ClusterID == 2 && v.NodeID == 1 {
+				found = true
+				if v.EntryCount == 0 || v.LastIndex < v.FirstIndex {
+					t.Errorf("unexpected cluster stats %+v", v)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("cluster stats not reported")
+		}
+		if len(stats.Shards) == 0 {
+			t.Errorf("shard stats not reported")
+		}
+	}
+	singleNodeHostTest(t, tf)
+}
+
+func TestNodeHostDrainRequiresDeadline(t *testing.T) {
+	tf := func(t *testing.T, nh *NodeHost) {
+		if err := nh.Drain(context.Background()); err != ErrDeadlineNotSet {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	singleNodeHostTest(t, tf)
+}
+
+func TestNodeHostDrainRejectsNewRequests(t *testing.T) {
+	tf := func(t *testing.T, nh *NodeHost) {
+		cs := nh.GetNoOPSession(2)
+		n, ok := nh.getCluster(2)
+		if !ok {
+			t.Fatalf("failed to get node")
+		}
+		n.setDraining(true)
+		_, err := nh.Propose(cs, make([]byte, 16), time.Second)
+		if err != ErrNodeHostDraining {
+			t.Errorf("unexpected error %v", err)
+		}
+		_, err = nh.ReadIndex(2, time.Second)
+		if err != ErrNodeHostDraining {
+			t.Errorf("unexpected error %v", err)
+		}
+		_, err = nh.RequestAddNode(2, 2, "localhost:25000", 0, time.Second)
+		if err != ErrNodeHostDraining {
+			t.Errorf("unexpected error %v", err)
+		}
+		n.setDraining(false)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if _, err := nh.SyncPropose(ctx, cs, make([]byte, 16)); err != nil {
+			t.Errorf("make proposal failed %v", err)
+		}
+	}
+	singleNodeHostTest(t, tf)
+}
+
+func TestNodeHostWithSingleNodeClusterCanNotBeDrained(t *testing.T) {
+	tf := func(t *testing.T, nh *NodeHost) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := nh.Drain(ctx); err != ErrTimeout {
+			t.Errorf("unexpected error %v", err)
+		}
+		n, ok := nh.getCluster(2)
+		if !ok {
+			t.Fatalf("failed to get node")
+		}
+		if n.isDraining() {
+			t.Errorf("node still draining")
+		}
+	}
+	singleNodeHostTest(t, tf)
+}
+
+func TestPreferenceIsReportedInClusterInfo(t *testing.T) {
+	tf := func(t *testing.T, nh *NodeHost) {
+		if err := nh.SetPreferredLeader(2, 1); err != nil {
+			t.Fatalf("failed to set preferred leader %v", err)
+		}
+		if err := nh.SetPreferredRegion(2, []uint64{1}); err != nil {
+			t.Fatalf("failed to set preferred region %v", err)
+		}
+		cil, _ := nh.getClusterInfo()
+		if len(cil) != 1 || cil[0].PreferredLeader != 1 ||
+			len(cil[0].PreferredRegion) != 1 || cil[0].PreferredRegion[0] != 1 {
+			t.Errorf("preference not reported, %v", cil)
+		}
+	}
+	singleNodeHostTest(t, tf)
+}
+
 func TestNodeHostHasNodeInfo(t *testing.T) {
 	tf := func(t *testing.T, nh *NodeHost) {
 		if ok := nh.HasNodeInfo(2, 1); !ok {
@@ -1309,7 +1452,7 @@ func TestConcurrentStateMachineSaveSnapshot(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to make proposal %v", err)
 			}
-			result[v] = struct{}{}
+			result[v.Value] = struct{}{}
 			if len(result) > 1 {
 				return
 			}
@@ -1385,7 +1528,7 @@ func TestRegularStateMachineDoesNotAllowConcurrentSaveSnapshot(t *testing.T) {
 			if err != nil {
 				continue
 			}
-			result[v] = struct{}{}
+			result[v.Value] = struct{}{}
 			if len(result) > 1 {
 				t.Fatalf("unexpected concurrent save snapshot observed")
 			}
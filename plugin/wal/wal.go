@@ -12,23 +12,26 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build dragonboat_custom_logdb
+/*
+Package wal provides a pure Go Log DB implementation that stores Raft logs in
+segmented, checksummed and append only log files. It can be used by setting
+the LogDBFactory field of NodeHostConfig to the NewLogDB function.
 
-package logdb
+	nhc := config.NodeHostConfig{
+		...
+		LogDBFactory: wal.NewLogDB,
+	}
+*/
+package wal
 
 import (
-	"flag"
-)
-
-const (
-	// LogDBType is the logdb type name
-	LogDBType = "custom-noop"
+	"github.com/lni/dragonboat/internal/logdb"
+	"github.com/lni/dragonboat/raftio"
 )
 
-func newKVStore(dir string, wal string) (IKvStore, error) {
-	if v := flag.Lookup("test.v"); v == nil || v.Value.String() != "true" {
-		panic("no suppose to be called")
-	} else {
-		panic("trying to run logdb tests when logdb is diabled")
-	}
+// NewLogDB creates a WAL based Log DB instance. It has the signature of the
+// config.LogDBFactoryFunc type.
+func NewLogDB(dirs []string,
+	lowLatencyDirs []string) (raftio.ILogDB, error) {
+	return logdb.OpenWALLogDB(dirs, lowLatencyDirs)
 }
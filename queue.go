@@ -77,6 +77,25 @@ func (q *entryQueue) add(ent pb.Entry) (bool, bool) {
 	return true, false
 }
 
+// addBatch adds all specified entries to the queue, entries are either all
+// added or none of them is added.
+func (q *entryQueue) addBatch(ents []pb.Entry) (bool, bool) {
+	q.mu.Lock()
+	if q.paused || q.idx+uint64(len(ents)) > q.size {
+		q.mu.Unlock()
+		return false, q.stopped
+	}
+	if q.stopped {
+		q.mu.Unlock()
+		return false, true
+	}
+	w := q.targetQueue()
+	copy(w[q.idx:], ents)
+	q.idx += uint64(len(ents))
+	q.mu.Unlock()
+	return true, false
+}
+
 func (q *entryQueue) gc() {
 	if q.lazyFreeCycle > 0 {
 		oldq := q.targetQueue()
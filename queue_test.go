@@ -219,6 +219,30 @@ func TestEntryQueueAllowAddedEntriesToBeReturned(t *testing.T) {
 	}
 }
 
+func TestEntryQueueAddsBatchAtomically(t *testing.T) {
+	q := newEntryQueue(5, 0)
+	ents := []raftpb.Entry{{Index: 1}, {Index: 2}, {Index: 3}}
+	if ok, stopped := q.addBatch(ents); !ok || stopped {
+		t.Fatalf("failed to add batch")
+	}
+	if ok, stopped := q.addBatch(ents); ok || stopped {
+		t.Fatalf("batch unexpectedly added")
+	}
+	r := q.get(false)
+	if len(r) != 3 {
+		t.Fatalf("len %d, want 3", len(r))
+	}
+	for i, e := range r {
+		if e.Index != uint64(i+1) {
+			t.Errorf("index %d, want %d", e.Index, i+1)
+		}
+	}
+	q.close()
+	if ok, stopped := q.addBatch(ents); ok || !stopped {
+		t.Errorf("batch added to closed queue")
+	}
+}
+
 func TestClusterCanBeSetAsReady(t *testing.T) {
 	rc := newReadyCluster()
 	if len(rc.ready) != 0 {
@@ -0,0 +1,52 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raftio
+
+// NoLeader is the leader ID value used in LeaderUpdated to indicate that
+// there is no known leader.
+const NoLeader uint64 = 0
+
+// IRaftEventListener is the interface to be implemented by applications
+// interested in Raft and system events, e.g. leadership and membership
+// changes. Events are delivered by NodeHost on a dedicated goroutine in the
+// same order as they are raised, a slow IRaftEventListener delays the
+// delivery of following events but it never blocks the Raft nodes.
+type IRaftEventListener interface {
+	// LeaderUpdated is invoked when the specified Raft node observed a leader
+	// or term change. leaderID is NoLeader when there is no known leader in
+	// the specified term.
+	LeaderUpdated(clusterID uint64, nodeID uint64, term uint64, leaderID uint64)
+	// MembershipChanged is invoked when the membership of the cluster observed
+	// by the specified Raft node changed, either because a config change has
+	// been applied or because a snapshot has been recovered.
+	MembershipChanged(clusterID uint64, nodeID uint64)
+	// SnapshotCreated is invoked after the specified Raft node created a
+	// snapshot with the specified index.
+	SnapshotCreated(clusterID uint64, nodeID uint64, index uint64)
+	// SnapshotRecovered is invoked after the specified Raft node recovered its
+	// state machine from the snapshot with the specified index.
+	SnapshotRecovered(clusterID uint64, nodeID uint64, index uint64)
+	// SnapshotReceived is invoked after all chunks of the snapshot with the
+	// specified index sent by node from have been received for the specified
+	// Raft node.
+	SnapshotReceived(clusterID uint64, nodeID uint64, from uint64, index uint64)
+	// NodeUnloaded is invoked after the specified Raft node has been unloaded
+	// from NodeHost.
+	NodeUnloaded(clusterID uint64, nodeID uint64)
+	// ConnectionFailed is invoked when the transport module failed to connect
+	// to the remote NodeHost at the specified address. snapshot indicates
+	// whether the failed connection is used for sending snapshots.
+	ConnectionFailed(address string, snapshot bool)
+}
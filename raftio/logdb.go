@@ -136,3 +136,60 @@ type ILogDB interface {
 	// Raft node.
 	ListSnapshots(clusterID uint64, nodeID uint64) ([]pb.Snapshot, error)
 }
+
+// ClusterStats is the Log DB statistics of a Raft node.
+type ClusterStats struct {
+	// ClusterID is the cluster ID of the Raft node.
+	ClusterID uint64
+	// NodeID is the node ID of the Raft node.
+	NodeID uint64
+	// EntryCount is the number of log entries stored in the Log DB.
+	EntryCount uint64
+	// FirstIndex is the index of the first stored log entry.
+	FirstIndex uint64
+	// LastIndex is the index of the last stored log entry.
+	LastIndex uint64
+	// EntryBytes is the estimated size in bytes of the stored log entries.
+	EntryBytes uint64
+	// SnapshotCount is the number of snapshot records stored in the Log DB.
+	SnapshotCount uint64
+	// SnapshotBytes is the total size in bytes of snapshot files described by
+	// the stored snapshot records.
+	SnapshotBytes uint64
+}
+
+// ShardStats is the statistics reported by the key-value store used by a Log
+// DB shard. Values not supported by the key-value store are reported as 0,
+// Pebble doesn't support any of them.
+type ShardStats struct {
+	// ShardID is the ID of the Log DB shard.
+	ShardID uint64
+	// PendingCompactionBytes is the estimated number of bytes that need to be
+	// rewritten by compactions. It is only supported by RocksDB.
+	PendingCompactionBytes uint64
+	// MemtableSize is the size of all memtables in bytes. It is supported by
+	// RocksDB and LevelDB.
+	MemtableSize uint64
+	// SSTFileCount is the number of live SST files. It is supported by RocksDB
+	// and LevelDB.
+	SSTFileCount uint64
+}
+
+// LogDBStats is the statistics of a Log DB.
+type LogDBStats struct {
+	// Clusters is the list of statistics of Raft nodes found in the Log DB,
+	// sorted by cluster ID and node ID.
+	Clusters []ClusterStats
+	// Shards is the list of statistics of Log DB shards, it is empty when the
+	// Log DB is not backed by sharded key-value stores.
+	Shards []ShardStats
+}
+
+// ILogDBStatsReporter is the optional interface implemented by ILogDB types
+// that can report their statistics.
+type ILogDBStatsReporter interface {
+	// GetLogDBStats returns the statistics of the Log DB. Stored log entries
+	// are scanned to collect the statistics, it is expected to take a while
+	// when there are many stored log entries.
+	GetLogDBStats() (LogDBStats, error)
+}
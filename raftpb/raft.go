@@ -59,6 +59,7 @@ type UpdateCommit struct {
 	StableLogTerm    uint64
 	StableSnapshotTo uint64
 	ReadyToRead      uint64
+	Dropped          bool
 }
 
 // Update is a collection of state, entries and messages that are expected to be
@@ -82,6 +83,16 @@ type Update struct {
 	Snapshot Snapshot
 	// ReadyToReads provides a list of ReadIndex requests ready for local read.
 	ReadyToReads []ReadyToRead
+	// DroppedEntries is a list of proposed entries dropped as the raft node is
+	// not ready to handle them, e.g. there is no leader. Only fields required
+	// for identifying the proposals are available.
+	DroppedEntries []Entry
+	// DroppedReadIndexes is a list of ReadIndex requests dropped as the raft
+	// node is not ready to handle them.
+	DroppedReadIndexes []SystemCtx
+	// LeaderHint is the ID of the node suggested for retrying dropped requests.
+	// It is 0 when no such node is known.
+	LeaderHint uint64
 	// Messages is a list of outgoing messages to be sent to remote nodes.
 	// As stated above, replication messages can be immediately sent, all other
 	// messages must be sent after the persistent state and entries are saved
@@ -102,7 +113,9 @@ func (ud *Update) HasUpdate() bool {
 		len(ud.EntriesToSave) > 0 ||
 		len(ud.CommittedEntries) > 0 ||
 		len(ud.Messages) > 0 ||
-		len(ud.ReadyToReads) != 0
+		len(ud.ReadyToReads) != 0 ||
+		len(ud.DroppedEntries) > 0 ||
+		len(ud.DroppedReadIndexes) > 0
 }
 
 // IsEmptyState returns a boolean flag indicating whether the given State is
@@ -126,6 +139,19 @@ func isStateEqual(a State, b State) bool {
 	return a.Term == b.Term && a.Vote == b.Vote && a.Commit == b.Commit
 }
 
+// IsJointConfigChange returns a boolean value indicating whether the config
+// change is used for entering or leaving the joint consensus configuration.
+func (cc *ConfigChange) IsJointConfigChange() bool {
+	return cc.Type == EnterJoint || cc.Type == LeaveJoint
+}
+
+// IsJoint returns a boolean value indicating whether the membership is in the
+// joint consensus configuration C_old,new described in section 4.3 of the raft
+// thesis.
+func (m *Membership) IsJoint() bool {
+	return len(m.Incoming) > 0 || len(m.Outgoing) > 0
+}
+
 // IsConfigChange returns a boolean value indicating whether the entry is for
 // config change.
 func (e *Entry) IsConfigChange() bool {
@@ -246,6 +272,10 @@ func checkFileSize(path string, size uint64) {
 
 // Validate validates the snapshot instance.
 func (snapshot *Snapshot) Validate() bool {
+	// witness snapshots only contain metadata, no file is involved
+	if snapshot.Witness {
+		return true
+	}
 	if len(snapshot.Filepath) == 0 ||
 		snapshot.FileSize == 0 {
 		return false
@@ -23,32 +23,38 @@ var _ = math.Inf
 type MessageType int32
 
 const (
-	LocalTick         MessageType = 0
-	Election          MessageType = 1
-	LeaderHeartbeat   MessageType = 2
-	ConfigChangeEvent MessageType = 3
-	NoOP              MessageType = 4
-	Ping              MessageType = 5
-	Pong              MessageType = 6
-	Propose           MessageType = 7
-	SnapshotStatus    MessageType = 8
-	Unreachable       MessageType = 9
-	CheckQuorum       MessageType = 10
-	BatchedReadIndex  MessageType = 11
-	Replicate         MessageType = 12
-	ReplicateResp     MessageType = 13
-	RequestVote       MessageType = 14
-	RequestVoteResp   MessageType = 15
-	InstallSnapshot   MessageType = 16
-	Heartbeat         MessageType = 17
-	HeartbeatResp     MessageType = 18
-	ReadIndex         MessageType = 19
-	ReadIndexResp     MessageType = 20
-	Quiesce           MessageType = 21
-	SnapshotReceived  MessageType = 22
-	LeaderTransfer    MessageType = 23
-	TimeoutNow        MessageType = 24
-	RateLimit         MessageType = 25
+	LocalTick          MessageType = 0
+	Election           MessageType = 1
+	LeaderHeartbeat    MessageType = 2
+	ConfigChangeEvent  MessageType = 3
+	NoOP               MessageType = 4
+	Ping               MessageType = 5
+	Pong               MessageType = 6
+	Propose            MessageType = 7
+	SnapshotStatus     MessageType = 8
+	Unreachable        MessageType = 9
+	CheckQuorum        MessageType = 10
+	BatchedReadIndex   MessageType = 11
+	Replicate          MessageType = 12
+	ReplicateResp      MessageType = 13
+	RequestVote        MessageType = 14
+	RequestVoteResp    MessageType = 15
+	InstallSnapshot    MessageType = 16
+	Heartbeat          MessageType = 17
+	HeartbeatResp      MessageType = 18
+	ReadIndex          MessageType = 19
+	ReadIndexResp      MessageType = 20
+	Quiesce            MessageType = 21
+	SnapshotReceived   MessageType = 22
+	LeaderTransfer     MessageType = 23
+	TimeoutNow         MessageType = 24
+	RateLimit          MessageType = 25
+	RequestPreVote     MessageType = 26
+	RequestPreVoteResp MessageType = 27
+	SnapshotProgress   MessageType = 28
+	ProposalDropped    MessageType = 29
+	ReadIndexDropped   MessageType = 30
+	PreferredLeader    MessageType = 31
 )
 
 var MessageType_name = map[int32]string{
@@ -78,34 +84,46 @@ var MessageType_name = map[int32]string{
 	23: "LeaderTransfer",
 	24: "TimeoutNow",
 	25: "RateLimit",
+	26: "RequestPreVote",
+	27: "RequestPreVoteResp",
+	28: "SnapshotProgress",
+	29: "ProposalDropped",
+	30: "ReadIndexDropped",
+	31: "PreferredLeader",
 }
 var MessageType_value = map[string]int32{
-	"LocalTick":         0,
-	"Election":          1,
-	"LeaderHeartbeat":   2,
-	"ConfigChangeEvent": 3,
-	"NoOP":              4,
-	"Ping":              5,
-	"Pong":              6,
-	"Propose":           7,
-	"SnapshotStatus":    8,
-	"Unreachable":       9,
-	"CheckQuorum":       10,
-	"BatchedReadIndex":  11,
-	"Replicate":         12,
-	"ReplicateResp":     13,
-	"RequestVote":       14,
-	"RequestVoteResp":   15,
-	"InstallSnapshot":   16,
-	"Heartbeat":         17,
-	"HeartbeatResp":     18,
-	"ReadIndex":         19,
-	"ReadIndexResp":     20,
-	"Quiesce":           21,
-	"SnapshotReceived":  22,
-	"LeaderTransfer":    23,
-	"TimeoutNow":        24,
-	"RateLimit":         25,
+	"LocalTick":          0,
+	"Election":           1,
+	"LeaderHeartbeat":    2,
+	"ConfigChangeEvent":  3,
+	"NoOP":               4,
+	"Ping":               5,
+	"Pong":               6,
+	"Propose":            7,
+	"SnapshotStatus":     8,
+	"Unreachable":        9,
+	"CheckQuorum":        10,
+	"BatchedReadIndex":   11,
+	"Replicate":          12,
+	"ReplicateResp":      13,
+	"RequestVote":        14,
+	"RequestVoteResp":    15,
+	"InstallSnapshot":    16,
+	"Heartbeat":          17,
+	"HeartbeatResp":      18,
+	"ReadIndex":          19,
+	"ReadIndexResp":      20,
+	"Quiesce":            21,
+	"SnapshotReceived":   22,
+	"LeaderTransfer":     23,
+	"TimeoutNow":         24,
+	"RateLimit":          25,
+	"RequestPreVote":     26,
+	"RequestPreVoteResp": 27,
+	"SnapshotProgress":   28,
+	"ProposalDropped":    29,
+	"ReadIndexDropped":   30,
+	"PreferredLeader":    31,
 }
 
 func (x MessageType) Enum() *MessageType {
@@ -133,15 +151,21 @@ type EntryType int32
 const (
 	ApplicationEntry  EntryType = 0
 	ConfigChangeEntry EntryType = 1
+	MetadataEntry     EntryType = 2
+	EncodedEntry      EntryType = 3
 )
 
 var EntryType_name = map[int32]string{
 	0: "ApplicationEntry",
 	1: "ConfigChangeEntry",
+	2: "MetadataEntry",
+	3: "EncodedEntry",
 }
 var EntryType_value = map[string]int32{
 	"ApplicationEntry":  0,
 	"ConfigChangeEntry": 1,
+	"MetadataEntry":     2,
+	"EncodedEntry":      3,
 }
 
 func (x EntryType) Enum() *EntryType {
@@ -167,20 +191,32 @@ func (EntryType) EnumDescriptor() ([]byte, []int) {
 type ConfigChangeType int32
 
 const (
-	AddNode     ConfigChangeType = 0
-	RemoveNode  ConfigChangeType = 1
-	AddObserver ConfigChangeType = 2
+	AddNode         ConfigChangeType = 0
+	RemoveNode      ConfigChangeType = 1
+	AddObserver     ConfigChangeType = 2
+	AddWitness      ConfigChangeType = 3
+	EnterJoint      ConfigChangeType = 4
+	LeaveJoint      ConfigChangeType = 5
+	PromoteObserver ConfigChangeType = 6
 )
 
 var ConfigChangeType_name = map[int32]string{
 	0: "AddNode",
 	1: "RemoveNode",
 	2: "AddObserver",
+	3: "AddWitness",
+	4: "EnterJoint",
+	5: "LeaveJoint",
+	6: "PromoteObserver",
 }
 var ConfigChangeType_value = map[string]int32{
-	"AddNode":     0,
-	"RemoveNode":  1,
-	"AddObserver": 2,
+	"AddNode":         0,
+	"RemoveNode":      1,
+	"AddObserver":     2,
+	"AddWitness":      3,
+	"EnterJoint":      4,
+	"LeaveJoint":      5,
+	"PromoteObserver": 6,
 }
 
 func (x ConfigChangeType) Enum() *ConfigChangeType {
@@ -239,6 +275,39 @@ func (ChecksumType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_raft_00707ff926eff8f6, []int{3}
 }
 
+type CompressionType int32
+
+const (
+	NoCompression CompressionType = 0
+	Snappy        CompressionType = 1
+)
+
+var CompressionType_name = map[int32]string{
+	0: "NoCompression",
+	1: "Snappy",
+}
+var CompressionType_value = map[string]int32{
+	"NoCompression": 0,
+	"Snappy":        1,
+}
+
+func (x CompressionType) Enum() *CompressionType {
+	p := new(CompressionType)
+	*p = x
+	return p
+}
+func (x CompressionType) String() string {
+	return proto.EnumName(CompressionType_name, int32(x))
+}
+func (x *CompressionType) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(CompressionType_value, data, "CompressionType")
+	if err != nil {
+		return err
+	}
+	*x = CompressionType(value)
+	return nil
+}
+
 type Bootstrap struct {
 	Addresses map[uint64]string `protobuf:"bytes,1,rep,name=addresses" json:"addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Join      bool              `protobuf:"varint,2,opt,name=join" json:"join"`
@@ -292,9 +361,10 @@ func (m *Bootstrap) GetJoin() bool {
 }
 
 type RaftDataStatus struct {
-	Address  string `protobuf:"bytes,1,opt,name=address" json:"address"`
-	BinVer   uint32 `protobuf:"varint,2,opt,name=bin_ver,json=binVer" json:"bin_ver"`
-	HardHash uint64 `protobuf:"varint,3,opt,name=hard_hash,json=hardHash" json:"hard_hash"`
+	Address     string `protobuf:"bytes,1,opt,name=address" json:"address"`
+	BinVer      uint32 `protobuf:"varint,2,opt,name=bin_ver,json=binVer" json:"bin_ver"`
+	HardHash    uint64 `protobuf:"varint,3,opt,name=hard_hash,json=hardHash" json:"hard_hash"`
+	KvStoreType string `protobuf:"bytes,4,opt,name=kv_store_type,json=kvStoreType" json:"kv_store_type"`
 }
 
 func (m *RaftDataStatus) Reset()         { *m = RaftDataStatus{} }
@@ -351,6 +421,13 @@ func (m *RaftDataStatus) GetHardHash() uint64 {
 	return 0
 }
 
+func (m *RaftDataStatus) GetKvStoreType() string {
+	if m != nil {
+		return m.KvStoreType
+	}
+	return ""
+}
+
 type State struct {
 	Term   uint64 `protobuf:"varint,1,opt,name=term" json:"term"`
 	Vote   uint64 `protobuf:"varint,2,opt,name=vote" json:"vote"`
@@ -560,6 +637,9 @@ type Membership struct {
 	Addresses      map[uint64]string `protobuf:"bytes,2,rep,name=addresses" json:"addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Removed        map[uint64]bool   `protobuf:"bytes,3,rep,name=removed" json:"removed,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	Observers      map[uint64]string `protobuf:"bytes,4,rep,name=observers" json:"observers,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Witnesses      map[uint64]string `protobuf:"bytes,5,rep,name=witnesses" json:"witnesses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Incoming       map[uint64]bool   `protobuf:"bytes,6,rep,name=incoming" json:"incoming,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Outgoing       map[uint64]bool   `protobuf:"bytes,7,rep,name=outgoing" json:"outgoing,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 }
 
 func (m *Membership) Reset()         { *m = Membership{} }
@@ -623,6 +703,27 @@ func (m *Membership) GetObservers() map[uint64]string {
 	return nil
 }
 
+func (m *Membership) GetWitnesses() map[uint64]string {
+	if m != nil {
+		return m.Witnesses
+	}
+	return nil
+}
+
+func (m *Membership) GetIncoming() map[uint64]bool {
+	if m != nil {
+		return m.Incoming
+	}
+	return nil
+}
+
+func (m *Membership) GetOutgoing() map[uint64]bool {
+	if m != nil {
+		return m.Outgoing
+	}
+	return nil
+}
+
 // field id 1 was used for optional string filename
 type SnapshotFile struct {
 	Filepath string `protobuf:"bytes,2,opt,name=filepath" json:"filepath"`
@@ -700,6 +801,8 @@ type Snapshot struct {
 	Term       uint64          `protobuf:"varint,5,opt,name=term" json:"term"`
 	Membership Membership      `protobuf:"bytes,6,opt,name=membership" json:"membership"`
 	Files      []*SnapshotFile `protobuf:"bytes,7,rep,name=files" json:"files,omitempty"`
+	Dummy      bool            `protobuf:"varint,8,opt,name=dummy" json:"dummy"`
+	Witness    bool            `protobuf:"varint,9,opt,name=witness" json:"witness"`
 }
 
 func (m *Snapshot) Reset()         { *m = Snapshot{} }
@@ -777,6 +880,20 @@ func (m *Snapshot) GetFiles() []*SnapshotFile {
 	return nil
 }
 
+func (m *Snapshot) GetDummy() bool {
+	if m != nil {
+		return m.Dummy
+	}
+	return false
+}
+
+func (m *Snapshot) GetWitness() bool {
+	if m != nil {
+		return m.Witness
+	}
+	return false
+}
+
 type Message struct {
 	Type      MessageType `protobuf:"varint,1,opt,name=type,enum=raftpb.MessageType" json:"type"`
 	To        uint64      `protobuf:"varint,2,opt,name=to" json:"to"`
@@ -923,6 +1040,7 @@ type ConfigChange struct {
 	NodeID         uint64           `protobuf:"varint,3,opt,name=NodeID" json:"NodeID"`
 	Address        string           `protobuf:"bytes,4,opt,name=Address" json:"Address"`
 	Initialize     bool             `protobuf:"varint,5,opt,name=Initialize" json:"Initialize"`
+	Changes        []ConfigChange   `protobuf:"bytes,6,rep,name=Changes" json:"Changes"`
 }
 
 func (m *ConfigChange) Reset()         { *m = ConfigChange{} }
@@ -993,15 +1111,23 @@ func (m *ConfigChange) GetInitialize() bool {
 	return false
 }
 
+func (m *ConfigChange) GetChanges() []ConfigChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
 type SnapshotHeader struct {
-	SessionSize     uint64       `protobuf:"varint,1,opt,name=session_size,json=sessionSize" json:"session_size"`
-	DataStoreSize   uint64       `protobuf:"varint,2,opt,name=data_store_size,json=dataStoreSize" json:"data_store_size"`
-	UnreliableTime  uint64       `protobuf:"varint,3,opt,name=unreliable_time,json=unreliableTime" json:"unreliable_time"`
-	GitVersion      string       `protobuf:"bytes,4,opt,name=git_version,json=gitVersion" json:"git_version"`
-	HeaderChecksum  []byte       `protobuf:"bytes,5,opt,name=header_checksum,json=headerChecksum" json:"header_checksum"`
-	PayloadChecksum []byte       `protobuf:"bytes,6,opt,name=payload_checksum,json=payloadChecksum" json:"payload_checksum"`
-	ChecksumType    ChecksumType `protobuf:"varint,7,opt,name=checksum_type,json=checksumType,enum=raftpb.ChecksumType" json:"checksum_type"`
-	Version         uint64       `protobuf:"varint,8,opt,name=version" json:"version"`
+	SessionSize     uint64          `protobuf:"varint,1,opt,name=session_size,json=sessionSize" json:"session_size"`
+	DataStoreSize   uint64          `protobuf:"varint,2,opt,name=data_store_size,json=dataStoreSize" json:"data_store_size"`
+	UnreliableTime  uint64          `protobuf:"varint,3,opt,name=unreliable_time,json=unreliableTime" json:"unreliable_time"`
+	GitVersion      string          `protobuf:"bytes,4,opt,name=git_version,json=gitVersion" json:"git_version"`
+	HeaderChecksum  []byte          `protobuf:"bytes,5,opt,name=header_checksum,json=headerChecksum" json:"header_checksum"`
+	PayloadChecksum []byte          `protobuf:"bytes,6,opt,name=payload_checksum,json=payloadChecksum" json:"payload_checksum"`
+	ChecksumType    ChecksumType    `protobuf:"varint,7,opt,name=checksum_type,json=checksumType,enum=raftpb.ChecksumType" json:"checksum_type"`
+	Version         uint64          `protobuf:"varint,8,opt,name=version" json:"version"`
+	CompressionType CompressionType `protobuf:"varint,9,opt,name=compression_type,json=compressionType,enum=raftpb.CompressionType" json:"compression_type"`
 }
 
 func (m *SnapshotHeader) Reset()         { *m = SnapshotHeader{} }
@@ -1093,6 +1219,13 @@ func (m *SnapshotHeader) GetVersion() uint64 {
 	return 0
 }
 
+func (m *SnapshotHeader) GetCompressionType() CompressionType {
+	if m != nil {
+		return m.CompressionType
+	}
+	return NoCompression
+}
+
 // dummy message used by grpc
 type Response struct {
 }
@@ -1218,6 +1351,7 @@ type SnapshotChunk struct {
 	HasFileInfo    bool         `protobuf:"varint,17,opt,name=has_file_info,json=hasFileInfo" json:"has_file_info"`
 	FileInfo       SnapshotFile `protobuf:"bytes,18,opt,name=file_info,json=fileInfo" json:"file_info"`
 	BinVer         uint32       `protobuf:"varint,19,opt,name=bin_ver,json=binVer" json:"bin_ver"`
+	Checksum       []byte       `protobuf:"bytes,20,opt,name=checksum" json:"checksum"`
 }
 
 func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
@@ -1379,6 +1513,65 @@ func (m *SnapshotChunk) GetBinVer() uint32 {
 	return 0
 }
 
+func (m *SnapshotChunk) GetChecksum() []byte {
+	if m != nil {
+		return m.Checksum
+	}
+	return nil
+}
+
+type LogDBMetadata struct {
+	BinVer uint32 `protobuf:"varint,1,opt,name=bin_ver,json=binVer" json:"bin_ver"`
+	Shards uint64 `protobuf:"varint,2,opt,name=shards" json:"shards"`
+}
+
+func (m *LogDBMetadata) Reset()         { *m = LogDBMetadata{} }
+func (m *LogDBMetadata) String() string { return proto.CompactTextString(m) }
+func (*LogDBMetadata) ProtoMessage()    {}
+func (*LogDBMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_raft_00707ff926eff8f6, []int{14}
+}
+func (m *LogDBMetadata) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LogDBMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LogDBMetadata.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *LogDBMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogDBMetadata.Merge(dst, src)
+}
+func (m *LogDBMetadata) XXX_Size() int {
+	return m.Size()
+}
+func (m *LogDBMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogDBMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogDBMetadata proto.InternalMessageInfo
+
+func (m *LogDBMetadata) GetBinVer() uint32 {
+	if m != nil {
+		return m.BinVer
+	}
+	return 0
+}
+
+func (m *LogDBMetadata) GetShards() uint64 {
+	if m != nil {
+		return m.Shards
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Bootstrap)(nil), "raftpb.Bootstrap")
 	proto.RegisterMapType((map[uint64]string)(nil), "raftpb.Bootstrap.AddressesEntry")
@@ -1389,6 +1582,7 @@ func init() {
 	proto.RegisterType((*Membership)(nil), "raftpb.Membership")
 	proto.RegisterMapType((map[uint64]string)(nil), "raftpb.Membership.AddressesEntry")
 	proto.RegisterMapType((map[uint64]string)(nil), "raftpb.Membership.ObserversEntry")
+	proto.RegisterMapType((map[uint64]string)(nil), "raftpb.Membership.WitnessesEntry")
 	proto.RegisterMapType((map[uint64]bool)(nil), "raftpb.Membership.RemovedEntry")
 	proto.RegisterType((*SnapshotFile)(nil), "raftpb.SnapshotFile")
 	proto.RegisterType((*Snapshot)(nil), "raftpb.Snapshot")
@@ -1398,10 +1592,12 @@ func init() {
 	proto.RegisterType((*Response)(nil), "raftpb.Response")
 	proto.RegisterType((*MessageBatch)(nil), "raftpb.MessageBatch")
 	proto.RegisterType((*SnapshotChunk)(nil), "raftpb.SnapshotChunk")
+	proto.RegisterType((*LogDBMetadata)(nil), "raftpb.LogDBMetadata")
 	proto.RegisterEnum("raftpb.MessageType", MessageType_name, MessageType_value)
 	proto.RegisterEnum("raftpb.EntryType", EntryType_name, EntryType_value)
 	proto.RegisterEnum("raftpb.ConfigChangeType", ConfigChangeType_name, ConfigChangeType_value)
 	proto.RegisterEnum("raftpb.ChecksumType", ChecksumType_name, ChecksumType_value)
+	proto.RegisterEnum("raftpb.CompressionType", CompressionType_name, CompressionType_value)
 }
 func (m *Bootstrap) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -1470,6 +1666,10 @@ func (m *RaftDataStatus) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x18
 	i++
 	i = encodeVarintRaft(dAtA, i, uint64(m.HardHash))
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintRaft(dAtA, i, uint64(len(m.KvStoreType)))
+	i += copy(dAtA[i:], m.KvStoreType)
 	return i, nil
 }
 
@@ -1646,6 +1846,62 @@ func (m *Membership) MarshalTo(dAtA []byte) (int, error) {
 			i += copy(dAtA[i:], v)
 		}
 	}
+	if len(m.Witnesses) > 0 {
+		for k, _ := range m.Witnesses {
+			dAtA[i] = 0x2a
+			i++
+			v := m.Witnesses[k]
+			mapSize := 1 + sovRaft(uint64(k)) + 1 + len(v) + sovRaft(uint64(len(v)))
+			i = encodeVarintRaft(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0x8
+			i++
+			i = encodeVarintRaft(dAtA, i, uint64(k))
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintRaft(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	if len(m.Incoming) > 0 {
+		for k, _ := range m.Incoming {
+			dAtA[i] = 0x32
+			i++
+			v := m.Incoming[k]
+			mapSize := 1 + sovRaft(uint64(k)) + 1 + 1
+			i = encodeVarintRaft(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0x8
+			i++
+			i = encodeVarintRaft(dAtA, i, uint64(k))
+			dAtA[i] = 0x10
+			i++
+			if v {
+				dAtA[i] = 1
+			} else {
+				dAtA[i] = 0
+			}
+			i++
+		}
+	}
+	if len(m.Outgoing) > 0 {
+		for k, _ := range m.Outgoing {
+			dAtA[i] = 0x3a
+			i++
+			v := m.Outgoing[k]
+			mapSize := 1 + sovRaft(uint64(k)) + 1 + 1
+			i = encodeVarintRaft(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0x8
+			i++
+			i = encodeVarintRaft(dAtA, i, uint64(k))
+			dAtA[i] = 0x10
+			i++
+			if v {
+				dAtA[i] = 1
+			} else {
+				dAtA[i] = 0
+			}
+			i++
+		}
+	}
 	return i, nil
 }
 
@@ -1731,6 +1987,22 @@ func (m *Snapshot) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	dAtA[i] = 0x40
+	i++
+	if m.Dummy {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i++
+	dAtA[i] = 0x48
+	i++
+	if m.Witness {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i++
 	return i, nil
 }
 
@@ -1846,6 +2118,18 @@ func (m *ConfigChange) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0
 	}
 	i++
+	if len(m.Changes) > 0 {
+		for _, msg := range m.Changes {
+			dAtA[i] = 0x32
+			i++
+			i = encodeVarintRaft(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
@@ -1895,6 +2179,9 @@ func (m *SnapshotHeader) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x40
 	i++
 	i = encodeVarintRaft(dAtA, i, uint64(m.Version))
+	dAtA[i] = 0x48
+	i++
+	i = encodeVarintRaft(dAtA, i, uint64(m.CompressionType))
 	return i, nil
 }
 
@@ -2052,6 +2339,38 @@ func (m *SnapshotChunk) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x1
 	i++
 	i = encodeVarintRaft(dAtA, i, uint64(m.BinVer))
+	if m.Checksum != nil {
+		dAtA[i] = 0xa2
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintRaft(dAtA, i, uint64(len(m.Checksum)))
+		i += copy(dAtA[i:], m.Checksum)
+	}
+	return i, nil
+}
+
+func (m *LogDBMetadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LogDBMetadata) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	dAtA[i] = 0x8
+	i++
+	i = encodeVarintRaft(dAtA, i, uint64(m.BinVer))
+	dAtA[i] = 0x10
+	i++
+	i = encodeVarintRaft(dAtA, i, uint64(m.Shards))
 	return i, nil
 }
 
@@ -2092,6 +2411,8 @@ func (m *RaftDataStatus) Size() (n int) {
 	n += 1 + l + sovRaft(uint64(l))
 	n += 1 + sovRaft(uint64(m.BinVer))
 	n += 1 + sovRaft(uint64(m.HardHash))
+	l = len(m.KvStoreType)
+	n += 1 + l + sovRaft(uint64(l))
 	return n
 }
 
@@ -2174,6 +2495,30 @@ func (m *Membership) Size() (n int) {
 			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
 		}
 	}
+	if len(m.Witnesses) > 0 {
+		for k, v := range m.Witnesses {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + sovRaft(uint64(k)) + 1 + len(v) + sovRaft(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Incoming) > 0 {
+		for k, v := range m.Incoming {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + sovRaft(uint64(k)) + 1 + 1
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Outgoing) > 0 {
+		for k, v := range m.Outgoing {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + sovRaft(uint64(k)) + 1 + 1
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
 	return n
 }
 
@@ -2213,6 +2558,8 @@ func (m *Snapshot) Size() (n int) {
 			n += 1 + l + sovRaft(uint64(l))
 		}
 	}
+	n += 2
+	n += 2
 	return n
 }
 
@@ -2256,6 +2603,12 @@ func (m *ConfigChange) Size() (n int) {
 	l = len(m.Address)
 	n += 1 + l + sovRaft(uint64(l))
 	n += 2
+	if len(m.Changes) > 0 {
+		for _, e := range m.Changes {
+			l = e.Size()
+			n += 1 + l + sovRaft(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2280,6 +2633,7 @@ func (m *SnapshotHeader) Size() (n int) {
 	}
 	n += 1 + sovRaft(uint64(m.ChecksumType))
 	n += 1 + sovRaft(uint64(m.Version))
+	n += 1 + sovRaft(uint64(m.CompressionType))
 	return n
 }
 
@@ -2341,6 +2695,21 @@ func (m *SnapshotChunk) Size() (n int) {
 	l = m.FileInfo.Size()
 	n += 2 + l + sovRaft(uint64(l))
 	n += 2 + sovRaft(uint64(m.BinVer))
+	if m.Checksum != nil {
+		l = len(m.Checksum)
+		n += 2 + l + sovRaft(uint64(l))
+	}
+	return n
+}
+
+func (m *LogDBMetadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 1 + sovRaft(uint64(m.BinVer))
+	n += 1 + sovRaft(uint64(m.Shards))
 	return n
 }
 
@@ -2630,6 +2999,35 @@ func (m *RaftDataStatus) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KvStoreType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KvStoreType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -3416,11 +3814,314 @@ func (m *Membership) Unmarshal(dAtA []byte) error {
 			}
 			m.Observers[mapkey] = mapvalue
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRaft(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Witnesses", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Witnesses == nil {
+				m.Witnesses = make(map[uint64]string)
+			}
+			var mapkey uint64
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Witnesses[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Incoming", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Incoming == nil {
+				m.Incoming = make(map[uint64]bool)
+			}
+			var mapkey uint64
+			var mapvalue bool
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var mapvaluetemp int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvaluetemp |= (int(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					mapvalue = bool(mapvaluetemp != 0)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Incoming[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Outgoing", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Outgoing == nil {
+				m.Outgoing = make(map[uint64]bool)
+			}
+			var mapkey uint64
+			var mapvalue bool
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else if fieldNum == 2 {
+					var mapvaluetemp int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvaluetemp |= (int(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					mapvalue = bool(mapvaluetemp != 0)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Outgoing[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
 			if skippy < 0 {
 				return ErrInvalidLengthRaft
@@ -3761,6 +4462,46 @@ func (m *Snapshot) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dummy", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Dummy = bool(v != 0)
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Witness", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Witness = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -4241,6 +4982,37 @@ func (m *ConfigChange) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Initialize = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Changes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Changes = append(m.Changes, ConfigChange{})
+			if err := m.Changes[len(m.Changes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -4477,6 +5249,25 @@ func (m *SnapshotHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompressionType", wireType)
+			}
+			m.CompressionType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CompressionType |= (CompressionType(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -5115,6 +5906,125 @@ func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 20:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Checksum = append(m.Checksum[:0], dAtA[iNdEx:postIndex]...)
+			if m.Checksum == nil {
+				m.Checksum = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LogDBMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogDBMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogDBMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BinVer", wireType)
+			}
+			m.BinVer = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BinVer |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Shards", wireType)
+			}
+			m.Shards = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Shards |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -5244,111 +6154,112 @@ var (
 func init() { proto.RegisterFile("raft.proto", fileDescriptor_raft_00707ff926eff8f6) }
 
 var fileDescriptor_raft_00707ff926eff8f6 = []byte{
-	// 1684 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x4f, 0x93, 0x1b, 0x47,
-	0x15, 0xd7, 0xe8, 0xbf, 0x9e, 0xfe, 0xec, 0x6c, 0xdb, 0x09, 0x53, 0x5b, 0xc9, 0x7a, 0x2d, 0x08,
-	0x2c, 0x1b, 0xbc, 0x2e, 0x96, 0x03, 0x06, 0xaa, 0x08, 0x6b, 0x79, 0xc3, 0xaa, 0x92, 0x38, 0x8e,
-	0xbc, 0x98, 0xca, 0x49, 0xd5, 0x9a, 0x79, 0xd2, 0x74, 0x2c, 0x4d, 0x8b, 0xe9, 0xd6, 0xc2, 0xe6,
-	0x03, 0x70, 0xe6, 0xc0, 0xf7, 0x80, 0x0b, 0x55, 0x7c, 0x03, 0x72, 0xf4, 0x05, 0x8a, 0x13, 0x05,
-	0xf6, 0x91, 0xef, 0x40, 0x51, 0xaf, 0x7b, 0x46, 0xea, 0x59, 0xd9, 0x31, 0xa1, 0x72, 0xd3, 0xfc,
-	0xde, 0xdf, 0x7e, 0xfd, 0xde, 0xef, 0xb5, 0x00, 0x52, 0x3e, 0xd5, 0xc7, 0xcb, 0x54, 0x6a, 0xc9,
-	0xea, 0xf4, 0x7b, 0x39, 0xd9, 0xbb, 0x33, 0x13, 0x3a, 0x5e, 0x4d, 0x8e, 0x43, 0xb9, 0xb8, 0x3b,
-	0x93, 0x33, 0x79, 0xd7, 0x88, 0x27, 0xab, 0xa9, 0xf9, 0x32, 0x1f, 0xe6, 0x97, 0x35, 0xeb, 0xff,
-	0xc1, 0x83, 0xd6, 0x7d, 0x29, 0xb5, 0xd2, 0x29, 0x5f, 0xb2, 0x9f, 0x42, 0x8b, 0x47, 0x51, 0x8a,
-	0x4a, 0xa1, 0x0a, 0xbc, 0x83, 0xca, 0x61, 0xfb, 0xe4, 0xe0, 0xd8, 0x3a, 0x3e, 0x5e, 0x6b, 0x1d,
-	0x9f, 0xe6, 0x2a, 0x67, 0x89, 0x4e, 0xaf, 0x46, 0x1b, 0x13, 0x16, 0x40, 0xf5, 0x33, 0x29, 0x92,
-	0xa0, 0x7c, 0xe0, 0x1d, 0x36, 0xef, 0x57, 0xbf, 0xf8, 0xc7, 0xad, 0xd2, 0xc8, 0x20, 0x7b, 0xe7,
-	0xd0, 0x2b, 0x9a, 0xb1, 0x37, 0xa1, 0xf2, 0x14, 0xaf, 0x02, 0xef, 0xc0, 0x3b, 0xac, 0x66, 0xaa,
-	0x04, 0xb0, 0x3d, 0xa8, 0x5d, 0xf2, 0xf9, 0x0a, 0x8d, 0x93, 0x56, 0x26, 0xb1, 0xd0, 0x8f, 0xcb,
-	0xf7, 0xbc, 0x7e, 0x0a, 0xbd, 0x11, 0x9f, 0xea, 0x07, 0x5c, 0xf3, 0xc7, 0x9a, 0xeb, 0x95, 0x62,
-	0xfb, 0xd0, 0xc8, 0x52, 0x30, 0xde, 0x72, 0x9b, 0x1c, 0x64, 0x6f, 0x43, 0x63, 0x22, 0x92, 0xf1,
-	0x25, 0xa6, 0xc6, 0x67, 0x37, 0x93, 0xd7, 0x27, 0x22, 0x79, 0x82, 0x29, 0xbb, 0x0d, 0xad, 0x98,
-	0xa7, 0xd1, 0x38, 0xe6, 0x2a, 0x0e, 0x2a, 0x4e, 0x3a, 0x4d, 0x82, 0xcf, 0xb9, 0x8a, 0xfb, 0x9f,
-	0x42, 0x8d, 0x62, 0x21, 0x1d, 0x50, 0x63, 0xba, 0x28, 0x64, 0x6d, 0x10, 0x92, 0x5c, 0x4a, 0x6d,
-	0xb3, 0x5e, 0x4b, 0x08, 0x61, 0x6f, 0x41, 0x3d, 0x94, 0x8b, 0x85, 0xd0, 0x05, 0xe7, 0x19, 0xd6,
-	0xff, 0x6d, 0x19, 0x6a, 0xb6, 0x20, 0x01, 0x54, 0x2f, 0xb6, 0x7c, 0x13, 0x42, 0x25, 0x19, 0x26,
-	0x11, 0xfe, 0xa6, 0xe0, 0xdc, 0x42, 0xec, 0x5d, 0xa8, 0x5e, 0x5c, 0x2d, 0xd1, 0xf8, 0xee, 0x9d,
-	0xec, 0xe6, 0xb7, 0x65, 0x5c, 0x92, 0x60, 0xed, 0xe8, 0x6a, 0x89, 0x54, 0xf3, 0x0f, 0xf0, 0x2a,
-	0xa8, 0xba, 0x35, 0xff, 0x00, 0xaf, 0xd8, 0x01, 0x34, 0x07, 0x73, 0x81, 0x89, 0x1e, 0x3e, 0x08,
-	0x6a, 0x6e, 0x05, 0x72, 0x94, 0x34, 0x1e, 0x63, 0x2a, 0x50, 0x0d, 0x1f, 0x04, 0x75, 0x57, 0x23,
-	0x47, 0xd9, 0xb7, 0xa1, 0x3d, 0x42, 0xb5, 0x94, 0x49, 0x84, 0xd1, 0x85, 0x0c, 0x1a, 0x8e, 0x92,
-	0x2b, 0xa0, 0x1c, 0x06, 0x8b, 0x28, 0x68, 0x1e, 0x78, 0x87, 0x9d, 0x3c, 0x87, 0xc1, 0x22, 0xea,
-	0xff, 0x04, 0xc0, 0x24, 0x7d, 0x9f, 0xeb, 0x30, 0x66, 0x77, 0xa0, 0x81, 0x89, 0x26, 0xd7, 0x59,
-	0x1f, 0x76, 0x0b, 0x27, 0xcb, 0xaf, 0x38, 0xd3, 0xe9, 0xff, 0xb5, 0x02, 0xf0, 0x11, 0x2e, 0x26,
-	0x98, 0xaa, 0x58, 0x2c, 0xd9, 0x31, 0xf8, 0xa1, 0x4c, 0xa6, 0x62, 0x36, 0x0e, 0x63, 0x9e, 0xcc,
-	0x70, 0x2c, 0xa2, 0x42, 0x59, 0x7b, 0x56, 0x3a, 0x30, 0xc2, 0x61, 0xc4, 0xde, 0x73, 0xfb, 0xbe,
-	0x6c, 0xe2, 0xdd, 0xce, 0xe3, 0x6d, 0xdc, 0x7e, 0x49, 0xe3, 0xff, 0x08, 0x1a, 0x29, 0x2e, 0xe4,
-	0x25, 0x46, 0x41, 0xc5, 0x98, 0xdf, 0x7a, 0x89, 0xf9, 0xc8, 0x6a, 0x58, 0xe3, 0x5c, 0x9f, 0x62,
-	0xcb, 0x89, 0xc2, 0xf4, 0x12, 0x53, 0x15, 0x54, 0x5f, 0x19, 0xfb, 0xe3, 0x5c, 0x27, 0x8b, 0xbd,
-	0xb6, 0xf9, 0xfa, 0x46, 0x6b, 0xef, 0x7d, 0xe8, 0xb8, 0x39, 0xfe, 0x6f, 0x7e, 0x9a, 0xdb, 0x7e,
-	0xce, 0xa1, 0x57, 0x4c, 0xf7, 0xff, 0x1e, 0xf6, 0xdf, 0x7b, 0xd0, 0x79, 0x9c, 0xf0, 0xa5, 0x8a,
-	0xa5, 0x7e, 0x5f, 0xcc, 0x91, 0xfa, 0x70, 0x2a, 0xe6, 0xb8, 0xe4, 0x3a, 0x2e, 0xd8, 0xac, 0x51,
-	0x1a, 0x67, 0xfa, 0x3d, 0x56, 0xe2, 0x73, 0x2c, 0x8e, 0x33, 0xc1, 0x8f, 0xc5, 0xe7, 0x48, 0x84,
-	0x60, 0x54, 0x44, 0x54, 0x18, 0x85, 0x3a, 0x81, 0xc3, 0x88, 0x62, 0x2c, 0x50, 0xf3, 0x88, 0x6b,
-	0x6e, 0xa6, 0x21, 0x6f, 0xd3, 0x35, 0xda, 0xff, 0xb7, 0x07, 0xcd, 0x3c, 0xad, 0xaf, 0x27, 0xa5,
-	0x3d, 0xa8, 0x09, 0x33, 0xe2, 0x6e, 0x42, 0x16, 0x5a, 0x93, 0x4e, 0x6d, 0x8b, 0x74, 0xee, 0x01,
-	0x2c, 0xd6, 0x2d, 0x62, 0xe6, 0xb2, 0x7d, 0xc2, 0xb6, 0x9b, 0x27, 0xb3, 0x71, 0x74, 0xd9, 0x11,
-	0xd4, 0x28, 0xb6, 0x0a, 0x1a, 0xa6, 0xe3, 0x6e, 0xe6, 0x46, 0x6e, 0xb1, 0x47, 0x56, 0xa5, 0xff,
-	0x97, 0x0a, 0x34, 0x3e, 0x42, 0xa5, 0xf8, 0x0c, 0xd9, 0x1d, 0xa8, 0x6a, 0xa2, 0x1b, 0xcf, 0xd0,
-	0xcd, 0x8d, 0x4d, 0x2c, 0x23, 0x76, 0x09, 0x87, 0xd4, 0xd8, 0x4d, 0x28, 0x6b, 0x59, 0xa0, 0xad,
-	0xb2, 0x96, 0x74, 0xa0, 0x69, 0x2a, 0x17, 0x85, 0x52, 0x18, 0x84, 0x7d, 0x13, 0x20, 0x9c, 0xaf,
-	0x94, 0xc6, 0xf4, 0xfa, 0xe5, 0xb4, 0x32, 0x7c, 0x18, 0x7d, 0x49, 0x3d, 0x6e, 0x41, 0x73, 0x2e,
-	0x67, 0x63, 0x23, 0x75, 0x59, 0xaa, 0x31, 0x97, 0x33, 0xc3, 0xa4, 0xb7, 0xa1, 0x45, 0x0a, 0xb6,
-	0xd4, 0x2e, 0x45, 0x91, 0x9d, 0x25, 0xd4, 0x0d, 0x5d, 0x37, 0xb7, 0xe9, 0x9a, 0xa4, 0x29, 0x7e,
-	0x86, 0xa1, 0x0e, 0x5a, 0x4e, 0xef, 0x67, 0x18, 0x65, 0x16, 0x8b, 0x44, 0x07, 0xe0, 0x66, 0x46,
-	0x88, 0xcb, 0x67, 0xed, 0xd7, 0xf3, 0x19, 0x3b, 0x81, 0xa6, 0xca, 0x6e, 0x22, 0xe8, 0x98, 0x6b,
-	0xf5, 0xaf, 0xdf, 0x50, 0x9e, 0x78, 0xae, 0x67, 0xf6, 0x98, 0x48, 0xf4, 0x38, 0x16, 0xb3, 0x38,
-	0xe8, 0x16, 0xf6, 0x98, 0x48, 0xf4, 0xb9, 0x98, 0xc5, 0xfd, 0xbf, 0x79, 0xd0, 0x19, 0x38, 0xd4,
-	0xf7, 0x95, 0x89, 0xf2, 0x24, 0xdb, 0x36, 0x65, 0x73, 0xfd, 0x41, 0x9e, 0x93, 0xeb, 0x73, 0x6b,
-	0xe9, 0xbc, 0x05, 0xf5, 0x87, 0x32, 0xc2, 0xe1, 0x83, 0xe2, 0xfe, 0xb3, 0x18, 0x2d, 0xef, 0x8c,
-	0xbd, 0xcc, 0x75, 0xaf, 0x97, 0x77, 0x06, 0xb2, 0x6f, 0x01, 0x0c, 0x13, 0xa1, 0x05, 0x9f, 0xd3,
-	0xf0, 0xd4, 0x9c, 0xa2, 0x3b, 0x78, 0xff, 0x3f, 0x65, 0xe8, 0xe5, 0x85, 0x39, 0x47, 0x1e, 0x61,
-	0xca, 0xbe, 0x03, 0x1d, 0x85, 0x4a, 0x09, 0x99, 0xd8, 0xb9, 0x73, 0x8f, 0xd5, 0xce, 0x24, 0x66,
-	0xf4, 0xbe, 0x07, 0x3b, 0x34, 0xd4, 0x63, 0xa5, 0x65, 0x9a, 0xcd, 0xa8, 0xdb, 0xb0, 0xdd, 0xc8,
-	0xbc, 0x34, 0x64, 0x6a, 0x07, 0xf5, 0x0e, 0xec, 0xac, 0x92, 0x14, 0xe7, 0x82, 0x4f, 0xe6, 0x38,
-	0xd6, 0x62, 0x51, 0x9c, 0xe8, 0xde, 0x46, 0x78, 0x21, 0x16, 0xc8, 0xde, 0x81, 0xf6, 0x4c, 0x68,
-	0x7a, 0x7b, 0x50, 0xbc, 0xc2, 0x11, 0x61, 0x26, 0xf4, 0x13, 0x8b, 0x93, 0xd7, 0xd8, 0xa4, 0x3d,
-	0x0e, 0x63, 0x0c, 0x9f, 0xaa, 0xd5, 0xa2, 0xc0, 0x3c, 0x3d, 0x2b, 0x1c, 0x64, 0x32, 0x76, 0x17,
-	0xfc, 0x25, 0xbf, 0x9a, 0x4b, 0x1e, 0x6d, 0xf4, 0xeb, 0x8e, 0xfe, 0x4e, 0x26, 0x5d, 0x1b, 0xbc,
-	0x07, 0xdd, 0x5c, 0x71, 0x6c, 0xe6, 0xb7, 0x61, 0x2e, 0x70, 0x3d, 0xf6, 0xb9, 0xa2, 0x73, 0x79,
-	0x9d, 0xd0, 0xc1, 0xe8, 0x9a, 0xf2, 0x33, 0xb8, 0x63, 0x91, 0x83, 0x7d, 0x80, 0xa6, 0x5d, 0xf2,
-	0x0a, 0xfb, 0x7f, 0xf6, 0xa0, 0x93, 0x11, 0x82, 0x5d, 0xe6, 0xdf, 0x87, 0x66, 0x8a, 0xbf, 0x5a,
-	0xa1, 0xd2, 0xf9, 0x36, 0xdf, 0xb9, 0x46, 0x1c, 0x79, 0xa7, 0xe6, 0x6a, 0xec, 0xbb, 0xd0, 0x8d,
-	0x70, 0x39, 0x97, 0x57, 0x0b, 0x4c, 0x34, 0x75, 0xa5, 0x7b, 0x25, 0x9d, 0x8d, 0x68, 0x18, 0xb1,
-	0x77, 0xa1, 0xa7, 0xe4, 0x2a, 0x0d, 0x71, 0x9c, 0xbf, 0x02, 0x2b, 0x4e, 0x95, 0xbb, 0x56, 0x76,
-	0xba, 0xfd, 0x16, 0xac, 0x6e, 0xbf, 0x05, 0xfb, 0x7f, 0xac, 0x41, 0x37, 0xef, 0xa3, 0x41, 0xbc,
-	0x4a, 0x9e, 0x5e, 0x63, 0x24, 0xef, 0xe5, 0x8c, 0xf4, 0x36, 0x34, 0x12, 0x19, 0xe1, 0xf5, 0x3c,
-	0xeb, 0x04, 0x5a, 0xc2, 0x7a, 0x05, 0xdf, 0xdd, 0x82, 0x66, 0x48, 0x61, 0xae, 0xb3, 0x5d, 0xc3,
-	0xa0, 0xc3, 0xc8, 0x84, 0x37, 0x0a, 0x2a, 0x6f, 0xff, 0x4d, 0x78, 0xc2, 0x4d, 0x4f, 0xbe, 0x03,
-	0x6d, 0xab, 0x14, 0xca, 0x55, 0xa2, 0x0b, 0xcc, 0x67, 0xad, 0x07, 0x84, 0x53, 0x1a, 0x66, 0xa7,
-	0x35, 0x9c, 0x4e, 0x31, 0xc8, 0x66, 0xfb, 0x34, 0x5f, 0xbd, 0x7d, 0x5a, 0xaf, 0xd9, 0x3e, 0xf0,
-	0x15, 0xb6, 0x8f, 0xbb, 0x32, 0x3b, 0xaf, 0x5f, 0x99, 0xdd, 0x97, 0xae, 0xcc, 0xad, 0x16, 0xe9,
-	0xbd, 0xb2, 0x45, 0x0e, 0xa1, 0x6b, 0xbc, 0xad, 0x6b, 0xbd, 0xe3, 0x92, 0x01, 0x89, 0x06, 0x59,
-	0xbd, 0x8f, 0xc1, 0x77, 0x34, 0x6d, 0x3d, 0x7d, 0x77, 0xbe, 0xd7, 0xca, 0xb6, 0xa6, 0x87, 0xd0,
-	0x8d, 0xb9, 0x1a, 0xdb, 0xe7, 0x44, 0x32, 0x95, 0xc1, 0xae, 0xc3, 0x50, 0xed, 0x98, 0x2b, 0x5a,
-	0xa4, 0xc3, 0x64, 0x2a, 0xd9, 0x0f, 0xb3, 0x13, 0x19, 0x2d, 0x66, 0x8a, 0xf5, 0xd2, 0xad, 0xeb,
-	0x9e, 0xd3, 0x18, 0x3a, 0x2d, 0x7b, 0x63, 0xbb, 0x65, 0x8f, 0xfe, 0x54, 0x81, 0xb6, 0xb3, 0x7e,
-	0x59, 0x17, 0x5a, 0x1f, 0xca, 0x90, 0xcf, 0x2f, 0x44, 0xf8, 0xd4, 0x2f, 0xb1, 0x0e, 0x34, 0xcf,
-	0xe6, 0x18, 0x6a, 0x21, 0x13, 0xdf, 0x63, 0x37, 0x60, 0xe7, 0x43, 0x43, 0x25, 0xe7, 0xc8, 0x53,
-	0x3d, 0x41, 0xae, 0xfd, 0x32, 0x7b, 0x03, 0x76, 0x5d, 0x02, 0x3f, 0xbb, 0xc4, 0x44, 0xfb, 0x15,
-	0xd6, 0x84, 0xea, 0x43, 0xf9, 0xf1, 0x23, 0xbf, 0x4a, 0xbf, 0x1e, 0x89, 0x64, 0xe6, 0xd7, 0xcc,
-	0x2f, 0x99, 0xcc, 0xfc, 0x3a, 0x6b, 0x43, 0xe3, 0x51, 0x2a, 0x97, 0x52, 0xa1, 0xdf, 0x60, 0x6c,
-	0xc3, 0xbe, 0xf6, 0x3f, 0x99, 0xdf, 0x64, 0x3b, 0xd0, 0xfe, 0x45, 0x92, 0x22, 0x0f, 0x63, 0x22,
-	0x43, 0xbf, 0x45, 0x80, 0xa1, 0x99, 0x4f, 0x56, 0x32, 0x5d, 0x2d, 0x7c, 0x60, 0x37, 0xc1, 0x37,
-	0xfc, 0x80, 0xd1, 0x08, 0x79, 0x64, 0xb6, 0xaf, 0xdf, 0xa6, 0xfc, 0x47, 0xb8, 0x9c, 0x8b, 0x90,
-	0x6b, 0xf4, 0x3b, 0x6c, 0x17, 0xba, 0xeb, 0x4f, 0x62, 0x18, 0xbf, 0x4b, 0x8e, 0x46, 0x96, 0x27,
-	0x9e, 0x48, 0x8d, 0x7e, 0x8f, 0x4e, 0xe5, 0x00, 0x46, 0x6b, 0x87, 0xc0, 0x61, 0xa2, 0x34, 0x9f,
-	0xcf, 0xf3, 0xd4, 0x7c, 0x9f, 0x9c, 0x6f, 0x4e, 0xbe, 0x4b, 0xce, 0xd7, 0x9f, 0xc6, 0x8c, 0xd9,
-	0xf0, 0x79, 0x36, 0x37, 0x6c, 0xf8, 0xec, 0xd3, 0x68, 0xdc, 0xa4, 0x93, 0x7f, 0xb2, 0x12, 0xa8,
-	0x42, 0xf4, 0xdf, 0xa0, 0x33, 0xe4, 0xee, 0x47, 0x18, 0xa2, 0xb8, 0xc4, 0xc8, 0x7f, 0x93, 0xea,
-	0x61, 0xcb, 0x7c, 0x91, 0xf2, 0x44, 0x4d, 0x31, 0xf5, 0xbf, 0xc1, 0x7a, 0x00, 0xb4, 0x11, 0xe4,
-	0x4a, 0x3f, 0x94, 0xbf, 0xf6, 0x83, 0xa3, 0x7b, 0xd0, 0x5a, 0xff, 0x49, 0x23, 0x37, 0xa7, 0x4b,
-	0x7b, 0x4a, 0x21, 0x13, 0x83, 0xfb, 0xa5, 0xad, 0x8b, 0x31, 0xb0, 0x77, 0xf4, 0x33, 0xf0, 0xaf,
-	0x2f, 0x5c, 0x4a, 0xea, 0x34, 0x8a, 0x68, 0xa7, 0xfa, 0x25, 0x0a, 0x65, 0xdf, 0xf1, 0xe6, 0xdb,
-	0xa3, 0x82, 0x9d, 0x46, 0x51, 0xfe, 0x24, 0xf7, 0xcb, 0x47, 0x47, 0xd0, 0x71, 0x19, 0x9f, 0x0e,
-	0x3d, 0x18, 0x0d, 0x7e, 0x70, 0x32, 0x3c, 0x3b, 0x3b, 0xf3, 0x4b, 0xe4, 0xec, 0x7c, 0xf8, 0xf3,
-	0xf3, 0x5f, 0x9e, 0x7e, 0xea, 0x7b, 0xf7, 0xdf, 0x7a, 0xf6, 0xaf, 0xfd, 0xd2, 0x17, 0xcf, 0xf7,
-	0xbd, 0x67, 0xcf, 0xf7, 0xbd, 0x7f, 0x3e, 0xdf, 0xf7, 0x7e, 0xf7, 0x62, 0xbf, 0xf4, 0xec, 0xc5,
-	0x7e, 0xe9, 0xef, 0x2f, 0xf6, 0x4b, 0xff, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x9e, 0x5c, 0x86, 0x7a,
-	0x83, 0x10, 0x00, 0x00,
+	// 1705 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x4f, 0x73, 0x1c, 0x47,
+	0x15, 0xdf, 0xd9, 0xff, 0xfb, 0xf6, 0x8f, 0x46, 0x6d, 0x27, 0x4c, 0xa9, 0x12, 0x59, 0x5e, 0x08,
+	0x08, 0x05, 0xcb, 0x85, 0x38, 0x60, 0xa0, 0x8a, 0x20, 0xad, 0x14, 0xb4, 0x15, 0xdb, 0x71, 0xd6,
+	0xc2, 0x54, 0x4e, 0x5b, 0xb3, 0x33, 0x6f, 0x77, 0x3a, 0x9e, 0x9d, 0x5e, 0xa6, 0x7b, 0x05, 0xca,
+	0x07, 0xe0, 0xcc, 0x81, 0xef, 0x01, 0x17, 0xaa, 0xf8, 0x06, 0xe4, 0xe8, 0x0b, 0x14, 0x27, 0x0a,
+	0xec, 0x23, 0xdf, 0x81, 0xa2, 0x5e, 0xf7, 0xcc, 0x6e, 0x8f, 0x56, 0x8e, 0x09, 0x95, 0xdb, 0xcc,
+	0xef, 0xfd, 0xed, 0xd7, 0xef, 0xfd, 0xde, 0x0c, 0x40, 0xea, 0x4f, 0xd5, 0xe1, 0x22, 0x15, 0x4a,
+	0xb0, 0x3a, 0x3d, 0x2f, 0x26, 0x3b, 0xf7, 0x66, 0x5c, 0x45, 0xcb, 0xc9, 0x61, 0x20, 0xe6, 0xf7,
+	0x67, 0x62, 0x26, 0xee, 0x6b, 0xf1, 0x64, 0x39, 0xd5, 0x6f, 0xfa, 0x45, 0x3f, 0x19, 0xb3, 0xfe,
+	0x1f, 0x1c, 0x68, 0x9d, 0x08, 0xa1, 0xa4, 0x4a, 0xfd, 0x05, 0xfb, 0x29, 0xb4, 0xfc, 0x30, 0x4c,
+	0x51, 0x4a, 0x94, 0x9e, 0xb3, 0x57, 0xd9, 0x6f, 0x1f, 0xed, 0x1d, 0x1a, 0xc7, 0x87, 0x2b, 0xad,
+	0xc3, 0xe3, 0x5c, 0xe5, 0x2c, 0x51, 0xe9, 0xd5, 0x68, 0x6d, 0xc2, 0x3c, 0xa8, 0x7e, 0x26, 0x78,
+	0xe2, 0x95, 0xf7, 0x9c, 0xfd, 0xe6, 0x49, 0xf5, 0x8b, 0x7f, 0xdc, 0x29, 0x8d, 0x34, 0xb2, 0x73,
+	0x0e, 0xbd, 0xa2, 0x19, 0x7b, 0x1b, 0x2a, 0xcf, 0xf1, 0xca, 0x73, 0xf6, 0x9c, 0xfd, 0x6a, 0xa6,
+	0x4a, 0x00, 0xdb, 0x81, 0xda, 0xa5, 0x1f, 0x2f, 0x51, 0x3b, 0x69, 0x65, 0x12, 0x03, 0xfd, 0xb8,
+	0xfc, 0xc0, 0xe9, 0xa7, 0xd0, 0x1b, 0xf9, 0x53, 0x75, 0xea, 0x2b, 0xff, 0xa9, 0xf2, 0xd5, 0x52,
+	0xb2, 0x5d, 0x68, 0x64, 0x29, 0x78, 0x8e, 0x65, 0x93, 0x83, 0xec, 0x5d, 0x68, 0x4c, 0x78, 0x32,
+	0xbe, 0xc4, 0x54, 0xfb, 0xec, 0x66, 0xf2, 0xfa, 0x84, 0x27, 0xcf, 0x30, 0x65, 0x77, 0xa1, 0x15,
+	0xf9, 0x69, 0x38, 0x8e, 0x7c, 0x19, 0x79, 0x15, 0x2b, 0x9d, 0x26, 0xc1, 0xe7, 0xbe, 0x8c, 0xfa,
+	0x9f, 0x42, 0x8d, 0x62, 0x21, 0x1d, 0x50, 0x61, 0x3a, 0x2f, 0x64, 0xad, 0x11, 0x92, 0x5c, 0x0a,
+	0x65, 0xb2, 0x5e, 0x49, 0x08, 0x61, 0xef, 0x40, 0x3d, 0x10, 0xf3, 0x39, 0x57, 0x05, 0xe7, 0x19,
+	0xd6, 0xff, 0x6d, 0x19, 0x6a, 0xa6, 0x20, 0x1e, 0x54, 0x2f, 0x36, 0x7c, 0x13, 0x42, 0x25, 0x19,
+	0x26, 0x21, 0xfe, 0xa6, 0xe0, 0xdc, 0x40, 0xec, 0x7d, 0xa8, 0x5e, 0x5c, 0x2d, 0x50, 0xfb, 0xee,
+	0x1d, 0x6d, 0xe7, 0xb7, 0xa5, 0x5d, 0x92, 0x60, 0xe5, 0xe8, 0x6a, 0x81, 0x54, 0xf3, 0x8f, 0xf0,
+	0xca, 0xab, 0xda, 0x35, 0xff, 0x08, 0xaf, 0xd8, 0x1e, 0x34, 0x07, 0x31, 0xc7, 0x44, 0x0d, 0x4f,
+	0xbd, 0x9a, 0x5d, 0x81, 0x1c, 0x25, 0x8d, 0xa7, 0x98, 0x72, 0x94, 0xc3, 0x53, 0xaf, 0x6e, 0x6b,
+	0xe4, 0x28, 0xfb, 0x36, 0xb4, 0x47, 0x28, 0x17, 0x22, 0x09, 0x31, 0xbc, 0x10, 0x5e, 0xc3, 0x52,
+	0xb2, 0x05, 0x94, 0xc3, 0x60, 0x1e, 0x7a, 0xcd, 0x3d, 0x67, 0xbf, 0x93, 0xe7, 0x30, 0x98, 0x87,
+	0xfd, 0x9f, 0x00, 0xe8, 0xa4, 0x4f, 0x7c, 0x15, 0x44, 0xec, 0x1e, 0x34, 0x30, 0x51, 0x29, 0x5f,
+	0xf5, 0x61, 0xb7, 0x70, 0xb2, 0xfc, 0x8a, 0x33, 0x9d, 0xfe, 0x5f, 0x2b, 0x00, 0x8f, 0x70, 0x3e,
+	0xc1, 0x54, 0x46, 0x7c, 0xc1, 0x0e, 0xc1, 0x0d, 0x44, 0x32, 0xe5, 0xb3, 0x71, 0x10, 0xf9, 0xc9,
+	0x0c, 0xc7, 0x3c, 0x2c, 0x94, 0xb5, 0x67, 0xa4, 0x03, 0x2d, 0x1c, 0x86, 0xec, 0x03, 0xbb, 0xef,
+	0xcb, 0x3a, 0xde, 0xdd, 0x3c, 0xde, 0xda, 0xed, 0x97, 0x34, 0xfe, 0x8f, 0xa0, 0x91, 0xe2, 0x5c,
+	0x5c, 0x62, 0xe8, 0x55, 0xb4, 0xf9, 0x9d, 0x1b, 0xcc, 0x47, 0x46, 0xc3, 0x18, 0xe7, 0xfa, 0x14,
+	0x5b, 0x4c, 0x24, 0xa6, 0x97, 0x98, 0x4a, 0xaf, 0xfa, 0xda, 0xd8, 0x1f, 0xe7, 0x3a, 0x59, 0xec,
+	0x95, 0xcd, 0xd7, 0x37, 0x5a, 0x3b, 0x1f, 0x42, 0xc7, 0xce, 0xf1, 0x7f, 0xf3, 0xd3, 0xdc, 0xf4,
+	0x73, 0x0e, 0xbd, 0x62, 0xba, 0xff, 0xf7, 0xb0, 0xff, 0xde, 0x81, 0xce, 0xd3, 0xc4, 0x5f, 0xc8,
+	0x48, 0xa8, 0x0f, 0x79, 0x8c, 0xd4, 0x87, 0x53, 0x1e, 0xe3, 0xc2, 0x57, 0x51, 0xc1, 0x66, 0x85,
+	0xd2, 0x38, 0xd3, 0xf3, 0x58, 0xf2, 0xcf, 0xb1, 0x38, 0xce, 0x04, 0x3f, 0xe5, 0x9f, 0x23, 0x11,
+	0x82, 0x56, 0xe1, 0x61, 0x61, 0x14, 0xea, 0x04, 0x0e, 0x43, 0x8a, 0x31, 0x47, 0xe5, 0x87, 0xbe,
+	0xf2, 0xbd, 0x9a, 0xd5, 0xa6, 0x2b, 0xb4, 0xff, 0x6f, 0x07, 0x9a, 0x79, 0x5a, 0x5f, 0x4f, 0x4a,
+	0x3b, 0x50, 0xe3, 0x7a, 0xc4, 0xed, 0x84, 0x0c, 0xb4, 0x22, 0x9d, 0xda, 0x06, 0xe9, 0x3c, 0x00,
+	0x98, 0xaf, 0x5a, 0x44, 0xcf, 0x65, 0xfb, 0x88, 0x6d, 0x36, 0x4f, 0x66, 0x63, 0xe9, 0xb2, 0x03,
+	0xa8, 0x51, 0x6c, 0xe9, 0x35, 0x74, 0xc7, 0xdd, 0xce, 0x8d, 0xec, 0x62, 0x8f, 0x8c, 0x4a, 0xff,
+	0x2f, 0x15, 0x68, 0x3c, 0x42, 0x29, 0xfd, 0x19, 0xb2, 0x7b, 0x50, 0x55, 0x44, 0x37, 0x8e, 0xa6,
+	0x9b, 0x5b, 0xeb, 0x58, 0x5a, 0x6c, 0x13, 0x0e, 0xa9, 0xb1, 0xdb, 0x50, 0x56, 0xa2, 0x40, 0x5b,
+	0x65, 0x25, 0xe8, 0x40, 0xd3, 0x54, 0xcc, 0x0b, 0xa5, 0xd0, 0x08, 0xfb, 0x26, 0x40, 0x10, 0x2f,
+	0xa5, 0xc2, 0xf4, 0xfa, 0xe5, 0xb4, 0x32, 0x7c, 0x18, 0x7e, 0x49, 0x3d, 0xee, 0x40, 0x33, 0x16,
+	0xb3, 0xb1, 0x96, 0xda, 0x2c, 0xd5, 0x88, 0xc5, 0x4c, 0x33, 0xe9, 0x5d, 0x68, 0x91, 0x82, 0x29,
+	0xb5, 0x4d, 0x51, 0x64, 0x67, 0x08, 0x75, 0x4d, 0xd7, 0xcd, 0x4d, 0xba, 0x26, 0x69, 0x8a, 0x9f,
+	0x61, 0xa0, 0xbc, 0x96, 0xd5, 0xfb, 0x19, 0x46, 0x99, 0x45, 0x3c, 0x51, 0x1e, 0xd8, 0x99, 0x11,
+	0x62, 0xf3, 0x59, 0xfb, 0xcd, 0x7c, 0xc6, 0x8e, 0xa0, 0x29, 0xb3, 0x9b, 0xf0, 0x3a, 0xfa, 0x5a,
+	0xdd, 0xeb, 0x37, 0x94, 0x27, 0x9e, 0xeb, 0xe9, 0x3d, 0xc6, 0x13, 0x35, 0x8e, 0xf8, 0x2c, 0xf2,
+	0xba, 0x85, 0x3d, 0xc6, 0x13, 0x75, 0xce, 0x67, 0x51, 0xff, 0x6f, 0x0e, 0x74, 0x06, 0x16, 0xf5,
+	0x7d, 0x65, 0xa2, 0x3c, 0xca, 0xb6, 0x4d, 0x59, 0x5f, 0xbf, 0x97, 0xe7, 0x64, 0xfb, 0xdc, 0x58,
+	0x3a, 0xef, 0x40, 0xfd, 0xb1, 0x08, 0x71, 0x78, 0x5a, 0xdc, 0x7f, 0x06, 0xa3, 0xe5, 0x9d, 0xb1,
+	0x97, 0x57, 0xb5, 0x86, 0x27, 0x07, 0xd9, 0xb7, 0x00, 0x86, 0x09, 0x57, 0xdc, 0x8f, 0x69, 0x78,
+	0x6a, 0x56, 0xd1, 0x2d, 0xbc, 0xff, 0x9f, 0x32, 0xf4, 0xf2, 0xc2, 0x9c, 0xa3, 0x1f, 0x62, 0xca,
+	0xbe, 0x03, 0x1d, 0x89, 0x52, 0x72, 0x91, 0x98, 0xb9, 0xb3, 0x8f, 0xd5, 0xce, 0x24, 0x7a, 0xf4,
+	0xbe, 0x07, 0x5b, 0x34, 0xd4, 0x63, 0xa9, 0x44, 0x9a, 0xcd, 0xa8, 0xdd, 0xb0, 0xdd, 0x50, 0x7f,
+	0x69, 0x88, 0xd4, 0x0c, 0xea, 0x3d, 0xd8, 0x5a, 0x26, 0x29, 0xc6, 0xdc, 0x9f, 0xc4, 0x38, 0x56,
+	0x7c, 0x5e, 0x9c, 0xe8, 0xde, 0x5a, 0x78, 0xc1, 0xe7, 0xc8, 0xde, 0x83, 0xf6, 0x8c, 0x2b, 0xfa,
+	0xf6, 0xa0, 0x78, 0x85, 0x23, 0xc2, 0x8c, 0xab, 0x67, 0x06, 0x27, 0xaf, 0x91, 0x4e, 0x7b, 0x1c,
+	0x44, 0x18, 0x3c, 0x97, 0xcb, 0x79, 0x81, 0x79, 0x7a, 0x46, 0x38, 0xc8, 0x64, 0xec, 0x3e, 0xb8,
+	0x0b, 0xff, 0x2a, 0x16, 0x7e, 0xb8, 0xd6, 0xaf, 0x5b, 0xfa, 0x5b, 0x99, 0x74, 0x65, 0xf0, 0x01,
+	0x74, 0x73, 0xc5, 0xb1, 0x9e, 0xdf, 0x86, 0xbe, 0xc0, 0xd5, 0xd8, 0xe7, 0x8a, 0xd6, 0xe5, 0x75,
+	0x02, 0x0b, 0xa3, 0x6b, 0xca, 0xcf, 0x60, 0x8f, 0x45, 0x0e, 0xf6, 0x01, 0x9a, 0x66, 0xc9, 0x4b,
+	0xec, 0xff, 0xd9, 0x81, 0x4e, 0x46, 0x08, 0x66, 0x99, 0x7f, 0x1f, 0x9a, 0x29, 0xfe, 0x6a, 0x89,
+	0x52, 0xe5, 0xdb, 0x7c, 0xeb, 0x1a, 0x71, 0xe4, 0x9d, 0x9a, 0xab, 0xb1, 0xef, 0x42, 0x37, 0xc4,
+	0x45, 0x2c, 0xae, 0xe6, 0x98, 0x28, 0xea, 0x4a, 0xfb, 0x4a, 0x3a, 0x6b, 0xd1, 0x30, 0x64, 0xef,
+	0x43, 0x4f, 0x8a, 0x65, 0x1a, 0xe0, 0x38, 0xff, 0x0a, 0xac, 0x58, 0x55, 0xee, 0x1a, 0xd9, 0xf1,
+	0xe6, 0xb7, 0x60, 0x75, 0xf3, 0x5b, 0xb0, 0xff, 0xc7, 0x1a, 0x74, 0xf3, 0x3e, 0x1a, 0x44, 0xcb,
+	0xe4, 0xf9, 0x35, 0x46, 0x72, 0x6e, 0x66, 0xa4, 0x77, 0xa1, 0x91, 0x88, 0x10, 0xaf, 0xe7, 0x59,
+	0x27, 0xd0, 0x10, 0xd6, 0x6b, 0xf8, 0xee, 0x0e, 0x34, 0x03, 0x0a, 0x73, 0x9d, 0xed, 0x1a, 0x1a,
+	0x1d, 0x86, 0x3a, 0xbc, 0x56, 0x90, 0x79, 0xfb, 0xaf, 0xc3, 0x13, 0xae, 0x7b, 0xf2, 0x3d, 0x68,
+	0x1b, 0xa5, 0x40, 0x2c, 0x13, 0x55, 0x60, 0x3e, 0x63, 0x3d, 0x20, 0x9c, 0xd2, 0xd0, 0x3b, 0xad,
+	0x61, 0x75, 0x8a, 0x46, 0xd6, 0xdb, 0xa7, 0xf9, 0xfa, 0xed, 0xd3, 0x7a, 0xc3, 0xf6, 0x81, 0xaf,
+	0xb0, 0x7d, 0xec, 0x95, 0xd9, 0x79, 0xf3, 0xca, 0xec, 0xde, 0xb8, 0x32, 0x37, 0x5a, 0xa4, 0xf7,
+	0xda, 0x16, 0xd9, 0x87, 0xae, 0xf6, 0xb6, 0xaa, 0xf5, 0x96, 0x4d, 0x06, 0x24, 0x1a, 0x64, 0xf5,
+	0x3e, 0x04, 0xd7, 0xd2, 0x34, 0xf5, 0x74, 0xed, 0xf9, 0x5e, 0x29, 0x9b, 0x9a, 0xee, 0x43, 0x37,
+	0xf2, 0xe5, 0x58, 0xdb, 0xf0, 0x64, 0x2a, 0xbc, 0x6d, 0x8b, 0xa1, 0xda, 0x91, 0x2f, 0x69, 0x91,
+	0x0e, 0x93, 0xa9, 0x60, 0x3f, 0x84, 0xd6, 0x5a, 0x8b, 0xe9, 0x62, 0xdd, 0xb8, 0x75, 0xed, 0x73,
+	0x6a, 0x43, 0xab, 0x65, 0x6f, 0xdd, 0xd0, 0xb2, 0x0f, 0xa1, 0xfb, 0x50, 0xcc, 0x4e, 0x4f, 0x1e,
+	0x65, 0x1f, 0x27, 0xb6, 0xbe, 0xb3, 0xa9, 0x4f, 0x74, 0x2c, 0xe9, 0xc7, 0x46, 0x16, 0x5b, 0xd5,
+	0x60, 0x07, 0x7f, 0xaa, 0x40, 0xdb, 0x5a, 0xe6, 0xac, 0x0b, 0xad, 0x87, 0x22, 0xf0, 0xe3, 0x0b,
+	0x1e, 0x3c, 0x77, 0x4b, 0xac, 0x03, 0xcd, 0xb3, 0x18, 0x03, 0xc5, 0x45, 0xe2, 0x3a, 0xec, 0x16,
+	0x6c, 0x3d, 0xd4, 0xc4, 0x74, 0x8e, 0x7e, 0xaa, 0x26, 0xe8, 0x2b, 0xb7, 0xcc, 0xde, 0x82, 0x6d,
+	0x7b, 0x1d, 0x9c, 0x5d, 0x62, 0xa2, 0xdc, 0x0a, 0x6b, 0x42, 0xf5, 0xb1, 0xf8, 0xf8, 0x89, 0x5b,
+	0xa5, 0xa7, 0x27, 0x3c, 0x99, 0xb9, 0x35, 0xfd, 0x24, 0x92, 0x99, 0x5b, 0x67, 0x6d, 0x68, 0x3c,
+	0x49, 0xc5, 0x42, 0x48, 0x74, 0x1b, 0x8c, 0xad, 0xb9, 0xdc, 0xfc, 0xe1, 0xb9, 0x4d, 0xb6, 0x05,
+	0xed, 0x5f, 0x24, 0x29, 0xfa, 0x41, 0x44, 0xd4, 0xea, 0xb6, 0x08, 0xd0, 0xa4, 0xf5, 0xc9, 0x52,
+	0xa4, 0xcb, 0xb9, 0x0b, 0xec, 0x36, 0xb8, 0x9a, 0x6d, 0x30, 0x1c, 0xa1, 0x1f, 0xea, 0x5d, 0xee,
+	0xb6, 0x29, 0xff, 0x11, 0x2e, 0x62, 0x1e, 0xf8, 0x0a, 0xdd, 0x0e, 0xdb, 0x86, 0xee, 0xea, 0x95,
+	0xf8, 0xca, 0xed, 0x92, 0xa3, 0x91, 0x61, 0x9d, 0x67, 0x42, 0xa1, 0xdb, 0xa3, 0x53, 0x59, 0x80,
+	0xd6, 0xda, 0x22, 0x70, 0x98, 0x48, 0xe5, 0xc7, 0x71, 0x9e, 0x9a, 0xeb, 0x92, 0xf3, 0xf5, 0xc9,
+	0xb7, 0xc9, 0xf9, 0xea, 0x55, 0x9b, 0x31, 0x13, 0x3e, 0xcf, 0xe6, 0x96, 0x09, 0x9f, 0xbd, 0x6a,
+	0x8d, 0xdb, 0x74, 0xf2, 0x4f, 0x96, 0x1c, 0x65, 0x80, 0xee, 0x5b, 0x74, 0x86, 0xdc, 0xfd, 0x08,
+	0x03, 0xe4, 0x97, 0x18, 0xba, 0x6f, 0x53, 0x3d, 0x4c, 0x99, 0x2f, 0x52, 0x3f, 0x91, 0x53, 0x4c,
+	0xdd, 0x6f, 0xb0, 0x1e, 0x00, 0xed, 0x17, 0xb1, 0x54, 0x8f, 0xc5, 0xaf, 0x5d, 0xef, 0xe0, 0x01,
+	0xb4, 0x56, 0xbf, 0x7c, 0xe4, 0xe6, 0x78, 0x61, 0x4e, 0xc9, 0x45, 0xa2, 0x71, 0xb7, 0xb4, 0x71,
+	0x31, 0x1a, 0x76, 0x0e, 0x7e, 0x06, 0xee, 0xf5, 0xf5, 0x4d, 0x49, 0x1d, 0x87, 0x21, 0x6d, 0x68,
+	0xb7, 0x44, 0xa1, 0xcc, 0x5f, 0x81, 0x7e, 0x77, 0xa8, 0x60, 0xc7, 0x61, 0x98, 0x7f, 0xe0, 0xbb,
+	0xe5, 0x83, 0x03, 0xe8, 0xd8, 0xfb, 0x83, 0x0e, 0x3d, 0x18, 0x0d, 0x7e, 0x70, 0x34, 0x3c, 0x3b,
+	0x3b, 0x73, 0x4b, 0xe4, 0xec, 0x7c, 0xf8, 0xf3, 0xf3, 0x5f, 0x1e, 0x7f, 0xea, 0x3a, 0x27, 0xef,
+	0xbc, 0xf8, 0xd7, 0x6e, 0xe9, 0x8b, 0x97, 0xbb, 0xce, 0x8b, 0x97, 0xbb, 0xce, 0x3f, 0x5f, 0xee,
+	0x3a, 0xbf, 0x7b, 0xb5, 0x5b, 0x7a, 0xf1, 0x6a, 0xb7, 0xf4, 0xf7, 0x57, 0xbb, 0xa5, 0xff, 0x0e,
+	0x00, 0x1f, 0x40, 0x90, 0xac, 0xd1, 0x10, 0x00, 0x00,
 }
@@ -16,6 +16,7 @@ package raftpb
 
 import (
 	"math"
+	"reflect"
 	"testing"
 
 	"github.com/lni/dragonboat/client"
@@ -351,3 +352,54 @@ func TestMessageBatchSizeUpperLimit(t *testing.T) {
 		t.Errorf("size > size upper limit")
 	}
 }
+
+func TestJointConfigChangeCanBeMarshaled(t *testing.T) {
+	cc := ConfigChange{
+		ConfigChangeId: 100,
+		Type:           EnterJoint,
+		Changes: []ConfigChange{
+			{Type: AddNode, NodeID: 4, Address: "a4"},
+			{Type: RemoveNode, NodeID: 1},
+		},
+	}
+	data, err := cc.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal %v", err)
+	}
+	if len(data) != cc.Size() {
+		t.Errorf("size %d, want %d", len(data), cc.Size())
+	}
+	var result ConfigChange
+	if err := result.Unmarshal(data); err != nil {
+		t.Fatalf("failed to unmarshal %v", err)
+	}
+	if !reflect.DeepEqual(&cc, &result) {
+		t.Errorf("got %+v, want %+v", result, cc)
+	}
+	if !result.IsJointConfigChange() {
+		t.Errorf("not a joint config change")
+	}
+}
+
+func TestJointMembershipCanBeMarshaled(t *testing.T) {
+	m := Membership{
+		ConfigChangeId: 100,
+		Addresses:      map[uint64]string{1: "a1", 2: "a2", 4: "a4"},
+		Incoming:       map[uint64]bool{4: true},
+		Outgoing:       map[uint64]bool{1: true},
+	}
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal %v", err)
+	}
+	var result Membership
+	if err := result.Unmarshal(data); err != nil {
+		t.Fatalf("failed to unmarshal %v", err)
+	}
+	if !reflect.DeepEqual(&m, &result) {
+		t.Errorf("got %+v, want %+v", result, m)
+	}
+	if !result.IsJoint() {
+		t.Errorf("not in joint config")
+	}
+}
@@ -27,10 +27,12 @@ import (
 	"time"
 
 	"github.com/lni/dragonboat/client"
+	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/settings"
 	"github.com/lni/dragonboat/internal/utils/random"
 	"github.com/lni/dragonboat/logger"
 	pb "github.com/lni/dragonboat/raftpb"
+	sm "github.com/lni/dragonboat/statemachine"
 )
 
 const (
@@ -61,6 +63,9 @@ var (
 	// ErrPendingConfigChangeExist indicates that there is already a pending
 	// membership change exist in the system.
 	ErrPendingConfigChangeExist = errors.New("pending config change request exist")
+	// ErrPendingSnapshotRequestExist indicates that there is already a pending
+	// snapshot request exist in the system.
+	ErrPendingSnapshotRequestExist = errors.New("pending snapshot request exist")
 	// ErrTimeout indicates that the operation timed out.
 	ErrTimeout = errors.New("timeout")
 	// ErrSystemStopped indicates that the system is being shut down.
@@ -69,6 +74,17 @@ var (
 	ErrCanceled = errors.New("request canceled")
 	// ErrRejected indicates that the request has been rejected.
 	ErrRejected = errors.New("request rejected")
+	// ErrClusterNotReady indicates that the request has been dropped as the
+	// specified raft cluster is not ready to handle it, e.g. there is no leader
+	// during an election. The request can be retried immediately.
+	ErrClusterNotReady = errors.New("request dropped as the cluster is not ready")
+	// ErrTooStale indicates that the local state machine is too far behind the
+	// leader to serve the requested stale read.
+	ErrTooStale = errors.New("local state machine is too stale")
+	// ErrNodeHostDraining indicates that the NodeHost is being drained and no
+	// longer accepts new requests, the request should be retried on another
+	// NodeHost.
+	ErrNodeHostDraining = errors.New("nodehost is being drained")
 )
 
 // IsTempError returns a boolean value indicating whether the specified error
@@ -78,8 +94,59 @@ func IsTempError(err error) bool {
 	return err == ErrSystemBusy ||
 		err == ErrBadKey ||
 		err == ErrPendingConfigChangeExist ||
+		err == ErrPendingSnapshotRequestExist ||
 		err == ErrClusterClosed ||
-		err == ErrSystemStopped
+		err == ErrSystemStopped ||
+		IsClusterNotReady(err) ||
+		err == ErrTooStale ||
+		err == ErrNodeHostDraining
+}
+
+// ClusterNotReadyError is the error returned by synchronous methods such as
+// SyncPropose and SyncRead when the request is dropped as the Raft cluster is
+// not ready to handle it. It wraps ErrClusterNotReady, use IsClusterNotReady
+// to check whether an error indicates such a dropped request.
+type ClusterNotReadyError struct {
+	// LeaderID is the ID of the node suggested for retrying the request, it is
+	// 0 when there is no suggestion.
+	LeaderID uint64
+}
+
+func (e *ClusterNotReadyError) Error() string {
+	if e.LeaderID == 0 {
+		return ErrClusterNotReady.Error()
+	}
+	return fmt.Sprintf("%s, leader hint %d", ErrClusterNotReady, e.LeaderID)
+}
+
+// Unwrap returns the wrapped ErrClusterNotReady error.
+func (e *ClusterNotReadyError) Unwrap() error {
+	return ErrClusterNotReady
+}
+
+// IsClusterNotReady returns a boolean value indicating whether the specified
+// error indicates that the request has been dropped as the Raft cluster is
+// not ready to handle it.
+func IsClusterNotReady(err error) bool {
+	if err == ErrClusterNotReady {
+		return true
+	}
+	_, ok := err.(*ClusterNotReadyError)
+	return ok
+}
+
+// GetLeaderHint returns the ID of the node suggested for retrying the request
+// dropped with the specified error. The returned boolean value indicates
+// whether such a hint is available.
+func GetLeaderHint(err error) (uint64, bool) {
+	if e, ok := err.(*ClusterNotReadyError); ok && e.LeaderID != 0 {
+		return e.LeaderID, true
+	}
+	return 0, false
+}
+
+func getDroppedError(rr RequestResult) error {
+	return &ClusterNotReadyError{LeaderID: rr.leaderHint}
 }
 
 // RequestResultCode is the result code returned to the client to indicate the
@@ -93,7 +160,11 @@ type RequestResult struct {
 	// Result is the returned result from the Update method of the IStateMachine
 	// instance. Result is only available when making a proposal and the Code
 	// value is RequestCompleted.
-	result uint64
+	result sm.Result
+	// results are the returned results of all commands proposed in a batch.
+	results []sm.Result
+	// leaderHint is the ID of the node suggested for retrying dropped requests.
+	leaderHint uint64
 }
 
 // Timeout returns a boolean value indicating whether the Request timed out.
@@ -124,23 +195,52 @@ func (rr *RequestResult) Terminated() bool {
 // on the server side. For a membership change request, it means the request
 // is out of order and thus ignored. Note that the out-of-order check when
 // making membership changes is only imposed when IMasterClient is used in
-// NodeHost.
+// NodeHost. For a snapshot request, it means the request is ignored as there
+// is no progress since the last snapshot or another snapshot is in progress.
 func (rr *RequestResult) Rejected() bool {
 	return rr.code == requestRejected
 }
 
+// Dropped returns a boolean value indicating the request is dropped as the
+// Raft cluster is not ready to handle it, e.g. there is no leader during an
+// election or the leader is being transferred. Dropped requests can be
+// retried immediately, GetLeaderHint suggests which node to retry on.
+func (rr *RequestResult) Dropped() bool {
+	return rr.code == requestDropped
+}
+
+// GetLeaderHint returns the ID of the node suggested for retrying a dropped
+// request. It is usually the current or the next leader known to the node
+// that dropped the request. The returned boolean value indicates whether such
+// a hint is available.
+func (rr *RequestResult) GetLeaderHint() (uint64, bool) {
+	return rr.leaderHint, rr.leaderHint != 0
+}
+
 // GetResult returns the result value of the request. When making a proposal,
 // the returned result is the value returned by the Update method of the
-// IStateMachine instance.
-func (rr *RequestResult) GetResult() uint64 {
+// IStateMachine instance. When requesting a snapshot, the Value field of the
+// returned result is the index of the created snapshot.
+func (rr *RequestResult) GetResult() sm.Result {
 	return rr.result
 }
 
+// GetBatchResults returns the results of commands proposed using the
+// ProposeBatch method. The returned slice has one value for each proposed
+// command in the same order as they were proposed, each value is the value
+// returned by the Update method of the IStateMachine instance for that
+// command. Batch results are only available when the Code value is
+// RequestCompleted.
+func (rr *RequestResult) GetBatchResults() []sm.Result {
+	return rr.results
+}
+
 const (
 	requestTimeout RequestResultCode = iota
 	requestCompleted
 	requestTerminated
 	requestRejected
+	requestDropped
 )
 
 var requestResultCodeName = [...]string{
@@ -148,6 +248,7 @@ var requestResultCodeName = [...]string{
 	"RequestCompleted",
 	"RequestTerminated",
 	"RequestRejected",
+	"RequestDropped",
 }
 
 func (c RequestResultCode) String() string {
@@ -166,6 +267,13 @@ func getTimeoutResult() RequestResult {
 	}
 }
 
+func getDroppedResult(leaderHint uint64) RequestResult {
+	return RequestResult{
+		code:       requestDropped,
+		leaderHint: leaderHint,
+	}
+}
+
 const (
 	maxProposalPayloadSize = settings.MaxProposalPayloadSize
 )
@@ -207,6 +315,9 @@ type RequestState struct {
 	respondedTo     uint64
 	deadline        uint64
 	completeHandler ICompleteHandler
+	trace           *requestTrace
+	batchSize       int
+	results         []sm.Result
 	// CompleteC is a channel for delivering request result to users.
 	CompletedC chan RequestResult
 	node       *node
@@ -237,18 +348,33 @@ func (r *RequestState) Release() {
 		r.clientID = 0
 		r.respondedTo = 0
 		r.completeHandler = nil
+		r.trace = nil
+		r.batchSize = 0
+		r.results = nil
 		r.node = nil
 		r.pool.Put(r)
 	}
 }
 
+// batchApplied records the result of an applied command of the batch and
+// returns a boolean value indicating whether all commands in the batch have
+// been applied. It always returns true for requests with a single command.
+func (r *RequestState) batchApplied(result sm.Result) bool {
+	if r.batchSize == 0 {
+		return true
+	}
+	r.results = append(r.results, result)
+	return len(r.results) == r.batchSize
+}
+
 type proposalShard struct {
-	mu             sync.Mutex
-	proposals      *entryQueue
-	pending        map[uint64]*RequestState
-	pool           *sync.Pool
-	stopped        bool
-	expireNotified uint64
+	mu              sync.Mutex
+	proposals       *entryQueue
+	pending         map[uint64]*RequestState
+	pool            *sync.Pool
+	compressionType pb.CompressionType
+	stopped         bool
+	expireNotified  uint64
 	logicalClock
 }
 
@@ -300,6 +426,13 @@ type pendingConfigChange struct {
 	logicalClock
 }
 
+type pendingSnapshot struct {
+	mu        sync.Mutex
+	pending   *RequestState
+	snapshotC chan<- rsm.SnapshotRequest
+	logicalClock
+}
+
 func newPendingConfigChange(confChangeC chan<- *RequestState,
 	tickInMillisecond uint64) *pendingConfigChange {
 	gcTick := defaultGCTick
@@ -330,6 +463,15 @@ func (p *pendingConfigChange) close() {
 	}
 }
 
+func (p *pendingConfigChange) pendingCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending != nil {
+		return 1
+	}
+	return 0
+}
+
 func (p *pendingConfigChange) request(cc pb.ConfigChange,
 	timeout time.Duration) (*RequestState, error) {
 	p.mu.Lock()
@@ -398,6 +540,120 @@ func (p *pendingConfigChange) apply(key uint64, rejected bool) {
 	}
 }
 
+func (p *pendingConfigChange) dropped(key uint64, leaderHint uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		return
+	}
+	if p.pending.key == key {
+		p.pending.notify(getDroppedResult(leaderHint))
+		p.pending = nil
+	}
+}
+
+func newPendingSnapshot(snapshotC chan<- rsm.SnapshotRequest,
+	tickInMillisecond uint64) *pendingSnapshot {
+	gcTick := defaultGCTick
+	if gcTick == 0 {
+		panic("invalid gcTick")
+	}
+	lcu := logicalClock{
+		tickInMillisecond: tickInMillisecond,
+		gcTick:            gcTick,
+	}
+	p := &pendingSnapshot{
+		snapshotC:    snapshotC,
+		logicalClock: lcu,
+	}
+	return p
+}
+
+func (p *pendingSnapshot) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.snapshotC != nil {
+		if p.pending != nil {
+			p.pending.notify(getTerminatedResult())
+			p.pending = nil
+		}
+		close(p.snapshotC)
+		p.snapshotC = nil
+	}
+}
+
+func (p *pendingSnapshot) request(st rsm.SnapshotRequestType,
+	path string, overrideCompaction bool, compactionOverhead uint64,
+	timeout time.Duration) (*RequestState, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timeoutTick := p.getTimeoutTick(timeout)
+	if timeoutTick == 0 {
+		return nil, ErrTimeoutTooSmall
+	}
+	if p.pending != nil {
+		return nil, ErrPendingSnapshotRequestExist
+	}
+	if p.snapshotC == nil {
+		return nil, ErrClusterClosed
+	}
+	ssreq := rsm.SnapshotRequest{
+		Type:               st,
+		Path:               path,
+		Key:                random.LockGuardedRand.Uint64(),
+		OverrideCompaction: overrideCompaction,
+		CompactionOverhead: compactionOverhead,
+	}
+	req := &RequestState{
+		key:        ssreq.Key,
+		deadline:   p.getTick() + timeoutTick,
+		CompletedC: make(chan RequestResult, 1),
+	}
+	select {
+	case p.snapshotC <- ssreq:
+		p.pending = req
+		return req, nil
+	default:
+	}
+	return nil, ErrSystemBusy
+}
+
+func (p *pendingSnapshot) gc() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		return
+	}
+	now := p.getTick()
+	if now-p.lastGcTime < p.gcTick {
+		return
+	}
+	p.lastGcTime = now
+	if p.pending.deadline < now {
+		p.pending.notify(getTimeoutResult())
+		p.pending = nil
+	}
+}
+
+func (p *pendingSnapshot) apply(key uint64, ignored bool, index uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		return
+	}
+	var v RequestResult
+	if ignored {
+		v.code = requestRejected
+	} else {
+		v.code = requestCompleted
+		v.result = sm.Result{Value: index}
+	}
+	if p.pending.key == key {
+		p.pending.notify(v)
+		p.pending = nil
+	}
+}
+
 func newPendingReadIndex(pool *sync.Pool, requests *readIndexQueue,
 	tickInMillisecond uint64) *pendingReadIndex {
 	gcTick := defaultGCTick
@@ -437,8 +693,25 @@ func (p *pendingReadIndex) close() {
 	}
 }
 
+// pendingCount returns the number of read requests yet to be completed,
+// including those still waiting in the incoming queue.
+func (p *pendingReadIndex) pendingCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := len(p.pending)
+	if p.requests != nil {
+		count += int(p.requests.pendingSize())
+	}
+	return count
+}
+
 func (p *pendingReadIndex) read(handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
+	return p.readWithTrace(handler, timeout, nil)
+}
+
+func (p *pendingReadIndex) readWithTrace(handler ICompleteHandler,
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
 	timeoutTick := p.getTimeoutTick(timeout)
 	if timeoutTick == 0 {
 		return nil, ErrTimeoutTooSmall
@@ -446,6 +719,7 @@ func (p *pendingReadIndex) read(handler ICompleteHandler,
 	req := p.pool.Get().(*RequestState)
 	req.completeHandler = handler
 	req.key = p.nextUserCtx()
+	req.trace = trace
 	req.deadline = p.getTick() + timeoutTick
 	if len(req.CompletedC) > 0 {
 		req.CompletedC = make(chan RequestResult, 1)
@@ -560,6 +834,7 @@ func (p *pendingReadIndex) applied(applied uint64) {
 			var v RequestResult
 			if req.deadline > now {
 				v.code = requestCompleted
+				req.trace.readIndexConfirmed()
 			} else {
 				v.code = requestTimeout
 			}
@@ -579,6 +854,25 @@ func (p *pendingReadIndex) applied(applied uint64) {
 	p.mu.Unlock()
 }
 
+func (p *pendingReadIndex) dropped(system pb.SystemCtx, leaderHint uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	toDelete := make([]uint64, 0)
+	for userKey, req := range p.pending {
+		if p.mapping[userKey] == system {
+			req.notify(getDroppedResult(leaderHint))
+			toDelete = append(toDelete, userKey)
+		}
+	}
+	for _, v := range toDelete {
+		delete(p.pending, v)
+		delete(p.mapping, v)
+	}
+}
+
 func (p *pendingReadIndex) gc(now uint64) {
 	toDeleteCount := 0
 	for _, v := range p.systemGcTime {
@@ -624,7 +918,7 @@ func getRandomGenerator(clusterID uint64,
 
 func newPendingProposal(pool *sync.Pool,
 	proposals *entryQueue, clusterID uint64, nodeID uint64, raftAddress string,
-	tickInMillisecond uint64) *pendingProposal {
+	tickInMillisecond uint64, ct pb.CompressionType) *pendingProposal {
 	ps := uint64(16)
 	p := &pendingProposal{
 		shards: make([]*proposalShard, ps),
@@ -633,7 +927,7 @@ func newPendingProposal(pool *sync.Pool,
 	}
 	for i := uint64(0); i < ps; i++ {
 		p.shards[i] = newPendingProposalShard(pool,
-			proposals, tickInMillisecond)
+			proposals, tickInMillisecond, ct)
 		p.keyg[i] = getRandomGenerator(clusterID, nodeID, raftAddress, i)
 	}
 	return p
@@ -642,9 +936,29 @@ func newPendingProposal(pool *sync.Pool,
 func (p *pendingProposal) propose(session *client.Session,
 	cmd []byte, handler ICompleteHandler,
 	timeout time.Duration) (*RequestState, error) {
+	return p.proposeWithTrace(session, cmd, handler, timeout, nil)
+}
+
+func (p *pendingProposal) proposeWithTrace(session *client.Session,
+	cmd []byte, handler ICompleteHandler,
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
 	key := p.nextKey(session.ClientID)
 	pp := p.shards[key%p.ps]
-	return pp.propose(session, cmd, key, handler, timeout)
+	return pp.propose(session, cmd, key, handler, timeout, trace)
+}
+
+func (p *pendingProposal) proposeBatch(session *client.Session,
+	cmds [][]byte, timeout time.Duration) (*RequestState, error) {
+	key := p.nextKey(session.ClientID)
+	pp := p.shards[key%p.ps]
+	return pp.proposeBatch(session, cmds, key, timeout)
+}
+
+// getTrace returns the trace of the pending proposal identified by the
+// specified key, nil is returned when the proposal is not traced.
+func (p *pendingProposal) getTrace(key uint64) *requestTrace {
+	pp := p.shards[key%p.ps]
+	return pp.getTrace(key)
 }
 
 func (p *pendingProposal) close() {
@@ -653,12 +967,26 @@ func (p *pendingProposal) close() {
 	}
 }
 
+func (p *pendingProposal) pendingCount() int {
+	count := 0
+	for _, pp := range p.shards {
+		count += pp.pendingCount()
+	}
+	return count
+}
+
 func (p *pendingProposal) applied(clientID uint64,
-	seriesID uint64, key uint64, result uint64, rejected bool) {
+	seriesID uint64, key uint64, result sm.Result, rejected bool) {
 	pp := p.shards[key%p.ps]
 	pp.applied(clientID, seriesID, key, result, rejected)
 }
 
+func (p *pendingProposal) dropped(clientID uint64,
+	seriesID uint64, key uint64, leaderHint uint64) {
+	pp := p.shards[key%p.ps]
+	pp.dropped(clientID, seriesID, key, leaderHint)
+}
+
 func (p *pendingProposal) nextKey(clientID uint64) uint64 {
 	return p.keyg[clientID%p.ps].nextKey()
 }
@@ -677,7 +1005,8 @@ func (p *pendingProposal) gc() {
 }
 
 func newPendingProposalShard(pool *sync.Pool,
-	proposals *entryQueue, tickInMillisecond uint64) *proposalShard {
+	proposals *entryQueue, tickInMillisecond uint64,
+	ct pb.CompressionType) *proposalShard {
 	gcTick := defaultGCTick
 	if gcTick == 0 {
 		panic("invalid gcTick")
@@ -687,17 +1016,18 @@ func newPendingProposalShard(pool *sync.Pool,
 		gcTick:            gcTick,
 	}
 	p := &proposalShard{
-		proposals:    proposals,
-		pending:      make(map[uint64]*RequestState),
-		logicalClock: lcu,
-		pool:         pool,
+		proposals:       proposals,
+		pending:         make(map[uint64]*RequestState),
+		logicalClock:    lcu,
+		pool:            pool,
+		compressionType: ct,
 	}
 	return p
 }
 
 func (p *proposalShard) propose(session *client.Session,
 	cmd []byte, key uint64, handler ICompleteHandler,
-	timeout time.Duration) (*RequestState, error) {
+	timeout time.Duration, trace *requestTrace) (*RequestState, error) {
 	timeoutTick := p.getTimeoutTick(timeout)
 	if timeoutTick == 0 {
 		return nil, ErrTimeoutTooSmall
@@ -710,13 +1040,14 @@ func (p *proposalShard) propose(session *client.Session,
 		ClientID:    session.ClientID,
 		SeriesID:    session.SeriesID,
 		RespondedTo: session.RespondedTo,
-		Cmd:         prepareProposalPayload(cmd),
 	}
+	entry.Type, entry.Cmd = getProposalPayload(cmd, p.compressionType)
 	req := p.pool.Get().(*RequestState)
 	req.clientID = session.ClientID
 	req.seriesID = session.SeriesID
 	req.completeHandler = handler
 	req.key = entry.Key
+	req.trace = trace
 	req.deadline = p.getTick() + timeoutTick
 	if len(req.CompletedC) > 0 {
 		req.CompletedC = make(chan RequestResult, 1)
@@ -752,6 +1083,73 @@ func (p *proposalShard) propose(session *client.Session,
 	return req, nil
 }
 
+// proposeBatch proposes all specified cmds using a single RequestState. All
+// entries in the batch share the same key and are added to the entry queue
+// together, they are thus proposed and applied in the same order.
+func (p *proposalShard) proposeBatch(session *client.Session,
+	cmds [][]byte, key uint64, timeout time.Duration) (*RequestState, error) {
+	timeoutTick := p.getTimeoutTick(timeout)
+	if timeoutTick == 0 {
+		return nil, ErrTimeoutTooSmall
+	}
+	if uint64(len(cmds)) > p.proposals.size {
+		return nil, ErrPayloadTooBig
+	}
+	entries := make([]pb.Entry, len(cmds))
+	for idx, cmd := range cmds {
+		if uint64(len(cmd)) > maxProposalPayloadSize {
+			return nil, ErrPayloadTooBig
+		}
+		entries[idx] = pb.Entry{
+			Key:         key,
+			ClientID:    session.ClientID,
+			SeriesID:    session.SeriesID,
+			RespondedTo: session.RespondedTo,
+		}
+		entries[idx].Type, entries[idx].Cmd = getProposalPayload(cmd,
+			p.compressionType)
+	}
+	req := p.pool.Get().(*RequestState)
+	req.clientID = session.ClientID
+	req.seriesID = session.SeriesID
+	req.key = key
+	req.batchSize = len(cmds)
+	req.results = make([]sm.Result, 0, len(cmds))
+	req.deadline = p.getTick() + timeoutTick
+	if len(req.CompletedC) > 0 {
+		req.CompletedC = make(chan RequestResult, 1)
+	}
+
+	p.mu.Lock()
+	if badKeyCheck {
+		_, ok := p.pending[key]
+		if ok {
+			plog.Warningf("bad key")
+			p.mu.Unlock()
+			return nil, ErrBadKey
+		}
+	}
+	p.pending[key] = req
+	p.mu.Unlock()
+
+	added, stopped := p.proposals.addBatch(entries)
+	if stopped {
+		plog.Warningf("dropping proposals, cluster stopped")
+		p.mu.Lock()
+		delete(p.pending, key)
+		p.mu.Unlock()
+		return nil, ErrClusterClosed
+	}
+	if !added {
+		p.mu.Lock()
+		delete(p.pending, key)
+		p.mu.Unlock()
+		plog.Warningf("dropping proposals, overloaded")
+		return nil, ErrSystemBusy
+	}
+	return req, nil
+}
+
 func (p *proposalShard) close() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -764,8 +1162,24 @@ func (p *proposalShard) close() {
 	}
 }
 
+func (p *proposalShard) pendingCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+func (p *proposalShard) getTrace(key uint64) *requestTrace {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ps, ok := p.pending[key]; ok {
+		return ps.trace
+	}
+	return nil
+}
+
 func (p *proposalShard) getProposal(clientID uint64,
-	seriesID uint64, key uint64, now uint64) *RequestState {
+	seriesID uint64, key uint64, result sm.Result, rejected bool,
+	now uint64) *RequestState {
 	p.mu.Lock()
 	if p.stopped {
 		p.mu.Unlock()
@@ -774,6 +1188,10 @@ func (p *proposalShard) getProposal(clientID uint64,
 	ps, ok := p.pending[key]
 	if ok && ps.deadline >= now {
 		if ps.clientID == clientID && ps.seriesID == seriesID {
+			if !rejected && !ps.batchApplied(result) {
+				p.mu.Unlock()
+				return nil
+			}
 			delete(p.pending, key)
 			p.mu.Unlock()
 			return ps
@@ -784,7 +1202,7 @@ func (p *proposalShard) getProposal(clientID uint64,
 }
 
 func (p *proposalShard) applied(clientID uint64,
-	seriesID uint64, key uint64, result uint64, rejected bool) {
+	seriesID uint64, key uint64, result sm.Result, rejected bool) {
 	now := p.getTick()
 	var code RequestResultCode
 	if rejected {
@@ -792,9 +1210,13 @@ func (p *proposalShard) applied(clientID uint64,
 	} else {
 		code = requestCompleted
 	}
-	ps := p.getProposal(clientID, seriesID, key, now)
+	ps := p.getProposal(clientID, seriesID, key, result, rejected, now)
 	if ps != nil {
-		ps.notify(RequestResult{code: code, result: result})
+		rr := RequestResult{code: code, result: result}
+		if !rejected {
+			rr.results = ps.results
+		}
+		ps.notify(rr)
 	}
 	tick := p.getTick()
 	if tick != p.expireNotified {
@@ -803,6 +1225,15 @@ func (p *proposalShard) applied(clientID uint64,
 	}
 }
 
+func (p *proposalShard) dropped(clientID uint64,
+	seriesID uint64, key uint64, leaderHint uint64) {
+	now := p.getTick()
+	ps := p.getProposal(clientID, seriesID, key, sm.Result{}, true, now)
+	if ps != nil {
+		ps.notify(getDroppedResult(leaderHint))
+	}
+}
+
 func (p *proposalShard) gc() {
 	now := p.getTick()
 	p.gcAt(now)
@@ -838,3 +1269,14 @@ func prepareProposalPayload(cmd []byte) []byte {
 	copy(dst, cmd)
 	return dst
 }
+
+// getProposalPayload returns the entry type and the payload of the entry
+// used for proposing the specified cmd. Empty cmds are never encoded as
+// they are used for session management.
+func getProposalPayload(cmd []byte,
+	ct pb.CompressionType) (pb.EntryType, []byte) {
+	if len(cmd) == 0 || ct == pb.NoCompression {
+		return pb.ApplicationEntry, prepareProposalPayload(cmd)
+	}
+	return pb.EncodedEntry, rsm.GetEncodedPayload(ct, cmd)
+}
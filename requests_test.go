@@ -25,7 +25,9 @@ import (
 	"time"
 
 	"github.com/lni/dragonboat/client"
+	"github.com/lni/dragonboat/internal/rsm"
 	pb "github.com/lni/dragonboat/raftpb"
+	sm "github.com/lni/dragonboat/statemachine"
 )
 
 const (
@@ -183,6 +185,197 @@ func TestConfigChangeRequestCanNotBeNotifiedWithDifferentKey(t *testing.T) {
 	}
 }
 
+func TestDroppedConfigChangeRequestCanBeNotified(t *testing.T) {
+	pcc, _ := getPendingConfigChange()
+	var cc pb.ConfigChange
+	rs, err := pcc.request(cc, time.Second)
+	if err != nil {
+		t.Errorf("RequestConfigChange failed: %v", err)
+	}
+	pcc.dropped(rs.key+1, 2)
+	select {
+	case <-rs.CompletedC:
+		t.Errorf("not suppose to return anything yet")
+	default:
+	}
+	pcc.dropped(rs.key, 2)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Dropped() {
+			t.Errorf("returned %d, want %d", v, requestDropped)
+		}
+		if leaderID, ok := v.GetLeaderHint(); !ok || leaderID != 2 {
+			t.Errorf("unexpected leader hint %d", leaderID)
+		}
+	default:
+		t.Errorf("suppose to return something")
+	}
+}
+
+func TestPendingConfigChangeCanBeCounted(t *testing.T) {
+	pcc, _ := getPendingConfigChange()
+	if v := pcc.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+	var cc pb.ConfigChange
+	rs, err := pcc.request(cc, time.Second)
+	if err != nil {
+		t.Fatalf("RequestConfigChange failed: %v", err)
+	}
+	if v := pcc.pendingCount(); v != 1 {
+		t.Errorf("count %d, want 1", v)
+	}
+	pcc.apply(rs.key, false)
+	if v := pcc.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+}
+
+//
+// pending snapshot
+//
+
+func getPendingSnapshot() (*pendingSnapshot, chan rsm.SnapshotRequest) {
+	c := make(chan rsm.SnapshotRequest, 1)
+	return newPendingSnapshot(c, testTickInMillisecond), c
+}
+
+func TestPendingSnapshotCanBeCreatedAndClosed(t *testing.T) {
+	ps, c := getPendingSnapshot()
+	select {
+	case <-c:
+		t.Errorf("unexpected content in snapshotC")
+	default:
+	}
+	ps.close()
+	select {
+	case _, ok := <-c:
+		if ok {
+			t.Errorf("suppose to be closed")
+		}
+	default:
+		t.Errorf("missing closed signal")
+	}
+	if _, err := ps.request(rsm.UserRequestedSnapshot,
+		"", false, 0, time.Second); err != ErrClusterClosed {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSnapshotCanBeRequested(t *testing.T) {
+	ps, c := getPendingSnapshot()
+	rs, err := ps.request(rsm.ExportedSnapshot, "p", true, 10, time.Second)
+	if err != nil {
+		t.Errorf("failed to request snapshot: %v", err)
+	}
+	if rs == nil {
+		t.Errorf("returned rs is nil")
+	}
+	if ps.pending == nil {
+		t.Errorf("request not internally recorded")
+	}
+	if len(c) != 1 {
+		t.Errorf("len(c) = %d, want 1", len(c))
+	}
+	req := <-c
+	if req.Key != rs.key || req.Path != "p" || !req.IsExportedSnapshot() ||
+		!req.OverrideCompaction || req.CompactionOverhead != 10 {
+		t.Errorf("unexpected snapshot request %+v", req)
+	}
+	_, err = ps.request(rsm.UserRequestedSnapshot, "", false, 0, time.Second)
+	if err != ErrPendingSnapshotRequestExist {
+		t.Errorf("expected ErrPendingSnapshotRequestExist, %v", err)
+	}
+	ps.close()
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Terminated() {
+			t.Errorf("returned %d, want %d", v, requestTerminated)
+		}
+	default:
+		t.Errorf("expect to return something")
+	}
+}
+
+func TestSnapshotRequestCanExpire(t *testing.T) {
+	ps, _ := getPendingSnapshot()
+	timeout := time.Duration(1000 * time.Millisecond)
+	tickCount := uint64(1000 / testTickInMillisecond)
+	rs, err := ps.request(rsm.UserRequestedSnapshot, "", false, 0, timeout)
+	if err != nil {
+		t.Errorf("failed to request snapshot: %v", err)
+	}
+	for i := uint64(0); i < tickCount; i++ {
+		ps.increaseTick()
+		ps.gc()
+	}
+	select {
+	case <-rs.CompletedC:
+		t.Errorf("not suppose to has anything at this stage")
+	default:
+	}
+	for i := uint64(0); i < defaultGCTick+1; i++ {
+		ps.increaseTick()
+		ps.gc()
+	}
+	select {
+	case v, ok := <-rs.CompletedC:
+		if ok {
+			if !v.Timeout() {
+				t.Errorf("v: %d, expect %d", v, requestTimeout)
+			}
+		}
+	default:
+		t.Errorf("expect to be expired")
+	}
+}
+
+func TestCompletedSnapshotRequestCanBeNotified(t *testing.T) {
+	ps, _ := getPendingSnapshot()
+	rs, err := ps.request(rsm.UserRequestedSnapshot, "", false, 0, time.Second)
+	if err != nil {
+		t.Errorf("failed to request snapshot: %v", err)
+	}
+	ps.apply(rs.key+1, false, 100)
+	select {
+	case <-rs.CompletedC:
+		t.Errorf("unexpectedly notified")
+	default:
+	}
+	ps.apply(rs.key, false, 100)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Completed() {
+			t.Errorf("returned %d, want %d", v, requestCompleted)
+		}
+		if v.GetResult().Value != 100 {
+			t.Errorf("snapshot index %d, want 100", v.GetResult().Value)
+		}
+	default:
+		t.Errorf("suppose to return something")
+	}
+	if ps.pending != nil {
+		t.Errorf("pending request not cleared")
+	}
+}
+
+func TestIgnoredSnapshotRequestIsRejected(t *testing.T) {
+	ps, _ := getPendingSnapshot()
+	rs, err := ps.request(rsm.UserRequestedSnapshot, "", false, 0, time.Second)
+	if err != nil {
+		t.Errorf("failed to request snapshot: %v", err)
+	}
+	ps.apply(rs.key, true, 0)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Rejected() {
+			t.Errorf("returned %d, want %d", v, requestRejected)
+		}
+	default:
+		t.Errorf("suppose to return something")
+	}
+}
+
 //
 // pending proposal
 //
@@ -196,8 +389,8 @@ func getPendingProposal() (*pendingProposal, *entryQueue) {
 		obj.CompletedC = make(chan RequestResult, 1)
 		return obj
 	}
-	return newPendingProposal(p,
-		c, 100, 120, "nodehost:12345", testTickInMillisecond), c
+	return newPendingProposal(p, c, 100, 120,
+		"nodehost:12345", testTickInMillisecond, pb.NoCompression), c
 }
 
 func getBlankTestSession() *client.Session {
@@ -267,7 +460,7 @@ func TestProposalCanBeCompleted(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to make proposal, %v", err)
 	}
-	pp.applied(rs.clientID, rs.seriesID, rs.key+1, 0, false)
+	pp.applied(rs.clientID, rs.seriesID, rs.key+1, sm.Result{}, false)
 	select {
 	case <-rs.CompletedC:
 		t.Errorf("unexpected applied proposal with invalid client ID")
@@ -276,7 +469,7 @@ func TestProposalCanBeCompleted(t *testing.T) {
 	if countPendingProposal(pp) == 0 {
 		t.Errorf("pending is empty")
 	}
-	pp.applied(rs.clientID, rs.seriesID, rs.key, 0, false)
+	pp.applied(rs.clientID, rs.seriesID, rs.key, sm.Result{}, false)
 	select {
 	case v := <-rs.CompletedC:
 		if !v.Completed() {
@@ -290,13 +483,192 @@ func TestProposalCanBeCompleted(t *testing.T) {
 	}
 }
 
+func TestProposalCanBeDropped(t *testing.T) {
+	pp, _ := getPendingProposal()
+	rs, err := pp.propose(getBlankTestSession(), []byte("test data"), nil, time.Second)
+	if err != nil {
+		t.Errorf("failed to make proposal, %v", err)
+	}
+	pp.dropped(rs.clientID, rs.seriesID, rs.key, 0)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Dropped() {
+			t.Errorf("get %d, want %d", v, requestDropped)
+		}
+		if _, ok := v.GetLeaderHint(); ok {
+			t.Errorf("unexpected leader hint")
+		}
+	default:
+		t.Errorf("expect to get dropped signal")
+	}
+	if countPendingProposal(pp) != 0 {
+		t.Errorf("pending is not empty")
+	}
+}
+
+func TestDroppedErrorCarriesLeaderHint(t *testing.T) {
+	err := getDroppedError(getDroppedResult(2))
+	if !IsClusterNotReady(err) || !IsTempError(err) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if leaderID, ok := GetLeaderHint(err); !ok || leaderID != 2 {
+		t.Errorf("leader hint %d, want 2", leaderID)
+	}
+	if e, ok := err.(*ClusterNotReadyError); !ok || e.Unwrap() != ErrClusterNotReady {
+		t.Errorf("ErrClusterNotReady not wrapped")
+	}
+	err = getDroppedError(getDroppedResult(0))
+	if !IsClusterNotReady(err) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, ok := GetLeaderHint(err); ok {
+		t.Errorf("unexpected leader hint")
+	}
+	if _, ok := GetLeaderHint(ErrClusterNotReady); ok {
+		t.Errorf("unexpected leader hint")
+	}
+	if IsClusterNotReady(ErrTimeout) {
+		t.Errorf("ErrTimeout reported as not ready")
+	}
+}
+
+func TestPendingProposalCanBeCounted(t *testing.T) {
+	pp, _ := getPendingProposal()
+	if v := pp.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+	rs, err := pp.propose(getBlankTestSession(), []byte("test data"), nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to make proposal, %v", err)
+	}
+	if v := pp.pendingCount(); v != 1 {
+		t.Errorf("count %d, want 1", v)
+	}
+	pp.applied(rs.clientID, rs.seriesID, rs.key, sm.Result{}, false)
+	if v := pp.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+}
+
+func TestDroppedBatchProposalIsNotifiedOnce(t *testing.T) {
+	pp, _ := getPendingProposal()
+	cmds := [][]byte{[]byte("cmd1"), []byte("cmd2")}
+	rs, err := pp.proposeBatch(getBlankTestSession(), cmds, time.Second)
+	if err != nil {
+		t.Fatalf("failed to make proposal, %v", err)
+	}
+	pp.dropped(rs.clientID, rs.seriesID, rs.key, 3)
+	pp.dropped(rs.clientID, rs.seriesID, rs.key, 3)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Dropped() {
+			t.Errorf("get %d, want %d", v, requestDropped)
+		}
+	default:
+		t.Errorf("expect to get dropped signal")
+	}
+	if countPendingProposal(pp) != 0 {
+		t.Errorf("pending is not empty")
+	}
+}
+
+func TestBatchProposalCanBeCompleted(t *testing.T) {
+	pp, c := getPendingProposal()
+	cmds := [][]byte{[]byte("cmd1"), []byte("cmd2"), []byte("cmd3")}
+	rs, err := pp.proposeBatch(getBlankTestSession(), cmds, time.Second)
+	if err != nil {
+		t.Fatalf("failed to make proposal, %v", err)
+	}
+	entries := c.get(false)
+	if len(entries) != len(cmds) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(cmds))
+	}
+	for idx, e := range entries {
+		if e.Key != rs.key || string(e.Cmd) != string(cmds[idx]) {
+			t.Errorf("unexpected entry %v", e)
+		}
+	}
+	for idx := range cmds {
+		select {
+		case <-rs.CompletedC:
+			t.Fatalf("completed before all commands are applied")
+		default:
+		}
+		pp.applied(rs.clientID, rs.seriesID, rs.key,
+			sm.Result{Value: uint64(idx + 100)}, false)
+	}
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Completed() {
+			t.Fatalf("get %d, want %d", v.code, requestCompleted)
+		}
+		results := v.GetBatchResults()
+		if len(results) != len(cmds) {
+			t.Fatalf("got %d results, want %d", len(results), len(cmds))
+		}
+		for idx, r := range results {
+			if r.Value != uint64(idx+100) {
+				t.Errorf("result %d, want %d", r.Value, idx+100)
+			}
+		}
+	default:
+		t.Errorf("expect to get complete signal")
+	}
+	if countPendingProposal(pp) != 0 {
+		t.Errorf("pending is not empty")
+	}
+}
+
+func TestProposalResultDataIsReturned(t *testing.T) {
+	pp, _ := getPendingProposal()
+	rs, err := pp.propose(getBlankTestSession(), []byte("test data"), nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to make proposal, %v", err)
+	}
+	result := sm.Result{Value: 100, Data: []byte("result-data")}
+	pp.applied(rs.clientID, rs.seriesID, rs.key, result, false)
+	select {
+	case v := <-rs.CompletedC:
+		r := v.GetResult()
+		if r.Value != 100 || string(r.Data) != "result-data" {
+			t.Errorf("unexpected result %v", r)
+		}
+	default:
+		t.Errorf("expect to get complete signal")
+	}
+}
+
+func TestBatchProposalErrorsAreReported(t *testing.T) {
+	pp, _ := getPendingProposal()
+	cmds := make([][]byte, 6)
+	if _, err := pp.proposeBatch(getBlankTestSession(),
+		cmds, time.Second); err != ErrPayloadTooBig {
+		t.Errorf("got %v, want %v", err, ErrPayloadTooBig)
+	}
+	if _, err := pp.proposeBatch(getBlankTestSession(),
+		cmds[:2], time.Millisecond); err != ErrTimeoutTooSmall {
+		t.Errorf("got %v, want %v", err, ErrTimeoutTooSmall)
+	}
+	if _, err := pp.proposeBatch(getBlankTestSession(),
+		cmds[:3], time.Second); err != nil {
+		t.Fatalf("failed to make proposal, %v", err)
+	}
+	if _, err := pp.proposeBatch(getBlankTestSession(),
+		cmds[:3], time.Second); err != ErrSystemBusy {
+		t.Errorf("got %v, want %v", err, ErrSystemBusy)
+	}
+	if countPendingProposal(pp) != 1 {
+		t.Errorf("unexpected pending count")
+	}
+}
+
 func TestClientIDIsCheckedWhenApplyingProposal(t *testing.T) {
 	pp, _ := getPendingProposal()
 	rs, err := pp.propose(getBlankTestSession(), []byte("test data"), nil, time.Second)
 	if err != nil {
 		t.Errorf("failed to make proposal, %v", err)
 	}
-	pp.applied(rs.clientID+1, rs.seriesID, rs.key, 0, false)
+	pp.applied(rs.clientID+1, rs.seriesID, rs.key, sm.Result{}, false)
 	select {
 	case <-rs.CompletedC:
 		t.Errorf("unexpected applied proposal with invalid client ID")
@@ -305,7 +677,7 @@ func TestClientIDIsCheckedWhenApplyingProposal(t *testing.T) {
 	if countPendingProposal(pp) == 0 {
 		t.Errorf("pending is empty")
 	}
-	pp.applied(rs.clientID, rs.seriesID, rs.key, 0, false)
+	pp.applied(rs.clientID, rs.seriesID, rs.key, sm.Result{}, false)
 	select {
 	case v := <-rs.CompletedC:
 		if !v.Completed() {
@@ -325,7 +697,7 @@ func TestSeriesIDIsCheckedWhenApplyingProposal(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to make proposal, %v", err)
 	}
-	pp.applied(rs.clientID, rs.seriesID+1, rs.key, 0, false)
+	pp.applied(rs.clientID, rs.seriesID+1, rs.key, sm.Result{}, false)
 	select {
 	case <-rs.CompletedC:
 		t.Errorf("unexpected applied proposal with invalid client ID")
@@ -334,7 +706,7 @@ func TestSeriesIDIsCheckedWhenApplyingProposal(t *testing.T) {
 	if countPendingProposal(pp) == 0 {
 		t.Errorf("pending is empty")
 	}
-	pp.applied(rs.clientID, rs.seriesID, rs.key, 0, false)
+	pp.applied(rs.clientID, rs.seriesID, rs.key, sm.Result{}, false)
 	select {
 	case v := <-rs.CompletedC:
 		if !v.Completed() {
@@ -456,7 +828,7 @@ func TestClosePendingProposalIgnoresStepEngineActivities(t *testing.T) {
 	for i := uint64(0); i < pp.ps; i++ {
 		pp.shards[i].stopped = true
 	}
-	pp.applied(rs.clientID, rs.seriesID, rs.key, 1, false)
+	pp.applied(rs.clientID, rs.seriesID, rs.key, sm.Result{Value: 1}, false)
 	select {
 	case <-rs.CompletedC:
 		t.Fatalf("completedC unexpectedly signaled")
@@ -557,6 +929,59 @@ func TestPendingSCReadCanComplete(t *testing.T) {
 	}
 }
 
+func TestPendingSCReadCanBeDropped(t *testing.T) {
+	pp, _ := getPendingSCRead()
+	rs, err := pp.read(nil, time.Second)
+	if err != nil {
+		t.Errorf("failed to do read")
+	}
+	s := pp.peepNextCtx()
+	pp.addPendingRead(s, []*RequestState{rs})
+	pp.dropped(pb.SystemCtx{Low: s.Low + 1, High: s.High}, 2)
+	select {
+	case <-rs.CompletedC:
+		t.Errorf("not expected to be signaled")
+	default:
+	}
+	pp.dropped(s, 2)
+	select {
+	case v := <-rs.CompletedC:
+		if !v.Dropped() {
+			t.Errorf("got %d, want %d", v, requestDropped)
+		}
+		if leaderID, ok := v.GetLeaderHint(); !ok || leaderID != 2 {
+			t.Errorf("unexpected leader hint %d", leaderID)
+		}
+	default:
+		t.Errorf("expect to be dropped")
+	}
+	if len(pp.pending) != 0 || len(pp.mapping) != 0 {
+		t.Errorf("leaking records")
+	}
+}
+
+func TestPendingSCReadCanBeCounted(t *testing.T) {
+	pp, q := getPendingSCRead()
+	if v := pp.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+	if _, err := pp.read(nil, time.Second); err != nil {
+		t.Fatalf("failed to do read")
+	}
+	if v := pp.pendingCount(); v != 1 {
+		t.Errorf("count %d, want 1", v)
+	}
+	s := pp.peepNextCtx()
+	pp.addPendingRead(s, q.get())
+	if v := pp.pendingCount(); v != 1 {
+		t.Errorf("count %d, want 1", v)
+	}
+	pp.dropped(s, 2)
+	if v := pp.pendingCount(); v != 0 {
+		t.Errorf("count %d, want 0", v)
+	}
+}
+
 func TestPendingSCReadCanExpire(t *testing.T) {
 	pp, _ := getPendingSCRead()
 	timeout := time.Duration(1000 * time.Millisecond)
@@ -656,3 +1081,23 @@ func TestSystemGcTimeInSCReadCanBeCleanedUp(t *testing.T) {
 		t.Errorf("not cleaning up systemGcTime")
 	}
 }
+
+func TestProposalPayloadIsEncodedWhenCompressionIsEnabled(t *testing.T) {
+	cmd := bytes.Repeat([]byte("test-data"), 128)
+	et, payload := getProposalPayload(cmd, pb.NoCompression)
+	if et != pb.ApplicationEntry || !bytes.Equal(payload, cmd) {
+		t.Errorf("unexpected payload")
+	}
+	et, payload = getProposalPayload(cmd, pb.Snappy)
+	if et != pb.EncodedEntry || len(payload) >= len(cmd) {
+		t.Errorf("payload not compressed")
+	}
+	e := pb.Entry{Type: et, Cmd: payload}
+	if !bytes.Equal(rsm.GetEntryPayload(e), cmd) {
+		t.Errorf("payload changed")
+	}
+	et, payload = getProposalPayload(nil, pb.Snappy)
+	if et != pb.ApplicationEntry || len(payload) != 0 {
+		t.Errorf("empty payload unexpectedly encoded")
+	}
+}
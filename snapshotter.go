@@ -41,6 +41,7 @@ var (
 	snapshotDirNameRe     = regexp.MustCompile(`^snapshot-[0-9A-F]+$`)
 	genSnapshotDirNameRe  = regexp.MustCompile(`^snapshot-[0-9A-F]+-[0-9A-F]+\.generating$`)
 	recvSnapshotDirNameRe = regexp.MustCompile(`^snapshot-[0-9A-F]+-[0-9A-F]+\.receiving$`)
+	strmSnapshotDirNameRe = regexp.MustCompile(`^snapshot-[0-9A-F]+-[0-9A-F]+\.streaming$`)
 )
 
 type snapshotter struct {
@@ -50,11 +51,14 @@ type snapshotter struct {
 	nodeID      uint64
 	logdb       raftio.ILogDB
 	stopc       chan struct{}
+	raftEvents  *raftEventListener
+	compression pb.CompressionType
 }
 
 func newSnapshotter(clusterID uint64,
 	nodeID uint64, rootDirFunc server.GetSnapshotDirFunc,
-	ldb raftio.ILogDB, stopc chan struct{}) *snapshotter {
+	ldb raftio.ILogDB, stopc chan struct{},
+	raftEvents *raftEventListener, ct pb.CompressionType) *snapshotter {
 	return &snapshotter{
 		rootDirFunc: rootDirFunc,
 		dir:         rootDirFunc(clusterID, nodeID),
@@ -62,18 +66,20 @@ func newSnapshotter(clusterID uint64,
 		clusterID:   clusterID,
 		nodeID:      nodeID,
 		stopc:       stopc,
+		raftEvents:  raftEvents,
+		compression: ct,
 	}
 }
 
 func (s *snapshotter) Save(savable rsm.IManagedStateMachine,
 	meta *rsm.SnapshotMeta) (*pb.Snapshot, *server.SnapshotEnv, error) {
-	env := s.getSnapshotEnv(meta.Index)
+	env := s.getCustomSnapshotEnv(meta.Index, meta.Request)
 	if err := env.CreateTempDir(); err != nil {
 		return nil, env, err
 	}
 	files := newFileCollection()
 	fp := env.GetTempFilepath()
-	writer, err := rsm.NewSnapshotWriter(fp)
+	writer, err := rsm.NewSnapshotWriter(fp, s.compression)
 	if err != nil {
 		return nil, env, err
 	}
@@ -83,7 +89,12 @@ func (s *snapshotter) Save(savable rsm.IManagedStateMachine,
 		}
 	}()
 	session := meta.Session.Bytes()
-	sz, err := savable.SaveSnapshot(meta.Ctx, writer, session, files)
+	var sz uint64
+	if meta.Dummy {
+		sz, err = rsm.SaveDummySnapshot(writer, session)
+	} else {
+		sz, err = savable.SaveSnapshot(meta.Ctx, writer, session, files)
+	}
 	if err != nil {
 		return nil, env, err
 	}
@@ -98,12 +109,65 @@ func (s *snapshotter) Save(savable rsm.IManagedStateMachine,
 		Index:      meta.Index,
 		Term:       meta.Term,
 		Files:      fs,
+		Dummy:      meta.Dummy,
+	}
+	return ss, env, nil
+}
+
+// Stream saves a full snapshot of the on disk state machine into a temporary
+// directory so it can be streamed to the specified remote node. The generated
+// snapshot is never committed into the LogDB.
+func (s *snapshotter) Stream(savable rsm.IManagedStateMachine,
+	meta *rsm.SnapshotMeta, to uint64) (*pb.Snapshot, *server.SnapshotEnv, error) {
+	env := server.NewSnapshotEnv(s.rootDirFunc,
+		s.clusterID, s.nodeID, meta.Index, to, server.StreamingMode)
+	if err := env.CreateTempDir(); err != nil {
+		return nil, env, err
+	}
+	fp := env.GetTempFilepath()
+	writer, err := rsm.NewSnapshotWriter(fp, s.compression)
+	if err != nil {
+		return nil, env, err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			panic(err)
+		}
+	}()
+	files := newFileCollection()
+	session := meta.Session.Bytes()
+	sz, err := savable.SaveSnapshot(meta.Ctx, writer, session, files)
+	if err != nil {
+		return nil, env, err
+	}
+	if files.Size() > 0 {
+		plog.Panicf("external files added by on disk state machine")
+	}
+	ss := &pb.Snapshot{
+		Filepath:   fp,
+		FileSize:   sz,
+		Membership: meta.Membership,
+		Index:      meta.Index,
+		Term:       meta.Term,
 	}
 	return ss, env, nil
 }
 
-func (s *snapshotter) Commit(snapshot pb.Snapshot) error {
-	env := s.getSnapshotEnv(snapshot.Index)
+// Commit makes the saved snapshot available. Snapshots exported to the user
+// specified directory are not recorded in the LogDB, the flag file is kept in
+// the exported snapshot directory to describe the snapshot.
+func (s *snapshotter) Commit(snapshot pb.Snapshot,
+	req rsm.SnapshotRequest) error {
+	if err := s.commit(snapshot, req); err != nil {
+		return err
+	}
+	s.raftEvents.SnapshotCreated(s.clusterID, s.nodeID, snapshot.Index)
+	return nil
+}
+
+func (s *snapshotter) commit(snapshot pb.Snapshot,
+	req rsm.SnapshotRequest) error {
+	env := s.getCustomSnapshotEnv(snapshot.Index, req)
 	if err := env.CreateFlagFile(&snapshot); err != nil {
 		return err
 	}
@@ -119,6 +183,9 @@ func (s *snapshotter) Commit(snapshot pb.Snapshot) error {
 		}
 		return err
 	}
+	if req.IsExportedSnapshot() {
+		return nil
+	}
 	if readyToReturnTestKnob(s.stopc, "saving to logdb") {
 		return sm.ErrSnapshotStopped
 	}
@@ -258,6 +325,21 @@ func (s *snapshotter) getSnapshotEnv(index uint64) *server.SnapshotEnv {
 		s.clusterID, s.nodeID, index, s.nodeID, server.SnapshottingMode)
 }
 
+func (s *snapshotter) getCustomSnapshotEnv(index uint64,
+	req rsm.SnapshotRequest) *server.SnapshotEnv {
+	if req.IsExportedSnapshot() {
+		if len(req.Path) == 0 {
+			plog.Panicf("empty export path")
+		}
+		getPath := func(clusterID uint64, nodeID uint64) string {
+			return req.Path
+		}
+		return server.NewSnapshotEnv(getPath,
+			s.clusterID, s.nodeID, index, s.nodeID, server.SnapshottingMode)
+	}
+	return s.getSnapshotEnv(index)
+}
+
 func (s *snapshotter) saveToLogDB(snapshot pb.Snapshot) error {
 	rec := pb.Update{
 		ClusterID: s.clusterID,
@@ -273,7 +355,8 @@ func (s *snapshotter) dirNameMatch(dir string) bool {
 
 func (s *snapshotter) isZombieDir(dir string) bool {
 	return genSnapshotDirNameRe.Match([]byte(dir)) ||
-		recvSnapshotDirNameRe.Match([]byte(dir))
+		recvSnapshotDirNameRe.Match([]byte(dir)) ||
+		strmSnapshotDirNameRe.Match([]byte(dir))
 }
 
 func (s *snapshotter) isOrphanDir(dir string) bool {
@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/lni/dragonboat/internal/logdb"
+	"github.com/lni/dragonboat/internal/rsm"
 	"github.com/lni/dragonboat/internal/utils/fileutil"
 	"github.com/lni/dragonboat/internal/utils/leaktest"
 	"github.com/lni/dragonboat/raftio"
@@ -58,7 +59,7 @@ func getTestSnapshotter(ldb raftio.ILogDB) *snapshotter {
 	f := func(cid uint64, nid uint64) string {
 		return fp
 	}
-	return newSnapshotter(1, 1, f, ldb, nil)
+	return newSnapshotter(1, 1, f, ldb, nil, nil, pb.NoCompression)
 }
 
 func runSnapshotterTest(t *testing.T,
@@ -89,7 +90,7 @@ func TestFinalizeSnapshotReturnExpectedErrorWhenOutOfDate(t *testing.T) {
 		if err := env.CreateTempDir(); err != nil {
 			t.Errorf("create tmp snapshot dir failed %v", err)
 		}
-		if err := s.Commit(ss); err != errSnapshotOutOfDate {
+		if err := s.Commit(ss, rsm.SnapshotRequest{}); err != errSnapshotOutOfDate {
 			t.Errorf("unexpected error result %v", err)
 		}
 	}
@@ -122,7 +123,7 @@ func TestSnapshotCanBeFinalized(t *testing.T) {
 		}
 		f.Write(make([]byte, 12))
 		f.Close()
-		if err = s.Commit(ss); err != nil {
+		if err = s.Commit(ss, rsm.SnapshotRequest{}); err != nil {
 			t.Errorf("finalize snapshot failed %v", err)
 		}
 		snapshots, err := ldb.ListSnapshots(1, 1)
@@ -168,6 +169,50 @@ func TestSnapshotCanBeFinalized(t *testing.T) {
 	runSnapshotterTest(t, fn)
 }
 
+func TestExportedSnapshotIsNotRecordedInLogDB(t *testing.T) {
+	fn := func(t *testing.T, ldb raftio.ILogDB, s *snapshotter) {
+		exportPath := filepath.Join(rdbTestDirectory, "export")
+		if err := os.MkdirAll(exportPath, 0755); err != nil {
+			t.Fatalf("failed to create export dir %v", err)
+		}
+		ss := pb.Snapshot{
+			FileSize: 1234,
+			Filepath: "f2",
+			Index:    100,
+			Term:     200,
+		}
+		req := rsm.SnapshotRequest{
+			Type: rsm.ExportedSnapshot,
+			Path: exportPath,
+		}
+		env := s.getCustomSnapshotEnv(ss.Index, req)
+		if err := env.CreateTempDir(); err != nil {
+			t.Errorf("create tmp snapshot dir failed %v", err)
+		}
+		if err := s.Commit(ss, req); err != nil {
+			t.Errorf("commit exported snapshot failed %v", err)
+		}
+		snapshots, err := ldb.ListSnapshots(1, 1)
+		if err != nil {
+			t.Errorf("failed to list snapshot")
+		}
+		if len(snapshots) != 0 {
+			t.Errorf("returned %d snapshot records, want 0", len(snapshots))
+		}
+		finalSnapDir := env.GetFinalDir()
+		if filepath.Dir(finalSnapDir) != exportPath {
+			t.Errorf("unexpected final dir %s", finalSnapDir)
+		}
+		if !fileutil.HasFlagFile(finalSnapDir, fileutil.SnapshotFlagFilename) {
+			t.Errorf("flag file not kept in the exported snapshot dir")
+		}
+		if _, err := os.Stat(s.getSnapshotEnv(ss.Index).GetFinalDir()); !os.IsNotExist(err) {
+			t.Errorf("exported snapshot saved into the snapshot dir, %v", err)
+		}
+	}
+	runSnapshotterTest(t, fn)
+}
+
 func TestSnapshotCanBeSavedToLogDB(t *testing.T) {
 	fn := func(t *testing.T, ldb raftio.ILogDB, s *snapshotter) {
 		s1 := pb.Snapshot{
@@ -345,7 +390,7 @@ func testRemoveUnusedSnapshotRemoveSnapshots(t *testing.T,
 			if err := env.CreateTempDir(); err != nil {
 				t.Errorf("failed to create snapshot dir")
 			}
-			if err := snapshotter.Commit(s); err != nil {
+			if err := snapshotter.Commit(s, rsm.SnapshotRequest{}); err != nil {
 				t.Errorf("failed to save snapshot record")
 			}
 			fp := snapshotter.GetFilePath(s.Index)
@@ -538,6 +583,9 @@ func TestZombieSnapshotDirNameMatchWorks(t *testing.T) {
 			{"snapshot-XX.generating", false},
 			{"snapshot-AB.generatingd", false},
 			{"dsnapshot-AB.generating", false},
+			{"snapshot-AB-01.streaming", true},
+			{"snapshot-AB.streaming", false},
+			{"snapshot-AB-01.streamingd", false},
 		}
 		for idx, tt := range tests {
 			v := s.isZombieDir(tt.dirName)
@@ -0,0 +1,91 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dragonboat
+
+import (
+	"sync"
+	"time"
+)
+
+// staleReadTracker tracks how far the local state machine is behind the
+// leader. The leader's commit index and the time when it was heard are
+// sampled by the step worker, a sampled commit index is considered as fully
+// applied once the last applied index of the local state machine reaches it,
+// the local state machine is then known to contain all entries committed
+// before the time when that commit index was heard.
+type staleReadTracker struct {
+	mu struct {
+		sync.Mutex
+		commit    uint64
+		heardAt   time.Time
+		pending   uint64
+		pendingAt time.Time
+		freshAt   time.Time
+	}
+}
+
+func newStaleReadTracker() *staleReadTracker {
+	return &staleReadTracker{}
+}
+
+func (t *staleReadTracker) update(commit uint64,
+	heardAt time.Time, applied uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if heardAt.After(t.mu.heardAt) {
+		// the commit index known to the leader never moves backwards
+		if commit > t.mu.commit {
+			t.mu.commit = commit
+		}
+		t.mu.heardAt = heardAt
+		if t.mu.pendingAt.IsZero() {
+			t.mu.pending = t.mu.commit
+			t.mu.pendingAt = heardAt
+		}
+	}
+	for !t.mu.pendingAt.IsZero() && applied >= t.mu.pending {
+		t.mu.freshAt = t.mu.pendingAt
+		if t.mu.heardAt.After(t.mu.pendingAt) {
+			t.mu.pending = t.mu.commit
+			t.mu.pendingAt = t.mu.heardAt
+		} else {
+			t.mu.pendingAt = time.Time{}
+		}
+	}
+}
+
+// lagged returns a boolean value indicating whether the specified applied
+// index is more than maxLag entries behind the last commit index heard from
+// the leader.
+func (t *staleReadTracker) lagged(applied uint64, maxLag uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.heardAt.IsZero() {
+		return true
+	}
+	return t.mu.commit > applied && t.mu.commit-applied > maxLag
+}
+
+// delayed returns a boolean value indicating whether the local state machine
+// might be missing entries committed more than maxDelay ago.
+func (t *staleReadTracker) delayed(maxDelay time.Duration,
+	now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.freshAt.IsZero() {
+		return true
+	}
+	return now.Sub(t.mu.freshAt) > maxDelay
+}
@@ -0,0 +1,101 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dragonboat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleReadTrackerWithoutLeaderIsStale(t *testing.T) {
+	tracker := newStaleReadTracker()
+	tracker.update(0, time.Time{}, 0)
+	if !tracker.lagged(0, 100) {
+		t.Errorf("not lagged")
+	}
+	if !tracker.delayed(time.Hour, time.Now()) {
+		t.Errorf("not delayed")
+	}
+}
+
+func TestStaleReadTrackerChecksLag(t *testing.T) {
+	tracker := newStaleReadTracker()
+	tracker.update(100, time.Now(), 0)
+	tests := []struct {
+		applied uint64
+		maxLag  uint64
+		lagged  bool
+	}{
+		{0, 0, true},
+		{0, 99, true},
+		{0, 100, false},
+		{90, 10, false},
+		{90, 9, true},
+		{100, 0, false},
+		{110, 0, false},
+	}
+	for idx, tt := range tests {
+		if v := tracker.lagged(tt.applied, tt.maxLag); v != tt.lagged {
+			t.Errorf("%d, lagged %t, want %t", idx, v, tt.lagged)
+		}
+	}
+}
+
+func TestStaleReadTrackerChecksDelay(t *testing.T) {
+	now := time.Now()
+	tracker := newStaleReadTracker()
+	tracker.update(100, now, 50)
+	if !tracker.delayed(time.Hour, now) {
+		t.Errorf("not delayed before commit index is applied")
+	}
+	tracker.update(120, now.Add(time.Second), 100)
+	if tracker.delayed(time.Second, now.Add(time.Second)) {
+		t.Errorf("unexpectedly delayed")
+	}
+	if !tracker.delayed(time.Second, now.Add(3*time.Second)) {
+		t.Errorf("not delayed")
+	}
+	// 120 was heard at now + 1s and is pending
+	tracker.update(150, now.Add(2*time.Second), 130)
+	if !tracker.delayed(time.Second, now.Add(3*time.Second)) {
+		t.Errorf("not delayed")
+	}
+	if tracker.delayed(2*time.Second, now.Add(3*time.Second)) {
+		t.Errorf("unexpectedly delayed")
+	}
+	// no more heartbeat received from the leader
+	tracker.update(150, now.Add(2*time.Second), 150)
+	if tracker.delayed(time.Second, now.Add(3*time.Second)) {
+		t.Errorf("unexpectedly delayed")
+	}
+	if !tracker.delayed(time.Second, now.Add(4*time.Second)) {
+		t.Errorf("not delayed")
+	}
+}
+
+func TestStaleReadTrackerCommitIsNotMovedBackwards(t *testing.T) {
+	now := time.Now()
+	tracker := newStaleReadTracker()
+	tracker.update(1000, now, 10)
+	// a smaller commit index, e.g. a heartbeat commit capped by the match value
+	// of a lagging node, should never hide how far the node is behind
+	tracker.update(20, now.Add(time.Second), 10)
+	if !tracker.lagged(10, 100) {
+		t.Errorf("not lagged")
+	}
+	if !tracker.delayed(time.Hour, now.Add(time.Second)) {
+		t.Errorf("not delayed")
+	}
+}
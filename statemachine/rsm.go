@@ -13,9 +13,9 @@
 // limitations under the License.
 
 /*
-Package statemachine contains the definitions of the IStateMachine and
-IConcurrentStateMachine interfaces required to be implemented by dragonboat
-based applications.
+Package statemachine contains the definitions of the IStateMachine,
+IConcurrentStateMachine and IOnDiskStateMachine interfaces required to be
+implemented by dragonboat based applications.
 
 Dragonboat users should determine whether the application state machine should
 implement the IStateMachine or IConcurrentStateMachine interface based on
@@ -34,6 +34,13 @@ SaveSnapshot() is in progress. It is up to the user implementation of such
 IConcurrentStateMachine type to correctly and safely maintain its internal data
 structures during such concurrent accesses.
 
+IOnDiskStateMachine is for state machines that keep their state on disk, e.g.
+those backed by an embedded key-value store. The state managed by such state
+machines is usually much larger than the available memory, it is thus not
+rebuilt from saved snapshots and Raft Logs each time when restarted. Instead,
+IOnDiskStateMachine reports the index of the last applied entry when it is
+opened, only Raft Log entries after that index will be applied again.
+
 When you are not sure which one to choose, the rule of thumb is to implement
 IStateMachine for your application and upgrade it to a IConcurrentStateMachine
 when necessary.
@@ -51,6 +58,10 @@ var (
 	// related operations have been aborted as the associated raft node is being
 	// closed.
 	ErrSnapshotStopped = errors.New("snapshot stopped")
+	// ErrOpenStopped is returned by the Open method of the IOnDiskStateMachine
+	// interface to indicate that the Open method has been aborted as the
+	// associated raft node is being closed.
+	ErrOpenStopped = errors.New("open stopped")
 )
 
 // SnapshotFile is the struct used to describe external files included in a
@@ -131,9 +142,10 @@ type IStateMachine interface {
 	// The IStateMachine implementation should not keep a reference to the input
 	// byte slice after the return of the Update() method.
 	//
-	// Update returns an uint64 value used to indicate the result of the update
-	// operation.
-	Update([]byte) uint64
+	// Update returns a Result value used to indicate the result of the update
+	// operation, the returned Result is passed back to the client that proposed
+	// the input command.
+	Update([]byte) Result
 	// Lookup queries the state of the IStateMachine instance and returns the
 	// query result as a byte slice. The input byte slice specifies what to query,
 	// it is up to the IStateMachine implementation to interpret the input byte
@@ -208,6 +220,20 @@ type IStateMachine interface {
 	GetHash() uint64
 }
 
+// Result is the result type generated and returned by the Update method in
+// IStateMachine, IConcurrentStateMachine and IOnDiskStateMachine instances.
+type Result struct {
+	// Value is a 64 bits integer value used to indicate the outcome of the
+	// update operation.
+	Value uint64
+	// Data is an optional byte slice created and returned by the update
+	// operation. It is useful for CompareAndSwap style updates in which an
+	// arbitrary length of bytes need to be returned. The Update method should
+	// not reuse the returned byte slice as the Data value is cached in client
+	// sessions and passed back to the client.
+	Data []byte
+}
+
 // Entry represents a Raft log entry that is going to be provided to the Update
 // method of an IConcurrentStateMachine instance.
 type Entry struct {
@@ -218,7 +244,7 @@ type Entry struct {
 	// Result is the result value obtained from the Update method of an
 	// IConcurrentStateMachine instance. This field is set by user
 	// IConcurrentStateMachine instances.
-	Result uint64
+	Result Result
 	// Cmd is the proposed command. This field is read-only for user
 	// IConcurrentStateMachine instances.
 	Cmd []byte
@@ -375,3 +401,141 @@ type IConcurrentStateMachine interface {
 	// GetHash is a read only method on the IConcurrentStateMachine instance.
 	GetHash() uint64
 }
+
+// IOnDiskStateMachine is the interface to be implemented by application's
+// state machine when the state machine state is always persisted on disks.
+// IOnDiskStateMachine basically matches the state machine type described
+// in the section 5.2 of the Raft thesis.
+//
+// For IOnDiskStateMachine types, concurrent access to the state machine is
+// supported. An IOnDiskStateMachine type allows its Update() method to be
+// concurrently invoked when there are ongoing calls to the Lookup() or the
+// SaveSnapshot() method. Lookup() is also allowed when the RecoverFromSnapshot
+// method is being invoked.
+//
+// IOnDiskStateMachine doesn't rely on saved snapshots and Raft Logs to restore
+// its state after restarts. Its Open() method returns the index of the last
+// Raft Log entry that has been durably applied into the state machine, only
+// entries after that index will be applied again. The SaveSnapshot() method is
+// only invoked when a snapshot need to be streamed to a remote Raft node that
+// is significantly behind its leader. Such snapshot data is never stored on
+// the local node.
+type IOnDiskStateMachine interface {
+	// Open opens the existing on disk state machine to be used or it creates a
+	// new state machine with empty state if it does not exist. Open returns the
+	// most recent index value of the Raft log that has been persisted, or it
+	// returns 0 when the state machine is a new one.
+	//
+	// The provided read only chan struct{} channel is used to notify the Open
+	// method that the node has been stopped and the Open method can choose to
+	// abort by returning an ErrOpenStopped error.
+	//
+	// Open is called shortly after the Raft node is started. The Update method
+	// and the Lookup method or any other methods of the IOnDiskStateMachine will
+	// not be invoked before the completion of the Open method.
+	Open(<-chan struct{}) (uint64, error)
+	// Update updates the IOnDiskStateMachine instance. The input Entry slice
+	// is a list of continuous proposed and committed commands from clients, they
+	// are provided together as a batch so the IOnDiskStateMachine implementation
+	// can choose to batch them and apply together to hide latency. Update returns
+	// the input entry slice with the Result field of all its members set.
+	//
+	// The Index field of each input Entry instance is the Raft log index of each
+	// entry, it is IOnDiskStateMachine's responsibility to atomically persist the
+	// Index value together with the corresponding state update.
+	//
+	// The Update method can choose to synchronize all of its in-core state with
+	// that on disk. This can minimize the number of committed Raft entries that
+	// need to be re-applied after each restart. Dragonboat does not require such
+	// synchronization after each Update, see the Sync method for details.
+	//
+	// The Update() method must be deterministic, meaning given the same initial
+	// state of IOnDiskStateMachine and the same input sequence, it should reach
+	// to the same updated state and outputs the same returned value. The input
+	// entry slice should be the only input to this method.
+	Update([]Entry) []Entry
+	// Lookup queries the state of the IOnDiskStateMachine instance and returns
+	// the query result as a byte slice. The input byte slice specifies what to
+	// query, it is up to the IOnDiskStateMachine implementation to interpret the
+	// input byte slice.
+	//
+	// When an error is returned by the Lookup() method, the error will be passed
+	// to the caller of NodeHost's ReadLocalNode() or SyncRead() methods to be
+	// handled.
+	//
+	// The Lookup() method is a read only method, it should never change the state
+	// of IOnDiskStateMachine.
+	Lookup([]byte) ([]byte, error)
+	// Sync synchronizes all in-core state of the state machine to permanent
+	// storage so the state machine can continue from its latest state after
+	// reboot.
+	//
+	// Sync is always invoked with mutual exclusion protection from the Update,
+	// PrepareSnapshot and RecoverFromSnapshot methods. Dragonboat invokes Sync
+	// before it compacts the Raft Log, entries covered by the compaction will
+	// no longer be available for replay after restarts.
+	//
+	// Sync returns an error when there is unrecoverable error for synchronizing
+	// the in-core state.
+	Sync() error
+	// PrepareSnapshot prepares the snapshot to be concurrently captured and
+	// streamed. PrepareSnapshot is invoked before SaveSnapshot is called and it
+	// is always invoked with mutual exclusion protection from the Update, Sync
+	// and RecoverFromSnapshot methods.
+	//
+	// PrepareSnapshot in general saves a state identifier of the current state,
+	// such as a snapshot of the underlying key-value store. The state identifier
+	// is returned as an interface{} and it is provided to the SaveSnapshot()
+	// method so a snapshot of the state machine state in that identified point
+	// in time can be saved.
+	PrepareSnapshot() (interface{}, error)
+	// SaveSnapshot saves the point in time state of the IOnDiskStateMachine
+	// identified by the input state identifier to the provided io.Writer. The
+	// written data is streamed to a remote Raft node significantly behind its
+	// leader, it is never used for restoring the local state. SaveSnapshot is a
+	// read only method that should never change the state of the
+	// IOnDiskStateMachine instance.
+	//
+	// It is SaveSnapshot's responsibility to free the resources owned by the
+	// input state identifier when it is done.
+	//
+	// The provided read-only chan struct{} is provided to notify the
+	// SaveSnapshot method that the associated Raft node is being closed so the
+	// implementation can choose to abort the SaveSnapshot procedure and return
+	// ErrSnapshotStopped immediately.
+	//
+	// SaveSnapshot returns the number of bytes written to the provided io.Writer
+	// and the encountered error when generating the snapshot.
+	SaveSnapshot(interface{}, io.Writer, <-chan struct{}) (uint64, error)
+	// RecoverFromSnapshot recovers the state of the IOnDiskStateMachine instance
+	// from a snapshot captured by the SaveSnapshot() method on a remote node.
+	// The saved snapshot is provided as an io.Reader. RecoverFromSnapshot is
+	// expected to replace all existing state with the state recovered from the
+	// snapshot, that recovered state must be durably persisted before the
+	// method returns.
+	//
+	// Dragonboat ensures that Update() and Close() will not be invoked when
+	// RecoverFromSnapshot() is in progress.
+	//
+	// The provided read-only chan struct{} is provided to notify the
+	// RecoverFromSnapshot() method that the associated Raft node is being closed.
+	// On receiving such notification, RecoverFromSnapshot() can choose to
+	// abort recovering from the snapshot and return an ErrSnapshotStopped error
+	// immediately.
+	RecoverFromSnapshot(io.Reader, <-chan struct{}) error
+	// Close closes the IOnDiskStateMachine instance.
+	//
+	// Close() allows the application to finalize resources to a state easier to
+	// be re-opened and used in the future. It is important to understand that
+	// Close() is not guaranteed to be always called, e.g. node might crash at
+	// any time. IOnDiskStateMachine should be designed in a way that the safety
+	// and integrity of its on disk data doesn't rely on the Close() method.
+	Close()
+	// GetHash returns a uint64 value used to represent the current state of the
+	// IOnDiskStateMachine.
+	//
+	// The feature backed by this method is optional, application can ignore this
+	// testing related capability by always returning a constant uint64 value from
+	// this method.
+	GetHash() uint64
+}
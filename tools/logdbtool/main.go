@@ -0,0 +1,255 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Logdbtool is an offline tool for maintaining LogDB data owned by a stopped
+NodeHost. The migrate operation copies all LogDB data found in the source dirs
+to the target dirs, the target can use a different type of LogDB, e.g.
+
+	logdbtool -op migrate -src-type rocksdb -src-dir /data/nh/host/dep \
+		-dst-type pebble -dst-dir /data/nh-pebble/host/dep
+
+The repartition operation copies all LogDB data to the target dirs using the
+same type of LogDB with a different shard count, e.g.
+
+	logdbtool -op repartition -src-type rocksdb -src-dir /data/nh/host/dep \
+		-dst-shards 4 -dst-dir /data/nh-4/host/dep
+
+LevelDB and Pebble are always compiled into the binary, RocksDB is available
+unless the tool is built with the dragonboat_no_rocksdb tag.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lni/dragonboat/internal/logdb"
+	"github.com/lni/dragonboat/internal/server"
+	"github.com/lni/dragonboat/internal/utils/fileutil"
+	"github.com/lni/dragonboat/logger"
+	"github.com/lni/dragonboat/raftio"
+)
+
+const (
+	walLogDBType = "wal"
+	// the flag file name used by NodeHost to record the data dir status
+	nodeHostDirStatusFilename = "dragonboat.address"
+)
+
+var (
+	plog = logger.GetLogger("logdbtool")
+)
+
+func getDirs(dirs string) []string {
+	if len(dirs) == 0 {
+		return []string{}
+	}
+	return strings.Split(dirs, ":")
+}
+
+func getLogDBTypes() []string {
+	return append(logdb.GetKVStoreTypes(), walLogDBType)
+}
+
+func checkLogDBDirs(dirs []string, lldirs []string) error {
+	if len(dirs) == 0 {
+		return errors.New("LogDB dir not specified")
+	}
+	if len(lldirs) > 0 && len(lldirs) != len(dirs) {
+		return errors.New("dir and wal dir count mismatch")
+	}
+	return nil
+}
+
+// openSourceLogDB opens the source LogDB in read only mode, the source LogDB
+// and its metadata are never modified.
+func openSourceLogDB(dbType string,
+	dirs []string, lldirs []string) (raftio.ILogDB, error) {
+	if err := checkLogDBDirs(dirs, lldirs); err != nil {
+		return nil, err
+	}
+	for _, dir := range append(dirs, lldirs...) {
+		if !fileutil.Exist(dir) {
+			return nil, fmt.Errorf("dir %s does not exist", dir)
+		}
+	}
+	if dbType == walLogDBType {
+		return logdb.OpenWALLogDBForReadOnly(dirs, lldirs)
+	}
+	if len(dbType) == 0 {
+		return nil, errors.New("LogDB type not specified")
+	}
+	return logdb.OpenLogDBForReadOnly(dirs, lldirs, dbType)
+}
+
+func openTargetLogDB(dbType string, dirs []string,
+	lldirs []string, shards uint64) (raftio.ILogDB, error) {
+	if err := checkLogDBDirs(dirs, lldirs); err != nil {
+		return nil, err
+	}
+	for _, dir := range append(dirs, lldirs...) {
+		if err := fileutil.MkdirAll(dir); err != nil {
+			return nil, err
+		}
+	}
+	if dbType == walLogDBType {
+		if shards > 0 {
+			return nil, errors.New("shard count is not supported by wal LogDB")
+		}
+		return logdb.OpenWALLogDB(dirs, lldirs)
+	}
+	if len(dbType) == 0 {
+		return nil, errors.New("LogDB type not specified")
+	}
+	return logdb.OpenLogDBWithKVStore(dirs, lldirs, dbType, shards)
+}
+
+// getRecordedLogDBType returns the LogDB type recorded by NodeHost for the
+// specified logdbtool LogDB type.
+func getRecordedLogDBType(dbType string) string {
+	if dbType == walLogDBType {
+		return logdb.WALLogDBType
+	}
+	return dbType
+}
+
+func copyNodeHostDirStatus(srcDir string,
+	dstDirs []string, dstType string) error {
+	if !fileutil.Exist(filepath.Join(srcDir, nodeHostDirStatusFilename)) {
+		return nil
+	}
+	for _, dir := range dstDirs {
+		if err := server.CopyNodeHostDirStatus(srcDir,
+			dir, getRecordedLogDBType(dstType)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSourceLogDB(dbType string, dirs []string, lldirs []string) error {
+	if len(dirs) == 0 {
+		return errors.New("LogDB dir not specified")
+	}
+	recorded, err := server.GetNodeHostDirLogDBType(dirs[0])
+	if err != nil {
+		return err
+	}
+	if len(recorded) > 0 && recorded != getRecordedLogDBType(dbType) {
+		return fmt.Errorf("%s LogDB recorded in %s, %s requested",
+			recorded, dirs[0], dbType)
+	}
+	if dbType == walLogDBType {
+		dir := dirs[0]
+		if len(lldirs) > 0 {
+			dir = lldirs[0]
+		}
+		if !logdb.HasWALLogDB(dir) {
+			return fmt.Errorf("no wal LogDB found in %s", dir)
+		}
+	} else if !logdb.HasLogDB(dirs[0]) {
+		return fmt.Errorf("no %s LogDB found in %s", dbType, dirs[0])
+	}
+	return nil
+}
+
+func migrate(srcType string, srcDirs []string, srcLLDirs []string,
+	dstType string, dstDirs []string, dstLLDirs []string,
+	dstShards uint64) error {
+	for _, dir := range append(dstDirs, dstLLDirs...) {
+		if fileutil.Exist(filepath.Join(dir, nodeHostDirStatusFilename)) {
+			return fmt.Errorf("target dir %s is used by a NodeHost", dir)
+		}
+	}
+	if len(srcLLDirs) > 0 && len(dstLLDirs) == 0 {
+		return errors.New("target wal dir not specified")
+	}
+	if err := checkSourceLogDB(srcType, srcDirs, srcLLDirs); err != nil {
+		return err
+	}
+	src, err := openSourceLogDB(srcType, srcDirs, srcLLDirs)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := openTargetLogDB(dstType, dstDirs, dstLLDirs, dstShards)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	nodes, err := dst.ListNodeInfo()
+	if err != nil {
+		return err
+	}
+	if len(nodes) > 0 {
+		return errors.New("target LogDB is not empty")
+	}
+	result, err := logdb.MigrateLogDB(src, dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("migrated %d nodes, %d entries and %d snapshot records\n",
+		result.Nodes, result.Entries, result.Snapshots)
+	if err := copyNodeHostDirStatus(srcDirs[0], dstDirs, dstType); err != nil {
+		return err
+	}
+	if len(srcLLDirs) > 0 {
+		return copyNodeHostDirStatus(srcLLDirs[0], dstLLDirs, dstType)
+	}
+	return copyNodeHostDirStatus(srcDirs[0], dstLLDirs, dstType)
+}
+
+func main() {
+	types := strings.Join(getLogDBTypes(), ", ")
+	op := flag.String("op", "migrate", "migrate and repartition are supported")
+	srcType := flag.String("src-type", "",
+		fmt.Sprintf("type of the source LogDB, supported types: %s", types))
+	srcDir := flag.String("src-dir", "",
+		"colon separated LogDB dirs of the source LogDB")
+	srcWALDir := flag.String("src-wal-dir", "",
+		"colon separated low latency LogDB dirs of the source LogDB")
+	dstType := flag.String("dst-type", "",
+		fmt.Sprintf("type of the target LogDB, supported types: %s", types))
+	dstDir := flag.String("dst-dir", "",
+		"colon separated LogDB dirs of the target LogDB")
+	dstWALDir := flag.String("dst-wal-dir", "",
+		"colon separated low latency LogDB dirs of the target LogDB")
+	dstShards := flag.Uint64("dst-shards", 0,
+		"shard count of the target LogDB, 0 means the default shard count")
+	flag.Parse()
+	if *op == "repartition" {
+		if *dstShards == 0 {
+			plog.Errorf("target shard count not specified")
+			os.Exit(1)
+		}
+		if len(*dstType) > 0 && *dstType != *srcType {
+			plog.Errorf("repartition op can not change the LogDB type")
+			os.Exit(1)
+		}
+		*dstType = *srcType
+	} else if *op != "migrate" {
+		plog.Errorf("unknown op %s", *op)
+		os.Exit(1)
+	}
+	if err := migrate(*srcType, getDirs(*srcDir), getDirs(*srcWALDir),
+		*dstType, getDirs(*dstDir), getDirs(*dstWALDir), *dstShards); err != nil {
+		plog.Errorf("%s failed, %v", *op, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dragonboat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/lni/dragonboat/raftpb"
+	"github.com/lni/dragonboat/tracing"
+)
+
+const (
+	syncProposeSpan    = "dragonboat.SyncPropose"
+	syncReadSpan       = "dragonboat.SyncRead"
+	entryQueueSpan     = "dragonboat.entry_queue"
+	leaderAppendSpan   = "dragonboat.leader_append"
+	logDBSaveSpan      = "dragonboat.logdb_save"
+	replicateSpan      = "dragonboat.replicate"
+	commitSpan         = "dragonboat.commit"
+	smUpdateSpan       = "dragonboat.statemachine_update"
+	readIndexQueueSpan = "dragonboat.read_index_queue"
+	readIndexSpan      = "dragonboat.read_index"
+	smLookupSpan       = "dragonboat.statemachine_lookup"
+)
+
+// requestTrace is the tracing state of a single traced request. Stages of the
+// request are reported as child spans of the request span once they are
+// completed, stages completed after the request span is finished are not
+// reported. All methods can be invoked on a nil requestTrace, nil is used
+// when the request is not traced.
+type requestTrace struct {
+	tracer   tracing.ITracer
+	span     tracing.ISpan
+	expire   time.Time
+	queued   time.Time
+	dequeued time.Time
+	appended time.Time
+	index    uint64
+	acked    map[uint64]struct{}
+	mu       struct {
+		sync.Mutex
+		finished bool
+	}
+}
+
+func newRequestTrace(ctx context.Context, tracer tracing.ITracer,
+	name string, clusterID uint64) *requestTrace {
+	if tracer == nil {
+		return nil
+	}
+	now := time.Now()
+	span := tracer.StartSpan(name,
+		tracing.ChildOf(tracing.SpanFromContext(ctx)), tracing.StartTime(now))
+	span.SetTag("cluster_id", clusterID)
+	expire, ok := ctx.Deadline()
+	if !ok {
+		expire = now
+	}
+	return &requestTrace{
+		tracer: tracer,
+		span:   span,
+		expire: expire,
+		queued: now,
+	}
+}
+
+// stage reports a completed stage of the request that started at the
+// specified time.
+func (t *requestTrace) stage(name string, start time.Time) {
+	t.stageWithTag(name, start, "", nil)
+}
+
+// stageWithTag reports a completed stage with the specified tag set, the tag
+// is omitted when key is empty.
+func (t *requestTrace) stageWithTag(name string,
+	start time.Time, key string, value interface{}) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.finished {
+		return
+	}
+	if start.IsZero() {
+		start = t.queued
+	}
+	span := t.tracer.StartSpan(name,
+		tracing.ChildOf(t.span), tracing.StartTime(start))
+	if len(key) > 0 {
+		span.SetTag(key, value)
+	}
+	span.Finish()
+}
+
+func (t *requestTrace) finished() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mu.finished
+}
+
+func (t *requestTrace) readIndexConfirmed() {
+	if t == nil {
+		return
+	}
+	t.stage(readIndexSpan, t.dequeued)
+}
+
+func (t *requestTrace) finish(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.finished {
+		return
+	}
+	t.mu.finished = true
+	if err != nil {
+		t.span.SetTag("error", err.Error())
+	}
+	t.span.Finish()
+}
+
+// proposalTracer tracks traced proposals after they are taken from the entry
+// queue. tracked is only accessed by the step worker, the apply worker picks
+// up committed proposals from the applying map. All methods can be invoked on
+// a nil proposalTracer, nil is used when tracing is not enabled.
+type proposalTracer struct {
+	tracked    map[uint64]*requestTrace
+	applyStart time.Time
+	mu         struct {
+		sync.Mutex
+		applying map[uint64]*requestTrace
+	}
+}
+
+func newProposalTracer(tracer tracing.ITracer) *proposalTracer {
+	if tracer == nil {
+		return nil
+	}
+	pt := &proposalTracer{tracked: make(map[uint64]*requestTrace)}
+	pt.mu.applying = make(map[uint64]*requestTrace)
+	return pt
+}
+
+// dequeued is invoked by the step worker after entries are taken from the
+// entry queue.
+func (pt *proposalTracer) dequeued(entries []pb.Entry,
+	getTrace func(key uint64) *requestTrace) {
+	if pt == nil {
+		return
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if t := getTrace(e.Key); t != nil && !t.finished() {
+			t.stage(entryQueueSpan, t.queued)
+			t.dequeued = now
+			pt.tracked[e.Key] = t
+		}
+	}
+}
+
+// appended is invoked by the step worker when entries appended to the log
+// are about to be saved.
+func (pt *proposalTracer) appended(entries []pb.Entry) {
+	if pt == nil || len(pt.tracked) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if t, ok := pt.tracked[e.Key]; ok && t.index == 0 {
+			t.stage(leaderAppendSpan, t.dequeued)
+			t.appended = now
+			t.index = e.Index
+		}
+	}
+}
+
+// saved is invoked by the step worker after entries are saved into the
+// LogDB.
+func (pt *proposalTracer) saved(entries []pb.Entry) {
+	if pt == nil || len(pt.tracked) == 0 {
+		return
+	}
+	for _, e := range entries {
+		if t, ok := pt.tracked[e.Key]; ok && t.index == e.Index {
+			t.stage(logDBSaveSpan, t.appended)
+		}
+	}
+}
+
+// replicated is invoked by the step worker on receiving replicate responses
+// from followers.
+func (pt *proposalTracer) replicated(m pb.Message) {
+	if pt == nil || len(pt.tracked) == 0 || m.Reject {
+		return
+	}
+	for _, t := range pt.tracked {
+		if t.index == 0 || t.index > m.LogIndex {
+			continue
+		}
+		if _, ok := t.acked[m.From]; ok {
+			continue
+		}
+		if t.acked == nil {
+			t.acked = make(map[uint64]struct{})
+		}
+		t.acked[m.From] = struct{}{}
+		t.stageWithTag(replicateSpan, t.appended, "follower", m.From)
+	}
+}
+
+// committed is invoked by the step worker when committed entries are
+// published to the apply worker.
+func (pt *proposalTracer) committed(entries []pb.Entry) {
+	if pt == nil || len(pt.tracked) == 0 {
+		return
+	}
+	for _, e := range entries {
+		if t, ok := pt.tracked[e.Key]; ok {
+			t.stage(commitSpan, t.appended)
+			delete(pt.tracked, e.Key)
+			pt.mu.Lock()
+			pt.mu.applying[e.Key] = t
+			pt.mu.Unlock()
+		}
+	}
+}
+
+// applyStarted is invoked by the apply worker before applying a batch of
+// committed entries.
+func (pt *proposalTracer) applyStarted() {
+	if pt != nil {
+		pt.applyStart = time.Now()
+	}
+}
+
+// applied is invoked by the apply worker after the entry identified by the
+// specified key has been applied. entries are applied one by one, the time
+// since the previous entry is reported as the time spent on updating the
+// state machine.
+func (pt *proposalTracer) applied(key uint64) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	t, ok := pt.mu.applying[key]
+	if ok {
+		delete(pt.mu.applying, key)
+	}
+	pt.mu.Unlock()
+	if ok {
+		t.stage(smUpdateSpan, pt.applyStart)
+	}
+	pt.applyStart = time.Now()
+}
+
+// gc removes traces of proposals that are no longer expected to complete,
+// e.g. those dropped during leadership changes, and traces of proposals that
+// have already been completed, e.g. by timing out. It is invoked by the step
+// worker.
+func (pt *proposalTracer) gc(now time.Time) {
+	if pt == nil {
+		return
+	}
+	for key, t := range pt.tracked {
+		if now.After(t.expire) || t.finished() {
+			delete(pt.tracked, key)
+		}
+	}
+	pt.mu.Lock()
+	for key, t := range pt.mu.applying {
+		if now.After(t.expire) || t.finished() {
+			delete(pt.mu.applying, key)
+		}
+	}
+	pt.mu.Unlock()
+}
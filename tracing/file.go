@@ -0,0 +1,149 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// SpanRecord is the record written by FileTracer for each finished span.
+type SpanRecord struct {
+	TraceID   uint64                 `json:"trace_id"`
+	SpanID    uint64                 `json:"span_id"`
+	ParentID  uint64                 `json:"parent_id,omitempty"`
+	Name      string                 `json:"name"`
+	StartTime time.Time              `json:"start_time"`
+	Duration  time.Duration          `json:"duration_ns"`
+	Tags      map[string]interface{} `json:"tags,omitempty"`
+}
+
+// FileTracer is an ITracer that writes finished spans to a local file, each
+// span is written as a JSON encoded SpanRecord on its own line.
+type FileTracer struct {
+	mu     sync.Mutex
+	rand   *rand.Rand
+	file   *os.File
+	writer *bufio.Writer
+	enc    *json.Encoder
+}
+
+var _ ITracer = (*FileTracer)(nil)
+
+// NewFileTracer creates a FileTracer that appends spans to the file
+// specified by path.
+func NewFileTracer(path string) (*FileTracer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t := newFileTracer(f)
+	t.file = f
+	return t, nil
+}
+
+func newFileTracer(w io.Writer) *FileTracer {
+	bw := bufio.NewWriter(w)
+	return &FileTracer{
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		writer: bw,
+		enc:    json.NewEncoder(bw),
+	}
+}
+
+// StartSpan starts a new span. The new span belongs to the trace of its
+// parent when the parent span is created by the same FileTracer, otherwise
+// it is the root span of a new trace.
+func (t *FileTracer) StartSpan(name string, opts ...SpanOption) ISpan {
+	o := GetSpanOptions(opts...)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &fileSpan{
+		tracer: t,
+		record: SpanRecord{
+			SpanID:    t.nextID(),
+			Name:      name,
+			StartTime: o.StartTime,
+		},
+	}
+	if p, ok := o.Parent.(*fileSpan); ok && p.tracer == t {
+		s.record.TraceID = p.record.TraceID
+		s.record.ParentID = p.record.SpanID
+	} else {
+		s.record.TraceID = t.nextID()
+	}
+	return s
+}
+
+// Flush writes all buffered span records to the underlying file.
+func (t *FileTracer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.writer.Flush()
+}
+
+// Close flushes buffered span records and closes the underlying file.
+func (t *FileTracer) Close() error {
+	if err := t.Flush(); err != nil {
+		return err
+	}
+	if t.file != nil {
+		return t.file.Close()
+	}
+	return nil
+}
+
+func (t *FileTracer) nextID() uint64 {
+	for {
+		if v := t.rand.Uint64(); v != 0 {
+			return v
+		}
+	}
+}
+
+func (t *FileTracer) write(r SpanRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// errors are ignored as tracing is not allowed to fail requests
+	_ = t.enc.Encode(r)
+}
+
+type fileSpan struct {
+	mu     sync.Mutex
+	tracer *FileTracer
+	record SpanRecord
+}
+
+func (s *fileSpan) SetTag(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.record.Tags == nil {
+		s.record.Tags = make(map[string]interface{})
+	}
+	s.record.Tags[key] = value
+}
+
+func (s *fileSpan) Finish() {
+	s.mu.Lock()
+	s.record.Duration = time.Since(s.record.StartTime)
+	r := s.record
+	s.mu.Unlock()
+	s.tracer.write(r)
+}
@@ -0,0 +1,122 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package tracing contains the interfaces required to trace individual requests
+handled by NodeHost.
+
+The ITracer and ISpan interfaces are modelled after OpenTracing, adapters for
+OpenTracing or OpenTelemetry compatible tracers can be built by wrapping their
+Tracer and Span types. A span can be attached to the context.Context instance
+passed to NodeHost's SyncPropose and SyncRead methods using the
+ContextWithSpan function, spans created by NodeHost for the request will then
+be children of the attached span.
+
+Tracing is disabled when no ITracer is specified in NodeHostConfig.
+*/
+package tracing
+
+import (
+	"context"
+	"time"
+)
+
+// ISpan is the interface of a span, a span represents a named and timed
+// operation.
+type ISpan interface {
+	// SetTag sets a key value pair on the span.
+	SetTag(key string, value interface{})
+	// Finish marks the end of the span. The span must not be used after
+	// Finish is called.
+	Finish()
+}
+
+// SpanOptions are the options used when starting a span.
+type SpanOptions struct {
+	// Parent is the parent span of the new span, nil means the new span is
+	// the root span of a new trace.
+	Parent ISpan
+	// StartTime is the start time of the new span, zero value means the
+	// current time.
+	StartTime time.Time
+}
+
+// SpanOption is the function type used for setting span options.
+type SpanOption func(opts *SpanOptions)
+
+// ChildOf returns a SpanOption that makes the new span a child of the
+// specified parent span.
+func ChildOf(parent ISpan) SpanOption {
+	return func(opts *SpanOptions) {
+		opts.Parent = parent
+	}
+}
+
+// StartTime returns a SpanOption that sets the start time of the new span.
+func StartTime(t time.Time) SpanOption {
+	return func(opts *SpanOptions) {
+		opts.StartTime = t
+	}
+}
+
+// GetSpanOptions returns the SpanOptions specified by the opts list.
+func GetSpanOptions(opts ...SpanOption) SpanOptions {
+	var result SpanOptions
+	for _, opt := range opts {
+		opt(&result)
+	}
+	if result.StartTime.IsZero() {
+		result.StartTime = time.Now()
+	}
+	return result
+}
+
+// ITracer is the interface of tracers. ITracer implementations must be safe
+// for concurrent access.
+type ITracer interface {
+	// StartSpan starts a new span with the specified operation name.
+	StartSpan(name string, opts ...SpanOption) ISpan
+}
+
+// NoopTracer is an ITracer that doesn't record anything.
+type NoopTracer struct{}
+
+var _ ITracer = NoopTracer{}
+
+// StartSpan returns a span that doesn't record anything.
+func (NoopTracer) StartSpan(name string, opts ...SpanOption) ISpan {
+	return noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetTag(key string, value interface{}) {}
+func (noopSpan) Finish()                              {}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a new context.Context instance that carries the
+// specified span.
+func ContextWithSpan(ctx context.Context, span ISpan) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by the specified context, nil is
+// returned when there is no such span.
+func SpanFromContext(ctx context.Context) ISpan {
+	if span, ok := ctx.Value(spanKey{}).(ISpan); ok {
+		return span
+	}
+	return nil
+}
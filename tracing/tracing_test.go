@@ -0,0 +1,124 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpanCanBeCarriedByContext(t *testing.T) {
+	if SpanFromContext(context.Background()) != nil {
+		t.Errorf("unexpected span")
+	}
+	span := NoopTracer{}.StartSpan("test")
+	ctx := ContextWithSpan(context.Background(), span)
+	if SpanFromContext(ctx) != span {
+		t.Errorf("span not carried by the context")
+	}
+}
+
+func TestSpanOptions(t *testing.T) {
+	opts := GetSpanOptions()
+	if opts.Parent != nil || opts.StartTime.IsZero() {
+		t.Errorf("unexpected default options %+v", opts)
+	}
+	parent := NoopTracer{}.StartSpan("parent")
+	start := time.Now().Add(-time.Second)
+	opts = GetSpanOptions(ChildOf(parent), StartTime(start))
+	if opts.Parent != parent || !opts.StartTime.Equal(start) {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
+
+func readRecords(t *testing.T, data []byte) []SpanRecord {
+	result := make([]SpanRecord, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var r SpanRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("failed to unmarshal %v", err)
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func TestFileTracerRecordsSpans(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := newFileTracer(buf)
+	root := tracer.StartSpan("root")
+	start := time.Now().Add(-time.Second)
+	child := tracer.StartSpan("child", ChildOf(root), StartTime(start))
+	child.SetTag("follower", 2)
+	child.Finish()
+	root.Finish()
+	other := tracer.StartSpan("other", ChildOf(NoopTracer{}.StartSpan("noop")))
+	other.Finish()
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("flush failed %v", err)
+	}
+	records := readRecords(t, buf.Bytes())
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	c, r, o := records[0], records[1], records[2]
+	if c.Name != "child" || r.Name != "root" || o.Name != "other" {
+		t.Errorf("unexpected names")
+	}
+	if c.TraceID != r.TraceID || c.ParentID != r.SpanID || r.ParentID != 0 {
+		t.Errorf("child not linked to its parent")
+	}
+	if o.TraceID == r.TraceID || o.ParentID != 0 {
+		t.Errorf("span with foreign parent should start a new trace")
+	}
+	if c.Duration < time.Second {
+		t.Errorf("start time option ignored, duration %s", c.Duration)
+	}
+	if v, ok := c.Tags["follower"]; !ok || v.(float64) != 2 {
+		t.Errorf("tag not recorded, %v", c.Tags)
+	}
+}
+
+func TestFileTracerWritesToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	if err != nil {
+		t.Fatalf("failed to create temp dir %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "spans.json")
+	tracer, err := NewFileTracer(fp)
+	if err != nil {
+		t.Fatalf("failed to create file tracer %v", err)
+	}
+	tracer.StartSpan("test").Finish()
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("close failed %v", err)
+	}
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatalf("failed to read file %v", err)
+	}
+	if records := readRecords(t, data); len(records) != 1 {
+		t.Errorf("got %d records, want 1", len(records))
+	}
+}
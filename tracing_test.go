@@ -0,0 +1,279 @@
+// Copyright 2017-2019 Lei Ni (nilei81@gmail.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !dragonboat_slowtest
+// +build !dragonboat_errorinjectiontest
+
+package dragonboat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/lni/dragonboat/raftpb"
+	"github.com/lni/dragonboat/tracing"
+)
+
+type testSpan struct {
+	name     string
+	parent   tracing.ISpan
+	tags     map[string]interface{}
+	finished bool
+}
+
+func (s *testSpan) SetTag(key string, value interface{}) {
+	s.tags[key] = value
+}
+
+func (s *testSpan) Finish() {
+	s.finished = true
+}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(name string,
+	opts ...tracing.SpanOption) tracing.ISpan {
+	o := tracing.GetSpanOptions(opts...)
+	s := &testSpan{
+		name:   name,
+		parent: o.Parent,
+		tags:   make(map[string]interface{}),
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return s
+}
+
+func (t *testTracer) names() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]string, 0)
+	for _, s := range t.spans {
+		result = append(result, s.name)
+	}
+	return result
+}
+
+func getTestTraceContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Minute)
+}
+
+func TestTracingCanBeUsedWhenNotEnabled(t *testing.T) {
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	trace := newRequestTrace(ctx, nil, syncProposeSpan, 1)
+	if trace != nil {
+		t.Fatalf("trace unexpectedly created")
+	}
+	trace.stage(commitSpan, time.Now())
+	trace.readIndexConfirmed()
+	trace.finish(nil)
+	pt := newProposalTracer(nil)
+	if pt != nil {
+		t.Fatalf("proposal tracer unexpectedly created")
+	}
+	entries := []pb.Entry{{Key: 1, Index: 1}}
+	pt.dequeued(entries, func(uint64) *requestTrace { return nil })
+	pt.appended(entries)
+	pt.saved(entries)
+	pt.replicated(pb.Message{Type: pb.ReplicateResp})
+	pt.committed(entries)
+	pt.applyStarted()
+	pt.applied(1)
+	pt.gc(time.Now())
+}
+
+func TestRequestSpanIsChildOfContextSpan(t *testing.T) {
+	tracer := &testTracer{}
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	parent := tracer.StartSpan("parent")
+	ctx = tracing.ContextWithSpan(ctx, parent)
+	trace := newRequestTrace(ctx, tracer, syncReadSpan, 1)
+	trace.stage(readIndexQueueSpan, time.Now())
+	trace.finish(errors.New("test error"))
+	if len(tracer.spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(tracer.spans))
+	}
+	req, stage := tracer.spans[1], tracer.spans[2]
+	if req.parent != parent || stage.parent != req {
+		t.Errorf("spans not linked")
+	}
+	if !req.finished || !stage.finished {
+		t.Errorf("spans not finished")
+	}
+	if req.tags["error"] != "test error" {
+		t.Errorf("error not recorded")
+	}
+}
+
+func TestProposalStagesAreTraced(t *testing.T) {
+	tracer := &testTracer{}
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	trace := newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	pt := newProposalTracer(tracer)
+	getTrace := func(key uint64) *requestTrace {
+		if key == 100 {
+			return trace
+		}
+		return nil
+	}
+	queued := []pb.Entry{{Key: 100}, {Key: 200}}
+	pt.dequeued(queued, getTrace)
+	appended := []pb.Entry{{Key: 100, Index: 5}, {Key: 200, Index: 6}}
+	pt.appended(appended)
+	pt.saved(appended)
+	resp := pb.Message{Type: pb.ReplicateResp, From: 2, LogIndex: 6}
+	pt.replicated(resp)
+	// duplicated responses are ignored
+	pt.replicated(resp)
+	pt.replicated(pb.Message{Type: pb.ReplicateResp, From: 3, LogIndex: 4})
+	pt.committed(appended)
+	if len(pt.tracked) != 0 {
+		t.Errorf("committed proposal still tracked")
+	}
+	pt.applyStarted()
+	pt.applied(100)
+	pt.applied(200)
+	trace.finish(nil)
+	expected := []string{
+		syncProposeSpan,
+		entryQueueSpan,
+		leaderAppendSpan,
+		logDBSaveSpan,
+		replicateSpan,
+		commitSpan,
+		smUpdateSpan,
+	}
+	names := tracer.names()
+	if len(names) != len(expected) {
+		t.Fatalf("got spans %v, want %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("span %d, got %s, want %s", i, names[i], expected[i])
+		}
+	}
+	if tracer.spans[4].tags["follower"] != uint64(2) {
+		t.Errorf("follower not tagged")
+	}
+	for _, s := range tracer.spans {
+		if !s.finished {
+			t.Errorf("span %s not finished", s.name)
+		}
+	}
+}
+
+func TestExpiredProposalTracesAreRemoved(t *testing.T) {
+	tracer := &testTracer{}
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	pt := newProposalTracer(tracer)
+	t1 := newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	t2 := newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	traces := map[uint64]*requestTrace{1: t1, 2: t2}
+	getTrace := func(key uint64) *requestTrace { return traces[key] }
+	pt.dequeued([]pb.Entry{{Key: 1}, {Key: 2}}, getTrace)
+	pt.appended([]pb.Entry{{Key: 1, Index: 1}, {Key: 2, Index: 2}})
+	pt.committed([]pb.Entry{{Key: 2, Index: 2}})
+	pt.gc(time.Now())
+	if len(pt.tracked) != 1 || len(pt.mu.applying) != 1 {
+		t.Errorf("traces unexpectedly removed")
+	}
+	pt.gc(time.Now().Add(2 * time.Minute))
+	if len(pt.tracked) != 0 || len(pt.mu.applying) != 0 {
+		t.Errorf("expired traces not removed")
+	}
+}
+
+func TestStagesAreNotTracedAfterRequestIsFinished(t *testing.T) {
+	tracer := &testTracer{}
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	trace := newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	pt := newProposalTracer(tracer)
+	getTrace := func(key uint64) *requestTrace { return trace }
+	entries := []pb.Entry{{Key: 100, Index: 1}}
+	pt.dequeued(entries, getTrace)
+	pt.appended(entries)
+	trace.finish(errors.New("timeout"))
+	trace.finish(nil)
+	pt.saved(entries)
+	pt.replicated(pb.Message{Type: pb.ReplicateResp, From: 2, LogIndex: 1})
+	pt.committed(entries)
+	pt.applyStarted()
+	pt.applied(100)
+	names := tracer.names()
+	want := []string{syncProposeSpan, entryQueueSpan, leaderAppendSpan}
+	if len(names) != len(want) {
+		t.Fatalf("got spans %v, want %v", names, want)
+	}
+	for idx := range want {
+		if names[idx] != want[idx] {
+			t.Errorf("got spans %v, want %v", names, want)
+		}
+	}
+	if tracer.spans[0].tags["error"] != "timeout" {
+		t.Errorf("request span finished again")
+	}
+	// finished traces are no longer tracked
+	trace = newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	pt.dequeued(entries, getTrace)
+	trace.finish(nil)
+	pt.gc(time.Now())
+	if len(pt.tracked) != 0 {
+		t.Errorf("finished trace not removed")
+	}
+	trace = newRequestTrace(ctx, tracer, syncProposeSpan, 1)
+	trace.finish(nil)
+	pt.dequeued(entries, getTrace)
+	if len(pt.tracked) != 0 {
+		t.Errorf("finished trace tracked")
+	}
+}
+
+func TestPendingProposalReturnsTrace(t *testing.T) {
+	pp, _ := getPendingProposal()
+	ctx, cancel := getTestTraceContext()
+	defer cancel()
+	trace := newRequestTrace(ctx, &testTracer{}, syncProposeSpan, 1)
+	rs, err := pp.proposeWithTrace(getBlankTestSession(),
+		[]byte("test data"), nil, time.Second, trace)
+	if err != nil {
+		t.Fatalf("propose failed %v", err)
+	}
+	if pp.getTrace(rs.key) != trace {
+		t.Errorf("trace not returned")
+	}
+	rs2, err := pp.propose(getBlankTestSession(),
+		[]byte("test data"), nil, time.Second)
+	if err != nil {
+		t.Fatalf("propose failed %v", err)
+	}
+	if pp.getTrace(rs2.key) != nil {
+		t.Errorf("unexpected trace")
+	}
+	rs.Release()
+	if rs.trace != nil {
+		t.Errorf("trace not reset")
+	}
+}